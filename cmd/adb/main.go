@@ -43,6 +43,7 @@ func main() {
 	// Execute root command
 	rootCmd := cli.NewRootCmd()
 	if err := rootCmd.Execute(); err != nil {
+		_ = app.Cleanup() // os.Exit skips the deferred call
 		os.Exit(1)
 	}
 }
@@ -1,9 +1,11 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/valter-silva-au/ai-dev-brain/internal/core"
 	"github.com/valter-silva-au/ai-dev-brain/internal/integration"
@@ -55,6 +57,7 @@ type App struct {
 	GovernanceLog     *observability.EventLog
 	MetricsCalculator *observability.MetricsCalculator
 	AlertEvaluator    *observability.AlertEvaluator
+	AlertTracker      *observability.AlertTracker
 	SerenaTelemetry   core.SerenaTelemetry
 }
 
@@ -118,6 +121,53 @@ func (a *backlogStoreAdapter) RemoveTask(id string) error {
 	return a.manager.RemoveTask(id)
 }
 
+// alertTaskSourceAdapter feeds the backlog to observability.AlertEvaluator so
+// alert rules can scope on priority, tag and repo without observability
+// importing the task model.
+type alertTaskSourceAdapter struct {
+	backlog storage.BacklogManager
+}
+
+func (a *alertTaskSourceAdapter) AlertTasks() ([]observability.AlertTask, error) {
+	backlog, err := a.backlog.Load()
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]observability.AlertTask, 0, len(backlog.Tasks))
+	for _, t := range backlog.Tasks {
+		tasks = append(tasks, observability.AlertTask{
+			ID:           t.ID,
+			Status:       string(t.Status),
+			Priority:     string(t.Priority),
+			Repo:         t.Repo,
+			Tags:         t.Tags,
+			WorktreePath: t.WorktreePath,
+		})
+	}
+	return tasks, nil
+}
+
+// BuildAlertConfig turns the notifications.alerts block into the evaluator's
+// rule set: the declared rules first, then the built-in defaults unless
+// disable_defaults is set. Shared by App wiring and `adb alerts rules`.
+func BuildAlertConfig(cfg models.AlertsConfig) (*observability.AlertConfig, error) {
+	specs := make([]observability.AlertRuleSpec, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		specs = append(specs, observability.AlertRuleSpec{
+			Name:       r.Name,
+			Condition:  r.Condition,
+			Severity:   r.Severity,
+			Threshold:  r.Threshold,
+			Count:      r.Count,
+			Priorities: r.Priorities,
+			Tags:       r.Tags,
+			Repos:      r.Repos,
+			Disabled:   r.Disabled,
+		})
+	}
+	return observability.BuildAlertConfig(specs, !cfg.DisableDefaults)
+}
+
 // contextStoreAdapter adapts storage.ContextManager to core.ContextStore
 type contextStoreAdapter struct {
 	manager storage.ContextManager
@@ -239,6 +289,32 @@ func (a *eventLoggerAdapter) Log(eventType string, data map[string]interface{})
 	a.log.Log(observability.EventType(eventType), data)
 }
 
+// subscribeInlineDispatch fires event rules for each event log appends, per
+// automation.inline_dispatch: sync runs them inside Log, async on the log's
+// subscriber goroutine (flushed by Cleanup), off leaves event rules to the
+// automation-dispatch drain. Sync never runs an exec command inside Log (a hook
+// would wait out its timeouts and retries): rules with exec steps are deferred
+// to the drain. A dispatch error is reported, never returned: Log is
+// non-fatal, and the drain retries a claim whose dispatch did not complete.
+func subscribeInlineDispatch(log *observability.EventLog, engine core.RuleEngine, mode string) {
+	deferExec := mode == models.InlineDispatchSync
+	handler := func(ev observability.Event) {
+		_, _, err := engine.DispatchLogged(context.Background(), core.LoggedEvent{
+			ID: ev.ID(), Type: string(ev.Type), At: ev.Timestamp, Payload: ev.Payload(),
+			Via: core.DispatchInline, DeferExec: deferExec,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: inline rule dispatch for %s: %v\n", ev.Type, err)
+		}
+	}
+	switch mode {
+	case models.InlineDispatchSync:
+		log.Subscribe(handler)
+	case models.InlineDispatchAsync:
+		log.SubscribeAsync(handler)
+	}
+}
+
 // serenaTelemetryAdapter implements core.SerenaTelemetry over the observability
 // EventLog: Record emits one serena.effectiveness_recorded event; Report rolls
 // the recorded history up from the append-only log — no separate store (#203).
@@ -702,8 +778,31 @@ func NewApp(basePath string) (*App, error) {
 	// Serena effectiveness telemetry - record/report over the event log (#203).
 	app.SerenaTelemetry = &serenaTelemetryAdapter{log: app.EventLog}
 
-	// Alert evaluator - evaluates alert conditions against thresholds
-	app.AlertEvaluator = observability.NewAlertEvaluator(nil, app.MetricsCalculator)
+	// Alert evaluator - evaluates alert conditions against thresholds. Rules
+	// declared under notifications.alerts run ahead of the built-in defaults;
+	// an invalid rule set is reported and falls back to the defaults so a typo
+	// never silences alerting (`adb alerts rules validate` shows every error).
+	var alertsCfg models.AlertsConfig
+	if app.MergedConfig != nil && app.MergedConfig.Global != nil {
+		alertsCfg = app.MergedConfig.Global.Notifications.Alerts
+	}
+	alertConfig, err := BuildAlertConfig(alertsCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: invalid alert rules, using defaults: %v\n", err)
+		alertConfig = nil
+	}
+	app.AlertEvaluator = observability.NewAlertEvaluator(alertConfig, app.MetricsCalculator,
+		observability.WithTaskSource(&alertTaskSourceAdapter{backlog: app.BacklogManager}),
+		observability.WithWorktreeInspector(func(path string) (bool, time.Time, error) {
+			st, err := app.GitWorktreeManager.WorktreeStatus(path)
+			return st.Exists && st.Dirty, st.LastModified, err
+		}),
+	)
+
+	// Alert tracker - persisted alert lifecycle (firing/acknowledged/snoozed/
+	// resolved) under .adb/; emits alert.fired/alert.resolved on transitions.
+	app.AlertTracker = observability.NewAlertTracker(
+		observability.NewAlertStateStore(app.StatePath(statedir.FileAlertState)), app.EventLog)
 
 	// Stage manager - owns Organization/Initiative registries + the Stage dimension
 	// and the founder-playbook StageGates. Registries are workspace-level metadata
@@ -806,14 +905,31 @@ func NewApp(basePath string) (*App, error) {
 	// (FileArtifactWriter) and/or typed graph edges (edgeWriter → the entity
 	// stores). Time-triggered rules become scheduler jobs; event-triggered rules
 	// fire via the scheduler's automation-dispatch job (opt-in, automation.enabled)
-	// or `adb schedule dispatch`.
+	// or `adb schedule dispatch`, and — with automation.inline_dispatch — in the
+	// process that logs the event, as it is logged. Both paths claim an event in
+	// the .adb/rule_outbox/ outbox, so it fires once, and the drain retries a
+	// claim whose dispatch never completed. Condition expressions
+	// read `task` through the backlog; every firing lands in the
+	// .adb/rule_firings.jsonl ledger, and debounce/throttle state persists in
+	// .adb/automation_state.yaml.
 	app.RuleEngine = core.NewRuleEngine(
 		storage.NewFileRuleStore(basePath),
 		app.GraphManager,
 		core.NewFileActionRunner(basePath),
 		edgeWriter,
 		core.NewFileArtifactWriter(basePath),
+		core.WithRuleTasks(&backlogStoreAdapter{manager: app.BacklogManager}),
+		core.WithRuleLedger(core.NewFileFiringLedger(app.StatePath(statedir.FileRuleFirings))),
+		core.WithRuleConcurrencyStore(core.NewFileConcurrencyStore(app.StatePath(statedir.FileAutomationState))),
+		core.WithRuleOutbox(core.NewFileDispatchOutbox(app.StatePath(statedir.FileRuleOutbox))),
 	)
+	if app.MergedConfig != nil && app.MergedConfig.Global != nil && app.MergedConfig.Global.Automation.Enabled {
+		mode, err := app.MergedConfig.Global.Automation.InlineDispatchMode()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v; inline rule dispatch is off\n", err)
+		}
+		subscribeInlineDispatch(app.EventLog, app.RuleEngine, mode)
+	}
 
 	// Ingest manager - the staged ingestion pipeline (decision D8): immutable
 	// raw/ landing with provenance + hash/cursor dedup (FileRawStore), a
@@ -881,6 +997,11 @@ func NewApp(basePath string) (*App, error) {
 	// Fail-open + non-clobbering; adb configures only, never installs a server.
 	app.TaskManager.SetSerenaProvisioner(core.NewSerenaProvisioner())
 
+	// Keep vector memory in step with the task lifecycle: a deleted task's
+	// namespaces are purged, an archived task's move under archive/. A no-op
+	// until the workspace has a memory db.
+	app.TaskManager.SetMemoryHook(memoryLifecycleHook{app: app})
+
 	return app, nil
 }
 
@@ -889,8 +1010,12 @@ func (app *App) GetSessionStore() storage.SessionStoreManager {
 	return app.SessionStoreManager
 }
 
-// Cleanup performs cleanup operations (optional, for graceful shutdown)
+// Cleanup performs cleanup operations (optional, for graceful shutdown): it
+// waits for asynchronous event subscribers (async inline rule dispatch) to
+// finish the events logged so far.
 func (app *App) Cleanup() error {
-	// Future: close any open resources, flush buffers, etc.
+	if app.EventLog != nil {
+		app.EventLog.Flush()
+	}
 	return nil
 }
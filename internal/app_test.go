@@ -1,11 +1,13 @@
 package internal
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/valter-silva-au/ai-dev-brain/internal/core"
 	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
 	"github.com/valter-silva-au/ai-dev-brain/internal/storage"
 	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
@@ -766,3 +768,42 @@ func TestApp_Integration_NoCircularImports(t *testing.T) {
 		}
 	}
 }
+
+// dispatchRecorder is a RuleEngine that records DispatchLogged calls.
+type dispatchRecorder struct {
+	core.RuleEngine
+	got []core.LoggedEvent
+}
+
+func (d *dispatchRecorder) DispatchLogged(_ context.Context, ev core.LoggedEvent) ([]core.Firing, bool, error) {
+	d.got = append(d.got, ev)
+	return nil, true, nil
+}
+
+func TestSubscribeInlineDispatch(t *testing.T) {
+	for _, mode := range []string{models.InlineDispatchOff, models.InlineDispatchSync, models.InlineDispatchAsync} {
+		log := observability.NewEventLog(filepath.Join(t.TempDir(), "events.jsonl"))
+		engine := &dispatchRecorder{}
+		subscribeInlineDispatch(log, engine, mode)
+		log.Log(observability.EventTaskStatusChanged, map[string]interface{}{"task_id": "TASK-1"})
+		log.Flush()
+
+		if mode == models.InlineDispatchOff {
+			if len(engine.got) != 0 {
+				t.Errorf("off: dispatched %+v", engine.got)
+			}
+			continue
+		}
+		events, _ := log.ReadAll()
+		if len(engine.got) != 1 || len(events) != 1 {
+			t.Fatalf("%s: dispatched %d events for %d logged", mode, len(engine.got), len(events))
+		}
+		ev := engine.got[0]
+		if ev.ID != events[0].ID() || ev.Type != "task.status_changed" || ev.Payload["task_id"] != "TASK-1" || ev.Via != core.DispatchInline {
+			t.Errorf("%s: dispatched %+v", mode, ev)
+		}
+		if ev.DeferExec != (mode == models.InlineDispatchSync) {
+			t.Errorf("%s: DeferExec = %v; only sync defers exec rules to the drain", mode, ev.DeferExec)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+)
+
+func newAlertsAckCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ack <id>",
+		Short: "Acknowledge an alert until it resolves",
+		Long: `Acknowledge an active alert. It is hidden from the dashboard and from
+notifications until its condition clears; if it fires again later it starts a
+new, unacknowledged episode. <id> is the alert ID shown by ` + "`adb alerts`" + `
+(a unique prefix is enough) or its key, e.g. task_stale:TASK-00042.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tracker, err := refreshedAlertTracker()
+			if err != nil {
+				return err
+			}
+			rec, err := tracker.Acknowledge(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ Acknowledged %s: %s\n", rec.ID, rec.Message)
+			return nil
+		},
+	}
+}
+
+func newAlertsSnoozeCmd() *cobra.Command {
+	var forDur string
+	cmd := &cobra.Command{
+		Use:   "snooze <id>",
+		Short: "Silence an alert for a while",
+		Long: `Snooze an active alert for a duration (e.g. 4h, 2d, 1w). It is hidden from
+the dashboard and from notifications until the snooze expires, then fires
+again if the condition still holds.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := observability.ParseAlertDuration(forDur)
+			if err != nil {
+				return fmt.Errorf("invalid --for: %w", err)
+			}
+			tracker, err := refreshedAlertTracker()
+			if err != nil {
+				return err
+			}
+			rec, err := tracker.Snooze(args[0], d)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ Snoozed %s until %s: %s\n",
+				rec.ID, rec.SnoozedUntil.Local().Format("2006-01-02 15:04"), rec.Message)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&forDur, "for", "1d", "snooze duration (e.g. 4h, 2d, 1w)")
+	return cmd
+}
+
+func newAlertsHistoryCmd() *cobra.Command {
+	var (
+		jsonOutput bool
+		limit      int
+	)
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show recent alerts and their lifecycle state",
+		Long: `List active alerts and those resolved in the last 30 days, most recently
+fired first. The complete timeline is in the event log:
+
+  adb events query --type alert.fired`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if App == nil || App.AlertTracker == nil {
+				return fmt.Errorf("app not initialized")
+			}
+			records, err := App.AlertTracker.Records()
+			if err != nil {
+				return fmt.Errorf("load alert state: %w", err)
+			}
+			if limit > 0 && len(records) > limit {
+				records = records[:limit]
+			}
+			if jsonOutput {
+				return printJSON(records)
+			}
+			if len(records) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No alert history yet.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tSTATE\tSEVERITY\tFIRED\tRESOLVED\tFIRES\tMESSAGE")
+			for _, r := range records {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+					r.ID, r.State, r.Severity, formatAlertTime(r.FiredAt), formatAlertTime(r.ResolvedAt), r.Fired, r.Message)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
+	cmd.Flags().IntVar(&limit, "limit", 50, "maximum number of alerts to show (0 = all)")
+	return cmd
+}
+
+// refreshedAlertTracker re-evaluates alerts so the state store knows every
+// currently-firing ID before an ack or snooze looks one up.
+func refreshedAlertTracker() (*observability.AlertTracker, error) {
+	if App == nil || App.AlertTracker == nil {
+		return nil, fmt.Errorf("app not initialized")
+	}
+	if _, err := evaluateTrackedAlerts(); err != nil {
+		return nil, fmt.Errorf("failed to evaluate alerts: %w", err)
+	}
+	return App.AlertTracker, nil
+}
+
+func formatAlertTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+)
+
+func runAlertsCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := NewAlertsCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestAlertsAckAndHistory(t *testing.T) {
+	app, cleanup := setupEventsTest(t)
+	defer cleanup()
+
+	// Eleven backlog tasks trip the default backlog_too_large rule.
+	for i := 0; i < 11; i++ {
+		app.EventLog.Log(observability.EventTaskCreated, map[string]interface{}{
+			"task_id": "TASK-" + string(rune('a'+i)), "status": "backlog",
+		})
+	}
+	id := observability.Alert{Type: observability.AlertBacklogTooLarge}.Fingerprint()
+
+	out, err := runAlertsCmd(t, "ack", id)
+	if err != nil {
+		t.Fatalf("ack: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Acknowledged "+id) {
+		t.Errorf("ack output = %q", out)
+	}
+
+	tracked, err := evaluateTrackedAlerts()
+	if err != nil {
+		t.Fatalf("evaluateTrackedAlerts: %v", err)
+	}
+	if len(tracked) != 1 || tracked[0].State != observability.AlertStateAcknowledged {
+		t.Fatalf("tracked = %+v", tracked)
+	}
+	if len(observability.Unacknowledged(tracked)) != 0 {
+		t.Error("acknowledged alert must not be in the unacknowledged set")
+	}
+
+	out, err = runAlertsCmd(t, "history")
+	if err != nil {
+		t.Fatalf("history: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, id) || !strings.Contains(out, "acknowledged") {
+		t.Errorf("history output:\n%s", out)
+	}
+
+	fired, _ := app.EventLog.ReadByType(observability.EventAlertFired)
+	if len(fired) != 1 {
+		t.Errorf("alert.fired events = %d, want 1", len(fired))
+	}
+}
+
+func TestAlertsSnooze_RejectsBadDuration(t *testing.T) {
+	_, cleanup := setupEventsTest(t)
+	defer cleanup()
+	if out, err := runAlertsCmd(t, "snooze", "abcd", "--for", "soon"); err == nil {
+		t.Fatalf("expected error, got:\n%s", out)
+	}
+	if _, err := runAlertsCmd(t, "snooze", "abcd", "--for", "2d"); err == nil || !strings.Contains(err.Error(), "no active alert") {
+		t.Fatalf("snooze of unknown id: %v", err)
+	}
+}
+
+func TestAlertsNotify_NoAlertsNeedNoChannels(t *testing.T) {
+	_, cleanup := setupEventsTest(t)
+	defer cleanup()
+
+	if out, err := runAlertsCmd(t, "--notify"); err != nil {
+		t.Fatalf("--notify with nothing firing and no channels: %v\n%s", err, out)
+	}
+	if _, err := notifyAlerts(context.Background(), []observability.Alert{{Type: observability.AlertBacklogTooLarge}}); err == nil ||
+		!strings.Contains(err.Error(), "no notification channels") {
+		t.Errorf("an alert to deliver without channels: err = %v", err)
+	}
+}
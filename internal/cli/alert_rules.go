@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/valter-silva-au/ai-dev-brain/internal"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// newAlertsRulesCmd creates `adb alerts rules` — inspect and check the alert
+// rules declared under notifications.alerts in .taskconfig.
+func newAlertsRulesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules",
+		Short: "List and validate alert rules",
+		Long: `Alert rules are declared in .taskconfig under notifications.alerts.rules
+and are matched, in order, ahead of the four built-in defaults:
+
+  notifications:
+    alerts:
+      rules:
+        - name: p0-blocked
+          condition: task_blocked_too_long
+          threshold: 4h
+          severity: high
+          priorities: [P0]
+        - name: dirty-worktrees
+          condition: worktree_dirty_untouched
+          threshold: 3d
+          repos: ["github.com/acme/*"]
+
+  adb alerts rules list       # effective rules, config first, then defaults
+  adb alerts rules validate   # report every problem in the declared rules`,
+	}
+	cmd.AddCommand(newAlertsRulesListCmd(), newAlertsRulesValidateCmd())
+	return cmd
+}
+
+// alertsConfig returns the notifications.alerts block, or the zero value when
+// config is not loaded.
+func alertsConfig() models.AlertsConfig {
+	return notificationConfig().Alerts
+}
+
+func newAlertsRulesListCmd() *cobra.Command {
+	var jsonOutput bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the effective alert rules",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if App == nil {
+				return fmt.Errorf("app not initialized")
+			}
+			cfg, err := internal.BuildAlertConfig(alertsConfig())
+			if err != nil {
+				return fmt.Errorf("invalid alert rules (run `adb alerts rules validate`): %w", err)
+			}
+			if jsonOutput {
+				return printJSON(cfg.Thresholds)
+			}
+			if len(cfg.Thresholds) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No alert rules (defaults disabled and none declared).")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tCONDITION\tSEVERITY\tTHRESHOLD\tSCOPE\tSOURCE")
+			for _, th := range cfg.Thresholds {
+				source := "config"
+				if th.Name == "" {
+					source = "default"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					th.RuleName(), th.Type, th.Severity, alertThresholdLabel(th), alertScopeLabel(th), source)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
+	return cmd
+}
+
+func newAlertsRulesValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the alert rules declared in .taskconfig",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if App == nil {
+				return fmt.Errorf("app not initialized")
+			}
+			rules := alertsConfig().Rules
+			if _, err := internal.BuildAlertConfig(alertsConfig()); err != nil {
+				for _, line := range strings.Split(err.Error(), "\n") {
+					fmt.Fprintf(cmd.OutOrStdout(), "✗ %s\n", line)
+				}
+				return fmt.Errorf("alert rules are invalid")
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ %d alert rule(s) valid\n", len(rules))
+			return nil
+		},
+	}
+}
+
+func alertThresholdLabel(th observability.AlertThreshold) string {
+	if cond, ok := observability.LookupAlertCondition(th.Type); ok && cond.Count {
+		return fmt.Sprintf("> %d", th.Count)
+	}
+	return observability.FormatAlertDuration(th.Duration)
+}
+
+func alertScopeLabel(th observability.AlertThreshold) string {
+	var parts []string
+	if len(th.Priorities) > 0 {
+		parts = append(parts, "priority="+strings.Join(th.Priorities, ","))
+	}
+	if len(th.Tags) > 0 {
+		parts = append(parts, "tag="+strings.Join(th.Tags, ","))
+	}
+	if len(th.Repos) > 0 {
+		parts = append(parts, "repo="+strings.Join(th.Repos, ","))
+	}
+	if len(parts) == 0 {
+		return "all"
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+func runAlertsRules(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := NewAlertsCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(append([]string{"rules"}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestAlertsRulesList_ConfigRulesThenDefaults(t *testing.T) {
+	app, cleanup := setupEventsTest(t)
+	defer cleanup()
+	app.MergedConfig.Global.Notifications.Alerts = models.AlertsConfig{Rules: []models.AlertRuleConfig{
+		{Name: "p0-blocked", Condition: "task_blocked_too_long", Threshold: "4h", Severity: "high", Priorities: []string{"P0"}},
+	}}
+
+	out, err := runAlertsRules(t, "list")
+	if err != nil {
+		t.Fatalf("list: %v\n%s", err, out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("want header + 5 rules, got:\n%s", out)
+	}
+	if !strings.Contains(lines[1], "p0-blocked") || !strings.Contains(lines[1], "priority=P0") || !strings.Contains(lines[1], "config") {
+		t.Errorf("config rule row = %q", lines[1])
+	}
+	if !strings.Contains(out, "backlog_too_large") || !strings.Contains(out, "> 10") || !strings.Contains(out, "default") {
+		t.Errorf("defaults missing:\n%s", out)
+	}
+}
+
+func TestAlertsRulesValidate(t *testing.T) {
+	app, cleanup := setupEventsTest(t)
+	defer cleanup()
+
+	app.MergedConfig.Global.Notifications.Alerts = models.AlertsConfig{Rules: []models.AlertRuleConfig{
+		{Name: "ok", Condition: "task_stale", Threshold: "2d"},
+	}}
+	out, err := runAlertsRules(t, "validate")
+	if err != nil || !strings.Contains(out, "1 alert rule(s) valid") {
+		t.Fatalf("validate valid config: %v\n%s", err, out)
+	}
+
+	app.MergedConfig.Global.Notifications.Alerts = models.AlertsConfig{Rules: []models.AlertRuleConfig{
+		{Name: "bad", Condition: "task_stale", Threshold: "whenever"},
+		{Name: "worse", Condition: "made_up"},
+	}}
+	out, err = runAlertsRules(t, "validate")
+	if err == nil {
+		t.Fatalf("validate should fail:\n%s", out)
+	}
+	if !strings.Contains(out, "bad") || !strings.Contains(out, "worse") {
+		t.Errorf("both errors should be listed:\n%s", out)
+	}
+}
@@ -1,42 +1,80 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/valter-silva-au/ai-dev-brain/internal/integration/notify"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
 )
 
 // NewAlertsCmd creates the alerts command
 func NewAlertsCmd() *cobra.Command {
-	var notify bool
+	var (
+		sendNotify bool
+		showAll    bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "alerts",
 		Short: "Display active alerts",
-		Long:  `Evaluate and display active alerts based on thresholds`,
+		Long: `Evaluate and display active alerts based on thresholds.
+
+Each alert has a stable ID. Acknowledged and snoozed alerts are hidden (and
+not notified) until they resolve or the snooze expires:
+
+  adb alerts ack <id>
+  adb alerts snooze <id> --for 2d
+  adb alerts history`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if App == nil {
 				return fmt.Errorf("app not initialized")
 			}
 
-			// Evaluate all alerts
-			alerts, err := App.AlertEvaluator.EvaluateAll()
+			// Evaluate all alerts and reconcile them with the lifecycle state
+			tracked, err := evaluateTrackedAlerts()
 			if err != nil {
 				return fmt.Errorf("failed to evaluate alerts: %w", err)
 			}
+			alerts := observability.Unacknowledged(tracked)
+			hidden := len(tracked) - len(alerts)
 
-			if len(alerts) == 0 {
+			shown := tracked
+			if !showAll {
+				shown = shown[:0:0]
+				for _, t := range tracked {
+					if t.State == observability.AlertStateFiring {
+						shown = append(shown, t)
+					}
+				}
+			}
+
+			if len(shown) == 0 {
 				fmt.Println("✓ No active alerts")
+				if hidden > 0 {
+					fmt.Printf("  (%d acknowledged or snoozed — use --all to show)\n", hidden)
+				}
+				// Still run the notifier so the ledger forgets alerts that
+				// have resolved — a later re-fire then notifies again.
+				if sendNotify {
+					if _, err := notifyAlerts(cmd.Context(), nil); err != nil {
+						return fmt.Errorf("failed to send notifications: %w", err)
+					}
+				}
 				return nil
 			}
 
 			// Display alerts
-			fmt.Printf("=== Active Alerts (%d) ===\n\n", len(alerts))
+			fmt.Printf("=== Active Alerts (%d) ===\n\n", len(shown))
 
-			for _, alert := range alerts {
+			for _, alert := range shown {
 				// Display alert with severity emoji
 				emoji := getAlertEmoji(string(alert.Severity))
 				fmt.Printf("%s [%s] %s\n", emoji, alert.Severity, alert.Message)
+				fmt.Printf("   ID: %s%s\n", alert.ID, alertStateSuffix(alert))
 
 				if alert.TaskID != "" {
 					fmt.Printf("   Task: %s\n", alert.TaskID)
@@ -44,25 +82,68 @@ func NewAlertsCmd() *cobra.Command {
 
 				fmt.Println()
 			}
+			if hidden > 0 && !showAll {
+				fmt.Printf("(%d acknowledged or snoozed — use --all to show)\n\n", hidden)
+			}
 
 			// Send notifications if requested
-			if notify {
+			if sendNotify {
 				fmt.Println("Sending notifications...")
-				// TODO: Implement notification sending
-				// This would integrate with configured notification channels
-				// (e.g., Slack, email, Discord)
-				fmt.Println("✓ Notifications sent")
+				deliveries, err := notifyAlerts(cmd.Context(), alerts)
+				if err != nil {
+					return fmt.Errorf("failed to send notifications: %w", err)
+				}
+				for _, d := range deliveries {
+					if d.Status == notify.DeliveryFailed {
+						fmt.Printf("   ✗ %s → %s: %s\n", d.Key, d.Channel, d.Error)
+					}
+				}
+				fmt.Printf("✓ Notifications: %s\n", notify.Summary(deliveries))
 			}
 
 			return nil
 		},
 	}
 
-	cmd.Flags().BoolVar(&notify, "notify", false, "Send notifications for alerts")
+	cmd.Flags().BoolVar(&sendNotify, "notify", false, "Send notifications for unacknowledged alerts to the configured channels")
+	cmd.Flags().BoolVar(&showAll, "all", false, "Also show acknowledged and snoozed alerts")
+	cmd.AddCommand(newAlertsRulesCmd(), newAlertsAckCmd(), newAlertsSnoozeCmd(), newAlertsHistoryCmd())
 
 	return cmd
 }
 
+// evaluateTrackedAlerts runs the AlertEvaluator and reconciles the result with
+// the persisted alert lifecycle, emitting alert.fired/alert.resolved on
+// transitions. Shared by `adb alerts`, the dashboard and the alerts-tick job.
+func evaluateTrackedAlerts() ([]observability.TrackedAlert, error) {
+	if App.AlertEvaluator == nil {
+		return nil, nil
+	}
+	alerts, err := App.AlertEvaluator.EvaluateAll()
+	if err != nil {
+		return nil, err
+	}
+	if App.AlertTracker == nil {
+		tracked := make([]observability.TrackedAlert, len(alerts))
+		for i, a := range alerts {
+			tracked[i] = observability.TrackedAlert{Alert: a, ID: a.Fingerprint(), State: observability.AlertStateFiring}
+		}
+		return tracked, nil
+	}
+	return App.AlertTracker.Track(alerts)
+}
+
+// alertStateSuffix annotates a non-firing alert with its lifecycle state.
+func alertStateSuffix(t observability.TrackedAlert) string {
+	switch t.State {
+	case observability.AlertStateAcknowledged:
+		return " (acknowledged)"
+	case observability.AlertStateSnoozed:
+		return " (snoozed until " + t.SnoozedUntil.Local().Format("2006-01-02 15:04") + ")"
+	}
+	return ""
+}
+
 // getAlertEmoji returns an emoji for the alert severity
 func getAlertEmoji(severity string) string {
 	switch severity {
@@ -76,3 +157,40 @@ func getAlertEmoji(severity string) string {
 		return "⚠️"
 	}
 }
+
+// notificationConfig returns the global notifications block, or the zero
+// value when config is not loaded.
+func notificationConfig() models.NotificationConfig {
+	if App == nil || App.MergedConfig == nil || App.MergedConfig.Global == nil {
+		return models.NotificationConfig{}
+	}
+	return App.MergedConfig.Global.Notifications
+}
+
+// notifyAlerts delivers the currently-firing alerts to every configured
+// notification channel through the workspace's delivery ledger, so an alert
+// already sent within the renotify window is suppressed. It is shared by
+// `adb alerts --notify` and the scheduler's alerts-tick job. alerts must be
+// the complete active set — keys missing from it are dropped from the ledger.
+func notifyAlerts(ctx context.Context, alerts []observability.Alert) ([]notify.Delivery, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cfg := notificationConfig()
+	msgs := notify.MessagesForConfig(cfg, alerts)
+	// Channels are only checked with something to deliver; with nothing, the
+	// notifier still runs, channel-less, so the ledger forgets resolved alerts.
+	var channels []notify.Channel
+	if len(msgs) > 0 {
+		if len(cfg.Channels) == 0 {
+			return nil, fmt.Errorf("no notification channels configured (set notifications.channels in .taskconfig)")
+		}
+		var err error
+		if channels, err = notify.ChannelsFromConfig(cfg, App.BasePath); err != nil {
+			return nil, err
+		}
+	}
+	ledger := notify.NewLedger(statedir.Path(App.BasePath, statedir.FileNotifyLedger))
+	n := notify.NewNotifier(channels, ledger, notify.WithRenotify(notify.RenotifyFromConfig(cfg)))
+	return n.Notify(ctx, msgs)
+}
@@ -5,7 +5,6 @@ import (
 	"io"
 	"strings"
 	"text/tabwriter"
-	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
@@ -69,7 +68,7 @@ func newCommLogCmd() *cobra.Command {
 				return fmt.Errorf("no content: pass --message or pipe via stdin")
 			}
 
-			comm := models.NewCommunication(newCommID(), taskID, content)
+			comm := models.NewCommunication(models.NewCommunicationID(), taskID, content)
 			comm.Direction = dir
 			comm.From = from
 			comm.To = to
@@ -162,12 +161,6 @@ func newCommListCmd() *cobra.Command {
 	return cmd
 }
 
-// newCommID mints a timestamp-based communication id. The stored filename is
-// primarily date+subject; the id is a stable fallback identifier.
-func newCommID() string {
-	return "comm-" + time.Now().UTC().Format("20060102T150405.000000000")
-}
-
 func subjectSuffix(subject string) string {
 	if subject == "" {
 		return ""
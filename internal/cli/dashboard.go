@@ -132,12 +132,13 @@ func generateDashboardContent() string {
 			content.WriteString("⚠️  Failed to load metrics\n")
 		}
 
-		// Alerts section
+		// Alerts section (unacknowledged only — acked/snoozed alerts are
+		// managed with `adb alerts ack|snooze`)
 		if App.AlertEvaluator != nil {
-			alerts, err := App.AlertEvaluator.EvaluateAll()
+			tracked, err := evaluateTrackedAlerts()
 			if err == nil {
 				content.WriteString("\n")
-				content.WriteString(formatAlertsSection(alerts))
+				content.WriteString(formatAlertsSection(observability.Unacknowledged(tracked)))
 			}
 		}
 
@@ -17,9 +17,10 @@ import (
 //	adb events query [--type=T] [--task=ID] [--since=DUR] [--json]
 //	adb events tail  [--follow] [--json]
 //
-// Both read from <ADB_HOME>/.adb/events.jsonl (see internal/app.go). Filters are
-// applied in Go, so the CLI is a thin renderer over EventLog.ReadAll /
-// ReadSince — the file format itself is stable JSONL and the extension can
+// Both read from <ADB_HOME>/.adb/events.jsonl and its sealed segments (see
+// internal/app.go and observability/segments.go). Filters are applied in Go,
+// so the CLI is a thin renderer over EventLog.ReadRange / ReadAll — the file
+// format itself is stable JSONL and the extension can
 // spawn `adb events tail --follow --json` and pipe stdout directly into the
 // webview.
 func NewEventsCmd() *cobra.Command {
@@ -34,7 +35,8 @@ Every event is one of the types documented in internal/observability/schema.go
 (KnownEventTypes / IsKnownEventType). Consumers — metrics, alerts, the VS Code
 webview — should use that set as the allowlist rather than hard-coding strings.`,
 	}
-	cmd.AddCommand(newEventsQueryCmd(), newEventsTailCmd(), newEventsDigestCmd())
+	cmd.AddCommand(newEventsQueryCmd(), newEventsTailCmd(), newEventsDigestCmd(),
+		newEventsSegmentsCmd(), newEventsCompactCmd())
 	return cmd
 }
 
@@ -132,11 +134,6 @@ by any consumer, including the webview overview reload path).`,
 			if App == nil || App.EventLog == nil {
 				return fmt.Errorf("app not initialized")
 			}
-			events, err := App.EventLog.ReadAll()
-			if err != nil {
-				return fmt.Errorf("read event log: %w", err)
-			}
-
 			var cutoff time.Time
 			if since != "" {
 				d, err := parseDuration(since)
@@ -146,6 +143,17 @@ by any consumer, including the webview overview reload path).`,
 				cutoff = time.Now().UTC().Add(-d)
 			}
 
+			// The segment index narrows the read to segments that can hold
+			// a match; filterEvents still applies every filter.
+			var types []observability.EventType
+			if typeFilter != "" {
+				types = append(types, observability.EventType(typeFilter))
+			}
+			events, err := App.EventLog.ReadRange(cutoff, time.Time{}, types...)
+			if err != nil {
+				return fmt.Errorf("read event log: %w", err)
+			}
+
 			filtered := filterEvents(events, typeFilter, taskFilter, cutoff)
 
 			if jsonOut {
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+)
+
+func newEventsSegmentsCmd() *cobra.Command {
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "segments",
+		Short: "List the event log's segment files and their index entries",
+		Long: `List the segments that make up the event log, oldest first: the sealed
+events.jsonl.N files and the active events.jsonl, with the time range and
+event types the sidecar index (events.jsonl.idx) records for each. Queries
+only open segments whose range and types can match.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if App == nil || App.EventLog == nil {
+				return fmt.Errorf("app not initialized")
+			}
+			segs, err := App.EventLog.Segments()
+			if err != nil {
+				return fmt.Errorf("read event log index: %w", err)
+			}
+			if jsonOut {
+				return printJSON(segs)
+			}
+			if len(segs) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "Event log is empty.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SEGMENT\tEVENTS\tSIZE\tFROM\tTO\tTYPES")
+			for _, s := range segs {
+				name := filepath.Base(s.Path)
+				if s.Active {
+					name += " (active)"
+				}
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%d\n", name, s.Events, formatBytes(s.Bytes),
+					formatSegmentTime(s.From), formatSegmentTime(s.To), len(s.Types))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+	return cmd
+}
+
+func newEventsCompactCmd() *cobra.Command {
+	var (
+		olderThan string
+		dryRun    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Merge small sealed segments and drop malformed or duplicate lines",
+		Long: `Rewrite the sealed event-log segments: runs of small segments are merged
+up to the segment size (8 MiB), malformed and exact-duplicate lines are
+removed, and with --older-than events older than the window are discarded.
+The active segment is never touched. The scheduler's events-rotate job runs
+this (without --older-than) every tick.
+
+Examples:
+  adb events compact --dry-run
+  adb events compact --older-than 180d`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if App == nil || App.EventLog == nil {
+				return fmt.Errorf("app not initialized")
+			}
+			opts := observability.CompactOptions{DryRun: dryRun}
+			if olderThan != "" {
+				d, err := parseDuration(olderThan)
+				if err != nil {
+					return fmt.Errorf("invalid --older-than duration %q: %w", olderThan, err)
+				}
+				opts.DropBefore = time.Now().UTC().Add(-d)
+			}
+			res, err := App.EventLog.Compact(opts)
+			if err != nil {
+				return fmt.Errorf("compact event log: %w", err)
+			}
+			verb := "Compacted"
+			if dryRun {
+				verb = "Would compact"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %d sealed segments (%s) into %d (%s): %d dropped, %d malformed, %d duplicates\n",
+				verb, res.SegmentsBefore, formatBytes(res.BytesBefore), res.SegmentsAfter, formatBytes(res.BytesAfter),
+				res.Dropped, res.Malformed, res.Duplicates)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Discard events older than this window (e.g. 180d)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report what would change without rewriting anything")
+	return cmd
+}
+
+func formatSegmentTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// formatBytes renders a byte count with a binary unit (KiB, MiB, ...).
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
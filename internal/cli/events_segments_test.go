@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+)
+
+func TestEventsSegmentsAndCompact(t *testing.T) {
+	app, cleanup := setupEventsTest(t)
+	defer cleanup()
+
+	for i := 0; i < 3; i++ {
+		app.EventLog.Log(observability.EventTaskCreated, map[string]interface{}{"task_id": "TASK-1"})
+		if err := app.EventLog.Seal(); err != nil {
+			t.Fatalf("Seal: %v", err)
+		}
+	}
+	app.EventLog.Log(observability.EventTaskCreated, map[string]interface{}{"task_id": "TASK-2"})
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := NewEventsCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("events %v: %v\n%s", args, err, out.String())
+		}
+		return out.String()
+	}
+
+	out := run("segments")
+	if strings.Count(out, "events.jsonl.") != 3 || !strings.Contains(out, "events.jsonl (active)") {
+		t.Errorf("segments output:\n%s", out)
+	}
+
+	out = run("compact", "--dry-run")
+	if !strings.Contains(out, "Would compact 3 sealed segments") || !strings.Contains(out, "into 1") {
+		t.Errorf("dry-run output: %q", out)
+	}
+	if segs, _ := app.EventLog.Segments(); len(segs) != 4 {
+		t.Fatalf("dry run rewrote segments: %d left", len(segs))
+	}
+
+	run("compact")
+	segs, _ := app.EventLog.Segments()
+	if len(segs) != 2 {
+		t.Fatalf("after compact %d segments, want 2 (one sealed + active)", len(segs))
+	}
+	if all, _ := app.EventLog.ReadAll(); len(all) != 4 {
+		t.Errorf("events after compact = %d, want 4", len(all))
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{512: "512 B", 2048: "2.0 KiB", 8 << 20: "8.0 MiB"} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/valter-silva-au/ai-dev-brain/internal/core"
@@ -27,6 +28,7 @@ func NewHookCmd() *cobra.Command {
 		newHookStopCmd(),
 		newHookTaskCompletedCmd(),
 		newHookSessionEndCmd(),
+		newHookTraceFlushCmd(),
 	)
 
 	return hookCmd
@@ -164,7 +166,11 @@ func newHookPreToolUseCmd() *cobra.Command {
 				return fmt.Errorf("failed to parse event: %w", err)
 			}
 
-			if err := engine.ProcessPreToolUse(event); err != nil {
+			span := newHookTracer().startHook("pre-tool-use", event.SessionID, event.ToolUseID, "")
+			span.startTool(event.ToolUseID, event.ToolName)
+			err = engine.ProcessPreToolUse(event)
+			span.end(err)
+			if err != nil {
 				return err
 			}
 
@@ -196,7 +202,11 @@ func newHookPostToolUseCmd() *cobra.Command {
 				return fmt.Errorf("failed to parse event: %w", err)
 			}
 
-			if err := engine.ProcessPostToolUse(event); err != nil {
+			span := newHookTracer().startHook("post-tool-use", event.SessionID, event.ToolUseID, "")
+			span.finishTool(event.ToolUseID, event.ToolName)
+			err = engine.ProcessPostToolUse(event)
+			span.end(err)
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 			}
 
@@ -223,7 +233,10 @@ func newHookStopCmd() *cobra.Command {
 				return nil
 			}
 
-			if err := engine.ProcessStop(); err != nil {
+			span := newHookTracer().startHook("stop", "", "", "")
+			err := engine.ProcessStop()
+			span.end(err)
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 			}
 
@@ -245,7 +258,13 @@ func newHookTaskCompletedCmd() *cobra.Command {
 				return fmt.Errorf("app not initialized")
 			}
 
-			engine := core.NewHookEngineWithOptions(App.BasePath, hookOptionsFromConfig())
+			// Each quality gate becomes a child span of this hook's span.
+			var span *hookSpan
+			opts := hookOptionsFromConfig()
+			opts.GateObserver = func(gate string, start, end time.Time, err error) {
+				span.observeGate(gate, start, end, err)
+			}
+			engine := core.NewHookEngineWithOptions(App.BasePath, opts)
 			if engine.PreventRecursion() {
 				return nil
 			}
@@ -255,7 +274,10 @@ func newHookTaskCompletedCmd() *cobra.Command {
 				return fmt.Errorf("failed to parse event: %w", err)
 			}
 
-			if err := engine.ProcessTaskCompleted(event); err != nil {
+			span = newHookTracer().startHook("task-completed", event.SessionID, "", event.TaskID)
+			err = engine.ProcessTaskCompleted(event)
+			span.end(err)
+			if err != nil {
 				return err
 			}
 
@@ -287,7 +309,11 @@ func newHookSessionEndCmd() *cobra.Command {
 				return fmt.Errorf("failed to parse event: %w", err)
 			}
 
-			if err := engine.ProcessSessionEnd(event); err != nil {
+			span := newHookTracer().startHook("session-end", event.SessionID, "", "")
+			err = engine.ProcessSessionEnd(event)
+			span.endSession(time.Duration(event.Duration * float64(time.Second)))
+			span.end(err)
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 			}
 
@@ -0,0 +1,367 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valter-silva-au/ai-dev-brain/internal/configenv"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// hookTracer turns hook invocations into OpenTelemetry spans when
+// tracing.enabled is set. A session is the root span, each tool call a child
+// of it, and each hook invocation a child of its tool call (or of the session
+// for stop / task-completed / session-end). A nil *hookTracer is valid and
+// traces nothing, so the hook commands call it unconditionally.
+//
+// A network exporter never runs inside a hook: spans go to a local spool and
+// ship starts a detached `adb hook trace-flush` to send them, so an
+// unreachable collector costs a hook nothing.
+type hookTracer struct {
+	exporter observability.SpanExporter
+	state    *observability.TraceStateStore
+	now      func() time.Time
+	ship     func() // nil: the exporter is local
+}
+
+// newHookTracer builds a tracer from config, or returns nil when tracing is
+// off or misconfigured (with a warning — tracing never fails a hook).
+func newHookTracer() *hookTracer {
+	if App == nil || App.MergedConfig == nil || App.MergedConfig.Global == nil {
+		return nil
+	}
+	cfg := App.MergedConfig.Global.Tracing
+	if !cfg.Enabled {
+		return nil
+	}
+	exporter, err := spanExporterFromConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tracing disabled: %v\n", err)
+		return nil
+	}
+	ht := &hookTracer{
+		exporter: exporter,
+		state:    observability.NewTraceStateStore(App.StatePath(statedir.FileTraceState)),
+		now:      time.Now,
+	}
+	if remote, ok := exporter.(*observability.OTLPHTTPSpanExporter); ok {
+		ht.exporter = observability.NewSpanSpool(App.StatePath(statedir.FileTraceSpool), remote.Service)
+		ht.ship = startTraceFlush
+	}
+	return ht
+}
+
+// startTraceFlush starts a detached `adb hook trace-flush` and returns
+// without waiting for it.
+func startTraceFlush() {
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: trace flush: %v\n", err)
+		return
+	}
+	cmd := exec.Command(exe, "hook", "trace-flush")
+	cmd.Env = os.Environ()
+	detachProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: trace flush: %v\n", err)
+		return
+	}
+	_ = cmd.Process.Release()
+}
+
+// traceFlushTimeout bounds one `adb hook trace-flush` run.
+const traceFlushTimeout = 2 * time.Minute
+
+// newHookTraceFlushCmd ships the spans hooks spooled for the otlphttp
+// exporter. Hooks start it detached; it is hidden from help.
+func newHookTraceFlushCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "trace-flush",
+		Short:  "Send spooled trace spans to the OTLP collector",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if App == nil || App.MergedConfig == nil || App.MergedConfig.Global == nil {
+				return nil
+			}
+			cfg := App.MergedConfig.Global.Tracing
+			if !cfg.Enabled {
+				return nil
+			}
+			exporter, err := spanExporterFromConfig(cfg)
+			if err != nil {
+				return err
+			}
+			remote, ok := exporter.(*observability.OTLPHTTPSpanExporter)
+			if !ok {
+				return nil
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), traceFlushTimeout)
+			defer cancel()
+			spool := observability.NewSpanSpool(App.StatePath(statedir.FileTraceSpool), remote.Service)
+			_, err = spool.Ship(ctx, remote.Post)
+			return err
+		},
+	}
+}
+
+// spanExporterFromConfig maps the tracing block onto an exporter.
+func spanExporterFromConfig(cfg models.TracingConfig) (observability.SpanExporter, error) {
+	service := cfg.ServiceName
+	if service == "" {
+		service = "adb"
+	}
+	switch cfg.Exporter {
+	case "", "file":
+		path := cfg.Path
+		if path == "" {
+			path = App.StatePath(statedir.FileTraces)
+		} else if !filepath.IsAbs(path) {
+			path = filepath.Join(App.BasePath, path)
+		}
+		return observability.NewFileSpanExporter(path, service), nil
+	case "otlphttp":
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+		}
+		if endpoint == "" {
+			if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+				endpoint = strings.TrimRight(base, "/") + "/v1/traces"
+			}
+		}
+		headers := make(map[string]string, len(cfg.Headers))
+		for k, v := range cfg.Headers {
+			headers[k] = configenv.Expand(v)
+		}
+		return observability.NewOTLPHTTPSpanExporter(endpoint, headers, service), nil
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q (want file or otlphttp)", cfg.Exporter)
+	}
+}
+
+// traceScope is where a hook's spans hang: the trace and the session span.
+type traceScope struct {
+	traceID    string
+	sessionID  string // parent for tool-call and session-level hook spans
+	sessionKey string // agent session id when adb owns the root span
+}
+
+// scope resolves the trace for a hook. A TRACEPARENT from the environment
+// (set by `adb task run-with-ruflo`, or any traced launcher) wins; otherwise
+// the agent's session id keys the trace and adb owns its root span; with
+// neither, the hook gets a trace of its own.
+func (ht *hookTracer) scope(sessionKey, taskID string) traceScope {
+	if traceID, parentID, ok := observability.ParseTraceparent(os.Getenv("TRACEPARENT")); ok {
+		return traceScope{traceID: traceID, sessionID: parentID}
+	}
+	if sessionKey != "" {
+		// The IDs derive from the key, so a state failure only costs the
+		// session span its start time.
+		sess, err := ht.state.Session(sessionKey, taskID, ht.now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: trace state: %v\n", err)
+			sess.TraceID = observability.TraceIDFromKey(sessionKey)
+			sess.RootSpanID = observability.SpanIDFromKey(sess.TraceID + "/session")
+		}
+		return traceScope{traceID: sess.TraceID, sessionID: sess.RootSpanID, sessionKey: sessionKey}
+	}
+	return traceScope{traceID: observability.NewTraceID()}
+}
+
+// toolSpanID is the span of one tool call, derived so the pre- and
+// post-tool-use processes agree on it.
+func (s traceScope) toolSpanID(toolUseID string) string {
+	return observability.SpanIDFromKey(s.traceID + "/tool/" + toolUseID)
+}
+
+// hookSpan is an in-flight hook invocation plus the spans it will export
+// alongside itself.
+type hookSpan struct {
+	tracer *hookTracer
+	scope  traceScope
+	span   observability.Span
+	extra  []observability.Span
+}
+
+// startHook opens the span for one `adb hook <name>` invocation. toolUseID
+// parents it under that tool call's span.
+func (ht *hookTracer) startHook(name, sessionKey, toolUseID, taskID string) *hookSpan {
+	if ht == nil {
+		return nil
+	}
+	if taskID == "" {
+		taskID = os.Getenv("ADB_TASK_ID")
+	}
+	sc := ht.scope(sessionKey, taskID)
+	parent := sc.sessionID
+	if toolUseID != "" {
+		parent = sc.toolSpanID(toolUseID)
+	}
+	return &hookSpan{
+		tracer: ht,
+		scope:  sc,
+		span: observability.Span{
+			TraceID:      sc.traceID,
+			SpanID:       observability.NewSpanID(),
+			ParentSpanID: parent,
+			Name:         "hook " + name,
+			Start:        ht.now(),
+			Attributes: map[string]interface{}{
+				"adb.hook":    name,
+				"adb.task_id": taskID,
+				"session.id":  sessionKey,
+			},
+		},
+	}
+}
+
+// startTool records the start of a tool call (pre-tool-use).
+func (hs *hookSpan) startTool(toolUseID, tool string) {
+	if hs == nil || toolUseID == "" {
+		return
+	}
+	hs.span.Attributes["tool.name"] = tool
+	key := hs.scope.traceID + "/" + toolUseID
+	if err := hs.tracer.state.StartTool(key, tool, hs.span.Start); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: trace state: %v\n", err)
+	}
+}
+
+// finishTool closes a tool call (post-tool-use) as a span under the session,
+// from its pre-tool-use start to now. Without a recorded start the span is
+// zero-length, so the post hook still has its parent.
+func (hs *hookSpan) finishTool(toolUseID, tool string) {
+	if hs == nil || toolUseID == "" {
+		return
+	}
+	hs.span.Attributes["tool.name"] = tool
+	start := hs.span.Start
+	key := hs.scope.traceID + "/" + toolUseID
+	if pending, ok, err := hs.tracer.state.FinishTool(key, hs.span.Start); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: trace state: %v\n", err)
+	} else if ok {
+		start = pending.StartedAt
+	}
+	hs.extra = append(hs.extra, observability.Span{
+		TraceID:      hs.scope.traceID,
+		SpanID:       hs.scope.toolSpanID(toolUseID),
+		ParentSpanID: hs.scope.sessionID,
+		Name:         "tool " + tool,
+		Start:        start,
+		End:          hs.span.Start,
+		Attributes:   map[string]interface{}{"tool.name": tool, "tool.use_id": toolUseID},
+		StatusCode:   observability.SpanStatusOK,
+	})
+}
+
+// observeGate is a core.HookEngineOptions.GateObserver recording each
+// task-completed quality gate as a child of the hook span.
+func (hs *hookSpan) observeGate(gate string, start, end time.Time, err error) {
+	if hs == nil {
+		return
+	}
+	outcome := "pass"
+	if err != nil {
+		outcome = "fail"
+	}
+	s := observability.Span{
+		TraceID:      hs.scope.traceID,
+		SpanID:       observability.NewSpanID(),
+		ParentSpanID: hs.span.SpanID,
+		Name:         "quality gate " + gate,
+		Start:        start,
+		End:          end,
+		Attributes:   map[string]interface{}{"adb.gate": gate, "adb.gate.outcome": outcome, "adb.task_id": hs.span.Attributes["adb.task_id"]},
+	}
+	s.SetError(err)
+	hs.extra = append(hs.extra, s)
+}
+
+// endSession closes the root span adb owns for this agent session
+// (session-end). duration is the agent-reported session length, used when
+// no hook recorded the session's start.
+func (hs *hookSpan) endSession(duration time.Duration) {
+	if hs == nil || hs.scope.sessionKey == "" {
+		return
+	}
+	now := hs.tracer.now()
+	sess, ok, err := hs.tracer.state.EndSession(hs.scope.sessionKey, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: trace state: %v\n", err)
+	}
+	start := now.Add(-duration)
+	if ok && (duration <= 0 || sess.StartedAt.Before(start)) {
+		start = sess.StartedAt
+	}
+	hs.extra = append(hs.extra, observability.Span{
+		TraceID:    hs.scope.traceID,
+		SpanID:     hs.scope.sessionID,
+		Name:       "agent session",
+		Start:      start,
+		End:        now,
+		Attributes: map[string]interface{}{"session.id": hs.scope.sessionKey, "adb.task_id": sess.TaskID},
+		StatusCode: observability.SpanStatusOK,
+	})
+}
+
+// end closes the hook span with the hook's outcome and exports it with
+// everything gathered along the way. Export failures only warn.
+func (hs *hookSpan) end(err error) {
+	if hs == nil {
+		return
+	}
+	hs.span.End = hs.tracer.now()
+	hs.span.SetError(err)
+	hs.tracer.export(append([]observability.Span{hs.span}, hs.extra...))
+}
+
+func (ht *hookTracer) export(spans []observability.Span) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := ht.exporter.ExportSpans(ctx, spans); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	if ht.ship != nil {
+		ht.ship()
+	}
+}
+
+// startAgentSession opens the root span for an agent `adb task run-with-ruflo`
+// dispatches and returns the TRACEPARENT the agent (and so its hooks) should
+// inherit. The returned finish exports the span.
+func (ht *hookTracer) startAgentSession(taskID, worktree, bin string) (traceparent string, finish func(error)) {
+	if ht == nil {
+		return "", func(error) {}
+	}
+	traceID, parent := observability.NewTraceID(), ""
+	if t, p, ok := observability.ParseTraceparent(os.Getenv("TRACEPARENT")); ok {
+		traceID, parent = t, p
+	}
+	span := observability.Span{
+		TraceID:      traceID,
+		SpanID:       observability.NewSpanID(),
+		ParentSpanID: parent,
+		Name:         "agent session",
+		Start:        ht.now(),
+		Attributes: map[string]interface{}{
+			"adb.task_id":   taskID,
+			"adb.worktree":  worktree,
+			"adb.agent.bin": bin,
+		},
+	}
+	return observability.FormatTraceparent(traceID, span.SpanID), func(err error) {
+		span.End = ht.now()
+		span.SetError(err)
+		ht.export([]observability.Span{span})
+	}
+}
@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// exportedSpan is the subset of an OTLP/JSON span the tests look at.
+type exportedSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Start        string `json:"startTimeUnixNano"`
+	Status       *struct {
+		Code int `json:"code"`
+	} `json:"status"`
+}
+
+// readExportedSpans flattens every request line in an OTLP/JSON file.
+func readExportedSpans(t *testing.T, path string) map[string]exportedSpan {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open traces: %v", err)
+	}
+	defer f.Close()
+	byName := make(map[string]exportedSpan)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 1<<20), 1<<20)
+	for sc.Scan() {
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []exportedSpan `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			t.Fatalf("parse trace line: %v", err)
+		}
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					byName[s.Name] = s
+				}
+			}
+		}
+	}
+	return byName
+}
+
+func newTestHookTracer(t *testing.T, clock *time.Time) (*hookTracer, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "traces.jsonl")
+	return &hookTracer{
+		exporter: observability.NewFileSpanExporter(path, "adb"),
+		state:    observability.NewTraceStateStore(filepath.Join(dir, "trace_state.yaml")),
+		now:      func() time.Time { return *clock },
+	}, path
+}
+
+// TestHookTracer_SessionToolAndHookSpansLink drives the hooks of one agent
+// session — each its own process in real life — and checks the spans form
+// session → tool call → hook, with task-completed gates under their hook.
+func TestHookTracer_SessionToolAndHookSpansLink(t *testing.T) {
+	t.Setenv("TRACEPARENT", "")
+	t.Setenv("ADB_TASK_ID", "TASK-00042")
+	clock := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ht, path := newTestHookTracer(t, &clock)
+
+	pre := ht.startHook("pre-tool-use", "sess-1", "toolu_1", "")
+	pre.startTool("toolu_1", "Edit")
+	clock = clock.Add(100 * time.Millisecond)
+	pre.end(nil)
+
+	clock = clock.Add(2 * time.Second)
+	post := ht.startHook("post-tool-use", "sess-1", "toolu_1", "")
+	post.finishTool("toolu_1", "Edit")
+	clock = clock.Add(50 * time.Millisecond)
+	post.end(nil)
+
+	done := ht.startHook("task-completed", "sess-1", "", "TASK-00042")
+	done.observeGate("go test", clock, clock.Add(time.Second), nil)
+	done.observeGate("go build", clock.Add(time.Second), clock.Add(2*time.Second), errors.New("undefined: x"))
+	clock = clock.Add(2 * time.Second)
+	done.end(errors.New("quality gate failed"))
+
+	clock = clock.Add(time.Second)
+	end := ht.startHook("session-end", "sess-1", "", "")
+	end.endSession(0)
+	end.end(nil)
+
+	spans := readExportedSpans(t, path)
+	session, tool := spans["agent session"], spans["tool Edit"]
+	if session.SpanID == "" || session.ParentSpanID != "" {
+		t.Fatalf("session span = %+v, want a root", session)
+	}
+	if session.Start != "1772442000000000000" {
+		t.Errorf("session starts %s, want the first hook's start", session.Start)
+	}
+	if tool.ParentSpanID != session.SpanID || tool.Start != session.Start {
+		t.Errorf("tool span = %+v, want a child of the session starting at pre-tool-use", tool)
+	}
+	for _, name := range []string{"hook pre-tool-use", "hook post-tool-use"} {
+		if spans[name].ParentSpanID != tool.SpanID {
+			t.Errorf("%s parent = %s, want tool span %s", name, spans[name].ParentSpanID, tool.SpanID)
+		}
+	}
+	hook := spans["hook task-completed"]
+	if hook.ParentSpanID != session.SpanID || hook.Status == nil || hook.Status.Code != observability.SpanStatusError {
+		t.Errorf("task-completed span = %+v", hook)
+	}
+	if g := spans["quality gate go test"]; g.ParentSpanID != hook.SpanID || g.Status.Code != observability.SpanStatusOK {
+		t.Errorf("go test gate = %+v", g)
+	}
+	if g := spans["quality gate go build"]; g.Status.Code != observability.SpanStatusError {
+		t.Errorf("go build gate = %+v, want an error status", g)
+	}
+	for name, s := range spans {
+		if s.TraceID != session.TraceID {
+			t.Errorf("%s is in trace %s, want %s", name, s.TraceID, session.TraceID)
+		}
+	}
+}
+
+// TestHookTracer_JoinsInheritedTraceparent: under `adb task run-with-ruflo`
+// the agent's hooks hang off the dispatch span instead of starting a session.
+func TestHookTracer_JoinsInheritedTraceparent(t *testing.T) {
+	clock := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ht, path := newTestHookTracer(t, &clock)
+	t.Setenv("TRACEPARENT", "")
+
+	traceparent, finish := ht.startAgentSession("TASK-00042", "/wt", "claude-flow")
+	t.Setenv("TRACEPARENT", traceparent)
+	hook := ht.startHook("session-end", "sess-1", "", "")
+	hook.endSession(time.Minute)
+	hook.end(nil)
+	clock = clock.Add(time.Minute)
+	finish(errors.New("exit status 1"))
+
+	spans := readExportedSpans(t, path)
+	root := spans["agent session"]
+	if root.Status == nil || root.Status.Code != observability.SpanStatusError {
+		t.Errorf("dispatch span = %+v, want an error status", root)
+	}
+	if h := spans["hook session-end"]; h.TraceID != root.TraceID || h.ParentSpanID != root.SpanID {
+		t.Errorf("hook span = %+v, want a child of %s", h, root.SpanID)
+	}
+	if len(spans) != 2 {
+		t.Errorf("got %d spans, want only the dispatch and hook spans", len(spans))
+	}
+}
+
+func TestNewHookTracer_FromConfig(t *testing.T) {
+	app, cleanup := setupEventsTest(t)
+	defer cleanup()
+
+	if newHookTracer() != nil {
+		t.Fatal("tracing should be off by default")
+	}
+	if app.MergedConfig == nil || app.MergedConfig.Global == nil {
+		t.Skip("app has no global config")
+	}
+	app.MergedConfig.Global.Tracing = models.TracingConfig{Enabled: true}
+	ht := newHookTracer()
+	if ht == nil {
+		t.Fatal("tracing enabled but no tracer")
+	}
+	if fe, ok := ht.exporter.(*observability.FileSpanExporter); !ok || fe.Path != app.StatePath(statedir.FileTraces) {
+		t.Errorf("default exporter = %#v, want a file exporter at .adb/traces.jsonl", ht.exporter)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/")
+	t.Setenv("OTLP_TOKEN", "secret")
+	exp, err := spanExporterFromConfig(models.TracingConfig{Exporter: "otlphttp", Headers: map[string]string{"Authorization": "$OTLP_TOKEN"}})
+	if err != nil {
+		t.Fatalf("spanExporterFromConfig: %v", err)
+	}
+	he := exp.(*observability.OTLPHTTPSpanExporter)
+	if he.Endpoint != "http://collector:4318/v1/traces" || he.Headers["Authorization"] != "secret" {
+		t.Errorf("otlphttp exporter = %+v", he)
+	}
+	// Hooks spool for the otlphttp exporter and hand shipping to a flush.
+	app.MergedConfig.Global.Tracing = models.TracingConfig{Enabled: true, Exporter: "otlphttp"}
+	if ht := newHookTracer(); ht == nil || ht.ship == nil {
+		t.Errorf("otlphttp tracer = %+v, want a spool and a shipper", ht)
+	} else if sp, ok := ht.exporter.(*observability.SpanSpool); !ok || sp.Path != app.StatePath(statedir.FileTraceSpool) {
+		t.Errorf("otlphttp hook exporter = %#v, want the spool at .adb/trace_spool.jsonl", ht.exporter)
+	}
+
+	app.MergedConfig.Global.Tracing.Exporter = "zipkin"
+	if newHookTracer() != nil {
+		t.Error("an unknown exporter should disable tracing")
+	}
+}
@@ -2,14 +2,20 @@ package cli
 
 import (
 	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 
 	"github.com/valter-silva-au/ai-dev-brain/internal/mcpserver"
+	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
 )
 
 // newMCPServeCmd creates the 'mcp serve' command, which runs ADB as a
-// Model Context Protocol server over stdio. This is the command MCP clients
+// Model Context Protocol server over stdio (or, with --http, over the
+// network for a shared workspace). This is the command MCP clients
 // (Claude Code, Claude Desktop) launch via their server registration:
 //
 //	{ "command": "adb", "args": ["mcp", "serve"], "type": "stdio" }
@@ -18,32 +24,73 @@ import (
 // command, so the workspace it operates on is resolved the usual way:
 // ADB_HOME, then a walked-up .taskconfig/.taskrc, then the cwd.
 func newMCPServeCmd() *cobra.Command {
+	var (
+		httpAddr  string
+		tokenFile string
+	)
 	cmd := &cobra.Command{
-		Use:   "serve",
-		Short: "Run adb as an MCP server over stdio",
+		Use:   "serve [--http :8765] [--token-file <f>]",
+		Short: "Run adb as an MCP server over stdio or HTTP",
 		Long: `Run AI Dev Brain as a Model Context Protocol (MCP) server over stdio.
 
 Exposes the task lifecycle (list, create, start, close, update, and bulk
 start-all/close-all) as MCP tools so clients like Claude Code can manage the
-workspace's tickets natively.
+workspace's tickets natively. Workspace documents are also published as
+resources (adb://task/<id>/context, adb://task/<id>/notes, adb://adr/<n>,
+adb://initiative/<id>/gate, adb://wiki/<path>) with change notifications,
+and resume_task / write_handoff / propose_adr are offered as prompts.
+
+With --http the server listens on the network instead, so one shared
+workspace can serve several agents and editors at once:
+
+  /mcp              streamable HTTP transport
+  /sse, /message    SSE transport (older clients)
+
+Every request needs an "Authorization: Bearer <token>" header with a token
+minted by 'adb mcp token add'. A read token sees only the read-only tools
+(plus every resource and prompt); a write token gets everything. Tokens are
+re-read when the token file changes, so adding or revoking one needs no
+restart. Each request is logged to the event log as mcp.request. Concurrent
+writes are serialised in the server, and each backlog write also holds the
+backlog's file lock, so CLI commands run on the same box stay safe.
 
 The workspace is resolved like every adb command: the ADB_HOME env var wins,
 otherwise adb walks up from the working directory looking for .taskconfig or
 .taskrc, falling back to the current directory. When launched by an MCP client
 the working directory is often unpredictable, so set ADB_HOME in the server
-registration to pin the workspace.`,
+registration to pin the workspace.
+
+Examples:
+  adb mcp serve
+  adb mcp token add ci-agent --scope write
+  adb mcp serve --http :8765`,
 		Args:         cobra.NoArgs,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if App == nil {
 				return fmt.Errorf("app not initialized")
 			}
-			// stdio is the MCP transport here, so nothing may write to
-			// stdout except the protocol itself. ServeStdio blocks until
-			// the client disconnects.
-			return mcpserver.Serve(App, Version)
+			if httpAddr == "" {
+				// stdio is the MCP transport here, so nothing may write to
+				// stdout except the protocol itself. ServeStdio blocks until
+				// the client disconnects.
+				return mcpserver.Serve(App, Version)
+			}
+			if tokenFile == "" {
+				tokenFile = App.StatePath(statedir.FileMCPTokens)
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			ln, err := net.Listen("tcp", httpAddr)
+			if err != nil {
+				return fmt.Errorf("listen on %s: %w", httpAddr, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Serving MCP on http://%s/mcp (SSE: /sse) for %s\n", ln.Addr(), App.BasePath)
+			return mcpserver.ServeHTTP(ctx, ln, App, Version, tokenFile)
 		},
 	}
+	cmd.Flags().StringVar(&httpAddr, "http", "", "Serve over HTTP on this address (e.g. :8765) instead of stdio")
+	cmd.Flags().StringVar(&tokenFile, "token-file", "", "Bearer-token file for --http (default .adb/"+statedir.FileMCPTokens+")")
 
 	return cmd
 }
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/mcpserver"
+	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
+)
+
+// newMCPTokenCmd creates `adb mcp token`, which manages the bearer tokens
+// `adb mcp serve --http` accepts.
+func newMCPTokenCmd() *cobra.Command {
+	var tokenFile string
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage bearer tokens for the HTTP MCP server",
+		Long: `Manage the bearer tokens 'adb mcp serve --http' accepts.
+
+Each token has a name (shown in the mcp.request event log) and a scope: read
+tokens see only the read-only tools plus every resource and prompt; write
+tokens get the full tool set. Only a SHA-256 of each token is stored, in
+.adb/` + statedir.FileMCPTokens + ` (owner-only), so a lost token is revoked
+and replaced rather than recovered.`,
+	}
+	cmd.PersistentFlags().StringVar(&tokenFile, "token-file", "", "Token file (default .adb/"+statedir.FileMCPTokens+")")
+	path := func() (string, error) {
+		if tokenFile != "" {
+			return tokenFile, nil
+		}
+		if App == nil {
+			return "", fmt.Errorf("app not initialized")
+		}
+		return App.StatePath(statedir.FileMCPTokens), nil
+	}
+
+	var scope string
+	add := &cobra.Command{
+		Use:   "add <name> [--scope read|write]",
+		Short: "Mint a token and print it once",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sc, err := mcpserver.ParseScope(scope)
+			if err != nil {
+				return err
+			}
+			p, err := path()
+			if err != nil {
+				return err
+			}
+			f, err := mcpserver.LoadTokens(p)
+			if err != nil {
+				return err
+			}
+			secret, err := f.Add(args[0], sc)
+			if err != nil {
+				return err
+			}
+			if err := f.Save(p); err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "✓ Created %s token %q. It is shown only once:\n\n  %s\n\n", sc, args[0], secret)
+			fmt.Fprintln(out, "Send it as \"Authorization: Bearer <token>\".")
+			return nil
+		},
+	}
+	add.Flags().StringVar(&scope, "scope", string(mcpserver.ScopeRead), "Token scope: read or write")
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List token names and scopes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := path()
+			if err != nil {
+				return err
+			}
+			f, err := mcpserver.LoadTokens(p)
+			if err != nil {
+				return err
+			}
+			if len(f.Tokens) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No MCP tokens. Create one with: adb mcp token add <name>")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSCOPE\tCREATED")
+			for _, t := range f.Tokens {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, t.Scope, t.CreatedAt.Local().Format("2006-01-02 15:04"))
+			}
+			return w.Flush()
+		},
+	}
+
+	revoke := &cobra.Command{
+		Use:   "revoke <name>",
+		Short: "Revoke a token; a running server stops accepting it at once",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := path()
+			if err != nil {
+				return err
+			}
+			f, err := mcpserver.LoadTokens(p)
+			if err != nil {
+				return err
+			}
+			if !f.Revoke(args[0]) {
+				return fmt.Errorf("no token named %q", args[0])
+			}
+			if err := f.Save(p); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ Revoked token %q\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.AddCommand(add, list, revoke)
+	return cmd
+}
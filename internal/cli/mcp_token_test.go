@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal"
+	"github.com/valter-silva-au/ai-dev-brain/internal/mcpserver"
+	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
+)
+
+func TestMCPTokenCLI_AddListRevoke(t *testing.T) {
+	app, err := internal.NewApp(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	defer app.Cleanup()
+	App = app
+
+	run := func(args ...string) (string, error) {
+		cmd := newMCPTokenCmd()
+		cmd.SetArgs(args)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("add", "agent", "--scope", "write")
+	if err != nil {
+		t.Fatalf("add: %v\n%s", err, out)
+	}
+	var secret string
+	for _, field := range strings.Fields(out) {
+		if strings.HasPrefix(field, "adb_") {
+			secret = field
+		}
+	}
+	f, err := mcpserver.LoadTokens(app.StatePath(statedir.FileMCPTokens))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok, ok := f.Lookup(secret); !ok || tok.Scope != mcpserver.ScopeWrite {
+		t.Fatalf("printed token %q does not match the stored one (%+v)", secret, f.Tokens)
+	}
+
+	if _, err := run("add", "agent"); err == nil {
+		t.Error("a duplicate name should be an error")
+	}
+	if _, err := run("add", "ci", "--scope", "admin"); err == nil {
+		t.Error("an unknown scope should be an error")
+	}
+	if out, _ := run("list"); !strings.Contains(out, "agent") || !strings.Contains(out, "write") || strings.Contains(out, secret) {
+		t.Errorf("list = %q", out)
+	}
+	if out, err := run("revoke", "agent"); err != nil || !strings.Contains(out, "Revoked") {
+		t.Errorf("revoke = %q, %v", out, err)
+	}
+	if _, err := run("revoke", "agent"); err == nil {
+		t.Error("revoking a missing token should be an error")
+	}
+}
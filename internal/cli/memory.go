@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -36,12 +37,17 @@ func NewMemoryCmd() *cobra.Command {
 Stores and searches semantically-embedded records keyed by (namespace, key).
 Default-off; enable by passing --db-path or via hooks.memory.enabled in
 .taskconfig. Embeddings come from a pluggable provider (fake for tests,
-OpenAI-compatible HTTP, or Ollama).`,
+OpenAI-compatible HTTP, Ollama, or local).
+
+The local provider runs offline from a static token-embedding table
+(word2vec/fastText .vec, optionally .gz; a model2vec-style distillation of
+a sentence-embedding model exports to one). It does not run transformer
+models: ONNX files are not supported.`,
 	}
 
 	memCmd.PersistentFlags().StringVar(&memoryDBPath, "db-path", "", "path to SQLite file (default: <workspace>/.adb/memory.sqlite)")
-	memCmd.PersistentFlags().StringVar(&memoryProvider, "provider", "fake", "embedding provider: fake | openai | ollama")
-	memCmd.PersistentFlags().StringVar(&memoryModel, "model", "", "embedding model (provider-specific)")
+	memCmd.PersistentFlags().StringVar(&memoryProvider, "provider", "fake", "embedding provider: fake | openai | ollama | local")
+	memCmd.PersistentFlags().StringVar(&memoryModel, "model", "", "embedding model (provider-specific; local: path to a static .vec[.gz] table, not ONNX; empty for the lexical fallback)")
 	memCmd.PersistentFlags().StringVar(&memoryEndpoint, "endpoint", "", "provider endpoint URL (openai: full URL incl /v1/embeddings; ollama: base URL)")
 	memCmd.PersistentFlags().IntVar(&memoryDim, "dim", 64, "embedding dimensions (must match provider/model)")
 	memCmd.PersistentFlags().StringVar(&memoryAPIKey, "api-key", "", "API key (may reference env var: $OPENAI_API_KEY)")
@@ -51,6 +57,8 @@ OpenAI-compatible HTTP, or Ollama).`,
 	memCmd.AddCommand(newMemoryDeleteCmd())
 	memCmd.AddCommand(newMemoryListCmd())
 	memCmd.AddCommand(newMemoryIndexCmd())
+	memCmd.AddCommand(newMemoryGCCmd())
+	memCmd.AddCommand(newMemoryReembedCmd())
 	memCmd.AddCommand(newMemoryExportCmd())
 	memCmd.AddCommand(newMemoryImportCmd())
 	return memCmd
@@ -66,6 +74,9 @@ OpenAI-compatible HTTP, or Ollama).`,
 // default db (<workspace>/.adb/memory.sqlite) that search_knowledge reads. This
 // is the MANUAL, full-workspace counterpart to the AUTOMATIC per-completion
 // indexing done by the memory hook, which is gated by hooks.memory.enabled.
+//
+// Markdown files are indexed as heading-aware chunks; a rerun re-embeds only
+// the chunks whose content hash changed and deletes those that disappeared.
 func newMemoryIndexCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "index",
@@ -89,11 +100,17 @@ func newMemoryIndexCmd() *cobra.Command {
 			fmt.Fprintf(cmd.OutOrStdout(),
 				"✓ Indexed %d knowledge file(s) across %d ticket(s) + %d graph edge(s) into the vector store.\n",
 				stats.Files, stats.Tickets, stats.Edges)
+			fmt.Fprintf(cmd.OutOrStdout(), "  %d chunk(s) embedded, %d moved, %d unchanged, %d stale record(s) removed.\n",
+				stats.Chunks, stats.Moved, stats.Unchanged, stats.Removed)
 			return nil
 		},
 	}
 }
 
+// The store implements core's incremental-index seam, so `adb memory index`
+// reruns skip unchanged chunks.
+var _ core.MemoryChunkStore = (*memory.SQLiteStore)(nil)
+
 // openStoreFromFlags constructs a memory.SQLiteStore from the current
 // flag values. It is called from each subcommand's RunE so that flag
 // parsing happens first (some flags only take effect after Cobra has
@@ -163,19 +180,66 @@ func newMemoryStoreCmd() *cobra.Command {
 func newMemorySearchCmd() *cobra.Command {
 	var k int
 	var asJSON bool
+	var mode, nsGlob, since, until string
+	var where []string
+	var minScore float32
 	cmd := &cobra.Command{
-		Use:   "search <namespace> <query>",
-		Short: "Semantic search within a namespace",
-		Args:  cobra.ExactArgs(2),
+		Use:   "search [namespace] <query>",
+		Short: "Lexical, semantic or hybrid search across one or more namespaces",
+		Long: `Search the memory store.
+
+With two arguments the first is an exact namespace. With one, the query
+runs across every namespace matching --ns (a glob: 'tickets/*',
+'sessions/*'; default all), and the hits are ranked together.
+
+--mode lexical ranks by BM25 over the stored text, so exact identifiers
+(ticket IDs, function names, error strings) match as written. --mode vector
+ranks by embedding similarity. --mode hybrid fuses the two rankings with
+reciprocal rank fusion, and is the default when a real embedder is
+configured; with the fake embedder the default is lexical.
+
+Filters apply before ranking, so a small namespace is never crowded out by
+a large one: --where key=value keeps records whose metadata matches
+(repeatable, or comma-separated), --since/--until bound the last update
+(a duration back from now like 30d or 12h, or a date like 2026-01-31), and
+--min-score drops weak hits: it floors the ranker's own score (cosine
+similarity for vector, BM25 mapped to 0-1 for lexical), and in hybrid mode
+applies to each ranking before fusion, keeping a hit that clears it in one.
+
+Examples:
+  adb memory search tickets/TASK-00042 "retry backoff"
+  adb memory search --ns 'tickets/*' --where source=ticket-knowledge "webhook"
+  adb memory search --since 30d --min-score 0.3 "flaky test"`,
+		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			ns, query := args[0], args[1]
+			q := memory.Query{Namespace: nsGlob, Text: args[len(args)-1], K: k, MinScore: minScore}
+			if len(args) == 2 {
+				if nsGlob != "" {
+					return fmt.Errorf("pass the namespace either as an argument or with --ns, not both")
+				}
+				q.Namespace = args[0]
+			}
+			var err error
+			if q.Mode, err = memory.ParseSearchMode(mode); err != nil {
+				return err
+			}
+			if len(where) > 0 {
+				q.Where = parseMeta(where)
+			}
+			now := time.Now()
+			if q.Since, err = parseSearchTime(since, now); err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			if q.Until, err = parseSearchTime(until, now); err != nil {
+				return fmt.Errorf("--until: %w", err)
+			}
 			ctx := cmd.Context()
 			store, err := openStoreFromFlags(ctx)
 			if err != nil {
 				return err
 			}
 			defer store.Close()
-			hits, err := store.Search(ctx, ns, query, k)
+			hits, err := store.SearchMulti(ctx, q)
 			if err != nil {
 				return err
 			}
@@ -185,8 +249,15 @@ func newMemorySearchCmd() *cobra.Command {
 				return enc.Encode(hits)
 			}
 			for i, h := range hits {
-				fmt.Fprintf(cmd.OutOrStdout(), "[%d] %s/%s  score=%.4f\n    %s\n",
-					i+1, h.Namespace, h.Key, h.Score, truncate(h.Content, 120))
+				fmt.Fprintf(cmd.OutOrStdout(), "[%d] %s/%s  score=%.4f\n", i+1, h.Namespace, h.Key, h.Score)
+				if h.Doc() != h.Key {
+					fmt.Fprintf(cmd.OutOrStdout(), "    in %s", h.Doc())
+					if heading := h.Meta[memory.MetaHeading]; heading != "" {
+						fmt.Fprintf(cmd.OutOrStdout(), " › %s", heading)
+					}
+					fmt.Fprintln(cmd.OutOrStdout())
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "    %s\n", truncate(h.Content, 120))
 			}
 			if len(hits) == 0 {
 				fmt.Fprintln(cmd.OutOrStdout(), "(no hits)")
@@ -196,9 +267,31 @@ func newMemorySearchCmd() *cobra.Command {
 	}
 	cmd.Flags().IntVar(&k, "k", 5, "number of results to return")
 	cmd.Flags().BoolVar(&asJSON, "json", false, "output as JSON array")
+	cmd.Flags().StringVar(&mode, "mode", "", "ranking: lexical | vector | hybrid (default hybrid, or lexical with the fake embedder)")
+	cmd.Flags().StringVar(&nsGlob, "ns", "", "namespace glob to search, e.g. 'tickets/*' (default: all namespaces)")
+	cmd.Flags().StringSliceVar(&where, "where", nil, "metadata filter key=value (repeatable)")
+	cmd.Flags().StringVar(&since, "since", "", "only records updated since: a duration back (30d, 12h) or a date (2006-01-02)")
+	cmd.Flags().StringVar(&until, "until", "", "only records updated before: a duration back or a date")
+	cmd.Flags().Float32Var(&minScore, "min-score", 0, "drop hits the ranker scores below this (0-1: cosine for vector, mapped BM25 for lexical; hybrid floors each before fusion)")
 	return cmd
 }
 
+// parseSearchTime reads a --since/--until bound: a duration back from now
+// (30d, 12h) or a calendar date. Empty means unbounded.
+func parseSearchTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	d, err := parseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a duration (30d, 12h) nor a date (2006-01-02)", s)
+	}
+	return now.Add(-d), nil
+}
+
 func newMemoryDeleteCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "delete <namespace> <key>",
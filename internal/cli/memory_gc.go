@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/core"
+	"github.com/valter-silva-au/ai-dev-brain/internal/memory"
+)
+
+// The store implements core's lifecycle seam, which `adb memory gc` and the
+// task archive/delete hook drive.
+var _ core.MemoryLifecycleStore = (*memory.SQLiteStore)(nil)
+
+// newMemoryGCCmd builds `adb memory gc` — the sweep that reconciles the
+// vector store with backlog.yaml. Archive and delete already move or purge a
+// task's namespaces as they happen; gc catches everything they could not:
+// namespaces left behind by tasks deleted before that hook existed or removed
+// from backlog.yaml by hand, namespaces whose task was archived or restored
+// while the store was unavailable, and records past a retention rule.
+func newMemoryGCCmd() *cobra.Command {
+	var dryRun, asJSON bool
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Purge orphaned namespaces, archive cold ones and apply retention",
+		Long: `Reconcile the memory store with the backlog.
+
+A namespace belongs to a task when it is tickets/<id> or wiki/<id>, or is a
+sessions/<session-id> namespace whose records carry task_id=<id>; the same
+holds under archive/. For each of them gc:
+
+  purge    the namespace when the task is no longer in backlog.yaml
+  archive  moves it under archive/ when the task is archived
+  restore  moves it back out of archive/ when the task is not
+
+It then expires records older than the hooks.memory.retention rule with the
+longest matching namespace prefix, e.g.
+
+  hooks:
+    memory:
+      retention:
+        - prefix: sessions/
+          max_age: 30d
+        - prefix: archive/
+          max_age: 180d
+
+--dry-run prints the plan and changes nothing.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			store, err := openStoreFromFlags(ctx)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			ml, err := App.NewMemoryLifecycle(store)
+			if err != nil {
+				return err
+			}
+			actions, err := ml.Plan(ctx)
+			if err != nil {
+				return err
+			}
+
+			if actions == nil {
+				actions = []core.MemoryGCAction{}
+			}
+			out := cmd.OutOrStdout()
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			if dryRun {
+				if asJSON {
+					return enc.Encode(actions)
+				}
+				printGCPlan(cmd, actions)
+				fmt.Fprintf(out, "(dry run) %d action(s); nothing changed.\n", len(actions))
+				return nil
+			}
+
+			removed, moved, err := ml.Apply(ctx, actions)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return enc.Encode(map[string]any{"actions": actions, "removed": removed, "moved": moved})
+			}
+			printGCPlan(cmd, actions)
+			fmt.Fprintf(out, "✓ %d record(s) removed, %d moved.\n", removed, moved)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would change without changing it")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "output the plan (and result) as JSON")
+	return cmd
+}
+
+// printGCPlan lists a gc plan one action per line.
+func printGCPlan(cmd *cobra.Command, actions []core.MemoryGCAction) {
+	out := cmd.OutOrStdout()
+	if len(actions) == 0 {
+		fmt.Fprintln(out, "Nothing to collect.")
+		return
+	}
+	for _, a := range actions {
+		target := a.Namespace
+		if a.Target != "" {
+			target += " → " + a.Target
+		}
+		fmt.Fprintf(out, "%-8s %s  (%d record(s)): %s\n", a.Kind, target, a.Records, a.Reason)
+	}
+}
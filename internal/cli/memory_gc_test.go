@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal"
+	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// TestMemoryCLI_GC: --dry-run reports orphaned and archived namespaces
+// without touching them; a real run purges and moves them.
+func TestMemoryCLI_GC(t *testing.T) {
+	tmp := t.TempDir()
+	app, err := internal.NewApp(tmp)
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	defer app.Cleanup()
+	App = app
+	memoryDBPath = filepath.Join(tmp, ".adb_memory.sqlite")
+	memoryProvider, memoryDim, memoryModel, memoryEndpoint, memoryAPIKey = "fake", 64, "", "", ""
+
+	for _, task := range []models.Task{
+		{ID: "TASK-00001", Title: "live", Status: models.TaskStatusInProgress},
+		{ID: "TASK-00002", Title: "old", Status: models.TaskStatusArchived},
+	} {
+		if err := app.BacklogManager.AddTask(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	store, err := openStoreFromFlags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Upsert(ctx, "tickets/TASK-00001", "notes.md", "live notes", nil)
+	_ = store.Upsert(ctx, "tickets/TASK-00002", "notes.md", "old notes", nil)
+	_ = store.Upsert(ctx, "tickets/TASK-00099", "notes.md", "deleted notes", nil)
+	_ = store.Upsert(ctx, "sessions/S-1", "transcript", "deleted session", map[string]string{"task_id": "TASK-00099"})
+	_ = store.Close()
+
+	run := func(args ...string) (string, error) {
+		cmd := newMemoryGCCmd()
+		cmd.SetContext(ctx)
+		cmd.SetArgs(args)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	namespaces := func() string {
+		store, err := openStoreFromFlags(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		names, _ := store.ListNamespaces(ctx)
+		return strings.Join(names, " ")
+	}
+
+	out, err := run("--dry-run")
+	if err != nil {
+		t.Fatalf("gc --dry-run: %v", err)
+	}
+	for _, want := range []string{
+		"purge    sessions/S-1", "purge    tickets/TASK-00099", "task TASK-00099 is not in the backlog",
+		"archive  tickets/TASK-00002 → archive/tickets/TASK-00002", "(dry run) 3 action(s)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dry run missing %q:\n%s", want, out)
+		}
+	}
+	if got := namespaces(); got != "sessions/S-1 tickets/TASK-00001 tickets/TASK-00002 tickets/TASK-00099" {
+		t.Fatalf("dry run changed the store: %s", got)
+	}
+
+	if out, err := run(); err != nil || !strings.Contains(out, "2 record(s) removed, 1 moved") {
+		t.Fatalf("gc: %v\n%s", err, out)
+	}
+	if got := namespaces(); got != "archive/tickets/TASK-00002 tickets/TASK-00001" {
+		t.Errorf("after gc: %s", got)
+	}
+	if out, _ := run("--dry-run"); !strings.Contains(out, "Nothing to collect.") {
+		t.Errorf("second gc should find nothing:\n%s", out)
+	}
+}
+
+// TestMemoryLifecycleHook_DeletePurgesNamespace: the App wires the memory
+// hook into the TaskManager, so deleting a task drops its namespace from
+// the workspace store.
+func TestMemoryLifecycleHook_DeletePurgesNamespace(t *testing.T) {
+	tmp := t.TempDir()
+	app, err := internal.NewApp(tmp)
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	defer app.Cleanup()
+	App = app
+	memoryDBPath = ""
+	memoryProvider, memoryDim, memoryModel, memoryEndpoint, memoryAPIKey = "fake", 64, "", "", ""
+	if err := os.MkdirAll(filepath.Dir(app.StatePath(statedir.FileMemoryDB)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.BacklogManager.AddTask(models.Task{ID: "TASK-00003", Title: "gone", Status: models.TaskStatusBacklog}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	store, err := openStoreFromFlags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Upsert(ctx, "tickets/TASK-00003", "notes.md", "soon deleted", nil)
+	_ = store.Upsert(ctx, "tickets/TASK-00004", "notes.md", "unrelated", nil)
+	_ = store.Close()
+
+	if err := app.TaskManager.Delete("TASK-00003"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	store, err = openStoreFromFlags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	names, _ := store.ListNamespaces(ctx)
+	if strings.Join(names, " ") != "tickets/TASK-00004" {
+		t.Errorf("namespaces after delete = %v", names)
+	}
+}
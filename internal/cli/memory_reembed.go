@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/memory"
+	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
+)
+
+// newMemoryReembedCmd builds `adb memory reembed` — the migration path when
+// the embedding model changes. The store pins the embedder it was built
+// with and refuses any other, so switching models used to mean deleting
+// the database and re-indexing from scratch, losing session transcripts
+// and anything stored by hand. reembed carries every record over instead.
+func newMemoryReembedCmd() *cobra.Command {
+	var to string
+	var rate float64
+	cmd := &cobra.Command{
+		Use:   "reembed --to provider[:model]",
+		Short: "Re-embed the store with a new embedding provider/model",
+		Long: `Migrate the memory store to a new embedding provider or model.
+
+Every record is embedded again with the target into a shadow table; once
+all of them are done the new vectors replace the old ones in a single
+transaction, so the store is never left half-migrated. Interrupting the
+run (Ctrl-C, provider outage) keeps the finished records; rerun the same
+command to resume. --dim, --endpoint and --api-key describe the target.
+The old provider is not contacted.
+
+  adb memory reembed --to ollama:nomic-embed-text --dim 768
+  adb memory reembed --to openai:text-embedding-3-small --dim 1536 --rate 5
+
+Stop other adb processes that write to the store first, and afterwards
+point hooks.memory.embedder at the new provider.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if App == nil {
+				return fmt.Errorf("app not initialised")
+			}
+			provider, model, _ := strings.Cut(to, ":")
+			if provider == "" {
+				return fmt.Errorf("--to is required (provider[:model])")
+			}
+			target, err := memory.NewEmbedder(memory.EmbedderConfig{
+				Provider: provider,
+				Model:    model,
+				Endpoint: memoryEndpoint,
+				APIKey:   memoryAPIKey,
+				Dim:      memoryDim,
+			})
+			if err != nil {
+				return fmt.Errorf("build target embedder: %w", err)
+			}
+			dbPath := memoryDBPath
+			if dbPath == "" {
+				dbPath = App.StatePath(statedir.FileMemoryDB)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			errOut := cmd.ErrOrStderr()
+			step := 0
+			stats, err := memory.Reembed(ctx, dbPath, target, memory.ReembedOptions{
+				RatePerSecond: rate,
+				Progress: func(done, total int) {
+					// Report roughly every 10% so a large store does not
+					// flood the terminal.
+					if pct := done * 10 / total; pct > step || done == total {
+						step = pct
+						fmt.Fprintf(errOut, "  %d/%d record(s) embedded\n", done, total)
+					}
+				},
+			})
+			if err != nil {
+				if errors.Is(err, ctx.Err()) || stats.Embedded > 0 {
+					fmt.Fprintf(errOut, "Re-embed stopped after %d record(s); rerun the same command to resume.\n", stats.Embedded)
+				}
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "✓ Re-embedded %d record(s) (%d resumed): %s → %s.\n",
+				stats.Total, stats.Resumed, stats.From, stats.To)
+			fmt.Fprintf(out, "  Set hooks.memory.embedder to provider %q", provider)
+			if model != "" {
+				fmt.Fprintf(out, ", model %q", model)
+			}
+			fmt.Fprintf(out, ", dim %d so adb opens the store with it.\n", target.Dimensions())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&to, "to", "", "target embedder as provider[:model], e.g. ollama:nomic-embed-text")
+	cmd.Flags().Float64Var(&rate, "rate", 0, "max embedding requests per second (0 = unlimited)")
+	return cmd
+}
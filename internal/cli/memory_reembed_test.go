@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal"
+)
+
+// TestMemoryCLI_Reembed migrates a 16-dim store to a 32-dim one; the store
+// then opens with the new dimension only.
+func TestMemoryCLI_Reembed(t *testing.T) {
+	tmp := t.TempDir()
+	app, err := internal.NewApp(tmp)
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	defer app.Cleanup()
+	App = app
+	memoryDBPath = filepath.Join(tmp, ".adb_memory.sqlite")
+	memoryProvider, memoryDim, memoryModel, memoryEndpoint, memoryAPIKey = "fake", 16, "", "", ""
+
+	ctx := context.Background()
+	store, err := openStoreFromFlags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Upsert(ctx, "tickets/TASK-00001", "notes.md", "first note", nil)
+	_ = store.Upsert(ctx, "tickets/TASK-00001", "design.md", "second note", nil)
+	_ = store.Close()
+
+	run := func(args ...string) (string, error) {
+		cmd := newMemoryReembedCmd()
+		cmd.SetContext(ctx)
+		cmd.SetArgs(args)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run(); err == nil {
+		t.Error("missing --to should be an error")
+	}
+	memoryDim = 32
+	out, err := run("--to", "fake", "--rate", "1000")
+	if err != nil {
+		t.Fatalf("reembed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"2/2 record(s) embedded", "Re-embedded 2 record(s) (0 resumed): fake (16 dims) → fake (32 dims)", `provider "fake", dim 32`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	store, err = openStoreFromFlags(ctx)
+	if err != nil {
+		t.Fatalf("open with the new dimension: %v", err)
+	}
+	defer store.Close()
+	hits, err := store.Search(ctx, "tickets/TASK-00001", "second note", 1)
+	if err != nil || len(hits) != 1 || hits[0].Key != "design.md" {
+		t.Errorf("search after reembed = %+v, %v", hits, err)
+	}
+	if _, err := run("--to", "fake"); err == nil {
+		t.Error("re-embedding into the current embedder should be refused")
+	}
+}
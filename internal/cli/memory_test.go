@@ -3,9 +3,11 @@ package cli
 import (
 	"bytes"
 	"context"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -19,7 +21,7 @@ func TestMemoryCommandsExist(t *testing.T) {
 	if memCmd == nil {
 		t.Fatal("memory command not registered on root")
 	}
-	for _, sub := range []string{"store", "search", "delete", "list", "export", "import"} {
+	for _, sub := range []string{"store", "search", "delete", "list", "index", "gc", "reembed", "export", "import"} {
 		if findCobraSub(memCmd, sub) == nil {
 			t.Errorf("memory subcommand %q not registered", sub)
 		}
@@ -94,3 +96,166 @@ func TestMemoryCLI_StoreSearchRoundtrip(t *testing.T) {
 }
 
 func bytesContains(s, substr string) bool { return strings.Contains(s, substr) }
+
+// TestMemoryCLI_SearchModes: --mode lexical matches an exact ticket ID the
+// fake embedder cannot, and a bad --mode fails before the store opens.
+func TestMemoryCLI_SearchModes(t *testing.T) {
+	tmp := t.TempDir()
+	app, err := internal.NewApp(tmp)
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	defer app.Cleanup()
+	App = app
+	memoryDBPath = filepath.Join(tmp, ".adb_memory.sqlite")
+	memoryProvider, memoryDim, memoryModel, memoryEndpoint, memoryAPIKey = "fake", 64, "", "", ""
+
+	ctx := context.Background()
+	store, err := openStoreFromFlags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Upsert(ctx, "tickets", "a", "TASK-00042 retry webhook deliveries", nil)
+	_ = store.Upsert(ctx, "tickets", "b", "TASK-00043 dark mode", nil)
+	_ = store.Close()
+
+	run := func(args ...string) (string, error) {
+		cmd := newMemorySearchCmd()
+		cmd.SetContext(ctx)
+		cmd.SetArgs(args)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	for _, mode := range []string{"lexical", "hybrid", ""} {
+		out, err := run("tickets", "TASK-00043", "--k", "1", "--mode", mode)
+		if err != nil || !strings.Contains(out, "tickets/b") {
+			t.Errorf("--mode %q: %v\n%s", mode, err, out)
+		}
+	}
+	if _, err := run("tickets", "x", "--mode", "fuzzy"); err == nil {
+		t.Error("expected an error for --mode fuzzy")
+	}
+}
+
+// TestMemoryCLI_SearchAcrossNamespaces: --ns takes a glob and the query as
+// the only argument; --where and --since narrow the candidates.
+func TestMemoryCLI_SearchAcrossNamespaces(t *testing.T) {
+	tmp := t.TempDir()
+	app, err := internal.NewApp(tmp)
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	defer app.Cleanup()
+	App = app
+	memoryDBPath = filepath.Join(tmp, ".adb_memory.sqlite")
+	memoryProvider, memoryDim, memoryModel, memoryEndpoint, memoryAPIKey = "fake", 64, "", "", ""
+
+	ctx := context.Background()
+	store, err := openStoreFromFlags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Upsert(ctx, "tickets/TASK-00001", "context.md", "webhook retries", map[string]string{"source": "ticket-knowledge"})
+	_ = store.Upsert(ctx, "tickets/TASK-00001", "edge", "webhook edge", map[string]string{"source": "graph-edge"})
+	_ = store.Upsert(ctx, "sessions/S-1", "summary", "webhook session", map[string]string{"source": "session"})
+	_ = store.Close()
+
+	run := func(args ...string) (string, error) {
+		cmd := newMemorySearchCmd()
+		cmd.SetContext(ctx)
+		cmd.SetArgs(args)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	out, err := run("--ns", "tickets/*", "--where", "source=ticket-knowledge", "webhook")
+	if err != nil || !strings.Contains(out, "tickets/TASK-00001/context.md") || strings.Contains(out, "edge") || strings.Contains(out, "sessions/") {
+		t.Errorf("--ns/--where: %v\n%s", err, out)
+	}
+	out, err = run("webhook", "--k", "10")
+	if err != nil || !strings.Contains(out, "sessions/S-1/summary") || !strings.Contains(out, "tickets/TASK-00001/edge") {
+		t.Errorf("all namespaces: %v\n%s", err, out)
+	}
+	if out, err := run("--since", "2099-01-01", "webhook"); err != nil || !strings.Contains(out, "(no hits)") {
+		t.Errorf("--since in the future: %v\n%s", err, out)
+	}
+	for _, args := range [][]string{
+		{"--ns", "tickets/*", "tickets", "webhook"},
+		{"--ns", "tickets/[", "webhook"},
+		{"--since", "yesterday", "webhook"},
+		{"--min-score", "2", "webhook"},
+	} {
+		if _, err := run(args...); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestParseSearchTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	if got, err := parseSearchTime("30d", now); err != nil || !got.Equal(now.Add(-30*24*time.Hour)) {
+		t.Errorf("30d = %v, %v", got, err)
+	}
+	if got, err := parseSearchTime("2026-01-31", now); err != nil || got.Format("2006-01-02") != "2026-01-31" {
+		t.Errorf("date = %v, %v", got, err)
+	}
+	if got, err := parseSearchTime("", now); err != nil || !got.IsZero() {
+		t.Errorf("empty = %v, %v", got, err)
+	}
+}
+
+// TestMemoryCLI_IndexIsIncremental: a second `adb memory index` over an
+// unchanged workspace embeds nothing, and search surfaces the matching
+// chunk with its parent document.
+func TestMemoryCLI_IndexIsIncremental(t *testing.T) {
+	tmp := t.TempDir()
+	app, err := internal.NewApp(tmp)
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	defer app.Cleanup()
+	App = app
+	memoryDBPath = filepath.Join(tmp, ".adb_memory.sqlite")
+	memoryProvider, memoryDim, memoryModel, memoryEndpoint, memoryAPIKey = "fake", 64, "", "", ""
+
+	ticketDir := filepath.Join(tmp, "tickets", "TASK-00001")
+	if err := os.MkdirAll(ticketDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	notes := "# Notes\n\n## Retry policy\n\nBack off exponentially.\n\n## Ownership\n\nThe platform team owns the queue.\n"
+	if err := os.WriteFile(filepath.Join(ticketDir, "notes.md"), []byte(notes), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	backlog := "tasks:\n  - id: TASK-00001\n    title: t\n    type: feat\n    status: backlog\n    priority: P2\n    ticket_path: " + ticketDir + "\n"
+	if err := os.WriteFile(filepath.Join(tmp, "backlog.yaml"), []byte(backlog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(cmd *cobra.Command, args ...string) string {
+		t.Helper()
+		cmd.SetContext(context.Background())
+		cmd.SetArgs(args)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%s: %v\n%s", cmd.Name(), err, out.String())
+		}
+		return out.String()
+	}
+	if out := run(newMemoryIndexCmd()); !strings.Contains(out, "2 chunk(s) embedded") {
+		t.Fatalf("first index:\n%s", out)
+	}
+	if out := run(newMemoryIndexCmd()); !strings.Contains(out, "0 chunk(s) embedded, 0 moved, 2 unchanged") {
+		t.Fatalf("second index:\n%s", out)
+	}
+	out := run(newMemorySearchCmd(), "tickets/TASK-00001", "platform team", "--k", "1")
+	if !strings.Contains(out, "in notes.md › Notes > Ownership") || strings.Contains(out, "Retry") {
+		t.Errorf("search should return just the Ownership chunk:\n%s", out)
+	}
+}
@@ -14,17 +14,39 @@ func NewMetricsCmd() *cobra.Command {
 	var (
 		jsonOutput bool
 		since      string
+		flow       bool
+		groupBy    string
+		where      []string
+		cfdPath    string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "metrics",
 		Short: "Display workspace metrics",
-		Long:  `Display metrics derived from the event log`,
+		Long: `Display metrics derived from the event log.
+
+With --flow, report flow metrics instead: lead time (created → done), cycle
+time (first in_progress → done), time spent per status, daily WIP, weekly
+throughput and aging WIP, with p50/p85/p95 percentiles. Slice them with
+--by type|priority|repo|initiative and narrow them with --where key=value.
+--cfd writes a cumulative flow diagram as CSV (one row per day, one column
+per status); use "-" for stdout.
+
+Examples:
+  adb metrics --flow --since 30d
+  adb metrics --flow --by priority --json
+  adb metrics --flow --where repo=github.com/org/api --cfd cfd.csv`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if App == nil {
 				return fmt.Errorf("app not initialized")
 			}
 
+			if flow || cfdPath != "" {
+				return runFlowMetrics(cmd, flowMetricsFlags{
+					since: since, groupBy: groupBy, where: where, cfdPath: cfdPath, jsonOutput: jsonOutput, flow: flow,
+				})
+			}
+
 			// Compute metrics — windowed when --since is given, else all-time.
 			var metrics *observability.Metrics
 			if since != "" {
@@ -66,6 +88,12 @@ func NewMetricsCmd() *cobra.Command {
 
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 	cmd.Flags().StringVar(&since, "since", "", "Show metrics since duration (e.g., 7d, 24h)")
+	cmd.Flags().BoolVar(&flow, "flow", false, "Show flow metrics (lead/cycle time, throughput, WIP)")
+	cmd.Flags().StringVar(&groupBy, "by", "", "Slice flow metrics by type, priority, repo or initiative")
+	cmd.Flags().StringArrayVar(&where, "where", nil, "Only include tasks matching key=value (repeatable)")
+	cmd.Flags().StringVar(&cfdPath, "cfd", "", "Write the cumulative flow diagram as CSV to this file (- for stdout)")
+
+	cmd.AddCommand(newMetricsServeCmd())
 
 	return cmd
 }
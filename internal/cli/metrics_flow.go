@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+)
+
+// flowMetricsFlags carries the `adb metrics --flow` flags.
+type flowMetricsFlags struct {
+	since      string
+	groupBy    string
+	where      []string
+	cfdPath    string
+	jsonOutput bool
+	flow       bool
+}
+
+func runFlowMetrics(cmd *cobra.Command, f flowMetricsFlags) error {
+	opts := observability.FlowOptions{GroupBy: f.groupBy}
+	if f.since != "" {
+		d, err := parseDuration(f.since)
+		if err != nil {
+			return fmt.Errorf("invalid duration format: %w", err)
+		}
+		opts.Since = time.Now().UTC().Add(-d)
+	}
+	if len(f.where) > 0 {
+		opts.Filter = make(map[string]string, len(f.where))
+		for _, kv := range f.where {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok || k == "" {
+				return fmt.Errorf("invalid --where %q: expected key=value", kv)
+			}
+			opts.Filter[k] = v
+		}
+	}
+	if App.BacklogManager != nil {
+		backlog, err := App.BacklogManager.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load backlog: %w", err)
+		}
+		for _, t := range backlog.Tasks {
+			opts.Tasks = append(opts.Tasks, observability.FlowTask{
+				ID:         t.ID,
+				Type:       string(t.Type),
+				Priority:   string(t.Priority),
+				Repo:       t.Repo,
+				Initiative: t.Initiative,
+			})
+		}
+	}
+
+	fm, err := App.MetricsCalculator.ComputeFlow(opts)
+	if err != nil {
+		return fmt.Errorf("failed to compute flow metrics: %w", err)
+	}
+
+	// --cfd alone exports the CSV; with --flow the report follows it.
+	out := cmd.OutOrStdout()
+	if f.cfdPath != "" {
+		if f.cfdPath == "-" {
+			return observability.WriteCFDCSV(out, fm.CFD)
+		}
+		file, err := os.Create(f.cfdPath)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", f.cfdPath, err)
+		}
+		if err := observability.WriteCFDCSV(file, fm.CFD); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write %s: %w", f.cfdPath, err)
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "✓ Wrote cumulative flow diagram (%d days) to %s\n", len(fm.CFD), f.cfdPath)
+		if !f.flow {
+			return nil
+		}
+	}
+
+	if f.jsonOutput {
+		return printJSON(fm)
+	}
+	return printFlowMetrics(out, fm)
+}
+
+// printFlowMetrics prints flow metrics in human-readable format
+func printFlowMetrics(out io.Writer, fm *observability.FlowMetrics) error {
+	title := "=== Flow Metrics ==="
+	if !fm.Since.IsZero() {
+		title = fmt.Sprintf("=== Flow Metrics (since %s) ===", fm.Since.Local().Format("2006-01-02"))
+	}
+	fmt.Fprintln(out, title)
+	fmt.Fprintln(out)
+
+	o := fm.Overall
+	fmt.Fprintf(out, "Tasks:      %d\n", o.Tasks)
+	fmt.Fprintf(out, "Completed:  %d\n", o.Completed)
+	if n := len(o.WIP); n > 0 {
+		fmt.Fprintf(out, "WIP now:    %d\n", o.WIP[n-1].Count)
+	}
+	fmt.Fprintln(out)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tN\tP50\tP85\tP95\tMEAN")
+	printFlowStatsRow(w, "lead time", o.LeadTime)
+	printFlowStatsRow(w, "cycle time", o.CycleTime)
+	for _, status := range observability.FlowStatuses {
+		if s, ok := o.TimeInStatus[status]; ok {
+			printFlowStatsRow(w, "in "+status, s)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(o.Throughput) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Weekly throughput:")
+		for _, p := range o.Throughput {
+			fmt.Fprintf(out, "  %s  %3d  %s\n", p.Period, p.Count, strings.Repeat("█", p.Count))
+		}
+	}
+
+	if len(o.AgingWIP) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Aging WIP:")
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  TASK\tSTATUS\tAGE")
+		for _, a := range o.AgingWIP {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", a.TaskID, a.Status, formatFlowDuration(a.Age))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(fm.Groups) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "By %s:\n", fm.GroupBy)
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  GROUP\tTASKS\tDONE\tLEAD P50\tLEAD P85\tCYCLE P50\tCYCLE P85\tWIP")
+		for _, g := range fm.Groups {
+			fmt.Fprintf(w, "  %s\t%d\t%d\t%s\t%s\t%s\t%s\t%d\n", g.Group, g.Tasks, g.Completed,
+				formatFlowStat(g.LeadTime, g.LeadTime.P50), formatFlowStat(g.LeadTime, g.LeadTime.P85),
+				formatFlowStat(g.CycleTime, g.CycleTime.P50), formatFlowStat(g.CycleTime, g.CycleTime.P85),
+				len(g.AgingWIP))
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+func printFlowStatsRow(w io.Writer, label string, s observability.DurationStats) {
+	fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", label, s.Count,
+		formatFlowStat(s, s.P50), formatFlowStat(s, s.P85), formatFlowStat(s, s.P95), formatFlowStat(s, s.Mean))
+}
+
+func formatFlowStat(s observability.DurationStats, d time.Duration) string {
+	if s.Count == 0 {
+		return "-"
+	}
+	return formatFlowDuration(d)
+}
+
+// formatFlowDuration renders minutes under an hour, hours under two days and
+// days beyond that.
+func formatFlowDuration(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%.1fh", d.Hours())
+	default:
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	}
+}
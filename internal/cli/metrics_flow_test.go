@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+)
+
+func TestMetricsFlow_TableAndCFD(t *testing.T) {
+	app, cleanup := setupEventsTest(t)
+	defer cleanup()
+
+	app.EventLog.Log(observability.EventTaskCreated, map[string]interface{}{
+		"task_id": "TASK-1", "type": "feat", "priority": "P1", "status": "backlog",
+	})
+	app.EventLog.Log(observability.EventTaskStatusChanged, map[string]interface{}{
+		"task_id": "TASK-1", "old_status": "backlog", "new_status": "in_progress",
+	})
+	app.EventLog.Log(observability.EventTaskCreated, map[string]interface{}{
+		"task_id": "TASK-2", "type": "bug", "priority": "P0", "status": "backlog",
+	})
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := NewMetricsCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("metrics %v: %v\n%s", args, err, out.String())
+		}
+		return out.String()
+	}
+
+	out := run("--flow", "--by", "type")
+	for _, want := range []string{"Flow Metrics", "lead time", "Aging WIP:", "TASK-1", "By type:", "bug"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("flow output missing %q:\n%s", want, out)
+		}
+	}
+
+	csv := run("--cfd", "-", "--where", "type=feat")
+	lines := strings.Split(strings.TrimSpace(csv), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], ",0,1,0,0,0,0") {
+		t.Errorf("cfd csv:\n%s", csv)
+	}
+}
+
+func TestMetricsFlow_RejectsBadWhere(t *testing.T) {
+	_, cleanup := setupEventsTest(t)
+	defer cleanup()
+	cmd := NewMetricsCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--flow", "--where", "owner"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for --where without '='")
+	}
+}
@@ -0,0 +1,312 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/internal/scheduler"
+)
+
+const (
+	// defaultMetricsListen keeps the unauthenticated endpoint off the network
+	// unless the operator asks for it.
+	defaultMetricsListen = "127.0.0.1:9464"
+	// defaultMetricsCacheTTL matches Prometheus' usual 15s scrape interval.
+	defaultMetricsCacheTTL = 15 * time.Second
+)
+
+func newMetricsServeCmd() *cobra.Command {
+	var (
+		listen   string
+		textfile string
+		cacheTTL time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Expose workspace metrics for Prometheus (OpenMetrics)",
+		Long: `Serve adb's workspace state on /metrics for Prometheus or any OpenMetrics
+scraper. The metrics are computed from the backlog, the event log, the alert
+rules and the scheduler state, and reused for --cache-ttl so that frequent or
+concurrent scrapes do not re-read the workspace (and re-run git status on
+task worktrees) each time:
+
+  adb_tasks{status,type,priority}                     backlog tasks (gauge)
+  adb_tasks_created_total, adb_tasks_completed_total  from the event log
+  adb_agent_sessions_total                            sessions started
+  adb_agent_sessions_active                           sessions not ended in the last 8h
+  adb_alerts_firing{type,severity}                    alerts currently firing
+  adb_issue_sync_conflicts                            tasks with an open issue-sync conflict
+  adb_scheduler_job_last_success_timestamp_seconds{job}
+  adb_scheduler_job_runs_total{job}, adb_scheduler_job_failures_total{job}
+  adb_events_last_timestamp_seconds                   newest event in the log
+
+Scrapers that ask for application/openmetrics-text get OpenMetrics 1.0;
+anything else gets the Prometheus 0.0.4 text format.
+
+The listener binds to localhost by default and has no authentication; pass
+--listen :9464 only on a trusted network.
+
+With --textfile, write the metrics once to a file (atomically) and exit —
+point node_exporter's textfile collector at its directory and run this from
+cron or the adb scheduler.
+
+Examples:
+  adb metrics serve
+  adb metrics serve --listen 0.0.0.0:9464 --cache-ttl 1m
+  adb metrics serve --textfile /var/lib/node_exporter/textfile/adb.prom`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if App == nil || App.MetricsCalculator == nil {
+				return fmt.Errorf("app not initialized")
+			}
+			if textfile != "" {
+				if err := writeMetricsTextfile(textfile); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "✓ Wrote metrics to %s\n", textfile)
+				return nil
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			ln, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("listen on %s: %w", listen, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Serving metrics on http://%s/metrics (Ctrl-C to stop)\n", ln.Addr())
+			return serveMetrics(ctx, ln, cacheTTL)
+		},
+	}
+	cmd.Flags().StringVar(&listen, "listen", defaultMetricsListen, "Address to serve /metrics on")
+	cmd.Flags().DurationVar(&cacheTTL, "cache-ttl", defaultMetricsCacheTTL, "Reuse computed metrics for this long between scrapes (0 recomputes on every scrape)")
+	cmd.Flags().StringVar(&textfile, "textfile", "", "Write the metrics once to this file (node_exporter textfile collector) and exit")
+	return cmd
+}
+
+// serveMetrics runs the /metrics HTTP server on ln until ctx is done.
+func serveMetrics(ctx context.Context, ln net.Listener, cacheTTL time.Duration) error {
+	srv := &http.Server{Handler: metricsHandler(cacheTTL), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// metricsCache holds the last computed families for ttl. Scrapes that arrive
+// while a collection is running wait for it instead of starting their own.
+type metricsCache struct {
+	ttl     time.Duration
+	collect func(now time.Time) ([]observability.MetricFamily, error)
+
+	mu       sync.Mutex
+	at       time.Time
+	families []observability.MetricFamily
+}
+
+// get returns the cached families while they are younger than ttl, and
+// collects afresh otherwise. A failed collection is not cached.
+func (c *metricsCache) get(now time.Time) ([]observability.MetricFamily, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.families != nil && now.Sub(c.at) < c.ttl {
+		return c.families, nil
+	}
+	families, err := c.collect(now)
+	if err != nil {
+		return nil, err
+	}
+	c.at, c.families = now, families
+	return families, nil
+}
+
+func metricsHandler(cacheTTL time.Duration) http.Handler {
+	cache := &metricsCache{ttl: cacheTTL, collect: collectWorkspaceMetrics}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		families, err := cache.get(time.Now().UTC())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var buf bytes.Buffer
+		if strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+			w.Header().Set("Content-Type", observability.OpenMetricsContentType)
+			err = observability.WriteOpenMetrics(&buf, families)
+		} else {
+			w.Header().Set("Content-Type", observability.PrometheusTextContentType)
+			err = observability.WritePrometheusText(&buf, families)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, "adb metrics exporter — scrape /metrics")
+	})
+	return mux
+}
+
+// writeMetricsTextfile writes the Prometheus text format to path via a temp
+// file and rename, so the textfile collector never reads a partial file.
+func writeMetricsTextfile(path string) error {
+	families, err := collectWorkspaceMetrics(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := observability.WritePrometheusText(&buf, families); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// collectWorkspaceMetrics gathers every exported family. A source that is
+// not wired (no backlog, no alert evaluator) simply contributes nothing.
+func collectWorkspaceMetrics(now time.Time) ([]observability.MetricFamily, error) {
+	var families []observability.MetricFamily
+
+	tasks := observability.MetricFamily{Name: "adb_tasks", Type: observability.MetricGauge,
+		Help: "Backlog tasks by status, type and priority."}
+	if App.BacklogManager != nil {
+		backlog, err := App.BacklogManager.Load()
+		if err != nil {
+			return nil, fmt.Errorf("load backlog: %w", err)
+		}
+		counts := make(map[[3]string]int)
+		for _, t := range backlog.Tasks {
+			counts[[3]string{string(t.Status), string(t.Type), string(t.Priority)}]++
+		}
+		keys := make([][3]string, 0, len(counts))
+		for k := range counts {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return strings.Join(keys[i][:], "\x00") < strings.Join(keys[j][:], "\x00")
+		})
+		for _, k := range keys {
+			tasks.Add(float64(counts[k]), "status", k[0], "type", k[1], "priority", k[2])
+		}
+	}
+	families = append(families, tasks)
+
+	m, err := App.MetricsCalculator.ComputeMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("compute metrics: %w", err)
+	}
+	created := observability.MetricFamily{Name: "adb_tasks_created", Type: observability.MetricCounter,
+		Help: "Tasks created, from the event log."}
+	created.Add(float64(m.TasksCreated))
+	completed := observability.MetricFamily{Name: "adb_tasks_completed", Type: observability.MetricCounter,
+		Help: "Tasks completed, from the event log."}
+	completed.Add(float64(m.TasksCompleted))
+	sessions := observability.MetricFamily{Name: "adb_agent_sessions", Type: observability.MetricCounter,
+		Help: "Agent sessions started."}
+	sessions.Add(float64(m.AgentSessions))
+	lastEvent := observability.MetricFamily{Name: "adb_events_last_timestamp_seconds", Type: observability.MetricGauge,
+		Help: "Unix time of the newest event in the log."}
+	lastEvent.Add(observability.UnixSeconds(m.LastEventTimestamp))
+
+	active, err := App.MetricsCalculator.ActiveAgentSessions(now, observability.DefaultSessionDigestSince)
+	if err != nil {
+		return nil, fmt.Errorf("count active sessions: %w", err)
+	}
+	activeSessions := observability.MetricFamily{Name: "adb_agent_sessions_active", Type: observability.MetricGauge,
+		Help: "Agent sessions whose latest event in the last 8h is not agent.session_ended."}
+	activeSessions.Add(float64(active))
+
+	conflicts, err := App.MetricsCalculator.OpenIssueConflicts()
+	if err != nil {
+		return nil, fmt.Errorf("count issue-sync conflicts: %w", err)
+	}
+	conflictFamily := observability.MetricFamily{Name: "adb_issue_sync_conflicts", Type: observability.MetricGauge,
+		Help: "Tasks with an unresolved issue-sync conflict."}
+	conflictFamily.Add(float64(conflicts))
+
+	families = append(families, created, completed, sessions, activeSessions, conflictFamily)
+
+	alerts := observability.MetricFamily{Name: "adb_alerts_firing", Type: observability.MetricGauge,
+		Help: "Alerts currently firing, by type and severity."}
+	if App.AlertEvaluator != nil {
+		firing, err := App.AlertEvaluator.EvaluateAll()
+		if err != nil {
+			return nil, fmt.Errorf("evaluate alerts: %w", err)
+		}
+		counts := make(map[[2]string]int)
+		for _, a := range firing {
+			counts[[2]string{string(a.Type), string(a.Severity)}]++
+		}
+		keys := make([][2]string, 0, len(counts))
+		for k := range counts {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i][0]+keys[i][1] < keys[j][0]+keys[j][1] })
+		for _, k := range keys {
+			alerts.Add(float64(counts[k]), "type", k[0], "severity", k[1])
+		}
+	}
+	families = append(families, alerts)
+
+	states, err := scheduler.LoadStates(schedulerStatePath())
+	if err != nil {
+		return nil, fmt.Errorf("load scheduler state: %w", err)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	lastSuccess := observability.MetricFamily{Name: "adb_scheduler_job_last_success_timestamp_seconds", Type: observability.MetricGauge,
+		Help: "Unix time each scheduler job last finished without error (0: never)."}
+	runs := observability.MetricFamily{Name: "adb_scheduler_job_runs", Type: observability.MetricCounter,
+		Help: "Scheduler job runs."}
+	failures := observability.MetricFamily{Name: "adb_scheduler_job_failures", Type: observability.MetricCounter,
+		Help: "Scheduler job runs that returned an error."}
+	for _, s := range states {
+		lastSuccess.Add(observability.UnixSeconds(s.LastSuccess), "job", s.Name)
+		runs.Add(float64(s.Runs), "job", s.Name)
+		failures.Add(float64(s.Failures), "job", s.Name)
+	}
+	families = append(families, lastEvent, lastSuccess, runs, failures)
+
+	return families, nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+)
+
+func TestMetricsHandler_NegotiatesFormat(t *testing.T) {
+	app, cleanup := setupEventsTest(t)
+	defer cleanup()
+	app.EventLog.Log(observability.EventTaskCreated, map[string]interface{}{"task_id": "TASK-1", "status": "backlog"})
+	app.EventLog.Log(observability.EventAgentSessionStarted, map[string]interface{}{"task_id": "TASK-1"})
+
+	srv := httptest.NewServer(metricsHandler(0))
+	defer srv.Close()
+
+	get := func(accept string) (string, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/metrics", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET /metrics: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status %d: %s", resp.StatusCode, body)
+		}
+		return resp.Header.Get("Content-Type"), string(body)
+	}
+
+	ct, body := get("application/openmetrics-text;version=1.0.0,text/plain;q=0.5")
+	if ct != observability.OpenMetricsContentType || !strings.HasSuffix(body, "# EOF\n") {
+		t.Errorf("OpenMetrics response %q:\n%s", ct, body)
+	}
+	for _, want := range []string{
+		"adb_tasks_created_total 1\n",
+		"adb_agent_sessions_active 1\n",
+		"adb_issue_sync_conflicts 0\n",
+		"# TYPE adb_alerts_firing gauge",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+
+	ct, body = get("")
+	if ct != observability.PrometheusTextContentType || strings.Contains(body, "# EOF") {
+		t.Errorf("text response %q:\n%s", ct, body)
+	}
+}
+
+func TestMetricsCache_ReusesFamiliesWithinTTL(t *testing.T) {
+	calls := 0
+	fail := false
+	cache := &metricsCache{ttl: 15 * time.Second, collect: func(time.Time) ([]observability.MetricFamily, error) {
+		if fail {
+			return nil, errors.New("boom")
+		}
+		calls++
+		return []observability.MetricFamily{{Name: "adb_x", Type: observability.MetricGauge}}, nil
+	}}
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, at := range []time.Duration{0, time.Second, 14 * time.Second} {
+		if _, err := cache.get(start.Add(at)); err != nil {
+			t.Fatalf("get at +%s: %v", at, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("collections within the TTL = %d, want 1", calls)
+	}
+	if _, err := cache.get(start.Add(15 * time.Second)); err != nil || calls != 2 {
+		t.Errorf("after the TTL: err %v, collections %d, want 2", err, calls)
+	}
+
+	fail = true
+	if _, err := cache.get(start.Add(time.Minute)); err == nil {
+		t.Error("a failed collection was served from the cache")
+	}
+	fail = false
+	if _, err := cache.get(start.Add(time.Minute)); err != nil || calls != 3 {
+		t.Errorf("after a failure: err %v, collections %d, want 3", err, calls)
+	}
+}
+
+func TestMetricsServe_DefaultsToLocalhost(t *testing.T) {
+	cmd := newMetricsServeCmd()
+	if got := cmd.Flags().Lookup("listen").DefValue; got != "127.0.0.1:9464" {
+		t.Errorf("--listen default = %q, want 127.0.0.1:9464", got)
+	}
+}
+
+func TestMetricsServe_Textfile(t *testing.T) {
+	_, cleanup := setupEventsTest(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "adb.prom")
+	cmd := NewMetricsCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetArgs([]string{"serve", "--textfile", path})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("serve --textfile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read textfile: %v", err)
+	}
+	if !strings.Contains(string(data), "# TYPE adb_tasks_created_total counter") {
+		t.Errorf("textfile:\n%s", data)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".adb.prom.tmp*")); len(leftovers) != 0 {
+		t.Errorf("temp files left behind: %v", leftovers)
+	}
+}
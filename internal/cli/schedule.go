@@ -3,8 +3,10 @@ package cli
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/valter-silva-au/ai-dev-brain/internal/core"
@@ -26,20 +28,40 @@ func NewScheduleCmd() *cobra.Command {
 		Short: "Author and run declarative automation rules",
 		Long: `Declarative automation rules (decision D7):
 
-  on <trigger> [if <graph condition>] run <action> → write <outputs>
+  on <trigger> [if <condition>] run <action> → write <outputs>
 
 Rules live in automation/rules.yaml. A trigger is either a time schedule
-(e.g. every 15m) or an event type (e.g. task.status_changed). An optional
-graph condition guards firing. The action is a skill (recorded as a request
-for an agent to run) or an exec command (run for real). Outputs are written
-artifacts and/or typed graph edges.
+(every 15m, or a cron expression such as "0 9 * * MON-FRI" or @daily, in the
+rule's or the workspace's automation.timezone) or an event type (e.g.
+task.status_changed). Scheduled firings that land in the workspace
+automation.quiet_hours or automation.holidays are suppressed. An optional
+condition guards firing: a graph check (--if-entity has an --if-edge) and/or
+an --if expression over the event payload, the task and the graph. The action is a skill (recorded as a request
+for an agent to run) or an exec command (run for real); a multi-step workflow
+(steps with timeouts, retries and on_failure) is written in rules.yaml.
+Outputs are written artifacts and/or typed graph edges. --debounce and
+--throttle coalesce or rate-limit firings per --concurrency-key.
+
+Event rules fire when the scheduler's automation-dispatch job drains the event
+log (automation.enabled), or, with automation.inline_dispatch set to sync or
+async, in the adb process that logs the event; an outbox under .adb/ keeps the
+two paths from firing an event twice. dispatch fires rules for an event that
+was never logged.
 
   adb schedule list
   adb schedule add --name nightly-pull --every 15m --run-skill repos-pull
+  adb schedule add --name standup --cron '0 9 * * MON-FRI' --timezone Europe/London \
+      --misfire skip --run-skill standup-digest
   adb schedule add --name flag-blocked --on-event task.status_changed \
       --if-entity '{{.task_id}}' --if-edge depends_on --run-skill triage
+  adb schedule add --name p0-blocked --on-event task.status_changed \
+      --if 'task.priority == "P0" && payload.new_status == "blocked"' --run-skill escalate
+  adb schedule add --name status-digest --on-event task.status_changed \
+      --concurrency-key '{{.task_id}}' --debounce 1m --run-skill status-digest
   adb schedule run [<name>]                 # fire a rule now (or all time rules)
   adb schedule dispatch --event task.status_changed --data task_id=TASK-1
+  adb schedule history [--rule <name>]      # what fired, when, on what
+  adb schedule simulate --rule <name> --since 30d   # dry-run replay of past events
   adb schedule remove <name>`,
 	}
 	cmd.AddCommand(
@@ -48,6 +70,8 @@ artifacts and/or typed graph edges.
 		newScheduleRemoveCmd(),
 		newScheduleRunCmd(),
 		newScheduleDispatchCmd(),
+		newScheduleHistoryCmd(),
+		newScheduleSimulateCmd(),
 	)
 	return cmd
 }
@@ -91,6 +115,13 @@ func newScheduleListCmd() *cobra.Command {
 }
 
 func triggerLabel(r models.Rule) string {
+	if r.On.IsCron() {
+		label := "cron " + r.On.Schedule
+		if r.On.Timezone != "" {
+			label += " (" + r.On.Timezone + ")"
+		}
+		return label
+	}
 	if r.On.IsSchedule() {
 		return "every " + r.On.Schedule
 	}
@@ -104,10 +135,24 @@ func conditionLabel(r models.Rule) string {
 	if r.If == nil {
 		return "-"
 	}
-	return fmt.Sprintf("%s has %s", r.If.Entity, r.If.HasEdge)
+	var parts []string
+	if r.If.HasEdge != "" {
+		parts = append(parts, fmt.Sprintf("%s has %s", r.If.Entity, r.If.HasEdge))
+	}
+	if r.If.Expr != "" {
+		parts = append(parts, truncateText(r.If.Expr, 60))
+	}
+	return strings.Join(parts, " && ")
 }
 
 func actionLabel(r models.Rule) string {
+	if len(r.Steps) > 0 {
+		names := make([]string, 0, len(r.Steps))
+		for _, st := range r.Workflow() {
+			names = append(names, st.Name)
+		}
+		return "steps " + strings.Join(names, " → ")
+	}
 	if strings.TrimSpace(r.Run.Skill) != "" {
 		return "skill " + r.Run.Skill
 	}
@@ -118,15 +163,23 @@ func newScheduleAddCmd() *cobra.Command {
 	var (
 		name       string
 		every      string
+		cron       string
+		timezone   string
+		misfire    string
+		ignoreCal  bool
 		onEvent    string
 		ifEntity   string
 		ifEdge     string
+		ifExpr     string
 		runSkill   string
 		runExec    string
 		writeEdges []string
 		writeArts  []string
 		edgeFrom   string
 		disabled   bool
+		concKey    string
+		debounce   string
+		throttle   string
 	)
 	cmd := &cobra.Command{
 		Use:   "add",
@@ -136,10 +189,22 @@ func newScheduleAddCmd() *cobra.Command {
 			if App == nil {
 				return fmt.Errorf("app not initialized")
 			}
-			rule, err := buildRuleFromFlags(name, every, onEvent, ifEntity, ifEdge, runSkill, runExec, writeEdges, writeArts, edgeFrom, disabled)
+			if strings.TrimSpace(cron) != "" {
+				if strings.TrimSpace(every) != "" {
+					return fmt.Errorf("set exactly one of --every or --cron")
+				}
+				every = cron
+			}
+			rule, err := buildRuleFromFlags(name, every, onEvent, ifEntity, ifEdge, ifExpr, runSkill, runExec, writeEdges, writeArts, edgeFrom, disabled)
 			if err != nil {
 				return err
 			}
+			if err := applyScheduleOptions(&rule, timezone, misfire, ignoreCal); err != nil {
+				return err
+			}
+			if err := applyConcurrencyOptions(&rule, concKey, debounce, throttle); err != nil {
+				return err
+			}
 			// Reject unknown event types at the write surface (read stays tolerant).
 			if rule.On.IsEvent() && !observability.IsKnownEventType(observability.EventType(rule.On.Event)) {
 				return fmt.Errorf("unknown event type %q; must be one of the adb event schema (see `adb events`)", rule.On.Event)
@@ -159,43 +224,93 @@ func newScheduleAddCmd() *cobra.Command {
 				return err
 			}
 			fmt.Fprintf(cmd.OutOrStdout(), "✓ Added rule %q (%s → %s).\n", rule.Name, triggerLabel(rule), actionLabel(rule))
+			_, daemonUp := readPIDFile(schedulerPIDPath())
+			if w := eventRuleWarning(rule, automationConfig(), daemonUp); w != "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", w)
+			}
 			return nil
 		},
 	}
 	f := cmd.Flags()
 	f.StringVar(&name, "name", "", "unique rule name (required)")
 	f.StringVar(&every, "every", "", "time trigger: a Go duration, e.g. 15m, 6h")
+	f.StringVar(&cron, "cron", "", "time trigger: a cron expression, e.g. '0 9 * * MON-FRI', '0 9 * * MON#1', @daily")
+	f.StringVar(&timezone, "timezone", "", "IANA time zone for --cron (default automation.timezone, else local)")
+	f.StringVar(&misfire, "misfire", "", "missed-run policy after downtime: run_once (default), run_all or skip")
+	f.BoolVar(&ignoreCal, "ignore-calendar", false, "fire even in the workspace quiet hours and holidays")
 	f.StringVar(&onEvent, "on-event", "", "event trigger: a known event type, e.g. task.status_changed")
 	f.StringVar(&ifEntity, "if-entity", "", "graph condition entity (may template, e.g. '{{.task_id}}')")
 	f.StringVar(&ifEdge, "if-edge", "", "graph condition edge type the entity must have, e.g. depends_on")
+	f.StringVar(&ifExpr, "if", "", `condition expression, e.g. 'task.priority == "P0" && !has_edge(task, "depends_on")'`)
 	f.StringVar(&runSkill, "run-skill", "", "action: record a request to run this skill")
 	f.StringVar(&runExec, "run-exec", "", "action: run this command (whitespace-split)")
 	f.StringArrayVar(&writeEdges, "write-edge", nil, "output: write a typed edge 'type:target' (repeatable)")
 	f.StringArrayVar(&writeArts, "write-artifact", nil, "output: write an artifact at this path (repeatable)")
 	f.StringVar(&edgeFrom, "edge-from", "", "output: source entity for --write-edge (defaults to the condition entity)")
 	f.BoolVar(&disabled, "disabled", false, "add the rule parked (enabled: false)")
+	f.StringVar(&concKey, "concurrency-key", "", "group firings by this key (may template, e.g. '{{.task_id}}'; default the rule)")
+	f.StringVar(&debounce, "debounce", "", "event rules: run once per key after events stop for this long, e.g. 1m")
+	f.StringVar(&throttle, "throttle", "", "run at most once per key in this window, e.g. 10m")
 	return cmd
 }
 
+// eventRuleWarning explains why a new event rule will not fire as added, or
+// returns "": event rules are inert until automation.enabled, and without the
+// scheduler daemon only inline dispatch fires them — exec steps only async.
+func eventRuleWarning(rule models.Rule, cfg models.AutomationConfig, daemonUp bool) string {
+	if !rule.On.IsEvent() || !rule.IsEnabled() {
+		return ""
+	}
+	if !cfg.Enabled {
+		return "event rules do not fire until automation.enabled is set"
+	}
+	mode, _ := cfg.InlineDispatchMode()
+	switch {
+	case daemonUp || mode == models.InlineDispatchAsync:
+		return ""
+	case mode == models.InlineDispatchSync && !rule.RunsExec():
+		return ""
+	case mode == models.InlineDispatchSync:
+		return "sync inline dispatch leaves exec steps to the scheduler, which is not running; start it with `adb scheduler start`"
+	}
+	return "the scheduler is not running, and this rule fires only from its drain; start it with `adb scheduler start`"
+}
+
+// applyConcurrencyOptions sets the concurrency flags on a built rule and
+// re-validates it. All-empty flags leave the rule unkeyed.
+func applyConcurrencyOptions(rule *models.Rule, key, debounce, throttle string) error {
+	key, debounce, throttle = strings.TrimSpace(key), strings.TrimSpace(debounce), strings.TrimSpace(throttle)
+	if key == "" && debounce == "" && throttle == "" {
+		return nil
+	}
+	rule.Concurrency = &models.RuleConcurrency{Key: key, Debounce: debounce, Throttle: throttle}
+	return rule.Validate()
+}
+
 // buildRuleFromFlags assembles + validates a Rule from `adb schedule add` flags.
-func buildRuleFromFlags(name, every, onEvent, ifEntity, ifEdge, runSkill, runExec string, writeEdges, writeArts []string, edgeFrom string, disabled bool) (models.Rule, error) {
+// schedule is the --every duration or the --cron expression.
+func buildRuleFromFlags(name, schedule, onEvent, ifEntity, ifEdge, ifExpr, runSkill, runExec string, writeEdges, writeArts []string, edgeFrom string, disabled bool) (models.Rule, error) {
 	rule := models.Rule{Name: strings.TrimSpace(name)}
 	if disabled {
 		off := false
 		rule.Enabled = &off
 	}
 	switch {
-	case strings.TrimSpace(every) != "" && strings.TrimSpace(onEvent) != "":
-		return models.Rule{}, fmt.Errorf("set exactly one of --every or --on-event")
-	case strings.TrimSpace(every) != "":
-		rule.On = models.RuleTrigger{Schedule: strings.TrimSpace(every)}
+	case strings.TrimSpace(schedule) != "" && strings.TrimSpace(onEvent) != "":
+		return models.Rule{}, fmt.Errorf("set exactly one of --every/--cron or --on-event")
+	case strings.TrimSpace(schedule) != "":
+		rule.On = models.RuleTrigger{Schedule: strings.TrimSpace(schedule)}
 	case strings.TrimSpace(onEvent) != "":
 		rule.On = models.RuleTrigger{Event: strings.TrimSpace(onEvent)}
 	default:
-		return models.Rule{}, fmt.Errorf("a trigger is required: pass --every <dur> or --on-event <type>")
+		return models.Rule{}, fmt.Errorf("a trigger is required: pass --every <dur>, --cron <expr> or --on-event <type>")
 	}
-	if strings.TrimSpace(ifEntity) != "" || strings.TrimSpace(ifEdge) != "" {
-		rule.If = &models.RuleCondition{Entity: strings.TrimSpace(ifEntity), HasEdge: models.EdgeType(strings.TrimSpace(ifEdge))}
+	if strings.TrimSpace(ifEntity) != "" || strings.TrimSpace(ifEdge) != "" || strings.TrimSpace(ifExpr) != "" {
+		rule.If = &models.RuleCondition{
+			Entity:  strings.TrimSpace(ifEntity),
+			HasEdge: models.EdgeType(strings.TrimSpace(ifEdge)),
+			Expr:    strings.TrimSpace(ifExpr),
+		}
 	}
 	switch {
 	case strings.TrimSpace(runSkill) != "" && strings.TrimSpace(runExec) != "":
@@ -228,6 +343,15 @@ func buildRuleFromFlags(name, every, onEvent, ifEntity, ifEdge, runSkill, runExe
 	return rule, nil
 }
 
+// applyScheduleOptions sets the schedule-only trigger options from `adb
+// schedule add` flags and re-validates the rule.
+func applyScheduleOptions(rule *models.Rule, timezone, misfire string, ignoreCalendar bool) error {
+	rule.On.Timezone = strings.TrimSpace(timezone)
+	rule.On.Misfire = models.MisfirePolicy(strings.TrimSpace(misfire))
+	rule.On.IgnoreCalendar = ignoreCalendar
+	return rule.Validate()
+}
+
 func newScheduleRemoveCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "remove <name>",
@@ -336,7 +460,13 @@ func newScheduleDispatchCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return printFirings(cmd, firings, jsonOutput)
+			// Release any debounced firing whose quiet period has passed, so
+			// debounced rules also progress without the scheduler daemon.
+			released, err := App.RuleEngine.FlushDebounced(context.Background())
+			if err != nil {
+				return fmt.Errorf("release debounced firings: %w", err)
+			}
+			return printFirings(cmd, append(firings, released...), jsonOutput)
 		},
 	}
 	cmd.Flags().StringVar(&event, "event", "", "the event type to dispatch, e.g. task.status_changed")
@@ -345,6 +475,273 @@ func newScheduleDispatchCmd() *cobra.Command {
 	return cmd
 }
 
+func newScheduleHistoryCmd() *cobra.Command {
+	var (
+		rule       string
+		status     string
+		since      string
+		limit      int
+		jsonOutput bool
+	)
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show recorded rule firings, newest first",
+		Long: `Show the firing ledger (.adb/rule_firings.jsonl): every firing of
+` + "`adb schedule run`" + `, ` + "`adb schedule dispatch`" + ` and the scheduler, with its
+trigger, the entity it was about, its outputs and why it skipped or errored.
+A disabled rule's skips are not recorded.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if App == nil || App.RuleEngine == nil {
+				return fmt.Errorf("app not initialized")
+			}
+			from, err := parseSearchTime(since, time.Now())
+			if err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			switch status {
+			case "", core.FiringFired, core.FiringSkipped, core.FiringError:
+			default:
+				return fmt.Errorf("--status must be %s, %s or %s", core.FiringFired, core.FiringSkipped, core.FiringError)
+			}
+			records, err := App.RuleEngine.History(core.FiringFilter{Rule: rule, Status: status, Since: from, Limit: limit})
+			if err != nil {
+				return fmt.Errorf("read firing history: %w", err)
+			}
+			if jsonOutput {
+				return printJSON(records)
+			}
+			if len(records) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No firings recorded.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "WHEN\tRULE\tTRIGGER\tENTITY\tSTATUS\tDETAIL")
+			for _, r := range records {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					r.At.Local().Format(time.RFC3339), r.Rule, firingTrigger(r.Firing), dashIfEmpty(r.Entity),
+					r.Status, truncateText(firingSummary(r.Firing), 70))
+			}
+			return w.Flush()
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&rule, "rule", "", "only this rule's firings")
+	f.StringVar(&status, "status", "", "only firings with this status: fired, skipped or error")
+	f.StringVar(&since, "since", "", "only firings since: a duration back (7d, 12h) or a date (2006-01-02)")
+	f.IntVar(&limit, "limit", 50, "show at most this many firings (0 = all)")
+	f.BoolVar(&jsonOutput, "json", false, "output as JSON")
+	return cmd
+}
+
+// simulateMaxOccurrences caps how many due times a time-rule simulation
+// enumerates, so `--since 90d` on a one-minute rule stays readable.
+const simulateMaxOccurrences = 1000
+
+func newScheduleSimulateCmd() *cobra.Command {
+	var (
+		rule       string
+		since      string
+		until      string
+		all        bool
+		jsonOutput bool
+	)
+	cmd := &cobra.Command{
+		Use:   "simulate --rule <name> [--since 30d]",
+		Short: "Replay past events through a rule without side effects",
+		Long: `Replay history through one rule in dry-run mode and report what it would
+have done. An event rule is evaluated against every matching event in
+.events.jsonl within the window; a time rule against each of its due times
+(those in quiet hours or on holidays are reported as skipped).
+
+The rule may be disabled — simulate before enabling it. Nothing runs: skill
+requests are not recorded, commands are not executed, and no artifact or edge
+is written. Conditions read the task and graph as they are NOW, not as they
+were when the event happened.
+
+  adb schedule simulate --rule p0-blocked --since 30d
+  adb schedule simulate --rule standup --since 14d --all`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if App == nil || App.RuleEngine == nil {
+				return fmt.Errorf("app not initialized")
+			}
+			if strings.TrimSpace(rule) == "" {
+				return fmt.Errorf("--rule is required")
+			}
+			now := time.Now()
+			from, err := parseSearchTime(since, now)
+			if err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			to, err := parseSearchTime(until, now)
+			if err != nil {
+				return fmt.Errorf("--until: %w", err)
+			}
+			if to.IsZero() {
+				to = now
+			}
+			target, err := findRule(rule)
+			if err != nil {
+				return err
+			}
+
+			var (
+				occurrences []core.RuleOccurrence
+				firings     []core.Firing
+				truncated   bool
+			)
+			if target.On.IsEvent() {
+				if App.EventLog == nil {
+					return fmt.Errorf("event log not available")
+				}
+				events, err := App.EventLog.ReadRange(from, to, observability.EventType(target.On.Event))
+				if err != nil {
+					return fmt.Errorf("read events: %w", err)
+				}
+				for _, ev := range events {
+					occurrences = append(occurrences, core.RuleOccurrence{At: ev.Timestamp, Event: string(ev.Type), Payload: ev.Payload()})
+				}
+			} else {
+				if from.IsZero() {
+					return fmt.Errorf("--since is required to simulate a time rule")
+				}
+				occurrences, firings, truncated, err = timeRuleOccurrences(target, from, to)
+				if err != nil {
+					return err
+				}
+			}
+			simulated, err := App.RuleEngine.Simulate(context.Background(), target.Name, occurrences)
+			if err != nil {
+				return err
+			}
+			firings = append(firings, simulated...)
+			sort.SliceStable(firings, func(i, j int) bool { return firings[i].At.Before(firings[j].At) })
+
+			if jsonOutput {
+				return printJSON(firings)
+			}
+			return printSimulation(cmd, target, firings, all, truncated)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&rule, "rule", "", "the rule to simulate (required)")
+	f.StringVar(&since, "since", "30d", "replay from: a duration back (30d, 12h) or a date (2006-01-02)")
+	f.StringVar(&until, "until", "", "replay up to: a duration back or a date (default now)")
+	f.BoolVar(&all, "all", false, "list skipped firings too, not only the ones that would fire or error")
+	f.BoolVar(&jsonOutput, "json", false, "output every simulated firing as JSON")
+	return cmd
+}
+
+// findRule looks a rule up by name in the engine's rule set.
+func findRule(name string) (models.Rule, error) {
+	rules, err := App.RuleEngine.Rules()
+	if err != nil {
+		return models.Rule{}, fmt.Errorf("load rules: %w", err)
+	}
+	for _, r := range rules {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return models.Rule{}, fmt.Errorf("no rule named %q", name)
+}
+
+// timeRuleOccurrences enumerates a time rule's due times in (from, to] in the
+// workspace time zone. Due times the workspace calendar suppresses come back
+// as skipped firings rather than occurrences, mirroring the daemon.
+func timeRuleOccurrences(rule models.Rule, from, to time.Time) ([]core.RuleOccurrence, []core.Firing, bool, error) {
+	automation := automationConfig()
+	loc, err := automation.Location()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	cadence, err := rule.On.Cadence(loc)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	var calendar *models.WorkCalendar
+	if !rule.On.IgnoreCalendar {
+		if calendar, err = automation.Calendar(); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	var (
+		occurrences []core.RuleOccurrence
+		suppressed  []core.Firing
+	)
+	for t := cadence.Next(from); !t.IsZero() && !t.After(to); t = cadence.Next(t) {
+		if len(occurrences)+len(suppressed) == simulateMaxOccurrences {
+			return occurrences, suppressed, true, nil
+		}
+		if reason, ok := calendar.Suppressed(t); ok {
+			suppressed = append(suppressed, core.Firing{Rule: rule.Name, Status: core.FiringSkipped, Reason: "suppressed: " + reason, At: t, DryRun: true})
+			continue
+		}
+		occurrences = append(occurrences, core.RuleOccurrence{At: t})
+	}
+	return occurrences, suppressed, false, nil
+}
+
+func printSimulation(cmd *cobra.Command, rule models.Rule, firings []core.Firing, all, truncated bool) error {
+	out := cmd.OutOrStdout()
+	counts := make(map[string]int)
+	for _, f := range firings {
+		counts[f.Status]++
+	}
+	what := "events"
+	if rule.On.IsSchedule() {
+		what = "due times"
+	}
+	fmt.Fprintf(out, "Simulated %q over %d %s: %d would fire, %d skipped, %d errored.\n",
+		rule.Name, len(firings), what, counts[core.FiringFired], counts[core.FiringSkipped], counts[core.FiringError])
+	if truncated {
+		fmt.Fprintf(out, "(stopped after %d due times; narrow --since/--until to see the rest)\n", simulateMaxOccurrences)
+	}
+	if !rule.IsEnabled() {
+		fmt.Fprintln(out, "(the rule is disabled; a live run would skip every one)")
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	header := false
+	for _, f := range firings {
+		if f.Status == core.FiringSkipped && !all {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "\nWHEN\tTRIGGER\tENTITY\tSTATUS\tDETAIL")
+			header = true
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			f.At.Local().Format(time.RFC3339), firingTrigger(f), dashIfEmpty(f.Entity), f.Status, truncateText(firingSummary(f), 90))
+	}
+	return w.Flush()
+}
+
+// firingTrigger labels what a firing ran for: its event, else a time or
+// manual run.
+func firingTrigger(f core.Firing) string {
+	if f.Event != "" {
+		return f.Event
+	}
+	return "run"
+}
+
+// firingSummary is firingDetail plus the outputs a fired rule wrote (or would
+// have written).
+func firingSummary(f core.Firing) string {
+	detail := strings.TrimSpace(firingDetail(f))
+	if f.Coalesced > 1 {
+		detail = fmt.Sprintf("(%d events) %s", f.Coalesced, detail)
+	}
+	if len(f.Outputs) > 0 {
+		if detail != "" {
+			detail += " → "
+		}
+		detail += strings.Join(f.Outputs, ", ")
+	}
+	return detail
+}
+
 // parseDataFlags turns repeated "key=value" flags into a payload map.
 func parseDataFlags(data []string) (map[string]string, error) {
 	if len(data) == 0 {
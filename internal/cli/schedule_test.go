@@ -1,13 +1,16 @@
 package cli
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/valter-silva-au/ai-dev-brain/internal/core"
 	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
 )
 
 func TestBuildRuleFromFlags_TimeSkill(t *testing.T) {
-	r, err := buildRuleFromFlags("nightly", "15m", "", "", "", "repos-pull", "", nil, nil, "", false)
+	r, err := buildRuleFromFlags("nightly", "15m", "", "", "", "", "repos-pull", "", nil, nil, "", false)
 	if err != nil {
 		t.Fatalf("buildRuleFromFlags error = %v", err)
 	}
@@ -22,7 +25,7 @@ func TestBuildRuleFromFlags_TimeSkill(t *testing.T) {
 func TestBuildRuleFromFlags_EventConditionEdgeOutput(t *testing.T) {
 	r, err := buildRuleFromFlags(
 		"flag-blocked", "", "task.status_changed",
-		"{{.task_id}}", "depends_on",
+		"{{.task_id}}", "depends_on", "",
 		"triage", "",
 		[]string{"relates_to:INIT-1"}, []string{"reports/{{.task_id}}.md"},
 		"{{.task_id}}", false,
@@ -52,7 +55,7 @@ func TestBuildRuleFromFlags_EventConditionEdgeOutput(t *testing.T) {
 }
 
 func TestBuildRuleFromFlags_Disabled(t *testing.T) {
-	r, err := buildRuleFromFlags("parked", "1h", "", "", "", "s", "", nil, nil, "", true)
+	r, err := buildRuleFromFlags("parked", "1h", "", "", "", "", "s", "", nil, nil, "", true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -75,7 +78,7 @@ func TestBuildRuleFromFlags_Rejections(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := buildRuleFromFlags(tc.rname, tc.every, tc.event, tc.ifEnt, tc.ifEdge, tc.skill, tc.exec, tc.edges, tc.arts, tc.edgeFrom, false)
+			_, err := buildRuleFromFlags(tc.rname, tc.every, tc.event, tc.ifEnt, tc.ifEdge, "", tc.skill, tc.exec, tc.edges, tc.arts, tc.edgeFrom, false)
 			if err == nil {
 				t.Fatalf("expected error for %q, got nil", tc.name)
 			}
@@ -83,6 +86,43 @@ func TestBuildRuleFromFlags_Rejections(t *testing.T) {
 	}
 }
 
+func TestBuildRuleFromFlags_CronScheduleOptions(t *testing.T) {
+	r, err := buildRuleFromFlags("standup", "0 9 * * MON-FRI", "", "", "", "", "digest", "", nil, nil, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := applyScheduleOptions(&r, "Europe/London", "skip", true); err != nil {
+		t.Fatal(err)
+	}
+	if r.On.Timezone != "Europe/London" || r.On.Misfire != models.MisfireSkip || !r.On.IgnoreCalendar {
+		t.Fatalf("trigger = %+v", r.On)
+	}
+	if got := triggerLabel(r); got != "cron 0 9 * * MON-FRI (Europe/London)" {
+		t.Errorf("triggerLabel = %q", got)
+	}
+	if err := applyScheduleOptions(&r, "", "eventually", false); err == nil {
+		t.Error("expected an unknown misfire policy to be refused")
+	}
+	ev, _ := buildRuleFromFlags("ev", "", "task.created", "", "", "", "s", "", nil, nil, "", false)
+	if err := applyScheduleOptions(&ev, "UTC", "", false); err == nil {
+		t.Error("expected --timezone on an event rule to be refused")
+	}
+}
+
+func TestBuildRuleFromFlags_IfExpression(t *testing.T) {
+	r, err := buildRuleFromFlags("p0-blocked", "", "task.status_changed", "", "", `task.priority == "P0" && payload.new_status == "blocked"`, "escalate", "", nil, nil, "", false)
+	if err != nil {
+		t.Fatalf("buildRuleFromFlags error = %v", err)
+	}
+	if r.If == nil || r.If.Expr != `task.priority == "P0" && payload.new_status == "blocked"` || r.If.HasEdge != "" {
+		t.Fatalf("condition = %+v", r.If)
+	}
+	_, err = buildRuleFromFlags("p0-blocked", "", "task.status_changed", "", "", `task.prio == "P0"`, "escalate", "", nil, nil, "", false)
+	if err == nil || !strings.Contains(err.Error(), `rule "p0-blocked": if: column 6: unknown task field "prio"`) {
+		t.Fatalf("bad --if error = %v", err)
+	}
+}
+
 func TestParseDataFlags(t *testing.T) {
 	got, err := parseDataFlags([]string{"task_id=TASK-1", "note=has=equals"})
 	if err != nil {
@@ -102,24 +142,6 @@ func TestParseDataFlags(t *testing.T) {
 	}
 }
 
-func TestStringifyEventValue(t *testing.T) {
-	cases := []struct {
-		in   interface{}
-		want string
-	}{
-		{"TASK-42", "TASK-42"},                    // strings pass through
-		{float64(42), "42"},                       // whole JSON numbers: no ".0"
-		{float64(42.5), "42.5"},                   // fractional numbers keep precision
-		{float64(1e21), "1000000000000000000000"}, // no scientific notation
-		{true, "true"},                            // other types fall back to %v
-	}
-	for _, c := range cases {
-		if got := stringifyEventValue(c.in); got != c.want {
-			t.Fatalf("stringifyEventValue(%v) = %q, want %q", c.in, got, c.want)
-		}
-	}
-}
-
 func mustNil(t *testing.T, data []string) map[string]string {
 	t.Helper()
 	m, err := parseDataFlags(data)
@@ -128,3 +150,92 @@ func mustNil(t *testing.T, data []string) map[string]string {
 	}
 	return m
 }
+
+func TestTimeRuleOccurrences(t *testing.T) {
+	from := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	rule := models.Rule{Name: "hourly", On: models.RuleTrigger{Schedule: "1h"}, Run: models.RuleAction{Skill: "s"}}
+	occ, suppressed, truncated, err := timeRuleOccurrences(rule, from, from.Add(5*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(occ) != 5 || len(suppressed) != 0 || truncated {
+		t.Fatalf("got %d occurrences, %d suppressed, truncated=%v; want 5, 0, false", len(occ), len(suppressed), truncated)
+	}
+	if !occ[0].At.Equal(from.Add(time.Hour)) || occ[0].Event != "" {
+		t.Errorf("first occurrence = %+v", occ[0])
+	}
+
+	rule.On.Schedule = "1m"
+	occ, _, truncated, _ = timeRuleOccurrences(rule, from, from.Add(48*time.Hour))
+	if !truncated || len(occ) != simulateMaxOccurrences {
+		t.Errorf("one-minute rule over two days: %d occurrences, truncated=%v; want the cap", len(occ), truncated)
+	}
+}
+
+func TestFiringSummary(t *testing.T) {
+	f := core.Firing{Status: core.FiringFired, Output: "would run: notify TASK-1", Outputs: []string{"artifact a.md", "edge TASK-1 --relates_to--> INIT-1"}}
+	if got, want := firingSummary(f), "would run: notify TASK-1 → artifact a.md, edge TASK-1 --relates_to--> INIT-1"; got != want {
+		t.Errorf("firingSummary = %q, want %q", got, want)
+	}
+	if got := firingSummary(core.Firing{Status: core.FiringSkipped, Reason: "condition not met"}); got != "condition not met" {
+		t.Errorf("skipped summary = %q", got)
+	}
+	if got := firingTrigger(core.Firing{Event: "task.created"}); got != "task.created" {
+		t.Errorf("firingTrigger = %q", got)
+	}
+}
+
+func TestApplyConcurrencyOptions(t *testing.T) {
+	r, _ := buildRuleFromFlags("digest", "", "task.status_changed", "", "", "", "digest", "", nil, nil, "", false)
+	if err := applyConcurrencyOptions(&r, "", "", ""); err != nil || r.Concurrency != nil {
+		t.Fatalf("no flags = %+v, %v; want an unkeyed rule", r.Concurrency, err)
+	}
+	if err := applyConcurrencyOptions(&r, "{{.task_id}}", "1m", ""); err != nil || r.Concurrency.Debounce != "1m" {
+		t.Fatalf("debounce = %+v, %v", r.Concurrency, err)
+	}
+	timed, _ := buildRuleFromFlags("nightly", "24h", "", "", "", "", "s", "", nil, nil, "", false)
+	if err := applyConcurrencyOptions(&timed, "", "1m", ""); err == nil || !strings.Contains(err.Error(), "debounce only applies to an event trigger") {
+		t.Errorf("debounced time rule error = %v", err)
+	}
+}
+
+func TestActionLabel_Steps(t *testing.T) {
+	r := models.Rule{Steps: []models.RuleStep{
+		{Name: "collect", RuleAction: models.RuleAction{Exec: []string{"x"}}},
+		{RuleAction: models.RuleAction{Skill: "y"}},
+	}}
+	if got := actionLabel(r); got != "steps collect → step2" {
+		t.Errorf("actionLabel = %q", got)
+	}
+}
+
+func TestEventRuleWarning(t *testing.T) {
+	execRule := models.Rule{Name: "n", On: models.RuleTrigger{Event: "task.created"}, Run: models.RuleAction{Exec: []string{"notify"}}}
+	skillRule := models.Rule{Name: "s", On: models.RuleTrigger{Event: "task.created"}, Run: models.RuleAction{Skill: "triage"}}
+	timeRule := models.Rule{Name: "t", On: models.RuleTrigger{Schedule: "1h"}, Run: models.RuleAction{Exec: []string{"x"}}}
+	on := func(mode string) models.AutomationConfig {
+		return models.AutomationConfig{Enabled: true, InlineDispatch: mode}
+	}
+	tests := []struct {
+		name     string
+		rule     models.Rule
+		cfg      models.AutomationConfig
+		daemonUp bool
+		want     string // substring; "" wants no warning
+	}{
+		{"time rule", timeRule, models.AutomationConfig{}, false, ""},
+		{"automation off", skillRule, models.AutomationConfig{}, true, "automation.enabled"},
+		{"exec, sync, no daemon", execRule, on("sync"), false, "sync inline dispatch"},
+		{"exec, off, no daemon", execRule, on(""), false, "fires only from its drain"},
+		{"exec, async", execRule, on("async"), false, ""},
+		{"exec, daemon up", execRule, on("sync"), true, ""},
+		{"skill, sync, no daemon", skillRule, on("sync"), false, ""},
+		{"skill, off, no daemon", skillRule, on("off"), false, "fires only from its drain"},
+	}
+	for _, tt := range tests {
+		got := eventRuleWarning(tt.rule, tt.cfg, tt.daemonUp)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("%s: warning = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
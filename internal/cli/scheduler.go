@@ -18,8 +18,11 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/valter-silva-au/ai-dev-brain/internal/core"
 	"github.com/valter-silva-au/ai-dev-brain/internal/integration"
+	"github.com/valter-silva-au/ai-dev-brain/internal/integration/notify"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
 	"github.com/valter-silva-au/ai-dev-brain/internal/scheduler"
 	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
 )
 
 // NewSchedulerCmd creates the `adb scheduler` command group.
@@ -31,7 +34,7 @@ func NewSchedulerCmd() *cobra.Command {
 
   repos-pull     fetch + fast-forward every repo under <workspace>/repos
   alerts-tick    evaluate alert conditions and log transitions
-  events-rotate  size-check the event and scheduler logs, rotate if large
+  events-rotate  rotate the scheduler log if large; compact event-log segments
 
 Start:    adb scheduler start
 Stop:     adb scheduler stop
@@ -184,7 +187,7 @@ func newSchedulerListCmd() *cobra.Command {
 			order = append(order, extra...)
 
 			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "JOB\tINTERVAL\tRUNS\tFAILURES\tSKIPPED\tLAST_RUN\tLAST_DURATION\tLAST_ERROR")
+			fmt.Fprintln(w, "JOB\tINTERVAL\tRUNS\tFAILURES\tSKIPPED\tLAST_RUN\tNEXT_RUN\tLAST_DURATION\tLAST_ERROR")
 			for _, name := range order {
 				s := byName[name]
 				interval := intervals[name]
@@ -197,13 +200,20 @@ func newSchedulerListCmd() *cobra.Command {
 				if !s.LastStart.IsZero() {
 					lastRun = s.LastStart.Local().Format(time.RFC3339)
 				}
-				fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\n",
+				// SKIPPED folds in a scheduled job's misfired and
+				// calendar-suppressed due times alongside overlap skips.
+				nextRun := "-"
+				if !s.NextRun.IsZero() {
+					nextRun = s.NextRun.Local().Format(time.RFC3339)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n",
 					name,
 					interval,
 					s.Runs,
 					s.Failures,
-					s.Skipped,
+					s.Skipped+s.Missed+s.Suppressed,
 					lastRun,
+					nextRun,
 					s.LastDuration,
 					truncateText(s.LastError, 60),
 				)
@@ -332,20 +342,43 @@ func schedulerRunForeground() error {
 			if App.AlertEvaluator == nil {
 				return 0, "", nil
 			}
-			alerts, err := App.AlertEvaluator.EvaluateAll()
+			tracked, err := evaluateTrackedAlerts()
 			if err != nil {
 				return 0, "", err
 			}
+			// Acknowledged and snoozed alerts are neither logged nor notified.
+			alerts := observability.Unacknowledged(tracked)
 			var buf bytes.Buffer
 			for _, a := range alerts {
 				fmt.Fprintf(&buf, "      [%s] %s\n", a.Severity, a.Message)
 			}
+			// Deliver through the ledger when notifications are opted in. A
+			// delivery failure is logged, not returned: the evaluation itself
+			// succeeded and the ledger retries the failed pair next tick.
+			if notificationConfig().Enabled {
+				deliveries, nerr := notifyAlerts(ctx, alerts)
+				if nerr != nil {
+					fmt.Fprintf(&buf, "      notify: %v\n", nerr)
+				} else {
+					fmt.Fprintf(&buf, "      notify: %s\n", notify.Summary(deliveries))
+				}
+			}
 			return len(alerts), buf.String(), nil
 		},
 		LogFiles: []string{
-			statedir.Path(App.BasePath, statedir.FileEventsLog),
 			schedulerLogPath(),
 		},
+		CompactEvents: func(ctx context.Context) (string, error) {
+			if App.EventLog == nil {
+				return "events: log disabled", nil
+			}
+			res, err := App.EventLog.Compact(observability.CompactOptions{})
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("events: %d sealed segments -> %d, %d duplicates, %d malformed removed",
+				res.SegmentsBefore, res.SegmentsAfter, res.Duplicates, res.Malformed), nil
+		},
 	}
 
 	jobs := scheduler.DefaultJobs(deps)
@@ -395,9 +428,13 @@ func automationDispatchInterval() time.Duration {
 	return d
 }
 
-// ruleJobs turns each enabled time-triggered rule into a scheduler job whose Run
-// fires the rule. A firing that errors fails the job (so `adb scheduler list`
-// surfaces it); a skipped/fired firing is logged and the job succeeds.
+// ruleJobs turns each enabled time-triggered rule into a scheduled job whose
+// Run fires the rule. A firing that errors fails the job (so `adb scheduler
+// list` surfaces it); a skipped/fired firing is logged and the job succeeds.
+// Each job runs on the rule's cadence (a duration or a cron expression in the
+// rule's or the workspace's time zone) with its next due time persisted, its
+// misfire policy applied on restart, and the workspace calendar suppressing
+// firings in quiet hours and on holidays unless the rule ignores it.
 func ruleJobs(logger io.Writer) []scheduler.Job {
 	if App == nil || App.RuleEngine == nil {
 		return nil
@@ -407,17 +444,29 @@ func ruleJobs(logger io.Writer) []scheduler.Job {
 		fmt.Fprintf(logger, "    automation: load time rules: %v\n", err)
 		return nil
 	}
+	automation := automationConfig()
+	loc, err := automation.Location()
+	if err != nil {
+		fmt.Fprintf(logger, "    automation: %v; using UTC\n", err)
+		loc = time.UTC
+	}
+	calendar, err := automation.Calendar()
+	if err != nil {
+		fmt.Fprintf(logger, "    automation: calendar ignored: %v\n", err)
+		calendar = nil
+	}
 	jobs := make([]scheduler.Job, 0, len(rules))
 	for _, r := range rules {
-		interval, err := r.On.Interval()
+		cadence, err := r.On.Cadence(loc)
 		if err != nil {
 			fmt.Fprintf(logger, "    automation: rule %q has bad schedule: %v\n", r.Name, err)
 			continue
 		}
 		name := r.Name
-		jobs = append(jobs, scheduler.Job{
-			Name:            "rule:" + name,
-			DefaultInterval: interval,
+		job := scheduler.Job{
+			Name:     "rule:" + name,
+			Schedule: cadence,
+			Misfire:  scheduler.MisfirePolicy(r.On.Misfire),
 			Run: func(ctx context.Context) error {
 				f, err := App.RuleEngine.FireByName(ctx, name, nil)
 				if err != nil {
@@ -429,11 +478,23 @@ func ruleJobs(logger io.Writer) []scheduler.Job {
 				}
 				return nil
 			},
-		})
+		}
+		if calendar != nil && !r.On.IgnoreCalendar {
+			job.Suppress = calendar.Suppressed
+		}
+		jobs = append(jobs, job)
 	}
 	return jobs
 }
 
+// automationConfig returns the merged automation config (zero when unset).
+func automationConfig() models.AutomationConfig {
+	if App == nil || App.MergedConfig == nil || App.MergedConfig.Global == nil {
+		return models.AutomationConfig{}
+	}
+	return App.MergedConfig.Global.Automation
+}
+
 // automationDispatchJob drains new .events.jsonl entries past a persisted cursor
 // and fires matching event-triggered rules. On first run (no cursor) it seeds
 // the cursor to "now" so historical events are not replayed.
@@ -468,14 +529,21 @@ func writeAutomationCursor(t time.Time) error {
 }
 
 // drainAutomationEvents reads events after the cursor and dispatches each to the
-// rule engine, advancing the cursor to the newest processed event. Fired/errored
-// firings are logged.
+// rule engine, advancing the cursor to the newest processed event, then
+// releases debounced firings whose quiet period has passed. Fired/errored
+// firings are logged. Each event goes through the engine's dispatch outbox, so
+// one already claimed inline (automation.inline_dispatch) is skipped here, and
+// outbox claims left open — rules sync inline dispatch deferred, or a dispatch
+// that never completed — are dispatched again first.
 //
-// Semantics are FIRE-AND-FORGET: the cursor advances past every event in the
-// batch even when a firing errors, so one persistently-failing rule can never
-// wedge the whole dispatch. The trade-off is no retry — a transient action
-// failure (e.g. a flaky exec) is logged and skipped, not replayed. A rule that
-// must not miss an event should be idempotent and reconcile from state.
+// Semantics are FIRE-AND-FORGET per firing: the cursor advances past every
+// event in the batch even when a firing errors, so one persistently-failing
+// rule can never wedge the whole dispatch. A transient action failure (e.g. a
+// flaky exec) is logged and skipped, not replayed; only an event whose
+// dispatch was interrupted is. A rule that must not miss an event should be
+// idempotent and reconcile from state. An event that cannot be dispatched at
+// all (the rules fail to load, the outbox cannot be written) stops the batch
+// there, so the next drain starts from it.
 func drainAutomationEvents(ctx context.Context, logger io.Writer) error {
 	if App == nil || App.RuleEngine == nil || App.EventLog == nil {
 		return nil
@@ -489,18 +557,32 @@ func drainAutomationEvents(ctx context.Context, logger io.Writer) error {
 		}
 		return nil
 	}
+	retried, err := App.RuleEngine.RetryDispatches(ctx)
+	for _, f := range retried {
+		fmt.Fprintf(logger, "    retried %s [%s]: %s %s\n", f.Event, f.Rule, f.Status, firingDetail(f))
+	}
+	if err != nil {
+		fmt.Fprintf(logger, "    automation-dispatch: retry open claims: %v\n", err)
+	}
 	events, err := App.EventLog.ReadSince(cursor)
 	if err != nil {
 		return fmt.Errorf("read events since cursor: %w", err)
 	}
 	newCursor := cursor
+	already := 0
 	for _, ev := range events {
 		if !ev.Timestamp.After(cursor) {
 			continue // boundary event already processed
 		}
-		firings, derr := App.RuleEngine.Dispatch(ctx, string(ev.Type), eventPayload(ev.Data))
+		firings, claimed, derr := App.RuleEngine.DispatchLogged(ctx, core.LoggedEvent{
+			ID: ev.ID(), Type: string(ev.Type), At: ev.Timestamp, Payload: ev.Payload(), Via: core.DispatchDrain,
+		})
 		if derr != nil {
 			fmt.Fprintf(logger, "    automation-dispatch: %v\n", derr)
+			break
+		}
+		if !claimed {
+			already++
 		}
 		for _, f := range firings {
 			fmt.Fprintf(logger, "    dispatch %s [%s]: %s %s\n", ev.Type, f.Rule, f.Status, firingDetail(f))
@@ -509,41 +591,22 @@ func drainAutomationEvents(ctx context.Context, logger io.Writer) error {
 			newCursor = ev.Timestamp
 		}
 	}
+	if already > 0 {
+		fmt.Fprintf(logger, "    automation-dispatch: %d event(s) already claimed inline\n", already)
+	}
 	if newCursor.After(cursor) {
 		if err := writeAutomationCursor(newCursor); err != nil {
 			return fmt.Errorf("advance automation cursor: %w", err)
 		}
 	}
-	return nil
-}
-
-// eventPayload flattens an event's Data map into string values for template
-// expansion.
-func eventPayload(data map[string]interface{}) map[string]string {
-	if len(data) == 0 {
-		return nil
-	}
-	out := make(map[string]string, len(data))
-	for k, v := range data {
-		out[k] = stringifyEventValue(v)
+	released, err := App.RuleEngine.FlushDebounced(ctx)
+	if err != nil {
+		return fmt.Errorf("release debounced firings: %w", err)
 	}
-	return out
-}
-
-// stringifyEventValue renders one event-payload value for template use. JSON
-// numbers arrive as float64; rendering them with strconv 'f' avoids scientific
-// notation and prints whole numbers without a trailing ".0", so a numeric id
-// substitutes cleanly (float64 precision limits on huge integers are inherent to
-// the JSON event log, not introduced here).
-func stringifyEventValue(v interface{}) string {
-	switch t := v.(type) {
-	case string:
-		return t
-	case float64:
-		return strconv.FormatFloat(t, 'f', -1, 64)
-	default:
-		return fmt.Sprintf("%v", v)
+	for _, f := range released {
+		fmt.Fprintf(logger, "    debounced %s [%s]: %s %s\n", f.Event, f.Rule, f.Status, firingDetail(f))
 	}
+	return nil
 }
 
 // firingDetail returns the human detail for a firing: its output when fired,
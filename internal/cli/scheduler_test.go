@@ -6,6 +6,10 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/scheduler"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
 )
 
 // TestSchedulerList_ShowsRuleJobs guards #178: `adb scheduler list` must show
@@ -88,3 +92,42 @@ func TestSchedulerPaths_UnderADB(t *testing.T) {
 		}
 	}
 }
+
+// TestRuleJobs_ScheduleCalendarAndMisfire: time rules become scheduled jobs
+// carrying the rule's cadence and misfire policy, with the workspace calendar
+// wired as Suppress unless the rule ignores it.
+func TestRuleJobs_ScheduleCalendarAndMisfire(t *testing.T) {
+	app, cleanup := setupEventsTest(t)
+	defer cleanup()
+	app.MergedConfig.Global.Automation = models.AutomationConfig{
+		Timezone:   "UTC",
+		QuietHours: []models.QuietHoursConfig{{Start: "22:00", End: "07:00"}},
+	}
+	if err := ruleStore().Save(models.RuleSet{Rules: []models.Rule{
+		{Name: "standup", On: models.RuleTrigger{Schedule: "0 9 * * MON-FRI", Misfire: models.MisfireSkip}, Run: models.RuleAction{Skill: "digest"}},
+		{Name: "pager", On: models.RuleTrigger{Schedule: "15m", IgnoreCalendar: true}, Run: models.RuleAction{Skill: "page"}},
+		{Name: "on-create", On: models.RuleTrigger{Event: "task.created"}, Run: models.RuleAction{Skill: "triage"}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var log bytes.Buffer
+	jobs := ruleJobs(&log)
+	if len(jobs) != 2 {
+		t.Fatalf("jobs = %d (%s), want the two time rules", len(jobs), log.String())
+	}
+	standup, pager := jobs[0], jobs[1]
+	from := time.Date(2026, 7, 3, 10, 0, 0, 0, time.UTC) // a Friday
+	if got := standup.Schedule.Next(from); !got.Equal(time.Date(2026, 7, 6, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("standup next = %v, want Monday 09:00 UTC", got)
+	}
+	if standup.Misfire != scheduler.MisfireSkip || standup.Suppress == nil {
+		t.Errorf("standup job = %+v", standup)
+	}
+	if _, quiet := standup.Suppress(time.Date(2026, 7, 3, 23, 0, 0, 0, time.UTC)); !quiet {
+		t.Error("23:00 should be inside the workspace quiet hours")
+	}
+	if got := pager.Schedule.Next(from); !got.Equal(from.Add(15*time.Minute)) || pager.Suppress != nil {
+		t.Errorf("pager job next = %v, suppress set = %v", got, pager.Suppress != nil)
+	}
+}
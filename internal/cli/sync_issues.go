@@ -2,13 +2,16 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/valter-silva-au/ai-dev-brain/internal/core"
 	"github.com/valter-silva-au/ai-dev-brain/internal/integration/issuesync"
 	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
 	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
 )
 
@@ -16,7 +19,9 @@ import (
 // issue sync (WS-E). Unlike the other `sync` subcommands (which regenerate
 // LOCAL context files), this one talks to a remote: it links each ticket
 // whose `repo:` names a real github.com / gitlab remote to an issue and
-// reconciles them last-writer-wins over title/body/labels/status/priority.
+// reconciles them over title/body/labels/status/priority: field by field
+// against the last agreed baseline once one is stored, last-writer-wins
+// before that.
 //
 // Auth is per-host and owned by the user's `gh` / `glab` login. This code
 // path never reads ~/.config/gh/hosts.yml, never accepts a --token flag, and
@@ -30,16 +35,37 @@ func newSyncIssuesCmd() *cobra.Command {
 	)
 	cmd := &cobra.Command{
 		Use:   "issues [--repo <platform/org/repo>] [--dry-run] [--direction both|push|pull]",
-		Short: "Reconcile adb tickets with GitHub/GitLab issues",
+		Short: "Reconcile adb tickets with GitHub/GitLab issues or Jira/Linear",
 		Long: `Reconcile each ticket whose repo names a real github.com or gitlab remote
-with its remote issue. Direction defaults to 'both' (last-writer-wins over
-title/body/labels/status/priority); --dry-run shows the plan without writing.
+with its remote issue. Direction defaults to 'both'; --dry-run shows the plan
+and a per-field diff without writing.
 
-_local tickets, absolute or relative local paths, and enterprise-internal hosts
-(anything that isn't a github.com/gitlab remote) are skipped.
+Title, body, status, priority and labels merge field by field against the
+values both sides last agreed on, so edits to different fields on each side
+both land. A field changed differently on both sides is recorded as a
+conflict and left alone until 'adb sync issues resolve' picks a side. A
+ticket synced before baselines were stored falls back to last-writer-wins
+once.
 
-Auth is per-host: this uses the host's gh / glab login. No token is ever
-read or stored by adb.`,
+Tickets matching an issue_trackers entry (in .taskrc, then .taskconfig) sync
+to that Jira project or Linear team instead, with status and priority mapped
+to the tracker's workflow states through status_map / priority_map:
+
+  issue_trackers:
+    - provider: jira
+      url: https://acme.atlassian.net
+      project: PLAT
+      repos: ["github.com/acme/*"]
+      email: me@acme.io
+      token: "$JIRA_API_TOKEN"
+
+Otherwise _local tickets, absolute or relative local paths, and
+enterprise-internal hosts (anything that isn't a github.com/gitlab remote)
+are skipped.
+
+GitHub/GitLab auth is per-host: this uses the host's gh / glab login. Jira
+and Linear tokens come from the environment variable named in token; adb
+never stores them.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if App == nil || App.BacklogManager == nil {
 				return fmt.Errorf("app not initialized")
@@ -57,34 +83,9 @@ read or stored by adb.`,
 				return fmt.Errorf("load backlog: %w", err)
 			}
 
-			ticketsDir := filepath.Join(App.BasePath, "tickets")
-			s := &issuesync.Syncer{
-				Body: func(t models.Task) string {
-					// Body is the ticket's context.md; best-effort (empty on
-					// miss). ResolveTicketDir handles any nesting depth so
-					// this works for legacy flat tickets and the WS-A
-					// nested correlation layout.
-					dir, rerr := core.ResolveTicketDir(ticketsDir, t.ID)
-					if rerr != nil {
-						return ""
-					}
-					b, _ := os.ReadFile(filepath.Join(dir, "context.md"))
-					return string(b)
-				},
-				WriteBody: func(t models.Task, remoteBody string) error {
-					// On a remote-wins pull, persist the remote body to the
-					// ticket's context.md (body is a bidirectional LWW field);
-					// otherwise the pulled body is silently dropped (#176).
-					dir, rerr := core.ResolveTicketDir(ticketsDir, t.ID)
-					if rerr != nil {
-						return rerr
-					}
-					return os.WriteFile(filepath.Join(dir, "context.md"), []byte(remoteBody), 0o644)
-				},
-				Write: func(t models.Task) error { return App.BacklogManager.UpdateTask(t) },
-				Log: func(evt string, data map[string]interface{}) {
-					App.EventLog.Log(observability.EventType(evt), data)
-				},
+			s, err := newIssueSyncer()
+			if err != nil {
+				return err
 			}
 
 			synced := 0
@@ -97,6 +98,9 @@ read or stored by adb.`,
 					synced++
 					fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s (%s)\n", res.TaskID, res.Action, res.Reason)
 				}
+				if dryRun {
+					printFieldDiff(cmd.OutOrStdout(), res.Changes)
+				}
 			}
 			mode := ""
 			if dryRun {
@@ -109,5 +113,185 @@ read or stored by adb.`,
 	cmd.Flags().StringVar(&repo, "repo", "", "Limit to one platform-qualified repo (e.g. github.com/org/repo)")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the reconcile plan without writing")
 	cmd.Flags().StringVar(&direction, "direction", "both", "Sync direction: both, push, or pull")
+	cmd.AddCommand(newSyncIssuesServeCmd(), newSyncIssuesResolveCmd())
+	return cmd
+}
+
+// newSyncIssuesResolveCmd creates `adb sync issues resolve`, which settles a
+// field-level conflict recorded by a sync and then syncs that one ticket so
+// the chosen values land on both sides straight away.
+func newSyncIssuesResolveCmd() *cobra.Command {
+	var takes []string
+	cmd := &cobra.Command{
+		Use:   "resolve <task-id> --take local|remote|<field>=local|remote",
+		Short: "Resolve an issue-sync field conflict",
+		Long: `Resolve the open issue-sync conflict for a ticket by choosing a side.
+
+--take local or --take remote applies to every conflicting field;
+--take <field>=<side> chooses per field (title, body, status, priority,
+labels). The flag repeats, and later values win:
+
+  adb sync issues resolve TASK-00042 --take remote --take title=local
+
+Every conflicting field needs a side. The ticket is synced right after.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if App == nil || App.BacklogManager == nil {
+				return fmt.Errorf("app not initialized")
+			}
+			if len(takes) == 0 {
+				return fmt.Errorf("--take is required")
+			}
+			s, err := newIssueSyncer()
+			if err != nil {
+				return err
+			}
+			conflict, open, err := s.State.Conflict(args[0])
+			if err != nil {
+				return err
+			}
+			if !open {
+				return fmt.Errorf("no open issue-sync conflict for %s", args[0])
+			}
+			take, err := parseTakes(takes, conflict)
+			if err != nil {
+				return err
+			}
+			tk, err := App.BacklogManager.GetTask(args[0])
+			if err != nil {
+				return fmt.Errorf("load task: %w", err)
+			}
+			if err := s.State.Resolve(args[0], take); err != nil {
+				return err
+			}
+			for _, fc := range conflict.Fields {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s: took %s\n", fc.Field, take[fc.Field])
+			}
+			res := s.SyncTask(*tk, issuesync.DirectionBoth, false)
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ %s resolved: %s (%s)\n", res.TaskID, res.Action, res.Reason)
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVar(&takes, "take", nil, "Side to keep: local, remote, or <field>=local|remote (repeatable)")
 	return cmd
 }
+
+// parseTakes turns --take values into a side per conflicting field.
+func parseTakes(takes []string, conflict issuesync.Conflict) (map[string]issuesync.Side, error) {
+	parseSide := func(v string) (issuesync.Side, error) {
+		switch side := issuesync.Side(v); side {
+		case issuesync.SideLocal, issuesync.SideRemote:
+			return side, nil
+		}
+		return "", fmt.Errorf("invalid --take side %q (must be local or remote)", v)
+	}
+	take := make(map[string]issuesync.Side)
+	for _, t := range takes {
+		field, value, perField := strings.Cut(t, "=")
+		if !perField {
+			side, err := parseSide(t)
+			if err != nil {
+				return nil, err
+			}
+			for _, fc := range conflict.Fields {
+				take[fc.Field] = side
+			}
+			continue
+		}
+		side, err := parseSide(value)
+		if err != nil {
+			return nil, err
+		}
+		known := false
+		for _, fc := range conflict.Fields {
+			known = known || fc.Field == field
+		}
+		if !known {
+			return nil, fmt.Errorf("%s has no conflict on field %q", conflict.TaskID, field)
+		}
+		take[field] = side
+	}
+	return take, nil
+}
+
+// printFieldDiff prints a dry-run's per-field three-way diff, skipping
+// fields neither side changed.
+func printFieldDiff(w io.Writer, changes []issuesync.FieldChange) {
+	for _, c := range changes {
+		if c.Take == issuesync.SideNone {
+			continue
+		}
+		fmt.Fprintf(w, "      %s: base %q | local %q | remote %q => %s\n",
+			c.Field, diffValue(c.Base), diffValue(c.Local), diffValue(c.Remote), c.Take)
+	}
+}
+
+// diffValue shortens a field value to its first line, capped at 40 runes.
+func diffValue(v string) string {
+	v, _, multiline := strings.Cut(v, "\n")
+	if r := []rune(v); len(r) > 40 {
+		return string(r[:40]) + "…"
+	}
+	if multiline {
+		return v + "…"
+	}
+	return v
+}
+
+// issueTrackerConfigs returns the configured Jira/Linear trackers, the
+// workspace's .taskrc entries ahead of the global ones.
+func issueTrackerConfigs() []models.IssueTrackerConfig {
+	if App == nil || App.MergedConfig == nil {
+		return nil
+	}
+	var cfgs []models.IssueTrackerConfig
+	if App.MergedConfig.Repo != nil {
+		cfgs = append(cfgs, App.MergedConfig.Repo.IssueTrackers...)
+	}
+	if App.MergedConfig.Global != nil {
+		cfgs = append(cfgs, App.MergedConfig.Global.IssueTrackers...)
+	}
+	return cfgs
+}
+
+// newIssueSyncer wires an issuesync.Syncer to the workspace: bodies live in
+// each ticket's context.md, tasks in the backlog, decisions in the event log,
+// per-field baselines and conflicts under .adb/, and tickets matching an
+// issue_trackers entry go to Jira/Linear.
+func newIssueSyncer() (*issuesync.Syncer, error) {
+	trackers, err := issuesync.NewTrackers(issueTrackerConfigs())
+	if err != nil {
+		return nil, err
+	}
+	ticketsDir := filepath.Join(App.BasePath, "tickets")
+	return &issuesync.Syncer{
+		Trackers: trackers,
+		Body: func(t models.Task) string {
+			// Body is the ticket's context.md; best-effort (empty on
+			// miss). ResolveTicketDir handles any nesting depth so
+			// this works for legacy flat tickets and the WS-A
+			// nested correlation layout.
+			dir, rerr := core.ResolveTicketDir(ticketsDir, t.ID)
+			if rerr != nil {
+				return ""
+			}
+			b, _ := os.ReadFile(filepath.Join(dir, "context.md"))
+			return string(b)
+		},
+		WriteBody: func(t models.Task, remoteBody string) error {
+			// On a remote-wins pull, persist the remote body to the
+			// ticket's context.md (body is a bidirectional LWW field);
+			// otherwise the pulled body is silently dropped (#176).
+			dir, rerr := core.ResolveTicketDir(ticketsDir, t.ID)
+			if rerr != nil {
+				return rerr
+			}
+			return os.WriteFile(filepath.Join(dir, "context.md"), []byte(remoteBody), 0o644)
+		},
+		Write: func(t models.Task) error { return App.BacklogManager.UpdateTask(t) },
+		State: issuesync.NewStateStore(App.StatePath(statedir.FileIssueSyncState)),
+		Log: func(evt string, data map[string]interface{}) {
+			App.EventLog.Log(observability.EventType(evt), data)
+		},
+	}, nil
+}
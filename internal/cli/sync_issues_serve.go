@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/valter-silva-au/ai-dev-brain/internal/integration/issuesync"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// Environment variables holding the webhook secrets when no --*-secret-file
+// is given.
+const (
+	envGitHubWebhookSecret = "ADB_GITHUB_WEBHOOK_SECRET"
+	envGitLabWebhookSecret = "ADB_GITLAB_WEBHOOK_SECRET"
+)
+
+// newSyncIssuesServeCmd creates `adb sync issues serve`, the webhook-driven
+// counterpart to the poll-style `adb sync issues`.
+func newSyncIssuesServeCmd() *cobra.Command {
+	var (
+		listen           string
+		githubSecretFile string
+		gitlabSecretFile string
+		direction        string
+	)
+	cmd := &cobra.Command{
+		Use:   "serve [--listen :8787] [--github-secret-file <f>] [--gitlab-secret-file <f>]",
+		Short: "Receive GitHub/GitLab issue webhooks and sync the affected ticket",
+		Long: `Listen for issue webhooks and reconcile just the ticket linked to the issue,
+so a teammate's label or state change lands in backlog.yaml within seconds.
+
+  POST /github   GitHub "Issues" events, verified with X-Hub-Signature-256
+                 (HMAC-SHA256 of the body with the webhook secret)
+  POST /gitlab   GitLab "Issues events", verified with X-Gitlab-Token
+
+The delivered issue is used as the remote side of the reconcile (mapped
+through the adb:<status> label exactly like a pull), so no gh/glab call is
+made to read it; pushes, when local is newer, still go through gh/glab.
+Each decision is logged as issue.synced / issue.conflict. Issues not linked to
+a ticket are acknowledged and ignored.
+
+Secrets come from --github-secret-file / --gitlab-secret-file, or the
+ADB_GITHUB_WEBHOOK_SECRET / ADB_GITLAB_WEBHOOK_SECRET environment variables.
+A provider without a secret is disabled: unsigned deliveries are never
+accepted.
+
+Examples:
+  ADB_GITHUB_WEBHOOK_SECRET=... adb sync issues serve --listen :8787
+  adb sync issues serve --gitlab-secret-file ~/.config/adb/gitlab-hook`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if App == nil || App.BacklogManager == nil {
+				return fmt.Errorf("app not initialized")
+			}
+			dir := issuesync.Direction(direction)
+			switch dir {
+			case issuesync.DirectionBoth, issuesync.DirectionPush, issuesync.DirectionPull:
+			default:
+				return fmt.Errorf("invalid --direction %q (must be both, push, or pull)", direction)
+			}
+			githubSecret, err := webhookSecret(githubSecretFile, envGitHubWebhookSecret)
+			if err != nil {
+				return err
+			}
+			gitlabSecret, err := webhookSecret(gitlabSecretFile, envGitLabWebhookSecret)
+			if err != nil {
+				return err
+			}
+			if githubSecret == "" && gitlabSecret == "" {
+				return fmt.Errorf("no webhook secret configured (set %s or %s, or pass --github-secret-file / --gitlab-secret-file)",
+					envGitHubWebhookSecret, envGitLabWebhookSecret)
+			}
+
+			handler, err := newIssueWebhookHandler(githubSecret, gitlabSecret, dir, cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			ln, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("listen on %s: %w", listen, err)
+			}
+			for _, p := range []struct{ name, secret string }{{"github", githubSecret}, {"gitlab", gitlabSecret}} {
+				if p.secret != "" {
+					fmt.Fprintf(cmd.OutOrStdout(), "Accepting %s webhooks on http://%s/%s\n", p.name, ln.Addr(), p.name)
+				}
+			}
+			return serveIssueWebhooks(ctx, ln, handler)
+		},
+	}
+	cmd.Flags().StringVar(&listen, "listen", ":8787", "Address to receive webhooks on")
+	cmd.Flags().StringVar(&githubSecretFile, "github-secret-file", "", "File holding the GitHub webhook secret (default $"+envGitHubWebhookSecret+")")
+	cmd.Flags().StringVar(&gitlabSecretFile, "gitlab-secret-file", "", "File holding the GitLab webhook secret token (default $"+envGitLabWebhookSecret+")")
+	cmd.Flags().StringVar(&direction, "direction", "both", "Sync direction: both, push, or pull")
+	return cmd
+}
+
+// webhookSecret reads a secret from file (trimmed) or, without one, from env.
+func webhookSecret(file, env string) (string, error) {
+	if file == "" {
+		return os.Getenv(env), nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("read webhook secret: %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("webhook secret file %s is empty", file)
+	}
+	return secret, nil
+}
+
+// newIssueWebhookHandler wires the issuesync webhook handler to the backlog
+// and the workspace syncer, logging one line per delivery to out.
+func newIssueWebhookHandler(githubSecret, gitlabSecret string, dir issuesync.Direction, out io.Writer) (*issuesync.WebhookHandler, error) {
+	s, err := newIssueSyncer()
+	if err != nil {
+		return nil, err
+	}
+	return &issuesync.WebhookHandler{
+		GitHubSecret: githubSecret,
+		GitLabSecret: gitlabSecret,
+		Tasks: func() ([]models.Task, error) {
+			backlog, err := App.BacklogManager.Load()
+			if err != nil {
+				return nil, err
+			}
+			return backlog.Tasks, nil
+		},
+		Sync: func(t models.Task, remote issuesync.RemoteIssue) issuesync.Result {
+			return s.SyncTaskFromRemote(t, remote, dir, false)
+		},
+		Logf: func(format string, args ...interface{}) {
+			fmt.Fprintf(out, time.Now().Format("15:04:05")+" "+format+"\n", args...)
+		},
+	}, nil
+}
+
+// serveIssueWebhooks runs the webhook server on ln until ctx is done.
+func serveIssueWebhooks(ctx context.Context, ln net.Listener, handler http.Handler) error {
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
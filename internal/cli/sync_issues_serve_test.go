@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/integration/issuesync"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// TestSyncIssuesServe_WebhookUpdatesBacklog posts a signed GitHub "labeled"
+// delivery to the workspace-wired handler: the linked ticket's status follows
+// the teammate's adb: label in backlog.yaml and issue.synced is logged.
+func TestSyncIssuesServe_WebhookUpdatesBacklog(t *testing.T) {
+	tmp, cleanup := setupSyncTest(t)
+	defer cleanup()
+
+	backlog := "tasks:\n" +
+		"  - id: TASK-00042\n" +
+		"    title: Retry deliveries\n" +
+		"    type: feat\n" +
+		"    status: in_progress\n" +
+		"    priority: P1\n" +
+		"    repo: github.com/acme/widgets\n" +
+		"    remote_issue: 42\n" +
+		"    updated: 2026-03-01T00:00:00Z\n"
+	if err := os.WriteFile(filepath.Join(tmp, "backlog.yaml"), []byte(backlog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Record the last-synced baseline so only the remote side has changed.
+	seeded, err := App.BacklogManager.GetTask("TASK-00042")
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	backlog += "    sync_hash: " + issuesync.SyncHash(*seeded, "") + "\n"
+	if err := os.WriteFile(filepath.Join(tmp, "backlog.yaml"), []byte(backlog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	body := []byte(`{"action":"labeled","issue":{"number":42,"html_url":"https://github.com/acme/widgets/issues/42",` +
+		`"title":"Retry deliveries","body":"","state":"open","updated_at":"2026-03-02T14:05:11Z",` +
+		`"labels":[{"name":"adb:blocked"},{"name":"priority:P1"}]},` +
+		`"repository":{"full_name":"acme/widgets","html_url":"https://github.com/acme/widgets"}}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+
+	var log bytes.Buffer
+	h, err := newIssueWebhookHandler("s3cret", "", issuesync.DirectionBoth, &log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/github", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "issues")
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	tk, err := App.BacklogManager.GetTask("TASK-00042")
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if tk.Status != models.TaskStatusBlocked {
+		t.Errorf("status = %q, want blocked", tk.Status)
+	}
+	if !strings.Contains(log.String(), "TASK-00042 update_local") {
+		t.Errorf("delivery log = %q", log.String())
+	}
+	events, _ := App.EventLog.ReadByType(observability.EventIssueSynced)
+	if len(events) != 1 || events[0].Data["task_id"] != "TASK-00042" {
+		t.Errorf("issue.synced events = %+v", events)
+	}
+}
+
+func TestSyncIssuesServe_RequiresASecret(t *testing.T) {
+	_, cleanup := setupSyncTest(t)
+	defer cleanup()
+	t.Setenv(envGitHubWebhookSecret, "")
+	t.Setenv(envGitLabWebhookSecret, "")
+
+	cmd := newSyncIssuesServeCmd()
+	cmd.SetArgs([]string{"--listen", "127.0.0.1:0"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "no webhook secret") {
+		t.Fatalf("err = %v, want a missing-secret error", err)
+	}
+}
+
+func TestWebhookSecret_FileBeatsEnv(t *testing.T) {
+	t.Setenv(envGitLabWebhookSecret, "from-env")
+	if got, _ := webhookSecret("", envGitLabWebhookSecret); got != "from-env" {
+		t.Errorf("env secret = %q", got)
+	}
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := webhookSecret(path, envGitLabWebhookSecret); got != "from-file" {
+		t.Errorf("file secret = %q", got)
+	}
+	empty := filepath.Join(t.TempDir(), "empty")
+	_ = os.WriteFile(empty, nil, 0o600)
+	if _, err := webhookSecret(empty, envGitLabWebhookSecret); err == nil {
+		t.Error("empty secret file should be an error")
+	}
+}
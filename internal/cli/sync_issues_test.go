@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/valter-silva-au/ai-dev-brain/internal"
+	"github.com/valter-silva-au/ai-dev-brain/internal/integration/issuesync"
+	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
 )
 
 // setupSyncTest wires a temp workspace + global App the way sync_test.go's
@@ -96,3 +101,111 @@ func TestSyncIssues_AcceptsAllValidDirections(t *testing.T) {
 		})
 	}
 }
+
+// TestSyncIssues_IssueTrackersFromConfig: .taskrc trackers are consulted
+// before .taskconfig ones, and a broken entry fails the run up front rather
+// than once per ticket.
+func TestSyncIssues_IssueTrackersFromConfig(t *testing.T) {
+	tmp, cleanup := setupSyncTest(t)
+	defer cleanup()
+	if App.MergedConfig == nil || App.MergedConfig.Global == nil {
+		t.Skip("app has no merged config")
+	}
+	if App.MergedConfig.Repo == nil {
+		App.MergedConfig.Repo = &models.RepoConfig{}
+	}
+	t.Setenv("LINEAR_TOKEN", "lin_api_x")
+	App.MergedConfig.Global.IssueTrackers = []models.IssueTrackerConfig{{Provider: "linear", Project: "ORG", Token: "$LINEAR_TOKEN"}}
+	App.MergedConfig.Repo.IssueTrackers = []models.IssueTrackerConfig{{Provider: "linear", Project: "ENG", Token: "$LINEAR_TOKEN", Repos: []string{"github.com/acme/*"}}}
+
+	s, err := newIssueSyncer()
+	if err != nil {
+		t.Fatalf("newIssueSyncer: %v", err)
+	}
+	if len(s.Trackers) != 2 || s.Trackers[0].Project != "ENG" || s.Trackers[1].Project != "ORG" {
+		t.Errorf("trackers = %+v, want the .taskrc entry first", s.Trackers)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, "backlog.yaml"), []byte("tasks: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	App.MergedConfig.Global.IssueTrackers = append(App.MergedConfig.Global.IssueTrackers, models.IssueTrackerConfig{Provider: "trello", Project: "X", Token: "t"})
+	cmd := newSyncIssuesCmd()
+	cmd.SetArgs([]string{"--dry-run"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "trello") {
+		t.Fatalf("err = %v, want the unknown provider reported", err)
+	}
+}
+
+// TestSyncIssuesResolve_SettlesConflict: a blanket --take plus a per-field
+// override rewrites each conflicting field's baseline to the side NOT taken
+// (so the next sync carries the chosen side across) and clears the record.
+func TestSyncIssuesResolve_SettlesConflict(t *testing.T) {
+	tmp, cleanup := setupSyncTest(t)
+	defer cleanup()
+	backlog := "tasks:\n  - id: TASK-00042\n    title: Retry deliveries\n    type: feat\n    status: in_progress\n    priority: P1\n"
+	if err := os.WriteFile(filepath.Join(tmp, "backlog.yaml"), []byte(backlog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	st := issuesync.NewStateStore(App.StatePath(statedir.FileIssueSyncState))
+	base := issuesync.Fields{Title: "Retry", Body: "b"}
+	if err := st.Commit("TASK-00042", base, &issuesync.Conflict{Fields: []issuesync.FieldChange{
+		{Field: issuesync.FieldTitle, Base: "Retry", Local: "Retry deliveries", Remote: "Retry webhooks", Take: issuesync.SideConflict},
+		{Field: issuesync.FieldBody, Base: "b", Local: "b-local", Remote: "b-remote", Take: issuesync.SideConflict},
+	}}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, args := range map[string][]string{
+		"no take":       {"TASK-00042"},
+		"bad side":      {"TASK-00042", "--take", "theirs"},
+		"unknown field": {"TASK-00042", "--take", "status=local"},
+		"no conflict":   {"TASK-00001", "--take", "local"},
+	} {
+		cmd := newSyncIssuesResolveCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := func() error {
+		cmd := newSyncIssuesResolveCmd()
+		cmd.SetArgs([]string{"TASK-00042", "--take", "title=local"})
+		return cmd.Execute()
+	}(); err == nil || !strings.Contains(err.Error(), "body") {
+		t.Errorf("err = %v, want the unchosen body field named", err)
+	}
+
+	cmd := newSyncIssuesResolveCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"TASK-00042", "--take", "remote", "--take", "title=local"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if !strings.Contains(out.String(), "title: took local") || !strings.Contains(out.String(), "body: took remote") {
+		t.Errorf("output:\n%s", out.String())
+	}
+	b, _, _ := st.Baseline("TASK-00042")
+	if b.Title != "Retry webhooks" || b.Body != "b-local" {
+		t.Errorf("baseline = %+v", b)
+	}
+	if _, open, _ := st.Conflict("TASK-00042"); open {
+		t.Error("conflict still open")
+	}
+}
+
+func TestPrintFieldDiff(t *testing.T) {
+	var out bytes.Buffer
+	printFieldDiff(&out, []issuesync.FieldChange{
+		{Field: issuesync.FieldTitle, Base: "a", Local: "a", Remote: "a", Take: issuesync.SideNone},
+		{Field: issuesync.FieldBody, Base: "line one\nline two", Local: "line one\nline two", Remote: strings.Repeat("x", 50), Take: issuesync.SideRemote},
+		{Field: issuesync.FieldStatus, Base: "backlog", Local: "review", Remote: "blocked", Take: issuesync.SideConflict},
+	})
+	want := "      body: base \"line one…\" | local \"line one…\" | remote \"" + strings.Repeat("x", 40) + "…\" => remote\n" +
+		"      status: base \"backlog\" | local \"review\" | remote \"blocked\" => conflict\n"
+	if out.String() != want {
+		t.Errorf("diff =\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -123,6 +123,13 @@ func runTaskWithRuflo(cmd *cobra.Command, taskID, rufloBin string, rufloArgs []s
 		"ADB_TASK_WORKTREE="+worktree,
 	)
 
+	// With tracing on, the dispatched agent is a root span and its hooks
+	// join the trace through the inherited TRACEPARENT.
+	traceparent, finishTrace := newHookTracer().startAgentSession(taskID, worktree, rufloBin)
+	if traceparent != "" {
+		env = append(env, "TRACEPARENT="+traceparent)
+	}
+
 	emitDispatchEvent("agent.session_started", taskID, worktree, rufloBin, rufloArgs, nil)
 
 	runCtx := RunContext{
@@ -135,6 +142,7 @@ func runTaskWithRuflo(cmd *cobra.Command, taskID, rufloBin string, rufloArgs []s
 	runErr := taskRunWithRufloCommander.Run(runCtx, rufloBin, rufloArgs)
 
 	emitDispatchEvent("agent.session_ended", taskID, worktree, rufloBin, rufloArgs, runErr)
+	finishTrace(runErr)
 
 	return runErr
 }
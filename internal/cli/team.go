@@ -186,6 +186,7 @@ func NewMCPCmd() *cobra.Command {
 
 	mcpCmd.AddCommand(newMCPCheckCmd())
 	mcpCmd.AddCommand(newMCPServeCmd())
+	mcpCmd.AddCommand(newMCPTokenCmd())
 
 	return mcpCmd
 }
@@ -0,0 +1,21 @@
+// Package configenv resolves config values that name an environment variable.
+// A .taskconfig secret — an API key, a tracker token, a webhook URL or header —
+// may be written as "$ENV_VAR" so the file never holds the secret itself. It
+// is a stdlib-only leaf so memory, the integrations and the CLI share the one
+// convention without an import cycle.
+package configenv
+
+import (
+	"os"
+	"strings"
+)
+
+// Expand resolves a "$ENV_VAR" value from the environment (empty when the
+// variable is unset), leaving any other string untouched. Only a whole-value
+// reference is expanded: "Bearer $TOKEN" is taken literally.
+func Expand(v string) string {
+	if strings.HasPrefix(v, "$") {
+		return os.Getenv(strings.TrimPrefix(v, "$"))
+	}
+	return v
+}
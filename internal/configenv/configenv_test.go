@@ -0,0 +1,18 @@
+package configenv
+
+import "testing"
+
+func TestExpand(t *testing.T) {
+	t.Setenv("ADB_CONFIGENV_TEST", "s3cret")
+	for in, want := range map[string]string{
+		"$ADB_CONFIGENV_TEST":        "s3cret",
+		"$ADB_CONFIGENV_UNSET":       "",
+		"literal":                    "literal",
+		"Bearer $ADB_CONFIGENV_TEST": "Bearer $ADB_CONFIGENV_TEST",
+		"":                           "",
+	} {
+		if got := Expand(in); got != want {
+			t.Errorf("Expand(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -87,6 +87,10 @@ type HookEngineOptions struct {
 	Operator OperatorConfig
 	Memory   MemoryHookConfig
 	SpecGate SpecGateConfig
+	// GateObserver, when set, is told the outcome and timing of each
+	// task-completed quality gate ("go test", "go build", "go vet"). The CLI
+	// wires it to emit tracing spans; core stays free of observability.
+	GateObserver func(gate string, start, end time.Time, err error)
 }
 
 // operatorWithDefaults fills unset file names with the conventional
@@ -391,9 +395,11 @@ func (he *HookEngine) ProcessTaskCompleted(event *hooks.TaskCompletedEvent) erro
 }
 
 // indexSessionIntoMemory upserts a captured transcript into the
-// vector-memory namespace sessions/<session-id>. No-op when memory is
-// disabled, indexer is nil, or the session id / transcript is empty.
-func (he *HookEngine) indexSessionIntoMemory(sessionID, transcript string) {
+// vector-memory namespace sessions/<session-id>, recording the current task
+// (when there is one) so the session follows it on archive/delete. No-op
+// when memory is disabled, indexer is nil, or the session id / transcript
+// is empty.
+func (he *HookEngine) indexSessionIntoMemory(sessionID, taskID, transcript string) {
 	if !he.opts.Memory.Enabled || he.opts.Memory.Indexer == nil {
 		return
 	}
@@ -402,8 +408,11 @@ func (he *HookEngine) indexSessionIntoMemory(sessionID, transcript string) {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	ns := "sessions/" + sessionID
+	ns := MemorySessionsPrefix + sessionID
 	meta := map[string]string{"source": "session-end"}
+	if taskID != "" {
+		meta[MetaTaskID] = taskID
+	}
 	if err := he.opts.Memory.Indexer.Upsert(ctx, ns, "transcript", transcript, meta); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: memory auto-index %s/transcript: %v\n", ns, err)
 	}
@@ -420,7 +429,7 @@ func (he *HookEngine) indexTaskIntoMemory(taskID string) {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	ns := "tickets/" + taskID
+	ns := MemoryTicketsPrefix + taskID
 	meta := map[string]string{"source": "task-completed"}
 
 	taskDir := filepath.Join(he.basePath, "tickets", taskID)
@@ -444,10 +453,10 @@ func (he *HookEngine) ProcessSessionEnd(event *hooks.SessionEndEvent) error {
 	}
 
 	// Capture transcript if available
-	var transcriptStr string
+	var transcriptStr, taskID string
 	if transcript, ok := event.Metadata["transcript"].(string); ok {
 		transcriptStr = transcript
-		taskID := he.getCurrentTaskID()
+		taskID = he.getCurrentTaskID()
 		if taskID != "" {
 			taskDir := filepath.Join(he.basePath, "tickets", taskID)
 			if err := hooks.CaptureTranscript(taskDir, event.SessionID, transcript); err != nil {
@@ -458,7 +467,7 @@ func (he *HookEngine) ProcessSessionEnd(event *hooks.SessionEndEvent) error {
 
 	// Auto-index the transcript into vector memory under
 	// sessions/<session-id>. Non-blocking: failures log and continue.
-	he.indexSessionIntoMemory(event.SessionID, transcriptStr)
+	he.indexSessionIntoMemory(event.SessionID, taskID, transcriptStr)
 
 	// Update context.md with session summary
 	if err := he.updateContextOnSessionEnd(event); err != nil {
@@ -623,27 +632,42 @@ func (he *HookEngine) checkVet() error {
 // phaseAQualityGates performs blocking quality checks
 func (he *HookEngine) phaseAQualityGates(event *hooks.TaskCompletedEvent) error {
 	// Check tests pass
-	cmd := exec.Command("go", "test", "./...", "-count=1")
-	cmd.Env = append(os.Environ(), "ADB_HOOK_ACTIVE=1")
-	cmd.Dir = he.basePath
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("tests failed: %s", output)
+	if err := he.runGate("go test", func() error {
+		cmd := exec.Command("go", "test", "./...", "-count=1")
+		cmd.Env = append(os.Environ(), "ADB_HOOK_ACTIVE=1")
+		cmd.Dir = he.basePath
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s", output)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("tests failed: %w", err)
 	}
 
 	// Check build
-	if err := he.checkBuild(); err != nil {
+	if err := he.runGate("go build", he.checkBuild); err != nil {
 		return fmt.Errorf("build failed: %w", err)
 	}
 
 	// Check vet
-	if err := he.checkVet(); err != nil {
+	if err := he.runGate("go vet", he.checkVet); err != nil {
 		return fmt.Errorf("go vet failed: %w", err)
 	}
 
 	return nil
 }
 
+// runGate runs one quality gate and reports it to the GateObserver.
+func (he *HookEngine) runGate(name string, gate func() error) error {
+	start := time.Now()
+	err := gate()
+	if he.opts.GateObserver != nil {
+		he.opts.GateObserver(name, start, time.Now(), err)
+	}
+	return err
+}
+
 // phaseBKnowledgeExtraction performs non-blocking knowledge work
 func (he *HookEngine) phaseBKnowledgeExtraction(event *hooks.TaskCompletedEvent) error {
 	taskDir := filepath.Join(he.basePath, "tickets", event.TaskID)
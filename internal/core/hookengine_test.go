@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/valter-silva-au/ai-dev-brain/internal/hooks"
 )
@@ -927,6 +928,62 @@ func TestHookEngine_ProcessTaskCompleted(t *testing.T) {
 	})
 }
 
+func TestHookEngine_GateObserverSeesEachQualityGate(t *testing.T) {
+	writeModule := func(t *testing.T, src string) string {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module gatetest\n\ngo 1.21\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	type observed struct {
+		gate string
+		err  error
+	}
+	run := func(t *testing.T, dir string) ([]observed, error) {
+		var got []observed
+		engine := NewHookEngineWithOptions(dir, HookEngineOptions{
+			GateObserver: func(gate string, start, end time.Time, err error) {
+				if end.Before(start) {
+					t.Errorf("%s: end %v before start %v", gate, end, start)
+				}
+				got = append(got, observed{gate, err})
+			},
+		})
+		err := engine.phaseAQualityGates(&hooks.TaskCompletedEvent{TaskID: "TASK-001"})
+		return got, err
+	}
+	t.Setenv("ADB_HOOK_ACTIVE", "")
+
+	t.Run("all gates pass", func(t *testing.T) {
+		got, err := run(t, writeModule(t, "package main\n\nfunc main() {}\n"))
+		if err != nil {
+			t.Fatalf("phaseAQualityGates() error = %v", err)
+		}
+		if len(got) != 3 || got[0].gate != "go test" || got[1].gate != "go build" || got[2].gate != "go vet" {
+			t.Fatalf("observed %+v, want go test, go build, go vet", got)
+		}
+		for _, o := range got {
+			if o.err != nil {
+				t.Errorf("%s reported %v", o.gate, o.err)
+			}
+		}
+	})
+
+	t.Run("failing gate stops the run", func(t *testing.T) {
+		got, err := run(t, writeModule(t, "package main\n\nfunc main() {\n"))
+		if err == nil {
+			t.Fatal("phaseAQualityGates() succeeded on a module that does not compile")
+		}
+		if len(got) != 1 || got[0].gate != "go test" || got[0].err == nil {
+			t.Fatalf("observed %+v, want one failed go test", got)
+		}
+	})
+}
+
 func TestHookEngine_ProcessSessionEnd(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "hookengine-test-*")
 	if err != nil {
@@ -996,7 +1053,7 @@ func TestHookEngine_MemoryIndex_DisabledByDefault(t *testing.T) {
 	os.Unsetenv("ADB_HOOK_ACTIVE")
 
 	engine.indexTaskIntoMemory("TASK-00001")
-	engine.indexSessionIntoMemory("S-00001", "some transcript content")
+	engine.indexSessionIntoMemory("S-00001", "", "some transcript content")
 
 	if got := len(fake.Calls()); got != 0 {
 		t.Errorf("expected no indexer calls when disabled, got %d: %v", got, fake.Calls())
@@ -1057,7 +1114,7 @@ func TestHookEngine_MemoryIndex_SessionEndIndexesTranscript(t *testing.T) {
 	})
 	os.Unsetenv("ADB_HOOK_ACTIVE")
 
-	engine.indexSessionIntoMemory("S-20260511", "User: start\nAssistant: Did the work\nUser: thanks")
+	engine.indexSessionIntoMemory("S-20260511", "TASK-00007", "User: start\nAssistant: Did the work\nUser: thanks")
 
 	calls := fake.Calls()
 	if len(calls) != 1 {
@@ -1069,6 +1126,9 @@ func TestHookEngine_MemoryIndex_SessionEndIndexesTranscript(t *testing.T) {
 	if calls[0].Key != "transcript" {
 		t.Errorf("wrong key: got %q", calls[0].Key)
 	}
+	if calls[0].Meta[MetaTaskID] != "TASK-00007" {
+		t.Errorf("session not linked to its task: meta %v", calls[0].Meta)
+	}
 	if !strings.Contains(calls[0].Content, "Did the work") {
 		t.Errorf("content missing expected marker: %q", calls[0].Content)
 	}
@@ -1082,9 +1142,9 @@ func TestHookEngine_MemoryIndex_EmptyInputsAreNoOps(t *testing.T) {
 	})
 	os.Unsetenv("ADB_HOOK_ACTIVE")
 
-	engine.indexTaskIntoMemory("")                  // empty task ID
-	engine.indexSessionIntoMemory("", "transcript") // empty session ID
-	engine.indexSessionIntoMemory("S-1", "")        // empty transcript
+	engine.indexTaskIntoMemory("")                      // empty task ID
+	engine.indexSessionIntoMemory("", "", "transcript") // empty session ID
+	engine.indexSessionIntoMemory("S-1", "", "")        // empty transcript
 
 	if got := len(fake.Calls()); got != 0 {
 		t.Errorf("expected no calls for empty inputs, got %d: %v", got, fake.Calls())
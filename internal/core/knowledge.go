@@ -1,57 +1,354 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
 
+	"github.com/valter-silva-au/ai-dev-brain/internal/storage"
 	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
 	"gopkg.in/yaml.v3"
 )
 
-// KnowledgeExtractor extracts learnings, decisions, and gotchas from completed tasks
+// KnowledgeExtractor extracts learnings, decisions, and gotchas from tasks.
+// It parses the conventions in a ticket's markdown (context.md, design.md,
+// notes.md), its captured transcripts and the workspace sessions recorded
+// against the task (see knowledgeparse.go), and can hand the same documents
+// to an optional KnowledgeSummarizer. Sessions are listed through a
+// storage.SessionStoreManager, so the extractor reads them the way the
+// session commands write them.
 type KnowledgeExtractor struct {
-	basePath string
+	basePath   string
+	summarizer KnowledgeSummarizer
+	sessions   storage.SessionStoreManager
 }
 
-// NewKnowledgeExtractor creates a new knowledge extractor
+// KnowledgeSummarizer is an optional backend, typically an LLM, that reads a
+// task's documents and proposes what the section parser cannot see: a
+// decision stated in plain prose, a summary. Its entries are deduped and
+// merged like parsed ones and their provenance is marked "summarizer".
+// Implementations bound their own run time.
+type KnowledgeSummarizer interface {
+	Summarize(ctx context.Context, in KnowledgeSummaryInput) (*models.ExtractedKnowledge, error)
+}
+
+// KnowledgeDocument is one source handed to a KnowledgeSummarizer; Path is
+// relative to the workspace root.
+type KnowledgeDocument struct {
+	Path    string
+	Content string
+}
+
+// KnowledgeSummaryInput is what a KnowledgeSummarizer is asked to read.
+type KnowledgeSummaryInput struct {
+	TaskID    string
+	Documents []KnowledgeDocument
+}
+
+// Provenance extractor names.
+const (
+	ExtractorParser     = "parser"
+	ExtractorSummarizer = "summarizer"
+)
+
+// ticketKnowledgeSources are the ticket markdown files the parser reads, in
+// the order their entries win a dedupe.
+var ticketKnowledgeSources = []string{"context.md", "design.md", "notes.md"}
+
+// NewKnowledgeExtractor creates a new knowledge extractor. It reads
+// workspace sessions from the file session store under basePath/sessions
+// until SetSessionStore replaces it.
 func NewKnowledgeExtractor(basePath string) *KnowledgeExtractor {
 	return &KnowledgeExtractor{
 		basePath: basePath,
+		sessions: storage.NewFileSessionStoreManager(filepath.Join(basePath, "sessions")),
 	}
 }
 
-// ExtractFromTask extracts knowledge from a completed task
-// It analyzes the task artifacts (context.md, notes.md, etc.) and creates a decisions.yaml file
+// SetSummarizer plugs in a summarizer backend; nil disables it.
+func (ke *KnowledgeExtractor) SetSummarizer(s KnowledgeSummarizer) {
+	ke.summarizer = s
+}
+
+// SetSessionStore sets the store workspace sessions are listed from
+// (normally App.SessionStoreManager); nil skips them.
+func (ke *KnowledgeExtractor) SetSessionStore(s storage.SessionStoreManager) {
+	ke.sessions = s
+}
+
+// ticketDir returns the task's ticket directory: tickets/<id> when it
+// exists, else the nested directory ResolveTicketDir finds, else
+// tickets/<id>.
+func (ke *KnowledgeExtractor) ticketDir(taskID string) string {
+	ticketsDir := filepath.Join(ke.basePath, "tickets")
+	flat := filepath.Join(ticketsDir, taskID)
+	if _, err := os.Stat(flat); err == nil {
+		return flat
+	}
+	if dir, err := ResolveTicketDir(ticketsDir, taskID); err == nil {
+		return dir
+	}
+	return flat
+}
+
+// ExtractFromTask extracts knowledge from a task. The result starts from
+// what the task's knowledge base already holds and adds each parsed (and
+// summarized) entry whose kind and title are not there yet, so re-running
+// it, or running it after decisions were recorded by hand, adds nothing
+// twice. Summary is the summarizer's when it gives one, else context.md;
+// References lists the files read.
 func (ke *KnowledgeExtractor) ExtractFromTask(taskID string) (*models.ExtractedKnowledge, error) {
-	taskDir := filepath.Join(ke.basePath, "tickets", taskID)
+	taskDir := ke.ticketDir(taskID)
 
 	// Check if task directory exists
 	if _, err := os.Stat(taskDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("task directory not found: %s", taskDir)
 	}
 
-	// Create extracted knowledge
-	knowledge := models.NewExtractedKnowledge(taskID)
+	knowledge, err := ke.LoadKnowledge(taskID)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := ke.collectSources(taskID, taskDir)
+	if err != nil {
+		return nil, err
+	}
 
-	// Read context.md if it exists
-	contextPath := filepath.Join(taskDir, "context.md")
-	if contextData, err := os.ReadFile(contextPath); err == nil {
-		knowledge.Summary = string(contextData)
+	m := newKnowledgeMerger(knowledge)
+	now := time.Now().UTC()
+	knowledge.References = []string{}
+	for _, doc := range docs {
+		knowledge.References = appendUnique(knowledge.References, doc.file)
+		for _, e := range parseKnowledgeMarkdown(doc.content) {
+			m.add(e, &models.Provenance{File: doc.file, Line: e.line, Turn: doc.turn, Extractor: ExtractorParser}, now)
+		}
+		if doc.file == ticketRel(ke.basePath, taskDir, "context.md") {
+			knowledge.Summary = doc.content
+		}
 	}
 
-	// Read notes.md if it exists and append to references
-	notesPath := filepath.Join(taskDir, "notes.md")
-	if _, err := os.Stat(notesPath); err == nil {
-		knowledge.References = append(knowledge.References, notesPath)
+	if ke.summarizer != nil {
+		in := KnowledgeSummaryInput{TaskID: taskID}
+		for _, doc := range docs {
+			path := doc.file
+			if doc.turn > 0 {
+				path = fmt.Sprintf("%s#turn-%d", doc.file, doc.turn)
+			}
+			in.Documents = append(in.Documents, KnowledgeDocument{Path: path, Content: doc.content})
+		}
+		extra, err := ke.summarizer.Summarize(context.Background(), in)
+		if err != nil {
+			// Summarizing is advisory: the parsed entries stand on their own.
+			fmt.Fprintf(os.Stderr, "Warning: knowledge summarizer failed for %s: %v\n", taskID, err)
+		} else if extra != nil {
+			m.merge(extra)
+			if strings.TrimSpace(extra.Summary) != "" {
+				knowledge.Summary = extra.Summary
+			}
+		}
 	}
 
+	knowledge.TaskID = taskID
+	knowledge.ExtractedAt = now
 	return knowledge, nil
 }
 
+// knowledgeSource is one document (or one captured-session turn) to parse.
+type knowledgeSource struct {
+	file    string // relative to the workspace root, slash-separated
+	content string
+	turn    int // captured-session turn index; 0 for a file
+}
+
+// collectSources gathers, in dedupe order, the ticket markdown files, the
+// transcripts captured into the ticket (transcript_*.md, sessions/*.md),
+// and the turns of workspace sessions recorded against the task.
+func (ke *KnowledgeExtractor) collectSources(taskID, taskDir string) ([]knowledgeSource, error) {
+	var docs []knowledgeSource
+	addFile := func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		docs = append(docs, knowledgeSource{file: relSlash(ke.basePath, path), content: string(data)})
+		return nil
+	}
+	for _, name := range ticketKnowledgeSources {
+		if err := addFile(filepath.Join(taskDir, name)); err != nil {
+			return nil, err
+		}
+	}
+	for _, pattern := range []string{"transcript_*.md", filepath.Join("sessions", "*.md")} {
+		matches, _ := filepath.Glob(filepath.Join(taskDir, pattern))
+		sort.Strings(matches)
+		for _, path := range matches {
+			if err := addFile(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if ke.sessions == nil {
+		return docs, nil
+	}
+	sessions, err := ke.sessions.FilterSessions(&models.SessionFilter{TaskID: taskID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions for %s: %w", taskID, err)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+	for _, session := range sessions {
+		// Provenance points at the file the store keeps the turns in.
+		turnsFile := filepath.ToSlash(filepath.Join("sessions", session.ID, "turns.yaml"))
+		for i, turn := range session.Turns {
+			idx := turn.Index
+			if idx == 0 {
+				idx = i + 1
+			}
+			docs = append(docs, knowledgeSource{file: turnsFile, content: turn.Content, turn: idx})
+		}
+	}
+	return docs, nil
+}
+
+// knowledgeMerger appends entries to a knowledge base, skipping any whose
+// kind and normalised title it already holds and numbering new decisions
+// after the highest existing DEC-NNN.
+type knowledgeMerger struct {
+	k      *models.ExtractedKnowledge
+	seen   map[string]bool
+	nextID int
+}
+
+func newKnowledgeMerger(k *models.ExtractedKnowledge) *knowledgeMerger {
+	m := &knowledgeMerger{k: k, seen: map[string]bool{}, nextID: 1}
+	for _, d := range k.Decisions {
+		m.seen[dedupeKey(kindDecision, d.Title)] = true
+		var n int
+		if _, err := fmt.Sscanf(d.ID, "DEC-%d", &n); err == nil && n >= m.nextID {
+			m.nextID = n + 1
+		}
+	}
+	for _, l := range k.Learnings {
+		m.seen[dedupeKey(kindLearning, l.Title)] = true
+	}
+	for _, g := range k.Gotchas {
+		m.seen[dedupeKey(kindGotcha, g.Title)] = true
+	}
+	return m
+}
+
+// claim reports whether an entry is new, and remembers it.
+func (m *knowledgeMerger) claim(kind knowledgeKind, title string) bool {
+	key := dedupeKey(kind, title)
+	if key == "" || m.seen[key] {
+		return false
+	}
+	m.seen[key] = true
+	return true
+}
+
+func (m *knowledgeMerger) add(e *knowledgeEntry, src *models.Provenance, at time.Time) {
+	title := e.resolvedTitle()
+	if !m.claim(e.kind, title) {
+		return
+	}
+	switch e.kind {
+	case kindDecision:
+		m.addDecision(e.decision(src, at))
+	case kindLearning:
+		m.k.AddLearning(e.learning(src, at))
+	case kindGotcha:
+		m.k.AddGotcha(e.gotcha(src, at))
+	}
+}
+
+func (m *knowledgeMerger) addDecision(d models.Decision) {
+	d.ID = fmt.Sprintf("DEC-%03d", m.nextID)
+	m.nextID++
+	m.k.AddDecision(d)
+}
+
+// merge folds in a summarizer's entries.
+func (m *knowledgeMerger) merge(extra *models.ExtractedKnowledge) {
+	mark := func(src *models.Provenance) *models.Provenance {
+		if src == nil {
+			src = &models.Provenance{}
+		}
+		src.Extractor = ExtractorSummarizer
+		return src
+	}
+	for _, d := range extra.Decisions {
+		if m.claim(kindDecision, d.Title) {
+			d.Source = mark(d.Source)
+			m.addDecision(d)
+		}
+	}
+	for _, l := range extra.Learnings {
+		if m.claim(kindLearning, l.Title) {
+			l.Source = mark(l.Source)
+			m.k.AddLearning(l)
+		}
+	}
+	for _, g := range extra.Gotchas {
+		if m.claim(kindGotcha, g.Title) {
+			g.Source = mark(g.Source)
+			m.k.AddGotcha(g)
+		}
+	}
+}
+
+// dedupeKey normalises a title (case, punctuation, spacing) under its kind.
+func dedupeKey(kind knowledgeKind, title string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%s", kind, b.String())
+}
+
+func relSlash(base, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+func ticketRel(base, taskDir, name string) string {
+	return relSlash(base, filepath.Join(taskDir, name))
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
 // SaveKnowledge saves extracted knowledge to the task's knowledge directory
 func (ke *KnowledgeExtractor) SaveKnowledge(taskID string, knowledge *models.ExtractedKnowledge) error {
-	taskDir := filepath.Join(ke.basePath, "tickets", taskID)
+	taskDir := ke.ticketDir(taskID)
 	knowledgeDir := filepath.Join(taskDir, "knowledge")
 
 	// Create knowledge directory if it doesn't exist
@@ -75,7 +372,7 @@ func (ke *KnowledgeExtractor) SaveKnowledge(taskID string, knowledge *models.Ext
 
 // LoadKnowledge loads extracted knowledge from a task's knowledge directory
 func (ke *KnowledgeExtractor) LoadKnowledge(taskID string) (*models.ExtractedKnowledge, error) {
-	taskDir := filepath.Join(ke.basePath, "tickets", taskID)
+	taskDir := ke.ticketDir(taskID)
 	decisionsPath := filepath.Join(taskDir, "knowledge", "decisions.yaml")
 
 	// Check if file exists
@@ -146,7 +443,8 @@ func (ke *KnowledgeExtractor) AddGotcha(taskID string, gotcha models.Gotcha) err
 	return nil
 }
 
-// ExtractAndSave extracts knowledge from a task and saves it
+// ExtractAndSave extracts knowledge from a task and saves it. Entries
+// already in the knowledge base, including ones recorded by hand, are kept.
 func (ke *KnowledgeExtractor) ExtractAndSave(taskID string) error {
 	knowledge, err := ke.ExtractFromTask(taskID)
 	if err != nil {
@@ -1,12 +1,17 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/valter-silva-au/ai-dev-brain/internal/storage"
 	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+	"gopkg.in/yaml.v3"
 )
 
 func TestNewKnowledgeExtractor(t *testing.T) {
@@ -424,3 +429,217 @@ func TestKnowledgeExtractor_ListAllKnowledge_EmptyDir(t *testing.T) {
 		t.Errorf("Expected empty list, got %d items", len(list))
 	}
 }
+
+// fakeSummarizer returns canned knowledge and records what it was given.
+type fakeSummarizer struct {
+	out *models.ExtractedKnowledge
+	err error
+	got KnowledgeSummaryInput
+}
+
+func (f *fakeSummarizer) Summarize(_ context.Context, in KnowledgeSummaryInput) (*models.ExtractedKnowledge, error) {
+	f.got = in
+	return f.out, f.err
+}
+
+// seedKnowledgeTask writes a ticket with knowledge in its notes and a
+// captured transcript.
+func seedKnowledgeTask(t *testing.T, base, dirName string) string {
+	t.Helper()
+	dir := filepath.Join(base, "tickets", dirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "context.md"), []byte("# Context\n\nResolver work.\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "notes.md"), []byte("# Notes\n\n## Decision: Keep the flat layout\nWhy: old tickets use it\n\nGotcha: archived dirs match too\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "transcript_s1.md"), []byte("# Session Transcript\n\nTIL: WalkDir can skip a subtree\n\nDecision: keep the flat layout\n"), 0o644)
+	return dir
+}
+
+func TestKnowledgeExtractor_ExtractProvenanceAndDedupe(t *testing.T) {
+	base := t.TempDir()
+	seedKnowledgeTask(t, base, "TASK-00001")
+	ke := NewKnowledgeExtractor(base)
+
+	if err := ke.AddGotcha("TASK-00001", models.Gotcha{Title: "Archived dirs match too!", Description: "recorded by hand"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ke.ExtractAndSave("TASK-00001"); err != nil {
+		t.Fatal(err)
+	}
+	k, err := ke.LoadKnowledge("TASK-00001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(k.Decisions) != 1 || len(k.Learnings) != 1 || len(k.Gotchas) != 1 {
+		t.Fatalf("got %d decisions, %d learnings, %d gotchas; want 1 each (transcript decision and notes gotcha deduped)", len(k.Decisions), len(k.Learnings), len(k.Gotchas))
+	}
+	d := k.Decisions[0]
+	if d.ID != "DEC-001" || d.Rationale != "old tickets use it" || d.Source == nil || *d.Source != (models.Provenance{File: "tickets/TASK-00001/notes.md", Line: 3, Extractor: ExtractorParser}) {
+		t.Errorf("decision = %+v source %+v", d, d.Source)
+	}
+	if l := k.Learnings[0]; l.Source == nil || l.Source.File != "tickets/TASK-00001/transcript_s1.md" || l.Source.Line != 3 {
+		t.Errorf("learning source = %+v", l.Source)
+	}
+	if g := k.Gotchas[0]; g.Description != "recorded by hand" || g.Source != nil {
+		t.Errorf("the hand-recorded gotcha should survive untouched: %+v", g)
+	}
+	if k.Summary != "# Context\n\nResolver work.\n" || len(k.References) != 3 {
+		t.Errorf("summary %q, references %v", k.Summary, k.References)
+	}
+
+	// Re-running adds nothing, and a new note gets the next decision ID.
+	f, _ := os.OpenFile(filepath.Join(base, "tickets", "TASK-00001", "notes.md"), os.O_APPEND|os.O_WRONLY, 0o644)
+	_, _ = f.WriteString("\nDecision: hash tokens at rest\n")
+	_ = f.Close()
+	if err := ke.ExtractAndSave("TASK-00001"); err != nil {
+		t.Fatal(err)
+	}
+	k, _ = ke.LoadKnowledge("TASK-00001")
+	if len(k.Decisions) != 2 || k.Decisions[1].ID != "DEC-002" || len(k.Learnings) != 1 || len(k.Gotchas) != 1 {
+		t.Errorf("after re-run: %+v", k)
+	}
+}
+
+func TestKnowledgeExtractor_CapturedSessionsAndNestedTicket(t *testing.T) {
+	base := t.TempDir()
+	seedKnowledgeTask(t, base, filepath.Join("acme", "TASK-00002-resolver"))
+	store := storage.NewFileSessionStoreManager(filepath.Join(base, "sessions"))
+	for _, s := range []*models.CapturedSession{
+		{ID: "S-00001", TaskID: "TASK-00002", Turns: []models.SessionTurn{
+			{Index: 1, Role: "user", Content: "Why is it slow?"},
+			{Index: 2, Role: "assistant", Content: "Found it.\n\n**Gotcha:** the walk descends into worktrees\nFix: skip work/\n"},
+		}},
+		{ID: "S-00002", TaskID: "TASK-00009", Turns: []models.SessionTurn{{Index: 1, Content: "Gotcha: unrelated"}}},
+	} {
+		if err := store.SaveSession(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	k, err := NewKnowledgeExtractor(base).ExtractFromTask("TASK-00002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var turn *models.Gotcha
+	for i, g := range k.Gotchas {
+		if g.Title == "unrelated" {
+			t.Error("another task's session must not contribute")
+		}
+		if g.Title == "the walk descends into worktrees" {
+			turn = &k.Gotchas[i]
+		}
+	}
+	if turn == nil || turn.Solution != "skip work/" || *turn.Source != (models.Provenance{File: "sessions/S-00001/turns.yaml", Line: 3, Turn: 2, Extractor: ExtractorParser}) {
+		t.Errorf("session gotcha = %+v", turn)
+	}
+	if len(k.Decisions) == 0 || k.Decisions[0].Source.File != "tickets/acme/TASK-00002-resolver/notes.md" {
+		t.Errorf("nested ticket notes not read: %+v", k.Decisions)
+	}
+}
+
+// fakeSessionStore serves sessions from memory; only FilterSessions is used.
+type fakeSessionStore struct {
+	storage.SessionStoreManager
+	sessions []*models.CapturedSession
+	filter   *models.SessionFilter
+	err      error
+}
+
+func (f *fakeSessionStore) FilterSessions(filter *models.SessionFilter) ([]*models.CapturedSession, error) {
+	f.filter = filter
+	var out []*models.CapturedSession
+	for _, s := range f.sessions {
+		if filter.Matches(s) {
+			out = append(out, s)
+		}
+	}
+	return out, f.err
+}
+
+func TestKnowledgeExtractor_ListsSessionsThroughStore(t *testing.T) {
+	base := t.TempDir()
+	seedKnowledgeTask(t, base, "TASK-00004")
+	// A session file the store does not know about is not read.
+	stray, _ := yaml.Marshal(models.CapturedSession{ID: "S-00009", TaskID: "TASK-00004", Turns: []models.SessionTurn{{Index: 1, Content: "Gotcha: stray file"}}})
+	_ = os.MkdirAll(filepath.Join(base, "sessions", "S-00009"), 0o755)
+	_ = os.WriteFile(filepath.Join(base, "sessions", "S-00009", "session.yaml"), stray, 0o644)
+
+	store := &fakeSessionStore{sessions: []*models.CapturedSession{
+		{ID: "S-00002", TaskID: "TASK-00004", Turns: []models.SessionTurn{{Content: "Learning: the store is the seam"}}},
+		{ID: "S-00001", TaskID: "TASK-00004", Turns: []models.SessionTurn{{Index: 1, Content: "x"}, {Index: 2, Content: "Gotcha: first session"}}},
+		{ID: "S-00003", TaskID: "TASK-00005", Turns: []models.SessionTurn{{Index: 1, Content: "Gotcha: other task"}}},
+	}}
+	ke := NewKnowledgeExtractor(base)
+	ke.SetSessionStore(store)
+
+	docs, err := ke.collectSources("TASK-00004", filepath.Join(base, "tickets", "TASK-00004"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.filter == nil || store.filter.TaskID != "TASK-00004" {
+		t.Errorf("filter = %+v, want the task's sessions", store.filter)
+	}
+	var turns []string
+	for _, d := range docs {
+		if d.turn > 0 {
+			turns = append(turns, fmt.Sprintf("%s#%d %s", d.file, d.turn, d.content))
+		}
+	}
+	want := []string{
+		"sessions/S-00001/turns.yaml#1 x",
+		"sessions/S-00001/turns.yaml#2 Gotcha: first session",
+		"sessions/S-00002/turns.yaml#1 Learning: the store is the seam",
+	}
+	if fmt.Sprint(turns) != fmt.Sprint(want) {
+		t.Errorf("session turns = %q, want %q", turns, want)
+	}
+
+	store.err = errors.New("index unreadable")
+	if _, err := ke.ExtractFromTask("TASK-00004"); err == nil {
+		t.Error("a store error should fail the extraction")
+	}
+	ke.SetSessionStore(nil)
+	if docs, err := ke.collectSources("TASK-00004", filepath.Join(base, "tickets", "TASK-00004")); err != nil || len(docs) != 3 {
+		t.Errorf("without a store: %d docs, err %v; want the ticket files only", len(docs), err)
+	}
+}
+
+func TestKnowledgeExtractor_Summarizer(t *testing.T) {
+	base := t.TempDir()
+	seedKnowledgeTask(t, base, "TASK-00003")
+	ke := NewKnowledgeExtractor(base)
+	fake := &fakeSummarizer{out: &models.ExtractedKnowledge{
+		Summary: "Kept the flat layout; skipped archived dirs.",
+		Decisions: []models.Decision{
+			{Title: "Keep the flat layout", Description: "duplicate of the parsed one"},
+			{Title: "Resolve nested dirs lazily", Description: "only when the flat dir is missing", Source: &models.Provenance{File: "tickets/TASK-00003/notes.md"}},
+		},
+		Learnings: []models.Learning{{Title: "Shallowest match wins", Description: "fewer surprises"}},
+	}}
+	ke.SetSummarizer(fake)
+
+	k, err := ke.ExtractFromTask("TASK-00003")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.got.TaskID != "TASK-00003" || len(fake.got.Documents) != 3 || fake.got.Documents[1].Path != "tickets/TASK-00003/notes.md" {
+		t.Errorf("summarizer input = %+v", fake.got)
+	}
+	if k.Summary != "Kept the flat layout; skipped archived dirs." {
+		t.Errorf("summary = %q", k.Summary)
+	}
+	if len(k.Decisions) != 2 || k.Decisions[1].ID != "DEC-002" || *k.Decisions[1].Source != (models.Provenance{File: "tickets/TASK-00003/notes.md", Extractor: ExtractorSummarizer}) {
+		t.Errorf("decisions = %+v", k.Decisions)
+	}
+	if len(k.Learnings) != 2 || k.Learnings[1].Source.Extractor != ExtractorSummarizer {
+		t.Errorf("learnings = %+v", k.Learnings)
+	}
+
+	// A failing summarizer leaves the parsed entries in place.
+	ke.SetSummarizer(&fakeSummarizer{err: errors.New("model unavailable")})
+	k, err = ke.ExtractFromTask("TASK-00003")
+	if err != nil || len(k.Decisions) != 1 || k.Summary != "# Context\n\nResolver work.\n" {
+		t.Errorf("fallback = %+v, %v", k, err)
+	}
+}
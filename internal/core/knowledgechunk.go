@@ -0,0 +1,240 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+)
+
+// Defaults for ChunkOptions: a chunk is roughly a few paragraphs, small
+// enough that its embedding still says something specific.
+const (
+	DefaultChunkMaxChars     = 1500
+	DefaultChunkOverlapChars = 200
+)
+
+// ChunkOptions bounds the chunks ChunkMarkdown produces. Zero values take
+// the defaults.
+type ChunkOptions struct {
+	MaxChars     int // upper bound on a chunk's length in bytes
+	OverlapChars int // a trailing paragraph up to this long is repeated at the start of the next chunk
+}
+
+// Chunk is one indexable slice of a document. Content is always
+// text[Start:End] of the source, so offsets can be used to point back into
+// the file.
+type Chunk struct {
+	Index       int
+	Start, End  int
+	HeadingPath []string // enclosing headings, outermost first
+	Content     string
+	Hash        string // content hash; unchanged chunks are skipped on reindex
+}
+
+// span is a byte range of the source text.
+type span struct{ start, end int }
+
+// mdSection is one heading's body: the heading line (when there is one)
+// followed by the paragraphs up to the next heading.
+type mdSection struct {
+	path       []string
+	paragraphs []span
+	hasBody    bool
+}
+
+// ChunkMarkdown splits markdown into heading-aware chunks. Every heading
+// starts a new chunk, so a chunk never straddles two sections; within a
+// section, paragraphs (blank-line separated, with fenced code blocks kept
+// whole) are packed up to MaxChars, and the last paragraph of a chunk is
+// carried into the next when it is at most OverlapChars. A paragraph longer
+// than MaxChars is cut at line or word boundaries with OverlapChars of
+// overlap. A heading with no body of its own produces no chunk; it still
+// appears in its subsections' HeadingPath.
+func ChunkMarkdown(text string, opts ChunkOptions) []Chunk {
+	if opts.MaxChars <= 0 {
+		opts.MaxChars = DefaultChunkMaxChars
+	}
+	if opts.OverlapChars < 0 || opts.OverlapChars >= opts.MaxChars {
+		opts.OverlapChars = 0
+	} else if opts.OverlapChars == 0 {
+		opts.OverlapChars = min(DefaultChunkOverlapChars, opts.MaxChars/2)
+	}
+
+	var chunks []Chunk
+	emit := func(path []string, start, end int) {
+		content := text[start:end]
+		if strings.TrimSpace(content) == "" {
+			return
+		}
+		chunks = append(chunks, Chunk{
+			Index: len(chunks), Start: start, End: end,
+			HeadingPath: path, Content: content, Hash: contentHash(content),
+		})
+	}
+	for _, sec := range splitSections(text) {
+		if !sec.hasBody {
+			continue
+		}
+		var cur []span
+		flush := func() {
+			if len(cur) > 0 {
+				emit(sec.path, cur[0].start, cur[len(cur)-1].end)
+			}
+		}
+		for _, p := range sec.paragraphs {
+			if p.end-p.start > opts.MaxChars {
+				flush()
+				cur = nil
+				for _, piece := range splitLong(text, p, opts) {
+					emit(sec.path, piece.start, piece.end)
+				}
+				continue
+			}
+			if len(cur) > 0 && p.end-cur[0].start > opts.MaxChars {
+				flush()
+				last := cur[len(cur)-1]
+				cur = nil
+				if last.end-last.start <= opts.OverlapChars && p.end-last.start <= opts.MaxChars {
+					cur = []span{last}
+				}
+			}
+			cur = append(cur, p)
+		}
+		flush()
+	}
+	return chunks
+}
+
+// WholeChunk returns text as a single chunk, for files that are not
+// markdown (decisions.yaml: its # lines are comments, not headings).
+func WholeChunk(text string) []Chunk {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	return []Chunk{{Start: 0, End: len(text), Content: text, Hash: contentHash(text)}}
+}
+
+// splitSections walks text line by line, tracking ATX headings outside
+// fenced code blocks, and groups the blank-line separated paragraphs under
+// their heading.
+func splitSections(text string) []mdSection {
+	var (
+		sections []mdSection
+		path     []string
+		levels   []int
+		sec      = mdSection{}
+		para     = span{start: -1}
+		fence    string
+	)
+	closePara := func(end int) {
+		if para.start >= 0 {
+			sec.paragraphs = append(sec.paragraphs, span{para.start, end})
+			para.start = -1
+		}
+	}
+	for pos := 0; pos < len(text); {
+		lineEnd := strings.IndexByte(text[pos:], '\n')
+		next := len(text)
+		if lineEnd >= 0 {
+			lineEnd += pos
+			next = lineEnd + 1
+		} else {
+			lineEnd = len(text)
+		}
+		line := text[pos:lineEnd]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+			if para.start < 0 {
+				para.start = pos
+			}
+			sec.hasBody = true
+		case trimmed == "":
+			closePara(pos)
+		default:
+			if level, title, ok := atxHeading(line); ok {
+				closePara(pos)
+				sections = append(sections, sec)
+				for len(levels) > 0 && levels[len(levels)-1] >= level {
+					levels, path = levels[:len(levels)-1], path[:len(path)-1]
+				}
+				levels, path = append(levels, level), append(path, title)
+				sec = mdSection{path: append([]string(nil), path...)}
+				sec.paragraphs = append(sec.paragraphs, span{pos, lineEnd})
+				break
+			}
+			if para.start < 0 {
+				para.start = pos
+			}
+			sec.hasBody = true
+		}
+		pos = next
+	}
+	closePara(len(text))
+	return append(sections, sec)
+}
+
+// atxHeading reports whether line is a markdown ATX heading ("## Title"),
+// returning its level and text without the closing #s.
+func atxHeading(line string) (int, string, bool) {
+	if len(line)-len(strings.TrimLeft(line, " ")) > 3 {
+		return 0, "", false
+	}
+	line = strings.TrimLeft(line, " ")
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(rest), "#"))
+	return level, title, true
+}
+
+// splitLong cuts an oversized paragraph into pieces of at most MaxChars,
+// preferring a newline, then a space, in the second half of each window,
+// and starting each piece OverlapChars before the previous one ended.
+func splitLong(text string, p span, opts ChunkOptions) []span {
+	var pieces []span
+	for start := p.start; start < p.end; {
+		end := start + opts.MaxChars
+		if end >= p.end {
+			pieces = append(pieces, span{start, p.end})
+			break
+		}
+		window := text[start:end]
+		if cut := strings.LastIndexByte(window, '\n'); cut > len(window)/2 {
+			end = start + cut + 1
+		} else if cut := strings.LastIndexByte(window, ' '); cut > len(window)/2 {
+			end = start + cut + 1
+		}
+		for end > start && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		pieces = append(pieces, span{start, end})
+		next := end - opts.OverlapChars
+		for next > start && !utf8.RuneStart(text[next]) {
+			next--
+		}
+		if next <= start {
+			next = end
+		}
+		start = next
+	}
+	return pieces
+}
+
+// contentHash is a short, stable fingerprint of a chunk's content.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChunkMarkdown_SplitsByHeading(t *testing.T) {
+	doc := "Intro line.\n\n# Design\n\n## Storage\n\nSQLite, WAL mode.\n\n```sh\n# not a heading\nadb memory index\n```\n\n### Schema\n\nOne table.\n\n## API\n\nREST.\n"
+	chunks := ChunkMarkdown(doc, ChunkOptions{})
+
+	want := []struct {
+		path, head string
+	}{
+		{"", "Intro line."},
+		{"Design > Storage", "## Storage"},
+		{"Design > Storage > Schema", "### Schema"},
+		{"Design > API", "## API"},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks, want %d: %+v", len(chunks), len(want), chunks)
+	}
+	for i, w := range want {
+		c := chunks[i]
+		if got := strings.Join(c.HeadingPath, " > "); got != w.path || !strings.HasPrefix(c.Content, w.head) {
+			t.Errorf("chunk %d = path %q content %q, want %q / %q…", i, got, c.Content, w.path, w.head)
+		}
+		if c.Index != i || doc[c.Start:c.End] != c.Content || c.Hash != contentHash(c.Content) {
+			t.Errorf("chunk %d offsets/hash inconsistent: %+v", i, c)
+		}
+	}
+	if !strings.Contains(chunks[1].Content, "# not a heading\nadb memory index\n```") {
+		t.Errorf("fenced block split or lost: %q", chunks[1].Content)
+	}
+}
+
+func TestChunkMarkdown_PacksWithOverlap(t *testing.T) {
+	paras := []string{"alpha alpha alpha", "beta beta beta", "gamma gamma", "delta delta delta delta"}
+	doc := "## S\n\n" + strings.Join(paras, "\n\n") + "\n"
+	chunks := ChunkMarkdown(doc, ChunkOptions{MaxChars: 40, OverlapChars: 15})
+	if len(chunks) < 2 {
+		t.Fatalf("expected packing to produce several chunks: %+v", chunks)
+	}
+	for i, c := range chunks {
+		if len(c.Content) > 40 {
+			t.Errorf("chunk %d is %d bytes, over MaxChars", i, len(c.Content))
+		}
+	}
+	// "gamma gamma" (11 bytes) ends one chunk and is repeated to open the next.
+	var carried bool
+	for i := 1; i < len(chunks); i++ {
+		if strings.HasSuffix(strings.TrimSpace(chunks[i-1].Content), "gamma gamma") && strings.HasPrefix(chunks[i].Content, "gamma gamma") {
+			carried = true
+		}
+	}
+	if !carried {
+		t.Errorf("no paragraph overlap between chunks: %+v", chunks)
+	}
+}
+
+func TestChunkMarkdown_SplitsLongParagraph(t *testing.T) {
+	long := strings.Repeat("word ", 100) // 500 bytes, one paragraph
+	chunks := ChunkMarkdown(long, ChunkOptions{MaxChars: 120, OverlapChars: 20})
+	if len(chunks) < 5 {
+		t.Fatalf("got %d chunks", len(chunks))
+	}
+	for i, c := range chunks {
+		if len(c.Content) > 120 {
+			t.Errorf("piece %d is %d bytes", i, len(c.Content))
+		}
+		if i > 0 && c.Start >= chunks[i-1].End {
+			t.Errorf("piece %d does not overlap the previous one", i)
+		}
+		if !strings.HasPrefix(c.Content, "word") && i > 0 {
+			t.Errorf("piece %d starts mid-word: %q", i, c.Content[:10])
+		}
+	}
+	if chunks[len(chunks)-1].End != len(long) {
+		t.Error("tail of the paragraph lost")
+	}
+}
+
+func TestChunkMarkdown_Edges(t *testing.T) {
+	if got := ChunkMarkdown("   \n\n", ChunkOptions{}); len(got) != 0 {
+		t.Errorf("blank doc = %+v", got)
+	}
+	if got := ChunkMarkdown("# Only a title\n", ChunkOptions{}); len(got) != 0 {
+		t.Errorf("heading-only doc = %+v", got)
+	}
+	if got := ChunkMarkdown("#hashtag is not a heading\n", ChunkOptions{}); len(got) != 1 || got[0].HeadingPath != nil {
+		t.Errorf("#hashtag = %+v", got)
+	}
+	w := WholeChunk("# comment\nkey: value\n")
+	if len(w) != 1 || w[0].Content != "# comment\nkey: value\n" || w[0].HeadingPath != nil {
+		t.Errorf("WholeChunk = %+v", w)
+	}
+}
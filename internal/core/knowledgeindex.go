@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
 )
@@ -33,11 +35,42 @@ func NewKnowledgeIndexer(mem MemoryIndexer, backlog BacklogStore, graph GraphMan
 	return &KnowledgeIndexer{mem: mem, backlog: backlog, graph: graph, ticketsDir: ticketsDir}
 }
 
+// MemoryChunkStore is the optional read/delete surface that makes an index
+// pass incremental: with it, chunks whose stored metadata (content hash and
+// offsets) is unchanged are skipped, and records for chunks that no longer
+// exist are deleted. memory.SQLiteStore implements it; a plain
+// MemoryIndexer gets every chunk upserted on every pass.
+type MemoryChunkStore interface {
+	MemoryIndexer
+	ListMeta(ctx context.Context, ns string) (map[string]map[string]string, error)
+	Delete(ctx context.Context, ns, key string) error
+}
+
+// Metadata keys recorded on each indexed chunk. MetaDoc names the parent
+// document (e.g. "context.md"), which search results surface alongside the
+// matching chunk.
+const (
+	MetaSource  = "source"
+	MetaDoc     = "doc"
+	MetaChunk   = "chunk"
+	MetaStart   = "start"
+	MetaEnd     = "end"
+	MetaHeading = "heading"
+	MetaHash    = "hash"
+
+	sourceTicketKnowledge = "ticket-knowledge"
+	sourceGraphEdge       = "graph-edge"
+)
+
 // KnowledgeIndexStats reports what an index pass wrote.
 type KnowledgeIndexStats struct {
-	Tickets int // tickets that contributed at least one indexed file
-	Files   int // ticket knowledge files indexed
-	Edges   int // graph edges indexed
+	Tickets   int // tickets that contributed at least one indexed file
+	Files     int // ticket knowledge files indexed
+	Chunks    int // new or changed chunks written (and embedded)
+	Moved     int // unchanged chunks whose offsets moved: metadata rewritten, embedding reused by the store
+	Unchanged int // chunks skipped because their content hash was unchanged
+	Removed   int // stale chunk / edge records deleted
+	Edges     int // graph edges indexed
 }
 
 // ticketKnowledgeFiles are the per-ticket artifacts worth indexing for semantic
@@ -45,9 +78,11 @@ type KnowledgeIndexStats struct {
 var ticketKnowledgeFiles = []string{"context.md", "notes.md", "design.md", filepath.Join("knowledge", "decisions.yaml")}
 
 // IndexWorkspace indexes every ticket's knowledge files under namespace
-// tickets/<id> and every graph edge under namespace "graph". It is idempotent:
-// Upsert replaces a record at (ns, key), so re-running refreshes rather than
-// duplicates. A missing/empty file is skipped, not an error.
+// tickets/<id> (archive/tickets/<id> for archived tasks) — markdown split into heading-aware chunks keyed
+// <file>#<content-hash> — and every graph edge under namespace "graph". It is
+// idempotent: Upsert replaces a record at (ns, key), so re-running refreshes
+// rather than duplicates, and against a MemoryChunkStore only changed chunks
+// are re-embedded. A missing/empty file is skipped, not an error.
 func (ki *KnowledgeIndexer) IndexWorkspace(ctx context.Context) (KnowledgeIndexStats, error) {
 	var stats KnowledgeIndexStats
 	if ki.mem == nil {
@@ -59,7 +94,7 @@ func (ki *KnowledgeIndexer) IndexWorkspace(ctx context.Context) (KnowledgeIndexS
 		return stats, fmt.Errorf("load backlog: %w", err)
 	}
 	for _, t := range backlog.Tasks {
-		n, err := ki.indexTaskFiles(ctx, t)
+		n, err := ki.indexTaskFiles(ctx, t, &stats)
 		if err != nil {
 			return stats, err
 		}
@@ -74,23 +109,70 @@ func (ki *KnowledgeIndexer) IndexWorkspace(ctx context.Context) (KnowledgeIndexS
 		if err != nil {
 			return stats, fmt.Errorf("build graph: %w", err)
 		}
+		existing, err := ki.existing(ctx, "graph")
+		if err != nil {
+			return stats, err
+		}
+		keep := make(map[string]bool)
 		for _, e := range g.Index().Edges {
 			key := fmt.Sprintf("%s|%s|%s", e.From, e.Type, e.To)
+			keep[key] = true
+			stats.Edges++
+			if _, ok := existing[key]; ok {
+				continue // content is derived from the key: nothing to refresh
+			}
 			content := fmt.Sprintf("%s %s %s", e.From, e.Type, e.To)
-			if err := ki.mem.Upsert(ctx, "graph", key, content, map[string]string{"source": "graph-edge"}); err != nil {
+			if err := ki.mem.Upsert(ctx, "graph", key, content, map[string]string{MetaSource: sourceGraphEdge}); err != nil {
 				return stats, fmt.Errorf("index edge %s: %w", key, err)
 			}
-			stats.Edges++
+		}
+		if err := ki.prune(ctx, "graph", sourceGraphEdge, existing, keep, &stats); err != nil {
+			return stats, err
 		}
 	}
 	return stats, nil
 }
 
+// existing returns the stored metadata for ns when the store supports
+// incremental indexing, else nil.
+func (ki *KnowledgeIndexer) existing(ctx context.Context, ns string) (map[string]map[string]string, error) {
+	cs, ok := ki.mem.(MemoryChunkStore)
+	if !ok {
+		return nil, nil
+	}
+	meta, err := cs.ListMeta(ctx, ns)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", ns, err)
+	}
+	return meta, nil
+}
+
+// prune deletes the records in ns written by this indexer (source) that
+// the current pass did not produce — chunks of edited or deleted files,
+// whole-file records from before chunking, and edges no longer in the
+// graph. Records other writers own (the memory hook's) are left alone.
+func (ki *KnowledgeIndexer) prune(ctx context.Context, ns, source string, existing map[string]map[string]string, keep map[string]bool, stats *KnowledgeIndexStats) error {
+	cs, ok := ki.mem.(MemoryChunkStore)
+	if !ok {
+		return nil
+	}
+	for key, meta := range existing {
+		if keep[key] || meta[MetaSource] != source {
+			continue
+		}
+		if err := cs.Delete(ctx, ns, key); err != nil {
+			return fmt.Errorf("delete stale %s/%s: %w", ns, key, err)
+		}
+		stats.Removed++
+	}
+	return nil
+}
+
 // indexTaskFiles indexes a single ticket's knowledge files, returning how many
 // were indexed. It resolves the ticket dir from the task's TicketPath, falling
 // back to a tickets/ walk (the nested correlation layout means a task id alone
 // is not a directory).
-func (ki *KnowledgeIndexer) indexTaskFiles(ctx context.Context, t models.Task) (int, error) {
+func (ki *KnowledgeIndexer) indexTaskFiles(ctx context.Context, t models.Task, stats *KnowledgeIndexStats) (int, error) {
 	dir := t.TicketPath
 	if dir == "" {
 		resolved, err := ResolveTicketDir(ki.ticketsDir, t.ID)
@@ -103,19 +185,85 @@ func (ki *KnowledgeIndexer) indexTaskFiles(ctx context.Context, t models.Task) (
 		}
 		dir = resolved
 	}
-	ns := "tickets/" + t.ID
+	ns := TicketNamespace(t)
+	existing, err := ki.existing(ctx, ns)
+	if err != nil {
+		return 0, err
+	}
+	keep := make(map[string]bool)
 	indexed := 0
 	for _, rel := range ticketKnowledgeFiles {
 		data, err := os.ReadFile(filepath.Join(dir, rel))
 		if err != nil || len(data) == 0 {
 			continue
 		}
-		key := filepath.ToSlash(rel)
-		meta := map[string]string{"source": "ticket-knowledge", "task_id": t.ID}
-		if err := ki.mem.Upsert(ctx, ns, key, string(data), meta); err != nil {
-			return indexed, fmt.Errorf("index %s/%s: %w", ns, key, err)
+		doc := filepath.ToSlash(rel)
+		chunks := WholeChunk(string(data))
+		if strings.HasSuffix(doc, ".md") {
+			chunks = ChunkMarkdown(string(data), ChunkOptions{})
+		}
+		for key, c := range chunkKeys(doc, chunks) {
+			keep[key] = true
+			meta := map[string]string{
+				MetaSource:  sourceTicketKnowledge,
+				"task_id":   t.ID,
+				MetaDoc:     doc,
+				MetaChunk:   strconv.Itoa(c.Index),
+				MetaStart:   strconv.Itoa(c.Start),
+				MetaEnd:     strconv.Itoa(c.End),
+				MetaHeading: strings.Join(c.HeadingPath, " > "),
+				MetaHash:    c.Hash,
+			}
+			have, stored := existing[key]
+			if stored && sameMeta(have, meta) {
+				stats.Unchanged++
+				continue
+			}
+			if err := ki.mem.Upsert(ctx, ns, key, c.Content, meta); err != nil {
+				return indexed, fmt.Errorf("index %s/%s: %w", ns, key, err)
+			}
+			if stored {
+				stats.Moved++ // the key embeds the content hash
+			} else {
+				stats.Chunks++
+			}
 		}
 		indexed++
 	}
+	if err := ki.prune(ctx, ns, sourceTicketKnowledge, existing, keep, stats); err != nil {
+		return indexed, err
+	}
 	return indexed, nil
 }
+
+// chunkKeys keys each chunk <doc>#<hash>, so a chunk keeps its key — and its
+// embedding — when edits elsewhere in the file shift its position. A repeat
+// of identical content in the same document gets a -2, -3… suffix.
+func chunkKeys(doc string, chunks []Chunk) map[string]Chunk {
+	keyed := make(map[string]Chunk, len(chunks))
+	for _, c := range chunks {
+		key := doc + "#" + c.Hash
+		for n := 2; ; n++ {
+			if _, dup := keyed[key]; !dup {
+				break
+			}
+			key = fmt.Sprintf("%s#%s-%d", doc, c.Hash, n)
+		}
+		keyed[key] = c
+	}
+	return keyed
+}
+
+// sameMeta reports whether a stored record already carries want — same
+// hash, offsets and heading path — so the upsert can be skipped.
+func sameMeta(have, want map[string]string) bool {
+	if have == nil || len(have) != len(want) {
+		return false
+	}
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -75,7 +75,7 @@ func TestKnowledgeIndexer_IndexWorkspace(t *testing.T) {
 		switch c.ns {
 		case "tickets/TASK-00001":
 			ticketNS++
-			if c.key == "context.md" && c.content == "the problem" {
+			if c.meta[MetaDoc] == "context.md" && c.key == "context.md#"+contentHash("the problem") && c.content == "the problem" {
 				sawContext = true
 			}
 		case "graph":
@@ -99,3 +99,97 @@ func TestKnowledgeIndexer_NilMemory(t *testing.T) {
 		t.Fatal("expected error when no memory indexer is wired")
 	}
 }
+
+// fakeChunkStore is an in-memory MemoryChunkStore that records which keys
+// each pass wrote and deleted.
+type fakeChunkStore struct {
+	records map[string]map[string]upsertCall // ns -> key -> record
+	upserts []string
+	deletes []string
+}
+
+func (f *fakeChunkStore) Upsert(_ context.Context, ns, key, content string, meta map[string]string) error {
+	if f.records[ns] == nil {
+		f.records[ns] = map[string]upsertCall{}
+	}
+	f.records[ns][key] = upsertCall{ns: ns, key: key, content: content, meta: meta}
+	f.upserts = append(f.upserts, key)
+	return nil
+}
+
+func (f *fakeChunkStore) ListMeta(_ context.Context, ns string) (map[string]map[string]string, error) {
+	out := map[string]map[string]string{}
+	for k, r := range f.records[ns] {
+		out[k] = r.meta
+	}
+	return out, nil
+}
+
+func (f *fakeChunkStore) Delete(_ context.Context, ns, key string) error {
+	delete(f.records[ns], key)
+	f.deletes = append(f.deletes, key)
+	return nil
+}
+
+// TestKnowledgeIndexer_IncrementalChunks: a rerun with nothing changed
+// writes nothing; editing one section rewrites only that chunk and drops
+// its old record, plus the pre-chunking whole-file record — while a record
+// the memory hook owns in the same namespace survives.
+func TestKnowledgeIndexer_IncrementalChunks(t *testing.T) {
+	dir := t.TempDir()
+	ticketDir := filepath.Join(dir, "TASK-00001")
+	if err := os.MkdirAll(ticketDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(content string) {
+		if err := os.WriteFile(filepath.Join(ticketDir, "notes.md"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("# Notes\n\n## Retry policy\n\nBack off exponentially.\n\n## Open questions\n\nWho owns the queue?\n")
+
+	store := &fakeChunkStore{records: map[string]map[string]upsertCall{"tickets/TASK-00001": {
+		"notes.md":       {key: "notes.md", meta: map[string]string{MetaSource: sourceTicketKnowledge}},
+		"knowledge.yaml": {key: "knowledge.yaml", meta: map[string]string{MetaSource: "task-completed"}},
+	}}}
+	backlog := &models.Backlog{Tasks: []models.Task{{ID: "TASK-00001", TicketPath: ticketDir}}}
+	ki := NewKnowledgeIndexer(store, &fakeBacklogStore{backlog: backlog}, nil, dir)
+	ctx := context.Background()
+
+	stats, err := ki.IndexWorkspace(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Chunks != 2 || stats.Unchanged != 0 || stats.Removed != 1 {
+		t.Fatalf("first pass = %+v, want 2 chunks written and the whole-file record removed", stats)
+	}
+	ns := store.records["tickets/TASK-00001"]
+	if _, ok := ns["knowledge.yaml"]; !ok {
+		t.Error("the memory hook's record was pruned")
+	}
+	var retry upsertCall
+	for _, r := range ns {
+		if r.meta[MetaHeading] == "Notes > Retry policy" {
+			retry = r
+		}
+	}
+	if retry.content != "## Retry policy\n\nBack off exponentially.\n" || retry.meta[MetaDoc] != "notes.md" {
+		t.Errorf("retry chunk = %+v", retry)
+	}
+
+	store.upserts, store.deletes = nil, nil
+	if stats, _ := ki.IndexWorkspace(ctx); stats.Chunks != 0 || stats.Unchanged != 2 || len(store.upserts) != 0 {
+		t.Fatalf("unchanged rerun = %+v (upserts %v), want nothing written", stats, store.upserts)
+	}
+
+	write("# Notes\n\n## Retry policy\n\nBack off exponentially, capped at 5m.\n\n## Open questions\n\nWho owns the queue?\n")
+	stats, err = ki.IndexWorkspace(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The later section's content is unchanged but its offsets moved: its
+	// metadata is refreshed under the same key.
+	if stats.Chunks != 1 || stats.Moved != 1 || stats.Removed != 1 || len(store.deletes) != 1 || store.deletes[0] != retry.key {
+		t.Fatalf("edited rerun = %+v (deletes %v), want only the retry chunk replaced", stats, store.deletes)
+	}
+}
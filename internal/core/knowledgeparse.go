@@ -0,0 +1,641 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// The knowledge parser turns the conventions people already write in ticket
+// markdown and session transcripts into typed entries. It recognises:
+//
+//   - headings that name a kind: "## Decision: Use SQLite", "### Gotcha",
+//     "## ADR-0004: Poll for changes"; plural ones ("## Decisions",
+//     "## Lessons learned") make each list item or sub-heading an entry;
+//   - MADR-style blocks: any heading with "Context" and "Decision" (or
+//     "Decision Outcome") sub-sections;
+//   - inline markers starting a line or list item: "Decision:", "Learned:",
+//     "TIL:", "**Gotcha:**", …;
+//   - fields inside an entry, as "Field: value" lines or sub-headings:
+//     Context, Rationale/Why, Alternatives, Consequences, Status for a
+//     decision; Solution/Fix/Workaround, Prevention, Severity for a gotcha;
+//     Category for a learning; Tags for all three;
+//   - checkbox outcomes: under a decision "- [x]" options are what was chosen
+//     and "- [ ]" ones the alternatives; in a "## Decisions" list a checked
+//     item is accepted and an unchecked one still proposed.
+//
+// Fenced code blocks are skipped. Each entry records the 1-based line it
+// starts on.
+
+type knowledgeKind int
+
+const (
+	kindNone knowledgeKind = iota
+	kindDecision
+	kindLearning
+	kindGotcha
+)
+
+// kindLabel is what a heading or marker label means.
+type kindLabel struct {
+	kind   knowledgeKind
+	plural bool
+}
+
+// headingLabels are the heading texts (lower-cased) that name a kind.
+var headingLabels = map[string]kindLabel{
+	"decision":               {kindDecision, false},
+	"decisions":              {kindDecision, true},
+	"key decisions":          {kindDecision, true},
+	"decisions made":         {kindDecision, true},
+	"decision log":           {kindDecision, true},
+	"adr":                    {kindDecision, false},
+	"architecture decision":  {kindDecision, false},
+	"architecture decisions": {kindDecision, true},
+	"learning":               {kindLearning, false},
+	"learnings":              {kindLearning, true},
+	"key learnings":          {kindLearning, true},
+	"lesson":                 {kindLearning, false},
+	"lesson learned":         {kindLearning, false},
+	"lessons":                {kindLearning, true},
+	"lessons learned":        {kindLearning, true},
+	"til":                    {kindLearning, false},
+	"insight":                {kindLearning, false},
+	"insights":               {kindLearning, true},
+	"gotcha":                 {kindGotcha, false},
+	"gotchas":                {kindGotcha, true},
+	"pitfall":                {kindGotcha, false},
+	"pitfalls":               {kindGotcha, true},
+	"caveat":                 {kindGotcha, false},
+	"caveats":                {kindGotcha, true},
+}
+
+// markerLabels are the inline "Label:" markers that start an entry.
+var markerLabels = map[string]knowledgeKind{
+	"decision":       kindDecision,
+	"decided":        kindDecision,
+	"learning":       kindLearning,
+	"learned":        kindLearning,
+	"lesson":         kindLearning,
+	"lesson learned": kindLearning,
+	"til":            kindLearning,
+	"insight":        kindLearning,
+	"gotcha":         kindGotcha,
+	"pitfall":        kindGotcha,
+	"caveat":         kindGotcha,
+	"watch out":      kindGotcha,
+}
+
+// fieldNames maps a field label (lower-cased) to its canonical name.
+var fieldNames = map[string]string{
+	"status":                  "status",
+	"context":                 "context",
+	"rationale":               "rationale",
+	"why":                     "rationale",
+	"reason":                  "rationale",
+	"reasoning":               "rationale",
+	"alternatives":            "alternatives",
+	"alternatives considered": "alternatives",
+	"considered options":      "alternatives",
+	"options considered":      "alternatives",
+	"options":                 "alternatives",
+	"consequences":            "consequences",
+	"decision":                "decision",
+	"decision outcome":        "decision",
+	"outcome":                 "decision",
+	"solution":                "solution",
+	"fix":                     "solution",
+	"workaround":              "solution",
+	"resolution":              "solution",
+	"prevention":              "prevention",
+	"prevent":                 "prevention",
+	"avoid":                   "prevention",
+	"how to avoid":            "prevention",
+	"severity":                "severity",
+	"category":                "category",
+	"tags":                    "tags",
+}
+
+// kindFields are the canonical fields each kind accepts; any other
+// "Label: value" line stays part of the description.
+var kindFields = map[knowledgeKind]map[string]bool{
+	kindDecision: {"status": true, "context": true, "rationale": true, "alternatives": true, "consequences": true, "decision": true, "tags": true},
+	kindLearning: {"category": true, "tags": true},
+	kindGotcha:   {"solution": true, "prevention": true, "severity": true, "tags": true},
+}
+
+var (
+	headingRe  = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+	listItemRe = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+(.*)$`)
+	checkboxRe = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	labelRe    = regexp.MustCompile(`^(?:\*\*|__)?([A-Za-z][A-Za-z -]{0,30}?)(?:\*\*|__)?\s*:\s*(?:\*\*|__)?\s*(.*)$`)
+	adrLabelRe = regexp.MustCompile(`^adr[- ]?\d+$`)
+)
+
+// mdBlockLine is one source line with its 1-based number and indentation.
+type mdBlockLine struct {
+	n      int
+	indent int
+	text   string // trimmed
+}
+
+// mdBlock is a heading and everything under it up to the next heading of
+// the same or a higher level.
+type mdBlock struct {
+	level    int
+	heading  string
+	line     int
+	body     []mdBlockLine
+	children []*mdBlock
+}
+
+// lines returns the section's body and every descendant's, headings
+// included, in source order.
+func (s *mdBlock) lines() []mdBlockLine {
+	out := append([]mdBlockLine(nil), s.body...)
+	for _, c := range s.children {
+		out = append(out, mdBlockLine{n: c.line, text: strings.Repeat("#", c.level) + " " + c.heading})
+		out = append(out, c.lines()...)
+	}
+	return out
+}
+
+// parseBlocks splits markdown into a heading tree, dropping fenced code.
+func parseBlocks(content string) *mdBlock {
+	root := &mdBlock{}
+	stack := []*mdBlock{root}
+	fence := ""
+	for i, raw := range strings.Split(content, "\n") {
+		text := strings.TrimSpace(raw)
+		if fence != "" {
+			if strings.HasPrefix(text, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(text, "```") || strings.HasPrefix(text, "~~~") {
+			fence = text[:3]
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(text); m != nil && len(raw)-len(strings.TrimLeft(raw, " ")) < 4 {
+			sec := &mdBlock{level: len(m[1]), heading: m[2], line: i + 1}
+			for len(stack) > 1 && stack[len(stack)-1].level >= sec.level {
+				stack = stack[:len(stack)-1]
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, sec)
+			stack = append(stack, sec)
+			continue
+		}
+		cur := stack[len(stack)-1]
+		expanded := strings.ReplaceAll(raw, "\t", "    ")
+		cur.body = append(cur.body, mdBlockLine{n: i + 1, indent: len(expanded) - len(strings.TrimLeft(expanded, " ")), text: text})
+	}
+	return root
+}
+
+// knowledgeEntry is one parsed entry before it is typed.
+type knowledgeEntry struct {
+	kind        knowledgeKind
+	title       string
+	line        int
+	description []string
+	fields      map[string][]string
+	chosen      []string // checked options
+	options     []string // unchecked options
+	status      string   // from a checkbox in a plural decision list
+}
+
+func newEntry(kind knowledgeKind, title string, line int) *knowledgeEntry {
+	return &knowledgeEntry{kind: kind, title: cleanInline(title), line: line, fields: map[string][]string{}}
+}
+
+// parseKnowledgeMarkdown returns the entries found in one markdown document.
+func parseKnowledgeMarkdown(content string) []*knowledgeEntry {
+	var out []*knowledgeEntry
+	walkSection(parseBlocks(content), &out)
+	return out
+}
+
+func walkSection(s *mdBlock, out *[]*knowledgeEntry) {
+	if s.level > 0 {
+		label, title := classifyHeading(s.heading)
+		switch {
+		case label.kind != kindNone && label.plural:
+			parsePluralSection(s, label.kind, out)
+			return
+		case label.kind != kindNone:
+			e := newEntry(label.kind, title, s.line)
+			*out = append(*out, e)
+			fillEntry(e, s, out)
+			return
+		case isMADRBlock(s):
+			e := newEntry(kindDecision, s.heading, s.line)
+			*out = append(*out, e)
+			fillEntry(e, s, out)
+			return
+		}
+	}
+	scanMarkers(s.body, out)
+	for _, c := range s.children {
+		walkSection(c, out)
+	}
+}
+
+// classifyHeading reads a heading as "Label", "Label: title" or
+// "Label — title".
+func classifyHeading(heading string) (kindLabel, string) {
+	h := cleanInline(heading)
+	if l, ok := lookupHeadingLabel(h); ok {
+		return l, ""
+	}
+	for _, sep := range []string{":", " — ", " – ", " - "} {
+		if i := strings.Index(h, sep); i > 0 {
+			if l, ok := lookupHeadingLabel(h[:i]); ok {
+				return l, strings.TrimSpace(h[i+len(sep):])
+			}
+		}
+	}
+	return kindLabel{}, ""
+}
+
+func lookupHeadingLabel(s string) (kindLabel, bool) {
+	key := strings.ToLower(strings.TrimSpace(s))
+	if l, ok := headingLabels[key]; ok {
+		return l, true
+	}
+	if adrLabelRe.MatchString(key) {
+		return kindLabel{kind: kindDecision}, true
+	}
+	return kindLabel{}, false
+}
+
+// isMADRBlock reports whether a heading's sub-sections look like an ADR:
+// the decision or the options weighed, plus the context or consequences.
+func isMADRBlock(s *mdBlock) bool {
+	have := map[string]bool{}
+	for _, c := range s.children {
+		have[fieldNames[strings.ToLower(cleanInline(c.heading))]] = true
+	}
+	return (have["decision"] || have["alternatives"]) && (have["context"] || have["consequences"])
+}
+
+// fillEntry reads a singular entry's body and sub-sections. A sub-section
+// naming a field fills it; one naming another kind ("### Gotcha" under a
+// decision) is an entry of its own.
+func fillEntry(e *knowledgeEntry, s *mdBlock, out *[]*knowledgeEntry) {
+	own, rest := splitAtMarker(e, s.body)
+	parseEntryLines(e, own, "")
+	scanMarkers(rest, out)
+	for _, c := range s.children {
+		field := fieldNames[strings.ToLower(cleanInline(c.heading))]
+		if !kindFields[e.kind][field] {
+			if l, _ := classifyHeading(c.heading); l.kind != kindNone {
+				walkSection(c, out)
+				continue
+			}
+			field = ""
+		}
+		parseEntryLines(e, c.lines(), field)
+	}
+}
+
+// parsePluralSection makes each top-level list item (with its indented
+// continuation) and each sub-heading an entry of kind.
+func parsePluralSection(s *mdBlock, kind knowledgeKind, out *[]*knowledgeEntry) {
+	body := s.body
+	for i := 0; i < len(body); i++ {
+		m := listItemRe.FindStringSubmatch(body[i].text)
+		if m == nil || body[i].indent > 1 {
+			continue
+		}
+		text, status := m[1], ""
+		if cb := checkboxRe.FindStringSubmatch(text); cb != nil {
+			text, status = cb[2], "proposed"
+			if cb[1] != " " {
+				status = "accepted"
+			}
+		}
+		title, rest := splitTitle(text)
+		e := newEntry(kind, title, body[i].n)
+		if rest != "" {
+			e.description = append(e.description, rest)
+		}
+		if kind == kindDecision {
+			e.status = status
+		}
+		j := i + 1
+		for ; j < len(body); j++ {
+			if body[j].text != "" && body[j].indent <= body[i].indent {
+				break
+			}
+		}
+		parseEntryLines(e, body[i+1:j], "")
+		*out = append(*out, e)
+		i = j - 1
+	}
+	for _, c := range s.children {
+		if l, _ := classifyHeading(c.heading); l.kind != kindNone {
+			walkSection(c, out)
+			continue
+		}
+		e := newEntry(kind, c.heading, c.line)
+		*out = append(*out, e)
+		fillEntry(e, c, out)
+	}
+}
+
+// scanMarkers finds inline "Decision:"-style markers in prose.
+func scanMarkers(body []mdBlockLine, out *[]*knowledgeEntry) {
+	for i := 0; i < len(body); i++ {
+		l := body[i]
+		text := l.text
+		if m := listItemRe.FindStringSubmatch(text); m != nil {
+			text = m[1]
+			if cb := checkboxRe.FindStringSubmatch(text); cb != nil {
+				text = cb[2]
+			}
+		}
+		lm := labelRe.FindStringSubmatch(text)
+		if lm == nil || strings.TrimSpace(lm[2]) == "" {
+			continue
+		}
+		kind, ok := markerLabels[strings.ToLower(strings.TrimSpace(lm[1]))]
+		if !ok {
+			continue
+		}
+		title, rest := splitTitle(lm[2])
+		e := newEntry(kind, title, l.n)
+		if rest != "" {
+			e.description = append(e.description, rest)
+		}
+		// The entry runs on through its paragraph and any deeper-indented
+		// lines; a blank line or a sibling list item ends it.
+		j := i + 1
+		for ; j < len(body); j++ {
+			next := body[j]
+			if next.text == "" || next.indent < l.indent {
+				break
+			}
+			if next.indent == l.indent && (listItemRe.MatchString(next.text) || isMarker(next.text)) {
+				break
+			}
+		}
+		parseEntryLines(e, body[i+1:j], "")
+		*out = append(*out, e)
+		i = j - 1
+	}
+}
+
+// splitAtMarker cuts an entry's body at the first unindented marker that is
+// not one of the entry's own fields ("Gotcha:" under a decision), which
+// starts an entry of its own.
+func splitAtMarker(e *knowledgeEntry, lines []mdBlockLine) (own, rest []mdBlockLine) {
+	for i, l := range lines {
+		if l.indent > 0 || !isMarker(l.text) {
+			continue
+		}
+		if _, _, field := e.fieldLine(l.text); !field {
+			return lines[:i], lines[i:]
+		}
+	}
+	return lines, nil
+}
+
+func isMarker(text string) bool {
+	lm := labelRe.FindStringSubmatch(text)
+	if lm == nil {
+		return false
+	}
+	_, ok := markerLabels[strings.ToLower(strings.TrimSpace(lm[1]))]
+	return ok
+}
+
+// parseEntryLines reads an entry's lines: "Field: value" lines and list
+// items under a field fill that field, checkboxes record options, anything
+// else is description. field, when set, is the field the lines belong to
+// (a "### Consequences" sub-section).
+func parseEntryLines(e *knowledgeEntry, lines []mdBlockLine, field string) {
+	cur, sawBlank := field, false
+	for _, l := range lines {
+		text := l.text
+		if text == "" {
+			sawBlank = true
+			continue
+		}
+		if strings.HasPrefix(text, "#") && headingRe.MatchString(text) {
+			continue
+		}
+		if m := listItemRe.FindStringSubmatch(text); m != nil {
+			item := m[1]
+			if cb := checkboxRe.FindStringSubmatch(item); cb != nil {
+				if cb[1] == " " {
+					e.options = append(e.options, cleanInline(cb[2]))
+				} else {
+					e.chosen = append(e.chosen, cleanInline(cb[2]))
+				}
+				continue
+			}
+			if name, value, ok := e.fieldLine(item); ok {
+				cur = name
+				if value != "" {
+					e.fields[name] = append(e.fields[name], value)
+				}
+				continue
+			}
+			if cur != "" {
+				e.fields[cur] = append(e.fields[cur], cleanInline(item))
+			} else {
+				e.description = append(e.description, cleanInline(item))
+			}
+			sawBlank = false
+			continue
+		}
+		if name, value, ok := e.fieldLine(text); ok {
+			cur, sawBlank = name, false
+			if value != "" {
+				e.fields[name] = append(e.fields[name], value)
+			}
+			continue
+		}
+		// Prose after a blank line closes a field that already has a value,
+		// unless the lines belong to a field sub-section.
+		if cur != "" && (field != "" || !sawBlank || len(e.fields[cur]) == 0) {
+			e.fields[cur] = append(e.fields[cur], cleanInline(text))
+		} else {
+			cur = ""
+			e.description = append(e.description, cleanInline(text))
+		}
+		sawBlank = false
+	}
+}
+
+// fieldLine reports whether text is "Label: value" for a field e's kind
+// accepts.
+func (e *knowledgeEntry) fieldLine(text string) (name, value string, ok bool) {
+	m := labelRe.FindStringSubmatch(text)
+	if m == nil {
+		return "", "", false
+	}
+	name = fieldNames[strings.ToLower(strings.TrimSpace(m[1]))]
+	if !kindFields[e.kind][name] {
+		return "", "", false
+	}
+	return name, cleanInline(m[2]), true
+}
+
+// splitTitle splits "Title: more" or "Title — more" when the title part is
+// short enough to be one; otherwise the whole text is the title.
+func splitTitle(text string) (title, rest string) {
+	text = cleanInline(text)
+	for _, sep := range []string{": ", " — ", " – ", " - "} {
+		if i := strings.Index(text, sep); i > 0 && i <= 60 {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+len(sep):])
+		}
+	}
+	return text, ""
+}
+
+// cleanInline trims whitespace and wrapping emphasis.
+func cleanInline(s string) string {
+	s = strings.TrimSpace(s)
+	for _, w := range []string{"**", "__", "`"} {
+		if len(s) > 2*len(w) && strings.HasPrefix(s, w) && strings.HasSuffix(s, w) {
+			s = strings.TrimSpace(s[len(w) : len(s)-len(w)])
+		}
+	}
+	return s
+}
+
+// maxTitleLen bounds a title derived from a description.
+const maxTitleLen = 80
+
+func (e *knowledgeEntry) resolvedTitle() string {
+	title := e.title
+	if title == "" && len(e.fields["decision"]) > 0 {
+		title = e.fields["decision"][0]
+	}
+	if title == "" && len(e.description) > 0 {
+		title = e.description[0]
+	}
+	if title == "" && len(e.chosen) > 0 {
+		title = e.chosen[0]
+	}
+	title = strings.TrimSuffix(strings.TrimSpace(title), ".")
+	if utf8.RuneCountInString(title) > maxTitleLen {
+		r := []rune(title)[:maxTitleLen]
+		if i := strings.LastIndex(string(r), " "); i > maxTitleLen/2 {
+			r = []rune(string(r)[:i])
+		}
+		title = string(r) + "…"
+	}
+	return title
+}
+
+func (e *knowledgeEntry) resolvedDescription(title string) string {
+	var parts []string
+	if v := strings.Join(e.fields["decision"], " "); v != "" && v != title {
+		parts = append(parts, v)
+	}
+	if len(e.description) > 0 {
+		parts = append(parts, strings.Join(e.description, "\n"))
+	}
+	if len(parts) == 0 && len(e.chosen) > 0 {
+		parts = append(parts, "Chose "+strings.Join(e.chosen, ", "))
+	}
+	if len(parts) == 0 {
+		return title
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func (e *knowledgeEntry) tags() []string {
+	var tags []string
+	for _, v := range e.fields["tags"] {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimPrefix(strings.TrimSpace(t), "#"); t != "" {
+				tags = append(tags, t)
+			}
+		}
+	}
+	return tags
+}
+
+// firstWord returns the lower-cased first word of a field.
+func (e *knowledgeEntry) firstWord(field string) string {
+	if len(e.fields[field]) == 0 {
+		return ""
+	}
+	f := strings.Fields(strings.ToLower(e.fields[field][0]))
+	if len(f) == 0 {
+		return ""
+	}
+	return strings.Trim(f[0], ".,;*_`")
+}
+
+func (e *knowledgeEntry) decision(src *models.Provenance, at time.Time) models.Decision {
+	title := e.resolvedTitle()
+	status := e.firstWord("status")
+	if status == "superseded" {
+		status = "deprecated"
+	}
+	switch status {
+	case "proposed", "accepted", "rejected", "deprecated":
+	default:
+		switch {
+		case e.status != "":
+			status = e.status
+		case len(e.chosen) == 0 && len(e.options) > 0:
+			status = "proposed"
+		default:
+			status = "accepted"
+		}
+	}
+	return models.Decision{
+		Title:        title,
+		Description:  e.resolvedDescription(title),
+		Context:      strings.Join(e.fields["context"], " "),
+		Rationale:    strings.Join(e.fields["rationale"], " "),
+		Alternatives: append(append([]string(nil), e.fields["alternatives"]...), e.options...),
+		Consequences: e.fields["consequences"],
+		Status:       status,
+		DecidedAt:    at,
+		Tags:         e.tags(),
+		Source:       src,
+	}
+}
+
+func (e *knowledgeEntry) learning(src *models.Provenance, at time.Time) models.Learning {
+	title := e.resolvedTitle()
+	return models.Learning{
+		Title:       title,
+		Description: e.resolvedDescription(title),
+		Category:    e.firstWord("category"),
+		Tags:        e.tags(),
+		Timestamp:   at,
+		Source:      src,
+	}
+}
+
+func (e *knowledgeEntry) gotcha(src *models.Provenance, at time.Time) models.Gotcha {
+	title := e.resolvedTitle()
+	severity := e.firstWord("severity")
+	switch severity {
+	case "low", "medium", "high", "critical":
+	default:
+		severity = ""
+	}
+	return models.Gotcha{
+		Title:       title,
+		Description: e.resolvedDescription(title),
+		Solution:    strings.Join(e.fields["solution"], " "),
+		Prevention:  strings.Join(e.fields["prevention"], " "),
+		Severity:    severity,
+		Tags:        e.tags(),
+		Timestamp:   at,
+		Source:      src,
+	}
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// parsed types every entry parseKnowledgeMarkdown finds in doc.
+func parsed(doc string) (decisions, learnings, gotchas []*knowledgeEntry) {
+	for _, e := range parseKnowledgeMarkdown(doc) {
+		switch e.kind {
+		case kindDecision:
+			decisions = append(decisions, e)
+		case kindLearning:
+			learnings = append(learnings, e)
+		case kindGotcha:
+			gotchas = append(gotchas, e)
+		}
+	}
+	return
+}
+
+func TestParseKnowledge_HeadingsAndFields(t *testing.T) {
+	doc := `# Notes
+
+## Decision: Use SQLite for memory
+We need a local vector store.
+
+Why: embedded, no server to run
+Alternatives:
+- Postgres + pgvector
+- Qdrant
+Consequences:
+- CGO build
+Status: Accepted
+
+### Gotcha
+FTS5 needs a build tag.
+Fix: build with -tags sqlite_fts5
+Severity: high
+
+## Lessons learned
+- HandleMessage: drives the server without a transport
+- Polling beats fsnotify on network mounts
+  Category: technical
+`
+	ds, ls, gs := parsed(doc)
+	if len(ds) != 1 || len(ls) != 2 || len(gs) != 1 {
+		t.Fatalf("got %d decisions, %d learnings, %d gotchas", len(ds), len(ls), len(gs))
+	}
+
+	d := ds[0].decision(nil, time.Time{})
+	if d.Title != "Use SQLite for memory" || d.Description != "We need a local vector store." || d.Rationale != "embedded, no server to run" {
+		t.Errorf("decision = %+v", d)
+	}
+	if strings.Join(d.Alternatives, "|") != "Postgres + pgvector|Qdrant" || strings.Join(d.Consequences, "|") != "CGO build" || d.Status != "accepted" {
+		t.Errorf("decision fields = %+v", d)
+	}
+	if ds[0].line != 3 {
+		t.Errorf("decision line = %d, want 3", ds[0].line)
+	}
+
+	g := gs[0].gotcha(nil, time.Time{})
+	if g.Title != "FTS5 needs a build tag" || g.Solution != "build with -tags sqlite_fts5" || g.Severity != "high" || gs[0].line != 14 {
+		t.Errorf("gotcha = %+v (line %d)", g, gs[0].line)
+	}
+
+	l0, l1 := ls[0].learning(nil, time.Time{}), ls[1].learning(nil, time.Time{})
+	if l0.Title != "HandleMessage" || l0.Description != "drives the server without a transport" || ls[0].line != 20 {
+		t.Errorf("learning 0 = %+v (line %d)", l0, ls[0].line)
+	}
+	if l1.Title != "Polling beats fsnotify on network mounts" || l1.Category != "technical" {
+		t.Errorf("learning 1 = %+v", l1)
+	}
+}
+
+func TestParseKnowledge_InlineMarkers(t *testing.T) {
+	doc := "Worked on the resolver today.\n\n" +
+		"**Gotcha:** archived tickets shadow active ones\n" +
+		"Workaround: prefer the shallowest active match\n\n" +
+		"- TIL: filepath.WalkDir can skip a subtree\n" +
+		"- Decided: keep the flat layout as a fallback\n" +
+		"- plain bullet, not knowledge\n\n" +
+		"```\nDecision: inside a code fence\n```\n"
+	ds, ls, gs := parsed(doc)
+	if len(ds) != 1 || len(ls) != 1 || len(gs) != 1 {
+		t.Fatalf("got %d decisions, %d learnings, %d gotchas", len(ds), len(ls), len(gs))
+	}
+	if g := gs[0].gotcha(nil, time.Time{}); g.Title != "archived tickets shadow active ones" || g.Solution != "prefer the shallowest active match" || gs[0].line != 3 {
+		t.Errorf("gotcha = %+v (line %d)", g, gs[0].line)
+	}
+	if ls[0].resolvedTitle() != "filepath.WalkDir can skip a subtree" || ls[0].line != 6 {
+		t.Errorf("learning = %q (line %d)", ls[0].resolvedTitle(), ls[0].line)
+	}
+	if ds[0].resolvedTitle() != "keep the flat layout as a fallback" {
+		t.Errorf("decision = %q", ds[0].resolvedTitle())
+	}
+}
+
+func TestParseKnowledge_MADRAndCheckboxes(t *testing.T) {
+	doc := `## Poll for resource changes
+
+### Context
+Clients need to know when a file changes.
+
+### Considered Options
+- [x] Poll mtimes every two seconds
+- [ ] fsnotify
+- [ ] Push from the CLI
+
+### Consequences
+- Up to 2s latency
+
+## Decisions
+- [x] Tokens are hashed at rest
+- [ ] Rotate tokens automatically
+`
+	ds, _, _ := parsed(doc)
+	if len(ds) != 3 {
+		t.Fatalf("got %d decisions, want 3", len(ds))
+	}
+	madr := ds[0].decision(nil, time.Time{})
+	if madr.Title != "Poll for resource changes" || madr.Context != "Clients need to know when a file changes." {
+		t.Errorf("MADR decision = %+v", madr)
+	}
+	if madr.Description != "Chose Poll mtimes every two seconds" || strings.Join(madr.Alternatives, "|") != "fsnotify|Push from the CLI" || madr.Status != "accepted" {
+		t.Errorf("MADR outcome = %+v", madr)
+	}
+	if got := ds[1].decision(nil, time.Time{}); got.Title != "Tokens are hashed at rest" || got.Status != "accepted" {
+		t.Errorf("checked item = %+v", got)
+	}
+	if got := ds[2].decision(nil, time.Time{}); got.Status != "proposed" {
+		t.Errorf("unchecked item = %+v", got)
+	}
+}
+
+func TestParseKnowledge_ADRHeadingAndTitleFallback(t *testing.T) {
+	doc := "## ADR-0004: Serve MCP over HTTP\nStatus: proposed\nShare one workspace between clients.\n\n" +
+		"## Decision\nWe will hash tokens with SHA-256 before storing them anywhere on disk so that a leaked file reveals nothing usable.\n"
+	ds, _, _ := parsed(doc)
+	if len(ds) != 2 {
+		t.Fatalf("got %d decisions, want 2", len(ds))
+	}
+	if d := ds[0].decision(nil, time.Time{}); d.Title != "Serve MCP over HTTP" || d.Status != "proposed" {
+		t.Errorf("ADR = %+v", d)
+	}
+	title := ds[1].resolvedTitle()
+	if !strings.HasPrefix(title, "We will hash tokens") || !strings.HasSuffix(title, "…") || len([]rune(title)) > maxTitleLen+1 {
+		t.Errorf("derived title = %q", title)
+	}
+}
+
+func TestDedupeKey(t *testing.T) {
+	if dedupeKey(kindDecision, "Use SQLite!") != dedupeKey(kindDecision, "  use   sqlite") {
+		t.Error("case, punctuation and spacing should not matter")
+	}
+	if dedupeKey(kindDecision, "Use SQLite") == dedupeKey(kindGotcha, "Use SQLite") {
+		t.Error("kinds should not collide")
+	}
+	if dedupeKey(kindLearning, "---") != "" {
+		t.Error("a title with no words has no key")
+	}
+}
@@ -0,0 +1,379 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// Vector-memory namespace conventions. A task's knowledge lives under
+// tickets/<id> (KnowledgeIndexer, the task-completed hook) and wiki/<id>
+// (WikiPublisher); session transcripts live under sessions/<session-id>
+// and name their task in Meta["task_id"]. Archiving a task moves its
+// namespaces under archive/, keeping them searchable by an explicit glob
+// but out of the tickets/* and sessions/* families.
+const (
+	MemoryTicketsPrefix  = "tickets/"
+	MemoryWikiPrefix     = "wiki/"
+	MemorySessionsPrefix = "sessions/"
+	MemoryArchivePrefix  = "archive/"
+
+	MetaTaskID = "task_id"
+)
+
+// TicketNamespace is the namespace a task's knowledge is indexed under:
+// tickets/<id>, or archive/tickets/<id> once the task is archived.
+func TicketNamespace(t models.Task) string {
+	ns := MemoryTicketsPrefix + t.ID
+	if t.Status == models.TaskStatusArchived {
+		ns = MemoryArchivePrefix + ns
+	}
+	return ns
+}
+
+// MemoryLifecycleStore is the namespace-level surface the lifecycle needs
+// from the vector store. memory.SQLiteStore implements it.
+type MemoryLifecycleStore interface {
+	ListNamespaces(ctx context.Context) ([]string, error)
+	ListMeta(ctx context.Context, ns string) (map[string]map[string]string, error)
+	ListUpdated(ctx context.Context, ns string) (map[string]time.Time, error)
+	Delete(ctx context.Context, ns, key string) error
+	DeleteNamespace(ctx context.Context, ns string) (int, error)
+	RenameNamespace(ctx context.Context, from, to string) (int, error)
+}
+
+// MemoryRetention expires records under a namespace prefix once they have
+// gone MaxAge without an update.
+type MemoryRetention struct {
+	Prefix string
+	MaxAge time.Duration
+}
+
+// ParseMemoryRetention converts the hooks.memory.retention config into
+// rules, rejecting an empty prefix or a non-positive max_age. max_age
+// takes Go durations plus a days suffix ("90d").
+func ParseMemoryRetention(cfg []models.MemoryRetentionConfig) ([]MemoryRetention, error) {
+	rules := make([]MemoryRetention, 0, len(cfg))
+	for _, c := range cfg {
+		if strings.TrimSpace(c.Prefix) == "" {
+			return nil, fmt.Errorf("memory retention: prefix must not be empty")
+		}
+		age, err := parseRetentionAge(c.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("memory retention %q: %w", c.Prefix, err)
+		}
+		rules = append(rules, MemoryRetention{Prefix: c.Prefix, MaxAge: age})
+	}
+	return rules, nil
+}
+
+func parseRetentionAge(s string) (time.Duration, error) {
+	var (
+		d   time.Duration
+		err error
+	)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("max_age %q must be a positive duration (e.g. 90d, 720h)", s)
+	}
+	return d, nil
+}
+
+// The kinds of MemoryGCAction.
+const (
+	MemoryGCPurge   = "purge"   // drop a namespace whose task is gone
+	MemoryGCArchive = "archive" // move an archived task's namespace under archive/
+	MemoryGCRestore = "restore" // move an unarchived task's namespace back
+	MemoryGCExpire  = "expire"  // drop records past their retention
+)
+
+// MemoryGCAction is one step of a garbage-collection plan.
+type MemoryGCAction struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace"`
+	Target    string   `json:"target,omitempty"` // archive/restore destination
+	Keys      []string `json:"keys,omitempty"`   // expire: the expired records; nil means the whole namespace
+	Records   int      `json:"records"`
+	Reason    string   `json:"reason"`
+}
+
+// MemoryLifecycle keeps vector memory in step with the backlog: a deleted
+// task's namespaces are purged, an archived task's move under archive/, and
+// Plan/Apply sweep up whatever those hooks missed (orphans from before they
+// existed, tasks removed by hand from backlog.yaml) plus any records past a
+// retention rule. Like KnowledgeIndexer it works on an already-opened store.
+type MemoryLifecycle struct {
+	store     MemoryLifecycleStore
+	backlog   BacklogStore
+	retention []MemoryRetention
+	now       func() time.Time
+}
+
+// NewMemoryLifecycle wires the lifecycle. backlog is only read by Plan.
+func NewMemoryLifecycle(store MemoryLifecycleStore, backlog BacklogStore, retention []MemoryRetention) *MemoryLifecycle {
+	return &MemoryLifecycle{store: store, backlog: backlog, retention: retention, now: time.Now}
+}
+
+// memoryNamespace is a namespace resolved to the task that owns it.
+type memoryNamespace struct {
+	name   string
+	taskID string // "" when no task owns it (graph, custom namespaces)
+	cold   bool   // under archive/
+}
+
+// hot is the namespace's name outside archive/.
+func (n memoryNamespace) hot() string { return strings.TrimPrefix(n.name, MemoryArchivePrefix) }
+
+// namespaces lists every namespace with its owning task. tickets/<id> and
+// wiki/<id> are owned by id; a session namespace by the task_id its
+// records carry.
+func (ml *MemoryLifecycle) namespaces(ctx context.Context) ([]memoryNamespace, error) {
+	names, err := ml.store.ListNamespaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+	out := make([]memoryNamespace, 0, len(names))
+	for _, name := range names {
+		n := memoryNamespace{name: name}
+		inner, cold := strings.CutPrefix(name, MemoryArchivePrefix)
+		n.cold = cold
+		switch {
+		case strings.HasPrefix(inner, MemoryTicketsPrefix):
+			n.taskID = strings.TrimPrefix(inner, MemoryTicketsPrefix)
+		case strings.HasPrefix(inner, MemoryWikiPrefix):
+			n.taskID = strings.TrimPrefix(inner, MemoryWikiPrefix)
+		case strings.HasPrefix(inner, MemorySessionsPrefix):
+			metas, err := ml.store.ListMeta(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("list %s: %w", name, err)
+			}
+			for _, meta := range metas {
+				if id := meta[MetaTaskID]; id != "" {
+					n.taskID = id
+					break
+				}
+			}
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// owned returns the namespaces belonging to taskID.
+func (ml *MemoryLifecycle) owned(ctx context.Context, taskID string) ([]memoryNamespace, error) {
+	all, err := ml.namespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []memoryNamespace
+	for _, n := range all {
+		if n.taskID == taskID {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+// TaskDeleted purges every namespace the task owns, hot or archived, and
+// returns how many records went.
+func (ml *MemoryLifecycle) TaskDeleted(ctx context.Context, taskID string) (int, error) {
+	owned, err := ml.owned(ctx, taskID)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, n := range owned {
+		removed, err := ml.store.DeleteNamespace(ctx, n.name)
+		if err != nil {
+			return total, fmt.Errorf("purge %s: %w", n.name, err)
+		}
+		total += removed
+	}
+	return total, nil
+}
+
+// TaskArchived moves the task's namespaces under archive/ and returns how
+// many records moved.
+func (ml *MemoryLifecycle) TaskArchived(ctx context.Context, taskID string) (int, error) {
+	return ml.move(ctx, taskID, true)
+}
+
+// TaskUnarchived moves the task's namespaces back out of archive/.
+func (ml *MemoryLifecycle) TaskUnarchived(ctx context.Context, taskID string) (int, error) {
+	return ml.move(ctx, taskID, false)
+}
+
+func (ml *MemoryLifecycle) move(ctx context.Context, taskID string, toCold bool) (int, error) {
+	owned, err := ml.owned(ctx, taskID)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, n := range owned {
+		if n.cold == toCold {
+			continue
+		}
+		target := n.hot()
+		if toCold {
+			target = MemoryArchivePrefix + n.name
+		}
+		moved, err := ml.store.RenameNamespace(ctx, n.name, target)
+		if err != nil {
+			return total, fmt.Errorf("move %s to %s: %w", n.name, target, err)
+		}
+		total += moved
+	}
+	return total, nil
+}
+
+// Plan works out what a GC pass would do without changing anything:
+// purge namespaces whose task is no longer in the backlog, move those
+// whose task's archived state disagrees with their location, and expire
+// records past the retention rule with the longest matching prefix
+// (matched against where the namespace will end up). Actions come back
+// ordered by namespace, moves before expiries.
+func (ml *MemoryLifecycle) Plan(ctx context.Context) ([]MemoryGCAction, error) {
+	backlog, err := ml.backlog.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load backlog: %w", err)
+	}
+	tasks := make(map[string]models.Task, len(backlog.Tasks))
+	for _, t := range backlog.Tasks {
+		tasks[t.ID] = t
+	}
+	all, err := ml.namespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].name < all[j].name })
+
+	var actions []MemoryGCAction
+	for _, n := range all {
+		updated, err := ml.store.ListUpdated(ctx, n.name)
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", n.name, err)
+		}
+		dest := n.name
+		if n.taskID != "" {
+			t, ok := tasks[n.taskID]
+			archived := ok && t.Status == models.TaskStatusArchived
+			switch {
+			case !ok:
+				actions = append(actions, MemoryGCAction{
+					Kind: MemoryGCPurge, Namespace: n.name, Records: len(updated),
+					Reason: fmt.Sprintf("task %s is not in the backlog", n.taskID),
+				})
+				continue
+			case archived && !n.cold:
+				dest = MemoryArchivePrefix + n.name
+				actions = append(actions, MemoryGCAction{
+					Kind: MemoryGCArchive, Namespace: n.name, Target: dest, Records: len(updated),
+					Reason: fmt.Sprintf("task %s is archived", n.taskID),
+				})
+			case !archived && n.cold:
+				dest = n.hot()
+				actions = append(actions, MemoryGCAction{
+					Kind: MemoryGCRestore, Namespace: n.name, Target: dest, Records: len(updated),
+					Reason: fmt.Sprintf("task %s is %s", n.taskID, t.Status),
+				})
+			}
+		}
+		if a, ok := ml.expire(dest, updated); ok {
+			actions = append(actions, a)
+		}
+	}
+	return actions, nil
+}
+
+// expire plans the retention sweep of one namespace, if a rule applies
+// and anything is past it. Records with no recorded update time are kept.
+func (ml *MemoryLifecycle) expire(ns string, updated map[string]time.Time) (MemoryGCAction, bool) {
+	var rule *MemoryRetention
+	for i, r := range ml.retention {
+		if strings.HasPrefix(ns, r.Prefix) && (rule == nil || len(r.Prefix) > len(rule.Prefix)) {
+			rule = &ml.retention[i]
+		}
+	}
+	if rule == nil {
+		return MemoryGCAction{}, false
+	}
+	cutoff := ml.now().Add(-rule.MaxAge)
+	var keys []string
+	for key, at := range updated {
+		if !at.IsZero() && at.Before(cutoff) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return MemoryGCAction{}, false
+	}
+	sort.Strings(keys)
+	a := MemoryGCAction{
+		Kind: MemoryGCExpire, Namespace: ns, Keys: keys, Records: len(keys),
+		Reason: fmt.Sprintf("older than %s (retention for %s)", formatRetentionAge(rule.MaxAge), rule.Prefix),
+	}
+	if len(keys) == len(updated) {
+		a.Keys = nil
+	}
+	return a, true
+}
+
+// formatRetentionAge renders whole days as "90d", anything else as a Go
+// duration.
+func formatRetentionAge(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
+
+// Apply carries out a plan and returns how many records it removed and
+// moved. It stops at the first failure; a rerun of Plan picks up where it
+// left off.
+func (ml *MemoryLifecycle) Apply(ctx context.Context, actions []MemoryGCAction) (removed, moved int, err error) {
+	for _, a := range actions {
+		switch a.Kind {
+		case MemoryGCPurge:
+			n, err := ml.store.DeleteNamespace(ctx, a.Namespace)
+			if err != nil {
+				return removed, moved, fmt.Errorf("purge %s: %w", a.Namespace, err)
+			}
+			removed += n
+		case MemoryGCArchive, MemoryGCRestore:
+			n, err := ml.store.RenameNamespace(ctx, a.Namespace, a.Target)
+			if err != nil {
+				return removed, moved, fmt.Errorf("move %s to %s: %w", a.Namespace, a.Target, err)
+			}
+			moved += n
+		case MemoryGCExpire:
+			if a.Keys == nil {
+				n, err := ml.store.DeleteNamespace(ctx, a.Namespace)
+				if err != nil {
+					return removed, moved, fmt.Errorf("expire %s: %w", a.Namespace, err)
+				}
+				removed += n
+				continue
+			}
+			for _, key := range a.Keys {
+				if err := ml.store.Delete(ctx, a.Namespace, key); err != nil {
+					return removed, moved, fmt.Errorf("expire %s/%s: %w", a.Namespace, key, err)
+				}
+				removed++
+			}
+		default:
+			return removed, moved, fmt.Errorf("unknown memory gc action %q", a.Kind)
+		}
+	}
+	return removed, moved, nil
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+type lifecycleRecord struct {
+	meta    map[string]string
+	updated time.Time
+}
+
+// fakeLifecycleStore is an in-memory MemoryLifecycleStore.
+type fakeLifecycleStore struct {
+	ns map[string]map[string]lifecycleRecord
+}
+
+func newFakeLifecycleStore() *fakeLifecycleStore {
+	return &fakeLifecycleStore{ns: map[string]map[string]lifecycleRecord{}}
+}
+
+func (f *fakeLifecycleStore) put(ns, key string, updated time.Time, meta map[string]string) {
+	if f.ns[ns] == nil {
+		f.ns[ns] = map[string]lifecycleRecord{}
+	}
+	f.ns[ns][key] = lifecycleRecord{meta: meta, updated: updated}
+}
+
+func (f *fakeLifecycleStore) ListNamespaces(context.Context) ([]string, error) {
+	var out []string
+	for ns, recs := range f.ns {
+		if len(recs) > 0 {
+			out = append(out, ns)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (f *fakeLifecycleStore) ListMeta(_ context.Context, ns string) (map[string]map[string]string, error) {
+	out := map[string]map[string]string{}
+	for k, r := range f.ns[ns] {
+		out[k] = r.meta
+	}
+	return out, nil
+}
+
+func (f *fakeLifecycleStore) ListUpdated(_ context.Context, ns string) (map[string]time.Time, error) {
+	out := map[string]time.Time{}
+	for k, r := range f.ns[ns] {
+		out[k] = r.updated
+	}
+	return out, nil
+}
+
+func (f *fakeLifecycleStore) Delete(_ context.Context, ns, key string) error {
+	delete(f.ns[ns], key)
+	return nil
+}
+
+func (f *fakeLifecycleStore) DeleteNamespace(_ context.Context, ns string) (int, error) {
+	n := len(f.ns[ns])
+	delete(f.ns, ns)
+	return n, nil
+}
+
+func (f *fakeLifecycleStore) RenameNamespace(_ context.Context, from, to string) (int, error) {
+	n := len(f.ns[from])
+	for k, r := range f.ns[from] {
+		f.put(to, k, r.updated, r.meta)
+	}
+	delete(f.ns, from)
+	return n, nil
+}
+
+func (f *fakeLifecycleStore) names() string {
+	names, _ := f.ListNamespaces(context.Background())
+	return strings.Join(names, " ")
+}
+
+func TestMemoryLifecycle_TaskTransitions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := newFakeLifecycleStore()
+	store.put("tickets/TASK-00001", "context.md#a", now, nil)
+	store.put("wiki/TASK-00001", "page", now, nil)
+	store.put("sessions/S-1", "transcript", now, map[string]string{MetaTaskID: "TASK-00001"})
+	store.put("sessions/S-2", "transcript", now, map[string]string{MetaTaskID: "TASK-00002"})
+	store.put("tickets/TASK-00002", "notes.md#b", now, nil)
+	store.put("graph", "TASK-00001|depends_on|TASK-00002", now, nil)
+	ml := NewMemoryLifecycle(store, &fakeBacklogStore{backlog: &models.Backlog{}}, nil)
+
+	moved, err := ml.TaskArchived(ctx, "TASK-00001")
+	if err != nil || moved != 3 {
+		t.Fatalf("TaskArchived = %d, %v; want 3 records moved", moved, err)
+	}
+	want := "archive/sessions/S-1 archive/tickets/TASK-00001 archive/wiki/TASK-00001 graph sessions/S-2 tickets/TASK-00002"
+	if got := store.names(); got != want {
+		t.Errorf("after archive: %s\nwant %s", got, want)
+	}
+	if moved, _ := ml.TaskArchived(ctx, "TASK-00001"); moved != 0 {
+		t.Errorf("second archive moved %d records, want 0", moved)
+	}
+
+	if moved, err := ml.TaskUnarchived(ctx, "TASK-00001"); err != nil || moved != 3 {
+		t.Fatalf("TaskUnarchived = %d, %v", moved, err)
+	}
+	if got := store.names(); !strings.Contains(got, "tickets/TASK-00001") || strings.Contains(got, "archive/") {
+		t.Errorf("after unarchive: %s", got)
+	}
+
+	removed, err := ml.TaskDeleted(ctx, "TASK-00001")
+	if err != nil || removed != 3 {
+		t.Fatalf("TaskDeleted = %d, %v; want 3", removed, err)
+	}
+	if got := store.names(); got != "graph sessions/S-2 tickets/TASK-00002" {
+		t.Errorf("after delete: %s", got)
+	}
+}
+
+func TestMemoryLifecycle_PlanAndApply(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-40 * 24 * time.Hour)
+	store := newFakeLifecycleStore()
+	store.put("tickets/TASK-00001", "context.md#a", now, nil)                                 // active: kept
+	store.put("tickets/TASK-00002", "context.md#b", now, nil)                                 // archived: moved
+	store.put("archive/tickets/TASK-00003", "notes.md#c", now, nil)                           // restored
+	store.put("tickets/TASK-00009", "notes.md#d", now, nil)                                   // orphan: purged
+	store.put("sessions/S-9", "transcript", now, map[string]string{MetaTaskID: "TASK-00009"}) // orphan session
+	store.put("sessions/S-1", "transcript", old, map[string]string{MetaTaskID: "TASK-00001"}) // expired whole
+	store.put("sessions/S-x", "a", old, nil)                                                  // unowned, one expired
+	store.put("sessions/S-x", "b", now, nil)
+	store.put("graph", "edge", old, nil) // no rule: kept
+
+	backlog := &models.Backlog{Tasks: []models.Task{
+		{ID: "TASK-00001", Status: models.TaskStatusInProgress},
+		{ID: "TASK-00002", Status: models.TaskStatusArchived},
+		{ID: "TASK-00003", Status: models.TaskStatusBacklog},
+	}}
+	retention, err := ParseMemoryRetention([]models.MemoryRetentionConfig{
+		{Prefix: "sessions/", MaxAge: "30d"},
+		{Prefix: "archive/", MaxAge: "720h"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ml := NewMemoryLifecycle(store, &fakeBacklogStore{backlog: backlog}, retention)
+	ml.now = func() time.Time { return now }
+
+	actions, err := ml.Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, a := range actions {
+		s := a.Kind + " " + a.Namespace
+		if a.Target != "" {
+			s += ">" + a.Target
+		}
+		if a.Keys != nil {
+			s += " " + strings.Join(a.Keys, ",")
+		}
+		got = append(got, s)
+	}
+	want := []string{
+		"restore archive/tickets/TASK-00003>tickets/TASK-00003",
+		"expire sessions/S-1",
+		"purge sessions/S-9",
+		"expire sessions/S-x a",
+		"archive tickets/TASK-00002>archive/tickets/TASK-00002",
+		"purge tickets/TASK-00009",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("plan:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if before := store.names(); !strings.Contains(before, "tickets/TASK-00009") {
+		t.Fatal("Plan must not change the store")
+	}
+
+	removed, moved, err := ml.Apply(ctx, actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 4 || moved != 2 {
+		t.Errorf("Apply removed %d, moved %d; want 4, 2", removed, moved)
+	}
+	if got, want := store.names(), "archive/tickets/TASK-00002 graph sessions/S-x tickets/TASK-00001 tickets/TASK-00003"; got != want {
+		t.Errorf("after apply: %s\nwant %s", got, want)
+	}
+	if again, _ := ml.Plan(ctx); len(again) != 0 {
+		t.Errorf("second plan not empty: %+v", again)
+	}
+}
+
+func TestParseMemoryRetention(t *testing.T) {
+	rules, err := ParseMemoryRetention([]models.MemoryRetentionConfig{{Prefix: "sessions/", MaxAge: "7d"}, {Prefix: "wiki/", MaxAge: "36h"}})
+	if err != nil || len(rules) != 2 || rules[0].MaxAge != 7*24*time.Hour || rules[1].MaxAge != 36*time.Hour {
+		t.Fatalf("rules = %+v, %v", rules, err)
+	}
+	for _, bad := range []models.MemoryRetentionConfig{
+		{Prefix: "", MaxAge: "7d"},
+		{Prefix: "sessions/", MaxAge: "soon"},
+		{Prefix: "sessions/", MaxAge: "0d"},
+		{Prefix: "sessions/", MaxAge: "-1h"},
+	} {
+		if _, err := ParseMemoryRetention([]models.MemoryRetentionConfig{bad}); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
+func TestTicketNamespace(t *testing.T) {
+	if got := TicketNamespace(models.Task{ID: "TASK-1", Status: models.TaskStatusDone}); got != "tickets/TASK-1" {
+		t.Errorf("done task namespace = %s", got)
+	}
+	if got := TicketNamespace(models.Task{ID: "TASK-1", Status: models.TaskStatusArchived}); got != "archive/tickets/TASK-1" {
+		t.Errorf("archived task namespace = %s", got)
+	}
+}
+
+// recordingMemoryHook records TaskManager's memory-hook calls.
+type recordingMemoryHook struct {
+	calls []string
+	err   error
+}
+
+func (h *recordingMemoryHook) TaskArchived(id string) error {
+	h.calls = append(h.calls, "archived "+id)
+	return h.err
+}
+
+func (h *recordingMemoryHook) TaskUnarchived(id string) error {
+	h.calls = append(h.calls, "unarchived "+id)
+	return h.err
+}
+
+func (h *recordingMemoryHook) TaskDeleted(id string) error {
+	h.calls = append(h.calls, "deleted "+id)
+	return h.err
+}
+
+func TestTaskManager_MemoryHookFollowsLifecycle(t *testing.T) {
+	tm, _, _, _, _, _ := createTestTaskManager(t)
+	hook := &recordingMemoryHook{}
+	tm.SetMemoryHook(hook)
+
+	task, err := tm.Create(CreateTaskOpts{Title: "Memory", TaskType: models.TaskTypeFeat, Repo: "github.com/test/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.Archive(task.ID, ArchiveOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.Unarchive(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	// A failing hook warns but never fails the transition.
+	hook.err = errors.New("store locked")
+	if err := tm.Delete(task.ID); err != nil {
+		t.Fatalf("Delete with a failing memory hook: %v", err)
+	}
+	want := "archived " + task.ID + "|unarchived " + task.ID + "|deleted " + task.ID
+	if got := strings.Join(hook.calls, "|"); got != want {
+		t.Errorf("hook calls = %s, want %s", got, want)
+	}
+}
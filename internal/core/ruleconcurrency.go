@@ -0,0 +1,254 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/lockfile"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// ConcurrencyState is the debounce/throttle bookkeeping of rules that declare
+// `concurrency:`. It outlives a process (the scheduler daemon restarts; `adb
+// schedule dispatch` is a one-shot), so it sits behind ConcurrencyStore.
+type ConcurrencyState struct {
+	// LastRun is when each throttled rule last ran, by rule then key.
+	LastRun map[string]map[string]time.Time `yaml:"last_run,omitempty"`
+	// Pending are debounced firings waiting for their key to go quiet.
+	Pending []PendingFiring `yaml:"pending,omitempty"`
+}
+
+// PendingFiring is a debounced firing: the latest event for its rule and key,
+// held until Due, and how many events it stands for. Released is when a flush
+// took it to run; it stays held until that run is recorded, and a flush
+// releases it again once debounceLease has passed without.
+type PendingFiring struct {
+	Rule     string            `yaml:"rule"`
+	Key      string            `yaml:"key"`
+	Event    string            `yaml:"event"`
+	Payload  map[string]string `yaml:"payload,omitempty"`
+	First    time.Time         `yaml:"first"`
+	Due      time.Time         `yaml:"due"`
+	Count    int               `yaml:"count"`
+	Released time.Time         `yaml:"released,omitempty"`
+}
+
+// debounceLease is how long a released debounced firing may take to run and
+// be recorded before the next flush assumes its process died.
+const debounceLease = time.Hour
+
+// ConcurrencyStore persists ConcurrencyState.
+type ConcurrencyStore interface {
+	// Update applies fn to the state and saves the result unless fn errors, as
+	// one read-modify-write no other writer (in or out of process) interleaves
+	// with. fn returning errStateUnchanged skips the save.
+	Update(fn func(*ConcurrencyState) error) error
+}
+
+// errStateUnchanged lets an Update func skip the save; Update returns nil.
+var errStateUnchanged = errors.New("concurrency state unchanged")
+
+// admit decides whether a firing of rule for key at `at` runs now. With
+// debounce set (and the rule debounced) the firing is held — replacing any
+// held firing for the key and pushing its due time out — and admit reports
+// why. A throttled rule runs when its key has not run within the window, and
+// records the run.
+func (s *ConcurrencyState) admit(rule models.Rule, key, event string, payload map[string]string, at time.Time, debounce bool) (bool, string) {
+	if rule.Concurrency == nil {
+		return true, ""
+	}
+	if d, _ := rule.Concurrency.DebounceDuration(); debounce && d > 0 {
+		due := at.Add(d)
+		for i := range s.Pending {
+			p := &s.Pending[i]
+			if p.Rule == rule.Name && p.Key == key && p.Released.IsZero() {
+				p.Event, p.Payload, p.Due = event, payload, due
+				p.Count++
+				return false, fmt.Sprintf("debounced: %d event(s) for %q held until %s", p.Count, key, due.Format(time.RFC3339))
+			}
+		}
+		s.Pending = append(s.Pending, PendingFiring{Rule: rule.Name, Key: key, Event: event, Payload: payload, First: at, Due: due, Count: 1})
+		return false, fmt.Sprintf("debounced: 1 event(s) for %q held until %s", key, due.Format(time.RFC3339))
+	}
+	if d, _ := rule.Concurrency.ThrottleDuration(); d > 0 {
+		last := s.LastRun[rule.Name][key]
+		if !last.IsZero() && at.Sub(last) < d {
+			return false, fmt.Sprintf("throttled: %q ran at %s; next run after %s",
+				key, last.Format(time.RFC3339), last.Add(d).Format(time.RFC3339))
+		}
+		if s.LastRun == nil {
+			s.LastRun = make(map[string]map[string]time.Time)
+		}
+		if s.LastRun[rule.Name] == nil {
+			s.LastRun[rule.Name] = make(map[string]time.Time)
+		}
+		s.LastRun[rule.Name][key] = at
+	}
+	return true, ""
+}
+
+// takeDue removes and returns the pending firings due at or before now,
+// earliest first.
+func (s *ConcurrencyState) takeDue(now time.Time) []PendingFiring {
+	var due, kept []PendingFiring
+	for _, p := range s.Pending {
+		if p.Due.After(now) {
+			kept = append(kept, p)
+		} else {
+			due = append(due, p)
+		}
+	}
+	s.Pending = kept
+	sort.SliceStable(due, func(i, j int) bool { return due[i].Due.Before(due[j].Due) })
+	return due
+}
+
+// releaseDue marks the pending firings due at or before now as released and
+// returns them, earliest first, leaving them held until done. One released
+// more than debounceLease ago is released again.
+func (s *ConcurrencyState) releaseDue(now time.Time) []PendingFiring {
+	var due []PendingFiring
+	for i := range s.Pending {
+		p := &s.Pending[i]
+		if p.Due.After(now) || (!p.Released.IsZero() && now.Sub(p.Released) < debounceLease) {
+			continue
+		}
+		p.Released = now
+		due = append(due, *p)
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].Due.Before(due[j].Due) })
+	return due
+}
+
+// done drops a released firing once its run is recorded. A firing released
+// again since (by a flush that outlived the lease) is not this one, and stays.
+func (s *ConcurrencyState) done(p PendingFiring) {
+	for i, cur := range s.Pending {
+		if cur.Rule == p.Rule && cur.Key == p.Key && cur.Released.Equal(p.Released) {
+			s.Pending = append(s.Pending[:i:i], s.Pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// concurrencyKey resolves a rule's concurrency key against the payload. An
+// unset key groups every firing of the rule under its name.
+func concurrencyKey(rule models.Rule, payload map[string]string) (string, error) {
+	if rule.Concurrency == nil || rule.Concurrency.Key == "" {
+		return rule.Name, nil
+	}
+	return expandTemplate(rule.Concurrency.Key, payload)
+}
+
+// memConcurrencyStore is the engine's default: state for the life of the
+// process only.
+type memConcurrencyStore struct {
+	mu    sync.Mutex
+	state ConcurrencyState
+}
+
+func (m *memConcurrencyStore) Update(fn func(*ConcurrencyState) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.state
+	s.Pending = append([]PendingFiring(nil), m.state.Pending...)
+	if err := fn(&s); err != nil {
+		if errors.Is(err, errStateUnchanged) {
+			return nil
+		}
+		return err
+	}
+	m.state = s
+	return nil
+}
+
+// FileConcurrencyStore is the file-backed ConcurrencyStore: YAML under the
+// workspace state dir, replaced atomically on save. Update holds a flock on a
+// sidecar .lock file, so the daemon and a one-shot `adb schedule dispatch`
+// never lose each other's writes. A missing file is empty state.
+type FileConcurrencyStore struct {
+	mu   sync.Mutex // serialises this process before the file lock
+	path string
+}
+
+// NewFileConcurrencyStore returns a store at path (typically
+// .adb/automation_state.yaml).
+func NewFileConcurrencyStore(path string) *FileConcurrencyStore {
+	return &FileConcurrencyStore{path: path}
+}
+
+// Update applies fn to the state under the store lock and saves the result.
+func (f *FileConcurrencyStore) Update(fn func(*ConcurrencyState) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("create automation state dir: %w", err)
+	}
+	lf, err := os.OpenFile(f.path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open automation state lock: %w", err)
+	}
+	defer lf.Close()
+	unlock, err := lockfile.Lock(lf)
+	if err != nil {
+		return fmt.Errorf("lock automation state: %w", err)
+	}
+	defer unlock()
+
+	s, err := f.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(&s); err != nil {
+		if errors.Is(err, errStateUnchanged) {
+			return nil
+		}
+		return err
+	}
+	return f.save(s)
+}
+
+func (f *FileConcurrencyStore) load() (ConcurrencyState, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return ConcurrencyState{}, nil
+	}
+	if err != nil {
+		return ConcurrencyState{}, fmt.Errorf("read automation state: %w", err)
+	}
+	var s ConcurrencyState
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return ConcurrencyState{}, fmt.Errorf("parse automation state: %w", err)
+	}
+	return s, nil
+}
+
+// save replaces the state file via a private temp file and rename.
+func (f *FileConcurrencyStore) save(s ConcurrencyState) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("encode automation state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write automation state: %w", err)
+	}
+	_, werr := tmp.Write(data)
+	if cerr := tmp.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr == nil {
+		werr = os.Rename(tmp.Name(), f.path)
+	}
+	if werr != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write automation state: %w", werr)
+	}
+	return nil
+}
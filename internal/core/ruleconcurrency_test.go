@@ -0,0 +1,209 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+func TestRuleEngine_Debounce_CoalescesEvents(t *testing.T) {
+	rule := models.Rule{
+		Name:        "digest",
+		On:          models.RuleTrigger{Event: "task.status_changed"},
+		Run:         models.RuleAction{Exec: []string{"digest", "{{.task_id}}", "{{.new_status}}"}},
+		Concurrency: &models.RuleConcurrency{Key: "{{.task_id}}", Debounce: "1m"},
+	}
+	now := time.Date(2026, 7, 7, 12, 0, 0, 0, time.UTC)
+	runner, ledger := &fakeRunner{}, &fakeLedger{}
+	store := NewFileConcurrencyStore(filepath.Join(t.TempDir(), "automation_state.yaml"))
+	eng := NewRuleEngine(&fakeRuleStore{set: models.RuleSet{Rules: []models.Rule{rule}}}, nil, runner, nil, nil,
+		WithRuleClock(func() time.Time { return now }), WithRuleLedger(ledger), WithRuleConcurrencyStore(store))
+	ctx := context.Background()
+
+	// Ten status changes for TASK-1 in under a minute, one for TASK-2.
+	for i := 0; i < 10; i++ {
+		fs, _ := eng.Dispatch(ctx, "task.status_changed", map[string]string{"task_id": "TASK-1", "new_status": []string{"in_progress", "blocked"}[i%2]})
+		if fs[0].Status != FiringSkipped || !strings.HasPrefix(fs[0].Reason, "debounced:") {
+			t.Fatalf("event %d = %+v, want debounced", i, fs[0])
+		}
+		now = now.Add(5 * time.Second)
+	}
+	_, _ = eng.Dispatch(ctx, "task.status_changed", map[string]string{"task_id": "TASK-2", "new_status": "done"})
+	if len(runner.execs) != 0 {
+		t.Fatalf("debounced events ran: %+v", runner.execs)
+	}
+
+	// Still inside TASK-1's quiet period: nothing released.
+	now = now.Add(30 * time.Second)
+	if fs, err := eng.FlushDebounced(ctx); err != nil || len(fs) != 0 {
+		t.Fatalf("early flush = %+v, %v", fs, err)
+	}
+
+	// The state survives a new engine (a daemon restart) on the same store.
+	eng = NewRuleEngine(&fakeRuleStore{set: models.RuleSet{Rules: []models.Rule{rule}}}, nil, runner, nil, nil,
+		WithRuleClock(func() time.Time { return now }), WithRuleLedger(ledger), WithRuleConcurrencyStore(store))
+	now = now.Add(time.Minute)
+	fs, err := eng.FlushDebounced(ctx)
+	if err != nil || len(fs) != 2 {
+		t.Fatalf("flush = %+v, %v; want one run per key", fs, err)
+	}
+	if fs[0].Status != FiringFired || fs[0].Coalesced != 10 || fs[0].Entity != "TASK-1" {
+		t.Errorf("TASK-1 firing = %+v, want one run standing for 10 events", fs[0])
+	}
+	if got := strings.Join(runner.execs[0].args, " "); got != "digest TASK-1 blocked" {
+		t.Errorf("released run args = %q, want the latest event's payload", got)
+	}
+	if fs, _ := eng.FlushDebounced(ctx); len(fs) != 0 {
+		t.Errorf("second flush = %+v, want nothing left", fs)
+	}
+	if last := ledger.records[len(ledger.records)-1]; last.Coalesced != 1 || last.Entity != "TASK-2" {
+		t.Errorf("ledger tail = %+v", last)
+	}
+}
+
+func TestRuleEngine_Throttle(t *testing.T) {
+	rule := models.Rule{
+		Name:        "sync",
+		On:          models.RuleTrigger{Schedule: "1m"},
+		Run:         models.RuleAction{Skill: "sync"},
+		Concurrency: &models.RuleConcurrency{Throttle: "10m"},
+	}
+	now := time.Date(2026, 7, 7, 12, 0, 0, 0, time.UTC)
+	eng := NewRuleEngine(&fakeRuleStore{set: models.RuleSet{Rules: []models.Rule{rule}}}, nil, &fakeRunner{}, nil, nil,
+		WithRuleClock(func() time.Time { return now }))
+	for _, step := range []struct {
+		advance time.Duration
+		want    string
+	}{{0, FiringFired}, {5 * time.Minute, FiringSkipped}, {6 * time.Minute, FiringFired}, {time.Minute, FiringSkipped}} {
+		now = now.Add(step.advance)
+		f, _ := eng.FireByName(context.Background(), "sync", nil)
+		if f.Status != step.want {
+			t.Errorf("at %s: %+v, want %s", now.Format("15:04"), f, step.want)
+		}
+	}
+}
+
+func TestRuleEngine_ConcurrencyKey_OneRunAtATime(t *testing.T) {
+	rule := models.Rule{
+		Name:        "build",
+		On:          models.RuleTrigger{Event: "task.created"},
+		Run:         models.RuleAction{Skill: "build"},
+		Concurrency: &models.RuleConcurrency{Key: "{{.repo}}"},
+	}
+	eng := NewRuleEngine(&fakeRuleStore{set: models.RuleSet{Rules: []models.Rule{rule}}}, nil, &fakeRunner{}, nil, nil).(*ruleEngine)
+	eng.acquire("build", "repo-a") // a run for repo-a is in flight
+
+	fs, _ := eng.Dispatch(context.Background(), "task.created", map[string]string{"repo": "repo-a"})
+	if fs[0].Status != FiringSkipped || !strings.Contains(fs[0].Reason, "already running") {
+		t.Errorf("same key = %+v, want skipped", fs[0])
+	}
+	fs, _ = eng.Dispatch(context.Background(), "task.created", map[string]string{"repo": "repo-b"})
+	if fs[0].Status != FiringFired {
+		t.Errorf("other key = %+v, want fired", fs[0])
+	}
+	fs, _ = eng.Dispatch(context.Background(), "task.created", nil)
+	if fs[0].Status != FiringSkipped || !strings.Contains(fs[0].Reason, "unresolved") {
+		t.Errorf("missing key = %+v, want skipped", fs[0])
+	}
+}
+
+func TestRuleEngine_Simulate_Debounce(t *testing.T) {
+	off := false
+	rule := models.Rule{
+		Name:        "digest",
+		Enabled:     &off,
+		On:          models.RuleTrigger{Event: "task.status_changed"},
+		Run:         models.RuleAction{Skill: "digest"},
+		Concurrency: &models.RuleConcurrency{Key: "{{.task_id}}", Debounce: "1m"},
+	}
+	eng := NewRuleEngine(&fakeRuleStore{set: models.RuleSet{Rules: []models.Rule{rule}}}, nil, &fakeRunner{}, nil, nil)
+	t0 := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	var occ []RuleOccurrence
+	for i := 0; i < 10; i++ { // a burst...
+		occ = append(occ, RuleOccurrence{At: t0.Add(time.Duration(i) * 5 * time.Second), Event: "task.status_changed", Payload: map[string]string{"task_id": "TASK-1"}})
+	}
+	// ...then one more an hour later.
+	occ = append(occ, RuleOccurrence{At: t0.Add(time.Hour), Event: "task.status_changed", Payload: map[string]string{"task_id": "TASK-1"}})
+
+	fs, err := eng.Simulate(context.Background(), "digest", occ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fired []Firing
+	for _, f := range fs {
+		if f.Status == FiringFired {
+			fired = append(fired, f)
+		}
+	}
+	if len(fired) != 2 || fired[0].Coalesced != 10 || !fired[0].At.Equal(t0.Add(45*time.Second+time.Minute)) || fired[1].Coalesced != 1 {
+		t.Errorf("would fire = %+v; want the burst once at its quiet time and the straggler once", fired)
+	}
+}
+
+func TestFileConcurrencyStore_UpdateIsAtomicAcrossStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "automation_state.yaml")
+	rule := models.Rule{Name: "digest", Concurrency: &models.RuleConcurrency{Debounce: "1m"}}
+	at := time.Date(2026, 7, 7, 12, 0, 0, 0, time.UTC)
+	// Two stores on one file stand in for the daemon and a one-shot dispatch.
+	stores := []*FileConcurrencyStore{NewFileConcurrencyStore(path), NewFileConcurrencyStore(path)}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(store *FileConcurrencyStore) {
+			defer wg.Done()
+			_ = store.Update(func(s *ConcurrencyState) error {
+				s.admit(rule, "k", "task.created", nil, at, true)
+				return nil
+			})
+		}(stores[i%2])
+	}
+	wg.Wait()
+
+	var got ConcurrencyState
+	_ = stores[0].Update(func(s *ConcurrencyState) error { got = *s; return errStateUnchanged })
+	if len(got.Pending) != 1 || got.Pending[0].Count != 20 {
+		t.Errorf("pending = %+v, want one firing standing for all 20 events", got.Pending)
+	}
+	if matches, _ := filepath.Glob(path + ".*.tmp"); len(matches) != 0 {
+		t.Errorf("temp files left behind: %v", matches)
+	}
+}
+
+func TestRuleEngine_FlushDebounced_HoldsUntilRecorded(t *testing.T) {
+	rule := models.Rule{
+		Name:        "digest",
+		On:          models.RuleTrigger{Event: "task.created"},
+		Run:         models.RuleAction{Skill: "digest"},
+		Concurrency: &models.RuleConcurrency{Debounce: "1m"},
+	}
+	now := time.Date(2026, 7, 7, 12, 0, 0, 0, time.UTC)
+	store := NewFileConcurrencyStore(filepath.Join(t.TempDir(), "automation_state.yaml"))
+	runner := &fakeRunner{}
+	eng := NewRuleEngine(&fakeRuleStore{set: models.RuleSet{Rules: []models.Rule{rule}}}, nil, runner, nil, nil,
+		WithRuleClock(func() time.Time { return now }), WithRuleConcurrencyStore(store))
+	ctx := context.Background()
+	_, _ = eng.Dispatch(ctx, "task.created", map[string]string{"task_id": "TASK-1"})
+
+	// A flush that released the firing and died before recording it.
+	now = now.Add(2 * time.Minute)
+	_ = store.Update(func(s *ConcurrencyState) error { s.releaseDue(now); return nil })
+	if fs, _ := eng.FlushDebounced(ctx); len(fs) != 0 {
+		t.Fatalf("flush within the lease = %+v, want the in-flight firing left alone", fs)
+	}
+	// A new event for the key is held separately, not folded into the released one.
+	_, _ = eng.Dispatch(ctx, "task.created", map[string]string{"task_id": "TASK-2"})
+
+	now = now.Add(debounceLease)
+	fs, err := eng.FlushDebounced(ctx)
+	if err != nil || len(fs) != 2 || fs[0].Status != FiringFired || fs[1].Status != FiringFired {
+		t.Fatalf("flush after the lease = %+v, %v; want the abandoned and the new firing", fs, err)
+	}
+	if fs, _ := eng.FlushDebounced(ctx); len(fs) != 0 || len(runner.skills) != 2 {
+		t.Errorf("third flush = %+v (%d runs); want nothing left", fs, len(runner.skills))
+	}
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/lockfile"
+)
+
+// FiringRecord is one firing-ledger entry: the Firing plus the payload it was
+// evaluated against, so `adb schedule history` can say what fired, when, and
+// on what.
+type FiringRecord struct {
+	Firing
+	Payload map[string]string `json:"payload,omitempty"`
+}
+
+// FiringFilter narrows a ledger read. Zero fields match everything.
+type FiringFilter struct {
+	Rule   string    // exact rule name
+	Status string    // fired | skipped | error
+	Since  time.Time // firings at or after
+	Limit  int       // newest N; <= 0 means no limit
+}
+
+func (f FiringFilter) match(r FiringRecord) bool {
+	if f.Rule != "" && r.Rule != f.Rule {
+		return false
+	}
+	if f.Status != "" && r.Status != f.Status {
+		return false
+	}
+	return f.Since.IsZero() || !r.At.Before(f.Since)
+}
+
+// FiringLedger persists the firings the engine evaluates. It is a seam like
+// the other rule-engine collaborators: a file-backed default lives here, tests
+// use a fake.
+type FiringLedger interface {
+	Append(FiringRecord) error
+	// List returns the records matching the filter, newest first.
+	List(FiringFilter) ([]FiringRecord, error)
+}
+
+// fileLedgerMaxBytes bounds the ledger file. An append that grows it past
+// the bound rewrites it keeping the newest half of the records.
+const fileLedgerMaxBytes = 4 << 20
+
+// FileFiringLedger is the default FiringLedger: append-only JSONL (one record
+// per line) under the workspace state dir, trimmed from the oldest end once
+// it passes fileLedgerMaxBytes. Malformed lines are skipped on read. Appends
+// and trims hold a sidecar flock (path + ".lock"), since the scheduler drain,
+// inline dispatch and `adb schedule run` write it from separate processes: a
+// trim never drops a line another process appended meanwhile.
+type FileFiringLedger struct {
+	path     string
+	maxBytes int64
+	mu       sync.Mutex
+}
+
+// NewFileFiringLedger returns a ledger writing to path (typically
+// .adb/rule_firings.jsonl).
+func NewFileFiringLedger(path string) *FileFiringLedger {
+	return &FileFiringLedger{path: path, maxBytes: fileLedgerMaxBytes}
+}
+
+// Append writes one record.
+func (l *FileFiringLedger) Append(r FiringRecord) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encode firing: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("create ledger dir: %w", err)
+	}
+	lf, err := os.OpenFile(l.path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open ledger lock: %w", err)
+	}
+	defer lf.Close()
+	unlock, err := lockfile.Lock(lf)
+	if err != nil {
+		return fmt.Errorf("lock ledger: %w", err)
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open ledger: %w", err)
+	}
+	_, werr := f.Write(append(line, '\n'))
+	info, serr := f.Stat()
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		return fmt.Errorf("write ledger: %w", werr)
+	}
+	if serr == nil && l.maxBytes > 0 && info.Size() > l.maxBytes {
+		return l.trim()
+	}
+	return nil
+}
+
+// trim rewrites the ledger keeping the newest half of its lines. The caller
+// holds l.mu and the ledger lock.
+func (l *FileFiringLedger) trim() error {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return fmt.Errorf("read ledger: %w", err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	kept := bytes.Join(lines[len(lines)/2:], nil)
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, kept, 0o644); err != nil {
+		return fmt.Errorf("trim ledger: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("trim ledger: %w", err)
+	}
+	return nil
+}
+
+// List reads the ledger. A missing file is an empty ledger.
+func (l *FileFiringLedger) List(filter FiringFilter) ([]FiringRecord, error) {
+	l.mu.Lock()
+	f, err := os.Open(l.path)
+	if err != nil {
+		l.mu.Unlock()
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open ledger: %w", err)
+	}
+	var all []FiringRecord
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4<<20)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var r FiringRecord
+		if json.Unmarshal([]byte(line), &r) != nil {
+			continue
+		}
+		if filter.match(r) {
+			all = append(all, r)
+		}
+	}
+	serr := sc.Err()
+	_ = f.Close()
+	l.mu.Unlock()
+	if serr != nil {
+		return nil, fmt.Errorf("read ledger: %w", serr)
+	}
+
+	out := make([]FiringRecord, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		out = append(out, all[i])
+		if filter.Limit > 0 && len(out) == filter.Limit {
+			break
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileFiringLedger_AppendList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".adb", "rule_firings.jsonl")
+	l := NewFileFiringLedger(path)
+	if recs, err := l.List(FiringFilter{}); err != nil || len(recs) != 0 {
+		t.Fatalf("empty ledger List = %+v, %v", recs, err)
+	}
+	base := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	for i, r := range []FiringRecord{
+		{Firing: Firing{Rule: "a", Status: FiringFired, At: base}},
+		{Firing: Firing{Rule: "b", Status: FiringSkipped, At: base.Add(time.Hour)}},
+		{Firing: Firing{Rule: "a", Status: FiringError, At: base.Add(2 * time.Hour)}, Payload: map[string]string{"task_id": "TASK-1"}},
+	} {
+		if err := l.Append(r); err != nil {
+			t.Fatalf("Append %d: %v", i, err)
+		}
+	}
+	// A torn or hand-edited line is skipped, not fatal.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	_, _ = f.WriteString("{not json\n")
+	_ = f.Close()
+
+	recs, err := l.List(FiringFilter{Rule: "a"})
+	if err != nil || len(recs) != 2 || recs[0].Status != FiringError || recs[0].Payload["task_id"] != "TASK-1" {
+		t.Fatalf("List(rule a) = %+v, %v; want newest first", recs, err)
+	}
+	if recs, _ := l.List(FiringFilter{Since: base.Add(30 * time.Minute), Limit: 1}); len(recs) != 1 || recs[0].Rule != "a" {
+		t.Errorf("List(since, limit 1) = %+v", recs)
+	}
+	if recs, _ := l.List(FiringFilter{Status: FiringSkipped}); len(recs) != 1 || recs[0].Rule != "b" {
+		t.Errorf("List(status skipped) = %+v", recs)
+	}
+}
+
+func TestFileFiringLedger_TrimsOldest(t *testing.T) {
+	l := NewFileFiringLedger(filepath.Join(t.TempDir(), "rule_firings.jsonl"))
+	l.maxBytes = 2000
+	base := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		if err := l.Append(FiringRecord{Firing: Firing{Rule: "r", Status: FiringFired, At: base.Add(time.Duration(i) * time.Minute)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recs, err := l.List(FiringFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) == 0 || len(recs) >= 100 {
+		t.Fatalf("kept %d records, want a trimmed tail", len(recs))
+	}
+	if want := base.Add(99 * time.Minute); !recs[0].At.Equal(want) {
+		t.Errorf("newest = %v, want %v", recs[0].At, want)
+	}
+	if info, _ := os.Stat(l.path); info.Size() > l.maxBytes {
+		t.Errorf("ledger is %d bytes, over the %d bound", info.Size(), l.maxBytes)
+	}
+}
+
+func TestFileFiringLedger_TrimKeepsOtherWritersAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rule_firings.jsonl")
+	base := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	const perWriter = 300
+	// Separate ledgers share only the file, as separate processes do.
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		l := NewFileFiringLedger(path)
+		l.maxBytes = 1500
+		wg.Add(1)
+		go func(rule string) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if err := l.Append(FiringRecord{Firing: Firing{Rule: rule, Status: FiringFired, At: base.Add(time.Duration(i) * time.Minute)}}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(fmt.Sprintf("w%d", w))
+	}
+	wg.Wait()
+
+	recs, err := NewFileFiringLedger(path).List(FiringFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A trim drops the oldest records, possibly all of a writer that finished
+	// early; every record after a writer's oldest one kept must survive.
+	kept := map[string][]bool{}
+	for w := 0; w < 4; w++ {
+		kept[fmt.Sprintf("w%d", w)] = make([]bool, perWriter)
+	}
+	for _, r := range recs {
+		kept[r.Rule][int(r.At.Sub(base)/time.Minute)] = true
+	}
+	for rule, seen := range kept {
+		first := -1
+		for i, ok := range seen {
+			if ok && first < 0 {
+				first = i
+			}
+			if first >= 0 && !ok {
+				t.Errorf("%s: record %d lost after record %d was kept", rule, i, first)
+				break
+			}
+		}
+		if first >= 0 && !seen[perWriter-1] {
+			t.Errorf("%s: newest record lost", rule)
+		}
+	}
+}
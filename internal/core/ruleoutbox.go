@@ -0,0 +1,390 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/lockfile"
+)
+
+// Dispatch paths a logged event can reach the rule engine by.
+const (
+	DispatchInline = "inline" // an EventLog subscriber, as the event is logged
+	DispatchDrain  = "drain"  // the automation-dispatch job, past its cursor
+)
+
+// LoggedEvent is an event from the event log, as DispatchLogged takes it. ID
+// identifies it across processes (the content hash observability.Event.ID);
+// Via is the dispatch path. DeferExec leaves the rules that run an exec
+// command to the drain (sync inline dispatch, so a hook never waits on one).
+type LoggedEvent struct {
+	ID        string
+	Type      string
+	At        time.Time
+	Payload   map[string]string
+	Via       string
+	DeferExec bool
+}
+
+// Dispatch claim states.
+const (
+	ClaimPending  = "pending"  // being dispatched; retried once its lease expires
+	ClaimDeferred = "deferred" // Rules are left for the next drain
+)
+
+// DispatchClaim is an open outbox entry: a logged event some path has taken
+// responsibility for dispatching, with what it needs to dispatch it again.
+// Rules, when set, limits a retry to those rules (the ones deferred).
+type DispatchClaim struct {
+	ID       string            `json:"id"`
+	Event    string            `json:"event"`
+	At       time.Time         `json:"at"`
+	Payload  map[string]string `json:"payload,omitempty"`
+	Via      string            `json:"via"`
+	Status   string            `json:"status"`
+	Rules    []string          `json:"rules,omitempty"`
+	Claimed  time.Time         `json:"claimed"`
+	Attempts int               `json:"attempts"`
+}
+
+// DispatchOutbox lets inline dispatch and the automation-dispatch drain share
+// one event log: whichever path claims an event first dispatches it, and a
+// claim stays open until its dispatch completes, so a crash or a failed
+// dispatch is retried by the drain instead of lost. Delivery is
+// at-least-once: a crash part-way through an event's rules re-fires all of
+// them.
+type DispatchOutbox interface {
+	// Claim opens c unless its ID was ever claimed, and reports whether this
+	// call claimed it.
+	Claim(c DispatchClaim) (bool, error)
+	// Update rewrites an open claim (to defer rules).
+	Update(c DispatchClaim) error
+	// Complete closes a claim; its ID stays claimed.
+	Complete(id string) error
+	// Reclaim re-opens, for the caller to dispatch, every deferred claim and
+	// every pending claim whose lease expired, counting an attempt. A claim
+	// out of attempts is closed as failed instead.
+	Reclaim(now time.Time) ([]DispatchClaim, error)
+}
+
+const (
+	// outboxLease is how long a pending claim's dispatch may take before the
+	// drain assumes its process died and dispatches it again.
+	outboxLease = 10 * time.Minute
+	// outboxMaxAttempts bounds how often one event is dispatched.
+	outboxMaxAttempts = 5
+	// outboxRetention is how long a closed claim is remembered. The drain only
+	// reads events past its cursor, so an older one is never consulted while
+	// the scheduler keeps up.
+	outboxRetention = 7 * 24 * time.Hour
+	// outboxPruneEvery is how often Claim prunes, for workspaces whose
+	// drain (which prunes on every Reclaim) never runs.
+	outboxPruneEvery = time.Hour
+)
+
+// reclaimable reports whether an open claim is due for another dispatch.
+func (c DispatchClaim) reclaimable(now time.Time) bool {
+	return c.Status == ClaimDeferred || now.Sub(c.Claimed) >= outboxLease
+}
+
+// memDispatchOutbox is the engine's default: claims for the life of the
+// process only.
+type memDispatchOutbox struct {
+	mu     sync.Mutex
+	open   map[string]DispatchClaim
+	closed map[string]bool
+}
+
+func (m *memDispatchOutbox) Claim(c DispatchClaim) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.open[c.ID]; ok || m.closed[c.ID] {
+		return false, nil
+	}
+	if m.open == nil {
+		m.open = make(map[string]DispatchClaim)
+	}
+	m.open[c.ID] = c
+	return true, nil
+}
+
+func (m *memDispatchOutbox) Update(c DispatchClaim) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.open[c.ID]; ok {
+		m.open[c.ID] = c
+	}
+	return nil
+}
+
+func (m *memDispatchOutbox) Complete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.open, id)
+	if m.closed == nil {
+		m.closed = make(map[string]bool)
+	}
+	m.closed[id] = true
+	return nil
+}
+
+func (m *memDispatchOutbox) Reclaim(now time.Time) ([]DispatchClaim, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []DispatchClaim
+	for id, c := range m.open {
+		if !c.reclaimable(now) {
+			continue
+		}
+		if c.Attempts >= outboxMaxAttempts {
+			delete(m.open, id)
+			if m.closed == nil {
+				m.closed = make(map[string]bool)
+			}
+			m.closed[id] = true
+			continue
+		}
+		c.Status, c.Claimed, c.Attempts = ClaimPending, now, c.Attempts+1
+		m.open[id] = c
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
+
+// FileDispatchOutbox is the file-backed DispatchOutbox: a directory under the
+// workspace state dir with one file per claim, so claiming an event is a
+// single exclusive create however many events the outbox remembers.
+//
+//	open/<id>.json    an open claim (pending or deferred), with its payload
+//	claimed/<id>      the closed-claim marker, kept for outboxRetention
+//	failed/<id>.json  a claim that ran out of attempts, kept for inspection
+//
+// An ID is claimed while either file exists. Reclaim scans open/ (the
+// in-flight claims only) under a sidecar flock, so two drains never re-open
+// the same claim. Closed and failed claims past outboxRetention are pruned by
+// every Reclaim and, at most hourly, by Claim, so the outbox stays bounded
+// without the scheduler.
+type FileDispatchOutbox struct {
+	dir string
+}
+
+// NewFileDispatchOutbox returns an outbox in dir (typically .adb/rule_outbox).
+func NewFileDispatchOutbox(dir string) *FileDispatchOutbox {
+	return &FileDispatchOutbox{dir: dir}
+}
+
+func (o *FileDispatchOutbox) openPath(id string) string {
+	return filepath.Join(o.dir, "open", id+".json")
+}
+
+func (o *FileDispatchOutbox) markerPath(id string) string {
+	return filepath.Join(o.dir, "claimed", id)
+}
+
+// Claim opens c unless its ID was ever claimed. The open record is created
+// exclusively first and the marker second, so a crash in between leaves an
+// open claim the drain retries rather than a marker with nothing behind it.
+func (o *FileDispatchOutbox) Claim(c DispatchClaim) (bool, error) {
+	if err := validClaimID(c.ID); err != nil {
+		return false, err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return false, fmt.Errorf("encode dispatch claim: %w", err)
+	}
+	for _, sub := range []string{"open", "claimed"} {
+		if err := os.MkdirAll(filepath.Join(o.dir, sub), 0o755); err != nil {
+			return false, fmt.Errorf("create outbox dir: %w", err)
+		}
+	}
+	created, err := createExclusive(o.openPath(c.ID), data)
+	if err != nil || !created {
+		return false, err
+	}
+	marked, err := createExclusive(o.markerPath(c.ID), nil)
+	if err != nil || !marked {
+		// Closed before: this open record is ours alone, so drop it.
+		_ = os.Remove(o.openPath(c.ID))
+		return false, err
+	}
+	o.maybePrune(time.Now())
+	return true, nil
+}
+
+// maybePrune prunes at most once per outboxPruneEvery across processes,
+// tracked by the mtime of a stamp file in the outbox dir.
+func (o *FileDispatchOutbox) maybePrune(now time.Time) {
+	stamp := filepath.Join(o.dir, ".pruned")
+	if info, err := os.Stat(stamp); err == nil && now.Sub(info.ModTime()) < outboxPruneEvery {
+		return
+	}
+	if err := os.WriteFile(stamp, nil, 0o644); err != nil {
+		return
+	}
+	o.prune(now.Add(-outboxRetention))
+}
+
+// Update rewrites an open claim.
+func (o *FileDispatchOutbox) Update(c DispatchClaim) error {
+	if err := validClaimID(c.ID); err != nil {
+		return err
+	}
+	return o.writeOpen(c)
+}
+
+// Complete closes a claim by removing its open record. The marker is ensured
+// first, in case Claim died before writing it.
+func (o *FileDispatchOutbox) Complete(id string) error {
+	if err := validClaimID(id); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(o.dir, "claimed"), 0o755); err != nil {
+		return fmt.Errorf("create outbox dir: %w", err)
+	}
+	if _, err := createExclusive(o.markerPath(id), nil); err != nil {
+		return err
+	}
+	if err := os.Remove(o.openPath(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("close dispatch claim: %w", err)
+	}
+	return nil
+}
+
+// Reclaim re-opens the open claims due for another dispatch, and drops closed
+// and failed claims past outboxRetention.
+func (o *FileDispatchOutbox) Reclaim(now time.Time) ([]DispatchClaim, error) {
+	if err := os.MkdirAll(o.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create outbox dir: %w", err)
+	}
+	lf, err := os.OpenFile(filepath.Join(o.dir, ".lock"), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open outbox lock: %w", err)
+	}
+	defer lf.Close()
+	unlock, err := lockfile.Lock(lf)
+	if err != nil {
+		return nil, fmt.Errorf("lock outbox: %w", err)
+	}
+	defer unlock()
+
+	entries, err := os.ReadDir(filepath.Join(o.dir, "open"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+	var out []DispatchClaim
+	for _, ent := range entries {
+		if ent.IsDir() || !strings.HasSuffix(ent.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(o.dir, "open", ent.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // closed meanwhile
+		}
+		var c DispatchClaim
+		if json.Unmarshal(data, &c) != nil || validClaimID(c.ID) != nil {
+			info, ierr := ent.Info()
+			if ierr == nil && now.Sub(info.ModTime()) >= outboxLease {
+				_ = os.Remove(path) // a torn write no dispatch can use
+			}
+			continue
+		}
+		if !c.reclaimable(now) {
+			continue
+		}
+		if c.Attempts >= outboxMaxAttempts {
+			if err := os.MkdirAll(filepath.Join(o.dir, "failed"), 0o755); err != nil {
+				return nil, fmt.Errorf("create outbox dir: %w", err)
+			}
+			if err := os.Rename(path, filepath.Join(o.dir, "failed", ent.Name())); err != nil {
+				return nil, fmt.Errorf("close failed dispatch claim: %w", err)
+			}
+			continue
+		}
+		c.Status, c.Claimed, c.Attempts = ClaimPending, now, c.Attempts+1
+		if err := o.writeOpen(c); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	o.prune(now.Add(-outboxRetention))
+	sort.Slice(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out, nil
+}
+
+// writeOpen replaces an open record via a private temp file and rename.
+func (o *FileDispatchOutbox) writeOpen(c DispatchClaim) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encode dispatch claim: %w", err)
+	}
+	dir := filepath.Join(o.dir, "open")
+	tmp, err := os.CreateTemp(dir, c.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write dispatch claim: %w", err)
+	}
+	_, werr := tmp.Write(data)
+	if cerr := tmp.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr == nil {
+		werr = os.Rename(tmp.Name(), o.openPath(c.ID))
+	}
+	if werr != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write dispatch claim: %w", werr)
+	}
+	return nil
+}
+
+// prune removes closed markers and failed claims last written before cutoff.
+// Best-effort: a marker that outlives retention only costs disk.
+func (o *FileDispatchOutbox) prune(cutoff time.Time) {
+	for _, sub := range []string{"claimed", "failed"} {
+		dir := filepath.Join(o.dir, sub)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, ent := range entries {
+			if info, err := ent.Info(); err == nil && info.ModTime().Before(cutoff) {
+				_ = os.Remove(filepath.Join(dir, ent.Name()))
+			}
+		}
+	}
+}
+
+// createExclusive creates path with data, reporting false when it exists.
+func createExclusive(path string, data []byte) (bool, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("write dispatch claim: %w", err)
+	}
+	_, werr := f.Write(data)
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		return false, fmt.Errorf("write dispatch claim: %w", werr)
+	}
+	return true, nil
+}
+
+// validClaimID rejects an ID that cannot be a file name.
+func validClaimID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return fmt.Errorf("invalid dispatch claim id %q", id)
+	}
+	return nil
+}
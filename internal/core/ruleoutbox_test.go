@@ -0,0 +1,205 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+func TestRuleEngine_DispatchLogged_InlineThenDrainFiresOnce(t *testing.T) {
+	rule := models.Rule{
+		Name: "on-blocked",
+		On:   models.RuleTrigger{Event: "task.status_changed"},
+		Run:  models.RuleAction{Exec: []string{"notify", "{{.task_id}}"}},
+	}
+	dir := filepath.Join(t.TempDir(), "rule_outbox")
+	runner := &fakeRunner{}
+	newEngine := func() RuleEngine {
+		return NewRuleEngine(&fakeRuleStore{set: models.RuleSet{Rules: []models.Rule{rule}}}, nil, runner, nil, nil,
+			WithRuleOutbox(NewFileDispatchOutbox(dir)))
+	}
+	// Separate engines stand in for the CLI process and the scheduler daemon.
+	cli, daemon := newEngine(), newEngine()
+	ctx := context.Background()
+	ev := LoggedEvent{
+		ID: "ev-1", Type: "task.status_changed", At: time.Now().UTC(),
+		Payload: map[string]string{"task_id": "TASK-1"}, Via: DispatchInline,
+	}
+
+	fs, claimed, err := cli.DispatchLogged(ctx, ev)
+	if err != nil || !claimed || len(fs) != 1 || fs[0].Status != FiringFired {
+		t.Fatalf("inline dispatch = %+v, %v, %v", fs, claimed, err)
+	}
+	ev.Via = DispatchDrain
+	fs, claimed, err = daemon.DispatchLogged(ctx, ev)
+	if err != nil || claimed || len(fs) != 0 {
+		t.Fatalf("drain of an inline-dispatched event = %+v, %v, %v; want unclaimed", fs, claimed, err)
+	}
+	if fs, err := daemon.RetryDispatches(ctx); err != nil || len(fs) != 0 {
+		t.Errorf("retry after a completed dispatch = %+v, %v; want nothing", fs, err)
+	}
+	if len(runner.execs) != 1 {
+		t.Errorf("rule ran %d times, want once", len(runner.execs))
+	}
+
+	// An event only the drain sees is dispatched there.
+	ev.ID = "ev-2"
+	if fs, claimed, err := daemon.DispatchLogged(ctx, ev); err != nil || !claimed || len(fs) != 1 {
+		t.Errorf("drain of a new event = %+v, %v, %v", fs, claimed, err)
+	}
+}
+
+func TestRuleEngine_DispatchLogged_DeferExecLeavesExecRulesToRetry(t *testing.T) {
+	rules := []models.Rule{
+		{Name: "notify", On: models.RuleTrigger{Event: "task.created"}, Run: models.RuleAction{Exec: []string{"notify"}}},
+		{Name: "triage", On: models.RuleTrigger{Event: "task.created"}, Run: models.RuleAction{Skill: "triage"}},
+	}
+	runner := &fakeRunner{}
+	e := NewRuleEngine(&fakeRuleStore{set: models.RuleSet{Rules: rules}}, nil, runner, nil, nil,
+		WithRuleOutbox(NewFileDispatchOutbox(t.TempDir())))
+	ctx := context.Background()
+
+	fs, claimed, err := e.DispatchLogged(ctx, LoggedEvent{ID: "ev-1", Type: "task.created", Via: DispatchInline, DeferExec: true})
+	if err != nil || !claimed || len(fs) != 1 || fs[0].Rule != "triage" {
+		t.Fatalf("sync inline dispatch = %+v, %v, %v; want only the skill rule", fs, claimed, err)
+	}
+	if len(runner.execs) != 0 {
+		t.Fatalf("exec ran inline: %+v", runner.execs)
+	}
+
+	fs, err = e.RetryDispatches(ctx)
+	if err != nil || len(fs) != 1 || fs[0].Rule != "notify" || fs[0].Event != "task.created" {
+		t.Fatalf("retry = %+v, %v; want the deferred exec rule", fs, err)
+	}
+	if fs, _ := e.RetryDispatches(ctx); len(fs) != 0 || len(runner.skills) != 1 || len(runner.execs) != 1 {
+		t.Errorf("second retry = %+v (skills %d, execs %d); want each rule fired once", fs, len(runner.skills), len(runner.execs))
+	}
+}
+
+func TestFileDispatchOutbox_ReclaimsInterruptedClaims(t *testing.T) {
+	dir := t.TempDir()
+	o := NewFileDispatchOutbox(dir)
+	now := time.Date(2026, 7, 7, 12, 0, 0, 0, time.UTC)
+	claim := func(id string, claimed time.Time, attempts int) {
+		t.Helper()
+		c := DispatchClaim{ID: id, Event: "task.created", At: claimed, Status: ClaimPending, Claimed: claimed, Attempts: attempts}
+		if ok, err := o.Claim(c); err != nil || !ok {
+			t.Fatalf("Claim(%s) = %v, %v", id, ok, err)
+		}
+	}
+	claim("fresh", now.Add(-time.Minute), 1) // still within its lease
+	claim("crashed", now.Add(-time.Hour), 1) // its process died mid-dispatch
+	claim("hopeless", now.Add(-time.Hour), outboxMaxAttempts)
+	claim("done", now.Add(-time.Hour), 1)
+	if err := o.Complete("done"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := o.Reclaim(now)
+	if err != nil || len(got) != 1 || got[0].ID != "crashed" || got[0].Attempts != 2 || !got[0].Claimed.Equal(now) {
+		t.Fatalf("Reclaim = %+v, %v; want only crashed, on attempt 2", got, err)
+	}
+	if got, _ := o.Reclaim(now); len(got) != 0 {
+		t.Errorf("a reclaimed claim was reclaimed again within its lease: %+v", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "failed", "hopeless.json")); err != nil {
+		t.Errorf("a claim out of attempts was not moved to failed/: %v", err)
+	}
+	for _, id := range []string{"fresh", "done", "hopeless"} {
+		if ok, _ := o.Claim(DispatchClaim{ID: id, Claimed: now}); ok {
+			t.Errorf("%s was claimed again", id)
+		}
+	}
+}
+
+func TestFileDispatchOutbox_PrunesExpiredMarkers(t *testing.T) {
+	dir := t.TempDir()
+	o := NewFileDispatchOutbox(dir)
+	now := time.Now().UTC()
+	if ok, err := o.Claim(DispatchClaim{ID: "old", Claimed: now}); err != nil || !ok {
+		t.Fatalf("Claim(old) = %v, %v", ok, err)
+	}
+	if err := o.Complete("old"); err != nil {
+		t.Fatal(err)
+	}
+	stale := now.Add(-8 * 24 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "claimed", "old"), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "open", "torn.json"), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "open", "torn.json"), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := o.Reclaim(now); err != nil || len(got) != 0 {
+		t.Fatalf("Reclaim = %+v, %v", got, err)
+	}
+	for _, p := range []string{filepath.Join("claimed", "old"), filepath.Join("open", "torn.json")} {
+		if _, err := os.Stat(filepath.Join(dir, p)); !os.IsNotExist(err) {
+			t.Errorf("%s survived pruning: %v", p, err)
+		}
+	}
+	if ok, err := o.Claim(DispatchClaim{ID: "old", Claimed: now}); err != nil || !ok {
+		t.Errorf("an expired claim was not forgotten: %v, %v", ok, err)
+	}
+	if _, err := o.Claim(DispatchClaim{ID: "../escape"}); err == nil {
+		t.Error("a claim ID with a path separator was accepted")
+	}
+}
+
+func TestRuleEngine_DispatchLogged_UnwatchedEventsAreNotClaimed(t *testing.T) {
+	off := false
+	rules := []models.Rule{
+		{Name: "on-created", On: models.RuleTrigger{Event: "task.created"}, Run: models.RuleAction{Skill: "triage"}},
+		{Name: "parked", Enabled: &off, On: models.RuleTrigger{Event: "mcp.request"}, Run: models.RuleAction{Skill: "log"}},
+	}
+	dir := t.TempDir()
+	e := NewRuleEngine(&fakeRuleStore{set: models.RuleSet{Rules: rules}}, nil, &fakeRunner{}, nil, nil,
+		WithRuleOutbox(NewFileDispatchOutbox(dir)))
+	for i := 0; i < 50; i++ {
+		for _, typ := range []string{"agent.session_active", "mcp.request"} {
+			ev := LoggedEvent{ID: fmt.Sprintf("%s-%d", strings.ReplaceAll(typ, ".", "-"), i), Type: typ, Via: DispatchInline}
+			if fs, _, err := e.DispatchLogged(context.Background(), ev); err != nil || len(fs) != 0 {
+				t.Fatalf("DispatchLogged(%s) = %+v, %v", ev.ID, fs, err)
+			}
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("events no enabled rule triggers on wrote %d outbox entries", len(entries))
+	}
+}
+
+func TestFileDispatchOutbox_ClaimPrunesWithoutTheDrain(t *testing.T) {
+	dir := t.TempDir()
+	o := NewFileDispatchOutbox(dir)
+	if ok, err := o.Claim(DispatchClaim{ID: "old"}); err != nil || !ok {
+		t.Fatalf("Claim(old) = %v, %v", ok, err)
+	}
+	if err := o.Complete("old"); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-8 * 24 * time.Hour)
+	for _, p := range []string{filepath.Join("claimed", "old"), ".pruned"} {
+		if err := os.Chtimes(filepath.Join(dir, p), stale, stale); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ok, err := o.Claim(DispatchClaim{ID: "new"}); err != nil || !ok {
+		t.Fatalf("Claim(new) = %v, %v", ok, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "claimed", "old")); !os.IsNotExist(err) {
+		t.Errorf("an expired marker survived a later Claim: %v", err)
+	}
+}
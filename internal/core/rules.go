@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -16,6 +17,9 @@ import (
 //
 //	on <trigger> [if <graph condition>] run <action> → write <outputs>
 //
+// where the action may be a workflow of steps (ruleworkflow.go) and firings
+// may be keyed, debounced or throttled (ruleconcurrency.go).
+//
 // The engine decides WHEN a rule fires (a time schedule, or a matched event,
 // optionally guarded by a graph condition) and applies the rule's declared
 // OUTPUTS (artifacts + typed graph edges). WHAT a rule does is delegated to an
@@ -33,12 +37,32 @@ const (
 
 // Firing is the outcome of evaluating one rule against one trigger. It is a
 // value type so `adb schedule run/dispatch` can print (or JSON-emit) exactly
-// what happened without the engine knowing about output formats.
+// what happened without the engine knowing about output formats. Outputs
+// describes each edge/artifact output applied (or, in a dry run, that would
+// have been).
 type Firing struct {
-	Rule   string `json:"rule"`
-	Status string `json:"status"`
-	Reason string `json:"reason,omitempty"`
-	Output string `json:"output,omitempty"`
+	Rule    string    `json:"rule"`
+	Status  string    `json:"status"`
+	Reason  string    `json:"reason,omitempty"`
+	Output  string    `json:"output,omitempty"`
+	At      time.Time `json:"at"`
+	Event   string    `json:"event,omitempty"`
+	Entity  string    `json:"entity,omitempty"`
+	Outputs []string  `json:"outputs,omitempty"`
+	DryRun  bool      `json:"dry_run,omitempty"`
+	// Steps is each workflow step's result, for a rule with steps or
+	// on_failure.
+	Steps []StepResult `json:"steps,omitempty"`
+	// Coalesced is how many debounced events this firing stands for.
+	Coalesced int `json:"coalesced,omitempty"`
+}
+
+// RuleOccurrence is one past trigger a simulation replays: an event (Event and
+// Payload set) or, for a time rule, a due time (Event empty).
+type RuleOccurrence struct {
+	At      time.Time
+	Event   string
+	Payload map[string]string
 }
 
 // RuleStore persists the declarative rule set (automation/rules.yaml). It is the
@@ -73,6 +97,12 @@ type ArtifactWriter interface {
 	WriteArtifact(relPath, content string) error
 }
 
+// TaskLookup resolves the task a rule's condition expression reads as `task`.
+// app.go bridges it to the backlog; GetTask errors for an unknown id.
+type TaskLookup interface {
+	GetTask(id string) (*models.Task, error)
+}
+
 // RuleEngine evaluates declarative rules. Per the house convention the
 // constructor returns the interface so callers depend on behaviour.
 type RuleEngine interface {
@@ -89,6 +119,29 @@ type RuleEngine interface {
 	// whose optional condition holds, given the event's payload. Resilient: a
 	// single rule's failure becomes an error Firing, never an aborted batch.
 	Dispatch(ctx context.Context, evtType string, payload map[string]string) ([]Firing, error)
+	// DispatchLogged is Dispatch for an event from the event log. An event
+	// some enabled rule triggers on is claimed in the dispatch outbox first;
+	// one already claimed — inline when it was logged, or by an earlier drain
+	// — is not dispatched again, and claimed is false. The claim is completed
+	// once the event's rules have fired; until then RetryDispatches can
+	// dispatch it again. An event no enabled rule triggers on is neither
+	// claimed nor recorded; claimed is true, with no firings.
+	DispatchLogged(ctx context.Context, ev LoggedEvent) (firings []Firing, claimed bool, err error)
+	// RetryDispatches dispatches the outbox claims left open: the rules
+	// DeferExec deferred, and events whose dispatch never completed (a
+	// crash). The automation-dispatch job calls it on each drain.
+	RetryDispatches(ctx context.Context) ([]Firing, error)
+	// Simulate evaluates one rule, enabled or not, against past occurrences in
+	// dry-run mode: conditions and templates are evaluated, but no action runs
+	// and no output is written. It returns one Firing per occurrence matching
+	// the rule's trigger, in order.
+	Simulate(ctx context.Context, name string, occurrences []RuleOccurrence) ([]Firing, error)
+	// History reads the firing ledger, newest first. Empty without a ledger.
+	History(filter FiringFilter) ([]FiringRecord, error)
+	// FlushDebounced fires every debounced firing whose quiet period has
+	// passed, once each with the latest event it held. The automation-dispatch
+	// job calls it after each drain.
+	FlushDebounced(ctx context.Context) ([]Firing, error)
 }
 
 type ruleEngine struct {
@@ -97,7 +150,15 @@ type ruleEngine struct {
 	runner    ActionRunner
 	edges     EdgeWriter
 	artifacts ArtifactWriter
+	tasks     TaskLookup
+	ledger    FiringLedger
+	conc      ConcurrencyStore
+	outbox    DispatchOutbox
 	now       func() time.Time
+	sleep     func(context.Context, time.Duration) error
+
+	mu      sync.Mutex      // guards running
+	running map[string]bool // "<rule>\x00<key>" of keyed firings in flight
 }
 
 // RuleEngineOption customises a RuleEngine.
@@ -113,6 +174,40 @@ func WithRuleClock(now func() time.Time) RuleEngineOption {
 	}
 }
 
+// WithRuleTasks injects the task lookup condition expressions resolve `task`
+// through. Without one, `task` is always null.
+func WithRuleTasks(tasks TaskLookup) RuleEngineOption {
+	return func(e *ruleEngine) { e.tasks = tasks }
+}
+
+// WithRuleLedger records every FireByName and Dispatch firing (other than a
+// disabled rule's skip) in ledger. Recording is best-effort: a ledger write
+// failure never fails the firing.
+func WithRuleLedger(ledger FiringLedger) RuleEngineOption {
+	return func(e *ruleEngine) { e.ledger = ledger }
+}
+
+// WithRuleConcurrencyStore persists debounce/throttle state in store so it
+// survives a restart. Defaults to process memory.
+func WithRuleConcurrencyStore(store ConcurrencyStore) RuleEngineOption {
+	return func(e *ruleEngine) {
+		if store != nil {
+			e.conc = store
+		}
+	}
+}
+
+// WithRuleOutbox shares DispatchLogged's claims through outbox, so every
+// process dispatching from the same event log fires an event's rules once.
+// Defaults to process memory.
+func WithRuleOutbox(outbox DispatchOutbox) RuleEngineOption {
+	return func(e *ruleEngine) {
+		if outbox != nil {
+			e.outbox = outbox
+		}
+	}
+}
+
 // NewRuleEngine wires the declarative rule engine. graph may be nil (rules with
 // a condition then skip); edges may be nil (edge outputs then error); artifacts
 // may be nil (artifact outputs then error). runner and store are required.
@@ -123,7 +218,11 @@ func NewRuleEngine(store RuleStore, graph GraphManager, runner ActionRunner, edg
 		runner:    runner,
 		edges:     edges,
 		artifacts: artifacts,
+		conc:      &memConcurrencyStore{},
+		outbox:    &memDispatchOutbox{},
 		now:       func() time.Time { return time.Now().UTC() },
+		sleep:     sleepContext,
+		running:   make(map[string]bool),
 	}
 	for _, o := range opts {
 		o(e)
@@ -160,7 +259,7 @@ func (e *ruleEngine) FireByName(ctx context.Context, name string, payload map[st
 	}
 	for _, r := range rs.Rules {
 		if r.Name == name {
-			return e.fire(ctx, r, payload), nil
+			return e.record(e.fire(ctx, r, firingInput{payload: payload, at: e.now()}), payload), nil
 		}
 	}
 	return Firing{}, fmt.Errorf("no rule named %q", name)
@@ -171,28 +270,247 @@ func (e *ruleEngine) Dispatch(ctx context.Context, evtType string, payload map[s
 	if err != nil {
 		return nil, err
 	}
+	out, _ := e.dispatch(ctx, rs.Rules, evtType, payload, nil, false)
+	return out, nil
+}
+
+// dispatch fires the event rules matching evtType, limited to only when it
+// is non-nil. With deferExec, the enabled rules that run an exec command are
+// not fired but returned by name, for the drain to fire.
+func (e *ruleEngine) dispatch(ctx context.Context, rules []models.Rule, evtType string, payload map[string]string, only map[string]bool, deferExec bool) ([]Firing, []string) {
+	var (
+		out      []Firing
+		deferred []string
+	)
+	for _, r := range rules {
+		if !r.On.IsEvent() || r.On.Event != evtType || (only != nil && !only[r.Name]) {
+			continue
+		}
+		if deferExec && r.IsEnabled() && r.RunsExec() {
+			deferred = append(deferred, r.Name)
+			continue
+		}
+		in := firingInput{event: evtType, payload: payload, at: e.now(), debounce: true}
+		out = append(out, e.record(e.fire(ctx, r, in), payload))
+	}
+	return out, deferred
+}
+
+// listens reports whether an enabled event rule triggers on evtType.
+func listens(rules []models.Rule, evtType string) bool {
+	for _, r := range rules {
+		if r.On.IsEvent() && r.On.Event == evtType && r.IsEnabled() {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ruleEngine) DispatchLogged(ctx context.Context, ev LoggedEvent) ([]Firing, bool, error) {
+	rs, err := e.store.Load()
+	if err != nil {
+		return nil, false, err
+	}
+	if !listens(rs.Rules, ev.Type) {
+		// Nothing to fire, so nothing to claim: most logged events (sessions,
+		// MCP requests) have no rule, and must not grow the outbox.
+		return nil, true, nil
+	}
+	c := DispatchClaim{
+		ID: ev.ID, Event: ev.Type, At: ev.At, Payload: ev.Payload, Via: ev.Via,
+		Status: ClaimPending, Claimed: e.now(), Attempts: 1,
+	}
+	claimed, err := e.outbox.Claim(c)
+	if err != nil || !claimed {
+		return nil, false, err
+	}
+	firings, deferred := e.dispatch(ctx, rs.Rules, ev.Type, ev.Payload, nil, ev.DeferExec)
+	return firings, true, e.settle(c, deferred)
+}
+
+func (e *ruleEngine) RetryDispatches(ctx context.Context) ([]Firing, error) {
+	claims, err := e.outbox.Reclaim(e.now())
+	if err != nil {
+		return nil, err
+	}
+	if len(claims) == 0 {
+		return nil, nil
+	}
+	rs, err := e.store.Load()
+	if err != nil {
+		// The claims stay pending: the next drain retries them.
+		return nil, err
+	}
 	var out []Firing
+	for _, c := range claims {
+		var only map[string]bool
+		if len(c.Rules) > 0 {
+			only = make(map[string]bool, len(c.Rules))
+			for _, name := range c.Rules {
+				only[name] = true
+			}
+		}
+		firings, _ := e.dispatch(ctx, rs.Rules, c.Event, c.Payload, only, false)
+		out = append(out, firings...)
+		if err := e.settle(c, nil); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// settle completes a dispatched claim, or leaves it open with the rules that
+// were deferred.
+func (e *ruleEngine) settle(c DispatchClaim, deferred []string) error {
+	if len(deferred) == 0 {
+		return e.outbox.Complete(c.ID)
+	}
+	c.Status, c.Rules = ClaimDeferred, deferred
+	return e.outbox.Update(c)
+}
+
+func (e *ruleEngine) Simulate(ctx context.Context, name string, occurrences []RuleOccurrence) ([]Firing, error) {
+	rs, err := e.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rs.Rules {
+		if r.Name != name {
+			continue
+		}
+		// Debounce and throttle play out against simulation-only state on the
+		// occurrences' own clock: a held firing is released when the next
+		// occurrence comes after its due time, or at the end.
+		state := &ConcurrencyState{}
+		release := func(until time.Time, all bool) []Firing {
+			var out []Firing
+			for _, p := range state.takeDue(until) {
+				out = append(out, e.fire(ctx, r, firingInput{event: p.Event, payload: p.Payload, at: p.Due, dry: true, state: state, coalesced: p.Count}))
+			}
+			if all {
+				for _, p := range state.Pending {
+					out = append(out, e.fire(ctx, r, firingInput{event: p.Event, payload: p.Payload, at: p.Due, dry: true, state: state, coalesced: p.Count}))
+				}
+				state.Pending = nil
+			}
+			return out
+		}
+		var out []Firing
+		for _, o := range occurrences {
+			if (r.On.IsEvent() && o.Event != r.On.Event) || (r.On.IsSchedule() && o.Event != "") {
+				continue
+			}
+			out = append(out, release(o.At, false)...)
+			out = append(out, e.fire(ctx, r, firingInput{event: o.Event, payload: o.Payload, at: o.At, dry: true, debounce: true, state: state}))
+		}
+		return append(out, release(time.Time{}, true)...), nil
+	}
+	return nil, fmt.Errorf("no rule named %q", name)
+}
+
+func (e *ruleEngine) FlushDebounced(ctx context.Context) ([]Firing, error) {
+	now := e.now()
+	var due []PendingFiring
+	err := e.conc.Update(func(s *ConcurrencyState) error {
+		if due = s.releaseDue(now); len(due) == 0 {
+			return errStateUnchanged
+		}
+		return nil
+	})
+	if err != nil || len(due) == 0 {
+		return nil, err
+	}
+	rs, err := e.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[string]models.Rule, len(rs.Rules))
 	for _, r := range rs.Rules {
-		if r.On.IsEvent() && r.On.Event == evtType {
-			out = append(out, e.fire(ctx, r, payload))
+		rules[r.Name] = r
+	}
+	var out []Firing
+	for _, p := range due {
+		r, ok := rules[p.Rule]
+		var f Firing
+		if !ok {
+			f = e.record(skipped(p.Rule, "debounced rule no longer exists"), p.Payload)
+		} else {
+			in := firingInput{event: p.Event, payload: p.Payload, at: now, coalesced: p.Count}
+			f = e.record(e.fire(ctx, r, in), p.Payload)
+		}
+		out = append(out, f)
+		// Only a recorded firing lets go of its held events.
+		if err := e.conc.Update(func(s *ConcurrencyState) error { s.done(p); return nil }); err != nil {
+			return out, err
 		}
 	}
 	return out, nil
 }
 
-// fire evaluates one rule end-to-end: disabled/condition gate → action → outputs.
-// It never returns an error; failures surface as an error Firing so a batch
-// dispatch stays resilient.
-func (e *ruleEngine) fire(ctx context.Context, rule models.Rule, payload map[string]string) Firing {
-	if !rule.IsEnabled() {
-		return skipped(rule.Name, "rule disabled")
+func (e *ruleEngine) History(filter FiringFilter) ([]FiringRecord, error) {
+	if e.ledger == nil {
+		return nil, nil
+	}
+	return e.ledger.List(filter)
+}
+
+// record appends a live firing to the ledger and returns it unchanged. A
+// disabled rule's skip is not recorded, so a parked event rule does not fill
+// the ledger with one line per matching event.
+func (e *ruleEngine) record(f Firing, payload map[string]string) Firing {
+	if e.ledger == nil || (f.Status == FiringSkipped && f.Reason == reasonDisabled) {
+		return f
+	}
+	_ = e.ledger.Append(FiringRecord{Firing: f, Payload: payload})
+	return f
+}
+
+// firingInput is what one rule evaluation sees: the triggering event (empty
+// for a time or manual firing), its payload, when it happened, and whether
+// side effects are suppressed (a dry-run simulation). debounce marks a
+// dispatched event a debounced rule should hold; coalesced marks a held
+// firing being released, and how many events it stands for. state is the
+// concurrency state of a simulation (nil: the engine's store).
+type firingInput struct {
+	event     string
+	payload   map[string]string
+	at        time.Time
+	dry       bool
+	debounce  bool
+	coalesced int
+	state     *ConcurrencyState
+}
+
+const reasonDisabled = "rule disabled"
+
+// fire evaluates one rule and stamps the result with the trigger it ran for;
+// Entity falls back to the payload's task_id.
+func (e *ruleEngine) fire(ctx context.Context, rule models.Rule, in firingInput) Firing {
+	f := e.evaluate(ctx, rule, in)
+	f.At, f.Event, f.DryRun, f.Coalesced = in.at, in.event, in.dry, in.coalesced
+	if f.Entity == "" {
+		f.Entity = in.payload["task_id"]
+	}
+	return f
+}
+
+// evaluate runs one rule end-to-end: disabled/condition gate → concurrency
+// (debounce, throttle, one run per key at a time) → action → outputs. It never
+// returns an error; failures surface as an error Firing so a batch dispatch
+// stays resilient. A dry run ignores the enabled flag (the point is to tune a
+// rule before enabling it), describes the action instead of running it, and
+// resolves the outputs without writing them.
+func (e *ruleEngine) evaluate(ctx context.Context, rule models.Rule, in firingInput) Firing {
+	payload := in.payload
+	if !rule.IsEnabled() && !in.dry {
+		return skipped(rule.Name, reasonDisabled)
 	}
 
 	// Optional graph condition: the resolved entity must have an incident edge
 	// of the required type. An unresolved entity or an absent graph degrades to
 	// a skip (never blocks, never panics).
 	firingEntity := ""
-	if rule.If != nil {
+	if rule.If != nil && strings.TrimSpace(string(rule.If.HasEdge)) != "" {
 		entity, err := expandTemplate(rule.If.Entity, payload)
 		if err != nil || strings.TrimSpace(entity) == "" {
 			return skipped(rule.Name, fmt.Sprintf("condition entity %q unresolved", rule.If.Entity))
@@ -210,29 +528,167 @@ func (e *ruleEngine) fire(ctx context.Context, rule models.Rule, payload map[str
 		}
 	}
 
-	out, err := e.runAction(ctx, rule, payload)
+	// Optional condition expression, evaluated against the payload, the task
+	// the firing is about and the graph. A rules.yaml edited by hand may hold
+	// an expression that does not compile; that surfaces as an error firing
+	// naming the rule, like any other misconfiguration.
+	if rule.If != nil && strings.TrimSpace(rule.If.Expr) != "" {
+		held, taskID, err := e.evalCondition(rule, payload, in.at)
+		if err != nil {
+			return errored(rule.Name, fmt.Sprintf("if: %v", err))
+		}
+		if !held {
+			return skipped(rule.Name, fmt.Sprintf("condition not met: %s", rule.If.Expr))
+		}
+		if firingEntity == "" {
+			firingEntity = taskID
+		}
+	}
+
+	if rule.Concurrency != nil {
+		key, err := concurrencyKey(rule, payload)
+		if err != nil || strings.TrimSpace(key) == "" {
+			return skipped(rule.Name, fmt.Sprintf("concurrency key %q unresolved", rule.Concurrency.Key))
+		}
+		if ok, reason := e.admit(rule, key, in); !ok {
+			return skipped(rule.Name, reason)
+		}
+		if !in.dry {
+			if !e.acquire(rule.Name, key) {
+				return skipped(rule.Name, fmt.Sprintf("already running for %q", key))
+			}
+			defer e.release(rule.Name, key)
+		}
+	}
+
+	f := e.execute(ctx, rule, in, firingEntity)
+	f.Entity = firingEntity
+	return f
+}
+
+// execute runs the rule's action (or workflow steps) and, when that
+// succeeds, its outputs. A failure runs the on_failure steps and errors the
+// firing with the original failure.
+func (e *ruleEngine) execute(ctx context.Context, rule models.Rule, in firingInput, firingEntity string) Firing {
+	data := newWorkflowData(in.payload)
+	steps, out, err := e.runSteps(ctx, rule, rule.Workflow(), data, in.payload, in.dry, false)
+	var ops []resolvedOutput
+	if err == nil {
+		ops, err = e.resolveOutputs(rule, data, firingEntity)
+	}
+	if err == nil && !in.dry {
+		err = e.applyOutputs(ops)
+	}
+	workflow := len(rule.Steps) > 0 || len(rule.OnFailure) > 0
+	if err != nil {
+		failedStep := "" // empty when an output, not a step, failed
+		if n := len(steps); n > 0 && steps[n-1].Status == FiringError {
+			failedStep = steps[n-1].Name
+		}
+		reason := err.Error()
+		if len(rule.Steps) > 0 && failedStep != "" {
+			reason = fmt.Sprintf("step %q: %s", failedStep, reason)
+		}
+		f := errored(rule.Name, reason)
+		if len(rule.OnFailure) > 0 {
+			data["error"], data["failed_step"] = err.Error(), failedStep
+			recovery, _, ferr := e.runSteps(ctx, rule, rule.FailureSteps(), data, in.payload, in.dry, true)
+			steps = append(steps, recovery...)
+			if ferr != nil {
+				f.Reason += fmt.Sprintf("; on_failure step %q: %v", recovery[len(recovery)-1].Name, ferr)
+			}
+		}
+		if workflow {
+			f.Steps = steps
+		}
+		return f
+	}
+	f := Firing{Rule: rule.Name, Status: FiringFired, Output: out}
+	for _, op := range ops {
+		f.Outputs = append(f.Outputs, op.describe()...)
+	}
+	if workflow {
+		f.Steps = steps
+	}
+	return f
+}
+
+// admit applies the rule's debounce/throttle to one firing, against the
+// simulation's state or else the engine's store. A store that cannot be read
+// admits the firing: losing automation is worse than a duplicate run.
+func (e *ruleEngine) admit(rule models.Rule, key string, in firingInput) (bool, string) {
+	debounce := in.debounce && in.coalesced == 0
+	if in.state != nil {
+		return in.state.admit(rule, key, in.event, in.payload, in.at, debounce)
+	}
+	ok, reason := true, ""
+	err := e.conc.Update(func(s *ConcurrencyState) error {
+		ok, reason = s.admit(rule, key, in.event, in.payload, in.at, debounce)
+		return nil
+	})
 	if err != nil {
-		return errored(rule.Name, err.Error())
+		return true, ""
 	}
-	if err := e.applyOutputs(rule, payload, firingEntity); err != nil {
-		return errored(rule.Name, err.Error())
+	return ok, reason
+}
+
+// acquire marks a keyed firing in flight, refusing a second one for the same
+// rule and key until release.
+func (e *ruleEngine) acquire(rule, key string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	k := rule + "\x00" + key
+	if e.running[k] {
+		return false
 	}
-	return Firing{Rule: rule.Name, Status: FiringFired, Output: out}
+	e.running[k] = true
+	return true
 }
 
-func (e *ruleEngine) runAction(ctx context.Context, rule models.Rule, payload map[string]string) (string, error) {
-	if strings.TrimSpace(rule.Run.Skill) != "" {
-		return e.runner.RecordSkillRequest(rule.Name, rule.Run.Skill, payload)
+func (e *ruleEngine) release(rule, key string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.running, rule+"\x00"+key)
+}
+
+// evalCondition compiles and evaluates rule.If.Expr as of now. The condition's
+// task is rule.If.Entity (templated) when set, else the payload's task_id; one
+// that does not resolve, or that the lookup does not know, reads as null. It
+// returns the task id alongside the verdict so edge outputs can default to it.
+func (e *ruleEngine) evalCondition(rule models.Rule, payload map[string]string, now time.Time) (bool, string, error) {
+	expr, err := models.CompileRuleExpr(rule.If.Expr)
+	if err != nil {
+		return false, "", err
 	}
-	args := make([]string, len(rule.Run.Exec))
-	for i, a := range rule.Run.Exec {
-		ex, err := expandTemplate(a, payload)
-		if err != nil {
-			return "", fmt.Errorf("expand exec arg %d: %w", i, err)
+	taskID := payload["task_id"]
+	if strings.TrimSpace(rule.If.Entity) != "" {
+		taskID, _ = expandTemplate(rule.If.Entity, payload)
+	}
+	env := models.RuleExprEnv{Event: rule.On.Event, Payload: payload, Now: now}
+	if taskID = strings.TrimSpace(taskID); taskID != "" && e.tasks != nil {
+		if task, err := e.tasks.GetTask(taskID); err == nil {
+			env.Task = task
 		}
-		args[i] = ex
 	}
-	return e.runner.RunExec(ctx, args)
+	if e.graph != nil {
+		env.Neighbors = func(entity string, t models.EdgeType) ([]string, error) {
+			edges, err := e.graph.NeighborsByType(entity, t)
+			if err != nil {
+				return nil, err
+			}
+			ids := make([]string, 0, len(edges))
+			for _, edge := range edges {
+				if edge.From == entity {
+					ids = append(ids, edge.To)
+				} else {
+					ids = append(ids, edge.From)
+				}
+			}
+			return ids, nil
+		}
+	}
+	held, err := expr.Eval(env)
+	return held, taskID, err
 }
 
 // resolvedOutput is one output with every template already expanded, so the
@@ -244,51 +700,68 @@ type resolvedOutput struct {
 	link         *models.Link // edge to add (valid iff edgeFrom != "")
 }
 
-func (e *ruleEngine) applyOutputs(rule models.Rule, payload map[string]string, firingEntity string) error {
-	// RESOLVE every output's templates first, so a template/wiring error cannot
-	// leave a partially-applied set (e.g. an artifact written before a later edge
-	// fails to expand). Only once all resolve do we perform side effects.
+// describe renders the output for a Firing's Outputs list.
+func (op resolvedOutput) describe() []string {
+	var out []string
+	if op.artifactPath != "" {
+		out = append(out, "artifact "+op.artifactPath)
+	}
+	if op.link != nil {
+		out = append(out, fmt.Sprintf("edge %s --%s--> %s", op.edgeFrom, op.link.Type, op.link.Target))
+	}
+	return out
+}
+
+// resolveOutputs expands every output's templates before any side effect, so
+// a template/wiring error cannot leave a partially-applied set (e.g. an
+// artifact written before a later edge fails to expand). A dry run stops
+// here; a live firing hands the result to applyOutputs.
+func (e *ruleEngine) resolveOutputs(rule models.Rule, data workflowData, firingEntity string) ([]resolvedOutput, error) {
 	ops := make([]resolvedOutput, 0, len(rule.Write))
 	for i, o := range rule.Write {
 		var op resolvedOutput
 		if strings.TrimSpace(o.Artifact) != "" {
-			path, err := expandTemplate(o.Artifact, payload)
+			path, err := expandTemplate(o.Artifact, data)
 			if err != nil {
-				return fmt.Errorf("output %d: expand artifact path: %w", i, err)
+				return nil, fmt.Errorf("output %d: expand artifact path: %w", i, err)
 			}
 			if e.artifacts == nil {
-				return fmt.Errorf("output %d: artifact requested but no artifact writer wired", i)
+				return nil, fmt.Errorf("output %d: artifact requested but no artifact writer wired", i)
 			}
 			op.artifactPath = path
-			op.content = e.artifactContent(rule, payload)
+			op.content = e.artifactContent(rule, data)
 		}
 		if o.Edge != nil {
 			from := strings.TrimSpace(o.EdgeFrom)
 			if from == "" {
 				from = firingEntity
 			}
-			from, err := expandTemplate(from, payload)
+			from, err := expandTemplate(from, data)
 			if err != nil {
-				return fmt.Errorf("output %d: expand edge_from: %w", i, err)
+				return nil, fmt.Errorf("output %d: expand edge_from: %w", i, err)
 			}
 			if strings.TrimSpace(from) == "" {
-				return fmt.Errorf("output %d: edge needs edge_from (no condition entity to default to)", i)
+				return nil, fmt.Errorf("output %d: edge needs edge_from (no condition entity to default to)", i)
 			}
-			target, err := expandTemplate(o.Edge.Target, payload)
+			target, err := expandTemplate(o.Edge.Target, data)
 			if err != nil {
-				return fmt.Errorf("output %d: expand edge target: %w", i, err)
+				return nil, fmt.Errorf("output %d: expand edge target: %w", i, err)
 			}
 			if e.edges == nil {
-				return fmt.Errorf("output %d: edge requested but no edge writer wired", i)
+				return nil, fmt.Errorf("output %d: edge requested but no edge writer wired", i)
 			}
 			op.edgeFrom = from
 			op.link = &models.Link{Type: o.Edge.Type, Target: target}
 		}
 		ops = append(ops, op)
 	}
+	return ops, nil
+}
 
-	// APPLY. Writes are individually idempotent (artifact overwrite, edge dedup),
-	// so a rule that re-fires never accretes state.
+// applyOutputs performs the resolved outputs. Writes are individually
+// idempotent (artifact overwrite, edge dedup), so a rule that re-fires never
+// accretes state.
+func (e *ruleEngine) applyOutputs(ops []resolvedOutput) error {
 	for i, op := range ops {
 		if op.artifactPath != "" {
 			if err := e.artifacts.WriteArtifact(op.artifactPath, op.content); err != nil {
@@ -308,7 +781,7 @@ func (e *ruleEngine) applyOutputs(rule models.Rule, payload map[string]string, f
 // records the rule, the fire time, the action, and the payload so a derived
 // artifact traces back to what produced it (the D8 provenance discipline applied
 // to rule outputs).
-func (e *ruleEngine) artifactContent(rule models.Rule, payload map[string]string) string {
+func (e *ruleEngine) artifactContent(rule models.Rule, data workflowData) string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "# Automation firing: %s\n\n", rule.Name)
 	fmt.Fprintf(&b, "- rule: %s\n", rule.Name)
@@ -318,15 +791,29 @@ func (e *ruleEngine) artifactContent(rule models.Rule, payload map[string]string
 	} else {
 		fmt.Fprintf(&b, "- trigger: event %s\n", rule.On.Event)
 	}
-	if strings.TrimSpace(rule.Run.Skill) != "" {
+	switch {
+	case len(rule.Steps) > 0:
+		names := make([]string, 0, len(rule.Steps))
+		for _, st := range rule.Workflow() {
+			names = append(names, st.Name)
+		}
+		fmt.Fprintf(&b, "- action: steps %s\n", strings.Join(names, ", "))
+	case strings.TrimSpace(rule.Run.Skill) != "":
 		fmt.Fprintf(&b, "- action: skill %s\n", rule.Run.Skill)
-	} else {
+	default:
 		fmt.Fprintf(&b, "- action: exec %s\n", strings.Join(rule.Run.Exec, " "))
 	}
-	if len(payload) > 0 {
+	var keys []string
+	for k, v := range data {
+		if _, ok := v.(string); ok {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) > 0 {
+		sort.Strings(keys)
 		b.WriteString("- payload:\n")
-		for _, k := range sortedKeys(payload) {
-			fmt.Fprintf(&b, "  - %s: %s\n", k, payload[k])
+		for _, k := range keys {
+			fmt.Fprintf(&b, "  - %s: %s\n", k, data[k])
 		}
 	}
 	return b.String()
@@ -354,7 +841,7 @@ func hasEdgeType(edges []models.GraphEdge, t models.EdgeType) bool {
 // key the payload lacks is an error (missingkey=error) so a rule that needs a
 // field an event doesn't carry skips cleanly rather than writing a half-rendered
 // path.
-func expandTemplate(tmpl string, payload map[string]string) (string, error) {
+func expandTemplate(tmpl string, data interface{}) (string, error) {
 	if !strings.Contains(tmpl, "{{") {
 		return tmpl, nil
 	}
@@ -363,17 +850,8 @@ func expandTemplate(tmpl string, payload map[string]string) (string, error) {
 		return "", fmt.Errorf("parse template %q: %w", tmpl, err)
 	}
 	var buf bytes.Buffer
-	if err := t.Execute(&buf, payload); err != nil {
+	if err := t.Execute(&buf, data); err != nil {
 		return "", fmt.Errorf("expand %q: %w", tmpl, err)
 	}
 	return buf.String(), nil
 }
-
-func sortedKeys(m map[string]string) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	return keys
-}
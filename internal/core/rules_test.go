@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -211,6 +212,69 @@ func TestRuleEngine_Condition_NoGraphSkips(t *testing.T) {
 	}
 }
 
+type fakeTaskLookup map[string]*models.Task
+
+func (f fakeTaskLookup) GetTask(id string) (*models.Task, error) {
+	if t, ok := f[id]; ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("task %s not found", id)
+}
+
+func TestRuleEngine_Condition_Expression(t *testing.T) {
+	graph := &fakeGraph{neighbors: map[string][]models.GraphEdge{
+		"TASK-2": {{From: "TASK-2", Type: models.EdgeDependsOn, To: "TASK-9"}},
+	}}
+	tasks := fakeTaskLookup{
+		"TASK-1": {ID: "TASK-1", Priority: models.PriorityP0},
+		"TASK-2": {ID: "TASK-2", Priority: models.PriorityP0},
+		"TASK-3": {ID: "TASK-3", Priority: models.PriorityP2},
+	}
+	set := models.RuleSet{Rules: []models.Rule{{
+		Name:  "p0-blocked",
+		On:    models.RuleTrigger{Event: "task.status_changed"},
+		If:    &models.RuleCondition{Expr: `task.priority == "P0" && payload.new_status == "blocked" && !has_edge(task, "depends_on")`},
+		Run:   models.RuleAction{Skill: "escalate"},
+		Write: []models.RuleOutput{{Edge: &models.Link{Type: models.EdgeRelatesTo, Target: "INIT-1"}}},
+	}}}
+	runner := &fakeRunner{}
+	edges := &fakeEdgeWriter{}
+	eng := NewRuleEngine(&fakeRuleStore{set: set}, graph, runner, edges, &fakeArtifactWriter{}, WithRuleTasks(tasks))
+
+	dispatch := func(payload map[string]string) Firing {
+		t.Helper()
+		f, err := eng.Dispatch(context.Background(), "task.status_changed", payload)
+		if err != nil || len(f) != 1 {
+			t.Fatalf("Dispatch(%v) = %+v, %v", payload, f, err)
+		}
+		return f[0]
+	}
+	if f := dispatch(map[string]string{"task_id": "TASK-1", "new_status": "blocked"}); f.Status != FiringFired {
+		t.Errorf("P0 blocked without deps = %+v, want fired", f)
+	}
+	// The edge output defaults to the condition's task.
+	if len(edges.edges) != 1 || edges.edges[0].from != "TASK-1" {
+		t.Errorf("edges = %+v, want one from TASK-1", edges.edges)
+	}
+	for _, payload := range []map[string]string{
+		{"task_id": "TASK-2", "new_status": "blocked"},     // has a depends_on edge
+		{"task_id": "TASK-3", "new_status": "blocked"},     // not P0
+		{"task_id": "TASK-1", "new_status": "in_progress"}, // not blocked
+		{"task_id": "TASK-404", "new_status": "blocked"},   // unknown task reads as null
+	} {
+		if f := dispatch(payload); f.Status != FiringSkipped || !strings.Contains(f.Reason, "condition not met") {
+			t.Errorf("%v = %+v, want skipped", payload, f)
+		}
+	}
+
+	// A hand-edited expression that does not compile errors, naming the problem.
+	set.Rules[0].If.Expr = `task.prio == "P0"`
+	eng = NewRuleEngine(&fakeRuleStore{set: set}, graph, runner, edges, &fakeArtifactWriter{}, WithRuleTasks(tasks))
+	if f := dispatch(map[string]string{"task_id": "TASK-1"}); f.Status != FiringError || !strings.Contains(f.Reason, `unknown task field "prio"`) {
+		t.Errorf("bad expression = %+v, want an error firing", f)
+	}
+}
+
 func TestRuleEngine_Outputs_EdgeAndArtifact(t *testing.T) {
 	set := models.RuleSet{Rules: []models.Rule{
 		{
@@ -267,3 +331,111 @@ func TestRuleEngine_UnresolvedTemplateSkips(t *testing.T) {
 		t.Fatalf("firing = %+v, want skipped (unresolved template)", f)
 	}
 }
+
+type fakeLedger struct {
+	records []FiringRecord
+}
+
+func (f *fakeLedger) Append(r FiringRecord) error { f.records = append(f.records, r); return nil }
+func (f *fakeLedger) List(filter FiringFilter) ([]FiringRecord, error) {
+	var out []FiringRecord
+	for i := len(f.records) - 1; i >= 0; i-- {
+		if filter.match(f.records[i]) {
+			out = append(out, f.records[i])
+		}
+	}
+	return out, nil
+}
+
+func TestRuleEngine_LedgerRecordsFirings(t *testing.T) {
+	off := false
+	set := models.RuleSet{Rules: []models.Rule{
+		{Name: "on-status", On: models.RuleTrigger{Event: "task.status_changed"}, Run: models.RuleAction{Skill: "triage"}},
+		{Name: "parked", Enabled: &off, On: models.RuleTrigger{Event: "task.status_changed"}, Run: models.RuleAction{Skill: "s"}},
+		{Name: "nightly", On: models.RuleTrigger{Schedule: "24h"}, Run: models.RuleAction{Exec: []string{"true"}}},
+	}}
+	at := time.Date(2026, 7, 7, 12, 0, 0, 0, time.UTC)
+	ledger := &fakeLedger{}
+	eng := NewRuleEngine(&fakeRuleStore{set: set}, nil, &fakeRunner{}, nil, nil,
+		WithRuleClock(func() time.Time { return at }), WithRuleLedger(ledger))
+
+	if _, err := eng.Dispatch(context.Background(), "task.status_changed", map[string]string{"task_id": "TASK-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eng.FireByName(context.Background(), "nightly", nil); err != nil {
+		t.Fatal(err)
+	}
+	// The parked rule's skip is not recorded.
+	if len(ledger.records) != 2 {
+		t.Fatalf("ledger = %+v, want 2 records", ledger.records)
+	}
+	got := ledger.records[0]
+	if got.Rule != "on-status" || got.Status != FiringFired || got.Event != "task.status_changed" ||
+		got.Entity != "TASK-1" || !got.At.Equal(at) || got.Payload["task_id"] != "TASK-1" {
+		t.Errorf("dispatch record = %+v", got)
+	}
+	if got := ledger.records[1]; got.Rule != "nightly" || got.Event != "" || got.Output != "exec-ran" {
+		t.Errorf("fire-by-name record = %+v", got)
+	}
+
+	hist, err := eng.History(FiringFilter{Rule: "nightly"})
+	if err != nil || len(hist) != 1 || hist[0].Rule != "nightly" {
+		t.Errorf("History(nightly) = %+v, %v", hist, err)
+	}
+	if hist, err := NewRuleEngine(&fakeRuleStore{}, nil, &fakeRunner{}, nil, nil).History(FiringFilter{}); err != nil || hist != nil {
+		t.Errorf("History without a ledger = %+v, %v; want empty", hist, err)
+	}
+}
+
+func TestRuleEngine_Simulate_DryRun(t *testing.T) {
+	off := false
+	graph := &fakeGraph{neighbors: map[string][]models.GraphEdge{
+		"TASK-1": {{From: "TASK-1", Type: models.EdgeDependsOn, To: "TASK-9"}},
+	}}
+	set := models.RuleSet{Rules: []models.Rule{{
+		Name:    "flag-blocked",
+		Enabled: &off, // simulation is for rules not yet enabled
+		On:      models.RuleTrigger{Event: "task.status_changed"},
+		If:      &models.RuleCondition{Entity: "{{.task_id}}", HasEdge: models.EdgeDependsOn},
+		Run:     models.RuleAction{Exec: []string{"notify", "{{.task_id}}"}},
+		Write: []models.RuleOutput{
+			{Artifact: "reports/{{.task_id}}.md"},
+			{Edge: &models.Link{Type: models.EdgeRelatesTo, Target: "INIT-1"}},
+		},
+	}}}
+	runner, edges, artifacts, ledger := &fakeRunner{}, &fakeEdgeWriter{}, &fakeArtifactWriter{}, &fakeLedger{}
+	eng := NewRuleEngine(&fakeRuleStore{set: set}, graph, runner, edges, artifacts, WithRuleLedger(ledger))
+
+	t1 := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	firings, err := eng.Simulate(context.Background(), "flag-blocked", []RuleOccurrence{
+		{At: t1, Event: "task.status_changed", Payload: map[string]string{"task_id": "TASK-1"}},
+		{At: t1.Add(time.Hour), Event: "task.created", Payload: map[string]string{"task_id": "TASK-1"}}, // other trigger: ignored
+		{At: t1.Add(2 * time.Hour), Event: "task.status_changed", Payload: map[string]string{"task_id": "TASK-2"}},
+		{At: t1.Add(3 * time.Hour), Event: "task.status_changed", Payload: map[string]string{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(firings) != 3 {
+		t.Fatalf("firings = %+v, want 3", firings)
+	}
+	f := firings[0]
+	if f.Status != FiringFired || !f.DryRun || !f.At.Equal(t1) || f.Entity != "TASK-1" || f.Output != "would run: notify TASK-1" {
+		t.Errorf("firing[0] = %+v", f)
+	}
+	wantOutputs := []string{"artifact reports/TASK-1.md", "edge TASK-1 --relates_to--> INIT-1"}
+	if strings.Join(f.Outputs, "|") != strings.Join(wantOutputs, "|") {
+		t.Errorf("outputs = %q, want %q", f.Outputs, wantOutputs)
+	}
+	if firings[1].Status != FiringSkipped || firings[2].Status != FiringSkipped {
+		t.Errorf("unmet condition / unresolved entity = %+v, %+v; want skipped", firings[1], firings[2])
+	}
+	if len(runner.execs)+len(runner.skills)+len(edges.edges)+len(artifacts.artifacts)+len(ledger.records) != 0 {
+		t.Errorf("simulation had side effects: runner=%+v edges=%+v artifacts=%+v ledger=%+v",
+			runner, edges.edges, artifacts.artifacts, ledger.records)
+	}
+
+	if _, err := eng.Simulate(context.Background(), "ghost", nil); err == nil {
+		t.Error("Simulate of an unknown rule should error")
+	}
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// StepResult is how one workflow step went. A Firing carries one per step
+// that ran (on_failure steps flagged) when the rule declares steps or
+// on_failure.
+type StepResult struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"` // fired | error
+	Attempts  int    `json:"attempts"`
+	Output    string `json:"output,omitempty"`
+	Error     string `json:"error,omitempty"`
+	OnFailure bool   `json:"on_failure,omitempty"`
+}
+
+// workflowData is the template data a rule's steps and outputs expand against:
+// the payload's keys, "steps" (each finished step's output and status, read as
+// {{.steps.<name>.output}}) and, for on_failure steps, "error" and
+// "failed_step".
+type workflowData map[string]interface{}
+
+func newWorkflowData(payload map[string]string) workflowData {
+	d := make(workflowData, len(payload)+1)
+	for k, v := range payload {
+		d[k] = v
+	}
+	d["steps"] = map[string]interface{}{}
+	return d
+}
+
+// recordStep makes a finished step readable by later templates. A dry run
+// has no real output, so a placeholder stands in and the templates still
+// resolve.
+func (d workflowData) recordStep(r StepResult, dry bool) {
+	out := r.Output
+	if dry {
+		out = fmt.Sprintf("<output of %s>", r.Name)
+	}
+	d["steps"].(map[string]interface{})[r.Name] = map[string]string{"output": out, "status": r.Status}
+}
+
+// runSteps runs steps in order, stopping at the first that fails after its
+// retries. It returns every step's result and the last step's output; the
+// error names the failing step.
+func (e *ruleEngine) runSteps(ctx context.Context, rule models.Rule, steps []models.RuleStep, data workflowData, payload map[string]string, dry, onFailure bool) ([]StepResult, string, error) {
+	var (
+		results []StepResult
+		last    string
+	)
+	for _, st := range steps {
+		res, err := e.runStep(ctx, rule, st, data, payload, dry)
+		res.OnFailure = onFailure
+		results = append(results, res)
+		data.recordStep(res, dry)
+		if err != nil {
+			return results, last, err
+		}
+		last = res.Output
+	}
+	return results, last, nil
+}
+
+// runStep runs one step with its timeout and retries; a dry run describes the
+// step once. Template errors are not retried: they would fail the same way
+// again.
+func (e *ruleEngine) runStep(ctx context.Context, rule models.Rule, st models.RuleStep, data workflowData, payload map[string]string, dry bool) (StepResult, error) {
+	res := StepResult{Name: st.Name}
+	fail := func(err error) (StepResult, error) {
+		res.Status, res.Error = FiringError, err.Error()
+		return res, err
+	}
+	timeout, err := st.TimeoutDuration()
+	if err != nil {
+		return fail(err)
+	}
+	backoff, err := st.BackoffDuration()
+	if err != nil {
+		return fail(err)
+	}
+	for {
+		res.Attempts++
+		out, retryable, err := e.attemptStep(ctx, rule, st, data, payload, timeout, dry)
+		if err == nil {
+			res.Status, res.Output = FiringFired, strings.TrimSpace(out)
+			return res, nil
+		}
+		if dry || !retryable || res.Attempts > st.Retries || ctx.Err() != nil {
+			res.Output = strings.TrimSpace(out)
+			return fail(err)
+		}
+		if serr := e.sleep(ctx, backoff); serr != nil {
+			return fail(err)
+		}
+		backoff *= 2
+	}
+}
+
+// attemptStep runs a step's action once. In a dry run it expands the action
+// exactly as a real attempt would and says what would happen.
+func (e *ruleEngine) attemptStep(ctx context.Context, rule models.Rule, st models.RuleStep, data workflowData, payload map[string]string, timeout time.Duration, dry bool) (string, bool, error) {
+	if strings.TrimSpace(st.Skill) != "" {
+		p := payload
+		if len(st.With) > 0 {
+			p = make(map[string]string, len(payload)+len(st.With))
+			for k, v := range payload {
+				p[k] = v
+			}
+			for k, v := range st.With {
+				ex, err := expandTemplate(v, data)
+				if err != nil {
+					return "", false, fmt.Errorf("expand with.%s: %w", k, err)
+				}
+				p[k] = ex
+			}
+		}
+		if dry {
+			return fmt.Sprintf("would record skill request for %q", st.Skill), false, nil
+		}
+		out, err := e.runner.RecordSkillRequest(rule.Name, st.Skill, p)
+		return out, true, err
+	}
+	args := make([]string, len(st.Exec))
+	for i, a := range st.Exec {
+		ex, err := expandTemplate(a, data)
+		if err != nil {
+			return "", false, fmt.Errorf("expand exec arg %d: %w", i, err)
+		}
+		args[i] = ex
+	}
+	if dry {
+		return "would run: " + strings.Join(args, " "), false, nil
+	}
+	runCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	out, err := e.runner.RunExec(runCtx, args)
+	if err != nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	return out, true, err
+}
+
+// sleepContext waits d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// scriptedRunner answers each exec by its command name, counting calls.
+type scriptedRunner struct {
+	fakeRunner
+	exec  func(ctx context.Context, args []string, call int) (string, error)
+	calls map[string]int
+}
+
+func (s *scriptedRunner) RunExec(ctx context.Context, args []string) (string, error) {
+	s.execs = append(s.execs, recordedExec{args: args})
+	if s.calls == nil {
+		s.calls = make(map[string]int)
+	}
+	s.calls[args[0]]++
+	return s.exec(ctx, args, s.calls[args[0]])
+}
+
+func newWorkflowEngine(rule models.Rule, runner ActionRunner) (*ruleEngine, *[]time.Duration) {
+	eng := NewRuleEngine(&fakeRuleStore{set: models.RuleSet{Rules: []models.Rule{rule}}}, nil, runner, nil, &fakeArtifactWriter{}).(*ruleEngine)
+	var slept []time.Duration
+	eng.sleep = func(_ context.Context, d time.Duration) error { slept = append(slept, d); return nil }
+	return eng, &slept
+}
+
+func TestRuleEngine_Workflow_StepOutputsTemplateForward(t *testing.T) {
+	rule := models.Rule{
+		Name: "digest",
+		On:   models.RuleTrigger{Event: "task.status_changed"},
+		Steps: []models.RuleStep{
+			{Name: "collect", RuleAction: models.RuleAction{Exec: []string{"collect", "{{.task_id}}"}}},
+			{Name: "post", RuleAction: models.RuleAction{Exec: []string{"post", "{{.steps.collect.output}}"}}},
+			{RuleAction: models.RuleAction{Skill: "summarise"}, With: map[string]string{"posted": "{{.steps.post.output}}"}},
+		},
+		Write: []models.RuleOutput{{Artifact: "digests/{{.task_id}}.md"}},
+	}
+	runner := &scriptedRunner{exec: func(_ context.Context, args []string, _ int) (string, error) {
+		return strings.ToUpper(strings.Join(args, " ")) + "\n", nil
+	}}
+	eng, _ := newWorkflowEngine(rule, runner)
+
+	f := eng.fire(context.Background(), rule, firingInput{payload: map[string]string{"task_id": "TASK-1"}, at: eng.now()})
+	if f.Status != FiringFired {
+		t.Fatalf("firing = %+v", f)
+	}
+	if got := strings.Join(runner.execs[1].args, " "); got != "post COLLECT TASK-1" {
+		t.Errorf("post args = %q, want the collect output templated in", got)
+	}
+	if len(runner.skills) != 1 || runner.skills[0].payload["posted"] != "POST COLLECT TASK-1" || runner.skills[0].payload["task_id"] != "TASK-1" {
+		t.Errorf("skill request = %+v", runner.skills)
+	}
+	if len(f.Steps) != 3 || f.Steps[2].Name != "step3" || f.Output != "skill-recorded" {
+		t.Errorf("steps = %+v, output = %q", f.Steps, f.Output)
+	}
+	if len(f.Outputs) != 1 || f.Outputs[0] != "artifact digests/TASK-1.md" {
+		t.Errorf("outputs = %v", f.Outputs)
+	}
+}
+
+func TestRuleEngine_Workflow_RetriesWithBackoff(t *testing.T) {
+	rule := models.Rule{
+		Name:  "flaky",
+		On:    models.RuleTrigger{Schedule: "1h"},
+		Steps: []models.RuleStep{{Name: "fetch", RuleAction: models.RuleAction{Exec: []string{"fetch"}}, Retries: 3, Backoff: "10ms"}},
+	}
+	runner := &scriptedRunner{exec: func(_ context.Context, _ []string, call int) (string, error) {
+		if call < 3 {
+			return "", errors.New("connection reset")
+		}
+		return "ok", nil
+	}}
+	eng, slept := newWorkflowEngine(rule, runner)
+	f, err := eng.FireByName(context.Background(), "flaky", nil)
+	if err != nil || f.Status != FiringFired {
+		t.Fatalf("FireByName = %+v, %v", f, err)
+	}
+	if f.Steps[0].Attempts != 3 {
+		t.Errorf("attempts = %d, want 3", f.Steps[0].Attempts)
+	}
+	if want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}; len(*slept) != 2 || (*slept)[0] != want[0] || (*slept)[1] != want[1] {
+		t.Errorf("backoff waits = %v, want %v", *slept, want)
+	}
+}
+
+func TestRuleEngine_Workflow_TimeoutAndOnFailure(t *testing.T) {
+	rule := models.Rule{
+		Name: "deploy",
+		On:   models.RuleTrigger{Schedule: "1h"},
+		Steps: []models.RuleStep{
+			{Name: "build", RuleAction: models.RuleAction{Exec: []string{"build"}}},
+			{Name: "ship", RuleAction: models.RuleAction{Exec: []string{"ship"}}, Timeout: "20ms", Retries: 1},
+			{Name: "announce", RuleAction: models.RuleAction{Exec: []string{"announce"}}},
+		},
+		OnFailure: []models.RuleStep{{RuleAction: models.RuleAction{Exec: []string{"page", "{{.failed_step}}: {{.error}}"}}}},
+		Write:     []models.RuleOutput{{Artifact: "deploys/last.md"}},
+	}
+	runner := &scriptedRunner{exec: func(ctx context.Context, args []string, _ int) (string, error) {
+		if args[0] == "ship" {
+			<-ctx.Done() // hangs until the step timeout
+			return "", ctx.Err()
+		}
+		return "done", nil
+	}}
+	eng, _ := newWorkflowEngine(rule, runner)
+	f, _ := eng.FireByName(context.Background(), "deploy", nil)
+
+	if f.Status != FiringError || f.Reason != `step "ship": timed out after 20ms` {
+		t.Fatalf("firing = %+v", f)
+	}
+	if runner.calls["ship"] != 2 || runner.calls["announce"] != 0 {
+		t.Errorf("calls = %v; want ship tried twice and announce never", runner.calls)
+	}
+	page := runner.execs[len(runner.execs)-1].args
+	if page[0] != "page" || page[1] != "ship: timed out after 20ms" {
+		t.Errorf("on_failure args = %q", page)
+	}
+	if n := len(f.Steps); n != 3 || !f.Steps[n-1].OnFailure || f.Steps[1].Status != FiringError {
+		t.Errorf("steps = %+v", f.Steps)
+	}
+	if len(f.Outputs) != 0 || len(eng.artifacts.(*fakeArtifactWriter).artifacts) != 0 {
+		t.Error("outputs must not be written when a step fails")
+	}
+}
+
+func TestRuleEngine_Workflow_DryRunPlaceholders(t *testing.T) {
+	rule := models.Rule{
+		Name: "digest",
+		On:   models.RuleTrigger{Event: "task.created"},
+		Steps: []models.RuleStep{
+			{Name: "collect", RuleAction: models.RuleAction{Exec: []string{"collect"}}},
+			{Name: "post", RuleAction: models.RuleAction{Exec: []string{"post", "{{.steps.collect.output}}"}}},
+		},
+	}
+	runner := &scriptedRunner{exec: func(context.Context, []string, int) (string, error) { return "", nil }}
+	eng, _ := newWorkflowEngine(rule, runner)
+	fs, err := eng.Simulate(context.Background(), "digest", []RuleOccurrence{{Event: "task.created"}})
+	if err != nil || len(fs) != 1 {
+		t.Fatalf("Simulate = %+v, %v", fs, err)
+	}
+	if fs[0].Output != "would run: post <output of collect>" || len(runner.execs) != 0 {
+		t.Errorf("dry-run firing = %+v, execs = %+v", fs[0], runner.execs)
+	}
+}
@@ -96,6 +96,16 @@ type NeighborResolver interface {
 	Neighbors(id string) ([]models.GraphEdge, error)
 }
 
+// TaskMemoryHook is told when a task is archived, unarchived or deleted so
+// its vector-memory namespaces follow it (see MemoryLifecycle). It is
+// injected optionally via SetMemoryHook; the task transition has already
+// happened when it runs, so a failure only warns.
+type TaskMemoryHook interface {
+	TaskArchived(taskID string) error
+	TaskUnarchived(taskID string) error
+	TaskDeleted(taskID string) error
+}
+
 // TerminalStateUpdater defines the interface for updating terminal state
 type TerminalStateUpdater interface {
 	WriteTerminalState(worktreePath string, taskID string, state map[string]interface{}) error
@@ -136,6 +146,7 @@ type TaskManager struct {
 	initiativeResolver   InitiativeResolver
 	neighborResolver     NeighborResolver
 	serenaProvisioner    SerenaProvisioner
+	memoryHook           TaskMemoryHook
 	ticketsDir           string
 	archivedDir          string
 	worktreesDir         string
@@ -197,6 +208,33 @@ func (tm *TaskManager) SetSerenaProvisioner(p SerenaProvisioner) {
 	tm.serenaProvisioner = p
 }
 
+// SetMemoryHook wires the (optional) vector-memory lifecycle hook. A nil
+// hook leaves memory untouched on archive/unarchive/delete (unchanged
+// behaviour; `adb memory gc` can reconcile later).
+func (tm *TaskManager) SetMemoryHook(h TaskMemoryHook) {
+	tm.memoryHook = h
+}
+
+// notifyMemory tells the memory hook about a transition ("archived",
+// "unarchived" or "deleted"), warning on failure.
+func (tm *TaskManager) notifyMemory(taskID, transition string) {
+	if tm.memoryHook == nil {
+		return
+	}
+	var err error
+	switch transition {
+	case "archived":
+		err = tm.memoryHook.TaskArchived(taskID)
+	case "unarchived":
+		err = tm.memoryHook.TaskUnarchived(taskID)
+	case "deleted":
+		err = tm.memoryHook.TaskDeleted(taskID)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: memory not updated for %s %s: %v (run `adb memory gc` to reconcile)\n", transition, taskID, err)
+	}
+}
+
 // provisionSerena writes a per-worktree Serena config alongside the
 // task-context.md written by the worktree-bootstrap seam (#202). It is
 // nil-safe and fail-open: a provisioning error is logged and never blocks
@@ -709,6 +747,8 @@ func (tm *TaskManager) Archive(taskID string, opts ArchiveOptions) error {
 		return fmt.Errorf("failed to update task status: %w", err)
 	}
 
+	tm.notifyMemory(taskID, "archived")
+
 	// Log event
 	if tm.eventLogger != nil {
 		tm.eventLogger.Log("task.archived", map[string]interface{}{
@@ -764,6 +804,8 @@ func (tm *TaskManager) Unarchive(taskID string) error {
 		return fmt.Errorf("failed to update task status: %w", err)
 	}
 
+	tm.notifyMemory(taskID, "unarchived")
+
 	// Log event
 	if tm.eventLogger != nil {
 		tm.eventLogger.Log("task.unarchived", map[string]interface{}{
@@ -1047,6 +1089,8 @@ func (tm *TaskManager) Delete(taskID string) error {
 		return fmt.Errorf("failed to remove task from backlog: %w", err)
 	}
 
+	tm.notifyMemory(taskID, "deleted")
+
 	// Log event
 	if tm.eventLogger != nil {
 		tm.eventLogger.Log("task.deleted", map[string]interface{}{
@@ -460,6 +460,7 @@ func renderDecision(b *strings.Builder, d models.Decision) {
 		}
 		b.WriteString("\n")
 	}
+	renderSource(b, d.Source)
 }
 
 func renderLearning(b *strings.Builder, l models.Learning) {
@@ -471,6 +472,7 @@ func renderLearning(b *strings.Builder, l models.Learning) {
 	if l.Description != "" {
 		fmt.Fprintf(b, "%s\n\n", l.Description)
 	}
+	renderSource(b, l.Source)
 }
 
 func renderGotcha(b *strings.Builder, g models.Gotcha) {
@@ -488,4 +490,27 @@ func renderGotcha(b *strings.Builder, g models.Gotcha) {
 	if g.Prevention != "" {
 		fmt.Fprintf(b, "**Prevention:** %s\n\n", g.Prevention)
 	}
+	renderSource(b, g.Source)
+}
+
+// renderSource cites where an extracted entry came from, e.g.
+// "_Source: `tickets/TASK-00001/notes.md:12`_". Hand-recorded entries have
+// no source and render nothing.
+func renderSource(b *strings.Builder, src *models.Provenance) {
+	if src == nil || src.File == "" {
+		return
+	}
+	loc, where := src.File, ""
+	switch {
+	case src.Turn > 0 && src.Line > 0:
+		where = fmt.Sprintf(" turn %d, line %d", src.Turn, src.Line)
+	case src.Turn > 0:
+		where = fmt.Sprintf(" turn %d", src.Turn)
+	case src.Line > 0:
+		loc = fmt.Sprintf("%s:%d", src.File, src.Line)
+	}
+	if src.Extractor == ExtractorSummarizer {
+		where += " (summarized)"
+	}
+	fmt.Fprintf(b, "_Source: `%s`%s_\n\n", loc, where)
 }
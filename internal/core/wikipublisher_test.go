@@ -38,9 +38,11 @@ func TestWikiPublisher_PublishAll(t *testing.T) {
 			ID: "D1", Title: "Use JWT", Description: "Stateless sessions.",
 			Status: "accepted", Rationale: "Scales horizontally.",
 			Alternatives: []string{"Server sessions"}, Consequences: []string{"Token revocation is harder"},
+			Source: &models.Provenance{File: "tickets/TASK-00001/notes.md", Line: 12, Extractor: ExtractorParser},
 		}},
 		Learnings: []models.Learning{{Title: "pgx beats lib/pq", Description: "3x throughput.", Category: "technical"}},
-		Gotchas:   []models.Gotcha{{Title: "Clock skew", Description: "JWT exp fails.", Solution: "NTP", Severity: "high"}},
+		Gotchas: []models.Gotcha{{Title: "Clock skew", Description: "JWT exp fails.", Solution: "NTP", Severity: "high",
+			Source: &models.Provenance{File: "sessions/S-00001/turns.yaml", Turn: 4, Extractor: ExtractorSummarizer}}},
 	})
 	// A task with no knowledge entries — must be skipped, not written empty.
 	seedKnowledge(t, base, "TASK-00002", &models.ExtractedKnowledge{TaskID: "TASK-00002"})
@@ -92,6 +94,8 @@ func TestWikiPublisher_PublishAll(t *testing.T) {
 		"## Gotchas",
 		"### Clock skew `high`",
 		"**Solution:** NTP",
+		"_Source: `tickets/TASK-00001/notes.md:12`_",
+		"_Source: `sessions/S-00001/turns.yaml` turn 4 (summarized)_",
 	} {
 		if !strings.Contains(content, want) {
 			t.Errorf("page missing %q\n---\n%s", want, content)
@@ -33,8 +33,12 @@ type HookEvent struct {
 	Data      map[string]interface{} `json:"data,omitempty"`
 }
 
-// PreToolUseEvent represents the PreToolUse hook payload
+// PreToolUseEvent represents the PreToolUse hook payload. SessionID and
+// ToolUseID (sent by Claude Code) let tracing tie the pre- and post-tool-use
+// hooks of one call to the same span.
 type PreToolUseEvent struct {
+	SessionID  string                 `json:"session_id,omitempty"`
+	ToolUseID  string                 `json:"tool_use_id,omitempty"`
 	ToolName   string                 `json:"tool_name"`
 	Parameters map[string]interface{} `json:"parameters"`
 	Timestamp  string                 `json:"timestamp"`
@@ -42,6 +46,8 @@ type PreToolUseEvent struct {
 
 // PostToolUseEvent represents the PostToolUse hook payload
 type PostToolUseEvent struct {
+	SessionID  string                 `json:"session_id,omitempty"`
+	ToolUseID  string                 `json:"tool_use_id,omitempty"`
 	ToolName   string                 `json:"tool_name"`
 	Parameters map[string]interface{} `json:"parameters"`
 	Result     interface{}            `json:"result,omitempty"`
@@ -50,6 +56,7 @@ type PostToolUseEvent struct {
 
 // TaskCompletedEvent represents the TaskCompleted hook payload
 type TaskCompletedEvent struct {
+	SessionID string                 `json:"session_id,omitempty"`
 	TaskID    string                 `json:"task_id"`
 	Status    string                 `json:"status"`
 	Timestamp string                 `json:"timestamp"`
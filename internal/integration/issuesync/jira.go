@@ -0,0 +1,284 @@
+package issuesync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// Default Jira workflow-state and priority names. They match a stock Jira
+// Software board with a "Blocked" and "In Review" column; anything else is
+// remapped with status_map / priority_map.
+var (
+	defaultJiraStatuses = map[models.TaskStatus]string{
+		models.TaskStatusBacklog:    "To Do",
+		models.TaskStatusInProgress: "In Progress",
+		models.TaskStatusBlocked:    "Blocked",
+		models.TaskStatusReview:     "In Review",
+		models.TaskStatusDone:       "Done",
+		models.TaskStatusArchived:   "Done",
+	}
+	defaultJiraPriorities = map[models.Priority]string{
+		models.PriorityP0: "Highest",
+		models.PriorityP1: "High",
+		models.PriorityP2: "Medium",
+		models.PriorityP3: "Low",
+	}
+)
+
+// jiraTimeLayout is the timestamp format of Jira's REST v2 `updated` field.
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// jiraProvider speaks the Jira REST API v2 directly (plain-text descriptions,
+// which keeps bodies byte-comparable with context.md; v3 would need ADF).
+// An issue's Number is the numeric half of its key, so ticket linkage stays
+// the same RemoteIssue int the github/gitlab providers use: PROJ-123 <-> 123.
+type jiraProvider struct {
+	baseURL   string
+	email     string // set: basic auth (Jira Cloud); empty: bearer PAT (Data Center)
+	token     string
+	issueType string
+	mapping   FieldMapping
+	client    *http.Client
+}
+
+func (p *jiraProvider) Name() string { return "jira" }
+
+// jiraIssue is the subset of GET /rest/api/2/issue/{key} we read.
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string   `json:"summary"`
+		Description *string  `json:"description"`
+		Labels      []string `json:"labels"`
+		Updated     string   `json:"updated"`
+		Status      struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"` // new / indeterminate / done
+			} `json:"statusCategory"`
+		} `json:"status"`
+		Priority *struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+	} `json:"fields"`
+}
+
+// issueKey builds a Jira key / Linear identifier ("PROJ-123").
+func issueKey(project string, number int) string { return project + "-" + strconv.Itoa(number) }
+
+func (p *jiraProvider) Get(_, project string, number int) (RemoteIssue, bool, error) {
+	if number == 0 {
+		return RemoteIssue{}, false, nil
+	}
+	var ji jiraIssue
+	status, err := p.do(http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(issueKey(project, number))+
+		"?fields=summary,description,status,labels,priority,updated", nil, &ji)
+	if status == http.StatusNotFound {
+		return RemoteIssue{}, false, nil
+	}
+	if err != nil {
+		return RemoteIssue{}, false, err
+	}
+	return p.toRemote(ji), true, nil
+}
+
+func (p *jiraProvider) Create(_, project string, want RemoteIssue) (RemoteIssue, error) {
+	status, priority := wanted(want)
+	issueType := p.issueType
+	if issueType == "" {
+		issueType = "Task"
+	}
+	fields := map[string]interface{}{
+		"project":     map[string]string{"key": project},
+		"issuetype":   map[string]string{"name": issueType},
+		"summary":     want.Title,
+		"description": want.Body,
+	}
+	if name := p.mapping.Priority[priority]; name != "" {
+		fields["priority"] = map[string]string{"name": name}
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if _, err := p.do(http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": fields}, &created); err != nil {
+		return RemoteIssue{}, err
+	}
+	// New issues land in the workflow's initial state. Moving them is
+	// best-effort: failing the create here would leave the issue unlinked
+	// and the next sync would open a duplicate.
+	_ = p.transition(created.Key, p.mapping.Status[status])
+	return RemoteIssue{
+		Number: issueNumberFromKey(created.Key),
+		URL:    p.browseURL(created.Key),
+		Title:  want.Title,
+		Body:   want.Body,
+		Labels: want.Labels,
+		State:  want.State,
+	}, nil
+}
+
+func (p *jiraProvider) Update(_, project string, number int, want RemoteIssue) (RemoteIssue, error) {
+	status, priority := wanted(want)
+	key := issueKey(project, number)
+	fields := map[string]interface{}{
+		"summary":     want.Title,
+		"description": want.Body,
+	}
+	if name := p.mapping.Priority[priority]; name != "" {
+		fields["priority"] = map[string]string{"name": name}
+	}
+	if _, err := p.do(http.MethodPut, "/rest/api/2/issue/"+url.PathEscape(key), map[string]interface{}{"fields": fields}, nil); err != nil {
+		return RemoteIssue{}, err
+	}
+	// Status only moves through the workflow's transitions. Unlike the
+	// field write above, a missing transition is reported: it means
+	// status_map names a state this workflow cannot reach.
+	if err := p.transition(key, p.mapping.Status[status]); err != nil {
+		return RemoteIssue{}, err
+	}
+	want.Number, want.URL = number, p.browseURL(key)
+	return want, nil
+}
+
+// transition moves an issue to the named workflow state, doing nothing when
+// it is already there.
+func (p *jiraProvider) transition(key, state string) error {
+	if state == "" {
+		return nil
+	}
+	var ji jiraIssue
+	if _, err := p.do(http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=status", nil, &ji); err != nil {
+		return err
+	}
+	if strings.EqualFold(ji.Fields.Status.Name, state) {
+		return nil
+	}
+	var ts struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if _, err := p.do(http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"/transitions", nil, &ts); err != nil {
+		return err
+	}
+	for _, t := range ts.Transitions {
+		if strings.EqualFold(t.To.Name, state) {
+			_, err := p.do(http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/transitions",
+				map[string]interface{}{"transition": map[string]string{"id": t.ID}}, nil)
+			return err
+		}
+	}
+	return fmt.Errorf("jira %s: no transition from %q to %q", key, ji.Fields.Status.Name, state)
+}
+
+func (p *jiraProvider) toRemote(ji jiraIssue) RemoteIssue {
+	closed := ji.Fields.Status.StatusCategory.Key == "done"
+	var priority models.Priority
+	if ji.Fields.Priority != nil {
+		priority = p.mapping.PriorityFrom(ji.Fields.Priority.Name)
+	}
+	state := IssueOpen
+	if closed {
+		state = IssueClosed
+	}
+	var body string
+	if ji.Fields.Description != nil {
+		body = *ji.Fields.Description
+	}
+	updated, _ := time.Parse(jiraTimeLayout, ji.Fields.Updated)
+	return RemoteIssue{
+		Number:    issueNumberFromKey(ji.Key),
+		URL:       p.browseURL(ji.Key),
+		Title:     ji.Fields.Summary,
+		Body:      body,
+		Labels:    p.mapping.remoteLabels(p.mapping.StatusFrom(ji.Fields.Status.Name, closed), priority, ji.Fields.Labels),
+		State:     state,
+		UpdatedAt: updated,
+	}
+}
+
+func (p *jiraProvider) browseURL(key string) string { return p.baseURL + "/browse/" + key }
+
+// do sends one JSON request and decodes the response into out (when non-nil).
+// Errors carry the method, path, status and Jira's errorMessages — never the
+// request headers — so they are safe to log as issue.conflict.
+func (p *jiraProvider) do(method, path string, in, out interface{}) (int, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, p.baseURL+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.email != "" {
+		req.SetBasicAuth(p.email, p.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("jira %s %s: %w", method, strings.SplitN(path, "?", 2)[0], err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if resp.StatusCode >= 300 {
+		var je struct {
+			ErrorMessages []string          `json:"errorMessages"`
+			Errors        map[string]string `json:"errors"`
+		}
+		_ = json.Unmarshal(data, &je)
+		msgs := je.ErrorMessages
+		fields := make([]string, 0, len(je.Errors))
+		for field := range je.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			msgs = append(msgs, field+": "+je.Errors[field])
+		}
+		detail := ""
+		if len(msgs) > 0 {
+			detail = ": " + strings.Join(msgs, "; ")
+		}
+		return resp.StatusCode, fmt.Errorf("jira %s %s: %s%s", method, strings.SplitN(path, "?", 2)[0], resp.Status, detail)
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("parse jira response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// issueNumberFromKey extracts 123 from "PROJ-123" (Jira keys and Linear
+// identifiers alike); 0 when malformed, like issueNumberFromURL.
+func issueNumberFromKey(key string) int {
+	i := strings.LastIndex(key, "-")
+	if i < 0 {
+		return 0
+	}
+	n, _ := strconv.Atoi(key[i+1:])
+	return n
+}
@@ -0,0 +1,278 @@
+package issuesync
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// fakeJira is an in-memory Jira REST v2 server: issues in one project, a
+// workflow whose every state is reachable except the pairs in noTransition.
+type fakeJira struct {
+	mu           sync.Mutex
+	issues       map[string]*fakeJiraIssue
+	next         int
+	now          time.Time
+	noTransition map[string]bool // "From->To"
+	authHeaders  []string
+}
+
+type fakeJiraIssue struct {
+	Summary, Description, Status, Priority string
+	Labels                                 []string
+	Updated                                time.Time
+}
+
+var fakeJiraStates = []string{"To Do", "In Progress", "Blocked", "In Review", "Done"}
+
+func newFakeJira(t *testing.T) (*fakeJira, *httptest.Server) {
+	f := &fakeJira{issues: map[string]*fakeJiraIssue{}, now: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), noTransition: map[string]bool{}}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeJira) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.authHeaders = append(f.authHeaders, r.Header.Get("Authorization"))
+	rest := strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue")
+	key, sub, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	var in struct {
+		Fields struct {
+			Project  struct{ Key string }
+			Summary  *string
+			Desc     *string `json:"description"`
+			Priority *struct{ Name string }
+		}
+		Transition struct{ ID string }
+	}
+	_ = json.NewDecoder(r.Body).Decode(&in)
+
+	switch {
+	case r.Method == http.MethodPost && key == "":
+		f.next++
+		key = in.Fields.Project.Key + "-" + strconv.Itoa(f.next)
+		iss := &fakeJiraIssue{Summary: *in.Fields.Summary, Description: *in.Fields.Desc, Status: "To Do", Updated: f.now}
+		if in.Fields.Priority != nil {
+			iss.Priority = in.Fields.Priority.Name
+		}
+		f.issues[key] = iss
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "10001", "key": key})
+		return
+	}
+	iss, ok := f.issues[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errorMessages":["Issue does not exist or you do not have permission to see it."],"errors":{}}`))
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && sub == "":
+		category := "indeterminate"
+		switch iss.Status {
+		case "To Do":
+			category = "new"
+		case "Done":
+			category = "done"
+		}
+		fields := map[string]interface{}{
+			"summary": iss.Summary, "description": iss.Description, "labels": iss.Labels,
+			"updated": iss.Updated.Format(jiraTimeLayout),
+			"status":  map[string]interface{}{"name": iss.Status, "statusCategory": map[string]string{"key": category}},
+		}
+		if iss.Priority != "" {
+			fields["priority"] = map[string]string{"name": iss.Priority}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"key": key, "fields": fields})
+	case r.Method == http.MethodPut && sub == "":
+		if in.Fields.Summary != nil {
+			iss.Summary = *in.Fields.Summary
+		}
+		if in.Fields.Desc != nil {
+			iss.Description = *in.Fields.Desc
+		}
+		if in.Fields.Priority != nil {
+			iss.Priority = in.Fields.Priority.Name
+		}
+		iss.Updated = f.now
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && sub == "transitions":
+		var ts []map[string]interface{}
+		for i, to := range fakeJiraStates {
+			if to != iss.Status && !f.noTransition[iss.Status+"->"+to] {
+				ts = append(ts, map[string]interface{}{"id": strconv.Itoa(i), "to": map[string]string{"name": to}})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"transitions": ts})
+	case r.Method == http.MethodPost && sub == "transitions":
+		i, _ := strconv.Atoi(in.Transition.ID)
+		iss.Status, iss.Updated = fakeJiraStates[i], f.now
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestJira(t *testing.T, cfg models.IssueTrackerConfig) (*fakeJira, Tracker) {
+	t.Helper()
+	f, srv := newFakeJira(t)
+	cfg.Provider, cfg.URL = "jira", srv.URL
+	if cfg.Project == "" {
+		cfg.Project = "PLAT"
+	}
+	if cfg.Token == "" {
+		cfg.Token = "jira-token"
+	}
+	tr, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	return f, tr
+}
+
+func TestJiraProvider_GetMapsNativeFields(t *testing.T) {
+	f, tr := newTestJira(t, models.IssueTrackerConfig{Email: "me@acme.io"})
+	f.issues["PLAT-7"] = &fakeJiraIssue{
+		Summary: "Rotate keys", Description: "quarterly", Status: "Blocked", Priority: "Highest",
+		Labels: []string{"security"}, Updated: time.Date(2026, 3, 2, 14, 5, 11, 0, time.UTC),
+	}
+
+	iss, found, err := tr.Provider.Get("", tr.Project, 7)
+	if err != nil || !found {
+		t.Fatalf("Get found=%v err=%v", found, err)
+	}
+	if iss.Number != 7 || iss.Title != "Rotate keys" || iss.Body != "quarterly" || iss.State != IssueOpen {
+		t.Errorf("issue = %+v", iss)
+	}
+	if !strings.HasSuffix(iss.URL, "/browse/PLAT-7") {
+		t.Errorf("URL = %q", iss.URL)
+	}
+	want := []string{"adb:blocked", "priority:P0", "security"}
+	if strings.Join(iss.Labels, ",") != strings.Join(want, ",") {
+		t.Errorf("labels = %v, want %v", iss.Labels, want)
+	}
+	if !iss.UpdatedAt.Equal(time.Date(2026, 3, 2, 14, 5, 11, 0, time.UTC)) {
+		t.Errorf("UpdatedAt = %v", iss.UpdatedAt)
+	}
+	if !strings.HasPrefix(f.authHeaders[0], "Basic ") {
+		t.Errorf("with email set, auth = %q, want basic", f.authHeaders[0])
+	}
+
+	if _, found, err := tr.Provider.Get("", tr.Project, 99); found || err != nil {
+		t.Errorf("missing issue: found=%v err=%v, want not-found", found, err)
+	}
+	f.issues["PLAT-8"] = &fakeJiraIssue{Status: "Done"}
+	if iss, _, _ := tr.Provider.Get("", tr.Project, 8); iss.State != IssueClosed || AdbLabelFrom(iss.Labels) != "adb:done" {
+		t.Errorf("done-category issue = %+v, want closed + adb:done", iss)
+	}
+}
+
+func TestJiraProvider_CreateAndUpdateWalkTheWorkflow(t *testing.T) {
+	f, tr := newTestJira(t, models.IssueTrackerConfig{StatusMap: map[string]string{"review": "In Progress"}})
+	p := tr.Provider
+
+	created, err := p.Create("", "PLAT", RemoteIssue{
+		Title: "Retry deliveries", Body: "with backoff",
+		Labels: []string{StatusLabel(models.TaskStatusInProgress), PriorityLabel(models.PriorityP1)},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	iss := f.issues["PLAT-1"]
+	if created.Number != 1 || iss.Status != "In Progress" || iss.Priority != "High" {
+		t.Fatalf("created %+v, stored %+v", created, iss)
+	}
+	if f.authHeaders[0] != "Bearer jira-token" {
+		t.Errorf("without email, auth = %q, want a bearer PAT", f.authHeaders[0])
+	}
+
+	if _, err := p.Update("", "PLAT", 1, RemoteIssue{
+		Title: "Retry deliveries", Body: "with jittered backoff",
+		Labels: []string{StatusLabel(models.TaskStatusBlocked), PriorityLabel(models.PriorityP0)},
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if iss.Status != "Blocked" || iss.Priority != "Highest" || iss.Description != "with jittered backoff" {
+		t.Errorf("after update %+v", iss)
+	}
+
+	// status_map sends review to "In Progress"; the workflow forbids
+	// Blocked -> In Progress, which surfaces as an error.
+	f.noTransition["Blocked->In Progress"] = true
+	_, err = p.Update("", "PLAT", 1, RemoteIssue{Title: "Retry deliveries", Labels: []string{StatusLabel(models.TaskStatusReview)}})
+	if err == nil || !strings.Contains(err.Error(), `no transition from "Blocked" to "In Progress"`) {
+		t.Errorf("err = %v, want a missing-transition error", err)
+	}
+}
+
+func TestJiraProvider_ErrorsCarryJiraMessagesNotToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errorMessages":[],"errors":{"priority":"Field 'priority' cannot be set."}}`))
+	}))
+	defer srv.Close()
+	tr, err := NewTracker(models.IssueTrackerConfig{Provider: "jira", URL: srv.URL, Project: "PLAT", Token: "s3cret-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tr.Provider.Create("", "PLAT", RemoteIssue{Title: "x", Labels: []string{PriorityLabel(models.PriorityP1)}})
+	if err == nil || !strings.Contains(err.Error(), "priority: Field 'priority' cannot be set.") {
+		t.Fatalf("err = %v", err)
+	}
+	if strings.Contains(err.Error(), "s3cret-token") {
+		t.Errorf("error leaks the token: %v", err)
+	}
+}
+
+// TestSyncer_JiraTrackerRoundTrip drives the unchanged Reconcile through a
+// Jira tracker: the first sync creates and links PLAT-1, a teammate then
+// moves it to Blocked, and the next sync pulls that status back.
+func TestSyncer_JiraTrackerRoundTrip(t *testing.T) {
+	f, tr := newTestJira(t, models.IssueTrackerConfig{Repos: []string{"github.com/acme/*"}})
+	tk := models.Task{
+		ID: "TASK-00042", Title: "Retry deliveries", Status: models.TaskStatusInProgress,
+		Priority: models.PriorityP1, Repo: "github.com/acme/widgets",
+		Updated: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	var logged []loggedEvent
+	s := &Syncer{
+		Trackers: []Tracker{tr},
+		Body:     func(models.Task) string { return "" },
+		Write:    func(w models.Task) error { tk = w; return nil },
+		Log:      func(evt string, data map[string]interface{}) { logged = append(logged, loggedEvent{evt, data}) },
+	}
+
+	if res := s.SyncTask(tk, DirectionBoth, false); res.Action != ActionCreateRemote {
+		t.Fatalf("first sync = %+v", res)
+	}
+	if tk.RemoteIssue != 1 || !strings.HasSuffix(tk.RemoteURL, "/browse/PLAT-1") || tk.SyncHash == "" {
+		t.Fatalf("linked task = %+v", tk)
+	}
+	if logged[0].Data["provider"] != "jira" {
+		t.Errorf("event provider = %v", logged[0].Data["provider"])
+	}
+
+	f.issues["PLAT-1"].Status = "Blocked"
+	f.issues["PLAT-1"].Updated = time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	if res := s.SyncTask(tk, DirectionBoth, false); res.Action != ActionUpdateLocal {
+		t.Fatalf("second sync = %+v", res)
+	}
+	if tk.Status != models.TaskStatusBlocked || tk.Priority != models.PriorityP1 {
+		t.Errorf("pulled task = %+v, want blocked with local priority kept", tk)
+	}
+
+	// A ticket outside the tracker's repos still goes to github/gitlab
+	// selection, which skips a non-github repo.
+	other := models.Task{ID: "TASK-00043", Repo: "git.internal/acme/tool"}
+	if res := s.SyncTask(other, DirectionBoth, true); res.Action != ActionNoop {
+		t.Errorf("unrouted ticket = %+v", res)
+	}
+}
@@ -0,0 +1,299 @@
+package issuesync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// DefaultLinearEndpoint is Linear's GraphQL API.
+const DefaultLinearEndpoint = "https://api.linear.app/graphql"
+
+// Default Linear workflow-state and priority names. Linear teams have no
+// Blocked state out of the box; teams that add one keep the default, others
+// remap blocked with status_map.
+var (
+	defaultLinearStatuses = map[models.TaskStatus]string{
+		models.TaskStatusBacklog:    "Backlog",
+		models.TaskStatusInProgress: "In Progress",
+		models.TaskStatusBlocked:    "Blocked",
+		models.TaskStatusReview:     "In Review",
+		models.TaskStatusDone:       "Done",
+		models.TaskStatusArchived:   "Canceled",
+	}
+	defaultLinearPriorities = map[models.Priority]string{
+		models.PriorityP0: "Urgent",
+		models.PriorityP1: "High",
+		models.PriorityP2: "Medium",
+		models.PriorityP3: "Low",
+	}
+)
+
+// linearPriorityValues are Linear's fixed priority numbers by label.
+var linearPriorityValues = map[string]int{
+	"no priority": 0, "urgent": 1, "high": 2, "medium": 3, "low": 4,
+}
+
+// linearIssueFields is the selection every issue query/mutation returns.
+const linearIssueFields = `identifier url title description updatedAt priorityLabel
+labels { nodes { name } } state { name type }`
+
+// linearProvider speaks Linear's GraphQL API directly. Issues are addressed
+// by identifier (TEAM-123), so like Jira the RemoteIssue Number is the
+// numeric half and the team key is the `name` half of owner/name.
+type linearProvider struct {
+	endpoint string
+	token    string
+	mapping  FieldMapping
+	client   *http.Client
+
+	mu    sync.Mutex
+	teams map[string]linearTeam // team key -> id + states, fetched once
+}
+
+type linearTeam struct {
+	ID     string
+	States map[string]string // lower-cased state name -> state id
+}
+
+func (p *linearProvider) Name() string { return "linear" }
+
+// linearIssue matches linearIssueFields.
+type linearIssue struct {
+	Identifier    string    `json:"identifier"`
+	URL           string    `json:"url"`
+	Title         string    `json:"title"`
+	Description   *string   `json:"description"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	PriorityLabel string    `json:"priorityLabel"`
+	Labels        struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	State struct {
+		Name string `json:"name"`
+		Type string `json:"type"` // triage / backlog / unstarted / started / completed / canceled
+	} `json:"state"`
+}
+
+// errLinearNotFound marks the GraphQL "Entity not found" error, which Get
+// turns into found=false.
+var errLinearNotFound = errors.New("linear: entity not found")
+
+func (p *linearProvider) Get(_, team string, number int) (RemoteIssue, bool, error) {
+	if number == 0 {
+		return RemoteIssue{}, false, nil
+	}
+	var data struct {
+		Issue *linearIssue `json:"issue"`
+	}
+	err := p.query(`query Issue($id: String!) { issue(id: $id) { `+linearIssueFields+` } }`,
+		map[string]interface{}{"id": issueKey(team, number)}, &data)
+	if errors.Is(err, errLinearNotFound) || (err == nil && data.Issue == nil) {
+		return RemoteIssue{}, false, nil
+	}
+	if err != nil {
+		return RemoteIssue{}, false, err
+	}
+	return p.toRemote(*data.Issue), true, nil
+}
+
+func (p *linearProvider) Create(_, team string, want RemoteIssue) (RemoteIssue, error) {
+	t, err := p.team(team)
+	if err != nil {
+		return RemoteIssue{}, err
+	}
+	input, err := p.input(t, team, want)
+	if err != nil {
+		return RemoteIssue{}, err
+	}
+	input["teamId"] = t.ID
+	var data struct {
+		IssueCreate struct {
+			Success bool         `json:"success"`
+			Issue   *linearIssue `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	if err := p.query(`mutation Create($input: IssueCreateInput!) { issueCreate(input: $input) { success issue { `+linearIssueFields+` } } }`,
+		map[string]interface{}{"input": input}, &data); err != nil {
+		return RemoteIssue{}, err
+	}
+	if !data.IssueCreate.Success || data.IssueCreate.Issue == nil {
+		return RemoteIssue{}, fmt.Errorf("linear issueCreate in %s was not successful", team)
+	}
+	return p.toRemote(*data.IssueCreate.Issue), nil
+}
+
+func (p *linearProvider) Update(_, team string, number int, want RemoteIssue) (RemoteIssue, error) {
+	t, err := p.team(team)
+	if err != nil {
+		return RemoteIssue{}, err
+	}
+	input, err := p.input(t, team, want)
+	if err != nil {
+		return RemoteIssue{}, err
+	}
+	var data struct {
+		IssueUpdate struct {
+			Success bool         `json:"success"`
+			Issue   *linearIssue `json:"issue"`
+		} `json:"issueUpdate"`
+	}
+	if err := p.query(`mutation Update($id: String!, $input: IssueUpdateInput!) { issueUpdate(id: $id, input: $input) { success issue { `+linearIssueFields+` } } }`,
+		map[string]interface{}{"id": issueKey(team, number), "input": input}, &data); err != nil {
+		return RemoteIssue{}, err
+	}
+	if !data.IssueUpdate.Success || data.IssueUpdate.Issue == nil {
+		return RemoteIssue{}, fmt.Errorf("linear issueUpdate %s was not successful", issueKey(team, number))
+	}
+	return p.toRemote(*data.IssueUpdate.Issue), nil
+}
+
+// input builds the shared IssueCreateInput/IssueUpdateInput fields. Unlike
+// Jira, Linear sets the state directly, so an unknown state is an error on
+// create too.
+func (p *linearProvider) input(t linearTeam, team string, want RemoteIssue) (map[string]interface{}, error) {
+	status, priority := wanted(want)
+	input := map[string]interface{}{
+		"title":       want.Title,
+		"description": want.Body,
+	}
+	if name := p.mapping.Status[status]; name != "" {
+		id, ok := t.States[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("linear team %s has no workflow state %q", team, name)
+		}
+		input["stateId"] = id
+	}
+	if name := p.mapping.Priority[priority]; name != "" {
+		if v, ok := linearPriorityValues[strings.ToLower(name)]; ok {
+			input["priority"] = v
+		}
+	}
+	return input, nil
+}
+
+// team resolves a team key to its id and workflow states, once per run.
+func (p *linearProvider) team(key string) (linearTeam, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.teams[key]; ok {
+		return t, nil
+	}
+	var data struct {
+		Teams struct {
+			Nodes []struct {
+				ID     string `json:"id"`
+				States struct {
+					Nodes []struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"nodes"`
+				} `json:"states"`
+			} `json:"nodes"`
+		} `json:"teams"`
+	}
+	if err := p.query(`query Team($key: String!) { teams(filter: { key: { eq: $key } }) { nodes { id states { nodes { id name } } } } }`,
+		map[string]interface{}{"key": key}, &data); err != nil {
+		return linearTeam{}, err
+	}
+	if len(data.Teams.Nodes) == 0 {
+		return linearTeam{}, fmt.Errorf("linear team %q not found", key)
+	}
+	node := data.Teams.Nodes[0]
+	t := linearTeam{ID: node.ID, States: make(map[string]string, len(node.States.Nodes))}
+	for _, s := range node.States.Nodes {
+		t.States[strings.ToLower(s.Name)] = s.ID
+	}
+	if p.teams == nil {
+		p.teams = make(map[string]linearTeam)
+	}
+	p.teams[key] = t
+	return t, nil
+}
+
+func (p *linearProvider) toRemote(li linearIssue) RemoteIssue {
+	closed := li.State.Type == "completed" || li.State.Type == "canceled"
+	state := IssueOpen
+	if closed {
+		state = IssueClosed
+	}
+	native := make([]string, 0, len(li.Labels.Nodes))
+	for _, l := range li.Labels.Nodes {
+		native = append(native, l.Name)
+	}
+	var body string
+	if li.Description != nil {
+		body = *li.Description
+	}
+	return RemoteIssue{
+		Number:    issueNumberFromKey(li.Identifier),
+		URL:       li.URL,
+		Title:     li.Title,
+		Body:      body,
+		Labels:    p.mapping.remoteLabels(p.mapping.StatusFrom(li.State.Name, closed), p.mapping.PriorityFrom(li.PriorityLabel), native),
+		State:     state,
+		UpdatedAt: li.UpdatedAt,
+	}
+}
+
+// query runs one GraphQL operation and decodes `data` into out. Personal API
+// keys (lin_api_…) are sent as-is, OAuth tokens as a bearer. Errors carry
+// only the GraphQL messages, never the request headers.
+func (p *linearProvider) query(q string, vars map[string]interface{}, out interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{"query": q, "variables": vars})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if strings.HasPrefix(p.token, "lin_api_") {
+		req.Header.Set("Authorization", p.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("linear: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	var gr struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &gr); err != nil {
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("linear: %s", resp.Status)
+		}
+		return fmt.Errorf("parse linear response: %w", err)
+	}
+	if len(gr.Errors) > 0 {
+		msgs := make([]string, 0, len(gr.Errors))
+		for _, e := range gr.Errors {
+			if strings.HasPrefix(strings.ToLower(e.Message), "entity not found") {
+				return errLinearNotFound
+			}
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("linear: %s", strings.Join(msgs, "; "))
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("linear: %s", resp.Status)
+	}
+	return json.Unmarshal(gr.Data, out)
+}
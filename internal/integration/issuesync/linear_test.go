@@ -0,0 +1,254 @@
+package issuesync
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// fakeLinear is an in-memory Linear GraphQL endpoint for one team. It
+// dispatches on the operation name, which is all linearProvider sends.
+type fakeLinear struct {
+	mu          sync.Mutex
+	team        string
+	states      map[string]string // id -> name
+	issues      map[string]*fakeLinearIssue
+	next        int
+	now         time.Time
+	teamQueries int
+	auth        string
+}
+
+type fakeLinearIssue struct {
+	Title, Description, StateID string
+	Priority                    int
+	Labels                      []string
+	Updated                     time.Time
+}
+
+var fakeLinearStateTypes = map[string]string{
+	"Backlog": "backlog", "Todo": "unstarted", "In Progress": "started",
+	"In Review": "started", "Done": "completed", "Canceled": "canceled",
+}
+
+func newFakeLinear(t *testing.T) (*fakeLinear, *httptest.Server) {
+	f := &fakeLinear{
+		team:   "ENG",
+		states: map[string]string{"s1": "Backlog", "s2": "Todo", "s3": "In Progress", "s4": "In Review", "s5": "Done", "s6": "Canceled"},
+		issues: map[string]*fakeLinearIssue{},
+		now:    time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeLinear) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+	var req struct {
+		Query     string
+		Variables struct {
+			ID    string
+			Key   string
+			Input map[string]interface{}
+		}
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	reply := func(data interface{}) { _ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data}) }
+	notFound := func() {
+		_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"Entity not found: Issue","extensions":{"type":"invalid input"}}]}`))
+	}
+
+	switch {
+	case strings.HasPrefix(req.Query, "query Team"):
+		f.teamQueries++
+		var nodes []map[string]interface{}
+		if req.Variables.Key == f.team {
+			var states []map[string]string
+			for id, name := range f.states {
+				states = append(states, map[string]string{"id": id, "name": name})
+			}
+			nodes = append(nodes, map[string]interface{}{"id": "team-eng", "states": map[string]interface{}{"nodes": states}})
+		}
+		reply(map[string]interface{}{"teams": map[string]interface{}{"nodes": nodes}})
+	case strings.HasPrefix(req.Query, "query Issue"):
+		if _, ok := f.issues[req.Variables.ID]; !ok {
+			notFound()
+			return
+		}
+		reply(map[string]interface{}{"issue": f.render(req.Variables.ID)})
+	case strings.HasPrefix(req.Query, "mutation Create"):
+		f.next++
+		id := f.team + "-" + strconv.Itoa(f.next)
+		f.issues[id] = &fakeLinearIssue{StateID: "s1"}
+		f.apply(id, req.Variables.Input)
+		reply(map[string]interface{}{"issueCreate": map[string]interface{}{"success": true, "issue": f.render(id)}})
+	case strings.HasPrefix(req.Query, "mutation Update"):
+		if _, ok := f.issues[req.Variables.ID]; !ok {
+			notFound()
+			return
+		}
+		f.apply(req.Variables.ID, req.Variables.Input)
+		reply(map[string]interface{}{"issueUpdate": map[string]interface{}{"success": true, "issue": f.render(req.Variables.ID)}})
+	default:
+		_, _ = w.Write([]byte(`{"errors":[{"message":"unknown operation"}]}`))
+	}
+}
+
+func (f *fakeLinear) apply(id string, input map[string]interface{}) {
+	iss := f.issues[id]
+	if v, ok := input["title"].(string); ok {
+		iss.Title = v
+	}
+	if v, ok := input["description"].(string); ok {
+		iss.Description = v
+	}
+	if v, ok := input["stateId"].(string); ok {
+		iss.StateID = v
+	}
+	if v, ok := input["priority"].(float64); ok {
+		iss.Priority = int(v)
+	}
+	iss.Updated = f.now
+}
+
+func (f *fakeLinear) render(id string) map[string]interface{} {
+	iss := f.issues[id]
+	labels := make([]map[string]string, 0, len(iss.Labels))
+	for _, l := range iss.Labels {
+		labels = append(labels, map[string]string{"name": l})
+	}
+	name := f.states[iss.StateID]
+	priorityLabels := []string{"No priority", "Urgent", "High", "Medium", "Low"}
+	return map[string]interface{}{
+		"identifier": id, "url": "https://linear.app/acme/issue/" + id,
+		"title": iss.Title, "description": iss.Description,
+		"updatedAt": iss.Updated.Format(time.RFC3339), "priorityLabel": priorityLabels[iss.Priority],
+		"labels": map[string]interface{}{"nodes": labels},
+		"state":  map[string]string{"name": name, "type": fakeLinearStateTypes[name]},
+	}
+}
+
+func newTestLinear(t *testing.T, cfg models.IssueTrackerConfig) (*fakeLinear, Tracker) {
+	t.Helper()
+	f, srv := newFakeLinear(t)
+	cfg.Provider, cfg.URL, cfg.Project = "linear", srv.URL, "ENG"
+	if cfg.Token == "" {
+		cfg.Token = "lin_api_test"
+	}
+	tr, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	return f, tr
+}
+
+func TestLinearProvider_GetMapsNativeFields(t *testing.T) {
+	f, tr := newTestLinear(t, models.IssueTrackerConfig{})
+	f.issues["ENG-12"] = &fakeLinearIssue{
+		Title: "Dark mode", Description: "for the dashboard", StateID: "s4", Priority: 2,
+		Labels: []string{"frontend"}, Updated: time.Date(2026, 3, 2, 14, 5, 11, 0, time.UTC),
+	}
+
+	iss, found, err := tr.Provider.Get("", "ENG", 12)
+	if err != nil || !found {
+		t.Fatalf("Get found=%v err=%v", found, err)
+	}
+	if iss.Number != 12 || iss.Title != "Dark mode" || iss.URL != "https://linear.app/acme/issue/ENG-12" || iss.State != IssueOpen {
+		t.Errorf("issue = %+v", iss)
+	}
+	if got := strings.Join(iss.Labels, ","); got != "adb:review,priority:P1,frontend" {
+		t.Errorf("labels = %s", got)
+	}
+	if f.auth != "lin_api_test" {
+		t.Errorf("personal API key sent as %q, want it verbatim", f.auth)
+	}
+
+	if _, found, err := tr.Provider.Get("", "ENG", 404); found || err != nil {
+		t.Errorf("missing issue: found=%v err=%v, want not-found", found, err)
+	}
+	f.issues["ENG-13"] = &fakeLinearIssue{StateID: "s6"}
+	if iss, _, _ := tr.Provider.Get("", "ENG", 13); iss.State != IssueClosed || AdbLabelFrom(iss.Labels) != "adb:archived" {
+		t.Errorf("canceled issue = %+v, want closed + adb:archived", iss)
+	}
+}
+
+func TestLinearProvider_CreateAndUpdateSetStateAndPriority(t *testing.T) {
+	f, tr := newTestLinear(t, models.IssueTrackerConfig{
+		Token:     "oauth-token",
+		StatusMap: map[string]string{"blocked": "Todo"},
+	})
+	p := tr.Provider
+
+	created, err := p.Create("", "ENG", RemoteIssue{
+		Title: "Dark mode", Body: "for the dashboard",
+		Labels: []string{StatusLabel(models.TaskStatusInProgress), PriorityLabel(models.PriorityP0)},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	iss := f.issues["ENG-1"]
+	if created.Number != 1 || f.states[iss.StateID] != "In Progress" || iss.Priority != 1 {
+		t.Fatalf("created %+v, stored %+v", created, iss)
+	}
+	if f.auth != "Bearer oauth-token" {
+		t.Errorf("OAuth token sent as %q, want a bearer", f.auth)
+	}
+
+	if _, err := p.Update("", "ENG", 1, RemoteIssue{
+		Title: "Dark mode", Body: "v2", Labels: []string{StatusLabel(models.TaskStatusBlocked), PriorityLabel(models.PriorityP3)},
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if f.states[iss.StateID] != "Todo" || iss.Priority != 4 || iss.Description != "v2" {
+		t.Errorf("after update %+v", iss)
+	}
+	if f.teamQueries != 1 {
+		t.Errorf("team looked up %d times, want once", f.teamQueries)
+	}
+
+	// A team whose workflow lacks the mapped state (fresh provider, so the
+	// team is looked up again).
+	delete(f.states, "s4")
+	p.(*linearProvider).teams = nil
+	_, err = p.Update("", "ENG", 1, RemoteIssue{Labels: []string{StatusLabel(models.TaskStatusReview)}})
+	if err == nil || !strings.Contains(err.Error(), `no workflow state "In Review"`) {
+		t.Errorf("err = %v, want a missing-state error", err)
+	}
+}
+
+func TestSyncer_LinearTrackerPushesLocalEdit(t *testing.T) {
+	f, tr := newTestLinear(t, models.IssueTrackerConfig{})
+	tk := models.Task{
+		ID: "TASK-00050", Title: "Dark mode", Status: models.TaskStatusBacklog, Priority: models.PriorityP2,
+		RemoteIssue: 1, Updated: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	f.issues["ENG-1"] = &fakeLinearIssue{Title: "Dark mode", StateID: "s1", Priority: 3, Updated: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)}
+	tk.SyncHash = SyncHash(tk, "")
+	tk.Status = models.TaskStatusInProgress
+
+	s := &Syncer{
+		Trackers: []Tracker{tr},
+		Body:     func(models.Task) string { return "" },
+		Write:    func(w models.Task) error { tk = w; return nil },
+		Log:      func(string, map[string]interface{}) {},
+	}
+	if res := s.SyncTask(tk, DirectionBoth, false); res.Action != ActionUpdateRemote {
+		t.Fatalf("sync = %+v", res)
+	}
+	if got := f.states[f.issues["ENG-1"].StateID]; got != "In Progress" {
+		t.Errorf("Linear state = %q, want In Progress", got)
+	}
+	if tk.SyncHash != SyncHash(tk, "") {
+		t.Error("baseline not refreshed after push")
+	}
+}
@@ -0,0 +1,219 @@
+package issuesync
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// The synced fields, in the order diffs and conflict records list them.
+const (
+	FieldTitle    = "title"
+	FieldBody     = "body"
+	FieldStatus   = "status"
+	FieldPriority = "priority"
+	FieldLabels   = "labels"
+)
+
+// SyncedFields lists every field the three-way merge covers.
+var SyncedFields = []string{FieldTitle, FieldBody, FieldStatus, FieldPriority, FieldLabels}
+
+// Fields is one side's value of every synced field, and also the shape of
+// the stored per-field baseline (the values both sides last agreed on).
+// Labels are the remote's own labels — adb-owned adb:/priority: labels are
+// Status/Priority here — and adb has no local label editing, so a labels
+// change only ever comes from the remote.
+type Fields struct {
+	Title    string            `yaml:"title"`
+	Body     string            `yaml:"body"`
+	Status   models.TaskStatus `yaml:"status"`
+	Priority models.Priority   `yaml:"priority"`
+	Labels   []string          `yaml:"labels,omitempty"`
+}
+
+// Get returns a field's value as a string: labels sorted and comma-joined,
+// which is for display only (a label may itself contain a comma), so the
+// merge compares and carries labels with Equal and Copy.
+func (f Fields) Get(field string) string {
+	switch field {
+	case FieldTitle:
+		return f.Title
+	case FieldBody:
+		return f.Body
+	case FieldStatus:
+		return string(f.Status)
+	case FieldPriority:
+		return string(f.Priority)
+	case FieldLabels:
+		return strings.Join(sortedLabels(f.Labels), ",")
+	}
+	return ""
+}
+
+// Equal reports whether f and o hold the same value of field. Labels compare
+// as sets.
+func (f Fields) Equal(o Fields, field string) bool {
+	if field == FieldLabels {
+		return slices.Equal(sortedLabels(f.Labels), sortedLabels(o.Labels))
+	}
+	return f.Get(field) == o.Get(field)
+}
+
+// Copy sets field to from's value of it.
+func (f *Fields) Copy(field string, from Fields) {
+	switch field {
+	case FieldTitle:
+		f.Title = from.Title
+	case FieldBody:
+		f.Body = from.Body
+	case FieldStatus:
+		f.Status = from.Status
+	case FieldPriority:
+		f.Priority = from.Priority
+	case FieldLabels:
+		f.Labels = sortedLabels(from.Labels)
+	}
+}
+
+// sortedLabels returns a sorted copy of labels, nil when there are none.
+func sortedLabels(labels []string) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+	out := append([]string(nil), labels...)
+	sort.Strings(out)
+	return out
+}
+
+// LocalFields snapshots a ticket. Labels are carried over from the baseline
+// since the local side never edits them.
+func LocalFields(tk models.Task, body string, labels []string) Fields {
+	return Fields{Title: tk.Title, Body: body, Status: tk.Status, Priority: tk.Priority, Labels: labels}
+}
+
+// RemoteFields snapshots a remote issue through the same mapping a pull
+// uses, with base (normally the stored baseline) filling what the remote
+// cannot express: an issue with no priority: label reports base's priority,
+// and a closed issue keeps base's terminal status — closed means done or
+// archived alike — so neither reads as a change.
+func RemoteFields(r RemoteIssue, base Fields) Fields {
+	f := Fields{
+		Title:    r.Title,
+		Body:     r.Body,
+		Status:   StateToStatus(r.State, AdbLabelFrom(r.Labels)),
+		Priority: base.Priority,
+	}
+	if r.State == IssueClosed && (base.Status == models.TaskStatusDone || base.Status == models.TaskStatusArchived) {
+		f.Status = base.Status
+	}
+	seenPriority := false
+	for _, l := range r.Labels {
+		switch {
+		case strings.HasPrefix(l, priorityLabelPrefix):
+			if !seenPriority {
+				f.Priority, seenPriority = models.Priority(strings.TrimPrefix(l, priorityLabelPrefix)), true
+			}
+		case !isAdbOwnedLabel(l):
+			f.Labels = append(f.Labels, l)
+		}
+	}
+	sort.Strings(f.Labels)
+	return f
+}
+
+// Side says which value a field takes in a merge.
+type Side string
+
+const (
+	SideNone     Side = ""         // unchanged on both sides
+	SideLocal    Side = "local"    // only local changed: push it
+	SideRemote   Side = "remote"   // only remote changed: pull it
+	SideBoth     Side = "both"     // both changed to the same value
+	SideConflict Side = "conflict" // both changed, differently
+)
+
+// FieldChange is one field's three-way comparison. Base, Local and Remote
+// are each side's value as Fields.Get renders it; for labels they are only
+// the display form, and the lists themselves ride in the *Labels fields.
+type FieldChange struct {
+	Field        string   `yaml:"field"`
+	Base         string   `yaml:"base"`
+	Local        string   `yaml:"local"`
+	Remote       string   `yaml:"remote"`
+	BaseLabels   []string `yaml:"base_labels,omitempty"`
+	LocalLabels  []string `yaml:"local_labels,omitempty"`
+	RemoteLabels []string `yaml:"remote_labels,omitempty"`
+	Take         Side     `yaml:"take,omitempty"`
+}
+
+// newFieldChange records field's value on each side.
+func newFieldChange(field string, base, local, remote Fields) FieldChange {
+	c := FieldChange{Field: field, Base: base.Get(field), Local: local.Get(field), Remote: remote.Get(field)}
+	if field == FieldLabels {
+		c.BaseLabels, c.LocalLabels, c.RemoteLabels = sortedLabels(base.Labels), sortedLabels(local.Labels), sortedLabels(remote.Labels)
+	}
+	return c
+}
+
+// LocalValue returns the change's local value as Fields, for Fields.Copy.
+func (c FieldChange) LocalValue() Fields { return c.value(c.Local, c.LocalLabels) }
+
+// RemoteValue returns the change's remote value as Fields, for Fields.Copy.
+func (c FieldChange) RemoteValue() Fields { return c.value(c.Remote, c.RemoteLabels) }
+
+func (c FieldChange) value(v string, labels []string) Fields {
+	var f Fields
+	switch c.Field {
+	case FieldTitle:
+		f.Title = v
+	case FieldBody:
+		f.Body = v
+	case FieldStatus:
+		f.Status = models.TaskStatus(v)
+	case FieldPriority:
+		f.Priority = models.Priority(v)
+	case FieldLabels:
+		f.Labels = labels
+	}
+	return f
+}
+
+// Merge compares every synced field of local and remote against the stored
+// baseline. Like Reconcile it is pure: the Syncer applies the result. Edits
+// to different fields never conflict; only a field both sides changed to
+// different values does.
+func Merge(base, local, remote Fields) []FieldChange {
+	changes := make([]FieldChange, 0, len(SyncedFields))
+	for _, field := range SyncedFields {
+		c := newFieldChange(field, base, local, remote)
+		localChanged, remoteChanged := !local.Equal(base, field), !remote.Equal(base, field)
+		same := local.Equal(remote, field)
+		switch {
+		case same && localChanged:
+			c.Take = SideBoth
+		case same:
+			c.Take = SideNone
+		case localChanged && remoteChanged:
+			c.Take = SideConflict
+		case localChanged:
+			c.Take = SideLocal
+		default:
+			c.Take = SideRemote
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// conflictFields names the fields a merge could not settle.
+func conflictFields(changes []FieldChange) []string {
+	var fields []string
+	for _, c := range changes {
+		if c.Take == SideConflict {
+			fields = append(fields, c.Field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,101 @@
+package issuesync
+
+import (
+	"testing"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+func TestMerge_PerFieldThreeWay(t *testing.T) {
+	base := Fields{Title: "Retry", Body: "b", Status: models.TaskStatusInProgress, Priority: models.PriorityP2, Labels: []string{"infra"}}
+
+	local := base
+	local.Title, local.Status, local.Priority = "Retry deliveries", models.TaskStatusReview, models.PriorityP1
+	remote := base
+	remote.Body, remote.Status, remote.Priority = "b2", models.TaskStatusReview, models.PriorityP0
+	remote.Labels = []string{"infra", "needs-design"}
+
+	want := map[string]Side{
+		FieldTitle:    SideLocal,    // only local changed
+		FieldBody:     SideRemote,   // only remote changed
+		FieldStatus:   SideBoth,     // both changed, to the same value
+		FieldPriority: SideConflict, // both changed, differently
+		FieldLabels:   SideRemote,
+	}
+	changes := Merge(base, local, remote)
+	if len(changes) != len(SyncedFields) {
+		t.Fatalf("got %d changes, want one per synced field", len(changes))
+	}
+	for _, c := range changes {
+		if c.Take != want[c.Field] {
+			t.Errorf("%s: take %q, want %q (%+v)", c.Field, c.Take, want[c.Field], c)
+		}
+	}
+	if got := conflictFields(changes); len(got) != 1 || got[0] != FieldPriority {
+		t.Errorf("conflictFields = %v", got)
+	}
+	if c := changes[4]; c.Base != "infra" || c.Remote != "infra,needs-design" {
+		t.Errorf("labels compare as sorted sets: %+v", c)
+	}
+
+	if changes := Merge(base, base, base); conflictFields(changes) != nil || changes[0].Take != SideNone {
+		t.Errorf("unchanged sides: %+v", changes)
+	}
+}
+
+func TestRemoteFields_UsesBaseForWhatTheRemoteCannotSay(t *testing.T) {
+	base := Fields{Status: models.TaskStatusArchived, Priority: models.PriorityP1}
+
+	f := RemoteFields(RemoteIssue{Title: "t", State: IssueClosed, Labels: []string{"adb:archived", "bug"}}, base)
+	if f.Status != models.TaskStatusArchived || f.Priority != models.PriorityP1 {
+		t.Errorf("closed issue without priority label = %+v, want base's archived/P1", f)
+	}
+	if len(f.Labels) != 1 || f.Labels[0] != "bug" {
+		t.Errorf("labels = %v, want only the maintainer's", f.Labels)
+	}
+
+	f = RemoteFields(RemoteIssue{State: IssueOpen, Labels: []string{"priority:P3", "adb:blocked", "priority:P0"}}, base)
+	if f.Status != models.TaskStatusBlocked || f.Priority != models.PriorityP3 {
+		t.Errorf("open issue = %+v, want blocked/P3 (first priority label)", f)
+	}
+	if f := RemoteFields(RemoteIssue{State: IssueClosed}, Fields{Status: models.TaskStatusReview}); f.Status != models.TaskStatusDone {
+		t.Errorf("closing an active ticket's issue reads as %q, want done", f.Status)
+	}
+}
+
+func TestFields_CopyKeepsLabelsAsAList(t *testing.T) {
+	f := Fields{Title: "t", Body: "b", Status: models.TaskStatusBlocked, Priority: models.PriorityP0, Labels: []string{"z", "area: api, web"}}
+	var g Fields
+	for _, field := range SyncedFields {
+		g.Copy(field, f)
+	}
+	for _, field := range SyncedFields {
+		if !g.Equal(f, field) {
+			t.Errorf("%s: %q != %q", field, g.Get(field), f.Get(field))
+		}
+	}
+	if len(g.Labels) != 2 || g.Labels[0] != "area: api, web" {
+		t.Errorf("labels = %q, want the comma-bearing label kept whole", g.Labels)
+	}
+	g.Copy(FieldLabels, Fields{})
+	if g.Labels != nil {
+		t.Errorf("empty labels = %v", g.Labels)
+	}
+}
+
+func TestMerge_LabelsSurviveAConflictRoundTrip(t *testing.T) {
+	base := Fields{Labels: []string{"a,b"}}
+	local, remote := base, base
+	local.Labels = []string{"a", "b"} // joins to the same string as the base
+	remote.Labels = []string{"c"}
+
+	c := Merge(base, local, remote)[4]
+	if c.Field != FieldLabels || c.Take != SideConflict {
+		t.Fatalf("labels change = %+v, want a conflict: [a b] is not [a,b]", c)
+	}
+	var resolved Fields
+	resolved.Copy(FieldLabels, c.LocalValue())
+	if len(resolved.Labels) != 2 || resolved.Labels[0] != "a" || resolved.Labels[1] != "b" {
+		t.Errorf("local labels after a round trip = %q", resolved.Labels)
+	}
+}
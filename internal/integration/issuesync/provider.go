@@ -1,11 +1,15 @@
-// Package issuesync reconciles adb tickets with GitHub/GitLab issues.
+// Package issuesync reconciles adb tickets with GitHub/GitLab issues, or
+// with a Jira/Linear project configured under issue_trackers.
 //
-// Providers shell out to the host's `gh` / `glab` (the same os/exec model
-// used by internal/integration/reposync.go — this package is the FIRST gh/glab
-// consumer in adb; there is no prior gh precedent). Auth is per-host and
+// The GitHub/GitLab providers shell out to the host's `gh` / `glab` (the same
+// os/exec model used by internal/integration/reposync.go — this package is
+// the FIRST gh/glab consumer in adb; there is no prior gh precedent). Auth is per-host and
 // owned by the CLIs; we never read ~/.config/gh/hosts.yml, never accept a
 // --token flag, and never write a token or PII into backlog.yaml, status.yaml,
-// or the .events.jsonl event log.
+// or the .events.jsonl event log. The Jira/Linear providers (jira.go,
+// linear.go) call their REST/GraphQL APIs directly with a token resolved
+// from the `$ENV_VAR` named in config; it is held in memory only and never
+// appears in an error or event payload.
 //
 // Reconcile is pure and last-writer-wins over a fixed synced-fields allowlist
 // (title, body, labels, status, priority). Status maps as
@@ -41,14 +45,16 @@ type RemoteIssue struct {
 	UpdatedAt time.Time
 }
 
-// Provider is the GitHub/GitLab seam. owner/name are the <org>/<repo> pair
-// resolved from the ticket's platform-qualified Repo (canonicalised at
+// Provider is the issue-tracker seam. For GitHub/GitLab owner/name are the
+// <org>/<repo> pair resolved from the ticket's platform-qualified Repo (canonicalised at
 // task-create time by DefaultGitWorktreeManager.NormalizeRepoPath, so an
-// unlinked ticket's Repo is already `github.com/org/name` for github tasks).
+// unlinked ticket's Repo is already `github.com/org/name` for github tasks);
+// for a Tracker, owner is empty and name is the Jira project / Linear team key.
 // All methods are allowed to shell out; unit tests use a fake implementation
 // (see issuesync_test.go's fakeProvider).
 type Provider interface {
-	// Name identifies the backend for logging ("github" / "gitlab").
+	// Name identifies the backend for logging ("github", "gitlab", "jira",
+	// "linear").
 	Name() string
 	// Get fetches the issue by number. found=false (nil error) when number is
 	// 0 or the remote has no such issue — callers use this to decide between
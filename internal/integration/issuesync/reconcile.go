@@ -28,6 +28,8 @@ const (
 	ActionCreateRemote Action = "create_remote"
 	ActionUpdateRemote Action = "update_remote"
 	ActionUpdateLocal  Action = "update_local"
+	ActionMerge        Action = "merge"    // field-level merge wrote both sides
+	ActionConflict     Action = "conflict" // field-level conflict, nothing else to write
 )
 
 // Input bundles everything Reconcile needs. It is deliberately I/O-free so
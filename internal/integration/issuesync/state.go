@@ -0,0 +1,237 @@
+package issuesync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/lockfile"
+	"gopkg.in/yaml.v3"
+)
+
+// Conflict is an open field-level conflict: fields both sides changed to
+// different values since the last agreed baseline. It stays open — and the
+// fields stay unsynced — until `adb sync issues resolve` picks a side.
+type Conflict struct {
+	TaskID     string        `yaml:"task_id"`
+	Repo       string        `yaml:"repo,omitempty"`
+	Provider   string        `yaml:"provider,omitempty"`
+	DetectedAt time.Time     `yaml:"detected_at"`
+	Fields     []FieldChange `yaml:"fields"`
+}
+
+// StateStore persists the per-field sync baselines, open conflicts and the
+// newest remote updated_at applied per task to a YAML file under .adb/.
+// `adb sync issues`, the webhook listener and a resolve can overlap, so
+// every mutation holds a sidecar flock (the backlog.yaml pattern) across
+// load → modify → save, and writes land via temp file + rename.
+type StateStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+type stateFile struct {
+	Baselines map[string]Fields `yaml:"baselines,omitempty"`
+	Conflicts []Conflict        `yaml:"conflicts,omitempty"`
+	// RemoteUpdated is the newest remote updated_at applied per task, so a
+	// webhook delivered out of order cannot roll a ticket back.
+	RemoteUpdated map[string]time.Time `yaml:"remote_updated,omitempty"`
+}
+
+// NewStateStore returns a StateStore persisted at path (normally
+// statedir.Path(base, statedir.FileIssueSyncState)).
+func NewStateStore(path string) *StateStore {
+	return &StateStore{path: path}
+}
+
+// Baseline returns the last agreed field values for a task.
+func (s *StateStore) Baseline(taskID string) (Fields, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, err := s.load()
+	if err != nil {
+		return Fields{}, false, err
+	}
+	f, ok := st.Baselines[taskID]
+	return f, ok, nil
+}
+
+// RemoteUpdated returns the newest remote updated_at applied to a task.
+func (s *StateStore) RemoteUpdated(taskID string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, err := s.load()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	t, ok := st.RemoteUpdated[taskID]
+	return t, ok, nil
+}
+
+// Conflict returns the open conflict for a task, if any.
+func (s *StateStore) Conflict(taskID string) (Conflict, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, err := s.load()
+	if err != nil {
+		return Conflict{}, false, err
+	}
+	for _, c := range st.Conflicts {
+		if c.TaskID == taskID {
+			return c, true, nil
+		}
+	}
+	return Conflict{}, false, nil
+}
+
+// Conflicts returns every open conflict, sorted by task ID.
+func (s *StateStore) Conflicts() ([]Conflict, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return st.Conflicts, nil
+}
+
+// Commit records a task's new baseline and replaces its open conflict: a
+// nil conflict clears it. A conflict already open keeps its DetectedAt.
+// remoteUpdated is the updated_at of the remote issue the baseline was
+// reconciled against; it only ever moves forward, and a zero time (no
+// remote yet) leaves it as it was.
+func (s *StateStore) Commit(taskID string, base Fields, conflict *Conflict, remoteUpdated time.Time) error {
+	return s.update(func(st *stateFile) {
+		st.Baselines[taskID] = base
+		if remoteUpdated.After(st.RemoteUpdated[taskID]) {
+			st.RemoteUpdated[taskID] = remoteUpdated.UTC()
+		}
+		var open *Conflict
+		kept := st.Conflicts[:0]
+		for i := range st.Conflicts {
+			if st.Conflicts[i].TaskID == taskID {
+				c := st.Conflicts[i]
+				open = &c
+				continue
+			}
+			kept = append(kept, st.Conflicts[i])
+		}
+		st.Conflicts = kept
+		if conflict != nil {
+			c := *conflict
+			c.TaskID = taskID
+			if open != nil && !open.DetectedAt.IsZero() {
+				c.DetectedAt = open.DetectedAt
+			}
+			st.Conflicts = append(st.Conflicts, c)
+		}
+	})
+}
+
+// Resolve settles a task's open conflict. For each field, taking local
+// rewrites the baseline to the remote value (and vice versa), so the next
+// sync sees only the chosen side as changed and carries it across. Every
+// conflicting field needs a side; the conflict is then cleared.
+func (s *StateStore) Resolve(taskID string, take map[string]Side) error {
+	var rerr error
+	err := s.update(func(st *stateFile) {
+		idx := -1
+		for i, c := range st.Conflicts {
+			if c.TaskID == taskID {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			rerr = fmt.Errorf("no open issue-sync conflict for %s", taskID)
+			return
+		}
+		base := st.Baselines[taskID]
+		var missing []string
+		for _, fc := range st.Conflicts[idx].Fields {
+			switch take[fc.Field] {
+			case SideLocal:
+				base.Copy(fc.Field, fc.RemoteValue())
+			case SideRemote:
+				base.Copy(fc.Field, fc.LocalValue())
+			default:
+				missing = append(missing, fc.Field)
+			}
+		}
+		if len(missing) > 0 {
+			rerr = fmt.Errorf("%s: no side chosen for conflicting field(s) %v", taskID, missing)
+			return
+		}
+		st.Baselines[taskID] = base
+		st.Conflicts = append(st.Conflicts[:idx], st.Conflicts[idx+1:]...)
+	})
+	if err != nil {
+		return err
+	}
+	return rerr
+}
+
+// update runs fn over the state under the file lock and saves the result.
+func (s *StateStore) update(fn func(*stateFile)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create issue-sync state directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open issue-sync state lock: %w", err)
+	}
+	defer f.Close()
+	unlock, err := lockfile.Lock(f)
+	if err != nil {
+		return fmt.Errorf("lock issue-sync state: %w", err)
+	}
+	defer unlock()
+
+	st, err := s.load()
+	if err != nil {
+		return err
+	}
+	fn(st)
+	return s.save(st)
+}
+
+func (s *StateStore) load() (*stateFile, error) {
+	st := &stateFile{}
+	data, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read issue-sync state: %w", err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("parse issue-sync state: %w", err)
+		}
+	}
+	if st.Baselines == nil {
+		st.Baselines = make(map[string]Fields)
+	}
+	if st.RemoteUpdated == nil {
+		st.RemoteUpdated = make(map[string]time.Time)
+	}
+	return st, nil
+}
+
+func (s *StateStore) save(st *stateFile) error {
+	sort.Slice(st.Conflicts, func(i, j int) bool { return st.Conflicts[i].TaskID < st.Conflicts[j].TaskID })
+	data, err := yaml.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("marshal issue-sync state: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write issue-sync state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("rename issue-sync state: %w", err)
+	}
+	return nil
+}
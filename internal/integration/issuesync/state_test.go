@@ -0,0 +1,57 @@
+package issuesync
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+func TestStateStore_CommitAndResolve(t *testing.T) {
+	st := NewStateStore(filepath.Join(t.TempDir(), "issue_sync_state.yaml"))
+	if _, ok, err := st.Baseline("TASK-1"); ok || err != nil {
+		t.Fatalf("empty store: ok=%v err=%v", ok, err)
+	}
+
+	base := Fields{Title: "Retry", Status: models.TaskStatusInProgress, Priority: models.PriorityP2}
+	first := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	conflict := &Conflict{Repo: "github.com/acme/widgets", Provider: "github", DetectedAt: first, Fields: []FieldChange{
+		{Field: FieldTitle, Base: "Retry", Local: "Retry deliveries", Remote: "Retry webhooks", Take: SideConflict},
+		{Field: FieldPriority, Base: "P2", Local: "P1", Remote: "P0", Take: SideConflict},
+	}}
+	if err := st.Commit("TASK-1", base, conflict, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	// Seen again on a later run: the record keeps its first detection time.
+	again := *conflict
+	again.DetectedAt = first.Add(time.Hour)
+	if err := st.Commit("TASK-1", base, &again, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, _ := st.Conflict("TASK-1")
+	if !ok || got.TaskID != "TASK-1" || !got.DetectedAt.Equal(first) || len(got.Fields) != 2 {
+		t.Fatalf("conflict = %+v", got)
+	}
+
+	err := st.Resolve("TASK-1", map[string]Side{FieldTitle: SideLocal})
+	if err == nil || !strings.Contains(err.Error(), "priority") {
+		t.Fatalf("partial resolve err = %v, want priority reported", err)
+	}
+	if err := st.Resolve("TASK-1", map[string]Side{FieldTitle: SideLocal, FieldPriority: SideRemote}); err != nil {
+		t.Fatal(err)
+	}
+	b, _, _ := st.Baseline("TASK-1")
+	// Taking local sets the baseline to the remote value (so only local
+	// reads as changed), and vice versa.
+	if b.Title != "Retry webhooks" || b.Priority != models.PriorityP1 || b.Status != models.TaskStatusInProgress {
+		t.Errorf("resolved baseline = %+v", b)
+	}
+	if cs, _ := st.Conflicts(); len(cs) != 0 {
+		t.Errorf("conflicts after resolve = %+v", cs)
+	}
+	if err := st.Resolve("TASK-1", map[string]Side{FieldTitle: SideLocal}); err == nil {
+		t.Error("resolving with no open conflict should fail")
+	}
+}
@@ -1,6 +1,10 @@
 package issuesync
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
 	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
 )
@@ -15,8 +19,12 @@ import (
 //     of keys the CLI wraps into the payload is fixed and audited by
 //     TestSyncer_EventPayloadHasNoCredentials — auth-safety guard.
 type Syncer struct {
-	// provider selects a Provider for a repo; defaults to ProviderFor when nil.
+	// provider selects a Provider for a repo; defaults to Trackers, then
+	// ProviderFor, when nil.
 	provider func(repo string) (Provider, string, string, bool)
+	// Trackers route matching tickets to a Jira/Linear project ahead of the
+	// repo's own GitHub/GitLab issues (first match wins).
+	Trackers []Tracker
 	// Body returns the issue body for a task (adb keeps it in context.md).
 	Body func(models.Task) string
 	// WriteBody persists a pulled remote body back to the ticket's context.md.
@@ -28,19 +36,32 @@ type Syncer struct {
 	Write func(models.Task) error
 	// Log records a reconcile decision. Callers wrap this over App.EventLog.Log.
 	Log func(event string, data map[string]interface{})
+	// State holds the per-field baselines and open conflicts. Optional: when
+	// nil every sync is whole-task last-writer-wins (Reconcile).
+	State *StateStore
 }
 
 // Result is the per-ticket outcome (mirrors Decision + the resolved link).
+// Changes is the per-field diff behind it (dry-run output); Conflicts names
+// the fields left for `adb sync issues resolve`. Stale marks a webhook
+// snapshot that was not newer than the remote state already applied, and
+// so was ignored.
 type Result struct {
-	TaskID string
-	Action Action
-	Reason string
+	TaskID    string
+	Action    Action
+	Reason    string
+	Changes   []FieldChange
+	Conflicts []string
+	Stale     bool
 }
 
 func (s *Syncer) providerFor(repo string) (Provider, string, string, bool) {
 	if s.provider != nil {
 		return s.provider(repo)
 	}
+	if t, ok := TrackerFor(s.Trackers, repo); ok {
+		return t.Provider, "", t.Project, true
+	}
 	return ProviderFor(repo)
 }
 
@@ -61,7 +82,6 @@ func (s *Syncer) SyncTask(tk models.Task, dir Direction, dryRun bool) Result {
 		return Result{TaskID: tk.ID, Action: ActionNoop, Reason: "no gh/glab remote"}
 	}
 
-	body := s.Body(tk)
 	remote, found, err := p.Get(owner, name, tk.RemoteIssue)
 	if err != nil {
 		// A provider error is logged as a conflict so an operator sees
@@ -76,6 +96,75 @@ func (s *Syncer) SyncTask(tk models.Task, dir Direction, dryRun bool) Result {
 		})
 		return Result{TaskID: tk.ID, Action: ActionNoop, Reason: "provider get failed: " + err.Error()}
 	}
+	return s.reconcile(tk, p, owner, name, remote, found, dir, dryRun)
+}
+
+// SyncTaskFromRemote reconciles one ticket against a remote snapshot the
+// caller already holds — an issue webhook's payload — instead of fetching it
+// with the provider. Pushes still go through the provider, and the same
+// events are logged as for SyncTask.
+//
+// Deliveries can arrive out of order or be redelivered, so with a State
+// wired a snapshot whose updated_at is not newer than the last one applied
+// to the ticket is ignored (Result.Stale) rather than rolling it back.
+func (s *Syncer) SyncTaskFromRemote(tk models.Task, remote RemoteIssue, dir Direction, dryRun bool) Result {
+	p, owner, name, ok := s.providerFor(tk.Repo)
+	if !ok {
+		s.Log(string(observability.EventIssueSkipped), map[string]interface{}{
+			"task_id": tk.ID,
+			"repo":    tk.Repo,
+		})
+		return Result{TaskID: tk.ID, Action: ActionNoop, Reason: "no gh/glab remote"}
+	}
+	if s.State != nil && !remote.UpdatedAt.IsZero() {
+		applied, seen, err := s.State.RemoteUpdated(tk.ID)
+		if err != nil {
+			s.Log(string(observability.EventIssueConflict), map[string]interface{}{
+				"task_id":  tk.ID,
+				"repo":     tk.Repo,
+				"provider": p.Name(),
+				"error":    err.Error(),
+			})
+			return Result{TaskID: tk.ID, Action: ActionNoop, Reason: "load sync state: " + err.Error()}
+		}
+		if seen && !remote.UpdatedAt.After(applied) {
+			reason := fmt.Sprintf("stale delivery: updated %s, already applied %s",
+				remote.UpdatedAt.UTC().Format(time.RFC3339), applied.UTC().Format(time.RFC3339))
+			s.Log(string(observability.EventIssueSkipped), map[string]interface{}{
+				"task_id":  tk.ID,
+				"repo":     tk.Repo,
+				"provider": p.Name(),
+				"action":   string(ActionNoop),
+				"reason":   reason,
+			})
+			return Result{TaskID: tk.ID, Action: ActionNoop, Reason: reason, Stale: true}
+		}
+	}
+	return s.reconcile(tk, p, owner, name, remote, true, dir, dryRun)
+}
+
+// reconcile decides and applies the sync for tk given the remote issue. A
+// linked ticket with a stored per-field baseline is merged field by field;
+// otherwise (no State, first sync, or a link made before per-field
+// baselines) it falls back to whole-task last-writer-wins, and seeds the
+// baseline once that sync succeeds.
+func (s *Syncer) reconcile(tk models.Task, p Provider, owner, name string, remote RemoteIssue, found bool, dir Direction, dryRun bool) Result {
+	body := s.Body(tk)
+	if found && remote.Number != 0 && s.State != nil {
+		base, ok, err := s.State.Baseline(tk.ID)
+		if err != nil {
+			s.Log(string(observability.EventIssueConflict), map[string]interface{}{
+				"task_id":  tk.ID,
+				"repo":     tk.Repo,
+				"provider": p.Name(),
+				"error":    err.Error(),
+			})
+			return Result{TaskID: tk.ID, Action: ActionNoop, Reason: "load sync baseline: " + err.Error()}
+		}
+		if ok {
+			return s.merge(tk, body, base, p, owner, name, remote, dir, dryRun)
+		}
+	}
 
 	d := Reconcile(Input{
 		Local: tk, Body: body, Remote: remote, RemoteFound: found,
@@ -99,7 +188,19 @@ func (s *Syncer) SyncTask(tk models.Task, dir Direction, dryRun bool) Result {
 		"reason":   d.Reason,
 	})
 
-	if dryRun || d.Action == ActionNoop {
+	var remoteLabels []string
+	if found {
+		remoteLabels = RemoteFields(remote, LocalFields(tk, body, nil)).Labels
+	}
+	if dryRun {
+		return Result{TaskID: tk.ID, Action: d.Action, Reason: d.Reason, Changes: lwwChanges(d.Action, LocalFields(tk, body, remoteLabels), remote, found)}
+	}
+	if d.Action == ActionNoop {
+		// A linked ticket already in sync seeds its per-field baseline, so
+		// links made before per-field baselines move onto the merge path.
+		if found && tk.SyncHash != "" && SyncHash(tk, body) == tk.SyncHash {
+			s.commitState(tk.ID, LocalFields(tk, body, remoteLabels), nil, remote.UpdatedAt)
+		}
 		return Result{TaskID: tk.ID, Action: d.Action, Reason: d.Reason}
 	}
 
@@ -117,6 +218,7 @@ func (s *Syncer) SyncTask(tk models.Task, dir Direction, dryRun bool) Result {
 			return Result{TaskID: tk.ID, Action: ActionNoop, Reason: cerr.Error()}
 		}
 		tk.RemoteIssue, tk.RemoteURL = created.Number, created.URL
+		remoteLabels = nil
 	case ActionUpdateRemote:
 		if _, uerr := p.Update(owner, name, tk.RemoteIssue, want); uerr != nil {
 			return Result{TaskID: tk.ID, Action: ActionNoop, Reason: uerr.Error()}
@@ -147,5 +249,179 @@ func (s *Syncer) SyncTask(tk models.Task, dir Direction, dryRun bool) Result {
 	if werr := s.Write(tk); werr != nil {
 		return Result{TaskID: tk.ID, Action: d.Action, Reason: "write-back failed: " + werr.Error()}
 	}
+	s.commitState(tk.ID, LocalFields(tk, body, remoteLabels), nil, remote.UpdatedAt)
 	return Result{TaskID: tk.ID, Action: d.Action, Reason: d.Reason}
 }
+
+// merge applies a field-level three-way merge against the stored baseline:
+// fields only one side changed are carried to the other, fields both sides
+// changed identically are settled, and fields both changed differently are
+// left as they are on each side and recorded as a Conflict for `adb sync
+// issues resolve`. The baseline advances only for settled fields, so an
+// open conflict (or a write --direction vetoed) is seen again next run.
+func (s *Syncer) merge(tk models.Task, body string, base Fields, p Provider, owner, name string, remote RemoteIssue, dir Direction, dryRun bool) Result {
+	local := LocalFields(tk, body, base.Labels)
+	rem := RemoteFields(remote, base)
+	changes := Merge(base, local, rem)
+
+	newLocal, newRemote, newBase := local, rem, base
+	var pulled, pushed, held []string
+	for _, c := range changes {
+		switch c.Take {
+		case SideBoth:
+			newBase.Copy(c.Field, local)
+		case SideRemote:
+			if !dir.canPull() || (c.Field == FieldBody && s.WriteBody == nil) {
+				held = append(held, c.Field)
+				continue
+			}
+			newLocal.Copy(c.Field, rem)
+			newBase.Copy(c.Field, rem)
+			pulled = append(pulled, c.Field)
+		case SideLocal:
+			if !dir.canPush() {
+				held = append(held, c.Field)
+				continue
+			}
+			newRemote.Copy(c.Field, local)
+			newBase.Copy(c.Field, local)
+			pushed = append(pushed, c.Field)
+		}
+	}
+	conflicts := conflictFields(changes)
+	// Labels only live remotely: pulling them just advances the baseline.
+	pullsLocal := len(pulled) > 1 || (len(pulled) == 1 && pulled[0] != FieldLabels)
+
+	action := ActionNoop
+	switch {
+	case pullsLocal && len(pushed) > 0:
+		action = ActionMerge
+	case len(pushed) > 0:
+		action = ActionUpdateRemote
+	case pullsLocal:
+		action = ActionUpdateLocal
+	case len(conflicts) > 0:
+		action = ActionConflict
+	}
+	reason := mergeReason(pulled, pushed, conflicts, held)
+
+	evt := observability.EventIssueSynced
+	if len(conflicts) > 0 {
+		evt = observability.EventIssueConflict
+	}
+	s.Log(string(evt), map[string]interface{}{
+		"task_id":  tk.ID,
+		"repo":     tk.Repo,
+		"provider": p.Name(),
+		"action":   string(action),
+		"reason":   reason,
+	})
+	res := Result{TaskID: tk.ID, Action: action, Reason: reason, Changes: changes, Conflicts: conflicts}
+	if dryRun {
+		return res
+	}
+
+	if len(pushed) > 0 {
+		want := RemoteIssue{
+			Title:  newRemote.Title,
+			Body:   newRemote.Body,
+			Labels: []string{StatusLabel(newRemote.Status), PriorityLabel(newRemote.Priority)},
+			State:  StatusToState(newRemote.Status),
+		}
+		if _, uerr := p.Update(owner, name, tk.RemoteIssue, want); uerr != nil {
+			return Result{TaskID: tk.ID, Action: ActionNoop, Reason: uerr.Error(), Changes: changes}
+		}
+	}
+	if pullsLocal || len(pushed) > 0 {
+		tk.Title, tk.Status, tk.Priority = newLocal.Title, newLocal.Status, newLocal.Priority
+		tk.RemoteURL = remote.URL
+		if newLocal.Body != body {
+			if werr := s.WriteBody(tk, newLocal.Body); werr != nil {
+				return Result{TaskID: tk.ID, Action: ActionNoop, Reason: "write-body failed: " + werr.Error(), Changes: changes}
+			}
+		}
+		tk.SyncHash = SyncHash(tk, newLocal.Body)
+		tk.LastSynced = tk.Updated
+		if werr := s.Write(tk); werr != nil {
+			res.Reason = "write-back failed: " + werr.Error()
+			return res
+		}
+	}
+
+	var conflict *Conflict
+	if len(conflicts) > 0 {
+		conflict = &Conflict{Repo: tk.Repo, Provider: p.Name(), DetectedAt: time.Now().UTC()}
+		for _, c := range changes {
+			if c.Take == SideConflict {
+				conflict.Fields = append(conflict.Fields, c)
+			}
+		}
+	}
+	s.commitState(tk.ID, newBase, conflict, remote.UpdatedAt)
+	return res
+}
+
+// commitState records a baseline (and conflict), and the remote updated_at
+// it was reconciled against, when a State is wired. Failures only cost the
+// merge path one run — the ticket falls back to last-writer-wins — so they
+// are logged, not returned.
+func (s *Syncer) commitState(taskID string, base Fields, conflict *Conflict, remoteUpdated time.Time) {
+	if s.State == nil {
+		return
+	}
+	if err := s.State.Commit(taskID, base, conflict, remoteUpdated); err != nil {
+		s.Log(string(observability.EventIssueConflict), map[string]interface{}{
+			"task_id": taskID,
+			"error":   "save sync baseline: " + err.Error(),
+		})
+	}
+}
+
+// mergeReason summarises a merge for the event log and CLI output, e.g.
+// "pull title, status; push body; conflict on priority".
+func mergeReason(pulled, pushed, conflicts, held []string) string {
+	var parts []string
+	if len(pulled) > 0 {
+		parts = append(parts, "pull "+strings.Join(pulled, ", "))
+	}
+	if len(pushed) > 0 {
+		parts = append(parts, "push "+strings.Join(pushed, ", "))
+	}
+	if len(conflicts) > 0 {
+		parts = append(parts, "conflict on "+strings.Join(conflicts, ", "))
+	}
+	if len(held) > 0 {
+		parts = append(parts, "held by direction: "+strings.Join(held, ", "))
+	}
+	if len(parts) == 0 {
+		return "in sync"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// lwwChanges is the dry-run diff for a last-writer-wins decision, which has
+// no baseline: every field that differs takes the winning side.
+func lwwChanges(action Action, local Fields, remote RemoteIssue, found bool) []FieldChange {
+	var take Side
+	switch action {
+	case ActionCreateRemote, ActionUpdateRemote:
+		take = SideLocal
+	case ActionUpdateLocal:
+		take = SideRemote
+	default:
+		return nil
+	}
+	var rem Fields
+	if found {
+		rem = RemoteFields(remote, local)
+	}
+	var changes []FieldChange
+	for _, field := range SyncedFields {
+		if !local.Equal(rem, field) {
+			c := newFieldChange(field, Fields{}, local, rem)
+			c.Take = take
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
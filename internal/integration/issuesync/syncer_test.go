@@ -2,6 +2,7 @@ package issuesync
 
 import (
 	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ type fakeProvider struct {
 	found   bool
 	getErr  error
 	created RemoteIssue
+	updated RemoteIssue // last Update's wanted issue
 	calls   []string
 }
 
@@ -31,6 +33,7 @@ func (f *fakeProvider) Create(o, n string, w RemoteIssue) (RemoteIssue, error) {
 }
 func (f *fakeProvider) Update(o, n string, num int, w RemoteIssue) (RemoteIssue, error) {
 	f.calls = append(f.calls, "update")
+	f.updated = w
 	return w, nil
 }
 
@@ -366,3 +369,149 @@ func contains(s, sub string) bool {
 	}
 	return false
 }
+
+// newMergeSyncer wires a Syncer with a file-backed StateStore whose
+// baseline for tk is base; written captures the last backlog write.
+func newMergeSyncer(t *testing.T, fp *fakeProvider, tk models.Task, body string, base Fields, written *models.Task, logged *[]loggedEvent) *Syncer {
+	t.Helper()
+	st := NewStateStore(filepath.Join(t.TempDir(), "issue_sync_state.yaml"))
+	if err := st.Commit(tk.ID, base, nil, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	bodies := map[string]string{tk.ID: body}
+	return &Syncer{
+		provider:  func(string) (Provider, string, string, bool) { return fp, "acme", "widgets", true },
+		Body:      func(t models.Task) string { return bodies[t.ID] },
+		WriteBody: func(t models.Task, b string) error { bodies[t.ID] = b; return nil },
+		Write:     func(t models.Task) error { *written = t; return nil },
+		Log:       func(evt string, data map[string]interface{}) { *logged = append(*logged, loggedEvent{evt, data}) },
+		State:     st,
+	}
+}
+
+// TestSyncer_MergesNonOverlappingEdits: the local side retitles while a
+// teammate labels the issue blocked — both edits land, with no conflict,
+// where whole-task LWW would have discarded one of them.
+func TestSyncer_MergesNonOverlappingEdits(t *testing.T) {
+	base := Fields{Title: "Retry", Body: "b", Status: models.TaskStatusInProgress, Priority: models.PriorityP1}
+	tk := models.Task{ID: "TASK-00042", Title: "Retry deliveries", Status: models.TaskStatusInProgress,
+		Priority: models.PriorityP1, Repo: "github.com/acme/widgets", RemoteIssue: 42}
+	fp := &fakeProvider{name: "github", found: true, get: RemoteIssue{
+		Number: 42, URL: "https://github.com/acme/widgets/issues/42", Title: "Retry", Body: "b", State: IssueOpen,
+		Labels: []string{"adb:blocked", "priority:P1", "needs-design"},
+	}}
+	var (
+		written models.Task
+		logged  []loggedEvent
+	)
+	s := newMergeSyncer(t, fp, tk, "b", base, &written, &logged)
+
+	res := s.SyncTask(tk, DirectionBoth, false)
+	if res.Action != ActionMerge || res.Reason != "pull status, labels; push title" || len(res.Conflicts) != 0 {
+		t.Fatalf("result = %+v", res)
+	}
+	if fp.updated.Title != "Retry deliveries" || AdbLabelFrom(fp.updated.Labels) != "adb:blocked" {
+		t.Errorf("pushed %+v, want the local title with the remote's status kept", fp.updated)
+	}
+	if written.Title != "Retry deliveries" || written.Status != models.TaskStatusBlocked || written.SyncHash != SyncHash(written, "b") {
+		t.Errorf("written task = %+v", written)
+	}
+	nb, _, _ := s.State.Baseline(tk.ID)
+	if nb.Title != "Retry deliveries" || nb.Status != models.TaskStatusBlocked || nb.Get(FieldLabels) != "needs-design" {
+		t.Errorf("baseline = %+v, want every field settled", nb)
+	}
+	if len(logged) != 1 || logged[0].Event != string(observability.EventIssueSynced) {
+		t.Errorf("logged %+v, want one issue.synced", logged)
+	}
+}
+
+// TestSyncer_FieldConflictRecordedThenResolved: both sides retitle
+// differently while the teammate also edits the body. The body still merges;
+// the title is recorded as a conflict and re-reported until resolved, after
+// which the chosen side is carried across.
+func TestSyncer_FieldConflictRecordedThenResolved(t *testing.T) {
+	base := Fields{Title: "Retry", Body: "b", Status: models.TaskStatusInProgress, Priority: models.PriorityP1}
+	tk := models.Task{ID: "TASK-00042", Title: "Retry deliveries", Status: models.TaskStatusInProgress,
+		Priority: models.PriorityP1, Repo: "github.com/acme/widgets", RemoteIssue: 42}
+	fp := &fakeProvider{name: "github", found: true, get: RemoteIssue{
+		Number: 42, Title: "Retry webhooks", Body: "b2", State: IssueOpen, Labels: []string{"adb:in_progress", "priority:P1"},
+	}}
+	var (
+		written models.Task
+		logged  []loggedEvent
+	)
+	s := newMergeSyncer(t, fp, tk, "b", base, &written, &logged)
+
+	res := s.SyncTask(tk, DirectionBoth, true)
+	if res.Action != ActionUpdateLocal || len(res.Conflicts) != 1 || res.Conflicts[0] != FieldTitle {
+		t.Fatalf("dry-run = %+v", res)
+	}
+	if _, open, _ := s.State.Conflict(tk.ID); open || written.ID != "" {
+		t.Fatal("dry-run must not record the conflict or write")
+	}
+
+	res = s.SyncTask(tk, DirectionBoth, false)
+	if res.Reason != "pull body; conflict on title" {
+		t.Fatalf("result = %+v", res)
+	}
+	if written.Title != "Retry deliveries" || s.Body(written) != "b2" {
+		t.Errorf("written = %+v body %q, want local title kept and remote body pulled", written, s.Body(written))
+	}
+	c, open, _ := s.State.Conflict(tk.ID)
+	if !open || len(c.Fields) != 1 || c.Fields[0].Local != "Retry deliveries" || c.Fields[0].Remote != "Retry webhooks" {
+		t.Fatalf("conflict record = %+v", c)
+	}
+	if ev := logged[len(logged)-1]; ev.Event != string(observability.EventIssueConflict) || ev.Data["reason"] != res.Reason {
+		t.Errorf("last event = %+v", ev)
+	}
+
+	// Unresolved: the next run reports the same conflict and writes nothing.
+	tk = written
+	fp.get.Body = "b2"
+	written = models.Task{}
+	if res := s.SyncTask(tk, DirectionBoth, false); res.Action != ActionConflict || written.ID != "" {
+		t.Fatalf("rerun = %+v (written %+v)", res, written)
+	}
+
+	if err := s.State.Resolve(tk.ID, map[string]Side{FieldTitle: SideLocal}); err != nil {
+		t.Fatal(err)
+	}
+	res = s.SyncTask(tk, DirectionBoth, false)
+	if res.Action != ActionUpdateRemote || fp.updated.Title != "Retry deliveries" {
+		t.Fatalf("after resolve = %+v, pushed %+v", res, fp.updated)
+	}
+	if _, open, _ := s.State.Conflict(tk.ID); open {
+		t.Error("conflict still open after resolve + sync")
+	}
+}
+
+// TestSyncer_SeedsBaselineFromInSyncLink: a link made before per-field
+// baselines goes through LWW once, and an in-sync result seeds the baseline
+// so the next run merges.
+func TestSyncer_SeedsBaselineFromInSyncLink(t *testing.T) {
+	tk := models.Task{ID: "TASK-7", Title: "T", Status: models.TaskStatusReview, Priority: models.PriorityP2,
+		Repo: "github.com/acme/widgets", RemoteIssue: 7, Updated: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)}
+	tk.SyncHash = SyncHash(tk, "b")
+	fp := &fakeProvider{name: "github", found: true, get: RemoteIssue{
+		Number: 7, Title: "T", Body: "b", State: IssueOpen, Labels: []string{"adb:review", "priority:P2", "bug"},
+		UpdatedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}}
+	st := NewStateStore(filepath.Join(t.TempDir(), "issue_sync_state.yaml"))
+	s := &Syncer{
+		provider: func(string) (Provider, string, string, bool) { return fp, "acme", "widgets", true },
+		Body:     func(models.Task) string { return "b" },
+		Write:    func(models.Task) error { return nil },
+		Log:      func(string, map[string]interface{}) {},
+		State:    st,
+	}
+	if res := s.SyncTask(tk, DirectionBoth, false); res.Action != ActionNoop {
+		t.Fatalf("result = %+v", res)
+	}
+	b, ok, _ := st.Baseline(tk.ID)
+	if !ok || b.Title != "T" || b.Status != models.TaskStatusReview || b.Get(FieldLabels) != "bug" {
+		t.Fatalf("seeded baseline = %+v, %v", b, ok)
+	}
+	if res := s.SyncTask(tk, DirectionBoth, false); res.Reason != "in sync" || res.Changes == nil {
+		t.Errorf("second run = %+v, want the merge path", res)
+	}
+}
@@ -0,0 +1,210 @@
+package issuesync
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/configenv"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// Tracker routes the tickets of matching repos to a Jira or Linear project.
+// Unlike github/gitlab, where the project IS the ticket's repo, a tracker's
+// project comes from configuration (models.IssueTrackerConfig), so several
+// repos can share one Jira project and a repo-less ticket can still sync.
+type Tracker struct {
+	// Repos are platform-qualified repo globs; empty matches every ticket.
+	Repos []string
+	// Project is the Jira project key / Linear team key handed to the
+	// provider as the `name` half of owner/name.
+	Project  string
+	Provider Provider
+}
+
+// Matches reports whether the tracker covers a ticket's Repo.
+func (t Tracker) Matches(repo string) bool {
+	if len(t.Repos) == 0 {
+		return true
+	}
+	repo = strings.TrimSuffix(repo, "/")
+	for _, pattern := range t.Repos {
+		if ok, _ := path.Match(pattern, repo); ok || pattern == repo {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTracker builds a Tracker from its config entry. It fails on an unknown
+// provider, a missing project, or a token that resolves to empty, so a
+// misconfigured tracker is reported once instead of per ticket.
+func NewTracker(cfg models.IssueTrackerConfig) (Tracker, error) {
+	if cfg.Project == "" {
+		return Tracker{}, fmt.Errorf("issue tracker %q: project is required", cfg.Provider)
+	}
+	for _, pattern := range cfg.Repos {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Tracker{}, fmt.Errorf("issue tracker %q: bad repo pattern %q: %w", cfg.Provider, pattern, err)
+		}
+	}
+	token := configenv.Expand(cfg.Token)
+	if token == "" {
+		return Tracker{}, fmt.Errorf("issue tracker %q: token is empty (set token: \"$ENV_VAR\")", cfg.Provider)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var p Provider
+	switch cfg.Provider {
+	case "jira":
+		if cfg.URL == "" {
+			return Tracker{}, fmt.Errorf("issue tracker jira: url is required (e.g. https://acme.atlassian.net)")
+		}
+		p = &jiraProvider{
+			baseURL:   strings.TrimSuffix(cfg.URL, "/"),
+			email:     cfg.Email,
+			token:     token,
+			issueType: cfg.IssueType,
+			mapping:   newFieldMapping(defaultJiraStatuses, defaultJiraPriorities, cfg),
+			client:    client,
+		}
+	case "linear":
+		endpoint := cfg.URL
+		if endpoint == "" {
+			endpoint = DefaultLinearEndpoint
+		}
+		p = &linearProvider{
+			endpoint: endpoint,
+			token:    token,
+			mapping:  newFieldMapping(defaultLinearStatuses, defaultLinearPriorities, cfg),
+			client:   client,
+		}
+	default:
+		return Tracker{}, fmt.Errorf("unknown issue tracker provider %q (must be jira or linear)", cfg.Provider)
+	}
+	return Tracker{Repos: cfg.Repos, Project: cfg.Project, Provider: p}, nil
+}
+
+// NewTrackers builds the configured trackers in order; the first matching
+// tracker wins for a ticket.
+func NewTrackers(cfgs []models.IssueTrackerConfig) ([]Tracker, error) {
+	trackers := make([]Tracker, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		t, err := NewTracker(cfg)
+		if err != nil {
+			return nil, err
+		}
+		trackers = append(trackers, t)
+	}
+	return trackers, nil
+}
+
+// TrackerFor returns the first tracker covering repo.
+func TrackerFor(trackers []Tracker, repo string) (Tracker, bool) {
+	for _, t := range trackers {
+		if t.Matches(repo) {
+			return t, true
+		}
+	}
+	return Tracker{}, false
+}
+
+// FieldMapping translates adb status and priority to a tracker's native
+// workflow-state and priority names — the Jira/Linear analogue of
+// StatusLabel/PriorityLabel. Trackers carry status natively rather than in an
+// adb: label, so providers convert at the edge: Get synthesises the adb:
+// and priority: labels Reconcile and StateToStatus already understand, and
+// Create/Update read them back off the wanted issue.
+type FieldMapping struct {
+	Status   map[models.TaskStatus]string
+	Priority map[models.Priority]string
+}
+
+// statusOrder fixes which adb status wins when a mapping sends several to
+// the same remote state (e.g. done and archived both to "Done").
+var statusOrder = []models.TaskStatus{
+	models.TaskStatusBacklog, models.TaskStatusInProgress, models.TaskStatusBlocked,
+	models.TaskStatusReview, models.TaskStatusDone, models.TaskStatusArchived,
+}
+
+var priorityOrder = []models.Priority{models.PriorityP0, models.PriorityP1, models.PriorityP2, models.PriorityP3}
+
+// newFieldMapping overlays the config's status_map/priority_map onto a
+// provider's defaults.
+func newFieldMapping(statuses map[models.TaskStatus]string, priorities map[models.Priority]string, cfg models.IssueTrackerConfig) FieldMapping {
+	m := FieldMapping{
+		Status:   make(map[models.TaskStatus]string, len(statuses)),
+		Priority: make(map[models.Priority]string, len(priorities)),
+	}
+	for k, v := range statuses {
+		m.Status[k] = v
+	}
+	for k, v := range priorities {
+		m.Priority[k] = v
+	}
+	for k, v := range cfg.StatusMap {
+		m.Status[models.TaskStatus(k)] = v
+	}
+	for k, v := range cfg.PriorityMap {
+		m.Priority[models.Priority(k)] = v
+	}
+	return m
+}
+
+// StatusFrom is the inverse used on Get. An unmapped state falls back like
+// StateToStatus: done when the tracker says the state is closed, otherwise
+// in_progress.
+func (m FieldMapping) StatusFrom(name string, closed bool) models.TaskStatus {
+	for _, s := range statusOrder {
+		if v, ok := m.Status[s]; ok && strings.EqualFold(v, name) {
+			return s
+		}
+	}
+	if closed {
+		return models.TaskStatusDone
+	}
+	return models.TaskStatusInProgress
+}
+
+// PriorityFrom maps a remote priority name back to adb's, or "" when
+// unmapped.
+func (m FieldMapping) PriorityFrom(name string) models.Priority {
+	for _, p := range priorityOrder {
+		if v, ok := m.Priority[p]; ok && strings.EqualFold(v, name) {
+			return p
+		}
+	}
+	return ""
+}
+
+// remoteLabels builds a RemoteIssue's labels from native fields: the adb:
+// status label first (AdbLabelFrom takes the first), the priority label when
+// mapped, then the tracker's own labels.
+func (m FieldMapping) remoteLabels(status models.TaskStatus, priority models.Priority, native []string) []string {
+	labels := []string{StatusLabel(status)}
+	if priority != "" {
+		labels = append(labels, PriorityLabel(priority))
+	}
+	for _, l := range native {
+		if !isAdbOwnedLabel(l) {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// wanted decodes the adb status and priority the Syncer encoded as labels
+// on a wanted issue.
+func wanted(want RemoteIssue) (models.TaskStatus, models.Priority) {
+	status := StateToStatus(want.State, AdbLabelFrom(want.Labels))
+	var priority models.Priority
+	for _, l := range want.Labels {
+		if strings.HasPrefix(l, priorityLabelPrefix) {
+			priority = models.Priority(strings.TrimPrefix(l, priorityLabelPrefix))
+			break
+		}
+	}
+	return status, priority
+}
@@ -0,0 +1,100 @@
+package issuesync
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+func TestNewTracker_Validates(t *testing.T) {
+	t.Setenv("JIRA_TOKEN", "from-env")
+	t.Setenv("EMPTY_TOKEN", "")
+
+	tr, err := NewTracker(models.IssueTrackerConfig{Provider: "jira", URL: "https://acme.atlassian.net/", Project: "PLAT", Token: "$JIRA_TOKEN"})
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	if jp := tr.Provider.(*jiraProvider); jp.token != "from-env" || jp.baseURL != "https://acme.atlassian.net" {
+		t.Errorf("jira provider = %+v", jp)
+	}
+	tr, err = NewTracker(models.IssueTrackerConfig{Provider: "linear", Project: "ENG", Token: "lin_api_x"})
+	if err != nil || tr.Provider.(*linearProvider).endpoint != DefaultLinearEndpoint {
+		t.Errorf("linear default endpoint: %+v, %v", tr, err)
+	}
+
+	for name, cfg := range map[string]models.IssueTrackerConfig{
+		"unknown provider": {Provider: "trello", Project: "X", Token: "t"},
+		"no project":       {Provider: "linear", Token: "t"},
+		"empty token":      {Provider: "linear", Project: "ENG", Token: "$EMPTY_TOKEN"},
+		"jira without url": {Provider: "jira", Project: "PLAT", Token: "t"},
+		"bad repo glob":    {Provider: "linear", Project: "ENG", Token: "t", Repos: []string{"github.com/[acme"}},
+	} {
+		if _, err := NewTracker(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := NewTrackers([]models.IssueTrackerConfig{{Provider: "linear", Project: "ENG", Token: "t"}, {Provider: "x"}}); err == nil {
+		t.Error("NewTrackers should fail on any bad entry")
+	}
+}
+
+func TestTrackerFor_FirstMatchWins(t *testing.T) {
+	jira := Tracker{Repos: []string{"github.com/acme/*", "gitlab.com/acme/platform"}, Project: "PLAT"}
+	linear := Tracker{Project: "ENG"} // catch-all
+	trackers := []Tracker{jira, linear}
+
+	cases := map[string]string{
+		"github.com/acme/widgets":   "PLAT",
+		"github.com/acme/widgets/":  "PLAT",
+		"gitlab.com/acme/platform":  "PLAT",
+		"github.com/other/widgets":  "ENG",
+		"":                          "ENG",
+		"github.com/acme/a/b/extra": "ENG",
+	}
+	for repo, want := range cases {
+		got, ok := TrackerFor(trackers, repo)
+		if !ok || got.Project != want {
+			t.Errorf("TrackerFor(%q) = %q, %v; want %q", repo, got.Project, ok, want)
+		}
+	}
+	if _, ok := TrackerFor([]Tracker{jira}, "github.com/other/x"); ok {
+		t.Error("no tracker should match")
+	}
+}
+
+func TestFieldMapping_RoundTrip(t *testing.T) {
+	m := newFieldMapping(defaultJiraStatuses, defaultJiraPriorities, models.IssueTrackerConfig{
+		StatusMap:   map[string]string{"blocked": "On Hold"},
+		PriorityMap: map[string]string{"P0": "Blocker"},
+	})
+	if m.Status[models.TaskStatusBlocked] != "On Hold" || m.Priority[models.PriorityP0] != "Blocker" {
+		t.Errorf("overrides not applied: %+v", m)
+	}
+	if m.Status[models.TaskStatusReview] != "In Review" {
+		t.Error("defaults should survive a partial override")
+	}
+
+	// done and archived share "Done"; the earlier status wins on the way back.
+	if got := m.StatusFrom("done", true); got != models.TaskStatusDone {
+		t.Errorf("StatusFrom(done) = %q", got)
+	}
+	if got := m.StatusFrom("on hold", false); got != models.TaskStatusBlocked {
+		t.Errorf("StatusFrom is case-insensitive: got %q", got)
+	}
+	if m.StatusFrom("QA", false) != models.TaskStatusInProgress || m.StatusFrom("Won't Do", true) != models.TaskStatusDone {
+		t.Error("unmapped states should fall back like StateToStatus")
+	}
+	if m.PriorityFrom("Blocker") != models.PriorityP0 || m.PriorityFrom("Trivial") != "" {
+		t.Error("PriorityFrom mapping wrong")
+	}
+
+	labels := m.remoteLabels(models.TaskStatusBlocked, "", []string{"adb:stale", "infra"})
+	if strings.Join(labels, ",") != "adb:blocked,infra" {
+		t.Errorf("remoteLabels = %v (native adb: labels must not shadow the mapped status)", labels)
+	}
+	status, priority := wanted(RemoteIssue{Labels: []string{"priority:P2", "adb:review"}})
+	if status != models.TaskStatusReview || priority != models.PriorityP2 {
+		t.Errorf("wanted = %q, %q", status, priority)
+	}
+}
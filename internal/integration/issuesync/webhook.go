@@ -0,0 +1,340 @@
+package issuesync
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// Webhook-driven sync. Instead of waiting for the next `adb sync issues`,
+// `adb sync issues serve` receives GitHub/GitLab issue webhooks and
+// reconciles just the linked ticket, using the payload as the remote side (no
+// gh/glab round trip to read it). Deliveries are authenticated before the
+// body is parsed: GitHub signs the body with HMAC-SHA256 of the webhook
+// secret (X-Hub-Signature-256); GitLab sends its secret token verbatim
+// (X-Gitlab-Token), which is compared in constant time.
+
+// maxWebhookBody bounds one delivery. Issue payloads are a few KiB.
+const maxWebhookBody = 5 << 20
+
+// ErrBadSignature is returned for a delivery whose signature or token does
+// not match the configured secret.
+var ErrBadSignature = errors.New("webhook signature mismatch")
+
+// WebhookIssue is an issue webhook reduced to what sync needs: the
+// platform-qualified repo (as stored in Task.Repo) and the issue itself.
+type WebhookIssue struct {
+	Provider string // "github" / "gitlab"
+	Repo     string // e.g. github.com/org/repo
+	Action   string // opened, edited, labeled, closed, update, …
+	Issue    RemoteIssue
+}
+
+// VerifyGitHubSignature checks an X-Hub-Signature-256 header ("sha256=<hex>")
+// against the HMAC-SHA256 of body keyed by secret.
+func VerifyGitHubSignature(secret string, body []byte, header string) error {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return ErrBadSignature
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrBadSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// VerifyGitLabToken checks an X-Gitlab-Token header against secret.
+func VerifyGitLabToken(secret, header string) error {
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(header)) != 1 {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// ghWebhook is the subset of GitHub's `issues` event payload sync reads.
+type ghWebhook struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number    int    `json:"number"`
+		HTMLURL   string `json:"html_url"`
+		Title     string `json:"title"`
+		Body      string `json:"body"`
+		State     string `json:"state"` // open / closed
+		UpdatedAt string `json:"updated_at"`
+		Labels    []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		PullRequest json.RawMessage `json:"pull_request"`
+	} `json:"issue"`
+	Repository struct {
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+}
+
+// ParseGitHubIssueWebhook decodes an `issues` event. ok is false for
+// payloads that are not about an issue (pull-request mirrors, pings).
+func ParseGitHubIssueWebhook(body []byte) (WebhookIssue, bool, error) {
+	var p ghWebhook
+	if err := json.Unmarshal(body, &p); err != nil {
+		return WebhookIssue{}, false, fmt.Errorf("parse github webhook: %w", err)
+	}
+	if p.Issue.Number == 0 || p.Repository.FullName == "" || len(p.Issue.PullRequest) > 0 {
+		return WebhookIssue{}, false, nil
+	}
+	labels := make([]string, 0, len(p.Issue.Labels))
+	for _, l := range p.Issue.Labels {
+		labels = append(labels, l.Name)
+	}
+	state := IssueOpen
+	if strings.EqualFold(p.Issue.State, "closed") {
+		state = IssueClosed
+	}
+	updated, err := parseWebhookTime(p.Issue.UpdatedAt)
+	if err != nil {
+		return WebhookIssue{}, false, fmt.Errorf("parse github webhook: %w", err)
+	}
+	return WebhookIssue{
+		Provider: "github",
+		Repo:     webhookHost(p.Repository.HTMLURL, "github.com") + "/" + p.Repository.FullName,
+		Action:   p.Action,
+		Issue: RemoteIssue{
+			Number:    p.Issue.Number,
+			URL:       p.Issue.HTMLURL,
+			Title:     p.Issue.Title,
+			Body:      p.Issue.Body,
+			Labels:    labels,
+			State:     state,
+			UpdatedAt: updated,
+		},
+	}, true, nil
+}
+
+// glWebhook is the subset of GitLab's `Issue Hook` payload sync reads.
+type glWebhook struct {
+	ObjectKind       string `json:"object_kind"`
+	ObjectAttributes struct {
+		IID         int    `json:"iid"`
+		URL         string `json:"url"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		State       string `json:"state"` // opened / closed
+		Action      string `json:"action"`
+		UpdatedAt   string `json:"updated_at"`
+	} `json:"object_attributes"`
+	Labels []struct {
+		Title string `json:"title"`
+	} `json:"labels"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		WebURL            string `json:"web_url"`
+	} `json:"project"`
+}
+
+// ParseGitLabIssueWebhook decodes an `Issue Hook` event. ok is false for any
+// other object kind.
+func ParseGitLabIssueWebhook(body []byte) (WebhookIssue, bool, error) {
+	var p glWebhook
+	if err := json.Unmarshal(body, &p); err != nil {
+		return WebhookIssue{}, false, fmt.Errorf("parse gitlab webhook: %w", err)
+	}
+	if p.ObjectKind != "issue" || p.ObjectAttributes.IID == 0 || p.Project.PathWithNamespace == "" {
+		return WebhookIssue{}, false, nil
+	}
+	labels := make([]string, 0, len(p.Labels))
+	for _, l := range p.Labels {
+		labels = append(labels, l.Title)
+	}
+	state := IssueOpen
+	if p.ObjectAttributes.State == "closed" {
+		state = IssueClosed
+	}
+	updated, err := parseWebhookTime(p.ObjectAttributes.UpdatedAt)
+	if err != nil {
+		return WebhookIssue{}, false, fmt.Errorf("parse gitlab webhook: %w", err)
+	}
+	return WebhookIssue{
+		Provider: "gitlab",
+		Repo:     webhookHost(p.Project.WebURL, "gitlab.com") + "/" + p.Project.PathWithNamespace,
+		Action:   p.ObjectAttributes.Action,
+		Issue: RemoteIssue{
+			Number:    p.ObjectAttributes.IID,
+			URL:       p.ObjectAttributes.URL,
+			Title:     p.ObjectAttributes.Title,
+			Body:      p.ObjectAttributes.Description,
+			Labels:    labels,
+			State:     state,
+			UpdatedAt: updated,
+		},
+	}, true, nil
+}
+
+// parseWebhookTime accepts RFC 3339 (GitHub, newer GitLab) and GitLab's
+// "2006-01-02 15:04:05 UTC" form.
+func parseWebhookTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02 15:04:05 MST", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("updated_at %q: %w", s, err)
+	}
+	return t, nil
+}
+
+// webhookHost returns the host of a repository web URL, or fallback.
+func webhookHost(webURL, fallback string) string {
+	if u, err := url.Parse(webURL); err == nil && u.Host != "" {
+		return strings.ToLower(u.Host)
+	}
+	return fallback
+}
+
+// LinkedTask returns the ticket linked to issue number on repo.
+func LinkedTask(tasks []models.Task, repo string, number int) (models.Task, bool) {
+	for _, t := range tasks {
+		if t.RemoteIssue == number && strings.EqualFold(strings.TrimSuffix(t.Repo, "/"), repo) {
+			return t, true
+		}
+	}
+	return models.Task{}, false
+}
+
+// WebhookHandler serves POST /github and POST /gitlab. A provider with no
+// secret configured is disabled (404) — unsigned deliveries are never
+// accepted. Deliveries are handled one at a time so two webhooks for the same
+// ticket cannot interleave their backlog writes; one older than what was
+// already applied is answered "ignored" (see Syncer.SyncTaskFromRemote).
+type WebhookHandler struct {
+	GitHubSecret string
+	GitLabSecret string
+	// Tasks loads the current backlog.
+	Tasks func() ([]models.Task, error)
+	// Sync reconciles the linked ticket against the delivered issue
+	// (normally Syncer.SyncTaskFromRemote).
+	Sync func(models.Task, RemoteIssue) Result
+	// Logf, when set, receives one line per delivery.
+	Logf func(format string, args ...interface{})
+
+	mu sync.Mutex
+}
+
+// WebhookResponse is the JSON body of every handled delivery.
+type WebhookResponse struct {
+	Status string `json:"status"` // synced, ignored, unlinked
+	TaskID string `json:"task_id,omitempty"`
+	Action Action `json:"action,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	provider := strings.Trim(r.URL.Path, "/")
+	var secret string
+	switch provider {
+	case "github":
+		secret = h.GitHubSecret
+	case "gitlab":
+		secret = h.GitLabSecret
+	}
+	if secret == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "read body: "+err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var (
+		issue WebhookIssue
+		ok    bool
+	)
+	switch provider {
+	case "github":
+		if err := VerifyGitHubSignature(secret, body, r.Header.Get("X-Hub-Signature-256")); err != nil {
+			h.logf("github: rejected delivery %s: %v", r.Header.Get("X-GitHub-Delivery"), err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-GitHub-Event") != "issues" {
+			h.respond(w, http.StatusOK, WebhookResponse{Status: "ignored", Reason: "event " + r.Header.Get("X-GitHub-Event")})
+			return
+		}
+		issue, ok, err = ParseGitHubIssueWebhook(body)
+	case "gitlab":
+		if err := VerifyGitLabToken(secret, r.Header.Get("X-Gitlab-Token")); err != nil {
+			h.logf("gitlab: rejected delivery: %v", err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		issue, ok, err = ParseGitLabIssueWebhook(body)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !ok {
+		h.respond(w, http.StatusOK, WebhookResponse{Status: "ignored", Reason: "not an issue event"})
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	tasks, err := h.Tasks()
+	if err != nil {
+		http.Error(w, "load backlog: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tk, found := LinkedTask(tasks, issue.Repo, issue.Issue.Number)
+	if !found {
+		h.logf("%s: %s#%d %s: no linked ticket", provider, issue.Repo, issue.Issue.Number, issue.Action)
+		h.respond(w, http.StatusAccepted, WebhookResponse{Status: "unlinked"})
+		return
+	}
+	res := h.Sync(tk, issue.Issue)
+	h.logf("%s: %s#%d %s: %s %s (%s)", provider, issue.Repo, issue.Issue.Number, issue.Action, res.TaskID, res.Action, res.Reason)
+	status := "synced"
+	if res.Stale {
+		status = "ignored"
+	}
+	h.respond(w, http.StatusAccepted, WebhookResponse{Status: status, TaskID: res.TaskID, Action: res.Action, Reason: res.Reason})
+}
+
+func (h *WebhookHandler) respond(w http.ResponseWriter, code int, resp WebhookResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *WebhookHandler) logf(format string, args ...interface{}) {
+	if h.Logf != nil {
+		h.Logf(format, args...)
+	}
+}
@@ -0,0 +1,270 @@
+package issuesync
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+func readPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func githubSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseGitHubIssueWebhook_RecordedPayload(t *testing.T) {
+	ev, ok, err := ParseGitHubIssueWebhook(readPayload(t, "github_issues_labeled.json"))
+	if err != nil || !ok {
+		t.Fatalf("parse = %v, %v", ok, err)
+	}
+	if ev.Repo != "github.com/acme/widgets" || ev.Action != "labeled" || ev.Issue.Number != 42 {
+		t.Errorf("event = %+v", ev)
+	}
+	if got := StateToStatus(ev.Issue.State, AdbLabelFrom(ev.Issue.Labels)); got != models.TaskStatusBlocked {
+		t.Errorf("mapped status = %q, want blocked", got)
+	}
+	if want := time.Date(2026, 3, 2, 14, 5, 11, 0, time.UTC); !ev.Issue.UpdatedAt.Equal(want) {
+		t.Errorf("UpdatedAt = %v", ev.Issue.UpdatedAt)
+	}
+
+	// Pull requests arrive on the issues API too; they are not synced.
+	if _, ok, _ := ParseGitHubIssueWebhook([]byte(`{"action":"opened","issue":{"number":1,"pull_request":{"url":"x"}},"repository":{"full_name":"a/b"}}`)); ok {
+		t.Error("pull request payload should be ignored")
+	}
+}
+
+func TestParseGitLabIssueWebhook_RecordedPayload(t *testing.T) {
+	ev, ok, err := ParseGitLabIssueWebhook(readPayload(t, "gitlab_issue_closed.json"))
+	if err != nil || !ok {
+		t.Fatalf("parse = %v, %v", ok, err)
+	}
+	if ev.Repo != "gitlab.com/acme/platform" || ev.Issue.Number != 7 || ev.Issue.State != IssueClosed {
+		t.Errorf("event = %+v", ev)
+	}
+	if got := StateToStatus(ev.Issue.State, AdbLabelFrom(ev.Issue.Labels)); got != models.TaskStatusDone {
+		t.Errorf("closed issue mapped to %q, want done", got)
+	}
+	if want := time.Date(2026, 3, 2, 16, 45, 3, 0, time.UTC); !ev.Issue.UpdatedAt.Equal(want) {
+		t.Errorf("UpdatedAt = %v", ev.Issue.UpdatedAt)
+	}
+	if _, ok, _ := ParseGitLabIssueWebhook([]byte(`{"object_kind":"merge_request"}`)); ok {
+		t.Error("merge request payload should be ignored")
+	}
+}
+
+func TestVerifyGitHubSignature(t *testing.T) {
+	body := []byte(`{"x":1}`)
+	if err := VerifyGitHubSignature("s3cret", body, githubSignature("s3cret", body)); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	for _, header := range []string{"", "sha1=abc", "sha256=zz", githubSignature("other", body)} {
+		if err := VerifyGitHubSignature("s3cret", body, header); err == nil {
+			t.Errorf("signature %q accepted", header)
+		}
+	}
+	if err := VerifyGitLabToken("tok", "tok"); err != nil {
+		t.Errorf("valid token rejected: %v", err)
+	}
+	if err := VerifyGitLabToken("tok", "tok2"); err == nil {
+		t.Error("wrong token accepted")
+	}
+}
+
+// TestWebhookHandler_SyncsLinkedTask replays the recorded GitHub delivery
+// through the handler: a teammate's adb:blocked label lands on the linked
+// ticket and issue.synced is logged, with no gh call to read the issue.
+func TestWebhookHandler_SyncsLinkedTask(t *testing.T) {
+	local := models.Task{
+		ID: "TASK-00042", Title: "Retry webhook deliveries with backoff", Status: models.TaskStatusInProgress,
+		Priority: models.PriorityP1, Repo: "github.com/acme/widgets", RemoteIssue: 42,
+		Updated: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	local.SyncHash = SyncHash(local, "Deliveries that fail with a 5xx should be retried.")
+	other := models.Task{ID: "TASK-00043", Repo: "github.com/acme/widgets", RemoteIssue: 43}
+
+	fp := &fakeProvider{name: "github"}
+	var (
+		written models.Task
+		logged  []loggedEvent
+	)
+	s := &Syncer{
+		provider: func(string) (Provider, string, string, bool) { return fp, "acme", "widgets", true },
+		Body:     func(models.Task) string { return "Deliveries that fail with a 5xx should be retried." },
+		Write:    func(tk models.Task) error { written = tk; return nil },
+		Log:      func(evt string, data map[string]interface{}) { logged = append(logged, loggedEvent{evt, data}) },
+	}
+	h := &WebhookHandler{
+		GitHubSecret: "s3cret",
+		Tasks:        func() ([]models.Task, error) { return []models.Task{other, local}, nil },
+		Sync: func(tk models.Task, remote RemoteIssue) Result {
+			return s.SyncTaskFromRemote(tk, remote, DirectionBoth, false)
+		},
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	body := readPayload(t, "github_issues_labeled.json")
+	post := func(sig, event string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/github", bytes.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", sig)
+		req.Header.Set("X-GitHub-Event", event)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if resp := post(githubSignature("wrong", body), "issues"); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("bad signature status = %d", resp.StatusCode)
+	}
+	if written.ID != "" || len(logged) != 0 {
+		t.Fatal("a rejected delivery must not sync")
+	}
+
+	resp := post(githubSignature("s3cret", body), "issues")
+	defer resp.Body.Close()
+	var got WebhookResponse
+	_ = json.NewDecoder(resp.Body).Decode(&got)
+	if resp.StatusCode != http.StatusAccepted || got.Status != "synced" || got.TaskID != "TASK-00042" || got.Action != ActionUpdateLocal {
+		t.Fatalf("response %d %+v", resp.StatusCode, got)
+	}
+	if written.Status != models.TaskStatusBlocked {
+		t.Errorf("ticket status = %q, want blocked from the teammate's label", written.Status)
+	}
+	for _, c := range fp.calls {
+		if c == "get" {
+			t.Error("webhook sync should use the payload, not re-read the issue")
+		}
+	}
+	if len(logged) != 1 || logged[0].Event != string(observability.EventIssueSynced) {
+		t.Errorf("logged %+v, want one issue.synced", logged)
+	}
+
+	if resp := post(githubSignature("s3cret", body), "ping"); resp.StatusCode != http.StatusOK {
+		t.Errorf("ping status = %d", resp.StatusCode)
+	}
+}
+
+// TestWebhookHandler_IgnoresOlderDelivery: GitHub delivers the label change
+// back to in_progress after the later one to blocked. The older snapshot is
+// answered "ignored" and the ticket stays blocked.
+func TestWebhookHandler_IgnoresOlderDelivery(t *testing.T) {
+	current := models.Task{ID: "TASK-00042", Title: "Retry", Status: models.TaskStatusInProgress,
+		Priority: models.PriorityP1, Repo: "github.com/acme/widgets", RemoteIssue: 42}
+	base := Fields{Title: "Retry", Body: "b", Status: models.TaskStatusInProgress, Priority: models.PriorityP1}
+	fp := &fakeProvider{name: "github"}
+	var (
+		written models.Task
+		logged  []loggedEvent
+	)
+	s := newMergeSyncer(t, fp, current, "b", base, &written, &logged)
+	writes := 0
+	s.Write = func(tk models.Task) error { writes++; current = tk; return nil }
+	h := &WebhookHandler{
+		GitHubSecret: "s3cret",
+		Tasks:        func() ([]models.Task, error) { return []models.Task{current}, nil },
+		Sync: func(tk models.Task, remote RemoteIssue) Result {
+			return s.SyncTaskFromRemote(tk, remote, DirectionBoth, false)
+		},
+	}
+
+	deliver := func(status, updatedAt string) WebhookResponse {
+		t.Helper()
+		body := []byte(`{"action":"labeled","issue":{"number":42,"html_url":"https://github.com/acme/widgets/issues/42",` +
+			`"title":"Retry","body":"b","state":"open","updated_at":"` + updatedAt + `",` +
+			`"labels":[{"name":"adb:` + status + `"},{"name":"priority:P1"}]},` +
+			`"repository":{"full_name":"acme/widgets","html_url":"https://github.com/acme/widgets"}}`)
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/github", bytes.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", githubSignature("s3cret", body))
+		req.Header.Set("X-GitHub-Event", "issues")
+		h.ServeHTTP(rec, req)
+		var got WebhookResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &got)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("delivery at %s: status %d %+v", updatedAt, rec.Code, got)
+		}
+		return got
+	}
+
+	if got := deliver("blocked", "2026-03-02T10:00:05Z"); got.Status != "synced" || got.Action != ActionUpdateLocal {
+		t.Fatalf("newer delivery = %+v", got)
+	}
+	if current.Status != models.TaskStatusBlocked || writes != 1 {
+		t.Fatalf("after the newer delivery: status %q, %d writes", current.Status, writes)
+	}
+	for _, at := range []string{"2026-03-02T10:00:01Z", "2026-03-02T10:00:05Z"} {
+		got := deliver("in_progress", at)
+		if got.Status != "ignored" || got.Action != ActionNoop {
+			t.Errorf("delivery at %s = %+v, want ignored", at, got)
+		}
+	}
+	if current.Status != models.TaskStatusBlocked || writes != 1 {
+		t.Errorf("stale deliveries rolled the ticket back: status %q, %d writes", current.Status, writes)
+	}
+	if b, _, _ := s.State.Baseline(current.ID); b.Status != models.TaskStatusBlocked {
+		t.Errorf("baseline status = %q, want blocked", b.Status)
+	}
+	if last := logged[len(logged)-1]; last.Event != string(observability.EventIssueSkipped) {
+		t.Errorf("last event = %+v, want issue.skipped", last)
+	}
+
+	if got := deliver("in_progress", "2026-03-02T10:00:09Z"); got.Status != "synced" || current.Status != models.TaskStatusInProgress {
+		t.Errorf("a later delivery must still apply: %+v, status %q", got, current.Status)
+	}
+}
+
+func TestWebhookHandler_GitLabAndDisabledProviders(t *testing.T) {
+	synced := 0
+	h := &WebhookHandler{
+		GitLabSecret: "tok",
+		Tasks:        func() ([]models.Task, error) { return nil, nil },
+		Sync:         func(models.Task, RemoteIssue) Result { synced++; return Result{} },
+	}
+	body := readPayload(t, "gitlab_issue_closed.json")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/gitlab", bytes.NewReader(body))
+	req.Header.Set("X-Gitlab-Token", "tok")
+	h.ServeHTTP(rec, req)
+	var got WebhookResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &got)
+	if rec.Code != http.StatusAccepted || got.Status != "unlinked" || synced != 0 {
+		t.Errorf("unlinked issue: %d %+v (synced %d)", rec.Code, got, synced)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/gitlab", bytes.NewReader(body))
+	req.Header.Set("X-Gitlab-Token", "nope")
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad token status = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/github", bytes.NewReader(body)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("github without a secret: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// ChannelNames is the set of sink names NotificationConfig.Channels accepts.
+var ChannelNames = []string{"webhook", "slack", "email", "desktop", "file"}
+
+// ChannelsFromConfig builds one Channel per name in cfg.Channels, reading each
+// sink's sub-block. basePath resolves a relative file-sink path. An unknown
+// name or a sink missing its required setting is an error, so a typo in
+// .taskconfig surfaces at `adb alerts --notify` rather than as silence.
+func ChannelsFromConfig(cfg models.NotificationConfig, basePath string) ([]Channel, error) {
+	channels := make([]Channel, 0, len(cfg.Channels))
+	for _, raw := range cfg.Channels {
+		switch name := strings.ToLower(strings.TrimSpace(raw)); name {
+		case "webhook":
+			if cfg.Webhook.URL == "" {
+				return nil, fmt.Errorf("notifications.webhook.url is required for the webhook channel")
+			}
+			channels = append(channels, NewWebhookChannel(cfg.Webhook.URL, cfg.Webhook.Headers))
+		case "slack":
+			if cfg.Slack.URL == "" {
+				return nil, fmt.Errorf("notifications.slack.url is required for the slack channel")
+			}
+			channels = append(channels, NewSlackChannel(cfg.Slack.URL, cfg.Slack.Channel, cfg.Slack.Username))
+		case "email":
+			if cfg.Email.Host == "" || cfg.Email.From == "" || len(cfg.Email.To) == 0 {
+				return nil, fmt.Errorf("notifications.email needs host, from and to for the email channel")
+			}
+			channels = append(channels, NewEmailChannel(cfg.Email.Host, cfg.Email.From, cfg.Email.To, cfg.Email.Username, cfg.Email.Password))
+		case "desktop":
+			channels = append(channels, NewDesktopChannel(cfg.Desktop.Command))
+		case "file":
+			path := cfg.File.Path
+			if path == "" {
+				return nil, fmt.Errorf("notifications.file.path is required for the file channel")
+			}
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(basePath, path)
+			}
+			channels = append(channels, NewFileChannel(path))
+		default:
+			return nil, fmt.Errorf("unknown notification channel %q (valid: %s)", raw, strings.Join(ChannelNames, ", "))
+		}
+	}
+	return channels, nil
+}
+
+// RenotifyFromConfig parses cfg.Renotify, falling back to DefaultRenotify when
+// it is empty or not a positive Go duration.
+func RenotifyFromConfig(cfg models.NotificationConfig) time.Duration {
+	raw := strings.TrimSpace(cfg.Renotify)
+	if raw == "" {
+		return DefaultRenotify
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return DefaultRenotify
+	}
+	return d
+}
+
+// MessagesForConfig renders alerts as Messages, keeping only the alert types
+// listed in cfg.OnEvents (all of them when OnEvents is empty).
+func MessagesForConfig(cfg models.NotificationConfig, alerts []observability.Alert) []Message {
+	allow := make(map[string]bool, len(cfg.OnEvents))
+	for _, t := range cfg.OnEvents {
+		allow[strings.TrimSpace(t)] = true
+	}
+	msgs := make([]Message, 0, len(alerts))
+	for _, a := range alerts {
+		if len(allow) > 0 && !allow[string(a.Type)] {
+			continue
+		}
+		msgs = append(msgs, MessageFromAlert(a))
+	}
+	return msgs
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// DesktopChannel raises a local desktop notification by running
+// `<command> <title> <text>` (notify-send by default).
+type DesktopChannel struct {
+	Command string
+
+	// run executes the command; injectable for tests.
+	run func(ctx context.Context, name string, args ...string) error
+}
+
+// NewDesktopChannel returns a DesktopChannel. An empty command means
+// notify-send.
+func NewDesktopChannel(command string) *DesktopChannel {
+	if command == "" {
+		command = "notify-send"
+	}
+	return &DesktopChannel{
+		Command: command,
+		run: func(ctx context.Context, name string, args ...string) error {
+			out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+			if err != nil {
+				return fmt.Errorf("%s: %w: %s", name, err, out)
+			}
+			return nil
+		},
+	}
+}
+
+func (d *DesktopChannel) Name() string { return "desktop" }
+
+func (d *DesktopChannel) Send(ctx context.Context, msg Message) error {
+	return d.run(ctx, d.Command, "adb: "+msg.Title, msg.Text)
+}
+
+// FileChannel appends each Message as one JSON line to a file — the
+// zero-dependency sink for headless boxes, and what a log shipper can tail.
+type FileChannel struct {
+	Path string
+}
+
+// NewFileChannel returns a FileChannel writing to path.
+func NewFileChannel(path string) *FileChannel {
+	return &FileChannel{Path: path}
+}
+
+func (f *FileChannel) Name() string { return "file" }
+
+func (f *FileChannel) Send(ctx context.Context, msg Message) error {
+	if f.Path == "" {
+		return fmt.Errorf("no path configured")
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+	fh, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	_, err = fh.Write(append(data, '\n'))
+	return err
+}
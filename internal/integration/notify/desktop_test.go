@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileChannel_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "alerts.jsonl")
+	ch := NewFileChannel(path)
+	for _, id := range []string{"TASK-1", "TASK-2"} {
+		if err := ch.Send(context.Background(), MessageFromAlert(blockedAlert(id))); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+	var lines []Message
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var m Message
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			t.Fatalf("line is not JSON: %v", err)
+		}
+		lines = append(lines, m)
+	}
+	if len(lines) != 2 || lines[1].TaskID != "TASK-2" {
+		t.Fatalf("lines = %+v", lines)
+	}
+}
+
+func TestDesktopChannel_RunsCommandWithTitleAndText(t *testing.T) {
+	var gotName string
+	var gotArgs []string
+	ch := NewDesktopChannel("")
+	ch.run = func(_ context.Context, name string, args ...string) error {
+		gotName, gotArgs = name, args
+		return nil
+	}
+	if err := ch.Send(context.Background(), MessageFromAlert(blockedAlert("TASK-3"))); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if gotName != "notify-send" || len(gotArgs) != 2 || !strings.Contains(gotArgs[0], "TASK-3") {
+		t.Fatalf("ran %s %v", gotName, gotArgs)
+	}
+}
+
+func TestEmailChannel_SendsRenderedMessage(t *testing.T) {
+	t.Setenv("ADB_TEST_SMTP_PASS", "pw")
+	ch := NewEmailChannel("smtp.example.com:587", "adb@example.com", []string{"a@example.com", "b@example.com"}, "adb", "$ADB_TEST_SMTP_PASS")
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotBody string
+	var gotAuth smtp.Auth
+	ch.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotAuth, gotFrom, gotTo, gotBody = addr, a, from, to, string(msg)
+		return nil
+	}
+
+	alert := blockedAlert("TASK-4")
+	alert.Message = "line one\r\nBcc: evil@example.com"
+	if err := ch.Send(context.Background(), MessageFromAlert(alert)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if gotAddr != "smtp.example.com:587" || gotFrom != "adb@example.com" || len(gotTo) != 2 || gotAuth == nil {
+		t.Fatalf("envelope addr=%s from=%s to=%v auth=%v", gotAddr, gotFrom, gotTo, gotAuth)
+	}
+	if !strings.Contains(gotBody, "Subject: adb alert: [High] task_blocked_too_long TASK-4\r\n") {
+		t.Errorf("missing subject:\n%s", gotBody)
+	}
+	headers := strings.SplitN(gotBody, "\r\n\r\n", 2)[0]
+	if strings.Contains(headers, "Bcc:") {
+		t.Errorf("alert text leaked into headers:\n%s", headers)
+	}
+}
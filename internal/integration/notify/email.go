@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/configenv"
+)
+
+// EmailChannel delivers each Message as a plain-text email over SMTP.
+type EmailChannel struct {
+	Host     string // host:port
+	From     string
+	To       []string
+	Username string
+	Password string
+
+	// sendMail is net/smtp.SendMail; injectable so tests assert the envelope
+	// and body without a live SMTP server.
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailChannel returns an EmailChannel. password supports `$ENV_VAR`
+// interpolation.
+func NewEmailChannel(host, from string, to []string, username, password string) *EmailChannel {
+	return &EmailChannel{
+		Host:     host,
+		From:     from,
+		To:       to,
+		Username: username,
+		Password: configenv.Expand(password),
+		sendMail: smtp.SendMail,
+	}
+}
+
+func (e *EmailChannel) Name() string { return "email" }
+
+func (e *EmailChannel) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if e.Host == "" || e.From == "" || len(e.To) == 0 {
+		return fmt.Errorf("email channel needs host, from and at least one recipient")
+	}
+	var auth smtp.Auth
+	if e.Username != "" {
+		hostname, _, err := net.SplitHostPort(e.Host)
+		if err != nil {
+			hostname = e.Host
+		}
+		auth = smtp.PlainAuth("", e.Username, e.Password, hostname)
+	}
+	return e.sendMail(e.Host, auth, e.From, e.To, e.render(msg))
+}
+
+// render builds an RFC 5322 message. Header values are stripped of CR/LF so
+// an alert message can never inject extra headers.
+func (e *EmailChannel) render(msg Message) []byte {
+	clean := func(s string) string {
+		return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", clean(e.From))
+	fmt.Fprintf(&b, "To: %s\r\n", clean(strings.Join(e.To, ", ")))
+	fmt.Fprintf(&b, "Subject: adb alert: %s\r\n", clean(msg.Title))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(msg.Text)
+	b.WriteString("\r\n")
+	if msg.TaskID != "" {
+		fmt.Fprintf(&b, "\r\nTask: %s\r\n", msg.TaskID)
+	}
+	fmt.Fprintf(&b, "Alert: %s (%s)\r\n", msg.Type, msg.Severity)
+	return []byte(b.String())
+}
@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/lockfile"
+	"gopkg.in/yaml.v3"
+)
+
+// LedgerEntry records the delivery history of one alert key on one channel.
+type LedgerEntry struct {
+	Key         string    `yaml:"key"`
+	Channel     string    `yaml:"channel"`
+	FirstSent   time.Time `yaml:"first_sent,omitempty"`
+	LastSent    time.Time `yaml:"last_sent,omitempty"`
+	LastAttempt time.Time `yaml:"last_attempt,omitempty"`
+	Attempts    int       `yaml:"attempts"`
+	Sends       int       `yaml:"sends"`
+	LastError   string    `yaml:"last_error,omitempty"`
+}
+
+// Ledger persists LedgerEntries to a YAML file under .adb/. The scheduler
+// daemon and an interactive `adb alerts --notify` can run at once, so a
+// Notify cycle holds a sidecar flock (the backlog.yaml pattern) across its
+// whole load → deliver → save, and writes land via temp file + rename.
+type Ledger struct {
+	path string
+	mu   sync.Mutex
+}
+
+type ledgerFile struct {
+	Deliveries []LedgerEntry `yaml:"deliveries"`
+}
+
+// NewLedger returns a Ledger persisted at path (normally
+// statedir.Path(base, statedir.FileNotifyLedger)).
+func NewLedger(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+// Entries returns every recorded delivery, sorted by key then channel.
+func (l *Ledger) Entries() ([]LedgerEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	state, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+	return sortedEntries(state), nil
+}
+
+// begin locks the ledger and returns its current state plus a commit func
+// that saves the (mutated) state and releases the lock. commit must be called
+// exactly once.
+func (l *Ledger) begin() (map[string]LedgerEntry, func(map[string]LedgerEntry) error, error) {
+	l.mu.Lock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		l.mu.Unlock()
+		return nil, nil, fmt.Errorf("create ledger directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		l.mu.Unlock()
+		return nil, nil, fmt.Errorf("open notify ledger lock: %w", err)
+	}
+	unlock, err := lockfile.Lock(f)
+	if err != nil {
+		_ = f.Close()
+		l.mu.Unlock()
+		return nil, nil, fmt.Errorf("lock notify ledger: %w", err)
+	}
+	release := func() {
+		unlock()
+		_ = f.Close()
+		l.mu.Unlock()
+	}
+
+	state, err := l.load()
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	commit := func(next map[string]LedgerEntry) error {
+		defer release()
+		return l.save(next)
+	}
+	return state, commit, nil
+}
+
+func (l *Ledger) load() (map[string]LedgerEntry, error) {
+	state := make(map[string]LedgerEntry)
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("read notify ledger: %w", err)
+	}
+	var file ledgerFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse notify ledger: %w", err)
+	}
+	for _, e := range file.Deliveries {
+		state[ledgerID(e.Key, e.Channel)] = e
+	}
+	return state, nil
+}
+
+func (l *Ledger) save(state map[string]LedgerEntry) error {
+	data, err := yaml.Marshal(ledgerFile{Deliveries: sortedEntries(state)})
+	if err != nil {
+		return fmt.Errorf("marshal notify ledger: %w", err)
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write notify ledger: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("commit notify ledger: %w", err)
+	}
+	return nil
+}
+
+func ledgerID(key, channel string) string {
+	return key + "\x00" + channel
+}
+
+func sortedEntries(state map[string]LedgerEntry) []LedgerEntry {
+	out := make([]LedgerEntry, 0, len(state))
+	for _, e := range state {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Key != out[j].Key {
+			return out[i].Key < out[j].Key
+		}
+		return out[i].Channel < out[j].Channel
+	})
+	return out
+}
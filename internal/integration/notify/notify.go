@@ -0,0 +1,281 @@
+// Package notify delivers alert notifications to external sinks.
+//
+// A Channel is one sink (generic JSON webhook, Slack-compatible incoming
+// webhook, SMTP email, a desktop notify-send, or an append-only file). The
+// Notifier fans a set of Messages out to every configured Channel with a
+// bounded retry/backoff, and consults a persisted delivery Ledger so the same
+// alert is not re-sent on every run: the hourly alerts-tick scheduler job and
+// `adb alerts --notify` both hand the Notifier the COMPLETE set of currently
+// firing alerts, and a (key, channel) pair already delivered within the
+// renotify window is suppressed. A key that drops out of the firing set is
+// forgotten, so an alert that resolves and later fires again notifies again.
+//
+// Channels never see secrets from the event log — a Message carries only the
+// rendered alert (type, severity, text, task id, metadata). Credentials come
+// from config with `$ENV_VAR` interpolation (internal/configenv), shared with
+// the memory embedder's api_key and the issue trackers' tokens.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+)
+
+// Message is the sink-agnostic notification a Channel delivers. Key is the
+// dedupe identity (see observability.Alert.Key); two Messages with the same
+// Key are the same alert as far as the Ledger is concerned.
+type Message struct {
+	Key       string                 `json:"key"`
+	Type      string                 `json:"type"`
+	Severity  string                 `json:"severity"`
+	Title     string                 `json:"title"`
+	Text      string                 `json:"text"`
+	TaskID    string                 `json:"task_id,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// MessageFromAlert renders an observability.Alert as a Message.
+func MessageFromAlert(a observability.Alert) Message {
+	title := fmt.Sprintf("[%s] %s", a.Severity, a.Type)
+	if a.TaskID != "" {
+		title += " " + a.TaskID
+	}
+	return Message{
+		Key:       a.Key(),
+		Type:      string(a.Type),
+		Severity:  string(a.Severity),
+		Title:     title,
+		Text:      a.Message,
+		TaskID:    a.TaskID,
+		Timestamp: a.Timestamp,
+		Metadata:  a.Metadata,
+	}
+}
+
+// Channel is one notification sink. Send must be safe to retry: the Notifier
+// calls it again (after a backoff) when it returns an error.
+type Channel interface {
+	// Name identifies the sink in the ledger and in logs ("webhook", "slack", …).
+	Name() string
+	// Send delivers msg once. A non-nil error marks the attempt failed.
+	Send(ctx context.Context, msg Message) error
+}
+
+// DeliveryStatus is the outcome of one (message, channel) pair.
+type DeliveryStatus string
+
+const (
+	DeliverySent       DeliveryStatus = "sent"       // delivered on this run
+	DeliverySuppressed DeliveryStatus = "suppressed" // already delivered within the renotify window
+	DeliveryFailed     DeliveryStatus = "failed"     // every attempt errored
+)
+
+// Delivery reports what happened to one message on one channel.
+type Delivery struct {
+	Key      string
+	Channel  string
+	Status   DeliveryStatus
+	Attempts int
+	Error    string
+}
+
+// RetryPolicy bounds how hard a failing Send is retried. Backoff doubles after
+// each failed attempt.
+type RetryPolicy struct {
+	Attempts int           // total attempts per delivery (>= 1)
+	Backoff  time.Duration // wait before the second attempt
+}
+
+// DefaultRetryPolicy is three attempts with a 2s → 4s backoff — enough to ride
+// out a webhook blip without stalling an hourly scheduler tick.
+var DefaultRetryPolicy = RetryPolicy{Attempts: 3, Backoff: 2 * time.Second}
+
+// DefaultRenotify is how long a still-firing alert stays suppressed on a
+// channel after it was delivered.
+const DefaultRenotify = 24 * time.Hour
+
+// Notifier fans messages out to channels with retry and ledger-backed dedupe.
+type Notifier struct {
+	channels []Channel
+	ledger   *Ledger
+	renotify time.Duration
+	retry    RetryPolicy
+	now      func() time.Time
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+// Option configures a Notifier.
+type Option func(*Notifier)
+
+// WithRenotify overrides DefaultRenotify. Non-positive values are ignored.
+func WithRenotify(d time.Duration) Option {
+	return func(n *Notifier) {
+		if d > 0 {
+			n.renotify = d
+		}
+	}
+}
+
+// WithRetryPolicy overrides DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(n *Notifier) {
+		if p.Attempts < 1 {
+			p.Attempts = 1
+		}
+		n.retry = p
+	}
+}
+
+// WithClock injects the current-time source (tests).
+func WithClock(now func() time.Time) Option {
+	return func(n *Notifier) { n.now = now }
+}
+
+// withSleep injects the backoff sleeper so tests don't wait on real time.
+func withSleep(sleep func(ctx context.Context, d time.Duration) error) Option {
+	return func(n *Notifier) { n.sleep = sleep }
+}
+
+// NewNotifier builds a Notifier over channels. A nil ledger disables dedupe
+// (every call delivers everything) — useful for a one-off test send.
+func NewNotifier(channels []Channel, ledger *Ledger, opts ...Option) *Notifier {
+	n := &Notifier{
+		channels: channels,
+		ledger:   ledger,
+		renotify: DefaultRenotify,
+		retry:    DefaultRetryPolicy,
+		now:      time.Now,
+		sleep:    sleepCtx,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Channels returns the names of the configured channels, in order.
+func (n *Notifier) Channels() []string {
+	names := make([]string, 0, len(n.channels))
+	for _, c := range n.channels {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// Notify delivers every message in active to every channel, skipping pairs the
+// ledger says were delivered within the renotify window. active must be the
+// complete set of currently-firing alerts: ledger entries whose key is absent
+// are dropped so a resolved-then-refired alert notifies again. The returned
+// error is only a ledger persistence failure; per-channel send failures are
+// reported in the Delivery slice and retried on the next run.
+func (n *Notifier) Notify(ctx context.Context, active []Message) ([]Delivery, error) {
+	var state map[string]LedgerEntry
+	commit := func(map[string]LedgerEntry) error { return nil }
+	if n.ledger != nil {
+		var err error
+		state, commit, err = n.ledger.begin()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		state = make(map[string]LedgerEntry)
+	}
+
+	keep := make(map[string]bool, len(active)*len(n.channels))
+	var deliveries []Delivery
+	for _, msg := range active {
+		for _, ch := range n.channels {
+			id := ledgerID(msg.Key, ch.Name())
+			keep[id] = true
+			entry := state[id]
+			now := n.now().UTC()
+			if n.ledger != nil && !entry.LastSent.IsZero() && now.Sub(entry.LastSent) < n.renotify {
+				deliveries = append(deliveries, Delivery{Key: msg.Key, Channel: ch.Name(), Status: DeliverySuppressed})
+				continue
+			}
+
+			attempts, err := n.send(ctx, ch, msg)
+			entry.Key, entry.Channel = msg.Key, ch.Name()
+			entry.LastAttempt = n.now().UTC()
+			entry.Attempts += attempts
+			d := Delivery{Key: msg.Key, Channel: ch.Name(), Attempts: attempts}
+			if err != nil {
+				entry.LastError = err.Error()
+				d.Status, d.Error = DeliveryFailed, err.Error()
+			} else {
+				if entry.FirstSent.IsZero() {
+					entry.FirstSent = entry.LastAttempt
+				}
+				entry.LastSent = entry.LastAttempt
+				entry.Sends++
+				entry.LastError = ""
+				d.Status = DeliverySent
+			}
+			state[id] = entry
+			deliveries = append(deliveries, d)
+		}
+	}
+
+	for id := range state {
+		if !keep[id] {
+			delete(state, id)
+		}
+	}
+	if err := commit(state); err != nil {
+		return deliveries, err
+	}
+	return deliveries, nil
+}
+
+// send runs one delivery with the retry policy, returning the attempt count.
+func (n *Notifier) send(ctx context.Context, ch Channel, msg Message) (int, error) {
+	backoff := n.retry.Backoff
+	var err error
+	for attempt := 1; attempt <= n.retry.Attempts; attempt++ {
+		if err = ch.Send(ctx, msg); err == nil {
+			return attempt, nil
+		}
+		if attempt == n.retry.Attempts {
+			break
+		}
+		if serr := n.sleep(ctx, backoff); serr != nil {
+			return attempt, fmt.Errorf("%s: %w (retry aborted: %v)", ch.Name(), err, serr)
+		}
+		backoff *= 2
+	}
+	return n.retry.Attempts, fmt.Errorf("%s: %w", ch.Name(), err)
+}
+
+// Summary renders deliveries as a one-line tally, e.g. "2 sent, 1 suppressed".
+func Summary(deliveries []Delivery) string {
+	var sent, suppressed, failed int
+	for _, d := range deliveries {
+		switch d.Status {
+		case DeliverySent:
+			sent++
+		case DeliverySuppressed:
+			suppressed++
+		case DeliveryFailed:
+			failed++
+		}
+	}
+	return fmt.Sprintf("%d sent, %d suppressed, %d failed", sent, suppressed, failed)
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
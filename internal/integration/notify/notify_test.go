@@ -0,0 +1,220 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// fakeChannel records every Send and fails the first failN calls.
+type fakeChannel struct {
+	name  string
+	failN int
+	calls int
+	sent  []Message
+}
+
+func (f *fakeChannel) Name() string { return f.name }
+
+func (f *fakeChannel) Send(_ context.Context, msg Message) error {
+	f.calls++
+	if f.calls <= f.failN {
+		return errors.New("boom")
+	}
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+func noSleep(context.Context, time.Duration) error { return nil }
+
+func testClock(start time.Time) (func() time.Time, func(time.Duration)) {
+	now := start
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
+
+func blockedAlert(taskID string) observability.Alert {
+	return observability.Alert{
+		Type:     observability.AlertTaskBlockedTooLong,
+		Severity: observability.AlertSeverityHigh,
+		Message:  "Task " + taskID + " has been blocked",
+		TaskID:   taskID,
+	}
+}
+
+func TestNotify_DedupesAcrossRunsViaLedger(t *testing.T) {
+	ch := &fakeChannel{name: "fake"}
+	ledger := NewLedger(filepath.Join(t.TempDir(), "ledger.yaml"))
+	clock, advance := testClock(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+	msgs := []Message{MessageFromAlert(blockedAlert("TASK-1"))}
+
+	// First run delivers; an hour later (the alerts-tick cadence) the same
+	// still-firing alert is suppressed, even through a fresh Notifier.
+	n := NewNotifier([]Channel{ch}, ledger, WithClock(clock), withSleep(noSleep))
+	got, err := n.Notify(context.Background(), msgs)
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(got) != 1 || got[0].Status != DeliverySent {
+		t.Fatalf("first run = %+v, want one sent", got)
+	}
+
+	advance(time.Hour)
+	n = NewNotifier([]Channel{ch}, NewLedger(ledger.path), WithClock(clock), withSleep(noSleep))
+	got, err = n.Notify(context.Background(), msgs)
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got[0].Status != DeliverySuppressed {
+		t.Fatalf("second run status = %s, want suppressed", got[0].Status)
+	}
+	if len(ch.sent) != 1 {
+		t.Fatalf("channel received %d messages, want 1", len(ch.sent))
+	}
+
+	// Past the renotify window the alert is re-sent.
+	advance(DefaultRenotify)
+	got, _ = n.Notify(context.Background(), msgs)
+	if got[0].Status != DeliverySent {
+		t.Fatalf("post-window status = %s, want sent", got[0].Status)
+	}
+}
+
+func TestNotify_ResolvedAlertNotifiesAgainOnRefire(t *testing.T) {
+	ch := &fakeChannel{name: "fake"}
+	ledger := NewLedger(filepath.Join(t.TempDir(), "ledger.yaml"))
+	n := NewNotifier([]Channel{ch}, ledger, withSleep(noSleep))
+	msgs := []Message{MessageFromAlert(blockedAlert("TASK-1"))}
+
+	if _, err := n.Notify(context.Background(), msgs); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	// The alert resolves: an empty active set prunes its ledger entry.
+	if _, err := n.Notify(context.Background(), nil); err != nil {
+		t.Fatalf("Notify(empty): %v", err)
+	}
+	entries, err := ledger.Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("ledger kept %d entries for a resolved alert", len(entries))
+	}
+	got, _ := n.Notify(context.Background(), msgs)
+	if got[0].Status != DeliverySent {
+		t.Fatalf("re-fired alert status = %s, want sent", got[0].Status)
+	}
+}
+
+func TestNotify_RetriesWithBackoff(t *testing.T) {
+	ch := &fakeChannel{name: "flaky", failN: 2}
+	var waits []time.Duration
+	sleep := func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	n := NewNotifier([]Channel{ch}, nil,
+		WithRetryPolicy(RetryPolicy{Attempts: 3, Backoff: time.Second}), withSleep(sleep))
+
+	got, err := n.Notify(context.Background(), []Message{{Key: "k"}})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got[0].Status != DeliverySent || got[0].Attempts != 3 {
+		t.Fatalf("delivery = %+v, want sent after 3 attempts", got[0])
+	}
+	if len(waits) != 2 || waits[0] != time.Second || waits[1] != 2*time.Second {
+		t.Fatalf("backoff waits = %v, want [1s 2s]", waits)
+	}
+}
+
+func TestNotify_FailureIsRecordedAndRetriedNextRun(t *testing.T) {
+	ch := &fakeChannel{name: "down", failN: 2}
+	ledger := NewLedger(filepath.Join(t.TempDir(), "ledger.yaml"))
+	n := NewNotifier([]Channel{ch}, ledger,
+		WithRetryPolicy(RetryPolicy{Attempts: 2}), withSleep(noSleep))
+	msgs := []Message{{Key: "k"}}
+
+	got, err := n.Notify(context.Background(), msgs)
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got[0].Status != DeliveryFailed || got[0].Error == "" {
+		t.Fatalf("delivery = %+v, want failed with error", got[0])
+	}
+	entries, _ := ledger.Entries()
+	if len(entries) != 1 || entries[0].LastError == "" || !entries[0].LastSent.IsZero() {
+		t.Fatalf("ledger = %+v, want one unsent entry with last_error", entries)
+	}
+
+	// A failed pair is never suppressed — the next run tries again.
+	got, _ = n.Notify(context.Background(), msgs)
+	if got[0].Status != DeliverySent {
+		t.Fatalf("next run status = %s, want sent", got[0].Status)
+	}
+	entries, _ = ledger.Entries()
+	if entries[0].Attempts != 3 || entries[0].Sends != 1 || entries[0].LastError != "" {
+		t.Fatalf("ledger after recovery = %+v", entries[0])
+	}
+}
+
+func TestNotify_DedupeIsPerChannel(t *testing.T) {
+	a := &fakeChannel{name: "a"}
+	b := &fakeChannel{name: "b", failN: 1}
+	ledger := NewLedger(filepath.Join(t.TempDir(), "ledger.yaml"))
+	n := NewNotifier([]Channel{a, b}, ledger, WithRetryPolicy(RetryPolicy{Attempts: 1}), withSleep(noSleep))
+	msgs := []Message{{Key: "k"}}
+
+	_, _ = n.Notify(context.Background(), msgs)
+	got, _ := n.Notify(context.Background(), msgs)
+	if got[0].Status != DeliverySuppressed || got[1].Status != DeliverySent {
+		t.Fatalf("second run = %+v, want a suppressed and b sent", got)
+	}
+}
+
+func TestMessagesForConfig_FiltersOnEvents(t *testing.T) {
+	alerts := []observability.Alert{
+		blockedAlert("TASK-1"),
+		{Type: observability.AlertBacklogTooLarge, Severity: observability.AlertSeverityLow},
+	}
+	cfg := models.NotificationConfig{OnEvents: []string{string(observability.AlertBacklogTooLarge)}}
+	got := MessagesForConfig(cfg, alerts)
+	if len(got) != 1 || got[0].Key != string(observability.AlertBacklogTooLarge) {
+		t.Fatalf("filtered = %+v, want only backlog_too_large", got)
+	}
+	if len(MessagesForConfig(models.NotificationConfig{}, alerts)) != 2 {
+		t.Fatal("empty on_events should keep every alert")
+	}
+}
+
+func TestChannelsFromConfig(t *testing.T) {
+	base := t.TempDir()
+	cfg := models.NotificationConfig{
+		Channels: []string{"webhook", "slack", "email", "desktop", "file"},
+		Webhook:  models.WebhookNotifyConf{URL: "http://example.invalid/hook"},
+		Slack:    models.SlackNotifyConf{URL: "http://example.invalid/slack"},
+		Email:    models.EmailNotifyConf{Host: "localhost:25", From: "adb@example.com", To: []string{"me@example.com"}},
+		File:     models.FileNotifyConf{Path: "alerts.jsonl"},
+	}
+	chs, err := ChannelsFromConfig(cfg, base)
+	if err != nil {
+		t.Fatalf("ChannelsFromConfig: %v", err)
+	}
+	if len(chs) != 5 {
+		t.Fatalf("got %d channels, want 5", len(chs))
+	}
+	if fc := chs[4].(*FileChannel); fc.Path != filepath.Join(base, "alerts.jsonl") {
+		t.Errorf("file path = %q, want it resolved under the workspace", fc.Path)
+	}
+
+	if _, err := ChannelsFromConfig(models.NotificationConfig{Channels: []string{"pager"}}, base); err == nil {
+		t.Error("unknown channel should be an error")
+	}
+	if _, err := ChannelsFromConfig(models.NotificationConfig{Channels: []string{"webhook"}}, base); err == nil {
+		t.Error("webhook without a url should be an error")
+	}
+}
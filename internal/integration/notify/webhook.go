@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/configenv"
+)
+
+// defaultHTTPTimeout bounds one webhook POST so a hung endpoint cannot stall
+// the scheduler tick that triggered it.
+const defaultHTTPTimeout = 10 * time.Second
+
+// WebhookChannel POSTs each Message as JSON to a generic endpoint. The body is
+// the Message itself (key, type, severity, title, text, task_id, timestamp,
+// metadata) so a receiver can route on any field.
+type WebhookChannel struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+// NewWebhookChannel returns a WebhookChannel with a bounded HTTP client.
+// Header values support `$ENV_VAR` interpolation.
+func NewWebhookChannel(url string, headers map[string]string) *WebhookChannel {
+	resolved := make(map[string]string, len(headers))
+	for k, v := range headers {
+		resolved[k] = configenv.Expand(v)
+	}
+	return &WebhookChannel{
+		URL:     configenv.Expand(url),
+		Headers: resolved,
+		Client:  &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+func (w *WebhookChannel) Name() string { return "webhook" }
+
+func (w *WebhookChannel) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, w.Client, w.URL, w.Headers, msg)
+}
+
+// SlackChannel posts to a Slack-compatible incoming webhook.
+type SlackChannel struct {
+	URL      string
+	Channel  string
+	Username string
+	Client   *http.Client
+}
+
+// NewSlackChannel returns a SlackChannel with a bounded HTTP client. url
+// supports `$ENV_VAR` interpolation (incoming-webhook URLs are bearer secrets).
+func NewSlackChannel(url, channel, username string) *SlackChannel {
+	return &SlackChannel{
+		URL:      configenv.Expand(url),
+		Channel:  channel,
+		Username: username,
+		Client:   &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+func (s *SlackChannel) Name() string { return "slack" }
+
+// slackPayload is the incoming-webhook body. Only `text` is required; channel
+// and username are honoured by legacy webhooks and by Mattermost.
+type slackPayload struct {
+	Text     string `json:"text"`
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+func (s *SlackChannel) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, s.Client, s.URL, nil, slackPayload{
+		Text:     fmt.Sprintf("%s *%s*\n%s", severityEmoji(msg.Severity), msg.Title, msg.Text),
+		Channel:  s.Channel,
+		Username: s.Username,
+	})
+}
+
+// postJSON marshals body and POSTs it, treating any non-2xx status as an
+// error. Only the status line is surfaced — never the response body, which a
+// misbehaving proxy could fill with the request's own headers.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body interface{}) error {
+	if url == "" {
+		return fmt.Errorf("no URL configured")
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("POST returned %s", resp.Status)
+	}
+	return nil
+}
+
+// severityEmoji mirrors the CLI's alert emoji so a Slack message reads the
+// same as `adb alerts`.
+func severityEmoji(severity string) string {
+	switch severity {
+	case "High":
+		return "🔴"
+	case "Medium":
+		return "🟡"
+	case "Low":
+		return "🟢"
+	default:
+		return "⚠️"
+	}
+}
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookChannel_PostsMessageJSON(t *testing.T) {
+	var got Message
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	t.Setenv("ADB_TEST_HOOK_TOKEN", "Bearer s3cret")
+	ch := NewWebhookChannel(srv.URL, map[string]string{"Authorization": "$ADB_TEST_HOOK_TOKEN"})
+	msg := MessageFromAlert(blockedAlert("TASK-7"))
+	if err := ch.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.Key != "task_blocked_too_long:TASK-7" || got.TaskID != "TASK-7" || got.Severity != "High" {
+		t.Errorf("payload = %+v", got)
+	}
+	if auth != "Bearer s3cret" {
+		t.Errorf("Authorization = %q, want env-interpolated header", auth)
+	}
+}
+
+func TestWebhookChannel_Non2xxIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "secret-echo", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookChannel(srv.URL, nil).Send(context.Background(), Message{Key: "k"})
+	if err == nil {
+		t.Fatal("expected an error for 502")
+	}
+	if !strings.Contains(err.Error(), "502") || strings.Contains(err.Error(), "secret-echo") {
+		t.Errorf("error = %q, want the status line and never the response body", err)
+	}
+}
+
+func TestSlackChannel_PostsIncomingWebhookPayload(t *testing.T) {
+	var got slackPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	ch := NewSlackChannel(srv.URL, "#alerts", "adb")
+	if err := ch.Send(context.Background(), MessageFromAlert(blockedAlert("TASK-7"))); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.Channel != "#alerts" || got.Username != "adb" {
+		t.Errorf("payload routing = %+v", got)
+	}
+	if !strings.Contains(got.Text, "TASK-7") || !strings.HasPrefix(got.Text, "🔴") {
+		t.Errorf("text = %q, want severity emoji + task id", got.Text)
+	}
+}
+
+func TestNotifier_EndToEndThroughHTTPStandIn(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if hits == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n := NewNotifier([]Channel{NewWebhookChannel(srv.URL, nil)}, nil, withSleep(noSleep))
+	got, err := n.Notify(context.Background(), []Message{{Key: "k"}})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got[0].Status != DeliverySent || got[0].Attempts != 2 || hits != 2 {
+		t.Fatalf("delivery = %+v hits=%d, want sent on the retry", got[0], hits)
+	}
+}
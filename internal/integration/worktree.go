@@ -6,7 +6,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // validTaskID matches safe task IDs: alphanumeric with dashes and underscores
@@ -86,6 +88,10 @@ type WorktreeStatus struct {
 	Dirty  bool   `json:"dirty"`
 	Ahead  int    `json:"ahead"`
 	Behind int    `json:"behind"`
+
+	// LastModified is the newest mtime among the changed paths — when the
+	// uncommitted work was last touched. Zero for a clean worktree.
+	LastModified time.Time `json:"last_modified,omitempty"`
 }
 
 // WorktreeInfo represents information about a git worktree
@@ -637,11 +643,35 @@ func (m *DefaultGitWorktreeManager) WorktreeStatus(worktreePath string) (Worktre
 		}
 		if strings.TrimSpace(line) != "" {
 			st.Dirty = true
+			if mod := statusPathModTime(worktreePath, line); mod.After(st.LastModified) {
+				st.LastModified = mod
+			}
 		}
 	}
 	return st, nil
 }
 
+// statusPathModTime returns the mtime of the path named by one porcelain v1
+// status line ("XY path" or "XY old -> new"), or zero if it no longer exists
+// (a deletion).
+func statusPathModTime(worktreePath, line string) time.Time {
+	if len(line) < 4 {
+		return time.Time{}
+	}
+	p := line[3:]
+	if i := strings.Index(p, " -> "); i >= 0 {
+		p = p[i+4:]
+	}
+	if unq, err := strconv.Unquote(p); err == nil {
+		p = unq
+	}
+	info, err := os.Stat(filepath.Join(worktreePath, p))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
 // parseStatusBranchLine parses the "## " header of `git status --branch`:
 //
 //	"main...origin/main [ahead 1, behind 2]" → ("main", 1, 2)
@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNormalizeRepoPath(t *testing.T) {
@@ -576,8 +577,14 @@ func TestWorktreeStatus(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(worktreePath, "scratch.txt"), []byte("wip"), 0o644); err != nil {
 		t.Fatalf("write scratch: %v", err)
 	}
+	touched := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filepath.Join(worktreePath, "scratch.txt"), touched, touched); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
 	if st, err := manager.WorktreeStatus(worktreePath); err != nil || !st.Dirty {
 		t.Errorf("dirtied worktree: got Dirty=%v err=%v, want true/nil", st.Dirty, err)
+	} else if !st.LastModified.Equal(touched) {
+		t.Errorf("LastModified = %v, want the dirty file's mtime %v", st.LastModified, touched)
 	}
 
 	// Missing worktree → Exists=false, no error.
@@ -2,7 +2,9 @@
 // serialise access to shared on-disk state — the task-ID counter
 // (internal/core/taskid.go) and backlog.yaml (internal/storage/backlog.go).
 //
-// Lock blocks until the lock is available and returns a release function. The
+// Lock blocks until the lock is available and returns a release function.
+// LockShared is its reader counterpart: shared holders exclude only Lock, as
+// the event log's segment readers do against sealing and compaction. The
 // platform-specific implementations live in lock_unix.go (BSD flock, advisory)
 // and lock_windows.go (LockFileEx, mandatory). Callers should serialise
 // same-process access with an in-process mutex before taking the file lock, so
@@ -19,3 +19,13 @@ func Lock(f *os.File) (func(), error) {
 	}
 	return func() { _ = unix.Flock(fd, unix.LOCK_UN) }, nil
 }
+
+// LockShared acquires an advisory shared lock on the given file: any number of
+// holders at once, excluded only by Lock. Otherwise as Lock.
+func LockShared(f *os.File) (func(), error) {
+	fd := int(f.Fd())
+	if err := unix.Flock(fd, unix.LOCK_SH); err != nil {
+		return func() {}, err
+	}
+	return func() { _ = unix.Flock(fd, unix.LOCK_UN) }, nil
+}
@@ -24,3 +24,16 @@ func Lock(f *os.File) (func(), error) {
 		_ = windows.UnlockFileEx(handle, 0, 0xFFFFFFFF, 0xFFFFFFFF, &ol)
 	}, nil
 }
+
+// LockShared acquires a shared lock on the given file using LockFileEx: any
+// number of holders at once, excluded only by Lock. Otherwise as Lock.
+func LockShared(f *os.File) (func(), error) {
+	handle := windows.Handle(f.Fd())
+	var ol windows.Overlapped
+	if err := windows.LockFileEx(handle, 0, 0, 0xFFFFFFFF, 0xFFFFFFFF, &ol); err != nil {
+		return func() {}, err
+	}
+	return func() {
+		_ = windows.UnlockFileEx(handle, 0, 0xFFFFFFFF, 0xFFFFFFFF, &ol)
+	}, nil
+}
@@ -0,0 +1,453 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal"
+	"github.com/valter-silva-au/ai-dev-brain/internal/core"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// Knowledge-capture tools let an agent persist what it learns as it works —
+// decisions, learnings, gotchas, ADRs, tech debt, notes, stakeholder
+// communications — instead of waiting for the task-completed hook. Each
+// validates its arguments the way the matching CLI command does and calls
+// the same manager. The write tools are listed in gatedWriteTools: they are
+// refused until the workspace allowlists them under mcp.write_tools in
+// .taskrc. adb_events_query is read-only and always available.
+
+// gatedWriteTools are the write tools a workspace must opt into.
+var gatedWriteTools = map[string]bool{
+	"adb_record_decision": true,
+	"adb_record_learning": true,
+	"adb_record_gotcha":   true,
+	"adb_adr_new":         true,
+	"adb_debt_add":        true,
+	"adb_notes_append":    true,
+	"adb_comm_log":        true,
+}
+
+// defaultEventsLimit caps adb_events_query when no limit is given.
+const defaultEventsLimit = 50
+
+// writeToolAllowed reports whether the workspace config lets name run. Tools
+// outside gatedWriteTools are always allowed. toolGuard enforces it twice: a
+// tool that is not allowed is left out of tools/list, and a call to it is
+// refused with a pointer to mcp.write_tools rather than "tool not found".
+func writeToolAllowed(app *internal.App, name string) bool {
+	if !gatedWriteTools[name] {
+		return true
+	}
+	if app == nil || app.MergedConfig == nil || app.MergedConfig.Repo == nil {
+		return false
+	}
+	for _, t := range app.MergedConfig.Repo.MCP.WriteTools {
+		if t == "*" || t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// registerCaptureTools wires the knowledge-capture and event-query tools.
+func registerCaptureTools(s *server.MCPServer, app *internal.App) {
+	s.AddTool(mcp.NewTool("adb_record_decision",
+		mcp.WithDescription("Record a decision made while working on a task in its knowledge base (tickets/<id>/knowledge/decisions.yaml), so it is published to the wiki and survives the session."),
+		mcp.WithString("task_id", mcp.Required(), mcp.Description("The task the decision belongs to, e.g. TASK-00001.")),
+		mcp.WithString("title", mcp.Required(), mcp.Description("The decision in a short phrase.")),
+		mcp.WithString("description", mcp.Required(), mcp.Description("What was decided.")),
+		mcp.WithString("rationale", mcp.Description("Why it was decided.")),
+		mcp.WithString("context", mcp.Description("The situation that forced the decision.")),
+		mcp.WithArray("alternatives", mcp.WithStringItems(), mcp.Description("Options considered and not taken.")),
+		mcp.WithArray("consequences", mcp.WithStringItems(), mcp.Description("What follows from the decision, good and bad.")),
+		mcp.WithString("status", mcp.Enum("proposed", "accepted", "rejected", "deprecated"), mcp.Description("Decision status (default accepted).")),
+		mcp.WithArray("tags", mcp.WithStringItems(), mcp.Description("Optional tags.")),
+	), handleRecordDecision(app))
+
+	s.AddTool(mcp.NewTool("adb_record_learning",
+		mcp.WithDescription("Record a learning or insight from a task in its knowledge base."),
+		mcp.WithString("task_id", mcp.Required(), mcp.Description("The task the learning came from, e.g. TASK-00001.")),
+		mcp.WithString("title", mcp.Required(), mcp.Description("The learning in a short phrase.")),
+		mcp.WithString("description", mcp.Required(), mcp.Description("What was learned.")),
+		mcp.WithString("category", mcp.Enum("technical", "process", "domain"), mcp.Description("Optional category.")),
+		mcp.WithArray("tags", mcp.WithStringItems(), mcp.Description("Optional tags.")),
+	), handleRecordLearning(app))
+
+	s.AddTool(mcp.NewTool("adb_record_gotcha",
+		mcp.WithDescription("Record a gotcha — a pitfall hit while working on a task, with how to solve and avoid it — in the task's knowledge base."),
+		mcp.WithString("task_id", mcp.Required(), mcp.Description("The task it was hit on, e.g. TASK-00001.")),
+		mcp.WithString("title", mcp.Required(), mcp.Description("The gotcha in a short phrase.")),
+		mcp.WithString("description", mcp.Required(), mcp.Description("What went wrong.")),
+		mcp.WithString("solution", mcp.Description("How it was solved.")),
+		mcp.WithString("prevention", mcp.Description("How to avoid it next time.")),
+		mcp.WithString("severity", mcp.Enum("low", "medium", "high", "critical"), mcp.Description("Optional severity.")),
+		mcp.WithArray("tags", mcp.WithStringItems(), mcp.Description("Optional tags.")),
+	), handleRecordGotcha(app))
+
+	s.AddTool(mcp.NewTool("adb_adr_new",
+		mcp.WithDescription("Create the next-numbered architecture decision record (status proposed) with a MADR skeleton under docs/adr/. Returns the number and file to fill in."),
+		mcp.WithString("title", mcp.Required(), mcp.Description("The decision, e.g. \"Use SQLite for vector memory\".")),
+		mcp.WithString("relates_to", mcp.Description("Optional ticket or initiative id the ADR relates to; added as a relates_to graph edge.")),
+	), handleADRNew(app))
+
+	s.AddTool(mcp.NewTool("adb_debt_add",
+		mcp.WithDescription("Log a tech-debt item in the workspace's debt registry."),
+		mcp.WithString("title", mcp.Required(), mcp.Description("The debt in a short phrase.")),
+		mcp.WithString("area", mcp.Description("Subsystem or package the debt lives in.")),
+		mcp.WithString("note", mcp.Description("Optional detail.")),
+		mcp.WithString("priority", mcp.Enum("P0", "P1", "P2", "P3"), mcp.Description("Priority (default P2).")),
+	), handleDebtAdd(app))
+
+	s.AddTool(mcp.NewTool("adb_notes_append",
+		mcp.WithDescription("Append a note to a task's notes.md — the same file served as adb://task/<id>/notes."),
+		mcp.WithString("task_id", mcp.Required(), mcp.Description("The task ID, e.g. TASK-00001.")),
+		mcp.WithString("text", mcp.Required(), mcp.Description("Markdown to append.")),
+		mcp.WithString("heading", mcp.Description("Optional section heading; a dated one is used when omitted.")),
+	), handleNotesAppend(app))
+
+	s.AddTool(mcp.NewTool("adb_comm_log",
+		mcp.WithDescription("Log a stakeholder communication (received or sent) against a task, stored under its communications/ directory."),
+		mcp.WithString("task_id", mcp.Required(), mcp.Description("The task ID, e.g. TASK-00001.")),
+		mcp.WithString("direction", mcp.Required(), mcp.Enum("inbound", "outbound"), mcp.Description("inbound (received) or outbound (sent).")),
+		mcp.WithString("content", mcp.Required(), mcp.Description("The communication content.")),
+		mcp.WithString("from", mcp.Description("Who it is from.")),
+		mcp.WithArray("to", mcp.WithStringItems(), mcp.Description("Recipients.")),
+		mcp.WithString("subject", mcp.Description("Subject line.")),
+		mcp.WithString("channel", mcp.Description("Channel: email, slack, teams, meeting, …")),
+		mcp.WithArray("tags", mcp.WithStringItems(), mcp.Description("Tags, e.g. question, blocker.")),
+	), handleCommLog(app))
+
+	s.AddTool(mcp.NewTool("adb_events_query",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDescription("Query the workspace event log (task lifecycle, agent sessions, issue sync, stage gates, alerts, MCP requests), newest last. Returns JSON."),
+		mcp.WithString("type", mcp.Description("Optional event type, e.g. task.status_changed or agent.session_ended.")),
+		mcp.WithString("task_id", mcp.Description("Optional task ID to match against data.task_id.")),
+		mcp.WithString("since", mcp.Description("Optional window, e.g. 24h, 7d, 2w.")),
+		mcp.WithNumber("limit", mcp.Min(1), mcp.Description(fmt.Sprintf("Max events, newest kept (default %d).", defaultEventsLimit))),
+	), handleEventsQuery(app))
+}
+
+// requireTask resolves a task_id argument to a backlog task.
+func requireTask(app *internal.App, req mcp.CallToolRequest) (*models.Task, *mcp.CallToolResult) {
+	id, err := req.RequireString("task_id")
+	if err != nil || strings.TrimSpace(id) == "" {
+		return nil, mcp.NewToolResultError("invalid arguments: task_id is required")
+	}
+	task, err := app.BacklogManager.GetTask(strings.TrimSpace(id))
+	if err != nil {
+		return nil, mcp.NewToolResultErrorFromErr("unknown task", err)
+	}
+	return task, nil
+}
+
+// requireText returns a required, non-blank string argument.
+func requireText(req mcp.CallToolRequest, key string) (string, *mcp.CallToolResult) {
+	v, err := req.RequireString(key)
+	if err != nil || strings.TrimSpace(v) == "" {
+		return "", mcp.NewToolResultError(fmt.Sprintf("invalid arguments: %s is required", key))
+	}
+	return strings.TrimSpace(v), nil
+}
+
+// optionalEnum returns key's value (or def) when it is one of allowed.
+func optionalEnum(req mcp.CallToolRequest, key, def string, allowed ...string) (string, *mcp.CallToolResult) {
+	v := strings.TrimSpace(req.GetString(key, ""))
+	if v == "" {
+		return def, nil
+	}
+	for _, a := range allowed {
+		if v == a {
+			return v, nil
+		}
+	}
+	return "", mcp.NewToolResultError(fmt.Sprintf("invalid %s %q (must be one of %s)", key, v, strings.Join(allowed, ", ")))
+}
+
+func knowledgeExtractor(app *internal.App) *core.KnowledgeExtractor {
+	ke := core.NewKnowledgeExtractor(app.BasePath)
+	if app.SessionStoreManager != nil {
+		ke.SetSessionStore(app.SessionStoreManager)
+	}
+	return ke
+}
+
+func handleRecordDecision(app *internal.App) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		task, bad := requireTask(app, req)
+		if bad != nil {
+			return bad, nil
+		}
+		title, bad := requireText(req, "title")
+		if bad != nil {
+			return bad, nil
+		}
+		description, bad := requireText(req, "description")
+		if bad != nil {
+			return bad, nil
+		}
+		status, bad := optionalEnum(req, "status", "accepted", "proposed", "accepted", "rejected", "deprecated")
+		if bad != nil {
+			return bad, nil
+		}
+		ke := knowledgeExtractor(app)
+		existing, err := ke.LoadKnowledge(task.ID)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to load knowledge", err), nil
+		}
+		d := models.Decision{
+			ID:           fmt.Sprintf("DEC-%03d", len(existing.Decisions)+1),
+			Title:        title,
+			Description:  description,
+			Context:      req.GetString("context", ""),
+			Rationale:    req.GetString("rationale", ""),
+			Alternatives: req.GetStringSlice("alternatives", nil),
+			Consequences: req.GetStringSlice("consequences", nil),
+			Status:       status,
+			DecidedBy:    "mcp",
+			DecidedAt:    time.Now().UTC(),
+			Tags:         req.GetStringSlice("tags", nil),
+		}
+		if c, ok := clientFrom(ctx); ok {
+			d.DecidedBy = c.Name
+		}
+		if err := ke.AddDecision(task.ID, d); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to record decision", err), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Recorded decision %s on %s: %s", d.ID, task.ID, title)), nil
+	}
+}
+
+func handleRecordLearning(app *internal.App) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		task, bad := requireTask(app, req)
+		if bad != nil {
+			return bad, nil
+		}
+		title, bad := requireText(req, "title")
+		if bad != nil {
+			return bad, nil
+		}
+		description, bad := requireText(req, "description")
+		if bad != nil {
+			return bad, nil
+		}
+		category, bad := optionalEnum(req, "category", "", "technical", "process", "domain")
+		if bad != nil {
+			return bad, nil
+		}
+		if err := knowledgeExtractor(app).AddLearning(task.ID, models.Learning{
+			Title:       title,
+			Description: description,
+			Category:    category,
+			Tags:        req.GetStringSlice("tags", nil),
+			Timestamp:   time.Now().UTC(),
+		}); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to record learning", err), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Recorded learning on %s: %s", task.ID, title)), nil
+	}
+}
+
+func handleRecordGotcha(app *internal.App) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		task, bad := requireTask(app, req)
+		if bad != nil {
+			return bad, nil
+		}
+		title, bad := requireText(req, "title")
+		if bad != nil {
+			return bad, nil
+		}
+		description, bad := requireText(req, "description")
+		if bad != nil {
+			return bad, nil
+		}
+		severity, bad := optionalEnum(req, "severity", "", "low", "medium", "high", "critical")
+		if bad != nil {
+			return bad, nil
+		}
+		if err := knowledgeExtractor(app).AddGotcha(task.ID, models.Gotcha{
+			Title:       title,
+			Description: description,
+			Solution:    req.GetString("solution", ""),
+			Prevention:  req.GetString("prevention", ""),
+			Severity:    severity,
+			Tags:        req.GetStringSlice("tags", nil),
+			Timestamp:   time.Now().UTC(),
+		}); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to record gotcha", err), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Recorded gotcha on %s: %s", task.ID, title)), nil
+	}
+}
+
+func handleADRNew(app *internal.App) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		title, bad := requireText(req, "title")
+		if bad != nil {
+			return bad, nil
+		}
+		var links []models.Link
+		if rel := strings.TrimSpace(req.GetString("relates_to", "")); rel != "" {
+			links = append(links, models.Link{Type: models.EdgeRelatesTo, Target: rel})
+		}
+		adr, err := app.ADRManager.New(title, links)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to create ADR", err), nil
+		}
+		return jsonResult(map[string]any{
+			"number": adr.Number,
+			"title":  adr.Title,
+			"status": string(adr.Status),
+			"file":   filepath.ToSlash(filepath.Join("docs", "adr", adr.Filename())),
+			"uri":    fmt.Sprintf("%sadr/%d", resourceScheme, adr.Number),
+		})
+	}
+}
+
+func handleDebtAdd(app *internal.App) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		title, bad := requireText(req, "title")
+		if bad != nil {
+			return bad, nil
+		}
+		// An empty priority defaults to P2 inside DebtManager.Add, which also
+		// rejects anything outside P0..P3 exactly as `adb debt add` does.
+		item, err := app.DebtManager.Add(title, req.GetString("area", ""), req.GetString("note", ""),
+			models.Priority(strings.TrimSpace(req.GetString("priority", ""))))
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to add debt item", err), nil
+		}
+		return jsonResult(map[string]any{
+			"id":       item.ID,
+			"title":    item.Title,
+			"priority": string(item.Priority),
+			"area":     item.Area,
+		})
+	}
+}
+
+func handleNotesAppend(app *internal.App) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		task, bad := requireTask(app, req)
+		if bad != nil {
+			return bad, nil
+		}
+		text, bad := requireText(req, "text")
+		if bad != nil {
+			return bad, nil
+		}
+		dir := taskDir(app, *task)
+		if dir == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("task %s has no ticket directory", task.ID)), nil
+		}
+		heading := strings.TrimSpace(req.GetString("heading", ""))
+		if heading == "" {
+			heading = time.Now().Format("2006-01-02 15:04")
+		}
+		path := filepath.Join(dir, "notes.md")
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to open notes.md", err), nil
+		}
+		defer f.Close()
+		if _, err := fmt.Fprintf(f, "\n## %s\n\n%s\n", heading, text); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to append to notes.md", err), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Appended to %s notes (%s)", task.ID, path)), nil
+	}
+}
+
+func handleCommLog(app *internal.App) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if app.CommunicationManager == nil {
+			return mcp.NewToolResultError("communications are not available in this workspace"), nil
+		}
+		task, bad := requireTask(app, req)
+		if bad != nil {
+			return bad, nil
+		}
+		dir := models.CommunicationDirection(strings.TrimSpace(req.GetString("direction", "")))
+		if !dir.IsValid() {
+			return mcp.NewToolResultError("invalid arguments: direction must be inbound or outbound"), nil
+		}
+		content, bad := requireText(req, "content")
+		if bad != nil {
+			return bad, nil
+		}
+		comm := models.NewCommunication(models.NewCommunicationID(), task.ID, content)
+		comm.Direction = dir
+		comm.From = req.GetString("from", "")
+		comm.To = req.GetStringSlice("to", []string{})
+		comm.Subject = req.GetString("subject", "")
+		comm.Channel = req.GetString("channel", "")
+		for _, tg := range req.GetStringSlice("tags", nil) {
+			if tg = strings.TrimSpace(tg); tg != "" {
+				comm.AddTag(models.CommunicationTag(tg))
+			}
+		}
+		if err := app.CommunicationManager.SaveCommunication(comm); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to save communication", err), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Logged %s communication %s on %s", dir, comm.ID, task.ID)), nil
+	}
+}
+
+// eventView is the JSON shape adb_events_query returns for an event.
+type eventView struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Type      string         `json:"type"`
+	Data      map[string]any `json:"data,omitempty"`
+}
+
+func handleEventsQuery(app *internal.App) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if app.EventLog == nil {
+			return mcp.NewToolResultError("the event log is not available in this workspace"), nil
+		}
+		var cutoff time.Time
+		if since := strings.TrimSpace(req.GetString("since", "")); since != "" {
+			d, err := observability.ParseAlertDuration(since)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid since", err), nil
+			}
+			cutoff = time.Now().UTC().Add(-d)
+		}
+		var types []observability.EventType
+		if t := strings.TrimSpace(req.GetString("type", "")); t != "" {
+			types = append(types, observability.EventType(t))
+		}
+		limit := req.GetInt("limit", defaultEventsLimit)
+		if limit < 1 {
+			return mcp.NewToolResultError("invalid limit: must be at least 1"), nil
+		}
+		events, err := app.EventLog.ReadRange(cutoff, time.Time{}, types...)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to read event log", err), nil
+		}
+		taskID := strings.TrimSpace(req.GetString("task_id", ""))
+		views := []eventView{}
+		for _, e := range events {
+			if taskID != "" {
+				if id, _ := e.Data["task_id"].(string); id != taskID {
+					continue
+				}
+			}
+			views = append(views, eventView{Timestamp: e.Timestamp, Type: string(e.Type), Data: e.Data})
+		}
+		total := len(views)
+		if total > limit {
+			views = views[total-limit:]
+		}
+		return jsonResult(map[string]any{"total": total, "count": len(views), "events": views})
+	}
+}
@@ -0,0 +1,209 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal"
+	"github.com/valter-silva-au/ai-dev-brain/internal/core"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// runTool runs one tool through the server and returns its text and
+// whether it is an error result.
+func runTool(t *testing.T, s *server.MCPServer, name string, args map[string]any) (string, bool) {
+	t.Helper()
+	res := rpc(t, s, "tools/call", map[string]any{"name": name, "arguments": args})
+	if res["error"] != nil {
+		t.Fatalf("%s: %v", name, res["error"])
+	}
+	text := res["content"].([]any)[0].(map[string]any)["text"].(string)
+	isErr, _ := res["isError"].(bool)
+	return text, isErr
+}
+
+func toolNames(t *testing.T, s *server.MCPServer) string {
+	t.Helper()
+	var names []string
+	for _, tool := range rpc(t, s, "tools/list", nil)["tools"].([]any) {
+		names = append(names, tool.(map[string]any)["name"].(string))
+	}
+	return strings.Join(names, ",")
+}
+
+func allowWriteTools(app *internal.App, tools ...string) {
+	if app.MergedConfig.Repo == nil {
+		app.MergedConfig.Repo = &models.RepoConfig{}
+	}
+	app.MergedConfig.Repo.MCP.WriteTools = tools
+}
+
+// TestCaptureTools_Allowlist: gated write tools are hidden and refused until
+// the workspace allowlists them; adb_events_query is never gated.
+func TestCaptureTools_Allowlist(t *testing.T) {
+	app, _ := seedResourceApp(t)
+	s := New(app, "test")
+
+	names := toolNames(t, s)
+	if strings.Contains(names, "adb_record_decision") || !strings.Contains(names, "adb_events_query") {
+		t.Errorf("default tools = %s", names)
+	}
+	text, isErr := runTool(t, s, "adb_debt_add", map[string]any{"title": "flaky test"})
+	if !isErr || !strings.Contains(text, "mcp.write_tools") {
+		t.Errorf("un-allowlisted call = %q (error %v), want a pointer to mcp.write_tools", text, isErr)
+	}
+	if items, _ := app.DebtManager.List(); len(items) != 0 {
+		t.Errorf("a refused call must not write: %v", items)
+	}
+
+	allowWriteTools(app, "adb_debt_add")
+	if names := toolNames(t, s); !strings.Contains(names, "adb_debt_add") || strings.Contains(names, "adb_adr_new") {
+		t.Errorf("allowlisted tools = %s", names)
+	}
+	if text, isErr := runTool(t, s, "adb_debt_add", map[string]any{"title": "flaky test"}); isErr {
+		t.Errorf("allowlisted call = %q, want success", text)
+	}
+	if items, _ := app.DebtManager.List(); len(items) != 1 {
+		t.Errorf("allowlisted call wrote %d debt items, want 1", len(items))
+	}
+	allowWriteTools(app, "*")
+	if names := toolNames(t, s); !strings.Contains(names, "adb_comm_log") {
+		t.Errorf("\"*\" should expose every write tool: %s", names)
+	}
+}
+
+func TestCaptureTools_Knowledge(t *testing.T) {
+	app, _ := seedResourceApp(t)
+	allowWriteTools(app, "*")
+	s := New(app, "test")
+
+	if text, isErr := runTool(t, s, "adb_record_decision", map[string]any{
+		"task_id": "TASK-00001", "title": "Poll, don't watch", "description": "Poll mtimes every 2s",
+		"alternatives": []string{"fsnotify"}, "tags": []string{"mcp"},
+	}); isErr {
+		t.Fatalf("decision: %s", text)
+	}
+	if text, isErr := runTool(t, s, "adb_record_learning", map[string]any{
+		"task_id": "TASK-00001", "title": "HandleMessage", "description": "drives the server without a transport", "category": "technical",
+	}); isErr {
+		t.Fatalf("learning: %s", text)
+	}
+	if text, isErr := runTool(t, s, "adb_record_gotcha", map[string]any{
+		"task_id": "TASK-00001", "title": "No schema validation", "description": "mcp-go trusts arguments", "severity": "high",
+	}); isErr {
+		t.Fatalf("gotcha: %s", text)
+	}
+
+	k, err := core.NewKnowledgeExtractor(app.BasePath).LoadKnowledge("TASK-00001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(k.Decisions) != 1 || k.Decisions[0].ID != "DEC-001" || k.Decisions[0].Status != "accepted" || k.Decisions[0].Alternatives[0] != "fsnotify" {
+		t.Errorf("decisions = %+v", k.Decisions)
+	}
+	if len(k.Learnings) != 1 || k.Learnings[0].Category != "technical" {
+		t.Errorf("learnings = %+v", k.Learnings)
+	}
+	if len(k.Gotchas) != 1 || k.Gotchas[0].Severity != "high" {
+		t.Errorf("gotchas = %+v", k.Gotchas)
+	}
+
+	for _, tc := range []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"adb_record_decision", map[string]any{"task_id": "TASK-09999", "title": "x", "description": "y"}, "unknown task"},
+		{"adb_record_decision", map[string]any{"task_id": "TASK-00001", "title": "x", "description": "y", "status": "maybe"}, "invalid status"},
+		{"adb_record_learning", map[string]any{"task_id": "TASK-00001", "title": " ", "description": "y"}, "title is required"},
+		{"adb_record_gotcha", map[string]any{"task_id": "TASK-00001", "title": "x", "description": "y", "severity": "dire"}, "invalid severity"},
+	} {
+		if text, isErr := runTool(t, s, tc.name, tc.args); !isErr || !strings.Contains(text, tc.want) {
+			t.Errorf("%s %v = %q (error %v), want %q", tc.name, tc.args, text, isErr, tc.want)
+		}
+	}
+}
+
+func TestCaptureTools_ADRDebtNotesComm(t *testing.T) {
+	app, dir := seedResourceApp(t)
+	allowWriteTools(app, "*")
+	s := New(app, "test")
+
+	text, isErr := runTool(t, s, "adb_adr_new", map[string]any{"title": "Gate MCP writes", "relates_to": "TASK-00001"})
+	if isErr {
+		t.Fatalf("adr: %s", text)
+	}
+	var adr map[string]any
+	_ = json.Unmarshal([]byte(text), &adr)
+	if adr["number"] != float64(2) || adr["status"] != "proposed" {
+		t.Errorf("adr = %v", adr)
+	}
+	if got, ok, err := app.ADRManager.Get(2); err != nil || !ok || len(got.Links) != 1 || got.Links[0].Target != "TASK-00001" {
+		t.Errorf("ADR 2 = %+v, %v, %v", got, ok, err)
+	}
+
+	if text, isErr := runTool(t, s, "adb_debt_add", map[string]any{"title": "Retry SSE", "area": "mcpserver"}); isErr || !strings.Contains(text, `"priority": "P2"`) {
+		t.Errorf("debt = %q (error %v)", text, isErr)
+	}
+	if text, isErr := runTool(t, s, "adb_debt_add", map[string]any{"title": "x", "priority": "p0"}); !isErr {
+		t.Errorf("a lowercase priority should be refused like the CLI does: %q", text)
+	}
+
+	if text, isErr := runTool(t, s, "adb_notes_append", map[string]any{"task_id": "TASK-00001", "text": "Resources notify.", "heading": "Progress"}); isErr {
+		t.Fatalf("notes: %s", text)
+	}
+	notes, _ := os.ReadFile(filepath.Join(dir, "notes.md"))
+	if !strings.HasPrefix(string(notes), "# Notes") || !strings.HasSuffix(string(notes), "## Progress\n\nResources notify.\n") {
+		t.Errorf("notes.md = %q", notes)
+	}
+
+	if text, isErr := runTool(t, s, "adb_comm_log", map[string]any{"task_id": "TASK-00001", "direction": "sideways", "content": "hi"}); !isErr || !strings.Contains(text, "inbound or outbound") {
+		t.Errorf("bad direction = %q (error %v)", text, isErr)
+	}
+	if text, isErr := runTool(t, s, "adb_comm_log", map[string]any{
+		"task_id": "TASK-00001", "direction": "inbound", "content": "Can we ship Friday?",
+		"from": "pm", "channel": "slack", "tags": []string{"question"},
+	}); isErr {
+		t.Fatalf("comm: %s", text)
+	}
+	comms, err := app.CommunicationManager.GetAllCommunications("TASK-00001")
+	if err != nil || len(comms) != 1 || comms[0].From != "pm" || comms[0].Direction != models.DirectionInbound || !comms[0].HasTag("question") {
+		t.Errorf("communications = %+v, %v", comms, err)
+	}
+}
+
+func TestEventsQuery(t *testing.T) {
+	app, _ := seedResourceApp(t)
+	s := New(app, "test")
+	app.EventLog.Log(observability.EventTaskCreated, map[string]interface{}{"task_id": "TASK-00001"})
+	app.EventLog.Log(observability.EventTaskStatusChanged, map[string]interface{}{"task_id": "TASK-00001"})
+	app.EventLog.Log(observability.EventTaskCreated, map[string]interface{}{"task_id": "TASK-00002"})
+
+	query := func(args map[string]any) map[string]any {
+		t.Helper()
+		text, isErr := runTool(t, s, "adb_events_query", args)
+		if isErr {
+			t.Fatalf("events %v: %s", args, text)
+		}
+		var out map[string]any
+		_ = json.Unmarshal([]byte(text), &out)
+		return out
+	}
+	if out := query(map[string]any{"task_id": "TASK-00001", "since": "1d"}); out["total"] != float64(2) {
+		t.Errorf("by task = %v", out)
+	}
+	if out := query(map[string]any{"type": "task.created", "limit": 1}); out["total"] != float64(2) || out["count"] != float64(1) {
+		t.Errorf("by type with limit = %v", out)
+	} else if e := out["events"].([]any)[0].(map[string]any); e["data"].(map[string]any)["task_id"] != "TASK-00002" {
+		t.Errorf("limit should keep the newest event: %v", e)
+	}
+	if text, isErr := runTool(t, s, "adb_events_query", map[string]any{"since": "yesterday"}); !isErr {
+		t.Errorf("a bad since should be refused: %q", text)
+	}
+}
@@ -3,7 +3,7 @@ package mcpserver
 import (
 	"context"
 	"fmt"
-	"sort"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -19,6 +19,7 @@ import (
 // there is no divergent logic between the CLI and MCP entry points.
 func registerGraphTools(s *server.MCPServer, app *internal.App) {
 	s.AddTool(mcp.NewTool("graph_neighbors",
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDescription("List the graph edges incident to an entity (both directions: edges it declares and edges declared toward it), optionally filtered by edge type. Entity ids look like TASK-00001 or an initiative id."),
 		mcp.WithString("id", mcp.Required(),
 			mcp.Description("The entity id, e.g. TASK-00001 or an initiative id."),
@@ -29,6 +30,7 @@ func registerGraphTools(s *server.MCPServer, app *internal.App) {
 	), handleGraphNeighbors(app))
 
 	s.AddTool(mcp.NewTool("related_tickets",
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDescription("List the tickets directly linked to a ticket in the graph, each with the relationship type and direction (outgoing = this ticket declares it; incoming = the other declares it)."),
 		mcp.WithString("id", mcp.Required(),
 			mcp.Description("The ticket id, e.g. TASK-00001."),
@@ -36,6 +38,7 @@ func registerGraphTools(s *server.MCPServer, app *internal.App) {
 	), handleRelatedTickets(app))
 
 	s.AddTool(mcp.NewTool("get_initiative",
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDescription("Get a founder-playbook initiative: its org, stage (Idea/MVP/Launch/Scale), and most recent stage-gate state."),
 		mcp.WithString("id", mcp.Required(),
 			mcp.Description("The initiative id (slug)."),
@@ -43,16 +46,27 @@ func registerGraphTools(s *server.MCPServer, app *internal.App) {
 	), handleGetInitiative(app))
 
 	s.AddTool(mcp.NewTool("search_knowledge",
-		mcp.WithDescription("Semantic search over the workspace's vector memory. Degrades gracefully — returns a clear notice, never an error — when memory is not configured for the workspace."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDescription("Search the workspace's memory store by exact terms (lexical), meaning (vector) or both (hybrid). Degrades gracefully — returns a clear notice, never an error — when memory is not configured for the workspace."),
 		mcp.WithString("query", mcp.Required(),
 			mcp.Description("The natural-language search query."),
 		),
 		mcp.WithString("namespace",
-			mcp.Description("Optional namespace to scope the search (e.g. tickets/TASK-00001). Omit to search across all namespaces."),
+			mcp.Description("Optional namespace or glob to scope the search (e.g. tickets/TASK-00001, tickets/*). Omit to search across all namespaces; matches are ranked together."),
 		),
 		mcp.WithNumber("limit",
 			mcp.Description("Max hits to return (default 5)."),
 		),
+		mcp.WithString("mode",
+			mcp.Description("Ranking: lexical (BM25; best for ticket IDs, function names, error strings), vector, or hybrid. Defaults to hybrid when an embedder is configured, else lexical."),
+			mcp.Enum("lexical", "vector", "hybrid"),
+		),
+		mcp.WithString("where",
+			mcp.Description("Optional metadata filter as key=value pairs, comma-separated (e.g. source=ticket-knowledge,task_id=TASK-00001)."),
+		),
+		mcp.WithNumber("min_score",
+			mcp.Description("Drop hits the ranker scores below this (0-1): cosine similarity in vector mode, BM25 mapped to 0-1 in lexical; hybrid floors each ranking before fusing them."),
+		),
 	), handleSearchKnowledge(app))
 }
 
@@ -143,9 +157,12 @@ func handleGetInitiative(app *internal.App) server.ToolHandlerFunc {
 }
 
 // knowledgeHit is the JSON shape returned for one search_knowledge result.
+// For a chunk of a larger document, Content is just the matching chunk and
+// Doc names the document it came from.
 type knowledgeHit struct {
 	Namespace string            `json:"namespace"`
 	Key       string            `json:"key"`
+	Doc       string            `json:"doc"`
 	Score     float32           `json:"score"`
 	Content   string            `json:"content"`
 	Meta      map[string]string `json:"meta,omitempty"`
@@ -162,6 +179,15 @@ func handleSearchKnowledge(app *internal.App) server.ToolHandlerFunc {
 			limit = 5
 		}
 		ns := req.GetString("namespace", "")
+		mode, err := memory.ParseSearchMode(req.GetString("mode", ""))
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid arguments", err), nil
+		}
+		where, err := parseWhere(req.GetString("where", ""))
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid arguments", err), nil
+		}
+		minScore := req.GetFloat("min_score", 0)
 
 		store, configured, err := app.OpenMemoryStore(ctx)
 		if err != nil {
@@ -183,7 +209,9 @@ func handleSearchKnowledge(app *internal.App) server.ToolHandlerFunc {
 		}
 		defer store.Close()
 
-		hits, err := searchKnowledge(ctx, store, ns, query, limit)
+		hits, err := store.SearchMulti(ctx, memory.Query{
+			Namespace: ns, Text: query, K: limit, Mode: mode, Where: where, MinScore: float32(minScore),
+		})
 		if err != nil {
 			return jsonResult(map[string]any{
 				"configured": true,
@@ -195,34 +223,25 @@ func handleSearchKnowledge(app *internal.App) server.ToolHandlerFunc {
 		views := make([]knowledgeHit, 0, len(hits))
 		for _, h := range hits {
 			views = append(views, knowledgeHit{
-				Namespace: h.Namespace, Key: h.Key, Score: h.Score, Content: h.Content, Meta: h.Meta,
+				Namespace: h.Namespace, Key: h.Key, Doc: h.Doc(), Score: h.Score, Content: h.Content, Meta: h.Meta,
 			})
 		}
 		return jsonResult(map[string]any{"configured": true, "count": len(views), "hits": views})
 	}
 }
 
-// searchKnowledge searches one namespace when ns is set, otherwise every
-// namespace, merging the hits and returning the top `limit` by score.
-func searchKnowledge(ctx context.Context, store memory.Store, ns, query string, limit int) ([]memory.Hit, error) {
-	if ns != "" {
-		return store.Search(ctx, ns, query, limit)
+// parseWhere parses a "key=value,key=value" metadata filter.
+func parseWhere(s string) (map[string]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
 	}
-	names, err := store.ListNamespaces(ctx)
-	if err != nil {
-		return nil, err
-	}
-	var all []memory.Hit
-	for _, n := range names {
-		hits, err := store.Search(ctx, n, query, limit)
-		if err != nil {
-			return nil, err
+	where := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("where: %q is not key=value", pair)
 		}
-		all = append(all, hits...)
-	}
-	sort.SliceStable(all, func(i, j int) bool { return all[i].Score > all[j].Score })
-	if len(all) > limit {
-		all = all[:limit]
+		where[strings.TrimSpace(k)] = strings.TrimSpace(v)
 	}
-	return all, nil
+	return where, nil
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -200,6 +201,81 @@ func TestSearchKnowledge_Configured_ReturnsHits(t *testing.T) {
 	}
 }
 
+// TestSearchKnowledge_Modes: an exact ticket ID is found in lexical and
+// hybrid mode across namespaces, and an unknown mode is an argument error.
+func TestSearchKnowledge_Modes(t *testing.T) {
+	app, err := internal.NewApp(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	ctx := context.Background()
+	dbPath := app.StatePath("memory.sqlite")
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := memory.OpenSQLiteStore(ctx, dbPath, memory.NewFakeEmbedder(64))
+	if err != nil {
+		t.Fatalf("seed store: %v", err)
+	}
+	_ = store.Upsert(ctx, "tickets/TASK-00001", "note1", "blocked on TASK-00007 landing first", nil)
+	_ = store.Upsert(ctx, "tickets/TASK-00002", "note1", "the ranking algorithm favours recency", nil)
+	_ = store.Close()
+
+	for _, mode := range []string{"lexical", "hybrid"} {
+		out := callTool(t, handleSearchKnowledge(app), map[string]any{"query": "TASK-00007", "mode": mode, "limit": 1})
+		hits, _ := out["hits"].([]any)
+		if len(hits) != 1 || hits[0].(map[string]any)["namespace"] != "tickets/TASK-00001" {
+			t.Errorf("mode %s: hits=%v", mode, out["hits"])
+		}
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"query": "x", "mode": "fuzzy"}
+	res, err := handleSearchKnowledge(app)(ctx, req)
+	if err != nil || !res.IsError {
+		t.Errorf("unknown mode: res=%v err=%v, want an error result", res, err)
+	}
+}
+
+func TestSearchKnowledge_GlobAndWhere(t *testing.T) {
+	app, err := internal.NewApp(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	ctx := context.Background()
+	dbPath := app.StatePath("memory.sqlite")
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := memory.OpenSQLiteStore(ctx, dbPath, memory.NewFakeEmbedder(64))
+	if err != nil {
+		t.Fatalf("seed store: %v", err)
+	}
+	_ = store.Upsert(ctx, "tickets/TASK-00001", "context.md", "webhook retries", map[string]string{"source": "ticket-knowledge"})
+	_ = store.Upsert(ctx, "tickets/TASK-00001", "edge", "webhook edge", map[string]string{"source": "graph-edge"})
+	_ = store.Upsert(ctx, "sessions/S-1", "summary", "webhook session", nil)
+	_ = store.Close()
+
+	out := callTool(t, handleSearchKnowledge(app), map[string]any{
+		"query": "webhook", "namespace": "tickets/*", "where": "source=ticket-knowledge", "limit": 10,
+	})
+	hits, _ := out["hits"].([]any)
+	if len(hits) != 1 || hits[0].(map[string]any)["key"] != "context.md" {
+		t.Errorf("glob+where hits = %v", out["hits"])
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"query": "webhook", "where": "source"}
+	if res, err := handleSearchKnowledge(app)(ctx, req); err != nil || !res.IsError {
+		t.Errorf("malformed where: res=%v err=%v, want an error result", res, err)
+	}
+	// A bad glob is reported as a notice, like any other search failure.
+	out = callTool(t, handleSearchKnowledge(app), map[string]any{"query": "webhook", "namespace": "tickets/["})
+	if notice, _ := out["notice"].(string); !strings.Contains(notice, "glob") {
+		t.Errorf("bad glob: %v", out)
+	}
+}
+
 func TestGraphAndTaskTools_Registered(t *testing.T) {
 	app, err := internal.NewApp(t.TempDir())
 	if err != nil {
@@ -0,0 +1,240 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+)
+
+// The HTTP transport lets one workspace on a shared box serve several agents
+// and editors at once. Both MCP HTTP transports are mounted behind bearer
+// auth: streamable HTTP at /mcp and the older SSE pair at /sse + /message.
+// Every request carries its token's name and scope in the context, which the
+// tool guard checks and the request log records.
+const (
+	httpEndpoint        = "/mcp"
+	sseEndpoint         = "/sse"
+	sseMessageEndpoint  = "/message"
+	httpShutdownTimeout = 5 * time.Second
+)
+
+// client is the authenticated caller of an HTTP request.
+type client struct {
+	Name  string
+	Scope Scope
+}
+
+type clientKey struct{}
+
+func withClient(ctx context.Context, c client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+// clientFrom returns the HTTP caller, if any. Stdio requests have none and
+// are trusted like the local CLI.
+func clientFrom(ctx context.Context) (client, bool) {
+	c, ok := ctx.Value(clientKey{}).(client)
+	return c, ok
+}
+
+// toolGuard wraps every tool call. A gated write tool the workspace has not
+// allowlisted is refused on every transport. Read-only tools (those
+// annotated with readOnlyHint) run concurrently; any other tool is refused for a read-scope
+// token and otherwise runs alone. Serialising writers in-process means a
+// multi-step tool (load a task, change it, save it) never interleaves with
+// another client's; the backlog's file lock still serialises each write
+// against adb processes outside the server.
+type toolGuard struct {
+	server *server.MCPServer
+	app    *internal.App
+	mu     sync.RWMutex
+}
+
+func (g *toolGuard) readOnly(name string) bool {
+	if g.server == nil {
+		return false
+	}
+	t := g.server.GetTool(name)
+	return t != nil && t.Tool.Annotations.ReadOnlyHint != nil && *t.Tool.Annotations.ReadOnlyHint
+}
+
+func (g *toolGuard) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !writeToolAllowed(g.app, req.Params.Name) {
+			return mcp.NewToolResultError(fmt.Sprintf("%s is not enabled in this workspace; add it to mcp.write_tools in .taskrc", req.Params.Name)), nil
+		}
+		if g.readOnly(req.Params.Name) {
+			g.mu.RLock()
+			defer g.mu.RUnlock()
+			return next(ctx, req)
+		}
+		if c, ok := clientFrom(ctx); ok && c.Scope != ScopeWrite {
+			return mcp.NewToolResultError(fmt.Sprintf("%s changes the workspace; token %q is read-only", req.Params.Name, c.Name)), nil
+		}
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		return next(ctx, req)
+	}
+}
+
+// listTools hides from tools/list the write tools the workspace has not
+// allowlisted and, for a read-scope token, every tool that is not read-only.
+// It runs as a tools/list hook rather than a server tool filter: mcp-go also
+// applies tool filters to tools/call, which would turn the middleware's
+// explanatory refusals into a bare "tool not found".
+func (g *toolGuard) listTools(ctx context.Context, _ any, _ *mcp.ListToolsRequest, result *mcp.ListToolsResult) {
+	if result != nil {
+		result.Tools = g.filter(ctx, result.Tools)
+	}
+}
+
+// filter returns the tools a caller may see in tools/list.
+func (g *toolGuard) filter(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	c, ok := clientFrom(ctx)
+	readScope := ok && c.Scope != ScopeWrite
+	out := make([]mcp.Tool, 0, len(tools))
+	for _, t := range tools {
+		if !writeToolAllowed(g.app, t.Name) {
+			continue
+		}
+		if readScope && (t.Annotations.ReadOnlyHint == nil || !*t.Annotations.ReadOnlyHint) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// addRequestLogHooks records every authenticated request as an mcp.request
+// event: who sent it, what it asked for, and whether it succeeded.
+func addRequestLogHooks(hooks *server.Hooks, log *observability.EventLog) {
+	hooks.AddOnSuccess(func(ctx context.Context, _ any, method mcp.MCPMethod, message any, result any) {
+		outcome := "ok"
+		if r, ok := result.(*mcp.CallToolResult); ok && r.IsError {
+			outcome = "error"
+		}
+		logRequest(ctx, log, method, message, outcome, nil)
+	})
+	hooks.AddOnError(func(ctx context.Context, _ any, method mcp.MCPMethod, message any, err error) {
+		logRequest(ctx, log, method, message, "error", err)
+	})
+}
+
+func logRequest(ctx context.Context, log *observability.EventLog, method mcp.MCPMethod, message any, outcome string, err error) {
+	c, ok := clientFrom(ctx)
+	if !ok || log == nil || method == mcp.MethodPing {
+		return
+	}
+	data := map[string]interface{}{
+		"client":  c.Name,
+		"scope":   string(c.Scope),
+		"method":  string(method),
+		"outcome": outcome,
+	}
+	switch m := message.(type) {
+	case *mcp.CallToolRequest:
+		data["target"] = m.Params.Name
+	case *mcp.ReadResourceRequest:
+		data["target"] = m.Params.URI
+	case *mcp.GetPromptRequest:
+		data["target"] = m.Params.Name
+	}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+	log.Log(observability.EventMCPRequest, data)
+}
+
+// authMiddleware admits requests bearing a known token and attaches its
+// identity to the request context. Everything else gets a 401, which is
+// logged too so a misconfigured or hostile client shows up in the event log.
+func authMiddleware(tokens *tokenSource, log *observability.EventLog, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		tok, found := tokens.lookup(strings.TrimSpace(secret))
+		if !ok || !found {
+			if log != nil {
+				log.Log(observability.EventMCPRequest, map[string]interface{}{
+					"method":  r.Method + " " + r.URL.Path,
+					"outcome": "unauthorized",
+					"remote":  r.RemoteAddr,
+				})
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="adb"`)
+			http.Error(w, "missing or unknown bearer token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withClient(r.Context(), client{Name: tok.Name, Scope: tok.Scope})))
+	})
+}
+
+// httpServer is the MCP server mounted on both HTTP transports.
+type httpServer struct {
+	handler http.Handler
+	watcher *resourceWatcher
+}
+
+// newHTTPServer builds the authenticated HTTP handler for app. tokenFile
+// must hold at least one token: an unauthenticated shared workspace is
+// never served.
+func newHTTPServer(app *internal.App, version, tokenFile string) (*httpServer, error) {
+	tokens, err := newTokenSource(tokenFile)
+	if err != nil {
+		return nil, err
+	}
+	if f, _ := tokens.current(); len(f.Tokens) == 0 {
+		return nil, fmt.Errorf("no MCP tokens in %s; create one with `adb mcp token add <name>`", tokenFile)
+	}
+	s, w := newServer(app, version, app.EventLog)
+	sse := server.NewSSEServer(s, server.WithSSEEndpoint(sseEndpoint), server.WithMessageEndpoint(sseMessageEndpoint))
+	mux := http.NewServeMux()
+	mux.Handle(httpEndpoint, server.NewStreamableHTTPServer(s, server.WithEndpointPath(httpEndpoint)))
+	mux.Handle(sseEndpoint, sse.SSEHandler())
+	mux.Handle(sseMessageEndpoint, sse.MessageHandler())
+	return &httpServer{handler: authMiddleware(tokens, app.EventLog, mux), watcher: w}, nil
+}
+
+// ServeHTTP serves the MCP server on ln until ctx is done, authenticating
+// every request against the bearer tokens in tokenFile. A change to a listed
+// resource is pushed to the clients that subscribed to it.
+func ServeHTTP(ctx context.Context, ln net.Listener, app *internal.App, version, tokenFile string) error {
+	h, err := newHTTPServer(app, version, tokenFile)
+	if err != nil {
+		return err
+	}
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go h.watcher.run(watchCtx, resourcePollInterval)
+
+	// Requests run under streams' own context so the long-lived SSE
+	// streams can be ended on shutdown; Shutdown alone waits on them.
+	streams, endStreams := context.WithCancel(context.Background())
+	defer endStreams()
+	srv := &http.Server{
+		Handler:           h.handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return streams },
+	}
+	go func() {
+		<-ctx.Done()
+		endStreams()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package mcpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
+)
+
+// httpClient drives the streamable HTTP transport with one bearer token.
+type httpClient struct {
+	t       *testing.T
+	url     string
+	token   string
+	session string
+}
+
+// call posts one JSON-RPC request and decodes its result (or error).
+func (c *httpClient) call(method string, params map[string]any) (int, map[string]any) {
+	c.t.Helper()
+	body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	req, _ := http.NewRequest(http.MethodPost, c.url+httpEndpoint, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.session != "" {
+		req.Header.Set("Mcp-Session-Id", c.session)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s: %v", method, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	if id := resp.Header.Get("Mcp-Session-Id"); id != "" {
+		c.session = id
+	}
+	var out struct {
+		Result map[string]any `json:"result"`
+		Error  map[string]any `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		c.t.Fatalf("decode %s: %v", method, err)
+	}
+	if out.Error != nil {
+		return resp.StatusCode, map[string]any{"error": out.Error}
+	}
+	return resp.StatusCode, out.Result
+}
+
+func (c *httpClient) initialize() {
+	c.t.Helper()
+	if code, res := c.call("initialize", map[string]any{
+		"protocolVersion": "2025-03-26",
+		"clientInfo":      map[string]any{"name": "test", "version": "1"},
+		"capabilities":    map[string]any{},
+	}); code != http.StatusOK || res["serverInfo"] == nil {
+		c.t.Fatalf("initialize = %d %v", code, res)
+	}
+}
+
+// callTool returns a tool call's text and whether it is an error result.
+func (c *httpClient) callTool(name string, args map[string]any) (string, bool) {
+	c.t.Helper()
+	_, res := c.call("tools/call", map[string]any{"name": name, "arguments": args})
+	if res["error"] != nil {
+		return fmt.Sprint(res["error"]), true
+	}
+	text := res["content"].([]any)[0].(map[string]any)["text"].(string)
+	isErr, _ := res["isError"].(bool)
+	return text, isErr
+}
+
+// seedHTTPServer serves a seeded workspace over HTTP with one read and one
+// write token.
+func seedHTTPServer(t *testing.T) (app *internal.App, url, readToken, writeToken string) {
+	t.Helper()
+	app, _ = seedResourceApp(t)
+	path := filepath.Join(app.BasePath, ".adb", "mcp_tokens.yaml")
+	f := &TokenFile{}
+	readToken, _ = f.Add("vscode", ScopeRead)
+	writeToken, _ = f.Add("agent", ScopeWrite)
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	h, err := newHTTPServer(app, "test", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(h.handler)
+	t.Cleanup(srv.Close)
+	return app, srv.URL, readToken, writeToken
+}
+
+func TestHTTP_RequiresToken(t *testing.T) {
+	app, url, _, _ := seedHTTPServer(t)
+	for _, token := range []string{"", "adb_not-a-token"} {
+		c := &httpClient{t: t, url: url, token: token}
+		if code, _ := c.call("initialize", nil); code != http.StatusUnauthorized {
+			t.Errorf("token %q: status %d, want 401", token, code)
+		}
+	}
+	events, _ := app.EventLog.ReadByType(observability.EventMCPRequest)
+	if len(events) != 2 || events[0].Data["outcome"] != "unauthorized" {
+		t.Errorf("unauthorized requests should be logged: %v", events)
+	}
+}
+
+func TestHTTP_RefusesWithoutTokens(t *testing.T) {
+	app, _ := seedResourceApp(t)
+	_, err := newHTTPServer(app, "test", filepath.Join(t.TempDir(), "none.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "adb mcp token add") {
+		t.Errorf("err = %v, want a hint to create a token", err)
+	}
+}
+
+// TestHTTP_Scopes: a read token sees and may call only the read-only tools,
+// plus resources and prompts; a write token may change the workspace. Every
+// request lands in the event log under its token's name.
+func TestHTTP_Scopes(t *testing.T) {
+	app, url, readToken, writeToken := seedHTTPServer(t)
+
+	reader := &httpClient{t: t, url: url, token: readToken}
+	reader.initialize()
+	_, list := reader.call("tools/list", nil)
+	var names []string
+	for _, tool := range list["tools"].([]any) {
+		names = append(names, tool.(map[string]any)["name"].(string))
+	}
+	if got := strings.Join(names, ","); strings.Contains(got, "adb_task_create") || !strings.Contains(got, "adb_task_list") || len(names) != 6 {
+		t.Errorf("read token tools = %s", got)
+	}
+	if text, isErr := reader.callTool("adb_task_list", nil); isErr || !strings.Contains(text, "TASK-00001") {
+		t.Errorf("read token list = %q (error %v)", text, isErr)
+	}
+	if text, isErr := reader.callTool("adb_task_close", map[string]any{"task_id": "TASK-00001"}); !isErr || !strings.Contains(text, "read-only") {
+		t.Errorf("read token close = %q (error %v), want a refusal", text, isErr)
+	}
+	if task, _ := app.BacklogManager.GetTask("TASK-00001"); task.Status == "done" {
+		t.Error("a read token must not change the backlog")
+	}
+	if _, res := reader.call("resources/read", map[string]any{"uri": "adb://task/TASK-00001/notes"}); res["contents"] == nil {
+		t.Errorf("read token resources/read = %v", res)
+	}
+
+	writer := &httpClient{t: t, url: url, token: writeToken}
+	writer.initialize()
+	if _, list := writer.call("tools/list", nil); len(list["tools"].([]any)) != 12 {
+		t.Errorf("write token should see every enabled tool: %v", list)
+	}
+	if text, isErr := writer.callTool("adb_task_close", map[string]any{"task_id": "TASK-00001"}); isErr {
+		t.Errorf("write token close = %q", text)
+	}
+
+	events, _ := app.EventLog.ReadByType(observability.EventMCPRequest)
+	var lines []string
+	for _, e := range events {
+		if e.Data["method"] == "tools/call" {
+			lines = append(lines, fmt.Sprintf("%s %s %s %s", e.Data["client"], e.Data["scope"], e.Data["target"], e.Data["outcome"]))
+		}
+	}
+	want := "vscode read adb_task_list ok|vscode read adb_task_close error|agent write adb_task_close ok"
+	if got := strings.Join(lines, "|"); got != want {
+		t.Errorf("logged tool calls =\n  %s\nwant\n  %s", got, want)
+	}
+}
+
+// TestHTTP_ConcurrentWriters: several clients creating and updating tasks
+// at once neither lose a write nor mint a duplicate ID.
+func TestHTTP_ConcurrentWriters(t *testing.T) {
+	app, url, _, writeToken := seedHTTPServer(t)
+	// The seeded TASK-00001 was added without minting its ID.
+	if _, err := app.TaskIDGenerator.GenerateTaskID(); err != nil {
+		t.Fatal(err)
+	}
+	const n = 8
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := &httpClient{t: t, url: url, token: writeToken}
+			c.initialize()
+			if text, isErr := c.callTool("adb_task_create", map[string]any{"title": fmt.Sprintf("parallel %d", i)}); isErr {
+				t.Errorf("create %d: %s", i, text)
+			}
+			if text, isErr := c.callTool("adb_task_update", map[string]any{"task_id": "TASK-00001", "owner": fmt.Sprintf("agent-%d", i)}); isErr {
+				t.Errorf("update %d: %s", i, text)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	backlog, err := app.BacklogManager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, task := range backlog.Tasks {
+		if seen[task.ID] {
+			t.Errorf("duplicate task ID %s", task.ID)
+		}
+		seen[task.ID] = true
+	}
+	if len(backlog.Tasks) != n+1 {
+		t.Errorf("backlog has %d tasks, want %d", len(backlog.Tasks), n+1)
+	}
+}
+
+func TestHTTP_ResourceSubscriptionsArePerSession(t *testing.T) {
+	app, _ := seedResourceApp(t)
+	path := filepath.Join(app.BasePath, ".adb", "mcp_tokens.yaml")
+	f := &TokenFile{}
+	token, _ := f.Add("vscode", ScopeRead)
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	h, err := newHTTPServer(app, "test", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(h.handler)
+	t.Cleanup(srv.Close)
+
+	const uri = "adb://task/TASK-00001/notes"
+	subscriber := &httpClient{t: t, url: srv.URL, token: token}
+	subscriber.initialize()
+	bystander := &httpClient{t: t, url: srv.URL, token: token}
+	bystander.initialize()
+	if _, res := subscriber.call("resources/subscribe", map[string]any{"uri": uri}); res["error"] != nil {
+		t.Fatalf("resources/subscribe = %v", res["error"])
+	}
+	if got := h.watcher.subs.sessions(uri); len(got) != 1 || got[0] != subscriber.session {
+		t.Fatalf("subscribers of %s = %v, want only %s", uri, got, subscriber.session)
+	}
+	if _, res := subscriber.call("resources/unsubscribe", map[string]any{"uri": uri}); res["error"] != nil {
+		t.Fatalf("resources/unsubscribe = %v", res["error"])
+	}
+	if got := h.watcher.subs.sessions(uri); len(got) != 0 {
+		t.Errorf("subscribers after unsubscribe = %v", got)
+	}
+}
@@ -0,0 +1,157 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// registerPrompts wires the prompts: ready-made requests, prefilled from the
+// workspace, that give any MCP client the context the Claude Code hooks
+// inject on their own.
+func registerPrompts(s *server.MCPServer, app *internal.App) {
+	s.AddPrompt(mcp.NewPrompt("resume_task",
+		mcp.WithPromptDescription("Pick a task back up: its metadata, context.md, notes.md and graph links, with a request to summarise where it stands and continue."),
+		mcp.WithArgument("task_id", mcp.RequiredArgument(), mcp.ArgumentDescription("The task ID, e.g. TASK-00001.")),
+	), handleResumeTaskPrompt(app))
+
+	s.AddPrompt(mcp.NewPrompt("write_handoff",
+		mcp.WithPromptDescription("Draft a handoff.md for a task from its context and notes, in the same sections adb's archive template uses."),
+		mcp.WithArgument("task_id", mcp.RequiredArgument(), mcp.ArgumentDescription("The task ID, e.g. TASK-00001.")),
+	), handleWriteHandoffPrompt(app))
+
+	s.AddPrompt(mcp.NewPrompt("propose_adr",
+		mcp.WithPromptDescription("Draft a MADR architecture decision record, aware of the existing ADRs and, optionally, of a task's context."),
+		mcp.WithArgument("title", mcp.RequiredArgument(), mcp.ArgumentDescription("The decision to record, e.g. \"Use SQLite for vector memory\".")),
+		mcp.WithArgument("task_id", mcp.ArgumentDescription("Optional task whose context motivates the decision.")),
+	), handleProposeADRPrompt(app))
+}
+
+func handleResumeTaskPrompt(app *internal.App) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		task, brief, err := taskBrief(app, req.Params.Arguments["task_id"])
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "I'm resuming work on %s. Here is what adb has on it.\n\n%s", task.ID, brief)
+		b.WriteString("Summarise where the task stands in a few lines — what is done, what is in flight, " +
+			"and any open questions or blockers — then propose the next concrete step and start on it. " +
+			"Keep context.md up to date as you go.")
+		return promptResult("Resume "+task.ID, b.String()), nil
+	}
+}
+
+func handleWriteHandoffPrompt(app *internal.App) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		task, brief, err := taskBrief(app, req.Params.Arguments["task_id"])
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "Write a handoff for %s so someone else can pick it up cold.\n\n%s", task.ID, brief)
+		fmt.Fprintf(&b, "Write it as markdown titled \"# Handoff: %s\" with these sections:\n\n", task.Title)
+		for _, section := range []string{
+			"Summary — two or three sentences",
+			"What Was Done — a bullet per completed item",
+			"Key Decisions — each with its rationale",
+			"Open Items — as unchecked checkboxes",
+			"Next Steps",
+			"References — PRs, ADRs, docs, related tickets",
+		} {
+			fmt.Fprintf(&b, "- %s\n", section)
+		}
+		b.WriteString("\nOnly state what the material above supports; mark anything you are unsure of as an open item.")
+		if dir := taskDir(app, *task); dir != "" {
+			fmt.Fprintf(&b, " Save it as %s.", filepath.Join(dir, "handoff.md"))
+		}
+		return promptResult("Handoff for "+task.ID, b.String()), nil
+	}
+}
+
+func handleProposeADRPrompt(app *internal.App) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		title := strings.TrimSpace(req.Params.Arguments["title"])
+		if title == "" {
+			return nil, fmt.Errorf("title is required")
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "Propose an architecture decision record: %q.\n\n", title)
+		if app.ADRManager != nil {
+			if adrs, err := app.ADRManager.List(); err == nil && len(adrs) > 0 {
+				b.WriteString("## Existing ADRs\n\n")
+				for _, a := range adrs {
+					fmt.Fprintf(&b, "- ADR-%04d %s (%s)\n", a.Number, a.Title, a.Status)
+				}
+				b.WriteString("\nIf one of these already covers the decision, say so and propose superseding or amending it instead.\n\n")
+			}
+		}
+		if id := req.Params.Arguments["task_id"]; id != "" {
+			_, brief, err := taskBrief(app, id)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString("The decision comes out of this task:\n\n" + brief)
+		}
+		b.WriteString("Draft the record in MADR form with the sections Context and Problem Statement, Decision Drivers, " +
+			"Considered Options (at least two, with pros and cons), Decision Outcome and Consequences (good and bad). " +
+			"When it is ready, create it with `adb adr new` and paste the body into the file it scaffolds; it starts as proposed.")
+		return promptResult("Propose ADR: "+title, b.String()), nil
+	}
+}
+
+// taskBrief renders a task's metadata, graph links, context.md and notes.md
+// as markdown for a prompt.
+func taskBrief(app *internal.App, id string) (*models.Task, string, error) {
+	if id == "" {
+		return nil, "", fmt.Errorf("task_id is required")
+	}
+	task, err := app.BacklogManager.GetTask(id)
+	if err != nil {
+		return nil, "", fmt.Errorf("task %s: %w", id, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s: %s\n\n", task.ID, task.Title)
+	fmt.Fprintf(&b, "- Type: %s\n- Status: %s\n- Priority: %s\n", task.Type, task.Status, task.Priority)
+	if task.Owner != "" {
+		fmt.Fprintf(&b, "- Owner: %s\n", task.Owner)
+	}
+	if task.Branch != "" {
+		fmt.Fprintf(&b, "- Branch: %s\n", task.Branch)
+	}
+	if task.Repo != "" {
+		fmt.Fprintf(&b, "- Repo: %s\n", task.Repo)
+	}
+	if app.GraphManager != nil {
+		if edges, err := app.GraphManager.Neighbors(task.ID); err == nil {
+			for _, e := range edges {
+				fmt.Fprintf(&b, "- Link: %s %s %s\n", e.From, e.Type, e.To)
+			}
+		}
+	}
+	b.WriteString("\n")
+	if dir := taskDir(app, *task); dir != "" {
+		for _, file := range []string{"context.md", "notes.md"} {
+			data, err := os.ReadFile(filepath.Join(dir, file))
+			if err != nil || strings.TrimSpace(string(data)) == "" {
+				continue
+			}
+			fmt.Fprintf(&b, "### %s\n\n%s\n\n", file, strings.TrimSpace(string(data)))
+		}
+	}
+	return task, b.String(), nil
+}
+
+func promptResult(description, text string) *mcp.GetPromptResult {
+	return mcp.NewGetPromptResult(description, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+	})
+}
@@ -0,0 +1,50 @@
+package mcpserver
+
+import (
+	"strings"
+	"testing"
+)
+
+func promptText(t *testing.T, res map[string]any) string {
+	t.Helper()
+	if res["error"] != nil {
+		return "error"
+	}
+	msgs := res["messages"].([]any)
+	return msgs[0].(map[string]any)["content"].(map[string]any)["text"].(string)
+}
+
+func TestPrompts_PrefilledFromTask(t *testing.T) {
+	app, dir := seedResourceApp(t)
+	s := New(app, "test")
+
+	list := rpc(t, s, "prompts/list", nil)
+	if n := len(list["prompts"].([]any)); n != 3 {
+		t.Fatalf("prompts/list = %v", list)
+	}
+
+	resume := promptText(t, rpc(t, s, "prompts/get", map[string]any{"name": "resume_task", "arguments": map[string]any{"task_id": "TASK-00001"}}))
+	for _, want := range []string{"TASK-00001: MCP resources", "Status: in_progress", "Wiring MCP resources.", "Must notify on change."} {
+		if !strings.Contains(resume, want) {
+			t.Errorf("resume_task missing %q:\n%s", want, resume)
+		}
+	}
+
+	handoff := promptText(t, rpc(t, s, "prompts/get", map[string]any{"name": "write_handoff", "arguments": map[string]any{"task_id": "TASK-00001"}}))
+	for _, want := range []string{"# Handoff: MCP resources", "Open Items", dir} {
+		if !strings.Contains(handoff, want) {
+			t.Errorf("write_handoff missing %q:\n%s", want, handoff)
+		}
+	}
+
+	adr := promptText(t, rpc(t, s, "prompts/get", map[string]any{"name": "propose_adr", "arguments": map[string]any{"title": "Push instead of poll", "task_id": "TASK-00001"}}))
+	for _, want := range []string{"Push instead of poll", "ADR-0001 Poll for resource changes (proposed)", "Wiring MCP resources.", "Considered Options"} {
+		if !strings.Contains(adr, want) {
+			t.Errorf("propose_adr missing %q:\n%s", want, adr)
+		}
+	}
+
+	if got := promptText(t, rpc(t, s, "prompts/get", map[string]any{"name": "resume_task", "arguments": map[string]any{"task_id": "TASK-09999"}})); got != "error" {
+		t.Errorf("unknown task should be an error, got %q", got)
+	}
+}
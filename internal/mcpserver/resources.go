@@ -0,0 +1,401 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal"
+	"github.com/valter-silva-au/ai-dev-brain/internal/core"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// resourceScheme prefixes every resource URI the server exposes.
+const resourceScheme = "adb://"
+
+// resourcePollInterval is how often the watcher re-stats the files behind
+// the resource list. Polling keeps the server dependency-free and works the
+// same on every OS and filesystem (network mounts included).
+const resourcePollInterval = 2 * time.Second
+
+// registerResourceTemplates advertises the URI shapes a client can read
+// directly, even for resources not (yet) in resources/list.
+func registerResourceTemplates(s *server.MCPServer, app *internal.App) {
+	read := func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return readResource(app, req.Params.URI)
+	}
+	for _, t := range []mcp.ResourceTemplate{
+		mcp.NewResourceTemplate("adb://task/{id}/context", "Task context",
+			mcp.WithTemplateDescription("A task's context.md: the running summary of what the task is, where it stands and what is next."),
+			mcp.WithTemplateMIMEType("text/markdown")),
+		mcp.NewResourceTemplate("adb://task/{id}/notes", "Task notes",
+			mcp.WithTemplateDescription("A task's notes.md: requirements, acceptance criteria and working notes."),
+			mcp.WithTemplateMIMEType("text/markdown")),
+		mcp.NewResourceTemplate("adb://adr/{number}", "Architecture decision record",
+			mcp.WithTemplateDescription("An ADR's MADR markdown, with its registry status."),
+			mcp.WithTemplateMIMEType("text/markdown")),
+		mcp.NewResourceTemplate("adb://initiative/{id}/gate", "Initiative stage gate",
+			mcp.WithTemplateDescription("A fresh evaluation of the gate for an initiative's current stage, plus the last recorded transition decision."),
+			mcp.WithTemplateMIMEType("application/json")),
+		mcp.NewResourceTemplate("adb://wiki/{path}", "Wiki page",
+			mcp.WithTemplateDescription("A markdown page under the workspace's docs/wiki/."),
+			mcp.WithTemplateMIMEType("text/markdown")),
+	} {
+		s.AddResourceTemplate(t, read)
+	}
+}
+
+// catalogEntry is one concrete resource in resources/list, with the files
+// whose change makes it stale.
+type catalogEntry struct {
+	resource mcp.Resource
+	paths    []string
+}
+
+// buildCatalog lists every resource that currently exists in the workspace.
+// Read errors degrade to a shorter list: one unreadable registry must not
+// hide the rest.
+func buildCatalog(app *internal.App) []catalogEntry {
+	var out []catalogEntry
+	if backlog, err := app.BacklogManager.Load(); err == nil {
+		for _, t := range backlog.Tasks {
+			dir := taskDir(app, t)
+			if dir == "" {
+				continue
+			}
+			for _, doc := range []struct{ name, file, what string }{
+				{"context", "context.md", "context"},
+				{"notes", "notes.md", "notes"},
+			} {
+				path := filepath.Join(dir, doc.file)
+				if _, err := os.Stat(path); err != nil {
+					continue
+				}
+				out = append(out, catalogEntry{
+					resource: mcp.NewResource(fmt.Sprintf("adb://task/%s/%s", t.ID, doc.name), fmt.Sprintf("%s %s", t.ID, doc.what),
+						mcp.WithResourceDescription(fmt.Sprintf("%s — %s (%s)", t.ID, t.Title, t.Status)),
+						mcp.WithMIMEType("text/markdown")),
+					paths: []string{path},
+				})
+			}
+		}
+	}
+	if app.ADRManager != nil {
+		if adrs, err := app.ADRManager.List(); err == nil {
+			for _, a := range adrs {
+				out = append(out, catalogEntry{
+					resource: mcp.NewResource(fmt.Sprintf("adb://adr/%d", a.Number), fmt.Sprintf("ADR-%04d", a.Number),
+						mcp.WithResourceDescription(fmt.Sprintf("%s (%s)", a.Title, a.Status)),
+						mcp.WithMIMEType("text/markdown")),
+					paths: []string{filepath.Join(app.BasePath, "adr", "index.yaml"), filepath.Join(app.BasePath, "docs", "adr", a.Filename())},
+				})
+			}
+		}
+	}
+	if app.StageManager != nil {
+		if inits, err := app.StageManager.ListInitiatives(); err == nil {
+			for _, in := range inits {
+				out = append(out, catalogEntry{
+					resource: mcp.NewResource(fmt.Sprintf("adb://initiative/%s/gate", in.ID), in.ID+" gate",
+						mcp.WithResourceDescription(fmt.Sprintf("%s — stage %s", in.Name, in.Stage)),
+						mcp.WithMIMEType("application/json")),
+					paths: []string{filepath.Join(app.BasePath, "initiatives", "index.yaml"), filepath.Join(app.BasePath, "initiatives", in.ID)},
+				})
+			}
+		}
+	}
+	wikiDir := filepath.Join(app.BasePath, "docs", "wiki")
+	_ = filepath.WalkDir(wikiDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+		rel, _ := filepath.Rel(wikiDir, path)
+		rel = filepath.ToSlash(rel)
+		out = append(out, catalogEntry{
+			resource: mcp.NewResource("adb://wiki/"+rel, "wiki/"+rel, mcp.WithMIMEType("text/markdown")),
+			paths:    []string{path},
+		})
+		return nil
+	})
+	return out
+}
+
+// taskDir resolves a task's ticket directory, or "" when it has none.
+func taskDir(app *internal.App, t models.Task) string {
+	if t.TicketPath != "" {
+		return t.TicketPath
+	}
+	dir, err := core.ResolveTicketDir(filepath.Join(app.BasePath, "tickets"), t.ID)
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// fingerprint summarises the size and mtime of every file at or under
+// paths; a missing path contributes nothing.
+func fingerprint(paths []string) string {
+	h := fnv.New64a()
+	for _, root := range paths {
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil || info.IsDir() {
+				return nil
+			}
+			fmt.Fprintf(h, "%s\x00%d\x00%d\x00", path, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// resourceSubscriptions records which sessions subscribed to which resource
+// URIs. The SDK routes resources/subscribe and resources/unsubscribe but
+// keeps no state for its own sessions, so the server tracks them through
+// the request hooks and forgets a session when it unregisters.
+type resourceSubscriptions struct {
+	mu    sync.Mutex
+	byURI map[string]map[string]bool // uri -> subscribed session IDs
+}
+
+// addHooks wires the subscription hooks into hooks.
+func (r *resourceSubscriptions) addHooks(hooks *server.Hooks) {
+	hooks.AddAfterSubscribe(func(ctx context.Context, _ any, req *mcp.SubscribeRequest, _ *mcp.EmptyResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			r.add(session.SessionID(), req.Params.URI)
+		}
+	})
+	hooks.AddAfterUnsubscribe(func(ctx context.Context, _ any, req *mcp.UnsubscribeRequest, _ *mcp.EmptyResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			r.remove(session.SessionID(), req.Params.URI)
+		}
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		r.drop(session.SessionID())
+	})
+}
+
+func (r *resourceSubscriptions) add(sessionID, uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.byURI == nil {
+		r.byURI = make(map[string]map[string]bool)
+	}
+	if r.byURI[uri] == nil {
+		r.byURI[uri] = make(map[string]bool)
+	}
+	r.byURI[uri][sessionID] = true
+}
+
+func (r *resourceSubscriptions) remove(sessionID, uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byURI[uri], sessionID)
+	if len(r.byURI[uri]) == 0 {
+		delete(r.byURI, uri)
+	}
+}
+
+// drop forgets every subscription of a session.
+func (r *resourceSubscriptions) drop(sessionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for uri, sessions := range r.byURI {
+		delete(sessions, sessionID)
+		if len(sessions) == 0 {
+			delete(r.byURI, uri)
+		}
+	}
+}
+
+// sessions returns the IDs of the sessions subscribed to uri, sorted.
+func (r *resourceSubscriptions) sessions(uri string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.byURI[uri]))
+	for id := range r.byURI[uri] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// resourceWatcher keeps resources/list in step with the workspace and tells
+// subscribed clients when a listed resource changes: list changes go to
+// every client, notifications/resources/updated only to the sessions that
+// sent resources/subscribe for the URI.
+type resourceWatcher struct {
+	app          *internal.App
+	setResources func(...server.ServerResource)
+	subs         *resourceSubscriptions
+	notify       func(sessionID, method string, params map[string]any)
+
+	prints map[string]string // uri -> fingerprint at the last poll
+}
+
+func newResourceWatcher(s *server.MCPServer, app *internal.App, subs *resourceSubscriptions) *resourceWatcher {
+	return &resourceWatcher{
+		app:          app,
+		setResources: s.SetResources,
+		subs:         subs,
+		notify: func(sessionID, method string, params map[string]any) {
+			// A session that went away meanwhile is dropped by its unregister hook.
+			_ = s.SendNotificationToSpecificClient(sessionID, method, params)
+		},
+	}
+}
+
+// poll rebuilds the catalogue. When the set of URIs changed it replaces the
+// server's resource list (which itself sends notifications/resources/
+// list_changed); for each resource that was already listed and whose files
+// changed it sends notifications/resources/updated to its subscribers.
+func (w *resourceWatcher) poll() {
+	catalog := buildCatalog(w.app)
+	prints := make(map[string]string, len(catalog))
+	for _, e := range catalog {
+		prints[e.resource.URI] = fingerprint(e.paths)
+	}
+
+	listChanged := w.prints == nil || len(prints) != len(w.prints)
+	var updated []string
+	for uri, fp := range prints {
+		old, ok := w.prints[uri]
+		switch {
+		case !ok:
+			listChanged = true
+		case old != fp:
+			updated = append(updated, uri)
+		}
+	}
+	w.prints = prints
+
+	if listChanged {
+		read := func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return readResource(w.app, req.Params.URI)
+		}
+		resources := make([]server.ServerResource, len(catalog))
+		for i, e := range catalog {
+			resources[i] = server.ServerResource{Resource: e.resource, Handler: read}
+		}
+		w.setResources(resources...)
+	}
+	sort.Strings(updated)
+	for _, uri := range updated {
+		for _, id := range w.subs.sessions(uri) {
+			w.notify(id, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+		}
+	}
+}
+
+// run polls until ctx is done.
+func (w *resourceWatcher) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// readResource resolves an adb:// URI to its contents.
+func readResource(app *internal.App, uri string) ([]mcp.ResourceContents, error) {
+	rest, ok := strings.CutPrefix(uri, resourceScheme)
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource URI %q", uri)
+	}
+	kind, rest, _ := strings.Cut(rest, "/")
+	switch kind {
+	case "task":
+		id, doc, _ := strings.Cut(rest, "/")
+		file := map[string]string{"context": "context.md", "notes": "notes.md"}[doc]
+		if id == "" || file == "" {
+			return nil, fmt.Errorf("unknown task resource %q (want adb://task/<id>/context or /notes)", uri)
+		}
+		task, err := app.BacklogManager.GetTask(id)
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", id, err)
+		}
+		dir := taskDir(app, *task)
+		if dir == "" {
+			return nil, fmt.Errorf("task %s has no ticket directory", id)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, fmt.Errorf("read %s for %s: %w", file, id, err)
+		}
+		return textContents(uri, "text/markdown", string(data)), nil
+
+	case "adr":
+		n, err := strconv.Atoi(rest)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("unknown ADR resource %q (want adb://adr/<number>)", uri)
+		}
+		adr, body, err := app.ADRManager.Show(n)
+		if err != nil {
+			return nil, err
+		}
+		// The registry, not the markdown, is authoritative for status.
+		header := fmt.Sprintf("<!-- ADR-%04d status: %s -->\n", adr.Number, adr.Status)
+		return textContents(uri, "text/markdown", header+body), nil
+
+	case "initiative":
+		id, doc, _ := strings.Cut(rest, "/")
+		if id == "" || doc != "gate" {
+			return nil, fmt.Errorf("unknown initiative resource %q (want adb://initiative/<id>/gate)", uri)
+		}
+		eval, err := app.StageManager.EvaluateCurrentGate(id)
+		if err != nil {
+			return nil, err
+		}
+		view := map[string]any{
+			"initiative_id":            eval.InitiativeID,
+			"stage":                    eval.Stage,
+			"has_gate":                 eval.HasGate,
+			"last_transition_decision": eval.LastTransitionDecision,
+		}
+		if eval.HasGate {
+			view["current"] = eval.CurrentEvaluation
+			view["evaluated_at"] = eval.EvaluatedAt
+		}
+		data, err := json.MarshalIndent(view, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return textContents(uri, "application/json", string(data)), nil
+
+	case "wiki":
+		clean := filepath.Clean(filepath.FromSlash(rest))
+		if rest == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("invalid wiki path in %q", uri)
+		}
+		data, err := os.ReadFile(filepath.Join(app.BasePath, "docs", "wiki", clean))
+		if err != nil {
+			return nil, fmt.Errorf("read wiki page: %w", err)
+		}
+		return textContents(uri, "text/markdown", string(data)), nil
+	}
+	return nil, fmt.Errorf("unknown resource %q", uri)
+}
+
+func textContents(uri, mime, text string) []mcp.ResourceContents {
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: mime, Text: text}}
+}
@@ -0,0 +1,175 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal"
+	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
+)
+
+// seedResourceApp builds a workspace holding one of each resource kind.
+func seedResourceApp(t *testing.T) (*internal.App, string) {
+	t.Helper()
+	base := t.TempDir()
+	app, err := internal.NewApp(base)
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	t.Cleanup(func() { app.Cleanup() })
+
+	dir := filepath.Join(base, "tickets", "TASK-00001-resources")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "context.md"), []byte("# Context\n\nWiring MCP resources.\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "notes.md"), []byte("# Notes\n\nMust notify on change.\n"), 0o644)
+	if err := app.BacklogManager.AddTask(models.Task{
+		ID: "TASK-00001", Title: "MCP resources", Type: models.TaskTypeFeat,
+		Status: models.TaskStatusInProgress, Priority: models.PriorityP1, TicketPath: dir,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.ADRManager.New("Poll for resource changes", nil); err != nil {
+		t.Fatal(err)
+	}
+	org, err := app.StageManager.CreateOrganization("Acme", "github.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.StageManager.CreateInitiative("Launchpad", org.ID); err != nil {
+		t.Fatal(err)
+	}
+	wiki := filepath.Join(base, "docs", "wiki", "knowledge")
+	_ = os.MkdirAll(wiki, 0o755)
+	_ = os.WriteFile(filepath.Join(wiki, "TASK-00001.md"), []byte("# Learnings\n"), 0o644)
+	return app, dir
+}
+
+// rpc sends one JSON-RPC request through the server and decodes the result.
+func rpc(t *testing.T, s *server.MCPServer, method string, params map[string]any) map[string]any {
+	t.Helper()
+	msg, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	resp := s.HandleMessage(context.Background(), msg)
+	data, _ := json.Marshal(resp)
+	var out struct {
+		Result map[string]any `json:"result"`
+		Error  map[string]any `json:"error"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("decode %s: %v", method, err)
+	}
+	if out.Error != nil {
+		return map[string]any{"error": out.Error}
+	}
+	return out.Result
+}
+
+func TestResources_ListAndRead(t *testing.T) {
+	app, _ := seedResourceApp(t)
+	s := New(app, "test")
+
+	list := rpc(t, s, "resources/list", nil)
+	var uris []string
+	for _, r := range list["resources"].([]any) {
+		uris = append(uris, r.(map[string]any)["uri"].(string))
+	}
+	got := strings.Join(uris, " ")
+	for _, want := range []string{
+		"adb://task/TASK-00001/context", "adb://task/TASK-00001/notes", "adb://adr/1",
+		"adb://initiative/launchpad/gate", "adb://wiki/knowledge/TASK-00001.md",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("resources/list missing %s: %s", want, got)
+		}
+	}
+	if tpl := rpc(t, s, "resources/templates/list", nil); len(tpl["resourceTemplates"].([]any)) != 5 {
+		t.Errorf("templates = %v", tpl)
+	}
+
+	read := func(uri string) string {
+		res := rpc(t, s, "resources/read", map[string]any{"uri": uri})
+		if res["error"] != nil {
+			return "error"
+		}
+		return res["contents"].([]any)[0].(map[string]any)["text"].(string)
+	}
+	if text := read("adb://task/TASK-00001/context"); !strings.Contains(text, "Wiring MCP resources") {
+		t.Errorf("context = %q", text)
+	}
+	if text := read("adb://adr/1"); !strings.Contains(text, "status: proposed") || !strings.Contains(text, "Poll for resource changes") {
+		t.Errorf("adr = %q", text)
+	}
+	var gate map[string]any
+	if err := json.Unmarshal([]byte(read("adb://initiative/launchpad/gate")), &gate); err != nil || gate["initiative_id"] != "launchpad" {
+		t.Errorf("gate = %v, %v", gate, err)
+	}
+	if text := read("adb://wiki/knowledge/TASK-00001.md"); text != "# Learnings\n" {
+		t.Errorf("wiki = %q", text)
+	}
+}
+
+func TestReadResource_Rejects(t *testing.T) {
+	app, _ := seedResourceApp(t)
+	for _, uri := range []string{
+		"adb://wiki/../backlog.yaml", "adb://wiki/", "adb://task/TASK-00001/secrets",
+		"adb://task/TASK-09999/context", "adb://adr/zero", "adb://initiative/launchpad/stage", "file:///etc/passwd",
+	} {
+		if _, err := readResource(app, uri); err == nil {
+			t.Errorf("%s: expected an error", uri)
+		}
+	}
+}
+
+// TestResourceWatcher_Notifies: editing a listed file sends
+// notifications/resources/updated for it to the sessions subscribed to it; a
+// new file replaces the list.
+func TestResourceWatcher_Notifies(t *testing.T) {
+	app, dir := seedResourceApp(t)
+	subs := &resourceSubscriptions{}
+	subs.add("s1", "adb://task/TASK-00001/notes")
+	subs.add("s2", "adb://task/TASK-00001/notes")
+	subs.add("s3", "adb://task/TASK-00001/context")
+	subs.remove("s2", "adb://task/TASK-00001/notes")
+	var lists int
+	var updates []string
+	w := &resourceWatcher{
+		app:          app,
+		setResources: func(...server.ServerResource) { lists++ },
+		subs:         subs,
+		notify: func(sessionID, method string, params map[string]any) {
+			updates = append(updates, sessionID+" "+method+" "+params["uri"].(string))
+		},
+	}
+	w.poll()
+	w.poll()
+	if lists != 1 || len(updates) != 0 {
+		t.Fatalf("idle polls: %d list(s), updates %v", lists, updates)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "notes.md"), []byte("# Notes\n\nMust notify on change. Done.\n"), 0o644)
+	w.poll()
+	if strings.Join(updates, "|") != "s1 notifications/resources/updated adb://task/TASK-00001/notes" || lists != 1 {
+		t.Errorf("after edit: %d list(s), updates %v", lists, updates)
+	}
+
+	subs.drop("s1")
+	updates = nil
+	_ = os.WriteFile(filepath.Join(dir, "notes.md"), []byte("# Notes\n\nEdited again.\n"), 0o644)
+	w.poll()
+	if len(updates) != 0 {
+		t.Errorf("an unregistered session was notified: %v", updates)
+	}
+
+	_ = os.WriteFile(filepath.Join(app.BasePath, "docs", "wiki", "new.md"), []byte("# New\n"), 0o644)
+	w.poll()
+	if lists != 2 {
+		t.Errorf("a new page should replace the resource list (lists=%d)", lists)
+	}
+}
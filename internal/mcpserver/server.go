@@ -1,11 +1,13 @@
 // Package mcpserver implements a Model Context Protocol (MCP) server that
 // exposes AI Dev Brain's task lifecycle over stdio so that MCP clients
 // (Claude Code, Claude Desktop, etc.) can drive ADB natively as tool calls
-// instead of shelling out to the CLI.
+// instead of shelling out to the CLI. The same server can also be served
+// over HTTP (http.go) so several clients share one workspace, authenticated
+// by bearer tokens (tokens.go).
 //
-// The server is a thin adapter: every tool delegates to the same
-// internal.App subsystems the CLI uses (TaskManager, BacklogManager), so
-// behaviour and storage are identical regardless of entry point.
+// The server is a thin adapter: every tool, resource and prompt delegates to
+// the same internal.App subsystems the CLI uses (TaskManager, BacklogManager),
+// so behaviour and storage are identical regardless of entry point.
 package mcpserver
 
 import (
@@ -19,6 +21,7 @@ import (
 
 	"github.com/valter-silva-au/ai-dev-brain/internal"
 	"github.com/valter-silva-au/ai-dev-brain/internal/core"
+	"github.com/valter-silva-au/ai-dev-brain/internal/observability"
 	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
 )
 
@@ -37,33 +40,78 @@ Graph + knowledge tools traverse the workspace's typed entity graph and vector
 memory: graph_neighbors (edges incident to an entity), related_tickets (tickets
 linked to a ticket), get_initiative (an initiative's stage + gate), and
 search_knowledge (semantic search; degrades gracefully when memory is
-unconfigured).`
+unconfigured).
+
+Capture what you learn as you work rather than at the end: adb_record_decision,
+adb_record_learning and adb_record_gotcha write to a task's knowledge base,
+adb_adr_new drafts an ADR, adb_debt_add logs tech debt, adb_notes_append adds to
+a task's notes.md and adb_comm_log records a stakeholder communication. These
+appear only when the workspace enables them (mcp.write_tools in .taskrc).
+adb_events_query searches the event log.
+
+Resources expose workspace documents for browsing: adb://task/<id>/context,
+adb://task/<id>/notes, adb://adr/<n>, adb://initiative/<id>/gate and
+adb://wiki/<path>; the server notifies clients when one changes. Prompts
+(resume_task, write_handoff, propose_adr) come prefilled from a task's context.`
 )
 
 // New builds an MCP server backed by the given App. The version string is
 // surfaced to clients in the initialize handshake.
 func New(app *internal.App, version string) *server.MCPServer {
+	s, _ := newServer(app, version, nil)
+	return s
+}
+
+// newServer builds the server along with the watcher that keeps its
+// resource list current; the caller runs the watcher for as long as it
+// serves. A non-nil requestLog (the HTTP transport's) records every
+// authenticated request.
+func newServer(app *internal.App, version string, requestLog *observability.EventLog) (*server.MCPServer, *resourceWatcher) {
+	guard := &toolGuard{app: app}
+	hooks := &server.Hooks{}
+	hooks.AddAfterListTools(guard.listTools)
+	subs := &resourceSubscriptions{}
+	subs.addHooks(hooks)
+	if requestLog != nil {
+		addRequestLogHooks(hooks, requestLog)
+	}
 	s := server.NewMCPServer(
 		serverName,
 		version,
 		server.WithToolCapabilities(false),
+		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(false),
 		server.WithRecovery(),
+		server.WithToolHandlerMiddleware(guard.middleware),
+		server.WithHooks(hooks),
 		server.WithInstructions(instructions),
 	)
+	guard.server = s
 	registerTaskTools(s, app)
 	registerGraphTools(s, app)
-	return s
+	registerCaptureTools(s, app)
+	registerResourceTemplates(s, app)
+	registerPrompts(s, app)
+	w := newResourceWatcher(s, app, subs)
+	w.poll()
+	return s, w
 }
 
 // Serve builds the server and serves it over stdio, blocking until the
-// client disconnects or the context is cancelled.
+// client disconnects or the context is cancelled. While it serves, file
+// changes behind the resources the client subscribed to are pushed to it.
 func Serve(app *internal.App, version string) error {
-	return server.ServeStdio(New(app, version))
+	s, w := newServer(app, version, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.run(ctx, resourcePollInterval)
+	return server.ServeStdio(s)
 }
 
 // registerTaskTools wires every task tool onto the server.
 func registerTaskTools(s *server.MCPServer, app *internal.App) {
 	s.AddTool(mcp.NewTool("adb_task_list",
+		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDescription("List tasks in the workspace, optionally filtered by status. Returns JSON with id, title, type, status, priority, owner, tags for each task."),
 		mcp.WithString("status",
 			mcp.Description("Optional status filter: backlog, in_progress, blocked, review, done, or archived. Omit for all tasks."),
@@ -0,0 +1,200 @@
+package mcpserver
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scope is what a bearer token may do over the HTTP transport: read tokens
+// see only the read-only tools (plus every resource and prompt); write
+// tokens get the full tool set.
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+)
+
+// ParseScope validates a scope name.
+func ParseScope(s string) (Scope, error) {
+	switch Scope(s) {
+	case ScopeRead, ScopeWrite:
+		return Scope(s), nil
+	default:
+		return "", fmt.Errorf("invalid scope %q (must be read or write)", s)
+	}
+}
+
+// tokenPrefix marks adb MCP tokens so a leaked one is recognisable.
+const tokenPrefix = "adb_"
+
+// Token is one named bearer token. Only the SHA-256 of the secret is kept,
+// so the token file can be read without handing out credentials.
+type Token struct {
+	Name      string    `yaml:"name"`
+	SHA256    string    `yaml:"sha256"`
+	Scope     Scope     `yaml:"scope"`
+	CreatedAt time.Time `yaml:"created_at"`
+}
+
+// TokenFile is the on-disk token list (.adb/mcp_tokens.yaml by default).
+type TokenFile struct {
+	Tokens []Token `yaml:"tokens"`
+}
+
+// LoadTokens reads a token file. A missing file is an empty list.
+func LoadTokens(path string) (*TokenFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &TokenFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read token file: %w", err)
+	}
+	var f TokenFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse token file %s: %w", path, err)
+	}
+	for _, t := range f.Tokens {
+		if _, err := ParseScope(string(t.Scope)); err != nil {
+			return nil, fmt.Errorf("token %q in %s: %w", t.Name, path, err)
+		}
+	}
+	return &f, nil
+}
+
+// Save writes the token file owner-only (0o600).
+func (f *TokenFile) Save(path string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("marshal token file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create token dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("write token file: %w", err)
+	}
+	return nil
+}
+
+// Add mints a token called name with the given scope and returns its
+// secret. The secret is not stored, so this is the only time it is seen.
+func (f *TokenFile) Add(name string, scope Scope) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("token name is required")
+	}
+	if _, err := ParseScope(string(scope)); err != nil {
+		return "", err
+	}
+	for _, t := range f.Tokens {
+		if t.Name == name {
+			return "", fmt.Errorf("token %q already exists; revoke it first", name)
+		}
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	secret := tokenPrefix + hex.EncodeToString(buf)
+	f.Tokens = append(f.Tokens, Token{
+		Name:      name,
+		SHA256:    hashToken(secret),
+		Scope:     scope,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	})
+	return secret, nil
+}
+
+// Revoke removes the named token, reporting whether it existed.
+func (f *TokenFile) Revoke(name string) bool {
+	for i, t := range f.Tokens {
+		if t.Name == name {
+			f.Tokens = append(f.Tokens[:i], f.Tokens[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the token whose secret is secret.
+func (f *TokenFile) Lookup(secret string) (Token, bool) {
+	sum := []byte(hashToken(secret))
+	for _, t := range f.Tokens {
+		if subtle.ConstantTimeCompare(sum, []byte(t.SHA256)) == 1 {
+			return t, true
+		}
+	}
+	return Token{}, false
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenSource serves lookups from a token file, re-reading it whenever it
+// changes on disk, so `adb mcp token add/revoke` takes effect on a running
+// server without a restart.
+type tokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	file    *TokenFile
+}
+
+func newTokenSource(path string) (*tokenSource, error) {
+	ts := &tokenSource{path: path}
+	if _, err := ts.current(); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// current returns the token list, reloading it if the file changed. A
+// file that disappears revokes every token; one that stops parsing keeps
+// the last good list and reports the error.
+func (ts *tokenSource) current() (*TokenFile, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	info, err := os.Stat(ts.path)
+	if os.IsNotExist(err) {
+		ts.file, ts.modTime, ts.size = &TokenFile{}, time.Time{}, 0
+		return ts.file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("stat token file: %w", err)
+	}
+	if ts.file != nil && info.ModTime().Equal(ts.modTime) && info.Size() == ts.size {
+		return ts.file, nil
+	}
+	f, err := LoadTokens(ts.path)
+	if err != nil {
+		if ts.file != nil {
+			return ts.file, err
+		}
+		return nil, err
+	}
+	ts.file, ts.modTime, ts.size = f, info.ModTime(), info.Size()
+	return f, nil
+}
+
+// lookup authenticates a bearer secret against the current token list.
+func (ts *tokenSource) lookup(secret string) (Token, bool) {
+	f, _ := ts.current()
+	if f == nil || secret == "" {
+		return Token{}, false
+	}
+	return f.Lookup(secret)
+}
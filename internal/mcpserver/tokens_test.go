@@ -0,0 +1,113 @@
+package mcpserver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenFile_AddLookupRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".adb", "mcp_tokens.yaml")
+	f, err := LoadTokens(path)
+	if err != nil || len(f.Tokens) != 0 {
+		t.Fatalf("missing file = %v, %v; want an empty list", f, err)
+	}
+	secret, err := f.Add("vscode", ScopeRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(secret, tokenPrefix) || len(secret) != len(tokenPrefix)+64 {
+		t.Errorf("secret = %q", secret)
+	}
+	if _, err := f.Add("vscode", ScopeWrite); err == nil {
+		t.Error("a duplicate name should be rejected")
+	}
+	if _, err := f.Add("ci", "admin"); err == nil {
+		t.Error("an unknown scope should be rejected")
+	}
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), secret) {
+		t.Error("the token file must not hold the secret itself")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("token file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	loaded, err := LoadTokens(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok, ok := loaded.Lookup(secret); !ok || tok.Name != "vscode" || tok.Scope != ScopeRead {
+		t.Errorf("Lookup = %+v, %v", tok, ok)
+	}
+	if _, ok := loaded.Lookup(secret + "x"); ok {
+		t.Error("a wrong secret must not match")
+	}
+	if !loaded.Revoke("vscode") || loaded.Revoke("vscode") {
+		t.Error("Revoke should report the token once")
+	}
+	if _, ok := loaded.Lookup(secret); ok {
+		t.Error("a revoked token must not match")
+	}
+}
+
+func TestLoadTokens_RejectsBadScope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.yaml")
+	_ = os.WriteFile(path, []byte("tokens:\n  - name: x\n    sha256: ab\n    scope: root\n"), 0o600)
+	if _, err := LoadTokens(path); err == nil {
+		t.Error("expected an error for scope root")
+	}
+}
+
+// TestTokenSource_ReloadsOnChange: a token added or revoked on disk is seen
+// by the next lookup, and a file that stops parsing keeps the last good list.
+func TestTokenSource_ReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.yaml")
+	f := &TokenFile{}
+	first, _ := f.Add("a", ScopeRead)
+	_ = f.Save(path)
+	ts, err := newTokenSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ts.lookup(first); !ok {
+		t.Fatal("initial token not found")
+	}
+
+	second, _ := f.Add("b", ScopeWrite)
+	f.Revoke("a")
+	_ = f.Save(path)
+	bump(t, path, 1)
+	if _, ok := ts.lookup(first); ok {
+		t.Error("revoked token still accepted")
+	}
+	if tok, ok := ts.lookup(second); !ok || tok.Scope != ScopeWrite {
+		t.Errorf("added token = %+v, %v", tok, ok)
+	}
+
+	_ = os.WriteFile(path, []byte("tokens: [oops"), 0o600)
+	bump(t, path, 2)
+	if _, ok := ts.lookup(second); !ok {
+		t.Error("a corrupt file should keep the last good list")
+	}
+
+	_ = os.Remove(path)
+	if _, ok := ts.lookup(second); ok {
+		t.Error("deleting the file should revoke everything")
+	}
+}
+
+// bump moves path's mtime n hours ahead so a same-second rewrite is noticed.
+func bump(t *testing.T, path string, n int) {
+	t.Helper()
+	later := time.Now().Add(time.Duration(n) * time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+}
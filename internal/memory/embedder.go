@@ -1,6 +1,9 @@
 package memory
 
-import "context"
+import (
+	"context"
+	"errors"
+)
 
 // EmbeddingProvider turns arbitrary text into a fixed-dimensional
 // embedding vector. Implementations may call out to HTTP providers
@@ -23,3 +26,9 @@ type EmbeddingProvider interface {
 	// ollama/nomic-embed-text — different dims, store must refuse).
 	Name() string
 }
+
+// ErrNoEmbedding is returned (possibly wrapped) by an Embed that has nothing
+// to place text by — LocalEmbedder for text with no token its model knows.
+// Stores then keep the record without a vector: lexical search still finds
+// it, vector ranking skips it, and a query without one ranks lexically only.
+var ErrNoEmbedding = errors.New("no embedding for text")
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/configenv"
 )
 
 // EmbedderConfig is the provider-agnostic description of which embedding
@@ -13,8 +16,8 @@ import (
 // by the `adb memory` CLI, the memory hook indexer, and the MCP
 // search_knowledge tool so the three cannot drift.
 type EmbedderConfig struct {
-	Provider string // "" | "fake" | "openai" | "ollama"
-	Model    string
+	Provider string // "" | "fake" | "openai" | "ollama" | "local"
+	Model    string // for local: path to the model file ("" = lexical fallback)
 	Endpoint string
 	APIKey   string // supports "$ENV_VAR" interpolation
 	Dim      int    // <= 0 defaults to 64 (the fake provider's sensible default)
@@ -22,16 +25,15 @@ type EmbedderConfig struct {
 
 // NewEmbedder builds an EmbeddingProvider from cfg. The fake provider is the
 // default (no network, deterministic); openai/ollama get a sensible default
-// endpoint and HTTP client. An unknown provider is an error.
+// endpoint and HTTP client; local loads cfg.Model as an in-process model file
+// (its dimension wins over cfg.Dim) or, with no model, uses the lexical
+// projection. An unknown provider is an error.
 func NewEmbedder(cfg EmbedderConfig) (EmbeddingProvider, error) {
 	dim := cfg.Dim
 	if dim <= 0 {
 		dim = 64
 	}
-	apiKey := cfg.APIKey
-	if strings.HasPrefix(apiKey, "$") {
-		apiKey = os.Getenv(strings.TrimPrefix(apiKey, "$"))
-	}
+	apiKey := configenv.Expand(cfg.APIKey)
 	switch strings.ToLower(cfg.Provider) {
 	case "", "fake":
 		return NewFakeEmbedder(dim), nil
@@ -58,7 +60,18 @@ func NewEmbedder(cfg EmbedderConfig) (EmbeddingProvider, error) {
 			Dim:      dim,
 			Client:   &http.Client{Timeout: 60 * time.Second},
 		}, nil
+	case "local":
+		if cfg.Model == "" {
+			return NewLexicalEmbedder(dim), nil
+		}
+		path := cfg.Model
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		return LoadLocalEmbedder(path)
 	default:
-		return nil, fmt.Errorf("unknown provider %q (valid: fake, openai, ollama)", cfg.Provider)
+		return nil, fmt.Errorf("unknown provider %q (valid: fake, openai, ollama, local)", cfg.Provider)
 	}
 }
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LexicalEmbedder is the zero-file offline embedder: hashed term features
+// pushed through a fixed random projection. Each word, word bigram and
+// character trigram is weighted by sublinear term frequency (1 + ln tf),
+// mapped to a pseudo-random ±1 vector derived from its hash, and the sum
+// is L2-normalised. Texts that share words — or word stems, via the
+// trigrams — therefore land near each other, which gives vector and
+// hybrid search real lexical semantics where FakeEmbedder gives none.
+//
+// There is no corpus to learn document frequencies from (an embedder sees
+// one text at a time, and vectors must not drift as the store grows), so
+// the IDF half of TF-IDF is approximated: common English stopwords are
+// dropped and character trigrams count for less than whole words.
+//
+// Selected by embedder.provider: local with no model path.
+type LexicalEmbedder struct {
+	Dim int
+}
+
+// NewLexicalEmbedder constructs a LexicalEmbedder with the given dimension.
+func NewLexicalEmbedder(dim int) *LexicalEmbedder {
+	if dim <= 0 {
+		dim = 256
+	}
+	return &LexicalEmbedder{Dim: dim}
+}
+
+// Feature weights relative to a whole word.
+const (
+	lexicalBigramWeight  = 0.5
+	lexicalTrigramWeight = 0.25
+)
+
+// Embed returns the projected feature vector of text. Text with no
+// features (empty, or only stopwords and punctuation) embeds to a fixed
+// unit vector so cosine similarity stays defined.
+func (e *LexicalEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	return e.embed(text), nil
+}
+
+func (e *LexicalEmbedder) embed(text string) []float32 {
+	tf := map[string]int{}
+	var prev string
+	for _, w := range localTokens(text) {
+		if lexicalStopwords[w] {
+			prev = ""
+			continue
+		}
+		tf["w:"+w]++
+		if prev != "" {
+			tf["b:"+prev+" "+w]++
+		}
+		prev = w
+		padded := []rune("^" + w + "$")
+		for i := 0; i+3 <= len(padded); i++ {
+			tf["c:"+string(padded[i:i+3])]++
+		}
+	}
+	if len(tf) == 0 {
+		tf["empty"] = 1
+	}
+
+	// Sum in a fixed order: float addition is not associative, and the
+	// same text must give bit-identical vectors.
+	keys := make([]string, 0, len(tf))
+	for f := range tf {
+		keys = append(keys, f)
+	}
+	sort.Strings(keys)
+	acc := make([]float64, e.Dim)
+	for _, f := range keys {
+		w := 1 + math.Log(float64(tf[f]))
+		switch f[0] {
+		case 'b':
+			w *= lexicalBigramWeight
+		case 'c':
+			w *= lexicalTrigramWeight
+		}
+		projectFeature(acc, f, w)
+	}
+	return normalise(acc)
+}
+
+// projectFeature adds w times the feature's ±1 projection row to acc. The
+// row is a splitmix64 stream seeded by the feature's FNV-64a hash, so it
+// is the same in every process and on every platform.
+func projectFeature(acc []float64, feature string, w float64) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	state := h.Sum64()
+	var bits uint64
+	for i := range acc {
+		if i%64 == 0 {
+			state += 0x9e3779b97f4a7c15
+			z := state
+			z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+			z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+			bits = z ^ (z >> 31)
+		}
+		if bits&1 == 1 {
+			acc[i] += w
+		} else {
+			acc[i] -= w
+		}
+		bits >>= 1
+	}
+}
+
+// normalise converts acc to a unit-length float32 vector.
+func normalise(acc []float64) []float32 {
+	var sum float64
+	for _, x := range acc {
+		sum += x * x
+	}
+	out := make([]float32, len(acc))
+	if sum == 0 {
+		out[0] = 1
+		return out
+	}
+	norm := math.Sqrt(sum)
+	for i, x := range acc {
+		out[i] = float32(x / norm)
+	}
+	return out
+}
+
+// Dimensions reports the projection size.
+func (e *LexicalEmbedder) Dimensions() int { return e.Dim }
+
+// Name identifies the provider for stored metadata.
+func (e *LexicalEmbedder) Name() string { return "local/lexical" }
+
+// localTokens lower-cases text and splits it into runs of letters and
+// digits. Both local embedders tokenise the same way.
+func localTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// lexicalStopwords are dropped before hashing; they would otherwise
+// dominate every vector.
+var lexicalStopwords = func() map[string]bool {
+	m := map[string]bool{}
+	for _, w := range strings.Fields(`a an and are as at be been but by can did do does for from had has
+have he her his i if in into is it its me my no not of on or our she so than that the their them
+then there these they this to too was we were what when where which while who why will with would
+you your`) {
+		m[w] = true
+	}
+	return m
+}()
@@ -0,0 +1,200 @@
+package memory
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LocalEmbedder embeds text in-process from a static token-embedding model
+// on disk, for machines that can reach neither Ollama nor an OpenAI-style
+// endpoint. A sentence vector is the SIF-weighted mean of its tokens'
+// vectors (smooth inverse frequency: a/(a+p(w)), so frequent words count
+// for little), L2-normalised.
+//
+// The model is a plain-text table in the word2vec/fastText .vec layout —
+// an optional "<rows> <dim>" header, then one "<token> <v1> … <vdim>" line
+// per token — optionally gzipped (.gz). fastText .vec files, GloVe files
+// and static distillations of sentence-transformer models (model2vec and
+// similar) all load as-is. Rows are assumed to be in descending frequency
+// order, as those tools write them; p(w) is estimated from the row's rank
+// by Zipf's law. Inference is a table lookup and a weighted sum, so the
+// binary stays cgo-free and needs no runtime. Transformer graphs (ONNX
+// sentence-embedding models) are not executed and are rejected on load:
+// use the model's static distillation instead.
+//
+// Text with no known token has no embedding: Embed returns ErrNoEmbedding
+// rather than a vector from another space, and the store keeps such a
+// record for lexical search only.
+//
+// Selected by embedder.provider: local with embedder.model set to the
+// model path; the dimension comes from the file. A model is parsed once per
+// process and shared by every store that opens it.
+type LocalEmbedder struct {
+	name    string
+	dim     int
+	index   map[string]int // lower-cased token -> row
+	vectors []float32      // rows * dim, row-major
+	weights []float32      // per-row SIF weight
+}
+
+// sifA is the SIF smoothing constant from Arora et al. (2017).
+const sifA = 1e-3
+
+// localModels caches parsed models by path, for the life of the process. An
+// entry is reused while the file's size and modification time are unchanged.
+var localModels = struct {
+	sync.Mutex
+	byPath map[string]localModel
+}{byPath: map[string]localModel{}}
+
+type localModel struct {
+	size     int64
+	modified time.Time
+	embedder *LocalEmbedder
+}
+
+// LoadLocalEmbedder returns the model at path, parsing it on first use.
+func LoadLocalEmbedder(path string) (*LocalEmbedder, error) {
+	if strings.EqualFold(filepath.Ext(path), ".onnx") {
+		return nil, ErrInvalid{Reason: fmt.Sprintf("%s: ONNX models are not supported; use a static token-embedding table (.vec, optionally .gz)", path)}
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("open local model: %w", err)
+	}
+	localModels.Lock()
+	defer localModels.Unlock()
+	if m, ok := localModels.byPath[path]; ok && m.size == info.Size() && m.modified.Equal(info.ModTime()) {
+		return m.embedder, nil
+	}
+	e, err := parseLocalModel(path)
+	if err != nil {
+		return nil, err
+	}
+	localModels.byPath[path] = localModel{size: info.Size(), modified: info.ModTime(), embedder: e}
+	return e, nil
+}
+
+// parseLocalModel reads a .vec table.
+func parseLocalModel(path string) (*LocalEmbedder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open local model: %w", err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("open local model %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	// The table's content, not its file name, identifies its vector space:
+	// the digest keeps a replaced model from passing the store's
+	// embedder check.
+	digest := sha256.New()
+	e := &LocalEmbedder{index: map[string]int{}}
+	sc := bufio.NewScanner(io.TeeReader(r, digest))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if line == 1 && len(fields) == 2 {
+			if _, err := strconv.Atoi(fields[0]); err == nil {
+				if _, err := strconv.Atoi(fields[1]); err == nil {
+					continue // word2vec header
+				}
+			}
+		}
+		if e.dim == 0 {
+			e.dim = len(fields) - 1
+			if e.dim <= 0 {
+				return nil, fmt.Errorf("%s:%d: row has no vector", path, line)
+			}
+		}
+		if len(fields)-1 != e.dim {
+			return nil, fmt.Errorf("%s:%d: row has %d values, want %d", path, line, len(fields)-1, e.dim)
+		}
+		row := make([]float32, e.dim)
+		for i, s := range fields[1:] {
+			v, err := strconv.ParseFloat(s, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			row[i] = float32(v)
+		}
+		token := strings.ToLower(fields[0])
+		if _, seen := e.index[token]; seen {
+			continue // the more frequent casing came first
+		}
+		e.index[token] = len(e.weights)
+		e.vectors = append(e.vectors, row...)
+		e.weights = append(e.weights, 0)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read local model %s: %w", path, err)
+	}
+	if len(e.weights) == 0 {
+		return nil, ErrInvalid{Reason: fmt.Sprintf("local model %s has no rows", path)}
+	}
+
+	// Zipf: p(rank r) = 1 / (r * H_n), with H_n ≈ ln n + γ.
+	harmonic := math.Log(float64(len(e.weights))) + 0.5772156649
+	for r := range e.weights {
+		p := 1 / (float64(r+1) * harmonic)
+		e.weights[r] = float32(sifA / (sifA + p))
+	}
+	base := strings.TrimSuffix(filepath.Base(path), ".gz")
+	e.name = fmt.Sprintf("local/%s@%x", strings.TrimSuffix(base, filepath.Ext(base)), digest.Sum(nil)[:6])
+	return e, nil
+}
+
+// Embed returns the SIF-weighted mean of text's token vectors, or
+// ErrNoEmbedding when the model knows none of them.
+func (e *LocalEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	acc := make([]float64, e.dim)
+	known := false
+	for _, tok := range localTokens(text) {
+		row, ok := e.index[tok]
+		if !ok {
+			continue
+		}
+		known = true
+		w := float64(e.weights[row])
+		for i, v := range e.vectors[row*e.dim : (row+1)*e.dim] {
+			acc[i] += w * float64(v)
+		}
+	}
+	if !known {
+		return nil, ErrNoEmbedding
+	}
+	return normalise(acc), nil
+}
+
+// Dimensions reports the model's vector size.
+func (e *LocalEmbedder) Dimensions() int { return e.dim }
+
+// Name identifies the provider + model for stored metadata: the file's stem
+// and a digest of its (decompressed) content, e.g. local/minilm@1f2e3d4c5b6a.
+func (e *LocalEmbedder) Name() string { return e.name }
@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testModel is a tiny frequency-ordered .vec table: animals share the
+// first axis, vehicles the third, and "the" is the most frequent row.
+const testModel = `6 4
+the 0 1 0 0
+cat 1 0 0 0
+dog 0.9 0.1 0 0.1
+car 0 0 1 0
+truck 0 0.1 0.9 0.1
+Cat 0 0 0 1
+`
+
+func writeTestModel(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if strings.HasSuffix(name, ".gz") {
+		gz := gzip.NewWriter(f)
+		_, _ = gz.Write([]byte(testModel))
+		_ = gz.Close()
+		return path
+	}
+	_, _ = f.WriteString(testModel)
+	return path
+}
+
+func embedAll(t *testing.T, e EmbeddingProvider, texts ...string) [][]float32 {
+	t.Helper()
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		v, err := e.Embed(context.Background(), text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(v) != e.Dimensions() {
+			t.Fatalf("len(%q) = %d, want %d", text, len(v), e.Dimensions())
+		}
+		out[i] = v
+	}
+	return out
+}
+
+func TestLocalEmbedder_Semantics(t *testing.T) {
+	for _, name := range []string{"tiny.vec", "tiny.vec.gz"} {
+		t.Run(name, func(t *testing.T) {
+			e, err := LoadLocalEmbedder(writeTestModel(t, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Dimensions() != 4 || !strings.HasPrefix(e.Name(), "local/tiny@") {
+				t.Fatalf("dim %d, name %q", e.Dimensions(), e.Name())
+			}
+			v := embedAll(t, e, "The Cat!", "a dog", "the car", "the cat")
+			if near, far := cosineSimilarity(v[0], v[1]), cosineSimilarity(v[0], v[2]); near <= far {
+				t.Errorf("cat~dog %.3f should beat cat~car %.3f", near, far)
+			}
+			if sim := cosineSimilarity(v[0], v[3]); sim < 0.999 {
+				t.Errorf("case and punctuation should not matter: %.3f", sim)
+			}
+			// "the" is down-weighted: "the car" stays close to "car" (an
+			// unweighted mean would put them at 0.71).
+			car := embedAll(t, e, "car")[0]
+			if sim := cosineSimilarity(v[2], car); sim < 0.95 {
+				t.Errorf("frequent word dominates: the car~car = %.3f", sim)
+			}
+		})
+	}
+}
+
+func TestLocalEmbedder_UnknownTokensHaveNoEmbedding(t *testing.T) {
+	e, err := LoadLocalEmbedder(writeTestModel(t, "tiny.vec"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := e.Embed(context.Background(), "zxqv frobnicate"); !errors.Is(err, ErrNoEmbedding) {
+		t.Fatalf("Embed = %v, %v; want ErrNoEmbedding, not a vector from another space", v, err)
+	}
+
+	// The store keeps such a record for lexical search only.
+	ctx := context.Background()
+	s, err := OpenSQLiteStore(ctx, filepath.Join(t.TempDir(), "m.sqlite"), e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.Upsert(ctx, "ns", "odd", "zxqv frobnicate", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Upsert(ctx, "ns", "pet", "the cat", nil); err != nil {
+		t.Fatal(err)
+	}
+	if hits, err := s.SearchWithMode(ctx, "ns", "cat", 5, ModeVector); err != nil || len(hits) != 1 || hits[0].Key != "pet" {
+		t.Errorf("vector hits = %+v, %v; want only the embedded record", hits, err)
+	}
+	if hits, err := s.SearchWithMode(ctx, "ns", "frobnicate", 5, ModeHybrid); err != nil || len(hits) != 1 || hits[0].Key != "odd" {
+		t.Errorf("hybrid hits for an unplaceable query = %+v, %v; want the lexical match", hits, err)
+	}
+}
+
+func TestLoadLocalEmbedder_ParsesOncePerFile(t *testing.T) {
+	path := writeTestModel(t, "tiny.vec")
+	a, err := LoadLocalEmbedder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := LoadLocalEmbedder(path); b != a {
+		t.Error("a second load of an unchanged model reparsed it")
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := LoadLocalEmbedder(path); c == a {
+		t.Error("a changed model file was served from the cache")
+	}
+}
+
+func TestLoadLocalEmbedder_NameFollowsContent(t *testing.T) {
+	path := writeTestModel(t, "tiny.vec")
+	a, err := LoadLocalEmbedder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gz, _ := LoadLocalEmbedder(writeTestModel(t, "tiny.vec.gz")); gz == nil || gz.Name() != a.Name() {
+		t.Errorf("the gzipped table is named %v, want %q", gz, a.Name())
+	}
+	// Another model of the same name and dimension replaces the file.
+	if err := os.WriteFile(path, []byte("the 1 0 0 0\ncat 0 1 0 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadLocalEmbedder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Dimensions() != a.Dimensions() || b.Name() == a.Name() {
+		t.Errorf("replaced model: dim %d, name %q; want dim %d and a name other than %q", b.Dimensions(), b.Name(), a.Dimensions(), a.Name())
+	}
+}
+
+func TestLoadLocalEmbedder_Rejects(t *testing.T) {
+	dir := t.TempDir()
+	ragged := filepath.Join(dir, "ragged.vec")
+	_ = os.WriteFile(ragged, []byte("a 1 2 3\nb 1 2\n"), 0o644)
+	empty := filepath.Join(dir, "empty.vec")
+	_ = os.WriteFile(empty, []byte("0 4\n"), 0o644)
+	for _, path := range []string{ragged, empty, filepath.Join(dir, "missing.vec")} {
+		if _, err := LoadLocalEmbedder(path); err == nil {
+			t.Errorf("%s: expected an error", filepath.Base(path))
+		}
+	}
+	if _, err := LoadLocalEmbedder(filepath.Join(dir, "model.onnx")); !errors.As(err, new(ErrInvalid)) {
+		t.Errorf("onnx: err = %v, want ErrInvalid", err)
+	}
+}
+
+func TestLexicalEmbedder_SharesTerms(t *testing.T) {
+	e := NewLexicalEmbedder(256)
+	v := embedAll(t, e,
+		"database migration failed on deploy",
+		"the migrations of the database fail",
+		"frontend button colour palette",
+		"database migration failed on deploy",
+		"",
+	)
+	near, far := cosineSimilarity(v[0], v[1]), cosineSimilarity(v[0], v[2])
+	if near < 0.3 || near <= far+0.2 {
+		t.Errorf("shared terms %.3f vs unrelated %.3f", near, far)
+	}
+	for i := range v[0] {
+		if v[0][i] != v[3][i] {
+			t.Fatal("same text must embed identically")
+		}
+	}
+	if e.Name() != "local/lexical" {
+		t.Errorf("name = %q", e.Name())
+	}
+}
+
+func TestNewEmbedder_Local(t *testing.T) {
+	emb, err := NewEmbedder(EmbedderConfig{Provider: "local", Dim: 128})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := emb.(*LexicalEmbedder); !ok || emb.Dimensions() != 128 {
+		t.Errorf("no model: got %T with %d dims", emb, emb.Dimensions())
+	}
+	emb, err = NewEmbedder(EmbedderConfig{Provider: "local", Model: writeTestModel(t, "tiny.vec"), Dim: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if emb.Dimensions() != 4 {
+		t.Errorf("model dimension should win over cfg.Dim: %d", emb.Dimensions())
+	}
+}
+
+// TestSQLiteStore_LexicalEmbedderSearch: with the lexical fallback, vector
+// search ranks a paraphrase sharing the query's terms above unrelated text.
+func TestSQLiteStore_LexicalEmbedderSearch(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQLiteStore(ctx, filepath.Join(t.TempDir(), "m.sqlite"), NewLexicalEmbedder(256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	_ = s.Upsert(ctx, "ns", "a", "Retry the payment webhook when the gateway times out", nil)
+	_ = s.Upsert(ctx, "ns", "b", "Sidebar layout breaks on narrow screens", nil)
+	_ = s.Upsert(ctx, "ns", "c", "Rotate the signing keys every quarter", nil)
+	hits, err := s.SearchWithMode(ctx, "ns", "payment gateway timeouts", 1, ModeVector)
+	if err != nil || len(hits) != 1 || hits[0].Key != "a" {
+		t.Errorf("hits = %+v, %v", hits, err)
+	}
+}
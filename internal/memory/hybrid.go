@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SearchMode selects how a query is matched against stored records.
+type SearchMode string
+
+const (
+	// ModeVector ranks by cosine similarity of embeddings (the HNSW path).
+	ModeVector SearchMode = "vector"
+	// ModeLexical ranks by FTS5 BM25 over the raw content, so exact
+	// identifiers — ticket IDs, function names, error strings — match
+	// regardless of the embedder.
+	ModeLexical SearchMode = "lexical"
+	// ModeHybrid fuses the vector and lexical rankings with reciprocal
+	// rank fusion.
+	ModeHybrid SearchMode = "hybrid"
+)
+
+// rrfK is the reciprocal-rank-fusion damping constant from Cormack et al.
+// (2009); 60 is the value the literature and most engines settle on.
+const rrfK = 60
+
+// ParseSearchMode validates a --mode / MCP mode string. Empty is returned
+// as "" so callers can fall back to the store's default.
+func ParseSearchMode(s string) (SearchMode, error) {
+	switch m := SearchMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "", ModeVector, ModeLexical, ModeHybrid:
+		return m, nil
+	}
+	return "", ErrInvalid{Reason: fmt.Sprintf("unknown search mode %q (valid: lexical, vector, hybrid)", s)}
+}
+
+// ftsQuery turns free text into an FTS5 MATCH expression. Each
+// whitespace-separated word becomes a quoted phrase — so "TASK-00042" or
+// "connection refused:" match as written instead of being parsed as FTS5
+// operators — and the phrases are OR-ed so BM25 rewards records matching
+// more of them. Words with no letters or digits are dropped; an empty
+// result means nothing can match.
+func ftsQuery(query string) string {
+	var phrases []string
+	for _, word := range strings.Fields(query) {
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			continue
+		}
+		phrases = append(phrases, `"`+strings.ReplaceAll(word, `"`, `""`)+`"`)
+	}
+	return strings.Join(phrases, " OR ")
+}
+
+// bm25Score maps FTS5's bm25() — negative, lower is better, unbounded — onto
+// Hit.Score's (0, 1) range, preserving order.
+func bm25Score(rank float64) float32 {
+	b := -rank
+	if b < 0 {
+		b = 0
+	}
+	return float32(b / (1 + b))
+}
+
+// fuseRRF merges ranked hit lists by reciprocal rank fusion: each record
+// scores the sum of 1/(rrfK+rank) over the lists it appears in. Scores are
+// normalised so a record ranked first in every list scores 1. Ties break by
+// namespace and key for a stable order.
+func fuseRRF(k int, lists ...[]Hit) []Hit {
+	fused := map[string]*Hit{}
+	var order []string
+	for _, list := range lists {
+		for rank, h := range list {
+			ck := compositeKey(h.Namespace, h.Key)
+			f, ok := fused[ck]
+			if !ok {
+				hit := h
+				hit.Score = 0
+				f = &hit
+				fused[ck] = f
+				order = append(order, ck)
+			}
+			f.Score += 1 / float32(rrfK+rank+1)
+		}
+	}
+	best := float32(len(lists)) / float32(rrfK+1)
+	out := make([]Hit, 0, len(order))
+	for _, ck := range order {
+		h := *fused[ck]
+		h.Score /= best
+		out = append(out, h)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return compositeKey(out[i].Namespace, out[i].Key) < compositeKey(out[j].Namespace, out[j].Key)
+	})
+	if len(out) > k {
+		out = out[:k]
+	}
+	return out
+}
@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// namedEmbedder wraps the FakeEmbedder under another name so a store
+// treats it as a real, configured embedder.
+type namedEmbedder struct{ *FakeEmbedder }
+
+func (namedEmbedder) Name() string { return "test-real" }
+
+func seedIdentifiers(t *testing.T, s *SQLiteStore) {
+	t.Helper()
+	ctx := context.Background()
+	for key, content := range map[string]string{
+		"retry":   "TASK-00042 retry webhook deliveries with exponential backoff",
+		"neigh":   "TASK-00043 webhook signature verification",
+		"parser":  "refactor parse_config_file to return connection refused errors early",
+		"unrelat": "dark mode for the dashboard",
+	} {
+		if err := s.Upsert(ctx, "tickets", key, content, nil); err != nil {
+			t.Fatalf("Upsert %s: %v", key, err)
+		}
+	}
+}
+
+func TestSQLiteStore_LexicalFindsExactIdentifiers(t *testing.T) {
+	s := newTestStore(t)
+	seedIdentifiers(t, s)
+	ctx := context.Background()
+
+	for query, want := range map[string]string{
+		"TASK-00042":           "retry",
+		"parse_config_file":    "parser",
+		`"connection refused"`: "parser",
+		"webhook signature":    "neigh",
+	} {
+		hits, err := s.SearchWithMode(ctx, "tickets", query, 3, ModeLexical)
+		if err != nil {
+			t.Fatalf("lexical %q: %v", query, err)
+		}
+		if len(hits) == 0 || hits[0].Key != want {
+			t.Errorf("lexical %q = %v, want %s first", query, hits, want)
+			continue
+		}
+		if hits[0].Score <= 0 || hits[0].Score >= 1 {
+			t.Errorf("lexical %q score = %v, want within (0, 1)", query, hits[0].Score)
+		}
+	}
+
+	// FTS5 operator characters are literal, and no overlap means no hits.
+	for _, query := range []string{"AND OR NOT (", "-- ::", "zebra"} {
+		hits, err := s.SearchWithMode(ctx, "tickets", query, 3, ModeLexical)
+		if err != nil || len(hits) != 0 {
+			t.Errorf("lexical %q = %v, %v; want no hits and no error", query, hits, err)
+		}
+	}
+	if hits, _ := s.SearchWithMode(ctx, "other", "TASK-00042", 3, ModeLexical); len(hits) != 0 {
+		t.Errorf("lexical search leaked across namespaces: %v", hits)
+	}
+}
+
+func TestSQLiteStore_HybridFusesBothRankings(t *testing.T) {
+	s := newTestStore(t)
+	seedIdentifiers(t, s)
+	ctx := context.Background()
+
+	// The exact content is the vector top hit and the lexical top hit, so
+	// it fuses to a perfect score.
+	hits, err := s.SearchWithMode(ctx, "tickets", "dark mode for the dashboard", 2, ModeHybrid)
+	if err != nil {
+		t.Fatalf("hybrid: %v", err)
+	}
+	if len(hits) != 2 || hits[0].Key != "unrelat" || hits[0].Score < 0.999 {
+		t.Errorf("hybrid = %+v, want unrelat first with score 1", hits)
+	}
+	if hits[1].Score >= hits[0].Score {
+		t.Errorf("hybrid scores not descending: %+v", hits)
+	}
+}
+
+func TestSQLiteStore_DefaultSearchMode(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	if got := s.DefaultSearchMode(); got != ModeLexical {
+		t.Errorf("fake embedder default = %q, want lexical", got)
+	}
+	seedIdentifiers(t, s)
+	if hits, err := s.SearchWithMode(ctx, "tickets", "TASK-00043", 1, ""); err != nil || len(hits) != 1 || hits[0].Key != "neigh" {
+		t.Errorf("default-mode search = %v, %v", hits, err)
+	}
+
+	real, err := OpenSQLiteStore(ctx, filepath.Join(t.TempDir(), "m.sqlite"), namedEmbedder{NewFakeEmbedder(8)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer real.Close()
+	if got := real.DefaultSearchMode(); got != ModeHybrid {
+		t.Errorf("configured embedder default = %q, want hybrid", got)
+	}
+	if _, err := real.SearchWithMode(ctx, "tickets", "q", 1, "fuzzy"); err == nil {
+		t.Error("unknown mode should be rejected")
+	}
+}
+
+func TestSQLiteStore_FTSFollowsWritesAndBackfills(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "fts.sqlite")
+	s, err := OpenSQLiteStore(ctx, dbPath, NewFakeEmbedder(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Upsert(ctx, "ns", "a", "alpha original", nil)
+	_ = s.Upsert(ctx, "ns", "a", "alpha rewritten", nil)
+	_ = s.Upsert(ctx, "ns", "b", "beta", nil)
+	if hits, _ := s.SearchWithMode(ctx, "ns", "original", 5, ModeLexical); len(hits) != 0 {
+		t.Errorf("stale FTS row after update: %v", hits)
+	}
+	if err := s.Delete(ctx, "ns", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if hits, _ := s.SearchWithMode(ctx, "ns", "beta", 5, ModeLexical); len(hits) != 0 {
+		t.Errorf("FTS row survived delete: %v", hits)
+	}
+
+	// A store written before the FTS table existed is backfilled on open.
+	if _, err := s.db.ExecContext(ctx, `delete from memory_fts`); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+	s, err = OpenSQLiteStore(ctx, dbPath, NewFakeEmbedder(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if hits, _ := s.SearchWithMode(ctx, "ns", "rewritten", 5, ModeLexical); len(hits) != 1 || hits[0].Key != "a" {
+		t.Errorf("backfilled lexical search = %v", hits)
+	}
+}
+
+func TestFtsQuery(t *testing.T) {
+	cases := map[string]string{
+		"TASK-00042":           `"TASK-00042"`,
+		"retry  webhook":       `"retry" OR "webhook"`,
+		`say "hi"`:             `"say" OR """hi"""`,
+		"-- AND ::":            `"AND"`,
+		"   ":                  "",
+		"connection: refused!": `"connection:" OR "refused!"`,
+	}
+	for in, want := range cases {
+		if got := ftsQuery(in); got != want {
+			t.Errorf("ftsQuery(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestFuseRRF(t *testing.T) {
+	vector := []Hit{{Namespace: "n", Key: "a"}, {Namespace: "n", Key: "b"}, {Namespace: "n", Key: "c"}}
+	lexical := []Hit{{Namespace: "n", Key: "c"}, {Namespace: "n", Key: "a"}}
+	got := fuseRRF(3, vector, lexical)
+	if len(got) != 3 || got[0].Key != "a" || got[1].Key != "c" || got[2].Key != "b" {
+		t.Fatalf("fused order = %+v, want a, c, b", got)
+	}
+	if one := fuseRRF(1, vector, vector); one[0].Score < 0.999 || one[0].Score > 1.001 {
+		t.Errorf("first in every list should score 1, got %v", one[0].Score)
+	}
+}
+
+func TestParseSearchMode(t *testing.T) {
+	for in, want := range map[string]SearchMode{"": "", "Lexical": ModeLexical, " vector ": ModeVector, "hybrid": ModeHybrid} {
+		if got, err := ParseSearchMode(in); err != nil || got != want {
+			t.Errorf("ParseSearchMode(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseSearchMode("bm25"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
@@ -1,7 +1,8 @@
 // Package memory provides adb's namespaced vector-memory substrate. Records
 // are keyed by (namespace, key); content is embedded via a pluggable
 // EmbeddingProvider; nearest-neighbour queries use an in-memory HNSW index
-// layered over SQLite for persistence.
+// layered over SQLite for persistence, and an FTS5 table beside it serves
+// lexical (BM25) and hybrid queries — see SearchMode.
 //
 // Design sketched in .wiki/concepts/Vector Memory in adb.md on the consumer
 // monorepo. Rationale in .wiki/decisions/0002-ruflo-dispatch-and-vector-
@@ -16,6 +17,7 @@ package memory
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // Store is the persistence + retrieval contract for adb's vector memory.
@@ -32,6 +34,12 @@ type Store interface {
 	// Returns nil slice (not error) when the namespace is empty.
 	Search(ctx context.Context, ns, query string, k int) ([]Hit, error)
 
+	// SearchMulti ranks records across every namespace matching q's glob,
+	// after applying its metadata and time filters, in q.Mode. It is the
+	// one deliberate exception to namespace scoping: callers opt in with
+	// an explicit glob.
+	SearchMulti(ctx context.Context, q Query) ([]Hit, error)
+
 	// Delete removes the record at (ns, key). Missing records are a
 	// no-op, not an error.
 	Delete(ctx context.Context, ns, key string) error
@@ -52,6 +60,24 @@ type Hit struct {
 	Score   float32
 	Content string
 	Meta    map[string]string
+	Updated time.Time // when the record was last upserted
+}
+
+// MetaDoc and MetaHeading are the Meta keys a chunked indexer
+// (core.KnowledgeIndexer) records on each chunk: the parent document's key
+// and the chunk's heading path.
+const (
+	MetaDoc     = "doc"
+	MetaHeading = "heading"
+)
+
+// Doc returns the parent document of a chunk hit — Meta["doc"] — or the
+// record's own Key when it was stored whole.
+func (h Hit) Doc() string {
+	if d := h.Meta[MetaDoc]; d != "" {
+		return d
+	}
+	return h.Key
 }
 
 // Entry is the stored form of a record (used by export / import paths).
@@ -363,3 +363,97 @@ func TestSQLiteStore_ManyRecords_TopHit(t *testing.T) {
 		}
 	})
 }
+
+// countingEmbedder counts Embed calls.
+type countingEmbedder struct {
+	*FakeEmbedder
+	calls int
+}
+
+func (c *countingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	c.calls++
+	return c.FakeEmbedder.Embed(ctx, text)
+}
+
+// TestSQLiteStore_UpsertSameContentReusesEmbedding: re-upserting unchanged
+// content with new meta (a chunk whose offsets moved) skips the embedder,
+// and ListMeta reports the new meta.
+func TestSQLiteStore_UpsertSameContentReusesEmbedding(t *testing.T) {
+	ctx := context.Background()
+	emb := &countingEmbedder{FakeEmbedder: NewFakeEmbedder(16)}
+	s, err := OpenSQLiteStore(ctx, filepath.Join(t.TempDir(), "m.sqlite"), emb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	_ = s.Upsert(ctx, "ns", "notes.md#abc", "chunk text", map[string]string{MetaDoc: "notes.md", "start": "0"})
+	_ = s.Upsert(ctx, "ns", "notes.md#abc", "chunk text", map[string]string{MetaDoc: "notes.md", "start": "40"})
+	if emb.calls != 1 {
+		t.Errorf("embedder called %d times, want 1", emb.calls)
+	}
+	_ = s.Upsert(ctx, "ns", "notes.md#abc", "edited chunk text", nil)
+	if emb.calls != 2 {
+		t.Errorf("changed content must re-embed: %d calls", emb.calls)
+	}
+	_ = s.Upsert(ctx, "other", "k", "x", map[string]string{"a": "b"})
+
+	meta, err := s.ListMeta(ctx, "ns")
+	if err != nil || len(meta) != 1 || meta["notes.md#abc"] == nil || len(meta["notes.md#abc"]) != 0 {
+		t.Errorf("ListMeta = %v, %v", meta, err)
+	}
+}
+
+func TestHit_Doc(t *testing.T) {
+	if got := (Hit{Key: "notes.md#abc", Meta: map[string]string{MetaDoc: "notes.md"}}).Doc(); got != "notes.md" {
+		t.Errorf("chunk Doc = %q", got)
+	}
+	if got := (Hit{Key: "transcript"}).Doc(); got != "transcript" {
+		t.Errorf("whole-record Doc = %q", got)
+	}
+}
+
+func TestSQLiteStore_DeleteAndRenameNamespace(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	_ = s.Upsert(ctx, "tickets/TASK-1", "a", "alpha webhook", map[string]string{"source": "x"})
+	_ = s.Upsert(ctx, "tickets/TASK-1", "b", "beta webhook", nil)
+	_ = s.Upsert(ctx, "archive/tickets/TASK-1", "b", "stale beta", nil)
+	_ = s.Upsert(ctx, "tickets/TASK-2", "c", "gamma webhook", nil)
+	before, _ := s.ListUpdated(ctx, "tickets/TASK-1")
+
+	moved, err := s.RenameNamespace(ctx, "tickets/TASK-1", "archive/tickets/TASK-1")
+	if err != nil || moved != 2 {
+		t.Fatalf("RenameNamespace = %d, %v; want 2", moved, err)
+	}
+	hits, _ := s.SearchMulti(ctx, Query{Namespace: "archive/*/*", Text: "webhook", K: 5})
+	if len(hits) != 2 {
+		t.Errorf("moved records not searchable under the new namespace: %+v", hits)
+	}
+	for _, h := range hits {
+		if h.Key == "b" && h.Content != "beta webhook" {
+			t.Errorf("rename should replace the record at the target key, got %q", h.Content)
+		}
+		if h.Key == "a" && (h.Meta["source"] != "x" || !h.Updated.Equal(before["a"])) {
+			t.Errorf("rename lost meta or timestamp: %+v", h)
+		}
+	}
+	if hits, _ := s.SearchWithMode(ctx, "tickets/TASK-1", "webhook", 5, ModeLexical); len(hits) != 0 {
+		t.Errorf("old namespace still answers: %+v", hits)
+	}
+
+	removed, err := s.DeleteNamespace(ctx, "archive/tickets/TASK-1")
+	if err != nil || removed != 2 {
+		t.Fatalf("DeleteNamespace = %d, %v; want 2", removed, err)
+	}
+	names, _ := s.ListNamespaces(ctx)
+	if len(names) != 1 || names[0] != "tickets/TASK-2" {
+		t.Errorf("namespaces after delete = %v", names)
+	}
+	if hits, _ := s.SearchMulti(ctx, Query{Text: "webhook", K: 5, Mode: ModeVector}); len(hits) != 1 {
+		t.Errorf("deleted records still in the vector index: %+v", hits)
+	}
+	if n, err := s.DeleteNamespace(ctx, "nope"); err != nil || n != 0 {
+		t.Errorf("deleting a missing namespace = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
+// Query is a SearchMulti request: one ranking across every namespace that
+// matches a glob, restricted by metadata and update time.
+type Query struct {
+	// Namespace is a path.Match glob — "tickets/*", "sessions/*" — or an
+	// exact namespace. Empty matches every namespace. As with path.Match,
+	// "*" does not cross a "/".
+	Namespace string
+	Text      string
+	K         int        // <= 0 defaults to 5
+	Mode      SearchMode // "" uses the store's DefaultSearchMode
+
+	// Where keeps records whose Meta has every key with exactly that value
+	// (task_id, source, doc, …).
+	Where map[string]string
+	// Since and Until bound the record's last update, inclusive and
+	// exclusive respectively; zero leaves that side open.
+	Since, Until time.Time
+	// MinScore drops hits whose ranker scored them below it: cosine
+	// similarity for vector, mapped BM25 for lexical. Hybrid applies it to
+	// each ranking before fusion, so it means the same as in either mode and
+	// a hit survives by clearing it in one; the fused rank score is not
+	// floored.
+	MinScore float32
+}
+
+// validate rejects a malformed glob or an empty time range up front, so
+// a typo surfaces as an error rather than as no results.
+func (q Query) validate() error {
+	if _, err := path.Match(q.Namespace, ""); err != nil {
+		return ErrInvalid{Reason: fmt.Sprintf("namespace glob %q: %v", q.Namespace, err)}
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && !q.Until.After(q.Since) {
+		return ErrInvalid{Reason: "until must be after since"}
+	}
+	if q.MinScore < 0 || q.MinScore > 1 {
+		return ErrInvalid{Reason: "min score must be within [0, 1]"}
+	}
+	return nil
+}
+
+// matches reports whether a record passes the namespace glob, metadata
+// and time filters. The pattern was checked by validate.
+func (q Query) matches(ns string, meta map[string]string, updated time.Time) bool {
+	if q.Namespace != "" {
+		if ok, _ := path.Match(q.Namespace, ns); !ok {
+			return false
+		}
+	}
+	for k, v := range q.Where {
+		if got, ok := meta[k]; !ok || got != v {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && updated.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !updated.Before(q.Until) {
+		return false
+	}
+	return true
+}
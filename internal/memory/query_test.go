@@ -0,0 +1,229 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// seedMulti writes records across ticket and session namespaces with
+// source/task_id metadata.
+func seedMulti(t *testing.T, s *SQLiteStore) {
+	t.Helper()
+	ctx := context.Background()
+	for _, r := range []struct{ ns, key, content, source, task string }{
+		{"tickets/TASK-00001", "context.md", "webhook retries use exponential backoff", "ticket-knowledge", "TASK-00001"},
+		{"tickets/TASK-00001", "edge", "TASK-00001 blocks TASK-00002 webhook", "graph-edge", "TASK-00001"},
+		{"tickets/TASK-00002", "notes.md", "webhook signature verification notes", "ticket-knowledge", "TASK-00002"},
+		{"sessions/S-1", "summary", "debugged the webhook handler", "session", "TASK-00002"},
+	} {
+		meta := map[string]string{"source": r.source, "task_id": r.task}
+		if err := s.Upsert(ctx, r.ns, r.key, r.content, meta); err != nil {
+			t.Fatalf("Upsert %s/%s: %v", r.ns, r.key, err)
+		}
+	}
+}
+
+func hitNames(hits []Hit) map[string]bool {
+	out := map[string]bool{}
+	for _, h := range hits {
+		out[h.Namespace+"/"+h.Key] = true
+	}
+	return out
+}
+
+func TestSQLiteStore_SearchMultiFilters(t *testing.T) {
+	s := newTestStore(t)
+	seedMulti(t, s)
+	ctx := context.Background()
+
+	cases := []struct {
+		name string
+		q    Query
+		want []string
+	}{
+		{"all namespaces", Query{Text: "webhook", K: 10},
+			[]string{"tickets/TASK-00001/context.md", "tickets/TASK-00001/edge", "tickets/TASK-00002/notes.md", "sessions/S-1/summary"}},
+		{"glob", Query{Namespace: "tickets/*", Text: "webhook", K: 10},
+			[]string{"tickets/TASK-00001/context.md", "tickets/TASK-00001/edge", "tickets/TASK-00002/notes.md"}},
+		{"glob and where", Query{Namespace: "tickets/*", Text: "webhook", K: 10, Where: map[string]string{"source": "ticket-knowledge"}},
+			[]string{"tickets/TASK-00001/context.md", "tickets/TASK-00002/notes.md"}},
+		{"where across namespaces", Query{Text: "webhook", K: 10, Where: map[string]string{"task_id": "TASK-00002"}},
+			[]string{"tickets/TASK-00002/notes.md", "sessions/S-1/summary"}},
+		{"exact namespace", Query{Namespace: "sessions/S-1", Text: "webhook", K: 10},
+			[]string{"sessions/S-1/summary"}},
+		{"no match", Query{Namespace: "archive/*", Text: "webhook", K: 10}, nil},
+	}
+	for _, tc := range cases {
+		hits, err := s.SearchMulti(ctx, tc.q)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		got := hitNames(hits)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: hits = %v, want %v", tc.name, got, tc.want)
+			continue
+		}
+		for _, w := range tc.want {
+			if !got[w] {
+				t.Errorf("%s: missing %s in %v", tc.name, w, got)
+			}
+		}
+	}
+}
+
+func TestSQLiteStore_SearchMultiRanksAcrossNamespaces(t *testing.T) {
+	s := newTestStore(t)
+	seedMulti(t, s)
+	hits, err := s.SearchMulti(context.Background(), Query{Text: "webhook signature verification", K: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) == 0 || hits[0].Key != "notes.md" {
+		t.Fatalf("top hit = %+v, want notes.md", hits)
+	}
+	for i := 1; i < len(hits); i++ {
+		if hits[i].Score > hits[i-1].Score {
+			t.Errorf("scores not descending: %+v", hits)
+		}
+	}
+}
+
+func TestSQLiteStore_SearchMultiDateRangeAndScoreFloor(t *testing.T) {
+	s := newTestStore(t)
+	seedMulti(t, s)
+	ctx := context.Background()
+
+	// Backdate the session so only it falls before the cut-off.
+	old := time.Now().Add(-72 * time.Hour)
+	ck := compositeKey("sessions/S-1", "summary")
+	n := s.nodes[ck]
+	n.updated = old
+	s.nodes[ck] = n
+
+	cut := time.Now().Add(-24 * time.Hour)
+	hits, err := s.SearchMulti(ctx, Query{Text: "webhook", K: 10, Since: cut})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitNames(hits); len(got) != 3 || got["sessions/S-1/summary"] {
+		t.Errorf("since: hits = %v, want the three ticket records", got)
+	}
+	hits, _ = s.SearchMulti(ctx, Query{Text: "webhook", K: 10, Until: cut})
+	if len(hits) != 1 || hits[0].Namespace != "sessions/S-1" || !hits[0].Updated.Equal(old) {
+		t.Errorf("until: hits = %+v, want only the backdated session", hits)
+	}
+
+	all, _ := s.SearchMulti(ctx, Query{Text: "webhook signature verification", K: 10})
+	floor := all[0].Score
+	hits, _ = s.SearchMulti(ctx, Query{Text: "webhook signature verification", K: 10, MinScore: floor})
+	for _, h := range hits {
+		if h.Score < floor {
+			t.Errorf("hit %s/%s scored %v below the floor %v", h.Namespace, h.Key, h.Score, floor)
+		}
+	}
+	if len(hits) == 0 || len(hits) >= len(all) {
+		t.Errorf("min score kept %d of %d hits", len(hits), len(all))
+	}
+}
+
+// TestSQLiteStore_SearchMultiHybridFloorsEachRanking: in hybrid mode the
+// floor applies to the rankers' own scores, so a hybrid hit is exactly one
+// that clears it in vector or lexical mode — never one kept only for
+// ranking first in a list.
+func TestSQLiteStore_SearchMultiHybridFloorsEachRanking(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQLiteStore(ctx, filepath.Join(t.TempDir(), "m.sqlite"), NewLexicalEmbedder(256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	seedMulti(t, s)
+
+	const text, floor = "webhook signature verification", 0.4
+	search := func(mode SearchMode) map[string]bool {
+		hits, err := s.SearchMulti(ctx, Query{Text: text, K: 10, Mode: mode, MinScore: floor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hitNames(hits)
+	}
+	want := search(ModeVector)
+	for name := range search(ModeLexical) {
+		want[name] = true
+	}
+	got := search(ModeHybrid)
+	if len(got) != len(want) || len(want) == 0 || len(want) == 4 {
+		t.Fatalf("hybrid kept %v, want the union %v of a floor that drops some hits", got, want)
+	}
+	for name := range want {
+		if !got[name] {
+			t.Errorf("hybrid dropped %s, which clears the floor in one ranking", name)
+		}
+	}
+}
+
+// TestSQLiteStore_SearchMultiSmallNamespaceNotDrowned: a filter matching a
+// handful of records still finds them when hundreds of closer vectors
+// live elsewhere, because filtering precedes the HNSW over-fetch.
+func TestSQLiteStore_SearchMultiSmallNamespaceNotDrowned(t *testing.T) {
+	saved := bruteForceMax
+	bruteForceMax = 50
+	t.Cleanup(func() { bruteForceMax = saved })
+
+	s := newTestStore(t)
+	ctx := context.Background()
+	for i := 0; i < 300; i++ {
+		if err := s.Upsert(ctx, "bulk", fmt.Sprintf("k%04d", i), fmt.Sprintf("bulk record %d about caching", i), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = s.Upsert(ctx, "tickets/TASK-00009", "context.md", "an unrelated note on release trains", map[string]string{"source": "ticket-knowledge"})
+
+	for _, mode := range []SearchMode{ModeVector, ModeHybrid} {
+		hits, err := s.SearchMulti(ctx, Query{Namespace: "tickets/*", Text: "bulk record 7 about caching", K: 3, Mode: mode})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hits) != 1 || hits[0].Namespace != "tickets/TASK-00009" {
+			t.Errorf("%s: hits = %+v, want the lone ticket record", mode, hits)
+		}
+	}
+
+	// A broad filter takes the (approximate) graph walk and still returns
+	// k hits, all inside the filter.
+	hits, err := s.SearchMulti(ctx, Query{Namespace: "bulk", Text: "bulk record 7 about caching", K: 5, Mode: ModeVector})
+	if err != nil || len(hits) != 5 {
+		t.Fatalf("bulk: hits = %+v, %v; want 5", hits, err)
+	}
+	for _, h := range hits {
+		if h.Namespace != "bulk" {
+			t.Errorf("bulk: hit outside the filter: %+v", h)
+		}
+	}
+}
+
+func TestQuery_Validate(t *testing.T) {
+	now := time.Now()
+	for name, q := range map[string]Query{
+		"bad glob":        {Namespace: "tickets/["},
+		"empty range":     {Since: now, Until: now},
+		"negative floor":  {MinScore: -0.1},
+		"floor above one": {MinScore: 1.5},
+	} {
+		if err := q.validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+	if err := (Query{Namespace: "sessions/*", Since: now.Add(-time.Hour), Until: now, MinScore: 0.5}).validate(); err != nil {
+		t.Errorf("valid query rejected: %v", err)
+	}
+	if !(Query{Namespace: "tickets/*"}).matches("tickets/TASK-1", nil, now) {
+		t.Error("glob should match a direct child")
+	}
+	if (Query{Namespace: "tickets/*"}).matches("tickets/TASK-1/sub", nil, now) {
+		t.Error("* must not cross a /")
+	}
+}
@@ -0,0 +1,301 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReembedOptions tunes a Reembed run.
+type ReembedOptions struct {
+	// RatePerSecond caps Embed calls per second; <= 0 means unlimited.
+	RatePerSecond float64
+	// Progress, when set, is called after each record with the number of
+	// records embedded so far (including those carried over from an
+	// interrupted run) and the total.
+	Progress func(done, total int)
+}
+
+// ReembedStats reports what a Reembed run did.
+type ReembedStats struct {
+	Total    int    // records in the store
+	Resumed  int    // records already embedded by an earlier, interrupted run
+	Embedded int    // records embedded by this run
+	From     string // embedder the store was pinned to
+	To       string // embedder it is pinned to now
+}
+
+// reembedMaxPasses bounds the catch-up loop before the swap: each pass
+// embeds records written or edited since the previous one.
+const reembedMaxPasses = 5
+
+// Reembed migrates the store at dbPath to a new embedder. Every record's
+// content is streamed through to into a shadow table, memory_reembed; once
+// all of them are there, one transaction copies the new vectors over the
+// old ones and re-pins the store's embedder name and dimension, so readers
+// see either the old store or the new one, never a mix.
+//
+// The shadow table is the checkpoint: an interrupted run (ctx cancelled,
+// embedder error, process killed) keeps the records it finished, and a
+// later Reembed to the same embedder skips them. A record whose content
+// changed since it was shadowed is embedded again, so writes made while
+// the migration runs are not lost. Switching to a different target
+// discards the shadow and starts over.
+//
+// Reembed works on the database directly and never calls the old
+// embedder, so the migration does not need the old provider to be
+// reachable. Other adb processes should not write to the store while it
+// runs: once the swap lands, a writer still holding the old embedder
+// would add vectors of the wrong size.
+func Reembed(ctx context.Context, dbPath string, to EmbeddingProvider, opts ReembedOptions) (ReembedStats, error) {
+	var stats ReembedStats
+	if to == nil || to.Dimensions() <= 0 {
+		return stats, ErrInvalid{Reason: "target embedder must be set and have dimensions > 0"}
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return stats, fmt.Errorf("memory store: %w", err)
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return stats, fmt.Errorf("open sqlite at %q: %w", dbPath, err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, "PRAGMA busy_timeout=5000; PRAGMA journal_mode=WAL;"); err != nil {
+		return stats, fmt.Errorf("sqlite pragmas: %w", err)
+	}
+
+	fromName, fromDim, err := readEmbedderMeta(ctx, db)
+	if err != nil {
+		return stats, err
+	}
+	stats.From = fmt.Sprintf("%s (%d dims)", fromName, fromDim)
+	stats.To = fmt.Sprintf("%s (%d dims)", to.Name(), to.Dimensions())
+	if fromName == to.Name() && fromDim == to.Dimensions() {
+		return stats, ErrInvalid{Reason: fmt.Sprintf("store already uses %s", stats.To)}
+	}
+	if err := prepareShadow(ctx, db, to); err != nil {
+		return stats, err
+	}
+	if err := db.QueryRowContext(ctx, `select count(*) from memory_entries`).Scan(&stats.Total); err != nil {
+		return stats, fmt.Errorf("count entries: %w", err)
+	}
+
+	limiter := newRateLimiter(opts.RatePerSecond)
+	for pass := 0; pass < reembedMaxPasses; pass++ {
+		pending, err := pendingReembed(ctx, db)
+		if err != nil {
+			return stats, err
+		}
+		if pass == 0 {
+			stats.Resumed = stats.Total - len(pending)
+		}
+		if len(pending) == 0 {
+			swapped, err := swapReembed(ctx, db, to)
+			if err != nil {
+				return stats, err
+			}
+			if swapped {
+				return stats, nil
+			}
+			continue // a write landed between the scan and the swap
+		}
+		done := stats.Total - len(pending)
+		for _, r := range pending {
+			if err := limiter.wait(ctx); err != nil {
+				return stats, err
+			}
+			vec, err := to.Embed(ctx, r.content)
+			if errors.Is(err, ErrNoEmbedding) {
+				vec, err = nil, nil // kept for lexical search only
+			}
+			if err != nil {
+				return stats, fmt.Errorf("embed %s/%s: %w", r.ns, r.key, err)
+			}
+			if vec != nil && len(vec) != to.Dimensions() {
+				return stats, fmt.Errorf("embedder returned vector of length %d, expected %d", len(vec), to.Dimensions())
+			}
+			if _, err := db.ExecContext(ctx, `
+insert into memory_reembed (namespace, entry_key, content, embedding) values (?, ?, ?, ?)
+on conflict (namespace, entry_key) do update set content = excluded.content, embedding = excluded.embedding
+`, r.ns, r.key, r.content, encodeVector(vec)); err != nil {
+				return stats, fmt.Errorf("write shadow %s/%s: %w", r.ns, r.key, err)
+			}
+			stats.Embedded++
+			done++
+			if opts.Progress != nil {
+				opts.Progress(min(done, stats.Total), stats.Total)
+			}
+		}
+	}
+	return stats, fmt.Errorf("store kept changing during re-embed (%d passes); stop other writers and rerun to resume", reembedMaxPasses)
+}
+
+// readEmbedderMeta returns the embedder the store is pinned to.
+func readEmbedderMeta(ctx context.Context, db *sql.DB) (string, int, error) {
+	var name, dim string
+	err := db.QueryRowContext(ctx, `select v from memory_metadata where k = 'embedder_name'`).Scan(&name)
+	if err == nil {
+		err = db.QueryRowContext(ctx, `select v from memory_metadata where k = 'embedder_dim'`).Scan(&dim)
+	}
+	if errors.Is(err, sql.ErrNoRows) || (err != nil && isMissingTable(err)) {
+		return "", 0, ErrInvalid{Reason: "no memory store to re-embed (the database has no embedder recorded)"}
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("read embedder metadata: %w", err)
+	}
+	n, err := strconv.Atoi(dim)
+	if err != nil {
+		return "", 0, fmt.Errorf("embedder_dim %q: %w", dim, err)
+	}
+	return name, n, nil
+}
+
+// isMissingTable reports SQLite's "no such table" error, which the driver
+// only exposes as text.
+func isMissingTable(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "no such table")
+}
+
+// prepareShadow creates the shadow table, or empties it when it was
+// started for a different target than to.
+func prepareShadow(ctx context.Context, db *sql.DB, to EmbeddingProvider) error {
+	if _, err := db.ExecContext(ctx, `
+create table if not exists memory_reembed (
+    namespace   text not null,
+    entry_key   text not null,
+    content     text not null,
+    embedding   blob not null,
+    primary key (namespace, entry_key)
+)`); err != nil {
+		return fmt.Errorf("create shadow table: %w", err)
+	}
+	target := fmt.Sprintf("%s/%d", to.Name(), to.Dimensions())
+	var current string
+	err := db.QueryRowContext(ctx, `select v from memory_metadata where k = 'reembed_target'`).Scan(&current)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("read re-embed checkpoint: %w", err)
+	}
+	if current == target {
+		return nil
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("reset shadow table: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, `delete from memory_reembed`); err != nil {
+		return fmt.Errorf("reset shadow table: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `insert into memory_metadata (k, v) values ('reembed_target', ?) on conflict (k) do update set v = excluded.v`, target); err != nil {
+		return fmt.Errorf("record re-embed target: %w", err)
+	}
+	return tx.Commit()
+}
+
+type reembedRecord struct{ ns, key, content string }
+
+// pendingReembed lists the records with no shadow row, or whose content
+// changed after theirs was written.
+func pendingReembed(ctx context.Context, db *sql.DB) ([]reembedRecord, error) {
+	rows, err := db.QueryContext(ctx, `
+select e.namespace, e.entry_key, e.content
+from memory_entries e
+left join memory_reembed r on r.namespace = e.namespace and r.entry_key = e.entry_key
+where r.entry_key is null or r.content != e.content
+order by e.namespace, e.entry_key`)
+	if err != nil {
+		return nil, fmt.Errorf("scan pending records: %w", err)
+	}
+	defer rows.Close()
+	var out []reembedRecord
+	for rows.Next() {
+		var r reembedRecord
+		if err := rows.Scan(&r.ns, &r.key, &r.content); err != nil {
+			return nil, fmt.Errorf("scan pending records: %w", err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// swapReembed installs the shadow vectors and re-pins the embedder in one
+// transaction. It reports false, changing nothing, when a record became
+// pending since the last scan.
+func swapReembed(ctx context.Context, db *sql.DB, to EmbeddingProvider) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("swap: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	var stale int
+	if err := tx.QueryRowContext(ctx, `
+select count(*) from memory_entries e
+left join memory_reembed r on r.namespace = e.namespace and r.entry_key = e.entry_key
+where r.entry_key is null or r.content != e.content`).Scan(&stale); err != nil {
+		return false, fmt.Errorf("swap: %w", err)
+	}
+	if stale > 0 {
+		return false, nil
+	}
+	for _, stmt := range []struct {
+		sql  string
+		args []any
+	}{
+		{`update memory_entries set embedding = (
+    select r.embedding from memory_reembed r
+    where r.namespace = memory_entries.namespace and r.entry_key = memory_entries.entry_key)`, nil},
+		{`update memory_metadata set v = ? where k = 'embedder_name'`, []any{to.Name()}},
+		{`update memory_metadata set v = ? where k = 'embedder_dim'`, []any{strconv.Itoa(to.Dimensions())}},
+		{`delete from memory_metadata where k = 'reembed_target'`, nil},
+		{`drop table memory_reembed`, nil},
+	} {
+		if _, err := tx.ExecContext(ctx, stmt.sql, stmt.args...); err != nil {
+			return false, fmt.Errorf("swap: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("swap: %w", err)
+	}
+	return true, nil
+}
+
+// rateLimiter spaces calls at least 1/rate apart.
+type rateLimiter struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the next call is allowed or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if l.interval == 0 {
+		return nil
+	}
+	now := time.Now()
+	if l.next.After(now) {
+		timer := time.NewTimer(l.next.Sub(now))
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		now = l.next
+	}
+	l.next = now.Add(l.interval)
+	return nil
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// failingEmbedder fails every Embed call after the first ok.
+type failingEmbedder struct {
+	*FakeEmbedder
+	ok, calls int
+}
+
+func (f *failingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	f.calls++
+	if f.calls > f.ok {
+		return nil, errors.New("provider unavailable")
+	}
+	return f.FakeEmbedder.Embed(ctx, text)
+}
+
+func seedReembedStore(t *testing.T, n int) string {
+	t.Helper()
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "reembed.sqlite")
+	s, err := OpenSQLiteStore(ctx, dbPath, NewFakeEmbedder(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for i := 0; i < n; i++ {
+		ns := "tickets/A"
+		if i%2 == 1 {
+			ns = "tickets/B"
+		}
+		if err := s.Upsert(ctx, ns, fmt.Sprintf("k%02d", i), fmt.Sprintf("record number %d", i), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dbPath
+}
+
+// TestReembed_SwapsToNewDimension: after a re-embed from a 16-dim to a
+// 32-dim embedder the store opens with the new one, refuses the old one,
+// and search still finds each record by its own content.
+func TestReembed_SwapsToNewDimension(t *testing.T) {
+	ctx := context.Background()
+	dbPath := seedReembedStore(t, 10)
+
+	var progress []int
+	stats, err := Reembed(ctx, dbPath, NewFakeEmbedder(32), ReembedOptions{
+		Progress: func(done, total int) { progress = append(progress, done) },
+	})
+	if err != nil {
+		t.Fatalf("Reembed: %v", err)
+	}
+	if stats.Total != 10 || stats.Embedded != 10 || stats.Resumed != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if len(progress) != 10 || progress[9] != 10 {
+		t.Errorf("progress = %v", progress)
+	}
+
+	if _, err := OpenSQLiteStore(ctx, dbPath, NewFakeEmbedder(16)); err == nil {
+		t.Fatal("old embedder should be refused after the swap")
+	}
+	s, err := OpenSQLiteStore(ctx, dbPath, NewFakeEmbedder(32))
+	if err != nil {
+		t.Fatalf("open with new embedder: %v", err)
+	}
+	defer s.Close()
+	hits, err := s.Search(ctx, "tickets/B", "record number 7", 1)
+	if err != nil || len(hits) != 1 || hits[0].Key != "k07" {
+		t.Fatalf("search after swap = %+v, %v", hits, err)
+	}
+	var shadow int
+	_ = s.db.QueryRowContext(ctx, `select count(*) from sqlite_master where name = 'memory_reembed'`).Scan(&shadow)
+	if shadow != 0 {
+		t.Error("shadow table should be dropped after the swap")
+	}
+}
+
+// TestReembed_ResumesAfterInterruption: a run that fails midway leaves
+// the store on the old embedder, and the next run embeds only what is
+// left — including a record edited in between.
+func TestReembed_ResumesAfterInterruption(t *testing.T) {
+	ctx := context.Background()
+	dbPath := seedReembedStore(t, 10)
+
+	flaky := &failingEmbedder{FakeEmbedder: NewFakeEmbedder(32), ok: 4}
+	if _, err := Reembed(ctx, dbPath, flaky, ReembedOptions{}); err == nil {
+		t.Fatal("expected the interrupted run to fail")
+	}
+	s, err := OpenSQLiteStore(ctx, dbPath, NewFakeEmbedder(16))
+	if err != nil {
+		t.Fatalf("store must stay on the old embedder until the swap: %v", err)
+	}
+	// k00 was shadowed by the failed run; editing it must re-embed it.
+	if err := s.Upsert(ctx, "tickets/A", "k00", "record zero, edited", nil); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+
+	emb := &countingEmbedder{FakeEmbedder: NewFakeEmbedder(32)}
+	stats, err := Reembed(ctx, dbPath, emb, ReembedOptions{})
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if stats.Resumed != 3 || stats.Embedded != 7 || emb.calls != 7 {
+		t.Errorf("resume stats = %+v, calls = %d; want 3 resumed, 7 embedded", stats, emb.calls)
+	}
+
+	s, err = OpenSQLiteStore(ctx, dbPath, NewFakeEmbedder(32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	hits, _ := s.Search(ctx, "tickets/A", "record zero, edited", 1)
+	if len(hits) != 1 || hits[0].Key != "k00" || hits[0].Score < 0.99 {
+		t.Errorf("edited record not re-embedded: %+v", hits)
+	}
+}
+
+// TestReembed_TargetChangeRestarts: switching targets mid-migration
+// discards the shadow rows embedded for the first one.
+func TestReembed_TargetChangeRestarts(t *testing.T) {
+	ctx := context.Background()
+	dbPath := seedReembedStore(t, 6)
+
+	_, _ = Reembed(ctx, dbPath, &failingEmbedder{FakeEmbedder: NewFakeEmbedder(32), ok: 3}, ReembedOptions{})
+	stats, err := Reembed(ctx, dbPath, NewFakeEmbedder(24), ReembedOptions{})
+	if err != nil || stats.Resumed != 0 || stats.Embedded != 6 {
+		t.Fatalf("stats = %+v, %v; want a fresh run", stats, err)
+	}
+	s, err := OpenSQLiteStore(ctx, dbPath, NewFakeEmbedder(24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+}
+
+func TestReembed_Refuses(t *testing.T) {
+	ctx := context.Background()
+	dbPath := seedReembedStore(t, 2)
+	if _, err := Reembed(ctx, dbPath, NewFakeEmbedder(16), ReembedOptions{}); !errors.As(err, new(ErrInvalid)) {
+		t.Errorf("same embedder: err = %v, want ErrInvalid", err)
+	}
+	if _, err := Reembed(ctx, filepath.Join(t.TempDir(), "missing.sqlite"), NewFakeEmbedder(32), ReembedOptions{}); err == nil {
+		t.Error("missing database: expected an error")
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := Reembed(cancelled, dbPath, NewFakeEmbedder(32), ReembedOptions{}); err == nil {
+		t.Error("cancelled context: expected an error")
+	}
+}
+
+func TestRateLimiter_Spaces(t *testing.T) {
+	l := newRateLimiter(1000)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := l.wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if l.interval.Milliseconds() != 1 {
+		t.Errorf("interval = %v, want 1ms", l.interval)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := newRateLimiter(0).wait(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait on cancelled ctx = %v", err)
+	}
+}
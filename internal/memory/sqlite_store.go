@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -25,8 +26,9 @@ const (
 )
 
 // SQLiteStore persists memory entries in a SQLite database and keeps an
-// in-memory HNSW index for vector search. Thread-safe: all operations
-// take a mutex before touching SQLite or the index.
+// in-memory HNSW index for vector search, plus an FTS5 table maintained
+// alongside memory_entries for lexical (BM25) search. Thread-safe: all
+// operations take a mutex before touching SQLite or the index.
 //
 // The HNSW index is rebuilt from SQLite on Open. Crash recovery is
 // therefore "durable on disk, ephemeral in RAM" — no index files are
@@ -51,6 +53,7 @@ type indexNode struct {
 	content string
 	meta    map[string]string
 	vec     []float32
+	updated time.Time
 }
 
 func (n indexNode) ID() string           { return n.compKey }
@@ -115,11 +118,45 @@ create table if not exists memory_metadata (
     k text primary key,
     v text not null
 );
+create virtual table if not exists memory_fts using fts5(
+    namespace unindexed,
+    entry_key unindexed,
+    content,
+    tokenize = "unicode61 tokenchars '_'"
+);
 `
 	if _, err := s.db.ExecContext(ctx, ddl); err != nil {
 		return fmt.Errorf("initSchema: %w", err)
 	}
-	return nil
+	return s.backfillFTS(ctx)
+}
+
+// backfillFTS repopulates memory_fts from memory_entries when the two
+// disagree — a database created before lexical search existed, or one
+// written by an older adb. Writes keep them in step after that.
+func (s *SQLiteStore) backfillFTS(ctx context.Context) error {
+	var entries, indexed int
+	if err := s.db.QueryRowContext(ctx, `select count(*) from memory_entries`).Scan(&entries); err != nil {
+		return fmt.Errorf("count entries: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, `select count(*) from memory_fts`).Scan(&indexed); err != nil {
+		return fmt.Errorf("count fts rows: %w", err)
+	}
+	if entries == indexed {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("backfill fts: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, `delete from memory_fts`); err != nil {
+		return fmt.Errorf("backfill fts: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `insert into memory_fts (namespace, entry_key, content) select namespace, entry_key, content from memory_entries`); err != nil {
+		return fmt.Errorf("backfill fts: %w", err)
+	}
+	return tx.Commit()
 }
 
 // verifyOrRecordEmbedderMeta stores the embedder name + dimensions the
@@ -158,7 +195,7 @@ insert into memory_metadata(k, v) values ('embedder_name', ?), ('embedder_dim',
 // a fresh HNSW. Called once on Open. Deterministic RNG so the same input
 // produces the same graph (reproducible tests).
 func (s *SQLiteStore) rebuildIndex(ctx context.Context) error {
-	rows, err := s.db.QueryContext(ctx, `select namespace, entry_key, content, meta_json, embedding from memory_entries`)
+	rows, err := s.db.QueryContext(ctx, `select namespace, entry_key, content, meta_json, embedding, updated_at from memory_entries`)
 	if err != nil {
 		return fmt.Errorf("rebuildIndex query: %w", err)
 	}
@@ -166,9 +203,9 @@ func (s *SQLiteStore) rebuildIndex(ctx context.Context) error {
 
 	nodes := make(map[string]indexNode)
 	for rows.Next() {
-		var ns, key, content, metaJSON string
+		var ns, key, content, metaJSON, updatedAt string
 		var embBlob []byte
-		if err := rows.Scan(&ns, &key, &content, &metaJSON, &embBlob); err != nil {
+		if err := rows.Scan(&ns, &key, &content, &metaJSON, &embBlob, &updatedAt); err != nil {
 			return fmt.Errorf("rebuildIndex scan: %w", err)
 		}
 		vec, err := decodeVector(embBlob)
@@ -189,6 +226,9 @@ func (s *SQLiteStore) rebuildIndex(ctx context.Context) error {
 			meta:    meta,
 			vec:     vec,
 		}
+		// Unparseable timestamps (hand-edited rows) leave updated zero,
+		// which only a since/until filter notices.
+		n.updated, _ = time.Parse(time.RFC3339Nano, updatedAt)
 		nodes[n.compKey] = n
 	}
 	if err := rows.Err(); err != nil {
@@ -208,11 +248,23 @@ func (s *SQLiteStore) Upsert(ctx context.Context, ns, key, content string, meta
 		return ErrInvalid{Reason: "namespace and key must not contain ASCII record separator (U+001E)"}
 	}
 
-	vec, err := s.embedder.Embed(ctx, content)
-	if err != nil {
+	// Re-upserting unchanged content (a chunk whose offsets moved, say)
+	// reuses the stored vector instead of paying for another embedding.
+	s.mu.Lock()
+	prev, had := s.nodes[compositeKey(ns, key)]
+	s.mu.Unlock()
+	var (
+		vec []float32
+		err error
+	)
+	if had && prev.content == content {
+		vec = prev.vec
+	} else if vec, err = s.embedder.Embed(ctx, content); errors.Is(err, ErrNoEmbedding) {
+		vec = nil // kept for lexical search only
+	} else if err != nil {
 		return fmt.Errorf("embed: %w", err)
 	}
-	if len(vec) != s.embedder.Dimensions() {
+	if vec != nil && len(vec) != s.embedder.Dimensions() {
 		return fmt.Errorf("embedder returned vector of length %d, expected %d", len(vec), s.embedder.Dimensions())
 	}
 	embBlob := encodeVector(vec)
@@ -224,13 +276,20 @@ func (s *SQLiteStore) Upsert(ctx context.Context, ns, key, content string, meta
 		}
 		metaJSON = string(b)
 	}
-	now := time.Now().UTC().Format(time.RFC3339Nano)
+	updated := time.Now().UTC()
+	now := updated.Format(time.RFC3339Nano)
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Upsert via ON CONFLICT to preserve created_at on updates.
-	_, err = s.db.ExecContext(ctx, `
+	// Upsert via ON CONFLICT to preserve created_at on updates; the FTS
+	// row is replaced in the same transaction so the two never drift.
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("upsert sqlite: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	_, err = tx.ExecContext(ctx, `
 insert into memory_entries (namespace, entry_key, content, meta_json, embedding, created_at, updated_at)
 values (?, ?, ?, ?, ?, ?, ?)
 on conflict (namespace, entry_key) do update set
@@ -242,6 +301,15 @@ on conflict (namespace, entry_key) do update set
 	if err != nil {
 		return fmt.Errorf("upsert sqlite: %w", err)
 	}
+	if _, err := tx.ExecContext(ctx, `delete from memory_fts where namespace = ? and entry_key = ?`, ns, key); err != nil {
+		return fmt.Errorf("upsert fts: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `insert into memory_fts (namespace, entry_key, content) values (?, ?, ?)`, ns, key, content); err != nil {
+		return fmt.Errorf("upsert fts: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("upsert sqlite: %w", err)
+	}
 
 	// Replace the authoritative entry in the in-memory map, then rebuild
 	// the HNSW index from it. coder/hnsw v0.2.0 has no Delete or Update
@@ -258,6 +326,7 @@ on conflict (namespace, entry_key) do update set
 		content: content,
 		meta:    meta,
 		vec:     vec,
+		updated: updated,
 	}
 	s.nodes[n.compKey] = n
 	s.rebuildIndexFromNodesLocked()
@@ -265,7 +334,8 @@ on conflict (namespace, entry_key) do update set
 }
 
 // rebuildIndexFromNodesLocked reconstructs the HNSW index from the
-// current in-memory map. Caller must hold s.mu.
+// current in-memory map, leaving out records stored without a vector.
+// Caller must hold s.mu.
 func (s *SQLiteStore) rebuildIndexFromNodesLocked() {
 	g := hnsw.NewGraph[indexNode]()
 	g.M = defaultHnswM
@@ -274,90 +344,225 @@ func (s *SQLiteStore) rebuildIndexFromNodesLocked() {
 	if len(s.nodes) > 0 {
 		batch := make([]indexNode, 0, len(s.nodes))
 		for _, n := range s.nodes {
-			batch = append(batch, n)
+			if len(n.vec) > 0 {
+				batch = append(batch, n)
+			}
+		}
+		if len(batch) > 0 {
+			g.Add(batch...)
 		}
-		g.Add(batch...)
 	}
 	s.index = g
 }
 
-// Search implements Store.
+// Search implements Store. It ranks by vector similarity only; use
+// SearchWithMode for lexical or hybrid ranking.
 func (s *SQLiteStore) Search(ctx context.Context, ns, query string, k int) ([]Hit, error) {
+	return s.SearchWithMode(ctx, ns, query, k, ModeVector)
+}
+
+// DefaultSearchMode is hybrid when a real embedder is configured and
+// lexical under the FakeEmbedder, whose vectors carry no meaning.
+func (s *SQLiteStore) DefaultSearchMode() SearchMode {
+	if _, fake := s.embedder.(*FakeEmbedder); fake {
+		return ModeLexical
+	}
+	return ModeHybrid
+}
+
+// SearchWithMode is Search within one namespace with an explicit ranking
+// mode; an empty mode means DefaultSearchMode().
+func (s *SQLiteStore) SearchWithMode(ctx context.Context, ns, query string, k int, mode SearchMode) ([]Hit, error) {
 	if ns == "" {
 		return nil, ErrInvalid{Reason: "namespace must not be empty"}
 	}
+	return s.search(ctx, query, k, mode, 0, func(n indexNode) bool { return n.ns == ns })
+}
+
+// SearchMulti implements Store.
+func (s *SQLiteStore) SearchMulti(ctx context.Context, q Query) ([]Hit, error) {
+	if err := q.validate(); err != nil {
+		return nil, err
+	}
+	return s.search(ctx, q.Text, q.K, q.Mode, q.MinScore, func(n indexNode) bool {
+		return q.matches(n.ns, n.meta, n.updated)
+	})
+}
+
+// search ranks the records passing keep. Filtering happens first, over
+// s.nodes, so the rankers only ever see candidates: a small namespace or a
+// narrow filter is never crowded out of an over-fetch by larger ones.
+// Hybrid over-fetches from both rankers (4k, at least 20) so a record
+// ranked moderately by both can still fuse into the top k.
+func (s *SQLiteStore) search(ctx context.Context, query string, k int, mode SearchMode, minScore float32, keep func(indexNode) bool) ([]Hit, error) {
 	if k <= 0 {
 		k = 5
 	}
-
-	vec, err := s.embedder.Embed(ctx, query)
+	mode, err := ParseSearchMode(string(mode))
 	if err != nil {
-		return nil, fmt.Errorf("embed query: %w", err)
+		return nil, err
+	}
+	if mode == "" {
+		mode = s.DefaultSearchMode()
+	}
+
+	var vec []float32
+	if mode != ModeLexical {
+		if vec, err = s.embedder.Embed(ctx, query); errors.Is(err, ErrNoEmbedding) {
+			vec = nil // nothing to rank by: vector hits are empty
+		} else if err != nil {
+			return nil, fmt.Errorf("embed query: %w", err)
+		}
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// HNSW has no per-namespace filter; we over-fetch and filter in Go.
-	// `k * 10` is a heuristic: enough to reliably surface namespace hits
-	// without over-walking the graph. If we still end up short, we fall
-	// back to scanning `nodes` directly — correctness first, latency
-	// second.
-	want := k * 10
-	if want < 50 {
-		want = 50
+	candidates := make(map[string]indexNode)
+	for compKey, n := range s.nodes {
+		if keep(n) {
+			candidates[compKey] = n
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
 	}
-	graphHits := s.index.Search(vec, want)
 
-	seen := map[string]struct{}{}
-	out := make([]Hit, 0, k)
-	for _, h := range graphHits {
-		compKey := h.ID()
-		if _, dup := seen[compKey]; dup {
-			continue
+	switch mode {
+	case ModeVector:
+		return dropBelow(s.searchVectorLocked(candidates, vec, k), minScore), nil
+	case ModeLexical:
+		hits, err := s.searchLexicalLocked(ctx, candidates, query, k)
+		if err != nil {
+			return nil, err
+		}
+		return dropBelow(hits, minScore), nil
+	default:
+		depth := k * 4
+		if depth < 20 {
+			depth = 20
+		}
+		lexical, err := s.searchLexicalLocked(ctx, candidates, query, depth)
+		if err != nil {
+			return nil, err
+		}
+		// The floor is on each ranker's own score: a fused score only says
+		// where a hit ranked, not how well it matched.
+		return fuseRRF(k, dropBelow(s.searchVectorLocked(candidates, vec, depth), minScore), dropBelow(lexical, minScore)), nil
+	}
+}
+
+// dropBelow filters hits scoring under minScore, in place.
+func dropBelow(hits []Hit, minScore float32) []Hit {
+	if minScore <= 0 {
+		return hits
+	}
+	kept := hits[:0]
+	for _, h := range hits {
+		if h.Score >= minScore {
+			kept = append(kept, h)
 		}
-		node, ok := s.nodes[compKey]
+	}
+	return kept
+}
+
+// searchLexicalLocked returns up to k candidates ranked by BM25. The FTS
+// query is narrowed to the candidates' namespaces and read in rank order
+// until k candidates are found. Caller must hold s.mu; content and meta
+// come from s.nodes like the vector path.
+func (s *SQLiteStore) searchLexicalLocked(ctx context.Context, candidates map[string]indexNode, query string, k int) ([]Hit, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+	namespaces := map[string]bool{}
+	args := []any{match}
+	for _, n := range candidates {
+		if !namespaces[n.ns] {
+			namespaces[n.ns] = true
+			args = append(args, n.ns)
+		}
+	}
+	rows, err := s.db.QueryContext(ctx, `
+select namespace, entry_key, bm25(memory_fts) from memory_fts
+where memory_fts match ? and namespace in (?`+strings.Repeat(", ?", len(namespaces)-1)+`)
+order by bm25(memory_fts), namespace, entry_key
+`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("lexical search: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Hit
+	for len(out) < k && rows.Next() {
+		var ns, key string
+		var rank float64
+		if err := rows.Scan(&ns, &key, &rank); err != nil {
+			return nil, fmt.Errorf("lexical search scan: %w", err)
+		}
+		node, ok := candidates[compositeKey(ns, key)]
 		if !ok {
-			// Orphan from a stale Upsert — skip.
 			continue
 		}
-		if node.ns != ns {
-			continue
+		out = append(out, node.hit(bm25Score(rank)))
+	}
+	return out, rows.Err()
+}
+
+// bruteForceMax is the candidate count up to which vector search scores
+// every candidate exactly instead of walking the HNSW graph. A variable so
+// tests can reach the graph path without thousands of records.
+var bruteForceMax = 2000
+
+// searchVectorLocked returns up to k candidates nearest to vec. A candidate
+// set that is small, or a small share of the store, is scored exactly;
+// otherwise the HNSW graph is walked with an over-fetch, keeping only
+// candidates, and topped up exactly if that comes up short. Caller must
+// hold s.mu.
+func (s *SQLiteStore) searchVectorLocked(candidates map[string]indexNode, vec []float32, k int) []Hit {
+	if len(vec) == 0 {
+		return nil
+	}
+	out := make([]Hit, 0, k)
+	seen := map[string]struct{}{}
+	if len(candidates) > bruteForceMax && len(candidates)*4 > len(s.nodes) {
+		// HNSW has no filter; over-fetch and filter in Go. `k * 10` is a
+		// heuristic: enough to reliably surface candidates without
+		// over-walking the graph.
+		want := k * 10
+		if want < 50 {
+			want = 50
 		}
-		seen[compKey] = struct{}{}
-		out = append(out, Hit{
-			Namespace: node.ns,
-			Key:       node.key,
-			Score:     cosineSimilarity(vec, node.vec),
-			Content:   node.content,
-			Meta:      copyMeta(node.meta),
-		})
-		if len(out) >= k {
-			break
+		for _, h := range s.index.Search(vec, want) {
+			compKey := h.ID()
+			node, ok := candidates[compKey]
+			if _, dup := seen[compKey]; dup || !ok {
+				continue
+			}
+			seen[compKey] = struct{}{}
+			out = append(out, node.hit(cosineSimilarity(vec, node.vec)))
+			if len(out) >= k {
+				break
+			}
 		}
 	}
 
-	// Fallback: if the graph-walk missed enough namespace-matching
-	// candidates (small namespace drowned by larger ones), brute-force
-	// scan `nodes` to fill up to k.
+	// Exact scan: the whole answer for a small candidate set, or the
+	// top-up when the graph walk missed candidates.
 	if len(out) < k {
-		scored := make([]Hit, 0)
-		for compKey, node := range s.nodes {
-			if node.ns != ns {
-				continue
-			}
-			if _, already := seen[compKey]; already {
+		scored := make([]Hit, 0, len(candidates))
+		for compKey, node := range candidates {
+			if _, already := seen[compKey]; already || len(node.vec) == 0 {
 				continue
 			}
-			scored = append(scored, Hit{
-				Namespace: node.ns,
-				Key:       node.key,
-				Score:     cosineSimilarity(vec, node.vec),
-				Content:   node.content,
-				Meta:      copyMeta(node.meta),
-			})
+			scored = append(scored, node.hit(cosineSimilarity(vec, node.vec)))
 		}
-		sort.Slice(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+		sort.Slice(scored, func(i, j int) bool {
+			if scored[i].Score != scored[j].Score {
+				return scored[i].Score > scored[j].Score
+			}
+			return compositeKey(scored[i].Namespace, scored[i].Key) < compositeKey(scored[j].Namespace, scored[j].Key)
+		})
 		for _, h := range scored {
 			out = append(out, h)
 			if len(out) >= k {
@@ -366,10 +571,23 @@ func (s *SQLiteStore) Search(ctx context.Context, ns, query string, k int) ([]Hi
 		}
 	}
 
-	// Final sort so HNSW's greedy ordering and the fallback's exact
-	// ordering blend correctly (descending score).
-	sort.Slice(out, func(i, j int) bool { return out[i].Score > out[j].Score })
-	return out, nil
+	// Final sort so HNSW's greedy ordering and the exact ordering blend
+	// correctly (descending score).
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// hit builds a search result for the node. Meta is copied so callers
+// can't mutate the store's state through it.
+func (n indexNode) hit(score float32) Hit {
+	return Hit{
+		Namespace: n.ns,
+		Key:       n.key,
+		Score:     score,
+		Content:   n.content,
+		Meta:      copyMeta(n.meta),
+		Updated:   n.updated,
+	}
 }
 
 // Delete implements Store.
@@ -381,10 +599,20 @@ func (s *SQLiteStore) Delete(ctx context.Context, ns, key string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	_, err := s.db.ExecContext(ctx, `delete from memory_entries where namespace = ? and entry_key = ?`, ns, key)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("delete sqlite: %w", err)
 	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, `delete from memory_entries where namespace = ? and entry_key = ?`, ns, key); err != nil {
+		return fmt.Errorf("delete sqlite: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `delete from memory_fts where namespace = ? and entry_key = ?`, ns, key); err != nil {
+		return fmt.Errorf("delete fts: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("delete sqlite: %w", err)
+	}
 	delete(s.nodes, compositeKey(ns, key))
 	// Same rationale as Upsert: HNSW v0.2.0 has no Delete API, so the
 	// safe path is a full rebuild. Cheap at adb's scale.
@@ -392,6 +620,131 @@ func (s *SQLiteStore) Delete(ctx context.Context, ns, key string) error {
 	return nil
 }
 
+// ListMeta returns the metadata of every record in ns, keyed by record key.
+// Indexers use it to skip records whose content hash is unchanged and to
+// find stale ones.
+func (s *SQLiteStore) ListMeta(_ context.Context, ns string) (map[string]map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]map[string]string)
+	for _, n := range s.nodes {
+		if n.ns != ns {
+			continue
+		}
+		meta := copyMeta(n.meta)
+		if meta == nil {
+			meta = map[string]string{}
+		}
+		out[n.key] = meta
+	}
+	return out, nil
+}
+
+// ListUpdated returns when each record in ns was last upserted, keyed by
+// record key. Retention sweeps use it to find expired records.
+func (s *SQLiteStore) ListUpdated(_ context.Context, ns string) (map[string]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]time.Time)
+	for _, n := range s.nodes {
+		if n.ns == ns {
+			out[n.key] = n.updated
+		}
+	}
+	return out, nil
+}
+
+// DeleteNamespace removes every record in ns and reports how many there
+// were. An empty or missing namespace is a no-op.
+func (s *SQLiteStore) DeleteNamespace(ctx context.Context, ns string) (int, error) {
+	if ns == "" {
+		return 0, ErrInvalid{Reason: "namespace must not be empty"}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("delete namespace: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	res, err := tx.ExecContext(ctx, `delete from memory_entries where namespace = ?`, ns)
+	if err != nil {
+		return 0, fmt.Errorf("delete namespace: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `delete from memory_fts where namespace = ?`, ns); err != nil {
+		return 0, fmt.Errorf("delete namespace fts: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("delete namespace: %w", err)
+	}
+	removed, _ := res.RowsAffected()
+	for compKey, n := range s.nodes {
+		if n.ns == ns {
+			delete(s.nodes, compKey)
+		}
+	}
+	if removed > 0 {
+		s.rebuildIndexFromNodesLocked()
+	}
+	return int(removed), nil
+}
+
+// RenameNamespace moves every record in from to to, keeping content,
+// embeddings and timestamps, and reports how many moved. A record already
+// at the same key in to is replaced. Nothing is re-embedded.
+func (s *SQLiteStore) RenameNamespace(ctx context.Context, from, to string) (int, error) {
+	if from == "" || to == "" {
+		return 0, ErrInvalid{Reason: "namespace must not be empty"}
+	}
+	if strings.Contains(to, compositeKeySep) {
+		return 0, ErrInvalid{Reason: "namespace must not contain ASCII record separator (U+001E)"}
+	}
+	if from == to {
+		return 0, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("rename namespace: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, stmt := range []string{
+		`delete from memory_entries where namespace = ? and entry_key in (select entry_key from memory_entries where namespace = ?)`,
+		`delete from memory_fts where namespace = ? and entry_key in (select entry_key from memory_entries where namespace = ?)`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt, to, from); err != nil {
+			return 0, fmt.Errorf("rename namespace: %w", err)
+		}
+	}
+	res, err := tx.ExecContext(ctx, `update memory_entries set namespace = ? where namespace = ?`, to, from)
+	if err != nil {
+		return 0, fmt.Errorf("rename namespace: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `update memory_fts set namespace = ? where namespace = ?`, to, from); err != nil {
+		return 0, fmt.Errorf("rename namespace fts: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("rename namespace: %w", err)
+	}
+	moved, _ := res.RowsAffected()
+	for compKey, n := range s.nodes {
+		if n.ns != from {
+			continue
+		}
+		delete(s.nodes, compKey)
+		n.ns = to
+		n.compKey = compositeKey(to, n.key)
+		s.nodes[n.compKey] = n
+	}
+	if moved > 0 {
+		s.rebuildIndexFromNodesLocked()
+	}
+	return int(moved), nil
+}
+
 // ListNamespaces implements Store.
 func (s *SQLiteStore) ListNamespaces(ctx context.Context) ([]string, error) {
 	rows, err := s.db.QueryContext(ctx, `select distinct namespace from memory_entries order by namespace`)
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/valter-silva-au/ai-dev-brain/internal/core"
 	"github.com/valter-silva-au/ai-dev-brain/internal/memory"
 	"github.com/valter-silva-au/ai-dev-brain/internal/statedir"
 	"github.com/valter-silva-au/ai-dev-brain/pkg/models"
@@ -51,6 +53,60 @@ func (app *App) OpenMemoryStore(ctx context.Context) (store memory.Store, config
 	return s, true, nil
 }
 
+// NewMemoryLifecycle wraps an opened store in a core.MemoryLifecycle over
+// the workspace backlog, with the retention rules from the merged
+// hooks.memory config. `adb memory gc` and the task memory hook share it.
+func (app *App) NewMemoryLifecycle(store core.MemoryLifecycleStore) (*core.MemoryLifecycle, error) {
+	retention, err := core.ParseMemoryRetention(app.resolvedMemoryConfig().Retention)
+	if err != nil {
+		return nil, err
+	}
+	return core.NewMemoryLifecycle(store, app.BacklogManager, retention), nil
+}
+
+// memoryLifecycleHook is the core.TaskMemoryHook the TaskManager calls on
+// archive/unarchive/delete. It opens the store per transition (they are
+// rare) and is a no-op while the workspace has no knowledge base.
+type memoryLifecycleHook struct{ app *App }
+
+func (h memoryLifecycleHook) TaskArchived(taskID string) error {
+	return h.run(func(ctx context.Context, ml *core.MemoryLifecycle) (int, error) {
+		return ml.TaskArchived(ctx, taskID)
+	})
+}
+
+func (h memoryLifecycleHook) TaskUnarchived(taskID string) error {
+	return h.run(func(ctx context.Context, ml *core.MemoryLifecycle) (int, error) {
+		return ml.TaskUnarchived(ctx, taskID)
+	})
+}
+
+func (h memoryLifecycleHook) TaskDeleted(taskID string) error {
+	return h.run(func(ctx context.Context, ml *core.MemoryLifecycle) (int, error) {
+		return ml.TaskDeleted(ctx, taskID)
+	})
+}
+
+func (h memoryLifecycleHook) run(fn func(context.Context, *core.MemoryLifecycle) (int, error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	store, configured, err := h.app.OpenMemoryStore(ctx)
+	if err != nil || !configured {
+		return err
+	}
+	defer store.Close()
+	ls, ok := store.(core.MemoryLifecycleStore)
+	if !ok {
+		return nil
+	}
+	ml, err := h.app.NewMemoryLifecycle(ls)
+	if err != nil {
+		return err
+	}
+	_, err = fn(ctx, ml)
+	return err
+}
+
 // resolvedMemoryConfig returns the Memory hook block resolved across all three
 // config tiers (Repo > Org > Global) via MergedConfig.ResolvedHooks, so an org
 // tier that opts into vector memory is honoured here too.
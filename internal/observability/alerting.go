@@ -30,19 +30,42 @@ type Alert struct {
 	Severity  AlertSeverity          `json:"severity"`
 	Message   string                 `json:"message"`
 	TaskID    string                 `json:"task_id,omitempty"`
+	Rule      string                 `json:"rule,omitempty"` // config rule that fired; empty for the built-in defaults
 	Timestamp time.Time              `json:"timestamp"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 }
 
-// AlertThreshold represents a threshold configuration for an alert
+// Key is the alert's identity for notification dedupe: the alert type plus
+// the task it concerns. Workspace-level alerts such as backlog_too_large have
+// no task and key on the type, plus the rule name when a config rule fired
+// (several scoped backlog rules can fire at once). It deliberately excludes
+// the message and timestamp, which change on every evaluation.
+func (a Alert) Key() string {
+	if a.TaskID != "" {
+		return string(a.Type) + ":" + a.TaskID
+	}
+	if a.Rule != "" && a.Rule != string(a.Type) {
+		return string(a.Type) + ":" + a.Rule
+	}
+	return string(a.Type)
+}
+
+// AlertThreshold represents a threshold configuration for an alert — one
+// alert rule. The built-in defaults are unnamed and unscoped; rules declared
+// in .taskconfig carry a Name and may scope on priority, tag or repo.
 type AlertThreshold struct {
-	Type     AlertType     `json:"type"`
-	Severity AlertSeverity `json:"severity"`
-	Duration time.Duration `json:"duration,omitempty"` // for time-based thresholds
-	Count    int           `json:"count,omitempty"`    // for count-based thresholds
+	Name       string        `json:"name,omitempty"`
+	Type       AlertType     `json:"type"`
+	Severity   AlertSeverity `json:"severity"`
+	Duration   time.Duration `json:"duration,omitempty"` // for time-based thresholds
+	Count      int           `json:"count,omitempty"`    // for count-based thresholds
+	Priorities []string      `json:"priorities,omitempty"`
+	Tags       []string      `json:"tags,omitempty"`
+	Repos      []string      `json:"repos,omitempty"`
 }
 
-// AlertConfig holds all alert threshold configurations
+// AlertConfig holds all alert threshold configurations, in match order
+// (first matching threshold per type and task wins).
 type AlertConfig struct {
 	Thresholds []AlertThreshold
 }
@@ -101,58 +124,87 @@ func (ac *AlertConfig) SetThreshold(threshold AlertThreshold) {
 type AlertEvaluator struct {
 	config      *AlertConfig
 	metricsCalc *MetricsCalculator
+	tasks       TaskSource
+	inspect     WorktreeInspector
 }
 
 // NewAlertEvaluator creates a new alert evaluator
-func NewAlertEvaluator(config *AlertConfig, metricsCalc *MetricsCalculator) *AlertEvaluator {
+func NewAlertEvaluator(config *AlertConfig, metricsCalc *MetricsCalculator, opts ...AlertEvaluatorOption) *AlertEvaluator {
 	if config == nil {
 		config = DefaultAlertConfig()
 	}
-	return &AlertEvaluator{
+	ae := &AlertEvaluator{
 		config:      config,
 		metricsCalc: metricsCalc,
 	}
+	for _, opt := range opts {
+		opt(ae)
+	}
+	return ae
+}
+
+// Config returns the rules the evaluator runs.
+func (ae *AlertEvaluator) Config() *AlertConfig {
+	return ae.config
 }
 
 // EvaluateAll evaluates all alert conditions and returns triggered alerts
 func (ae *AlertEvaluator) EvaluateAll() ([]Alert, error) {
 	var alerts []Alert
 
+	tasks, err := ae.loadAlertTasks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks for alert rules: %w", err)
+	}
+
 	// Check task_blocked_too_long
-	blockedAlerts, err := ae.evaluateBlockedTooLong()
+	blockedAlerts, err := ae.evaluateBlockedTooLong(tasks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate blocked tasks: %w", err)
 	}
 	alerts = append(alerts, blockedAlerts...)
 
 	// Check task_stale
-	staleAlerts, err := ae.evaluateTaskStale()
+	staleAlerts, err := ae.evaluateTaskStale(tasks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate stale tasks: %w", err)
 	}
 	alerts = append(alerts, staleAlerts...)
 
 	// Check review_too_long
-	reviewAlerts, err := ae.evaluateReviewTooLong()
+	reviewAlerts, err := ae.evaluateReviewTooLong(tasks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate review tasks: %w", err)
 	}
 	alerts = append(alerts, reviewAlerts...)
 
 	// Check backlog_too_large
-	backlogAlerts, err := ae.evaluateBacklogTooLarge()
+	backlogAlerts, err := ae.evaluateBacklogTooLarge(tasks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate backlog size: %w", err)
 	}
 	alerts = append(alerts, backlogAlerts...)
 
+	// Check worktree_dirty_untouched (config rules only)
+	worktreeAlerts, err := ae.evaluateWorktreeDirtyUntouched(tasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate worktrees: %w", err)
+	}
+	alerts = append(alerts, worktreeAlerts...)
+
+	// Check issue_sync_conflict_unresolved (config rules only)
+	conflictAlerts, err := ae.evaluateIssueSyncConflict(tasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate issue-sync conflicts: %w", err)
+	}
+	alerts = append(alerts, conflictAlerts...)
+
 	return alerts, nil
 }
 
 // evaluateBlockedTooLong checks for tasks blocked longer than threshold
-func (ae *AlertEvaluator) evaluateBlockedTooLong() ([]Alert, error) {
-	threshold := ae.config.GetThreshold(AlertTaskBlockedTooLong)
-	if threshold == nil {
+func (ae *AlertEvaluator) evaluateBlockedTooLong(tasks map[string]AlertTask) ([]Alert, error) {
+	if !ae.config.hasType(AlertTaskBlockedTooLong) {
 		return nil, nil
 	}
 
@@ -163,6 +215,11 @@ func (ae *AlertEvaluator) evaluateBlockedTooLong() ([]Alert, error) {
 	}
 
 	for _, taskID := range blockedTasks {
+		threshold := ae.config.Match(AlertTaskBlockedTooLong, taskFor(tasks, taskID))
+		if threshold == nil {
+			continue
+		}
+
 		duration, err := ae.metricsCalc.GetTaskDuration(taskID, "blocked")
 		if err != nil {
 			continue
@@ -174,6 +231,7 @@ func (ae *AlertEvaluator) evaluateBlockedTooLong() ([]Alert, error) {
 				Severity:  threshold.Severity,
 				Message:   fmt.Sprintf("Task %s has been blocked for %v (threshold: %v)", taskID, duration.Round(time.Hour), threshold.Duration),
 				TaskID:    taskID,
+				Rule:      threshold.Name,
 				Timestamp: time.Now().UTC(),
 				Metadata: map[string]interface{}{
 					"duration":  duration.String(),
@@ -187,9 +245,8 @@ func (ae *AlertEvaluator) evaluateBlockedTooLong() ([]Alert, error) {
 }
 
 // evaluateTaskStale checks for tasks in progress longer than threshold
-func (ae *AlertEvaluator) evaluateTaskStale() ([]Alert, error) {
-	threshold := ae.config.GetThreshold(AlertTaskStale)
-	if threshold == nil {
+func (ae *AlertEvaluator) evaluateTaskStale(tasks map[string]AlertTask) ([]Alert, error) {
+	if !ae.config.hasType(AlertTaskStale) {
 		return nil, nil
 	}
 
@@ -200,6 +257,11 @@ func (ae *AlertEvaluator) evaluateTaskStale() ([]Alert, error) {
 	}
 
 	for _, taskID := range inProgressTasks {
+		threshold := ae.config.Match(AlertTaskStale, taskFor(tasks, taskID))
+		if threshold == nil {
+			continue
+		}
+
 		duration, err := ae.metricsCalc.GetTaskDuration(taskID, "in_progress")
 		if err != nil {
 			continue
@@ -211,6 +273,7 @@ func (ae *AlertEvaluator) evaluateTaskStale() ([]Alert, error) {
 				Severity:  threshold.Severity,
 				Message:   fmt.Sprintf("Task %s has been in progress for %v (threshold: %v)", taskID, duration.Round(time.Hour), threshold.Duration),
 				TaskID:    taskID,
+				Rule:      threshold.Name,
 				Timestamp: time.Now().UTC(),
 				Metadata: map[string]interface{}{
 					"duration":  duration.String(),
@@ -224,9 +287,8 @@ func (ae *AlertEvaluator) evaluateTaskStale() ([]Alert, error) {
 }
 
 // evaluateReviewTooLong checks for tasks in review longer than threshold
-func (ae *AlertEvaluator) evaluateReviewTooLong() ([]Alert, error) {
-	threshold := ae.config.GetThreshold(AlertReviewTooLong)
-	if threshold == nil {
+func (ae *AlertEvaluator) evaluateReviewTooLong(tasks map[string]AlertTask) ([]Alert, error) {
+	if !ae.config.hasType(AlertReviewTooLong) {
 		return nil, nil
 	}
 
@@ -237,6 +299,11 @@ func (ae *AlertEvaluator) evaluateReviewTooLong() ([]Alert, error) {
 	}
 
 	for _, taskID := range reviewTasks {
+		threshold := ae.config.Match(AlertReviewTooLong, taskFor(tasks, taskID))
+		if threshold == nil {
+			continue
+		}
+
 		duration, err := ae.metricsCalc.GetTaskDuration(taskID, "review")
 		if err != nil {
 			continue
@@ -248,6 +315,7 @@ func (ae *AlertEvaluator) evaluateReviewTooLong() ([]Alert, error) {
 				Severity:  threshold.Severity,
 				Message:   fmt.Sprintf("Task %s has been in review for %v (threshold: %v)", taskID, duration.Round(time.Hour), threshold.Duration),
 				TaskID:    taskID,
+				Rule:      threshold.Name,
 				Timestamp: time.Now().UTC(),
 				Metadata: map[string]interface{}{
 					"duration":  duration.String(),
@@ -260,10 +328,12 @@ func (ae *AlertEvaluator) evaluateReviewTooLong() ([]Alert, error) {
 	return alerts, nil
 }
 
-// evaluateBacklogTooLarge checks if backlog exceeds threshold
-func (ae *AlertEvaluator) evaluateBacklogTooLarge() ([]Alert, error) {
-	threshold := ae.config.GetThreshold(AlertBacklogTooLarge)
-	if threshold == nil {
+// evaluateBacklogTooLarge checks if backlog exceeds threshold. Unlike the
+// per-task conditions, every backlog_too_large rule is evaluated on its own,
+// counting only the backlog tasks in its scope; the first unscoped rule wins,
+// so an unscoped config rule replaces the default.
+func (ae *AlertEvaluator) evaluateBacklogTooLarge(tasks map[string]AlertTask) ([]Alert, error) {
+	if !ae.config.hasType(AlertBacklogTooLarge) {
 		return nil, nil
 	}
 
@@ -273,12 +343,37 @@ func (ae *AlertEvaluator) evaluateBacklogTooLarge() ([]Alert, error) {
 		return nil, err
 	}
 
-	backlogSize := len(backlogTasks)
-	if backlogSize > threshold.Count {
+	sawUnscoped := false
+	for i := range ae.config.Thresholds {
+		threshold := &ae.config.Thresholds[i]
+		if threshold.Type != AlertBacklogTooLarge {
+			continue
+		}
+		if !threshold.Scoped() {
+			if sawUnscoped {
+				continue
+			}
+			sawUnscoped = true
+		}
+
+		backlogSize := 0
+		for _, taskID := range backlogTasks {
+			if threshold.Matches(taskFor(tasks, taskID)) {
+				backlogSize++
+			}
+		}
+		if backlogSize <= threshold.Count {
+			continue
+		}
+		message := fmt.Sprintf("Backlog has %d tasks (threshold: %d)", backlogSize, threshold.Count)
+		if threshold.Scoped() {
+			message = fmt.Sprintf("Backlog has %d tasks in scope of rule %s (threshold: %d)", backlogSize, threshold.Name, threshold.Count)
+		}
 		alerts = append(alerts, Alert{
 			Type:      AlertBacklogTooLarge,
 			Severity:  threshold.Severity,
-			Message:   fmt.Sprintf("Backlog has %d tasks (threshold: %d)", backlogSize, threshold.Count),
+			Message:   message,
+			Rule:      threshold.Name,
 			Timestamp: time.Now().UTC(),
 			Metadata: map[string]interface{}{
 				"backlog_size": backlogSize,
@@ -0,0 +1,494 @@
+package observability
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Conditions beyond the four built-in defaults. They have no default rule —
+// they only fire when a workspace declares one under notifications.alerts.
+const (
+	// AlertWorktreeDirtyUntouched fires for a task whose worktree has
+	// uncommitted changes that nobody has touched for longer than the rule's
+	// threshold — work that is at risk of being forgotten. Needs a TaskSource
+	// and a WorktreeInspector.
+	AlertWorktreeDirtyUntouched AlertType = "worktree_dirty_untouched"
+
+	// AlertIssueSyncConflict fires for a task whose latest issue.conflict has
+	// not been followed by an issue.synced for longer than the threshold (an
+	// empty threshold fires on the first unresolved conflict).
+	AlertIssueSyncConflict AlertType = "issue_sync_conflict_unresolved"
+)
+
+// AlertCondition describes one condition an alert rule can name.
+type AlertCondition struct {
+	Type        AlertType
+	Count       bool // true: the rule takes a count; false: a duration threshold
+	Description string
+}
+
+// AlertConditions is the closed set of conditions AlertEvaluator knows how to
+// evaluate, in evaluation order.
+var AlertConditions = []AlertCondition{
+	{Type: AlertTaskBlockedTooLong, Description: "task blocked longer than threshold"},
+	{Type: AlertTaskStale, Description: "task in progress longer than threshold"},
+	{Type: AlertReviewTooLong, Description: "task in review longer than threshold"},
+	{Type: AlertBacklogTooLarge, Count: true, Description: "more than count tasks in backlog"},
+	{Type: AlertWorktreeDirtyUntouched, Description: "worktree dirty and untouched longer than threshold"},
+	{Type: AlertIssueSyncConflict, Description: "issue-sync conflict unresolved longer than threshold"},
+}
+
+// LookupAlertCondition returns the condition named t, or false.
+func LookupAlertCondition(t AlertType) (AlertCondition, bool) {
+	for _, c := range AlertConditions {
+		if c.Type == t {
+			return c, true
+		}
+	}
+	return AlertCondition{}, false
+}
+
+// AlertTask is the slice of a task alert rules scope on. It is filled in by
+// the caller's TaskSource so this package stays free of the task model.
+type AlertTask struct {
+	ID           string
+	Status       string
+	Priority     string
+	Repo         string
+	Tags         []string
+	WorktreePath string
+}
+
+// TaskSource lists the workspace's tasks for rule scoping and the worktree
+// condition. internal/app.go adapts the backlog to it.
+type TaskSource interface {
+	AlertTasks() ([]AlertTask, error)
+}
+
+// WorktreeInspector reports whether the worktree at path has uncommitted
+// changes and when those changes were last modified.
+type WorktreeInspector func(path string) (dirty bool, lastModified time.Time, err error)
+
+// AlertEvaluatorOption configures optional AlertEvaluator collaborators.
+type AlertEvaluatorOption func(*AlertEvaluator)
+
+// WithTaskSource lets rules scope on priority/tag/repo and enables
+// worktree_dirty_untouched. Without it only unscoped rules match.
+func WithTaskSource(src TaskSource) AlertEvaluatorOption {
+	return func(ae *AlertEvaluator) { ae.tasks = src }
+}
+
+// WithWorktreeInspector enables the worktree_dirty_untouched condition.
+func WithWorktreeInspector(fn WorktreeInspector) AlertEvaluatorOption {
+	return func(ae *AlertEvaluator) { ae.inspect = fn }
+}
+
+// RuleName is the threshold's display name: its Name, or the alert type for
+// the unnamed built-in defaults.
+func (t AlertThreshold) RuleName() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return string(t.Type)
+}
+
+// Scoped reports whether the threshold restricts which tasks it applies to.
+func (t AlertThreshold) Scoped() bool {
+	return len(t.Priorities) > 0 || len(t.Tags) > 0 || len(t.Repos) > 0
+}
+
+// Matches reports whether task falls inside the threshold's scope. Each
+// non-empty scope list must match (any-of within a list); Repos entries are
+// exact repo paths or path.Match globs such as "github.com/acme/*".
+func (t AlertThreshold) Matches(task AlertTask) bool {
+	if len(t.Priorities) > 0 && !containsFold(t.Priorities, task.Priority) {
+		return false
+	}
+	if len(t.Tags) > 0 {
+		hit := false
+		for _, tag := range task.Tags {
+			if containsFold(t.Tags, tag) {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return false
+		}
+	}
+	if len(t.Repos) > 0 {
+		hit := false
+		for _, pattern := range t.Repos {
+			if ok, _ := path.Match(pattern, task.Repo); ok || pattern == task.Repo {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns the first threshold of alertType whose scope contains task.
+// Config rules precede the defaults, so a scoped rule overrides the default
+// for the tasks it covers and the default still catches everything else.
+func (ac *AlertConfig) Match(alertType AlertType, task AlertTask) *AlertThreshold {
+	for i := range ac.Thresholds {
+		if ac.Thresholds[i].Type == alertType && ac.Thresholds[i].Matches(task) {
+			return &ac.Thresholds[i]
+		}
+	}
+	return nil
+}
+
+// AlertRuleSpec is one alert rule as written in .taskconfig under
+// notifications.alerts.rules, before validation. Threshold is a duration
+// such as "4h", "5d" or "2w"; Severity is high, medium or low.
+type AlertRuleSpec struct {
+	Name       string
+	Condition  string
+	Severity   string
+	Threshold  string
+	Count      int
+	Priorities []string
+	Tags       []string
+	Repos      []string
+	Disabled   bool
+}
+
+// BuildAlertConfig validates specs and returns an AlertConfig holding the
+// enabled rules followed (unless withDefaults is false) by the four built-in
+// defaults. Every problem is reported, joined, so `adb alerts rules validate`
+// can list them all at once.
+func BuildAlertConfig(specs []AlertRuleSpec, withDefaults bool) (*AlertConfig, error) {
+	cfg := &AlertConfig{}
+	var errs []error
+	seen := make(map[string]bool, len(specs))
+	for i, spec := range specs {
+		th, err := parseAlertRule(spec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %d (%s): %w", i+1, ruleLabel(spec), err))
+			continue
+		}
+		if seen[th.Name] {
+			errs = append(errs, fmt.Errorf("rule %d (%s): duplicate rule name", i+1, th.Name))
+			continue
+		}
+		seen[th.Name] = true
+		if !spec.Disabled {
+			cfg.Thresholds = append(cfg.Thresholds, th)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if withDefaults {
+		cfg.Thresholds = append(cfg.Thresholds, DefaultAlertConfig().Thresholds...)
+	}
+	return cfg, nil
+}
+
+func ruleLabel(spec AlertRuleSpec) string {
+	if spec.Name != "" {
+		return spec.Name
+	}
+	return "unnamed"
+}
+
+func parseAlertRule(spec AlertRuleSpec) (AlertThreshold, error) {
+	name := strings.TrimSpace(spec.Name)
+	if name == "" {
+		return AlertThreshold{}, fmt.Errorf("name is required")
+	}
+	cond, ok := LookupAlertCondition(AlertType(strings.TrimSpace(spec.Condition)))
+	if !ok {
+		names := make([]string, len(AlertConditions))
+		for i, c := range AlertConditions {
+			names[i] = string(c.Type)
+		}
+		return AlertThreshold{}, fmt.Errorf("unknown condition %q (valid: %s)", spec.Condition, strings.Join(names, ", "))
+	}
+	severity, err := parseAlertSeverity(spec.Severity)
+	if err != nil {
+		return AlertThreshold{}, err
+	}
+	th := AlertThreshold{
+		Name:       name,
+		Type:       cond.Type,
+		Severity:   severity,
+		Priorities: spec.Priorities,
+		Tags:       spec.Tags,
+		Repos:      spec.Repos,
+	}
+	for _, p := range spec.Priorities {
+		switch strings.ToUpper(p) {
+		case "P0", "P1", "P2", "P3":
+		default:
+			return AlertThreshold{}, fmt.Errorf("invalid priority %q (valid: P0, P1, P2, P3)", p)
+		}
+	}
+	for _, pattern := range spec.Repos {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return AlertThreshold{}, fmt.Errorf("invalid repo pattern %q: %w", pattern, err)
+		}
+	}
+	if cond.Count {
+		if spec.Threshold != "" {
+			return AlertThreshold{}, fmt.Errorf("%s takes count, not threshold", cond.Type)
+		}
+		if spec.Count <= 0 {
+			return AlertThreshold{}, fmt.Errorf("count must be positive")
+		}
+		th.Count = spec.Count
+		return th, nil
+	}
+	if spec.Count != 0 {
+		return AlertThreshold{}, fmt.Errorf("%s takes threshold, not count", cond.Type)
+	}
+	if spec.Threshold == "" {
+		if cond.Type != AlertIssueSyncConflict {
+			return AlertThreshold{}, fmt.Errorf("threshold is required")
+		}
+		return th, nil
+	}
+	d, err := ParseAlertDuration(spec.Threshold)
+	if err != nil {
+		return AlertThreshold{}, err
+	}
+	th.Duration = d
+	return th, nil
+}
+
+func parseAlertSeverity(s string) (AlertSeverity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "high":
+		return AlertSeverityHigh, nil
+	case "medium", "":
+		return AlertSeverityMedium, nil
+	case "low":
+		return AlertSeverityLow, nil
+	}
+	return "", fmt.Errorf("invalid severity %q (valid: high, medium, low)", s)
+}
+
+// ParseAlertDuration parses a rule threshold: a Go duration ("4h", "90m") or
+// a whole number of days or weeks ("5d", "2w").
+func ParseAlertDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid threshold %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid threshold %q", s)
+	}
+	return d, nil
+}
+
+// FormatAlertDuration renders d the way rules are written: whole days as
+// "5d", anything else as a Go duration.
+func FormatAlertDuration(d time.Duration) string {
+	day := 24 * time.Hour
+	if d >= day && d%day == 0 {
+		return strconv.Itoa(int(d/day)) + "d"
+	}
+	return d.String()
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadAlertTasks indexes the TaskSource by ID; nil when there is no source.
+func (ae *AlertEvaluator) loadAlertTasks() (map[string]AlertTask, error) {
+	if ae.tasks == nil {
+		return nil, nil
+	}
+	list, err := ae.tasks.AlertTasks()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]AlertTask, len(list))
+	for _, t := range list {
+		byID[t.ID] = t
+	}
+	return byID, nil
+}
+
+// taskFor returns the AlertTask for id, or a bare one carrying only the ID
+// (which only unscoped rules match) when the source doesn't know it.
+func taskFor(tasks map[string]AlertTask, id string) AlertTask {
+	if t, ok := tasks[id]; ok {
+		return t
+	}
+	return AlertTask{ID: id}
+}
+
+// evaluateWorktreeDirtyUntouched checks live tasks whose worktree has
+// uncommitted changes older than the matching rule's threshold.
+func (ae *AlertEvaluator) evaluateWorktreeDirtyUntouched(tasks map[string]AlertTask) ([]Alert, error) {
+	if ae.tasks == nil || ae.inspect == nil || !ae.config.hasType(AlertWorktreeDirtyUntouched) {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(tasks))
+	for id := range tasks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var alerts []Alert
+	for _, id := range ids {
+		task := tasks[id]
+		if task.WorktreePath == "" || task.Status == "done" || task.Status == "archived" {
+			continue
+		}
+		threshold := ae.config.Match(AlertWorktreeDirtyUntouched, task)
+		if threshold == nil {
+			continue
+		}
+		dirty, modified, err := ae.inspect(task.WorktreePath)
+		if err != nil || !dirty || modified.IsZero() {
+			continue
+		}
+		idle := time.Since(modified)
+		if idle > threshold.Duration {
+			alerts = append(alerts, Alert{
+				Type:      AlertWorktreeDirtyUntouched,
+				Severity:  threshold.Severity,
+				Message:   fmt.Sprintf("Task %s has uncommitted changes untouched for %v (threshold: %v)", id, idle.Round(time.Hour), threshold.Duration),
+				TaskID:    id,
+				Rule:      threshold.Name,
+				Timestamp: time.Now().UTC(),
+				Metadata: map[string]interface{}{
+					"worktree":  task.WorktreePath,
+					"duration":  idle.String(),
+					"threshold": threshold.Duration.String(),
+				},
+			})
+		}
+	}
+	return alerts, nil
+}
+
+// issueConflict is a task's oldest issue.conflict not yet superseded.
+type issueConflict struct {
+	at     time.Time
+	repo   string
+	reason string
+}
+
+// openIssueConflicts maps task ID to its unresolved issue-sync conflict: the
+// first issue.conflict since the task's last issue.synced (or archive or
+// delete, which retire it).
+func (mc *MetricsCalculator) openIssueConflicts() (map[string]issueConflict, error) {
+	events, err := mc.eventLog.ReadRange(time.Time{}, time.Time{},
+		EventIssueConflict, EventIssueSynced, EventTaskArchived, EventTaskDeleted)
+	if err != nil {
+		return nil, err
+	}
+	open := make(map[string]issueConflict)
+	for _, e := range events {
+		taskID, _ := e.Data["task_id"].(string)
+		if taskID == "" {
+			continue
+		}
+		switch e.Type {
+		case EventIssueConflict:
+			if _, already := open[taskID]; already {
+				continue // keep the first unresolved conflict's age
+			}
+			repo, _ := e.Data["repo"].(string)
+			reason, _ := e.Data["reason"].(string)
+			if reason == "" {
+				reason, _ = e.Data["error"].(string)
+			}
+			open[taskID] = issueConflict{at: e.Timestamp, repo: repo, reason: reason}
+		case EventIssueSynced, EventTaskArchived, EventTaskDeleted:
+			delete(open, taskID)
+		}
+	}
+	return open, nil
+}
+
+// evaluateIssueSyncConflict checks tasks whose latest issue.conflict has not
+// been superseded by an issue.synced.
+func (ae *AlertEvaluator) evaluateIssueSyncConflict(tasks map[string]AlertTask) ([]Alert, error) {
+	if !ae.config.hasType(AlertIssueSyncConflict) {
+		return nil, nil
+	}
+	open, err := ae.metricsCalc.openIssueConflicts()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(open))
+	for id := range open {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var alerts []Alert
+	for _, id := range ids {
+		c := open[id]
+		task := taskFor(tasks, id)
+		if task.Repo == "" {
+			task.Repo = c.repo
+		}
+		threshold := ae.config.Match(AlertIssueSyncConflict, task)
+		if threshold == nil {
+			continue
+		}
+		age := time.Since(c.at)
+		if age < threshold.Duration {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Type:      AlertIssueSyncConflict,
+			Severity:  threshold.Severity,
+			Message:   fmt.Sprintf("Task %s has an unresolved issue-sync conflict for %v", id, age.Round(time.Minute)),
+			TaskID:    id,
+			Rule:      threshold.Name,
+			Timestamp: time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"repo":      c.repo,
+				"reason":    c.reason,
+				"duration":  age.String(),
+				"threshold": threshold.Duration.String(),
+			},
+		})
+	}
+	return alerts, nil
+}
+
+// hasType reports whether any threshold targets alertType.
+func (ac *AlertConfig) hasType(alertType AlertType) bool {
+	for i := range ac.Thresholds {
+		if ac.Thresholds[i].Type == alertType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package observability
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubTaskSource []AlertTask
+
+func (s stubTaskSource) AlertTasks() ([]AlertTask, error) { return s, nil }
+
+func newRulesTestLog(t *testing.T) (*EventLog, *MetricsCalculator) {
+	t.Helper()
+	el := NewEventLog(filepath.Join(t.TempDir(), "events.jsonl"))
+	return el, NewMetricsCalculator(el)
+}
+
+func logBlocked(el *EventLog, taskID string) {
+	el.Log(EventTaskCreated, map[string]interface{}{"task_id": taskID, "status": "backlog"})
+	el.Log(EventTaskStatusChanged, map[string]interface{}{"task_id": taskID, "old_status": "backlog", "new_status": "blocked"})
+}
+
+func TestBuildAlertConfig_RulesPrecedeDefaults(t *testing.T) {
+	cfg, err := BuildAlertConfig([]AlertRuleSpec{
+		{Name: "p0-blocked", Condition: "task_blocked_too_long", Threshold: "4h", Severity: "high", Priorities: []string{"P0"}},
+		{Name: "off", Condition: "task_stale", Threshold: "1d", Disabled: true},
+	}, true)
+	if err != nil {
+		t.Fatalf("BuildAlertConfig: %v", err)
+	}
+	if len(cfg.Thresholds) != 5 {
+		t.Fatalf("got %d thresholds, want 1 rule + 4 defaults", len(cfg.Thresholds))
+	}
+	first := cfg.Thresholds[0]
+	if first.Name != "p0-blocked" || first.Duration != 4*time.Hour || first.Severity != AlertSeverityHigh {
+		t.Errorf("first threshold = %+v", first)
+	}
+
+	p0 := cfg.Match(AlertTaskBlockedTooLong, AlertTask{ID: "T", Priority: "P0"})
+	p2 := cfg.Match(AlertTaskBlockedTooLong, AlertTask{ID: "T", Priority: "P2"})
+	if p0.Name != "p0-blocked" || p2.Name != "" || p2.Duration != 24*time.Hour {
+		t.Errorf("P0 matched %q, P2 matched %q (%v)", p0.RuleName(), p2.RuleName(), p2.Duration)
+	}
+
+	cfg, err = BuildAlertConfig(nil, false)
+	if err != nil || len(cfg.Thresholds) != 0 {
+		t.Errorf("defaults disabled = %+v, %v", cfg, err)
+	}
+}
+
+func TestBuildAlertConfig_ReportsEveryError(t *testing.T) {
+	_, err := BuildAlertConfig([]AlertRuleSpec{
+		{Condition: "task_stale", Threshold: "1d"},
+		{Name: "a", Condition: "nope"},
+		{Name: "b", Condition: "task_stale", Threshold: "soon"},
+		{Name: "c", Condition: "backlog_too_large"},
+		{Name: "d", Condition: "task_stale", Threshold: "1d", Severity: "urgent"},
+		{Name: "e", Condition: "task_stale", Threshold: "1d", Priorities: []string{"P9"}},
+		{Name: "f", Condition: "task_stale", Threshold: "1d"},
+		{Name: "f", Condition: "task_stale", Threshold: "2d"},
+	}, true)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{"name is required", "unknown condition", "invalid threshold", "count must be positive", "invalid severity", "invalid priority", "duplicate rule name"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestParseAlertDuration(t *testing.T) {
+	cases := map[string]time.Duration{"4h": 4 * time.Hour, "5d": 120 * time.Hour, "2w": 14 * 24 * time.Hour, "90m": 90 * time.Minute}
+	for in, want := range cases {
+		got, err := ParseAlertDuration(in)
+		if err != nil || got != want {
+			t.Errorf("ParseAlertDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseAlertDuration("xd"); err == nil {
+		t.Error("expected error for xd")
+	}
+	if FormatAlertDuration(120*time.Hour) != "5d" || FormatAlertDuration(4*time.Hour) != "4h0m0s" {
+		t.Error("FormatAlertDuration mismatch")
+	}
+}
+
+func TestAlertThreshold_Matches(t *testing.T) {
+	th := AlertThreshold{Tags: []string{"customer"}, Repos: []string{"github.com/acme/*"}}
+	if !th.Matches(AlertTask{Tags: []string{"Customer"}, Repo: "github.com/acme/api"}) {
+		t.Error("expected tag (case-insensitive) + repo glob match")
+	}
+	if th.Matches(AlertTask{Tags: []string{"customer"}, Repo: "github.com/other/api"}) {
+		t.Error("repo outside the glob must not match")
+	}
+	if th.Matches(AlertTask{Repo: "github.com/acme/api"}) {
+		t.Error("missing tag must not match")
+	}
+}
+
+func TestAlertEvaluator_PerPriorityThresholds(t *testing.T) {
+	el, mc := newRulesTestLog(t)
+	logBlocked(el, "TASK-P0")
+	logBlocked(el, "TASK-P3")
+	time.Sleep(60 * time.Millisecond)
+
+	cfg, err := BuildAlertConfig([]AlertRuleSpec{
+		{Name: "p0-blocked", Condition: "task_blocked_too_long", Threshold: "10ms", Severity: "high", Priorities: []string{"P0"}},
+		{Name: "p3-blocked", Condition: "task_blocked_too_long", Threshold: "5d", Severity: "low", Priorities: []string{"P3"}},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ae := NewAlertEvaluator(cfg, mc, WithTaskSource(stubTaskSource{
+		{ID: "TASK-P0", Priority: "P0"},
+		{ID: "TASK-P3", Priority: "P3"},
+	}))
+	alerts, err := ae.EvaluateAll()
+	if err != nil {
+		t.Fatalf("EvaluateAll: %v", err)
+	}
+	// P0 trips its 10ms rule; P3 is claimed by its 5d rule and so does not
+	// fall through to the 24h default.
+	if len(alerts) != 1 || alerts[0].TaskID != "TASK-P0" || alerts[0].Rule != "p0-blocked" || alerts[0].Severity != AlertSeverityHigh {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+}
+
+func TestAlertEvaluator_ScopedBacklogRule(t *testing.T) {
+	el, mc := newRulesTestLog(t)
+	for _, id := range []string{"A", "B", "C"} {
+		el.Log(EventTaskCreated, map[string]interface{}{"task_id": id, "status": "backlog"})
+	}
+	cfg, err := BuildAlertConfig([]AlertRuleSpec{
+		{Name: "api-backlog", Condition: "backlog_too_large", Count: 1, Repos: []string{"github.com/acme/api"}},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ae := NewAlertEvaluator(cfg, mc, WithTaskSource(stubTaskSource{
+		{ID: "A", Repo: "github.com/acme/api"},
+		{ID: "B", Repo: "github.com/acme/api"},
+		{ID: "C", Repo: "github.com/acme/web"},
+	}))
+	alerts, err := ae.EvaluateAll()
+	if err != nil {
+		t.Fatalf("EvaluateAll: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Metadata["backlog_size"] != 2 {
+		t.Fatalf("alerts = %+v, want one scoped backlog alert of size 2", alerts)
+	}
+	if alerts[0].Key() != "backlog_too_large:api-backlog" {
+		t.Errorf("Key() = %q", alerts[0].Key())
+	}
+}
+
+func TestAlertEvaluator_WorktreeDirtyUntouched(t *testing.T) {
+	_, mc := newRulesTestLog(t)
+	cfg, err := BuildAlertConfig([]AlertRuleSpec{
+		{Name: "dirty", Condition: "worktree_dirty_untouched", Threshold: "2d"},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modified := map[string]time.Time{
+		"/wt/old":   time.Now().Add(-3 * 24 * time.Hour),
+		"/wt/fresh": time.Now().Add(-time.Hour),
+	}
+	ae := NewAlertEvaluator(cfg, mc,
+		WithTaskSource(stubTaskSource{
+			{ID: "OLD", Status: "in_progress", WorktreePath: "/wt/old"},
+			{ID: "FRESH", Status: "in_progress", WorktreePath: "/wt/fresh"},
+			{ID: "DONE", Status: "done", WorktreePath: "/wt/old"},
+		}),
+		WithWorktreeInspector(func(path string) (bool, time.Time, error) {
+			return true, modified[path], nil
+		}))
+	alerts, err := ae.EvaluateAll()
+	if err != nil {
+		t.Fatalf("EvaluateAll: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].TaskID != "OLD" || alerts[0].Type != AlertWorktreeDirtyUntouched {
+		t.Fatalf("alerts = %+v, want only OLD", alerts)
+	}
+}
+
+func TestAlertEvaluator_IssueSyncConflictUnresolved(t *testing.T) {
+	el, mc := newRulesTestLog(t)
+	el.Log(EventIssueConflict, map[string]interface{}{"task_id": "T1", "repo": "github.com/acme/api", "reason": "both changed"})
+	el.Log(EventIssueConflict, map[string]interface{}{"task_id": "T2", "repo": "github.com/acme/api"})
+	el.Log(EventIssueSynced, map[string]interface{}{"task_id": "T2", "repo": "github.com/acme/api"})
+
+	cfg, err := BuildAlertConfig([]AlertRuleSpec{
+		{Name: "conflicts", Condition: "issue_sync_conflict_unresolved", Severity: "high", Repos: []string{"github.com/acme/*"}},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alerts, err := NewAlertEvaluator(cfg, mc).EvaluateAll()
+	if err != nil {
+		t.Fatalf("EvaluateAll: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].TaskID != "T1" || alerts[0].Metadata["reason"] != "both changed" {
+		t.Fatalf("alerts = %+v, want only T1 (repo scope falls back to the event's repo)", alerts)
+	}
+}
@@ -0,0 +1,384 @@
+package observability
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/lockfile"
+	"gopkg.in/yaml.v3"
+)
+
+// Alert lifecycle events, emitted by AlertTracker.Track when an alert starts
+// firing and when it stops. Payload:
+//
+//	alert.fired     id, key, type, severity, message, task_id?, rule?
+//	alert.resolved  id, key, type, severity, fired_at, duration, task_id?, rule?
+const (
+	EventAlertFired    EventType = "alert.fired"
+	EventAlertResolved EventType = "alert.resolved"
+)
+
+// AlertState is where an alert is in its lifecycle.
+type AlertState string
+
+const (
+	AlertStateFiring       AlertState = "firing"
+	AlertStateAcknowledged AlertState = "acknowledged"
+	AlertStateSnoozed      AlertState = "snoozed"
+	AlertStateResolved     AlertState = "resolved"
+)
+
+// resolvedRetention bounds how long a resolved record stays in the state
+// file; the full timeline lives on in the event log.
+const resolvedRetention = 30 * 24 * time.Hour
+
+// Fingerprint is the alert's stable short ID — a hash of Key(), so the same
+// condition on the same task keeps its ID across evaluations and restarts.
+// It is what `adb alerts ack|snooze` take.
+func (a Alert) Fingerprint() string {
+	sum := sha256.Sum256([]byte(a.Key()))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+// AlertRecord is the persisted state of one alert fingerprint. A record is
+// reopened (State back to firing, ack and snooze cleared) when its alert
+// fires again after resolving.
+type AlertRecord struct {
+	ID           string        `yaml:"id" json:"id"`
+	Key          string        `yaml:"key" json:"key"`
+	Type         AlertType     `yaml:"type" json:"type"`
+	Severity     AlertSeverity `yaml:"severity" json:"severity"`
+	Message      string        `yaml:"message" json:"message"`
+	TaskID       string        `yaml:"task_id,omitempty" json:"task_id,omitempty"`
+	Rule         string        `yaml:"rule,omitempty" json:"rule,omitempty"`
+	State        AlertState    `yaml:"state" json:"state"`
+	FiredAt      time.Time     `yaml:"fired_at" json:"fired_at"`
+	LastSeen     time.Time     `yaml:"last_seen" json:"last_seen"`
+	ResolvedAt   time.Time     `yaml:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+	AckedAt      time.Time     `yaml:"acked_at,omitempty" json:"acked_at,omitempty"`
+	SnoozedUntil time.Time     `yaml:"snoozed_until,omitempty" json:"snoozed_until,omitempty"`
+	Fired        int           `yaml:"fired" json:"fired"` // firing episodes so far
+}
+
+// AlertStateStore persists AlertRecords to a YAML file under .adb/. Like the
+// notification ledger, the scheduler's alerts-tick and an interactive
+// `adb alerts` can run at once, so every update holds a sidecar flock across
+// load → mutate → save and writes land via temp file + rename.
+type AlertStateStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+type alertStateFile struct {
+	Alerts []AlertRecord `yaml:"alerts"`
+}
+
+// NewAlertStateStore returns a store persisted at path (normally
+// statedir.Path(base, statedir.FileAlertState)).
+func NewAlertStateStore(path string) *AlertStateStore {
+	return &AlertStateStore{path: path}
+}
+
+// Records returns every record, most recently fired first.
+func (s *AlertStateStore) Records() ([]AlertRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return sortedAlertRecords(state), nil
+}
+
+// Update runs fn over the records (keyed by ID) under the store lock and
+// saves the result unless fn errors.
+func (s *AlertStateStore) Update(fn func(map[string]*AlertRecord) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create alert state directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open alert state lock: %w", err)
+	}
+	defer f.Close()
+	unlock, err := lockfile.Lock(f)
+	if err != nil {
+		return fmt.Errorf("lock alert state: %w", err)
+	}
+	defer unlock()
+
+	state, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+	return s.save(state)
+}
+
+func (s *AlertStateStore) load() (map[string]*AlertRecord, error) {
+	state := make(map[string]*AlertRecord)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("read alert state: %w", err)
+	}
+	var file alertStateFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse alert state: %w", err)
+	}
+	for i := range file.Alerts {
+		rec := file.Alerts[i]
+		state[rec.ID] = &rec
+	}
+	return state, nil
+}
+
+func (s *AlertStateStore) save(state map[string]*AlertRecord) error {
+	data, err := yaml.Marshal(alertStateFile{Alerts: sortedAlertRecords(state)})
+	if err != nil {
+		return fmt.Errorf("marshal alert state: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write alert state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("commit alert state: %w", err)
+	}
+	return nil
+}
+
+func sortedAlertRecords(state map[string]*AlertRecord) []AlertRecord {
+	out := make([]AlertRecord, 0, len(state))
+	for _, rec := range state {
+		out = append(out, *rec)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].FiredAt.Equal(out[j].FiredAt) {
+			return out[i].FiredAt.After(out[j].FiredAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// TrackedAlert is a currently-active alert joined with its lifecycle state.
+type TrackedAlert struct {
+	Alert
+	ID           string     `json:"id"`
+	State        AlertState `json:"state"`
+	FiredAt      time.Time  `json:"fired_at"`
+	SnoozedUntil time.Time  `json:"snoozed_until,omitempty"`
+}
+
+// Unacknowledged returns the alerts still demanding attention — firing, not
+// acknowledged and not inside a snooze. This is what the dashboard shows and
+// what notifications are sent for.
+func Unacknowledged(tracked []TrackedAlert) []Alert {
+	var out []Alert
+	for _, t := range tracked {
+		if t.State == AlertStateFiring {
+			out = append(out, t.Alert)
+		}
+	}
+	return out
+}
+
+// AlertTracker gives evaluated alerts an identity and a memory: it reconciles
+// each evaluation against the AlertStateStore, emits alert.fired and
+// alert.resolved on transitions, and applies acknowledgements and snoozes.
+type AlertTracker struct {
+	store *AlertStateStore
+	log   *EventLog
+	now   func() time.Time
+}
+
+// NewAlertTracker returns a tracker over store that records transitions on
+// log (nil disables the events).
+func NewAlertTracker(store *AlertStateStore, log *EventLog) *AlertTracker {
+	return &AlertTracker{store: store, log: log, now: func() time.Time { return time.Now().UTC() }}
+}
+
+// Track reconciles the complete set of currently-active alerts with the
+// persisted state: new (or re-firing) alerts open a firing record, expired
+// snoozes fire again, and records absent from alerts resolve. It returns the
+// active alerts with their state, in input order.
+func (t *AlertTracker) Track(alerts []Alert) ([]TrackedAlert, error) {
+	now := t.now()
+	var fired, resolved []AlertRecord
+	tracked := make([]TrackedAlert, 0, len(alerts))
+
+	err := t.store.Update(func(state map[string]*AlertRecord) error {
+		active := make(map[string]bool, len(alerts))
+		var firedIDs []string
+		for _, a := range alerts {
+			id := a.Fingerprint()
+			active[id] = true
+			rec, ok := state[id]
+			if !ok || rec.State == AlertStateResolved {
+				if !ok {
+					rec = &AlertRecord{ID: id}
+					state[id] = rec
+				}
+				rec.State = AlertStateFiring
+				rec.FiredAt = now
+				rec.ResolvedAt = time.Time{}
+				rec.AckedAt = time.Time{}
+				rec.SnoozedUntil = time.Time{}
+				rec.Fired++
+				firedIDs = append(firedIDs, id)
+			}
+			if rec.State == AlertStateSnoozed && !now.Before(rec.SnoozedUntil) {
+				rec.State = AlertStateFiring
+				rec.SnoozedUntil = time.Time{}
+			}
+			rec.Key = a.Key()
+			rec.Type = a.Type
+			rec.Severity = a.Severity
+			rec.Message = a.Message
+			rec.TaskID = a.TaskID
+			rec.Rule = a.Rule
+			rec.LastSeen = now
+			tracked = append(tracked, TrackedAlert{Alert: a, ID: id, State: rec.State, FiredAt: rec.FiredAt, SnoozedUntil: rec.SnoozedUntil})
+		}
+		for _, id := range firedIDs {
+			fired = append(fired, *state[id])
+		}
+		for id, rec := range state {
+			if rec.State == AlertStateResolved {
+				if now.Sub(rec.ResolvedAt) > resolvedRetention {
+					delete(state, id)
+				}
+				continue
+			}
+			if !active[id] {
+				rec.State = AlertStateResolved
+				rec.ResolvedAt = now
+				resolved = append(resolved, *rec)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rec := range fired {
+		t.emit(EventAlertFired, rec, nil)
+	}
+	for _, rec := range resolved {
+		t.emit(EventAlertResolved, rec, map[string]interface{}{
+			"fired_at": rec.FiredAt.Format(time.RFC3339),
+			"duration": rec.ResolvedAt.Sub(rec.FiredAt).String(),
+		})
+	}
+	return tracked, nil
+}
+
+func (t *AlertTracker) emit(eventType EventType, rec AlertRecord, extra map[string]interface{}) {
+	if t.log == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"id":       rec.ID,
+		"key":      rec.Key,
+		"type":     string(rec.Type),
+		"severity": string(rec.Severity),
+	}
+	if eventType == EventAlertFired {
+		data["message"] = rec.Message
+	}
+	if rec.TaskID != "" {
+		data["task_id"] = rec.TaskID
+	}
+	if rec.Rule != "" {
+		data["rule"] = rec.Rule
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	t.log.Log(eventType, data)
+}
+
+// Acknowledge marks the alert identified by ref as acknowledged until it
+// resolves. ref is a fingerprint (or unique prefix) or an alert key.
+func (t *AlertTracker) Acknowledge(ref string) (AlertRecord, error) {
+	return t.transition(ref, func(rec *AlertRecord, now time.Time) {
+		rec.State = AlertStateAcknowledged
+		rec.AckedAt = now
+		rec.SnoozedUntil = time.Time{}
+	})
+}
+
+// Snooze silences the alert identified by ref for d; it fires again once the
+// snooze expires if the condition still holds.
+func (t *AlertTracker) Snooze(ref string, d time.Duration) (AlertRecord, error) {
+	if d <= 0 {
+		return AlertRecord{}, fmt.Errorf("snooze duration must be positive")
+	}
+	return t.transition(ref, func(rec *AlertRecord, now time.Time) {
+		rec.State = AlertStateSnoozed
+		rec.SnoozedUntil = now.Add(d)
+	})
+}
+
+func (t *AlertTracker) transition(ref string, apply func(*AlertRecord, time.Time)) (AlertRecord, error) {
+	var out AlertRecord
+	err := t.store.Update(func(state map[string]*AlertRecord) error {
+		rec, err := findAlertRecord(state, ref)
+		if err != nil {
+			return err
+		}
+		apply(rec, t.now())
+		out = *rec
+		return nil
+	})
+	return out, err
+}
+
+// findAlertRecord resolves ref against the non-resolved records: an exact ID
+// or key, else a unique ID prefix.
+func findAlertRecord(state map[string]*AlertRecord, ref string) (*AlertRecord, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("alert id is required")
+	}
+	var matches []*AlertRecord
+	for _, rec := range state {
+		if rec.State == AlertStateResolved {
+			continue
+		}
+		if rec.ID == ref || rec.Key == ref {
+			return rec, nil
+		}
+		if strings.HasPrefix(rec.ID, ref) {
+			matches = append(matches, rec)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no active alert %q (see `adb alerts --all`)", ref)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("alert id %q is ambiguous (%d matches)", ref, len(matches))
+}
+
+// Records returns every persisted alert record — active ones and those
+// resolved within the retention window — most recently fired first.
+func (t *AlertTracker) Records() ([]AlertRecord, error) {
+	return t.store.Records()
+}
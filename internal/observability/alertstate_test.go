@@ -0,0 +1,140 @@
+package observability
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestTracker(t *testing.T) (*AlertTracker, *EventLog, func(time.Duration)) {
+	t.Helper()
+	dir := t.TempDir()
+	el := NewEventLog(filepath.Join(dir, "events.jsonl"))
+	tr := NewAlertTracker(NewAlertStateStore(filepath.Join(dir, "alert_state.yaml")), el)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+	return tr, el, func(d time.Duration) { now = now.Add(d) }
+}
+
+func staleAlert(taskID string) Alert {
+	return Alert{Type: AlertTaskStale, Severity: AlertSeverityMedium, Message: "stale " + taskID, TaskID: taskID}
+}
+
+func TestAlert_FingerprintIsStable(t *testing.T) {
+	a := staleAlert("TASK-1")
+	b := staleAlert("TASK-1")
+	b.Message = "different text"
+	b.Timestamp = time.Now()
+	if a.Fingerprint() != b.Fingerprint() || len(a.Fingerprint()) != 8 {
+		t.Errorf("fingerprints %q vs %q", a.Fingerprint(), b.Fingerprint())
+	}
+	if a.Fingerprint() == staleAlert("TASK-2").Fingerprint() {
+		t.Error("different tasks must not share a fingerprint")
+	}
+}
+
+func TestAlertTracker_FiresAndResolvesOnce(t *testing.T) {
+	tr, el, advance := newTestTracker(t)
+	alerts := []Alert{staleAlert("TASK-1")}
+
+	for i := 0; i < 3; i++ {
+		if _, err := tr.Track(alerts); err != nil {
+			t.Fatalf("Track: %v", err)
+		}
+		advance(time.Hour)
+	}
+	if _, err := tr.Track(nil); err != nil {
+		t.Fatalf("Track(nil): %v", err)
+	}
+
+	fired, _ := el.ReadByType(EventAlertFired)
+	resolved, _ := el.ReadByType(EventAlertResolved)
+	if len(fired) != 1 || len(resolved) != 1 {
+		t.Fatalf("fired=%d resolved=%d events, want 1 each", len(fired), len(resolved))
+	}
+	if resolved[0].Data["duration"] != "3h0m0s" || fired[0].Data["task_id"] != "TASK-1" {
+		t.Errorf("payloads fired=%v resolved=%v", fired[0].Data, resolved[0].Data)
+	}
+
+	records, _ := tr.Records()
+	if len(records) != 1 || records[0].State != AlertStateResolved {
+		t.Fatalf("records = %+v", records)
+	}
+}
+
+func TestAlertTracker_AckHoldsUntilResolved(t *testing.T) {
+	tr, _, _ := newTestTracker(t)
+	alerts := []Alert{staleAlert("TASK-1"), staleAlert("TASK-2")}
+	tracked, _ := tr.Track(alerts)
+
+	if _, err := tr.Acknowledge(tracked[0].ID[:5]); err != nil {
+		t.Fatalf("Acknowledge by prefix: %v", err)
+	}
+	tracked, _ = tr.Track(alerts)
+	if tracked[0].State != AlertStateAcknowledged {
+		t.Fatalf("state = %s, want acknowledged", tracked[0].State)
+	}
+	if un := Unacknowledged(tracked); len(un) != 1 || un[0].TaskID != "TASK-2" {
+		t.Fatalf("Unacknowledged = %+v", un)
+	}
+
+	// Resolve, then re-fire: a new episode starts unacknowledged.
+	_, _ = tr.Track(alerts[1:])
+	tracked, _ = tr.Track(alerts)
+	if tracked[0].State != AlertStateFiring {
+		t.Fatalf("re-fired state = %s, want firing", tracked[0].State)
+	}
+	records, _ := tr.Records()
+	for _, r := range records {
+		if r.TaskID == "TASK-1" && r.Fired != 2 {
+			t.Errorf("TASK-1 fired %d episodes, want 2", r.Fired)
+		}
+	}
+
+	if _, err := tr.Acknowledge("task_stale:TASK-2"); err != nil {
+		t.Errorf("Acknowledge by key: %v", err)
+	}
+	if _, err := tr.Acknowledge("zzzz"); err == nil {
+		t.Error("unknown id should be an error")
+	}
+}
+
+func TestAlertTracker_SnoozeExpires(t *testing.T) {
+	tr, _, advance := newTestTracker(t)
+	alerts := []Alert{staleAlert("TASK-1")}
+	tracked, _ := tr.Track(alerts)
+
+	rec, err := tr.Snooze(tracked[0].ID, 48*time.Hour)
+	if err != nil {
+		t.Fatalf("Snooze: %v", err)
+	}
+	if rec.State != AlertStateSnoozed {
+		t.Fatalf("state = %s", rec.State)
+	}
+
+	advance(24 * time.Hour)
+	tracked, _ = tr.Track(alerts)
+	if tracked[0].State != AlertStateSnoozed || len(Unacknowledged(tracked)) != 0 {
+		t.Fatalf("mid-snooze state = %s", tracked[0].State)
+	}
+
+	advance(25 * time.Hour)
+	tracked, _ = tr.Track(alerts)
+	if tracked[0].State != AlertStateFiring {
+		t.Fatalf("post-snooze state = %s, want firing", tracked[0].State)
+	}
+	if _, err := tr.Snooze(tracked[0].ID, 0); err == nil {
+		t.Error("zero snooze should be an error")
+	}
+}
+
+func TestAlertTracker_PrunesOldResolved(t *testing.T) {
+	tr, _, advance := newTestTracker(t)
+	_, _ = tr.Track([]Alert{staleAlert("TASK-1")})
+	_, _ = tr.Track(nil)
+	advance(resolvedRetention + time.Hour)
+	_, _ = tr.Track(nil)
+	if records, _ := tr.Records(); len(records) != 0 {
+		t.Fatalf("records = %+v, want pruned", records)
+	}
+}
@@ -1,10 +1,10 @@
 package observability
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -39,18 +39,60 @@ type Event struct {
 	Data      map[string]interface{} `json:"data"`
 }
 
-// EventLog manages append-only JSONL event logging
+// Payload flattens the event's Data into string values, the form rule
+// templates and conditions read.
+func (e Event) Payload() map[string]string {
+	if len(e.Data) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(e.Data))
+	for k, v := range e.Data {
+		out[k] = stringifyEventValue(v)
+	}
+	return out
+}
+
+// stringifyEventValue renders one event-payload value for template use. JSON
+// numbers arrive as float64; rendering them with strconv 'f' avoids scientific
+// notation and prints whole numbers without a trailing ".0", so a numeric id
+// substitutes cleanly (float64 precision limits on huge integers are inherent to
+// the JSON event log, not introduced here).
+func stringifyEventValue(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// EventLog manages append-only JSONL event logging. The log is segmented
+// (see segments.go): filePath is the active segment, sealed segments sit
+// beside it, and reads consult a sidecar index. Subscribers (see
+// subscribers.go) are notified of each appended event.
 type EventLog struct {
-	filePath string
-	mu       sync.Mutex
-	enabled  bool // false if log file can't be created
+	filePath    string
+	mu          sync.Mutex
+	enabled     bool  // false if log file can't be created
+	segmentSize int64 // seal the active segment at this size (<= 0: never)
+	index       *segmentIndex
+
+	subMu   sync.Mutex // guards subs and nextSub; never held with mu
+	subs    []*subscriber
+	nextSub int
 }
 
 // NewEventLog creates a new event log
-func NewEventLog(filePath string) *EventLog {
+func NewEventLog(filePath string, opts ...EventLogOption) *EventLog {
 	el := &EventLog{
-		filePath: filePath,
-		enabled:  true,
+		filePath:    filePath,
+		enabled:     true,
+		segmentSize: DefaultSegmentSize,
+	}
+	for _, opt := range opts {
+		opt(el)
 	}
 
 	// Test if we can write to the file (non-fatal if we can't)
@@ -73,10 +115,18 @@ func (el *EventLog) ensureFileExists() error {
 	return f.Close()
 }
 
-// Log writes an event to the log file (thread-safe, non-fatal on error)
+// Log writes an event to the log file (thread-safe, non-fatal on error) and
+// then notifies the subscribers.
 func (el *EventLog) Log(eventType EventType, data map[string]interface{}) {
+	if line, ok := el.write(eventType, data); ok {
+		el.publish(line)
+	}
+}
+
+// write appends one event, reporting the line written.
+func (el *EventLog) write(eventType EventType, data map[string]interface{}) ([]byte, bool) {
 	if !el.enabled {
-		return // silently skip if disabled
+		return nil, false // silently skip if disabled
 	}
 
 	el.mu.Lock()
@@ -93,143 +143,115 @@ func (el *EventLog) Log(eventType EventType, data map[string]interface{}) {
 	if err != nil {
 		// Non-fatal: log to stderr but don't crash
 		fmt.Fprintf(os.Stderr, "Warning: failed to marshal event: %v\n", err)
-		return
+		return nil, false
 	}
 
 	// Append to file
 	f, err := os.OpenFile(el.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to open event log: %v\n", err)
-		return
+		return nil, false
 	}
 	defer f.Close()
 
 	// Write JSONL (JSON + newline)
 	if _, err := f.Write(append(jsonData, '\n')); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to write event: %v\n", err)
+		return nil, false
+	}
+
+	el.maybeSeal(f)
+	return jsonData, true
+}
+
+// maybeSeal seals the active segment once it reaches the segment size.
+// Caller holds el.mu and has just appended through f.
+func (el *EventLog) maybeSeal(f *os.File) {
+	if el.segmentSize <= 0 {
+		return
+	}
+	info, err := f.Stat()
+	if err != nil || info.Size() < el.segmentSize {
 		return
 	}
+	if err := el.sealLocked(el.segmentSize); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to seal event log segment: %v\n", err)
+	}
 }
 
 // appendEvent writes a fully-formed Event (with its own Timestamp) as one
 // JSONL line, bypassing the time.Now() stamping Log() does. It exists so
 // callers/tests can inject events with controlled timestamps. Thread-safe and
 // non-fatal in spirit, but returns the write error so tests can assert on it.
+// Subscribers are notified as for Log.
 func (el *EventLog) appendEvent(e Event) error {
+	line, err := el.appendLine(e)
+	if err == nil && line != nil {
+		el.publish(line)
+	}
+	return err
+}
+
+func (el *EventLog) appendLine(e Event) ([]byte, error) {
 	if !el.enabled {
-		return nil
+		return nil, nil
 	}
 	el.mu.Lock()
 	defer el.mu.Unlock()
 
 	jsonData, err := json.Marshal(e)
 	if err != nil {
-		return fmt.Errorf("marshal event: %w", err)
+		return nil, fmt.Errorf("marshal event: %w", err)
 	}
 	f, err := os.OpenFile(el.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 	if err != nil {
-		return fmt.Errorf("open event log: %w", err)
+		return nil, fmt.Errorf("open event log: %w", err)
 	}
 	defer f.Close()
 	if _, err := f.Write(append(jsonData, '\n')); err != nil {
-		return fmt.Errorf("write event: %w", err)
+		return nil, fmt.Errorf("write event: %w", err)
 	}
-	return nil
+	el.maybeSeal(f)
+	return jsonData, nil
 }
 
-// ReadAll reads all events from the log, gracefully skipping malformed lines
+// ReadAll reads all events from every segment, oldest first, gracefully
+// skipping malformed lines
 func (el *EventLog) ReadAll() ([]Event, error) {
-	el.mu.Lock()
-	defer el.mu.Unlock()
-
-	// Check if file exists
-	if _, err := os.Stat(el.filePath); os.IsNotExist(err) {
-		return []Event{}, nil
-	}
-
-	f, err := os.Open(el.filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open event log: %w", err)
-	}
-	defer f.Close()
-
-	var events []Event
-	scanner := bufio.NewScanner(f)
-	lineNum := 0
-
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-
-		// Skip empty lines
-		if len(line) == 0 {
-			continue
-		}
-
-		var event Event
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			// Gracefully skip malformed lines (log warning but continue)
-			fmt.Fprintf(os.Stderr, "Warning: skipping malformed event at line %d: %v\n", lineNum, err)
-			continue
-		}
-
-		events = append(events, event)
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to read event log: %w", err)
-	}
-
-	return events, nil
+	return el.ReadRange(time.Time{}, time.Time{})
 }
 
 // ReadSince returns every event with Timestamp >= cutoff (UTC). A zero
 // cutoff (time.Time{}) returns every event, equivalent to ReadAll. This is
 // the seam `adb events tail` uses to page in events after a "last seen"
-// watermark — the VS Code webview reconnect path relies on this.
+// watermark — the VS Code webview reconnect path relies on this. Segments
+// that ended before the cutoff are not read.
 func (el *EventLog) ReadSince(cutoff time.Time) ([]Event, error) {
-	all, err := el.ReadAll()
-	if err != nil {
-		return nil, err
-	}
-	if cutoff.IsZero() {
-		return all, nil
-	}
-	var out []Event
-	for _, e := range all {
-		if !e.Timestamp.Before(cutoff) {
-			out = append(out, e)
-		}
-	}
-	return out, nil
+	return el.ReadRange(cutoff, time.Time{})
 }
 
-// ReadByType reads all events of a specific type
+// ReadByType reads all events of a specific type, opening only the segments
+// whose index says they contain it
 func (el *EventLog) ReadByType(eventType EventType) ([]Event, error) {
-	allEvents, err := el.ReadAll()
-	if err != nil {
-		return nil, err
-	}
-
-	var filtered []Event
-	for _, event := range allEvents {
-		if event.Type == eventType {
-			filtered = append(filtered, event)
-		}
-	}
-
-	return filtered, nil
+	return el.ReadRange(time.Time{}, time.Time{}, eventType)
 }
 
-// Clear clears the event log (for testing purposes)
+// Clear clears the event log — every segment and the index (for testing
+// purposes)
 func (el *EventLog) Clear() error {
 	el.mu.Lock()
 	defer el.mu.Unlock()
 
-	// Remove the file if it exists
-	if err := os.Remove(el.filePath); err != nil && !os.IsNotExist(err) {
+	files, err := el.segmentFiles()
+	if err != nil {
 		return fmt.Errorf("failed to clear event log: %w", err)
 	}
+	files[el.indexPath()] = 0
+	for path := range files {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear event log: %w", err)
+		}
+	}
 
 	return nil
 }
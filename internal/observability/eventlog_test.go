@@ -341,3 +341,21 @@ func TestEventLog_Clear(t *testing.T) {
 		t.Errorf("Expected 0 events after clear, got %d", len(events))
 	}
 }
+
+func TestStringifyEventValue(t *testing.T) {
+	cases := []struct {
+		in   interface{}
+		want string
+	}{
+		{"TASK-42", "TASK-42"},                    // strings pass through
+		{float64(42), "42"},                       // whole JSON numbers: no ".0"
+		{float64(42.5), "42.5"},                   // fractional numbers keep precision
+		{float64(1e21), "1000000000000000000000"}, // no scientific notation
+		{true, "true"},                            // other types fall back to %v
+	}
+	for _, c := range cases {
+		if got := stringifyEventValue(c.in); got != c.want {
+			t.Fatalf("stringifyEventValue(%v) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,523 @@
+package observability
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Flow dimensions a FlowOptions.GroupBy / Filter can name.
+const (
+	FlowDimType       = "type"
+	FlowDimPriority   = "priority"
+	FlowDimRepo       = "repo"
+	FlowDimInitiative = "initiative"
+)
+
+// FlowDimensions lists the dimensions flow metrics can be sliced by.
+var FlowDimensions = []string{FlowDimType, FlowDimPriority, FlowDimRepo, FlowDimInitiative}
+
+// FlowStatuses is the column order of the cumulative flow diagram.
+var FlowStatuses = []string{"backlog", "in_progress", "blocked", "review", "done", "archived"}
+
+// wipStatuses are the statuses that count as work in progress (the same set
+// models.Task.IsActive uses).
+var wipStatuses = map[string]bool{"in_progress": true, "review": true, "blocked": true}
+
+// FlowTask carries the slicing dimensions the event log does not record
+// (repo, initiative) or may record stale (type, priority). Callers fill it
+// from the backlog; a task missing here falls back to its task.created data.
+type FlowTask struct {
+	ID         string
+	Type       string
+	Priority   string
+	Repo       string
+	Initiative string
+}
+
+// FlowOptions scopes a ComputeFlow call.
+type FlowOptions struct {
+	// Now is the end of the window (zero: time.Now).
+	Now time.Time
+	// Since starts the window: only completions, status time and daily
+	// snapshots at or after it count. Zero means the whole log.
+	Since time.Time
+	// GroupBy is one of FlowDimensions; empty reports only the overall view.
+	GroupBy string
+	// Filter keeps only tasks whose dimension equals the value, e.g.
+	// {"priority": "P0"}.
+	Filter map[string]string
+	// Tasks enriches the event log with backlog dimensions.
+	Tasks []FlowTask
+}
+
+// DurationStats summarises a sample of durations with nearest-rank
+// percentiles.
+type DurationStats struct {
+	Count int
+	Mean  time.Duration
+	P50   time.Duration
+	P85   time.Duration
+	P95   time.Duration
+}
+
+// MarshalJSON renders the durations as fractional hours.
+func (s DurationStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Count     int     `json:"count"`
+		MeanHours float64 `json:"mean_hours"`
+		P50Hours  float64 `json:"p50_hours"`
+		P85Hours  float64 `json:"p85_hours"`
+		P95Hours  float64 `json:"p95_hours"`
+	}{s.Count, hours(s.Mean), hours(s.P50), hours(s.P85), hours(s.P95)})
+}
+
+func hours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
+
+// NewDurationStats computes stats over samples (which it sorts in place).
+func NewDurationStats(samples []time.Duration) DurationStats {
+	if len(samples) == 0 {
+		return DurationStats{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	var total time.Duration
+	for _, d := range samples {
+		total += d
+	}
+	return DurationStats{
+		Count: len(samples),
+		Mean:  total / time.Duration(len(samples)),
+		P50:   percentile(samples, 50),
+		P85:   percentile(samples, 85),
+		P95:   percentile(samples, 95),
+	}
+}
+
+// percentile is the nearest-rank percentile of an ascending sample.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// PeriodCount is one point of a time series: a day ("2026-03-02") or an ISO
+// week start ("2026-W10").
+type PeriodCount struct {
+	Period string `json:"period"`
+	Count  int    `json:"count"`
+}
+
+// AgingItem is one task currently in progress and how long it has been.
+type AgingItem struct {
+	TaskID   string        `json:"task_id"`
+	Status   string        `json:"status"`
+	Started  time.Time     `json:"started"`
+	Age      time.Duration `json:"-"`
+	AgeHours float64       `json:"age_hours"`
+}
+
+// FlowReport holds the flow metrics for one slice of tasks.
+type FlowReport struct {
+	Group        string                   `json:"group,omitempty"`
+	Tasks        int                      `json:"tasks"`
+	Completed    int                      `json:"completed"`
+	LeadTime     DurationStats            `json:"lead_time"`
+	CycleTime    DurationStats            `json:"cycle_time"`
+	TimeInStatus map[string]DurationStats `json:"time_in_status"`
+	Throughput   []PeriodCount            `json:"weekly_throughput"`
+	WIP          []PeriodCount            `json:"wip"`
+	AgingWIP     []AgingItem              `json:"aging_wip"`
+	AgingAge     DurationStats            `json:"aging_wip_age"`
+}
+
+// CFDPoint is one day of the cumulative flow diagram: how many tasks sat in
+// each status at the end of that day.
+type CFDPoint struct {
+	Date   string
+	Counts map[string]int
+}
+
+// FlowMetrics is the result of ComputeFlow.
+type FlowMetrics struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Since       time.Time         `json:"since,omitempty"`
+	GroupBy     string            `json:"group_by,omitempty"`
+	Filter      map[string]string `json:"filter,omitempty"`
+	Overall     FlowReport        `json:"overall"`
+	Groups      []FlowReport      `json:"groups,omitempty"`
+	CFD         []CFDPoint        `json:"-"`
+}
+
+// flowTimeline is one task's status history replayed from the event log.
+type flowTimeline struct {
+	id      string
+	dims    map[string]string
+	created time.Time // zero when the task.created event is missing (rotated)
+	steps   []flowStep
+	removed time.Time // task.deleted
+}
+
+type flowStep struct {
+	at     time.Time
+	status string
+}
+
+// statusAt returns the task's status at t ("" before it existed or after it
+// was deleted).
+func (tl *flowTimeline) statusAt(t time.Time) string {
+	if !tl.removed.IsZero() && !tl.removed.After(t) {
+		return ""
+	}
+	status := ""
+	for _, s := range tl.steps {
+		if s.at.After(t) {
+			break
+		}
+		status = s.status
+	}
+	return status
+}
+
+// ComputeFlow derives flow metrics — lead and cycle time, time per status,
+// daily WIP, weekly throughput and aging WIP — from the task transitions in
+// the event log.
+func (mc *MetricsCalculator) ComputeFlow(opts FlowOptions) (*FlowMetrics, error) {
+	events, err := mc.eventLog.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+	if opts.GroupBy != "" && !isFlowDimension(opts.GroupBy) {
+		return nil, fmt.Errorf("unknown flow dimension %q (valid: type, priority, repo, initiative)", opts.GroupBy)
+	}
+	for dim := range opts.Filter {
+		if !isFlowDimension(dim) {
+			return nil, fmt.Errorf("unknown flow dimension %q (valid: type, priority, repo, initiative)", dim)
+		}
+	}
+
+	timelines := buildFlowTimelines(events, opts.Tasks)
+
+	var selected []*flowTimeline
+	for _, tl := range timelines {
+		if matchesFlowFilter(tl, opts.Filter) {
+			selected = append(selected, tl)
+		}
+	}
+
+	fm := &FlowMetrics{GeneratedAt: now, Since: opts.Since, GroupBy: opts.GroupBy, Filter: opts.Filter}
+	start := opts.Since
+	if start.IsZero() {
+		start = earliestFlowStep(selected)
+	}
+	fm.Overall = flowReport("", selected, start, now)
+	if opts.GroupBy != "" {
+		byGroup := make(map[string][]*flowTimeline)
+		for _, tl := range selected {
+			key := tl.dims[opts.GroupBy]
+			if key == "" {
+				key = "(none)"
+			}
+			byGroup[key] = append(byGroup[key], tl)
+		}
+		keys := make([]string, 0, len(byGroup))
+		for k := range byGroup {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fm.Groups = append(fm.Groups, flowReport(k, byGroup[k], start, now))
+		}
+	}
+	fm.CFD = cumulativeFlow(selected, start, now)
+	return fm, nil
+}
+
+func isFlowDimension(dim string) bool {
+	for _, d := range FlowDimensions {
+		if d == dim {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesFlowFilter(tl *flowTimeline, filter map[string]string) bool {
+	for dim, want := range filter {
+		if tl.dims[dim] != want {
+			return false
+		}
+	}
+	return true
+}
+
+func buildFlowTimelines(events []Event, tasks []FlowTask) []*flowTimeline {
+	byID := make(map[string]*flowTimeline)
+	var order []string
+	get := func(id string) *flowTimeline {
+		tl, ok := byID[id]
+		if !ok {
+			tl = &flowTimeline{id: id, dims: map[string]string{}}
+			byID[id] = tl
+			order = append(order, id)
+		}
+		return tl
+	}
+
+	for _, e := range events {
+		id, _ := e.Data["task_id"].(string)
+		if id == "" {
+			continue
+		}
+		switch e.Type {
+		case EventTaskCreated:
+			tl := get(id)
+			tl.created = e.Timestamp
+			if v, ok := e.Data["type"].(string); ok {
+				tl.dims[FlowDimType] = v
+			}
+			if v, ok := e.Data["priority"].(string); ok {
+				tl.dims[FlowDimPriority] = v
+			}
+			status, _ := e.Data["status"].(string)
+			if status == "" {
+				status = "backlog"
+			}
+			tl.steps = append(tl.steps, flowStep{at: e.Timestamp, status: status})
+		case EventTaskStatusChanged:
+			if status, ok := e.Data["new_status"].(string); ok {
+				tl := get(id)
+				tl.steps = append(tl.steps, flowStep{at: e.Timestamp, status: status})
+			}
+		case EventTaskPriorityChanged:
+			if v, ok := e.Data["new_priority"].(string); ok {
+				get(id).dims[FlowDimPriority] = v
+			}
+		case EventTaskArchived:
+			tl := get(id)
+			tl.steps = append(tl.steps, flowStep{at: e.Timestamp, status: "archived"})
+		case EventTaskUnarchived:
+			// TaskManager.UnarchiveTask returns the task to the backlog.
+			tl := get(id)
+			tl.steps = append(tl.steps, flowStep{at: e.Timestamp, status: "backlog"})
+		case EventTaskDeleted:
+			get(id).removed = e.Timestamp
+		}
+	}
+
+	for _, t := range tasks {
+		tl, ok := byID[t.ID]
+		if !ok {
+			continue // no history to measure
+		}
+		for dim, v := range map[string]string{
+			FlowDimType: t.Type, FlowDimPriority: t.Priority, FlowDimRepo: t.Repo, FlowDimInitiative: t.Initiative,
+		} {
+			if v != "" {
+				tl.dims[dim] = v
+			}
+		}
+	}
+
+	out := make([]*flowTimeline, 0, len(order))
+	for _, id := range order {
+		out = append(out, byID[id])
+	}
+	return out
+}
+
+func earliestFlowStep(tls []*flowTimeline) time.Time {
+	var first time.Time
+	for _, tl := range tls {
+		if len(tl.steps) > 0 && (first.IsZero() || tl.steps[0].at.Before(first)) {
+			first = tl.steps[0].at
+		}
+	}
+	return first
+}
+
+func flowReport(group string, tls []*flowTimeline, start, now time.Time) FlowReport {
+	r := FlowReport{Group: group, Tasks: len(tls), TimeInStatus: map[string]DurationStats{}}
+	var lead, cycle, aging []time.Duration
+	inStatus := make(map[string][]time.Duration)
+	weekly := make(map[string]int)
+
+	for _, tl := range tls {
+		if len(tl.steps) == 0 {
+			continue
+		}
+		first := tl.steps[0].at
+		if !tl.created.IsZero() {
+			first = tl.created
+		}
+		var started, doneAt time.Time
+		for _, s := range tl.steps {
+			if s.status == "in_progress" && started.IsZero() {
+				started = s.at
+			}
+			if s.status == "done" {
+				doneAt = s.at
+			}
+		}
+		current := tl.statusAt(now)
+		completed := !doneAt.IsZero() && (current == "done" || current == "archived")
+
+		if completed && !doneAt.Before(start) {
+			r.Completed++
+			lead = append(lead, doneAt.Sub(first))
+			if !started.IsZero() && !started.After(doneAt) {
+				cycle = append(cycle, doneAt.Sub(started))
+			}
+			weekly[isoWeek(doneAt)]++
+		}
+
+		// Dwell per status, clipped to the window; done/archived are terminal.
+		dwell := make(map[string]time.Duration)
+		for i, s := range tl.steps {
+			if s.status == "done" || s.status == "archived" {
+				continue
+			}
+			end := now
+			if i+1 < len(tl.steps) {
+				end = tl.steps[i+1].at
+			}
+			if !tl.removed.IsZero() && tl.removed.Before(end) {
+				end = tl.removed
+			}
+			from := s.at
+			if from.Before(start) {
+				from = start
+			}
+			if end.After(from) {
+				dwell[s.status] += end.Sub(from)
+			}
+		}
+		for status, d := range dwell {
+			inStatus[status] = append(inStatus[status], d)
+		}
+
+		if wipStatuses[current] {
+			since := started
+			if since.IsZero() {
+				// Went straight to review/blocked: age from the first WIP step.
+				for _, s := range tl.steps {
+					if wipStatuses[s.status] {
+						since = s.at
+						break
+					}
+				}
+			}
+			age := now.Sub(since)
+			aging = append(aging, age)
+			r.AgingWIP = append(r.AgingWIP, AgingItem{TaskID: tl.id, Status: current, Started: since, Age: age, AgeHours: hours(age)})
+		}
+	}
+
+	r.LeadTime = NewDurationStats(lead)
+	r.CycleTime = NewDurationStats(cycle)
+	r.AgingAge = NewDurationStats(aging)
+	for status, samples := range inStatus {
+		r.TimeInStatus[status] = NewDurationStats(samples)
+	}
+	sort.Slice(r.AgingWIP, func(i, j int) bool { return r.AgingWIP[i].Age > r.AgingWIP[j].Age })
+
+	weeks := make([]string, 0, len(weekly))
+	for w := range weekly {
+		weeks = append(weeks, w)
+	}
+	sort.Strings(weeks)
+	for _, w := range weeks {
+		r.Throughput = append(r.Throughput, PeriodCount{Period: w, Count: weekly[w]})
+	}
+
+	for _, p := range cumulativeFlow(tls, start, now) {
+		wip := 0
+		for status := range wipStatuses {
+			wip += p.Counts[status]
+		}
+		r.WIP = append(r.WIP, PeriodCount{Period: p.Date, Count: wip})
+	}
+	return r
+}
+
+// cumulativeFlow snapshots every task's status at the end of each UTC day
+// from start through now.
+func cumulativeFlow(tls []*flowTimeline, start, now time.Time) []CFDPoint {
+	if start.IsZero() {
+		return nil
+	}
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	var points []CFDPoint
+	for !day.After(now) {
+		eod := day.Add(24*time.Hour - time.Nanosecond)
+		if eod.After(now) {
+			eod = now
+		}
+		counts := make(map[string]int, len(FlowStatuses))
+		for _, tl := range tls {
+			if s := tl.statusAt(eod); s != "" {
+				counts[s]++
+			}
+		}
+		points = append(points, CFDPoint{Date: day.Format("2006-01-02"), Counts: counts})
+		day = day.AddDate(0, 0, 1)
+	}
+	return points
+}
+
+// isoWeek labels t with its ISO year and week, e.g. "2026-W10".
+func isoWeek(t time.Time) string {
+	year, week := t.UTC().ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// WriteCFDCSV writes the cumulative flow diagram as CSV: a date column then
+// one column per status in FlowStatuses order (plus any other status seen),
+// ready to stack in a spreadsheet.
+func WriteCFDCSV(w io.Writer, points []CFDPoint) error {
+	columns := append([]string(nil), FlowStatuses...)
+	known := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		known[c] = true
+	}
+	var extra []string
+	for _, p := range points {
+		for status := range p.Counts {
+			if !known[status] {
+				known[status] = true
+				extra = append(extra, status)
+			}
+		}
+	}
+	sort.Strings(extra)
+	columns = append(columns, extra...)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"date"}, columns...)); err != nil {
+		return err
+	}
+	for _, p := range points {
+		row := make([]string, 0, len(columns)+1)
+		row = append(row, p.Date)
+		for _, c := range columns {
+			row = append(row, strconv.Itoa(p.Counts[c]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
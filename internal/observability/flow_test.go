@@ -0,0 +1,171 @@
+package observability
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flowT0 is a Monday, so weekly buckets line up with the test's day offsets.
+var flowT0 = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func flowDay(n float64) time.Time { return flowT0.Add(time.Duration(n * 24 * float64(time.Hour))) }
+
+func newFlowLog(t *testing.T, events ...Event) *MetricsCalculator {
+	t.Helper()
+	el := NewEventLog(filepath.Join(t.TempDir(), "events.jsonl"))
+	for _, e := range events {
+		if err := el.appendEvent(e); err != nil {
+			t.Fatalf("appendEvent: %v", err)
+		}
+	}
+	return NewMetricsCalculator(el)
+}
+
+func flowCreated(at time.Time, id, typ, priority string) Event {
+	return Event{Timestamp: at, Type: EventTaskCreated, Data: map[string]interface{}{
+		"task_id": id, "type": typ, "priority": priority, "status": "backlog",
+	}}
+}
+
+func flowMoved(at time.Time, id, from, to string) Event {
+	return Event{Timestamp: at, Type: EventTaskStatusChanged, Data: map[string]interface{}{
+		"task_id": id, "old_status": from, "new_status": to,
+	}}
+}
+
+func flowFixture(t *testing.T) *MetricsCalculator {
+	return newFlowLog(t,
+		// A: 1d in backlog, 2d in progress, 1d review → lead 4d, cycle 3d.
+		flowCreated(flowDay(0), "A", "feat", "P1"),
+		flowMoved(flowDay(1), "A", "backlog", "in_progress"),
+		flowMoved(flowDay(3), "A", "in_progress", "review"),
+		flowMoved(flowDay(4), "A", "review", "done"),
+		// B: straight through in 2d, then archived.
+		flowCreated(flowDay(0), "B", "bug", "P0"),
+		flowMoved(flowDay(0), "B", "backlog", "in_progress"),
+		flowMoved(flowDay(2), "B", "in_progress", "done"),
+		Event{Timestamp: flowDay(3), Type: EventTaskArchived, Data: map[string]interface{}{"task_id": "B"}},
+		// C: still in progress since day 5.
+		flowCreated(flowDay(1), "C", "feat", "P0"),
+		flowMoved(flowDay(5), "C", "backlog", "in_progress"),
+		// D: created then deleted; never counts once gone.
+		flowCreated(flowDay(1), "D", "spike", "P2"),
+		Event{Timestamp: flowDay(2), Type: EventTaskDeleted, Data: map[string]interface{}{"task_id": "D"}},
+		// E: done in week two.
+		flowCreated(flowDay(6), "E", "bug", "P1"),
+		flowMoved(flowDay(7), "E", "backlog", "in_progress"),
+		flowMoved(flowDay(8), "E", "in_progress", "done"),
+	)
+}
+
+func TestComputeFlow_LeadCycleAndThroughput(t *testing.T) {
+	mc := flowFixture(t)
+	fm, err := mc.ComputeFlow(FlowOptions{Now: flowDay(10)})
+	if err != nil {
+		t.Fatalf("ComputeFlow: %v", err)
+	}
+	o := fm.Overall
+	if o.Completed != 3 {
+		t.Fatalf("Completed = %d, want 3", o.Completed)
+	}
+	// Lead: B 2d, E 2d, A 4d → p50 2d, p95 4d.
+	if o.LeadTime.P50 != 48*time.Hour || o.LeadTime.P95 != 96*time.Hour {
+		t.Errorf("lead time = %+v", o.LeadTime)
+	}
+	// Cycle: E 1d, B 2d, A 3d.
+	if o.CycleTime.P50 != 48*time.Hour || o.CycleTime.Count != 3 || o.CycleTime.Mean != 48*time.Hour {
+		t.Errorf("cycle time = %+v", o.CycleTime)
+	}
+	if got := o.TimeInStatus["review"]; got.Count != 1 || got.P50 != 24*time.Hour {
+		t.Errorf("time in review = %+v", got)
+	}
+	if _, ok := o.TimeInStatus["done"]; ok {
+		t.Error("done is terminal and must not accumulate dwell time")
+	}
+
+	want := []PeriodCount{{"2026-W10", 2}, {"2026-W11", 1}}
+	if len(o.Throughput) != 2 || o.Throughput[0] != want[0] || o.Throughput[1] != want[1] {
+		t.Errorf("throughput = %+v, want %+v", o.Throughput, want)
+	}
+
+	if len(o.AgingWIP) != 1 || o.AgingWIP[0].TaskID != "C" || o.AgingWIP[0].Age != 5*24*time.Hour {
+		t.Errorf("aging WIP = %+v", o.AgingWIP)
+	}
+	if last := o.WIP[len(o.WIP)-1]; last.Count != 1 {
+		t.Errorf("WIP today = %+v, want 1", last)
+	}
+}
+
+func TestComputeFlow_GroupAndFilter(t *testing.T) {
+	mc := flowFixture(t)
+	fm, err := mc.ComputeFlow(FlowOptions{
+		Now:     flowDay(10),
+		GroupBy: FlowDimRepo,
+		Filter:  map[string]string{FlowDimPriority: "P0"},
+		Tasks:   []FlowTask{{ID: "B", Repo: "github.com/org/api"}, {ID: "C", Repo: "github.com/org/web"}},
+	})
+	if err != nil {
+		t.Fatalf("ComputeFlow: %v", err)
+	}
+	if fm.Overall.Tasks != 2 {
+		t.Fatalf("P0 tasks = %d, want 2 (B, C)", fm.Overall.Tasks)
+	}
+	if len(fm.Groups) != 2 || fm.Groups[0].Group != "github.com/org/api" || fm.Groups[0].Completed != 1 {
+		t.Errorf("groups = %+v", fm.Groups)
+	}
+
+	if _, err := mc.ComputeFlow(FlowOptions{GroupBy: "owner"}); err == nil {
+		t.Error("unknown dimension should be an error")
+	}
+}
+
+func TestComputeFlow_SinceWindow(t *testing.T) {
+	mc := flowFixture(t)
+	fm, err := mc.ComputeFlow(FlowOptions{Now: flowDay(10), Since: flowDay(5)})
+	if err != nil {
+		t.Fatalf("ComputeFlow: %v", err)
+	}
+	if fm.Overall.Completed != 1 {
+		t.Errorf("Completed since day 5 = %d, want 1 (E)", fm.Overall.Completed)
+	}
+	if fm.CFD[0].Date != "2026-03-07" {
+		t.Errorf("CFD starts %s, want the window start", fm.CFD[0].Date)
+	}
+}
+
+func TestWriteCFDCSV(t *testing.T) {
+	mc := flowFixture(t)
+	fm, err := mc.ComputeFlow(FlowOptions{Now: flowDay(3.5)})
+	if err != nil {
+		t.Fatalf("ComputeFlow: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteCFDCSV(&buf, fm.CFD); err != nil {
+		t.Fatalf("WriteCFDCSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "date,backlog,in_progress,blocked,review,done,archived" {
+		t.Errorf("header = %q", lines[0])
+	}
+	// Day 0: A backlog, B in_progress. Day 3: A review, B archived, C backlog.
+	if lines[1] != "2026-03-02,1,1,0,0,0,0" || lines[4] != "2026-03-05,1,0,0,1,0,1" {
+		t.Errorf("rows:\n%s", buf.String())
+	}
+}
+
+func TestNewDurationStats_NearestRank(t *testing.T) {
+	var samples []time.Duration
+	for i := 1; i <= 20; i++ {
+		samples = append(samples, time.Duration(i)*time.Hour)
+	}
+	s := NewDurationStats(samples)
+	if s.P50 != 10*time.Hour || s.P85 != 17*time.Hour || s.P95 != 19*time.Hour {
+		t.Errorf("stats = %+v", s)
+	}
+	if (NewDurationStats(nil) != DurationStats{}) {
+		t.Error("empty sample should be zero stats")
+	}
+}
@@ -0,0 +1,175 @@
+package observability
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metric types an exported MetricFamily can have.
+const (
+	MetricGauge   = "gauge"
+	MetricCounter = "counter"
+)
+
+// OpenMetrics and Prometheus text exposition content types.
+const (
+	OpenMetricsContentType    = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	PrometheusTextContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// MetricFamily is one exported metric and its samples. Name excludes the
+// _total suffix counters get on the wire.
+type MetricFamily struct {
+	Name    string
+	Type    string
+	Help    string
+	Samples []MetricSample
+}
+
+// MetricSample is one labelled value of a family.
+type MetricSample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Add appends a sample with labels given as alternating name/value pairs.
+func (f *MetricFamily) Add(value float64, labels ...string) {
+	s := MetricSample{Value: value}
+	if len(labels) > 0 {
+		s.Labels = make(map[string]string, len(labels)/2)
+		for i := 0; i+1 < len(labels); i += 2 {
+			s.Labels[labels[i]] = labels[i+1]
+		}
+	}
+	f.Samples = append(f.Samples, s)
+}
+
+// WriteOpenMetrics renders families in the OpenMetrics 1.0 text format,
+// terminated by "# EOF".
+func WriteOpenMetrics(w io.Writer, families []MetricFamily) error {
+	return writeExposition(w, families, true)
+}
+
+// WritePrometheusText renders families in the Prometheus 0.0.4 text format
+// (what node_exporter's textfile collector parses).
+func WritePrometheusText(w io.Writer, families []MetricFamily) error {
+	return writeExposition(w, families, false)
+}
+
+func writeExposition(w io.Writer, families []MetricFamily, openMetrics bool) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		sampleName := f.Name
+		if f.Type == MetricCounter {
+			sampleName += "_total"
+		}
+		familyName := f.Name
+		if !openMetrics {
+			// 0.0.4 names the family after its samples.
+			familyName = sampleName
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", familyName, f.Type)
+		if f.Help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", familyName, escapeHelp(f.Help))
+		}
+		for _, s := range f.Samples {
+			bw.WriteString(sampleName)
+			writeLabels(bw, s.Labels)
+			bw.WriteByte(' ')
+			bw.WriteString(formatSampleValue(s.Value))
+			bw.WriteByte('\n')
+		}
+	}
+	if openMetrics {
+		bw.WriteString("# EOF\n")
+	}
+	return bw.Flush()
+}
+
+func writeLabels(bw *bufio.Writer, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	names := make([]string, 0, len(labels))
+	for n := range labels {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	bw.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString(n)
+		bw.WriteString(`="`)
+		bw.WriteString(escapeLabelValue(labels[n]))
+		bw.WriteByte('"')
+	}
+	bw.WriteByte('}')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabelValue(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+
+func formatSampleValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// UnixSeconds converts t to a timestamp gauge value (0 for the zero time).
+func UnixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / 1e9
+}
+
+// ActiveAgentSessions counts tasks whose latest agent.session_* event within
+// window before now is not agent.session_ended.
+func (mc *MetricsCalculator) ActiveAgentSessions(now time.Time, window time.Duration) (int, error) {
+	events, err := mc.eventLog.ReadRange(now.Add(-window), time.Time{},
+		EventAgentSessionStarted, EventAgentSessionActive, EventAgentSessionEnded)
+	if err != nil {
+		return 0, err
+	}
+	latest := make(map[string]EventType)
+	for _, e := range events {
+		if id, _ := e.Data["task_id"].(string); id != "" {
+			latest[id] = e.Type
+		}
+	}
+	active := 0
+	for _, t := range latest {
+		if t != EventAgentSessionEnded {
+			active++
+		}
+	}
+	return active, nil
+}
+
+// OpenIssueConflicts counts tasks whose latest issue.conflict has not been
+// superseded by an issue.synced (or the task archived or deleted) — the same
+// rule as the issue_sync_conflict alert.
+func (mc *MetricsCalculator) OpenIssueConflicts() (int, error) {
+	open, err := mc.openIssueConflicts()
+	if err != nil {
+		return 0, err
+	}
+	return len(open), nil
+}
@@ -0,0 +1,86 @@
+package observability
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleFamilies() []MetricFamily {
+	tasks := MetricFamily{Name: "adb_tasks", Type: MetricGauge, Help: "Backlog tasks."}
+	tasks.Add(3, "status", "backlog", "type", "feat")
+	tasks.Add(1, "type", `say "hi"\now`, "status", "blocked")
+	runs := MetricFamily{Name: "adb_scheduler_job_runs", Type: MetricCounter, Help: "Runs.\nPer job."}
+	runs.Add(12, "job", "alerts-tick")
+	return []MetricFamily{tasks, runs}
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteOpenMetrics(&buf, sampleFamilies()); err != nil {
+		t.Fatalf("WriteOpenMetrics: %v", err)
+	}
+	want := `# TYPE adb_tasks gauge
+# HELP adb_tasks Backlog tasks.
+adb_tasks{status="backlog",type="feat"} 3
+adb_tasks{status="blocked",type="say \"hi\"\\now"} 1
+# TYPE adb_scheduler_job_runs counter
+# HELP adb_scheduler_job_runs Runs.\nPer job.
+adb_scheduler_job_runs_total{job="alerts-tick"} 12
+# EOF
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestWritePrometheusText_CounterFamilyNamedAfterSamples(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePrometheusText(&buf, sampleFamilies()); err != nil {
+		t.Fatalf("WritePrometheusText: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "# TYPE adb_scheduler_job_runs_total counter\n") {
+		t.Errorf("counter TYPE line should carry _total:\n%s", out)
+	}
+	if strings.Contains(out, "# EOF") {
+		t.Error("0.0.4 text format has no EOF marker")
+	}
+}
+
+func TestFormatSampleValue(t *testing.T) {
+	if got := formatSampleValue(1714000000.5); got != "1714000000.5" {
+		t.Errorf("got %s", got)
+	}
+	if UnixSeconds(time.Time{}) != 0 {
+		t.Error("zero time should export as 0")
+	}
+}
+
+func TestActiveAgentSessionsAndOpenConflicts(t *testing.T) {
+	el := NewEventLog(filepath.Join(t.TempDir(), "events.jsonl"))
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	add := func(ago time.Duration, typ EventType, task string) {
+		t.Helper()
+		if err := el.appendEvent(Event{Timestamp: now.Add(-ago), Type: typ, Data: map[string]interface{}{"task_id": task}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add(2*time.Hour, EventAgentSessionStarted, "A")
+	add(90*time.Minute, EventAgentSessionStarted, "B")
+	add(time.Hour, EventAgentSessionEnded, "B")
+	add(30*time.Hour, EventAgentSessionStarted, "C") // outside the window
+	add(3*time.Hour, EventIssueConflict, "A")
+	add(3*time.Hour, EventIssueConflict, "B")
+	add(time.Hour, EventIssueSynced, "B")
+
+	mc := NewMetricsCalculator(el)
+	if n, err := mc.ActiveAgentSessions(now, 8*time.Hour); err != nil || n != 1 {
+		t.Errorf("ActiveAgentSessions = %d, %v; want 1", n, err)
+	}
+	if n, err := mc.OpenIssueConflicts(); err != nil || n != 1 {
+		t.Errorf("OpenIssueConflicts = %d, %v; want 1", n, err)
+	}
+}
@@ -6,7 +6,8 @@ package observability
 // VS Code webview — rely on this set being the authoritative contract. When a
 // new event is added it MUST be:
 //
-//  1. Declared here (or in eventlog.go's task.* / issue.* block for locality)
+//  1. Declared here (or beside its emitter — eventlog.go's task.* / issue.*
+//     block, alertstate.go's alert.* — for locality)
 //  2. Added to KnownEventTypes below
 //  3. Covered by TestKnownEventTypes_CoversEmittedSet in schema_test.go
 //
@@ -32,6 +33,9 @@ package observability
 //	stage.override         initiative_id, from, to, reason
 //	config.task_context_synced  task_id, trigger
 //	serena.effectiveness_recorded  verdict, score, used_for, beat, friction, task_id?
+//	alert.fired            id, key, type, severity, message, task_id?, rule?
+//	alert.resolved         id, key, type, severity, fired_at, duration, task_id?, rule?
+//	mcp.request            client, scope, method, outcome, target?, error? (unauthorized: method, outcome, remote)
 //
 // The five task.* and agent.* consts marked as emissions in
 // internal/core/taskmanager.go + internal/cli/task_runwith.go were
@@ -82,6 +86,15 @@ const (
 	// beat, friction, task_id (optional). The `adb serena report` rollup reads
 	// these back from the event log — there is no separate store.
 	EventSerenaEffectivenessRecorded EventType = "serena.effectiveness_recorded"
+
+	// EventMCPRequest is emitted by the MCP server's HTTP transport
+	// (`adb mcp serve --http`) once per authenticated request, so a shared
+	// workspace keeps a record of which token did what. Payload: client (the
+	// token name), scope, method, target (tool name, resource URI or prompt
+	// name, when the method has one), outcome (ok|error), error. A rejected
+	// request is logged with outcome "unauthorized", the HTTP method + path and
+	// the remote address instead.
+	EventMCPRequest EventType = "mcp.request"
 )
 
 // KnownEventTypes is the authoritative set of every EventType adb emits or
@@ -117,6 +130,11 @@ var KnownEventTypes = []EventType{
 	EventConfigTaskContextSynced,
 	// serena effectiveness telemetry (#203)
 	EventSerenaEffectivenessRecorded,
+	// alert lifecycle (alertstate.go)
+	EventAlertFired,
+	EventAlertResolved,
+	// MCP HTTP request log (mcpserver/http.go)
+	EventMCPRequest,
 }
 
 // IsKnownEventType reports whether e is part of the documented schema.
@@ -36,6 +36,11 @@ func TestKnownEventTypes_CoversEmittedSet(t *testing.T) {
 		EventConfigTaskContextSynced,
 		// serena effectiveness telemetry (`adb serena record`, #203)
 		EventSerenaEffectivenessRecorded,
+		// alert lifecycle (internal/observability/alertstate.go: AlertTracker.Track)
+		EventAlertFired,
+		EventAlertResolved,
+		// MCP HTTP request log (internal/mcpserver/http.go)
+		EventMCPRequest,
 	}
 	for _, e := range emitted {
 		if !IsKnownEventType(e) {
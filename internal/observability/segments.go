@@ -0,0 +1,772 @@
+package observability
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/lockfile"
+)
+
+// Segment layout
+//
+// The event log is a set of JSONL segment files sharing one base path:
+//
+//	events.jsonl       the active segment; Log appends here
+//	events.jsonl.7     sealed segments (any numeric suffix, including the
+//	events.jsonl.8     .1/.2/.3 files the old events-rotate job produced)
+//	events.jsonl.idx   sidecar index: per-segment time range + type bitmap
+//	events.jsonl.lock  cross-process lock: exclusive for sealing and
+//	                   compaction, shared for reading
+//
+// When the active segment reaches the segment size, Log seals it by renaming
+// it to the next free suffix and starting a fresh file, so the VS Code tail
+// fallback keeps reading the same path. Readers order segments by their
+// earliest timestamp, not by name, and use the index to open only the
+// segments that can hold a matching event.
+//
+// The index is a cache: it is rebuilt for any segment whose head bytes no
+// longer match (renamed over, truncated) and extended incrementally for a
+// segment that grew, so a lost or stale .idx only costs one full scan.
+
+// DefaultSegmentSize is the size at which Log seals the active segment.
+const DefaultSegmentSize int64 = 8 << 20
+
+const (
+	segmentIndexVersion = 1
+	segmentHeadBytes    = 256
+)
+
+// EventLogOption configures an EventLog.
+type EventLogOption func(*EventLog)
+
+// WithSegmentSize sets the size at which the active segment is sealed. Zero
+// or negative disables automatic sealing (Seal and Compact still work).
+func WithSegmentSize(n int64) EventLogOption {
+	return func(el *EventLog) { el.segmentSize = n }
+}
+
+// segmentIndex is the on-disk sidecar index.
+type segmentIndex struct {
+	Version int `json:"version"`
+	// Types is the bitmap dictionary: bit i of a segment's TypeBits means
+	// the segment holds at least one event of Types[i].
+	Types    []EventType    `json:"types"`
+	Segments []*segmentMeta `json:"segments"`
+}
+
+// segmentMeta summarises one segment file.
+type segmentMeta struct {
+	Name string `json:"name"`
+	// Size is how many bytes (whole lines only) have been indexed.
+	Size int64 `json:"size"`
+	// Head is a hash of the first HeadLen bytes: the segment's identity,
+	// so an entry never applies to a different file renamed into place.
+	Head     string    `json:"head"`
+	HeadLen  int       `json:"head_len"`
+	Count    int       `json:"count"`
+	Min      time.Time `json:"min,omitempty"`
+	Max      time.Time `json:"max,omitempty"`
+	TypeBits []uint64  `json:"type_bits,omitempty"`
+}
+
+// SegmentInfo describes one segment of the event log.
+type SegmentInfo struct {
+	Path   string      `json:"path"`
+	Active bool        `json:"active"`
+	Bytes  int64       `json:"bytes"`
+	Events int         `json:"events"`
+	From   time.Time   `json:"from,omitempty"`
+	To     time.Time   `json:"to,omitempty"`
+	Types  []EventType `json:"types"`
+}
+
+func (m *segmentMeta) overlaps(from, to time.Time) bool {
+	if m.Count == 0 {
+		return false
+	}
+	if !from.IsZero() && m.Max.Before(from) {
+		return false
+	}
+	if !to.IsZero() && m.Min.After(to) {
+		return false
+	}
+	return true
+}
+
+// hasAny reports whether the segment holds any type in mask (nil: any).
+func (m *segmentMeta) hasAny(mask []uint64) bool {
+	if mask == nil {
+		return true
+	}
+	for i, w := range mask {
+		if i < len(m.TypeBits) && m.TypeBits[i]&w != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *segmentMeta) hasBit(bit int) bool {
+	return bit/64 < len(m.TypeBits) && m.TypeBits[bit/64]&(1<<(bit%64)) != 0
+}
+
+func (m *segmentMeta) setBit(bit int) {
+	for len(m.TypeBits) <= bit/64 {
+		m.TypeBits = append(m.TypeBits, 0)
+	}
+	m.TypeBits[bit/64] |= 1 << (bit % 64)
+}
+
+// typeBit returns the dictionary position of t, adding it if new.
+func (idx *segmentIndex) typeBit(t EventType) int {
+	for i, known := range idx.Types {
+		if known == t {
+			return i
+		}
+	}
+	idx.Types = append(idx.Types, t)
+	return len(idx.Types) - 1
+}
+
+// mask builds the bitmap of types. It returns nil for "no type filter" and
+// an empty (all-zero) mask when none of the types has ever been logged.
+func (idx *segmentIndex) mask(types []EventType) []uint64 {
+	if len(types) == 0 {
+		return nil
+	}
+	mask := []uint64{}
+	for _, t := range types {
+		for i, known := range idx.Types {
+			if known == t {
+				for len(mask) <= i/64 {
+					mask = append(mask, 0)
+				}
+				mask[i/64] |= 1 << (i % 64)
+			}
+		}
+	}
+	return mask
+}
+
+func (el *EventLog) indexPath() string { return el.filePath + ".idx" }
+func (el *EventLog) lockPath() string  { return el.filePath + ".lock" }
+
+// segmentFiles lists the sealed segments (numeric suffix) followed by the
+// active segment, keyed by their sequence number (active: -1).
+func (el *EventLog) segmentFiles() (map[string]int, error) {
+	files := make(map[string]int)
+	matches, err := filepath.Glob(el.filePath + ".*")
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		if seq, ok := el.segmentSeq(m); ok {
+			files[m] = seq
+		}
+	}
+	if _, err := os.Stat(el.filePath); err == nil {
+		files[el.filePath] = -1
+	}
+	return files, nil
+}
+
+// segmentSeq parses the numeric suffix of a sealed segment path.
+func (el *EventLog) segmentSeq(path string) (int, bool) {
+	suffix := strings.TrimPrefix(path, el.filePath+".")
+	if suffix == path || suffix == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(suffix)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// loadIndex reads the sidecar index, starting empty when it is missing,
+// unreadable or from another version.
+func (el *EventLog) loadIndex() *segmentIndex {
+	idx := &segmentIndex{Version: segmentIndexVersion}
+	data, err := os.ReadFile(el.indexPath())
+	if err != nil {
+		return idx
+	}
+	var onDisk segmentIndex
+	if json.Unmarshal(data, &onDisk) != nil || onDisk.Version != segmentIndexVersion {
+		return idx
+	}
+	return &onDisk
+}
+
+// saveIndex writes the index atomically. Failures are ignored: the index is
+// a cache and the next reader rebuilds what it needs.
+func (el *EventLog) saveIndex(idx *segmentIndex) {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return
+	}
+	// A private temp file: readers holding the shared lock save concurrently.
+	tmp, err := os.CreateTemp(filepath.Dir(el.indexPath()), filepath.Base(el.indexPath())+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(data)
+	if cerr := tmp.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil || os.Rename(tmp.Name(), el.indexPath()) != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// refreshIndex brings the index up to date with the segment files and
+// returns their metadata in read order (oldest first). Caller holds el.mu.
+func (el *EventLog) refreshIndex() ([]*segmentMeta, error) {
+	files, err := el.segmentFiles()
+	if err != nil {
+		return nil, err
+	}
+	idx := el.loadIndex()
+	byName := make(map[string]*segmentMeta, len(idx.Segments))
+	for _, m := range idx.Segments {
+		byName[m.Name] = m
+	}
+
+	dirty := len(files) != len(idx.Segments)
+	metas := make([]*segmentMeta, 0, len(files))
+	for path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // sealed or compacted away under us
+			}
+			return nil, err
+		}
+		name := filepath.Base(path)
+		m := byName[name]
+		if m == nil || info.Size() < m.Size || !headMatches(path, m) {
+			m = &segmentMeta{Name: name}
+			dirty = true
+		}
+		if info.Size() > m.Size {
+			if err := scanSegment(path, m, idx); err != nil {
+				return nil, err
+			}
+			dirty = true
+		}
+		metas = append(metas, m)
+	}
+
+	seq := func(m *segmentMeta) int {
+		if n, ok := files[filepath.Join(filepath.Dir(el.filePath), m.Name)]; ok && n >= 0 {
+			return n
+		}
+		return math.MaxInt // the active segment sorts last
+	}
+	sort.Slice(metas, func(i, j int) bool {
+		a, b := metas[i], metas[j]
+		if (a.Count == 0) != (b.Count == 0) {
+			return b.Count == 0
+		}
+		if !a.Min.Equal(b.Min) {
+			return a.Min.Before(b.Min)
+		}
+		return seq(a) < seq(b)
+	})
+	idx.Segments = metas
+	if dirty {
+		el.saveIndex(idx)
+	}
+	el.index = idx
+	return metas, nil
+}
+
+// headMatches reports whether path still starts with the bytes m indexed.
+func headMatches(path string, m *segmentMeta) bool {
+	if m.Size == 0 {
+		return true
+	}
+	head, err := readHead(path, m.HeadLen)
+	return err == nil && head == m.Head
+}
+
+func readHead(path string, n int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// scanSegment indexes the whole lines of path past m.Size.
+func scanSegment(path string, m *segmentMeta, idx *segmentIndex) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open event log segment: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(m.Size, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek event log segment: %w", err)
+	}
+
+	var stamp struct {
+		Timestamp time.Time `json:"timestamp"`
+		Type      EventType `json:"type"`
+	}
+	offset := m.Size
+	err = forEachLine(f, func(line []byte) {
+		offset += int64(len(line))
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			return
+		}
+		stamp.Timestamp, stamp.Type = time.Time{}, ""
+		if json.Unmarshal(line, &stamp) != nil {
+			return // malformed: readers skip it too
+		}
+		if m.Count == 0 || stamp.Timestamp.Before(m.Min) {
+			m.Min = stamp.Timestamp
+		}
+		if m.Count == 0 || stamp.Timestamp.After(m.Max) {
+			m.Max = stamp.Timestamp
+		}
+		m.Count++
+		m.setBit(idx.typeBit(stamp.Type))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index event log segment: %w", err)
+	}
+	m.Size = offset
+	if m.HeadLen < segmentHeadBytes && m.Size > int64(m.HeadLen) {
+		m.HeadLen = int(min(m.Size, segmentHeadBytes))
+		if m.Head, err = readHead(path, m.HeadLen); err != nil {
+			return fmt.Errorf("failed to index event log segment: %w", err)
+		}
+	}
+	return nil
+}
+
+// forEachLine calls fn with every line of r (newline included). A trailing
+// line without a newline is passed only when it is already valid JSON (a
+// hand-written file); otherwise it is an append in flight, left for the next
+// read.
+func forEachLine(r io.Reader, fn func(line []byte)) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	for {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 && json.Valid(bytes.TrimSpace(line)) {
+				fn(line)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fn(line)
+	}
+}
+
+// ReadRange returns the events with from <= Timestamp <= to whose type is
+// one of types, oldest segment first. Zero from/to leave that end open and
+// no types means every type. Only segments whose indexed time range and
+// type bitmap can match are opened.
+func (el *EventLog) ReadRange(from, to time.Time, types ...EventType) ([]Event, error) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	var events []Event
+	err := el.withReadLock(func() error {
+		var err error
+		events, err = el.readRangeLocked(from, to, types)
+		return err
+	})
+	return events, err
+}
+
+// readRangeLocked is ReadRange. Caller holds el.mu and the read lock, so the
+// segments the index lists stay in place until they are read.
+func (el *EventLog) readRangeLocked(from, to time.Time, types []EventType) ([]Event, error) {
+	metas, err := el.refreshIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to index event log: %w", err)
+	}
+	mask := el.index.mask(types)
+	want := make(map[EventType]bool, len(types))
+	for _, t := range types {
+		want[t] = true
+	}
+
+	events := []Event{}
+	dir := filepath.Dir(el.filePath)
+	for _, m := range metas {
+		if !m.overlaps(from, to) || !m.hasAny(mask) {
+			continue
+		}
+		path := filepath.Join(dir, m.Name)
+		f, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to open event log: %w", err)
+		}
+		lineNum := 0
+		err = forEachLine(io.LimitReader(f, m.Size), func(line []byte) {
+			lineNum++
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				return
+			}
+			var event Event
+			if err := json.Unmarshal(line, &event); err != nil {
+				// Gracefully skip malformed lines (log warning but continue)
+				fmt.Fprintf(os.Stderr, "Warning: skipping malformed event at %s line %d: %v\n", m.Name, lineNum, err)
+				return
+			}
+			if len(want) > 0 && !want[event.Type] {
+				return
+			}
+			if (!from.IsZero() && event.Timestamp.Before(from)) || (!to.IsZero() && event.Timestamp.After(to)) {
+				return
+			}
+			events = append(events, event)
+		})
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event log: %w", err)
+		}
+	}
+	return events, nil
+}
+
+// Segments describes the log's segments, oldest first.
+func (el *EventLog) Segments() ([]SegmentInfo, error) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	var metas []*segmentMeta
+	err := el.withReadLock(func() error {
+		var err error
+		metas, err = el.refreshIndex()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SegmentInfo, 0, len(metas))
+	for _, m := range metas {
+		info := SegmentInfo{
+			Path:   filepath.Join(filepath.Dir(el.filePath), m.Name),
+			Bytes:  m.Size,
+			Events: m.Count,
+			From:   m.Min,
+			To:     m.Max,
+			Types:  []EventType{},
+		}
+		info.Active = info.Path == el.filePath
+		for i, t := range el.index.Types {
+			if m.hasBit(i) {
+				info.Types = append(info.Types, t)
+			}
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+// withFileLock runs fn holding the cross-process segment lock exclusively.
+// Caller holds el.mu.
+func (el *EventLog) withFileLock(fn func() error) error {
+	return el.withLock(lockfile.Lock, fn)
+}
+
+// withReadLock runs fn holding the cross-process segment lock shared, so no
+// other process seals or compacts the segments fn reads. Without a log
+// directory there is nothing to seal, and fn runs unlocked. Caller holds
+// el.mu.
+func (el *EventLog) withReadLock(fn func() error) error {
+	if _, err := os.Stat(filepath.Dir(el.lockPath())); err != nil {
+		return fn()
+	}
+	return el.withLock(lockfile.LockShared, fn)
+}
+
+func (el *EventLog) withLock(lock func(*os.File) (func(), error), fn func() error) error {
+	lf, err := os.OpenFile(el.lockPath(), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("open event log lock: %w", err)
+	}
+	defer lf.Close()
+	release, err := lock(lf)
+	if err != nil {
+		return fmt.Errorf("lock event log: %w", err)
+	}
+	defer release()
+	return fn()
+}
+
+// nextSegmentPath picks the sealed-segment name after the highest in use.
+func (el *EventLog) nextSegmentPath() (string, error) {
+	files, err := el.segmentFiles()
+	if err != nil {
+		return "", err
+	}
+	next := 1
+	for _, seq := range files {
+		if seq >= next {
+			next = seq + 1
+		}
+	}
+	return fmt.Sprintf("%s.%d", el.filePath, next), nil
+}
+
+// Seal closes the active segment: it is renamed to the next sealed-segment
+// name and an empty active segment takes its place. An empty active segment
+// is left alone.
+func (el *EventLog) Seal() error {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	return el.sealLocked(0)
+}
+
+// sealLocked seals the active segment if it holds at least minSize bytes
+// (re-checked under the file lock, since another process may have sealed
+// it first). Caller holds el.mu.
+func (el *EventLog) sealLocked(minSize int64) error {
+	return el.withFileLock(func() error {
+		info, err := os.Stat(el.filePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.Size() == 0 || info.Size() < minSize {
+			return nil
+		}
+		dst, err := el.nextSegmentPath()
+		if err != nil {
+			return err
+		}
+		if err := os.Rename(el.filePath, dst); err != nil {
+			return fmt.Errorf("seal event log segment: %w", err)
+		}
+		f, err := os.OpenFile(el.filePath, os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("recreate event log: %w", err)
+		}
+		return f.Close()
+	})
+}
+
+// CompactOptions tunes Compact.
+type CompactOptions struct {
+	// DropBefore discards events older than this instant. Zero keeps all.
+	DropBefore time.Time
+	// DryRun reports what would change without touching any file.
+	DryRun bool
+}
+
+// CompactResult reports what Compact did (or would do).
+type CompactResult struct {
+	SegmentsBefore int   `json:"segments_before"`
+	SegmentsAfter  int   `json:"segments_after"`
+	BytesBefore    int64 `json:"bytes_before"`
+	BytesAfter     int64 `json:"bytes_after"`
+	Dropped        int   `json:"dropped"`
+	Malformed      int   `json:"malformed"`
+	Duplicates     int   `json:"duplicates"`
+}
+
+// Compact rewrites the sealed segments: runs of small segments are merged
+// up to the segment size, malformed lines and exact duplicate lines are
+// removed, and events older than DropBefore are discarded. The active
+// segment is never touched.
+//
+// Each merged run is written to a temp file and renamed over the run's first
+// segment before the rest are removed, so a crash part-way leaves duplicate
+// lines rather than lost events; the next Compact removes them.
+func (el *EventLog) Compact(opts CompactOptions) (CompactResult, error) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	var res CompactResult
+	err := el.withFileLock(func() error {
+		metas, err := el.refreshIndex()
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(el.filePath)
+		var sealed []*segmentMeta
+		for _, m := range metas {
+			if filepath.Join(dir, m.Name) != el.filePath {
+				sealed = append(sealed, m)
+			}
+		}
+		res.SegmentsBefore = len(sealed)
+		for _, m := range sealed {
+			res.BytesBefore += m.Size
+		}
+
+		target := el.segmentSize
+		if target <= 0 {
+			target = DefaultSegmentSize
+		}
+		// Group consecutive (time-ordered) segments into runs up to target.
+		var runs [][]*segmentMeta
+		var run []*segmentMeta
+		var runSize int64
+		for _, m := range sealed {
+			if len(run) > 0 && runSize+m.Size > target {
+				runs = append(runs, run)
+				run, runSize = nil, 0
+			}
+			run = append(run, m)
+			runSize += m.Size
+		}
+		if len(run) > 0 {
+			runs = append(runs, run)
+		}
+
+		// Duplicates left by an interrupted compaction sit in adjacent runs,
+		// so hashes are kept for the current and previous run only.
+		var prev map[[sha256.Size]byte]bool
+		var rewritten []string
+		defer func() { el.forgetSegments(rewritten) }()
+		for _, run := range runs {
+			seen := make(map[[sha256.Size]byte]bool)
+			size, kept, err := el.compactRun(dir, run, opts, prev, seen, &res)
+			prev = seen
+			if !opts.DryRun {
+				for _, m := range run {
+					rewritten = append(rewritten, m.Name)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			res.BytesAfter += size
+			if kept {
+				res.SegmentsAfter++
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+// forgetSegments drops index entries for rewritten segments: a merged file
+// can keep its old head bytes while its earlier lines changed, so it must be
+// rescanned from the start rather than extended.
+func (el *EventLog) forgetSegments(names []string) {
+	if len(names) == 0 {
+		return
+	}
+	drop := make(map[string]bool, len(names))
+	for _, n := range names {
+		drop[n] = true
+	}
+	idx := el.loadIndex()
+	kept := idx.Segments[:0]
+	for _, m := range idx.Segments {
+		if !drop[m.Name] {
+			kept = append(kept, m)
+		}
+	}
+	idx.Segments = kept
+	el.saveIndex(idx)
+}
+
+// compactRun merges one run of segments into its first file. It returns the
+// merged size and whether any segment file remains.
+func (el *EventLog) compactRun(dir string, run []*segmentMeta, opts CompactOptions, prev, seen map[[sha256.Size]byte]bool, res *CompactResult) (int64, bool, error) {
+	var buf bytes.Buffer
+	changed := len(run) > 1
+	for _, m := range run {
+		f, err := os.Open(filepath.Join(dir, m.Name))
+		if err != nil {
+			return 0, false, fmt.Errorf("open segment %s: %w", m.Name, err)
+		}
+		err = forEachLine(io.LimitReader(f, m.Size), func(line []byte) {
+			trimmed := bytes.TrimSpace(line)
+			if len(trimmed) == 0 {
+				changed = true
+				return
+			}
+			var stamp struct {
+				Timestamp time.Time `json:"timestamp"`
+			}
+			if json.Unmarshal(trimmed, &stamp) != nil {
+				res.Malformed++
+				changed = true
+				return
+			}
+			if !opts.DropBefore.IsZero() && stamp.Timestamp.Before(opts.DropBefore) {
+				res.Dropped++
+				changed = true
+				return
+			}
+			sum := sha256.Sum256(trimmed)
+			if seen[sum] || prev[sum] {
+				res.Duplicates++
+				changed = true
+				return
+			}
+			seen[sum] = true
+			buf.Write(trimmed)
+			buf.WriteByte('\n')
+		})
+		f.Close()
+		if err != nil {
+			return 0, false, fmt.Errorf("read segment %s: %w", m.Name, err)
+		}
+	}
+
+	if !changed || opts.DryRun {
+		return int64(buf.Len()), buf.Len() > 0 || !changed, nil
+	}
+
+	first := filepath.Join(dir, run[0].Name)
+	if buf.Len() == 0 {
+		for _, m := range run {
+			if err := os.Remove(filepath.Join(dir, m.Name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return 0, false, fmt.Errorf("remove segment %s: %w", m.Name, err)
+			}
+		}
+		return 0, false, nil
+	}
+	tmp := first + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return 0, false, fmt.Errorf("write compacted segment: %w", err)
+	}
+	if err := os.Rename(tmp, first); err != nil {
+		_ = os.Remove(tmp)
+		return 0, false, fmt.Errorf("replace segment %s: %w", run[0].Name, err)
+	}
+	for _, m := range run[1:] {
+		if err := os.Remove(filepath.Join(dir, m.Name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, false, fmt.Errorf("remove segment %s: %w", m.Name, err)
+		}
+	}
+	return int64(buf.Len()), true, nil
+}
@@ -0,0 +1,243 @@
+package observability
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var segT0 = time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// newSegmentedLog logs n events an hour apart, alternating task.created and
+// every tenth one an agent.session_started, into segments of ~1 KiB.
+func newSegmentedLog(t *testing.T, n int) *EventLog {
+	t.Helper()
+	el := NewEventLog(filepath.Join(t.TempDir(), "events.jsonl"), WithSegmentSize(1024))
+	for i := 0; i < n; i++ {
+		typ := EventTaskCreated
+		if i%10 == 9 {
+			typ = EventAgentSessionStarted
+		}
+		e := Event{Timestamp: segT0.Add(time.Duration(i) * time.Hour), Type: typ, Data: map[string]interface{}{"task_id": fmt.Sprintf("TASK-%05d", i)}}
+		if err := el.appendEvent(e); err != nil {
+			t.Fatalf("appendEvent: %v", err)
+		}
+	}
+	return el
+}
+
+func TestEventLog_SealsSegmentsAndReadsThemAll(t *testing.T) {
+	el := newSegmentedLog(t, 100)
+
+	segs, err := el.Segments()
+	if err != nil {
+		t.Fatalf("Segments: %v", err)
+	}
+	if len(segs) < 5 {
+		t.Fatalf("got %d segments, want the 1 KiB size to force several", len(segs))
+	}
+	if !segs[len(segs)-1].Active {
+		t.Error("active segment should sort last")
+	}
+
+	all, err := el.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(all) != 100 {
+		t.Fatalf("ReadAll = %d events, want 100", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].Timestamp.Before(all[i-1].Timestamp) {
+			t.Fatalf("events out of order at %d", i)
+		}
+	}
+}
+
+func TestEventLog_ReadRangeAndType(t *testing.T) {
+	el := newSegmentedLog(t, 100)
+
+	got, err := el.ReadRange(segT0.Add(40*time.Hour), segT0.Add(49*time.Hour))
+	if err != nil {
+		t.Fatalf("ReadRange: %v", err)
+	}
+	if len(got) != 10 || got[0].Data["task_id"] != "TASK-00040" {
+		t.Fatalf("ReadRange = %d events starting %v", len(got), got[0].Data)
+	}
+
+	since, _ := el.ReadSince(segT0.Add(95 * time.Hour))
+	if len(since) != 5 {
+		t.Errorf("ReadSince = %d, want 5", len(since))
+	}
+
+	sessions, _ := el.ReadByType(EventAgentSessionStarted)
+	if len(sessions) != 10 {
+		t.Errorf("ReadByType = %d, want 10", len(sessions))
+	}
+	none, err := el.ReadByType(EventWorktreeRemoved)
+	if err != nil || len(none) != 0 {
+		t.Errorf("never-logged type = %d events, err %v", len(none), err)
+	}
+}
+
+func TestEventLog_IndexFollowsRenamedSegments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.jsonl")
+	write := func(name string, hours ...int) {
+		var b strings.Builder
+		for _, h := range hours {
+			fmt.Fprintf(&b, `{"timestamp":%q,"type":"task.created","data":{"n":%d}}`+"\n",
+				segT0.Add(time.Duration(h)*time.Hour).Format(time.RFC3339Nano), h)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(b.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Legacy events-rotate layout: .1 is the newest rotation.
+	write("events.jsonl.2", 0, 1)
+	write("events.jsonl.1", 2, 3)
+	write("events.jsonl", 4)
+	el := NewEventLog(path)
+	if all, _ := el.ReadAll(); len(all) != 5 || all[0].Data["n"] != float64(0) {
+		t.Fatalf("initial read = %+v", all)
+	}
+
+	// Rotate the old way: .1 → .2, active → .1, fresh active.
+	if err := os.Rename(filepath.Join(dir, "events.jsonl.1"), filepath.Join(dir, "events.jsonl.2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path, filepath.Join(dir, "events.jsonl.1")); err != nil {
+		t.Fatal(err)
+	}
+	write("events.jsonl", 5, 6)
+
+	all, err := el.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	var ns []float64
+	for _, e := range all {
+		ns = append(ns, e.Data["n"].(float64))
+	}
+	if fmt.Sprint(ns) != "[2 3 4 5 6]" {
+		t.Errorf("after rename read %v, want [2 3 4 5 6]", ns)
+	}
+}
+
+func TestEventLog_CorruptIndexIsRebuilt(t *testing.T) {
+	el := newSegmentedLog(t, 30)
+	if err := os.WriteFile(el.indexPath(), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	all, err := el.ReadAll()
+	if err != nil || len(all) != 30 {
+		t.Fatalf("ReadAll with corrupt index = %d, %v", len(all), err)
+	}
+}
+
+func TestEventLog_Compact(t *testing.T) {
+	el := newSegmentedLog(t, 100)
+	if err := el.Seal(); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	// An interrupted compaction can leave a duplicated segment; a hand edit
+	// can leave garbage.
+	segs, _ := el.Segments()
+	data, _ := os.ReadFile(segs[0].Path)
+	if err := os.WriteFile(el.filePath+".900", append(data, []byte("garbage\n")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	el.segmentSize = 4096
+	dry, err := el.Compact(CompactOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Compact dry run: %v", err)
+	}
+	if after, _ := el.Segments(); len(after) != len(segs)+1 {
+		t.Fatalf("dry run changed segments: %d → %d", len(segs)+1, len(after))
+	}
+
+	res, err := el.Compact(CompactOptions{})
+	if err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if res.SegmentsAfter != dry.SegmentsAfter || res.Duplicates != dry.Duplicates {
+		t.Errorf("dry run %+v disagrees with real run %+v", dry, res)
+	}
+	if res.Malformed != 1 || res.Duplicates != segs[0].Events || res.SegmentsAfter >= res.SegmentsBefore {
+		t.Errorf("result = %+v, want 1 malformed and %d duplicates", res, segs[0].Events)
+	}
+	if all, _ := el.ReadAll(); len(all) != 100 {
+		t.Fatalf("after dedupe %d events, want 100", len(all))
+	}
+
+	res, err = el.Compact(CompactOptions{DropBefore: segT0.Add(10 * time.Hour)})
+	if err != nil || res.Dropped != 10 {
+		t.Fatalf("Compact with DropBefore = %+v, %v", res, err)
+	}
+	all, _ := el.ReadAll()
+	if len(all) != 90 || all[0].Data["task_id"] != "TASK-00010" {
+		t.Fatalf("after compaction %d events starting %v", len(all), all[0].Data)
+	}
+	sessions, _ := el.ReadByType(EventAgentSessionStarted)
+	if len(sessions) != 9 {
+		t.Errorf("sessions after compaction = %d, want 9", len(sessions))
+	}
+}
+
+func TestEventLog_ClearRemovesSegments(t *testing.T) {
+	el := newSegmentedLog(t, 40)
+	if err := el.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	matches, _ := filepath.Glob(el.filePath + "*")
+	for _, m := range matches {
+		if !strings.HasSuffix(m, ".lock") {
+			t.Errorf("left behind %s", m)
+		}
+	}
+	if all, _ := el.ReadAll(); len(all) != 0 {
+		t.Errorf("ReadAll after Clear = %d", len(all))
+	}
+}
+
+func TestEventLog_SealWaitsForReaders(t *testing.T) {
+	reader := newSegmentedLog(t, 30)
+	// A second EventLog on the same path stands in for another adb process:
+	// it shares only the file lock.
+	sealer := NewEventLog(reader.filePath, WithSegmentSize(1024))
+
+	reader.mu.Lock()
+	var sealed chan error
+	err := reader.withReadLock(func() error {
+		if _, err := reader.refreshIndex(); err != nil {
+			return err
+		}
+		sealed = make(chan error, 1)
+		go func() { sealed <- sealer.Seal() }()
+		select {
+		case err := <-sealed:
+			t.Errorf("active segment sealed between index refresh and read: %v", err)
+			sealed <- err
+		case <-time.After(100 * time.Millisecond):
+		}
+		events, err := reader.readRangeLocked(time.Time{}, time.Time{}, nil)
+		if len(events) != 30 {
+			t.Errorf("read %d events during a seal, want 30", len(events))
+		}
+		return err
+	})
+	reader.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-sealed; err != nil {
+		t.Fatalf("Seal after the read: %v", err)
+	}
+	if all, err := reader.ReadAll(); err != nil || len(all) != 30 {
+		t.Errorf("ReadAll after the seal = %d events, %v", len(all), err)
+	}
+}
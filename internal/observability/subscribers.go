@@ -0,0 +1,191 @@
+package observability
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventHandler receives an event Log has appended.
+type EventHandler func(Event)
+
+// subscriber is one registered handler. A nil queue means synchronous
+// delivery.
+type subscriber struct {
+	id      int
+	handler EventHandler
+	queue   *eventQueue
+}
+
+// Subscribe registers h to run synchronously for every event Log appends:
+// Log returns only once h has, so a short-lived process cannot exit before
+// the handler ran. Handlers run in registration order after the event is
+// written and the log's lock is released, so a handler may itself Log. An
+// event that could not be written (or a disabled log) is not delivered. The
+// returned func unsubscribes.
+func (el *EventLog) Subscribe(h EventHandler) func() {
+	return el.addSubscriber(&subscriber{handler: h})
+}
+
+// SubscribeAsync registers h to run on its own goroutine, in log order, so
+// Log never waits on it. Flush waits for queued events to be handled; the
+// returned func unsubscribes once the queue is drained.
+func (el *EventLog) SubscribeAsync(h EventHandler) func() {
+	q := newEventQueue()
+	s := &subscriber{handler: h, queue: q}
+	go q.run(h)
+	return el.addSubscriber(s)
+}
+
+func (el *EventLog) addSubscriber(s *subscriber) func() {
+	el.subMu.Lock()
+	el.nextSub++
+	s.id = el.nextSub
+	el.subs = append(el.subs, s)
+	el.subMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			el.subMu.Lock()
+			for i, cur := range el.subs {
+				if cur.id == s.id {
+					el.subs = append(el.subs[:i:i], el.subs[i+1:]...)
+					break
+				}
+			}
+			el.subMu.Unlock()
+			if s.queue != nil {
+				s.queue.close()
+			}
+		})
+	}
+}
+
+// Flush blocks until every asynchronous subscriber has handled the events
+// queued for it so far. App.Cleanup calls it so a command's events are not
+// dropped when the process exits.
+func (el *EventLog) Flush() {
+	el.subMu.Lock()
+	subs := append([]*subscriber(nil), el.subs...)
+	el.subMu.Unlock()
+	for _, s := range subs {
+		if s.queue != nil {
+			s.queue.wait()
+		}
+	}
+}
+
+// publish delivers a written JSONL line to the subscribers. The event is
+// decoded from the line rather than passed through, so a subscriber sees
+// exactly what a later reader of the log will (JSON numbers as float64, and
+// so on) and Event.ID agrees between the two.
+func (el *EventLog) publish(line []byte) {
+	el.subMu.Lock()
+	subs := append([]*subscriber(nil), el.subs...)
+	el.subMu.Unlock()
+	if len(subs) == 0 {
+		return
+	}
+	var ev Event
+	if err := json.Unmarshal(line, &ev); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to decode event for subscribers: %v\n", err)
+		return
+	}
+	for _, s := range subs {
+		if s.queue != nil {
+			s.queue.push(ev)
+			continue
+		}
+		deliver(s.handler, ev)
+	}
+}
+
+// deliver runs one handler, containing a panic so a faulty subscriber cannot
+// take down the caller of Log.
+func deliver(h EventHandler, ev Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "Warning: event subscriber panicked on %s: %v\n", ev.Type, r)
+		}
+	}()
+	h(ev)
+}
+
+// ID identifies an event by content: a hash of its timestamp, type and data
+// as they read back from the log. Two events are only confused if they were
+// logged at the same instant with the same type and data.
+func (e Event) ID() string {
+	line, err := json.Marshal(e)
+	if err != nil {
+		line = []byte(fmt.Sprintf("%s|%s|%v", e.Timestamp.UTC().Format(time.RFC3339Nano), e.Type, e.Data))
+	}
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:16])
+}
+
+// eventQueue is an asynchronous subscriber's unbounded FIFO.
+type eventQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	events []Event
+	busy   bool
+	closed bool
+}
+
+func newEventQueue() *eventQueue {
+	q := &eventQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *eventQueue) push(ev Event) {
+	q.mu.Lock()
+	if !q.closed {
+		q.events = append(q.events, ev)
+		q.cond.Broadcast()
+	}
+	q.mu.Unlock()
+}
+
+// run hands queued events to h until the queue is closed and empty.
+func (q *eventQueue) run(h EventHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		for len(q.events) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.events) == 0 {
+			return
+		}
+		ev := q.events[0]
+		q.events = q.events[1:]
+		q.busy = true
+		q.mu.Unlock()
+		deliver(h, ev)
+		q.mu.Lock()
+		q.busy = false
+		q.cond.Broadcast()
+	}
+}
+
+// wait blocks until the queue is empty and no event is being handled.
+func (q *eventQueue) wait() {
+	q.mu.Lock()
+	for len(q.events) > 0 || q.busy {
+		q.cond.Wait()
+	}
+	q.mu.Unlock()
+}
+
+func (q *eventQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+}
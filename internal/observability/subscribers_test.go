@@ -0,0 +1,94 @@
+package observability
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestEventLog_Subscribe_Sync(t *testing.T) {
+	el := NewEventLog(filepath.Join(t.TempDir(), "events.jsonl"))
+	var got []Event
+	unsubscribe := el.Subscribe(func(ev Event) {
+		got = append(got, ev)
+		if ev.Type == EventTaskCreated {
+			// A handler may log: the log's lock is not held during delivery.
+			el.Log(EventTaskStatusChanged, map[string]interface{}{"task_id": ev.Data["task_id"]})
+		}
+	})
+
+	el.Log(EventTaskCreated, map[string]interface{}{"task_id": "TASK-1", "n": 3})
+	if len(got) != 2 || got[0].Type != EventTaskCreated || got[1].Type != EventTaskStatusChanged {
+		t.Fatalf("delivered %+v, want task.created then task.status_changed before Log returned", got)
+	}
+	if n, ok := got[0].Data["n"].(float64); !ok || n != 3 {
+		t.Errorf("Data[n] = %#v, want float64 3 as a reader of the log sees it", got[0].Data["n"])
+	}
+
+	events, err := el.ReadAll()
+	if err != nil || len(events) != 2 {
+		t.Fatalf("ReadAll = %d events, %v", len(events), err)
+	}
+	if events[0].ID() != got[0].ID() || events[1].ID() != got[1].ID() {
+		t.Errorf("subscriber and reader disagree on event IDs")
+	}
+	if events[0].ID() == events[1].ID() {
+		t.Errorf("distinct events share ID %s", events[0].ID())
+	}
+
+	unsubscribe()
+	el.Log(EventTaskCompleted, nil)
+	if len(got) != 2 {
+		t.Errorf("delivered %d events after unsubscribe, want 2", len(got))
+	}
+}
+
+func TestEventLog_SubscribeAsync_OrderAndFlush(t *testing.T) {
+	el := NewEventLog(filepath.Join(t.TempDir(), "events.jsonl"))
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	el.SubscribeAsync(func(ev Event) {
+		mu.Lock()
+		got = append(got, ev.Data["task_id"].(string))
+		mu.Unlock()
+	})
+	el.Subscribe(func(ev Event) {
+		if ev.Data["task_id"] == "TASK-2" {
+			panic("boom") // contained: neither Log nor other subscribers fail
+		}
+	})
+
+	for _, id := range []string{"TASK-1", "TASK-2", "TASK-3"} {
+		el.Log(EventTaskCreated, map[string]interface{}{"task_id": id})
+	}
+	el.Flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 3 || got[0] != "TASK-1" || got[1] != "TASK-2" || got[2] != "TASK-3" {
+		t.Errorf("async delivery = %v, want TASK-1..3 in log order", got)
+	}
+}
+
+func TestEventLog_Subscribe_DisabledLogDeliversNothing(t *testing.T) {
+	el := NewEventLog(filepath.Join(t.TempDir(), "missing", "events.jsonl"))
+	called := false
+	el.Subscribe(func(Event) { called = true })
+	el.Log(EventTaskCreated, nil)
+	if called {
+		t.Error("an event that was never written must not be delivered")
+	}
+}
+
+func TestEvent_Payload(t *testing.T) {
+	ev := Event{Data: map[string]interface{}{"task_id": "TASK-7", "count": float64(12)}}
+	p := ev.Payload()
+	if p["task_id"] != "TASK-7" || p["count"] != "12" {
+		t.Errorf("Payload() = %v", p)
+	}
+	if (Event{}).Payload() != nil {
+		t.Error("an event without data has a nil payload")
+	}
+}
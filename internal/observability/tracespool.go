@@ -0,0 +1,201 @@
+package observability
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/lockfile"
+)
+
+// SpanSpool queues spans on disk for a network exporter, so the process that
+// records them never waits on a collector. ExportSpans appends one OTLP/JSON
+// ExportTraceServiceRequest per line (the FileSpanExporter layout); Ship sends
+// the queued lines and is run off the hot path (a detached `adb hook
+// trace-flush`).
+//
+//	<path>            the spool hooks append to
+//	<path>.sending    the batch a shipper took, until every line is sent
+//	<path>.lock       shared by appenders, exclusive to take a batch
+//	<path>.ship.lock  serialises shippers
+//	<path>.failed     stamp of the last failed send, for backoff
+type SpanSpool struct {
+	Path    string
+	Service string
+	// MaxBytes bounds the spool while the collector is unreachable; spans
+	// past it are dropped with an error. Zero means DefaultSpoolMaxBytes.
+	MaxBytes int64
+}
+
+// DefaultSpoolMaxBytes bounds a SpanSpool by default.
+const DefaultSpoolMaxBytes int64 = 16 << 20
+
+// spoolRetryAfter is how long a shipper waits after a failed send before
+// trying the collector again: hooks spawn a shipper each, and a dead
+// collector must not queue one timeout per hook.
+const spoolRetryAfter = 30 * time.Second
+
+// NewSpanSpool returns a spool at path.
+func NewSpanSpool(path, service string) *SpanSpool {
+	return &SpanSpool{Path: path, Service: service}
+}
+
+// ExportSpans appends spans to the spool.
+func (s *SpanSpool) ExportSpans(_ context.Context, spans []Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	data, err := MarshalOTLPTraces(s.Service, spans)
+	if err != nil {
+		return fmt.Errorf("marshal spans: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return fmt.Errorf("create trace spool directory: %w", err)
+	}
+	unlock, err := lockPath(s.Path+".lock", lockfile.LockShared)
+	if err != nil {
+		return fmt.Errorf("lock trace spool: %w", err)
+	}
+	defer unlock()
+	max := s.MaxBytes
+	if max <= 0 {
+		max = DefaultSpoolMaxBytes
+	}
+	if info, err := os.Stat(s.Path); err == nil && info.Size() >= max {
+		return fmt.Errorf("trace spool %s is full (%d bytes); spans dropped until the collector is reachable", s.Path, info.Size())
+	}
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open trace spool: %w", err)
+	}
+	defer f.Close()
+	// One write per request keeps concurrent hook processes' lines whole.
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write trace spool: %w", err)
+	}
+	return nil
+}
+
+// Ship sends the spooled requests through post, oldest first, and reports
+// how many it sent. A failed send keeps that request and the rest for the
+// next Ship, which waits out spoolRetryAfter before trying again.
+func (s *SpanSpool) Ship(ctx context.Context, post func(context.Context, []byte) error) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return 0, fmt.Errorf("create trace spool directory: %w", err)
+	}
+	unlock, err := lockPath(s.Path+".ship.lock", lockfile.Lock)
+	if err != nil {
+		return 0, fmt.Errorf("lock trace spool: %w", err)
+	}
+	defer unlock()
+	if info, err := os.Stat(s.Path + ".failed"); err == nil && time.Since(info.ModTime()) < spoolRetryAfter {
+		return 0, nil
+	}
+
+	// A batch left by a failed Ship goes first; then the spool itself.
+	sent := 0
+	for round := 0; round < 2; round++ {
+		n, err := s.shipBatch(ctx, post)
+		sent += n
+		if err != nil {
+			_ = os.WriteFile(s.Path+".failed", nil, 0o644)
+			return sent, err
+		}
+	}
+	_ = os.Remove(s.Path + ".failed")
+	return sent, nil
+}
+
+// shipBatch sends the pending batch, taking the spool as the batch when none
+// is pending. Caller holds the ship lock.
+func (s *SpanSpool) shipBatch(ctx context.Context, post func(context.Context, []byte) error) (int, error) {
+	sending := s.Path + ".sending"
+	if _, err := os.Stat(sending); os.IsNotExist(err) {
+		if err := s.takeBatch(sending); err != nil {
+			return 0, err
+		}
+	}
+	data, err := os.ReadFile(sending)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read trace spool: %w", err)
+	}
+	var lines [][]byte
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		if line := bytes.TrimSpace(sc.Bytes()); len(line) > 0 {
+			lines = append(lines, append([]byte(nil), line...))
+		}
+	}
+	for i, line := range lines {
+		if err := post(ctx, line); err != nil {
+			if werr := writeSpoolLines(sending, lines[i:]); werr != nil {
+				return i, werr
+			}
+			return i, err
+		}
+	}
+	if err := os.Remove(sending); err != nil {
+		return len(lines), fmt.Errorf("clear trace spool: %w", err)
+	}
+	return len(lines), nil
+}
+
+// takeBatch moves the spool aside for sending. Appenders hold the spool lock
+// shared, so none is mid-write into the file being moved.
+func (s *SpanSpool) takeBatch(sending string) error {
+	unlock, err := lockPath(s.Path+".lock", lockfile.Lock)
+	if err != nil {
+		return fmt.Errorf("lock trace spool: %w", err)
+	}
+	defer unlock()
+	if err := os.Rename(s.Path, sending); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("take trace spool batch: %w", err)
+	}
+	return nil
+}
+
+// writeSpoolLines replaces path with lines via a temp file and rename.
+func writeSpoolLines(path string, lines [][]byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write trace spool: %w", err)
+	}
+	_, werr := tmp.Write(append(bytes.Join(lines, []byte("\n")), '\n'))
+	if cerr := tmp.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr == nil {
+		werr = os.Rename(tmp.Name(), path)
+	}
+	if werr != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write trace spool: %w", werr)
+	}
+	return nil
+}
+
+// lockPath opens the lock file at path and takes lock on it. The returned
+// release also closes the file.
+func lockPath(path string, lock func(*os.File) (func(), error)) (func(), error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	release, err := lock(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		release()
+		f.Close()
+	}, nil
+}
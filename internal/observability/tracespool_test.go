@@ -0,0 +1,69 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSpanSpool_ShipsAndKeepsWhatFailed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace_spool.jsonl")
+	spool := NewSpanSpool(path, "adb")
+	for i := 0; i < 3; i++ {
+		if err := spool.ExportSpans(context.Background(), testSpans()); err != nil {
+			t.Fatalf("ExportSpans: %v", err)
+		}
+	}
+
+	var sent int
+	down := errors.New("collector down")
+	flaky := func(_ context.Context, data []byte) error {
+		if sent == 1 {
+			return down
+		}
+		sent++
+		return nil
+	}
+	if n, err := spool.Ship(context.Background(), flaky); n != 1 || !errors.Is(err, down) {
+		t.Fatalf("Ship with a failing collector = %d, %v; want 1 sent and the error", n, err)
+	}
+	// Backoff: a Ship right after a failure does not contact the collector.
+	if n, err := spool.Ship(context.Background(), func(context.Context, []byte) error {
+		t.Error("shipped during backoff")
+		return nil
+	}); n != 0 || err != nil {
+		t.Errorf("Ship during backoff = %d, %v", n, err)
+	}
+
+	// Spans recorded meanwhile queue behind the unsent batch.
+	if err := spool.ExportSpans(context.Background(), testSpans()); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path+".failed", past, past); err != nil {
+		t.Fatal(err)
+	}
+	var got int
+	if n, err := spool.Ship(context.Background(), func(context.Context, []byte) error { got++; return nil }); n != 3 || err != nil || got != 3 {
+		t.Fatalf("Ship after backoff = %d, %v (posted %d); want the 2 unsent and 1 new", n, err, got)
+	}
+	for _, p := range []string{path, path + ".sending", path + ".failed"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s left after a full ship: %v", filepath.Base(p), err)
+		}
+	}
+}
+
+func TestSpanSpool_DropsPastMaxBytes(t *testing.T) {
+	spool := NewSpanSpool(filepath.Join(t.TempDir(), "trace_spool.jsonl"), "adb")
+	spool.MaxBytes = 1
+	if err := spool.ExportSpans(context.Background(), testSpans()); err != nil {
+		t.Fatalf("first export: %v", err)
+	}
+	if err := spool.ExportSpans(context.Background(), testSpans()); err == nil {
+		t.Error("an export into a full spool should fail")
+	}
+}
@@ -0,0 +1,211 @@
+package observability
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/valter-silva-au/ai-dev-brain/internal/lockfile"
+)
+
+// TraceStateTTL is how long an unfinished session or tool call is kept. A
+// session whose SessionEnd hook never ran (a crashed agent) is forgotten
+// after this and its root span is never exported.
+const TraceStateTTL = 24 * time.Hour
+
+// TraceSession is an agent session whose root span is still open.
+type TraceSession struct {
+	Key        string    `yaml:"key"`
+	TraceID    string    `yaml:"trace_id"`
+	RootSpanID string    `yaml:"root_span_id"`
+	TaskID     string    `yaml:"task_id,omitempty"`
+	StartedAt  time.Time `yaml:"started_at"`
+	LastSeen   time.Time `yaml:"last_seen"`
+}
+
+// PendingToolCall is a tool call seen by pre-tool-use whose post-tool-use has
+// not arrived yet.
+type PendingToolCall struct {
+	Key       string    `yaml:"key"` // trace ID + "/" + tool_use_id
+	Tool      string    `yaml:"tool,omitempty"`
+	StartedAt time.Time `yaml:"started_at"`
+}
+
+// TraceStateStore remembers when open sessions and tool calls started, so the
+// hook process that closes one can export a span with the right start time.
+// Hooks for parallel tool calls run concurrently, so every update holds a
+// sidecar flock across load → mutate → save, like AlertStateStore.
+type TraceStateStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+type traceStateFile struct {
+	Sessions []TraceSession    `yaml:"sessions,omitempty"`
+	Tools    []PendingToolCall `yaml:"tools,omitempty"`
+}
+
+// NewTraceStateStore returns a store persisted at path (normally
+// statedir.Path(base, statedir.FileTraceState)).
+func NewTraceStateStore(path string) *TraceStateStore {
+	return &TraceStateStore{path: path}
+}
+
+// Session returns the open session for key, starting one at now if there is
+// none. The trace ID derives from key, so the session's spans share a trace
+// even if this state is lost.
+func (s *TraceStateStore) Session(key, taskID string, now time.Time) (TraceSession, error) {
+	var out TraceSession
+	err := s.update(now, func(st *traceStateFile) {
+		for i := range st.Sessions {
+			if st.Sessions[i].Key == key {
+				st.Sessions[i].LastSeen = now
+				if st.Sessions[i].TaskID == "" {
+					st.Sessions[i].TaskID = taskID
+				}
+				out = st.Sessions[i]
+				return
+			}
+		}
+		traceID := TraceIDFromKey(key)
+		out = TraceSession{
+			Key:        key,
+			TraceID:    traceID,
+			RootSpanID: SpanIDFromKey(traceID + "/session"),
+			TaskID:     taskID,
+			StartedAt:  now,
+			LastSeen:   now,
+		}
+		st.Sessions = append(st.Sessions, out)
+	})
+	return out, err
+}
+
+// EndSession removes and returns the open session for key.
+func (s *TraceStateStore) EndSession(key string, now time.Time) (TraceSession, bool, error) {
+	var (
+		out   TraceSession
+		found bool
+	)
+	err := s.update(now, func(st *traceStateFile) {
+		for i := range st.Sessions {
+			if st.Sessions[i].Key == key {
+				out, found = st.Sessions[i], true
+				st.Sessions = append(st.Sessions[:i], st.Sessions[i+1:]...)
+				return
+			}
+		}
+	})
+	return out, found, err
+}
+
+// StartTool records that the tool call key began at now.
+func (s *TraceStateStore) StartTool(key, tool string, now time.Time) error {
+	return s.update(now, func(st *traceStateFile) {
+		for i := range st.Tools {
+			if st.Tools[i].Key == key {
+				return
+			}
+		}
+		st.Tools = append(st.Tools, PendingToolCall{Key: key, Tool: tool, StartedAt: now})
+	})
+}
+
+// FinishTool removes and returns the pending tool call key.
+func (s *TraceStateStore) FinishTool(key string, now time.Time) (PendingToolCall, bool, error) {
+	var (
+		out   PendingToolCall
+		found bool
+	)
+	err := s.update(now, func(st *traceStateFile) {
+		for i := range st.Tools {
+			if st.Tools[i].Key == key {
+				out, found = st.Tools[i], true
+				st.Tools = append(st.Tools[:i], st.Tools[i+1:]...)
+				return
+			}
+		}
+	})
+	return out, found, err
+}
+
+// update runs fn under the store lock, dropping entries older than
+// TraceStateTTL first, and saves the result.
+func (s *TraceStateStore) update(now time.Time, fn func(*traceStateFile)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create trace state directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open trace state lock: %w", err)
+	}
+	defer f.Close()
+	unlock, err := lockfile.Lock(f)
+	if err != nil {
+		return fmt.Errorf("lock trace state: %w", err)
+	}
+	defer unlock()
+
+	st, err := s.load()
+	if err != nil {
+		return err
+	}
+	cutoff := now.Add(-TraceStateTTL)
+	sessions := st.Sessions[:0]
+	for _, sess := range st.Sessions {
+		if sess.LastSeen.After(cutoff) {
+			sessions = append(sessions, sess)
+		}
+	}
+	st.Sessions = sessions
+	tools := st.Tools[:0]
+	for _, t := range st.Tools {
+		if t.StartedAt.After(cutoff) {
+			tools = append(tools, t)
+		}
+	}
+	st.Tools = tools
+
+	fn(&st)
+	return s.save(st)
+}
+
+func (s *TraceStateStore) load() (traceStateFile, error) {
+	var st traceStateFile
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st, nil
+		}
+		return st, fmt.Errorf("read trace state: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &st); err != nil {
+		// The state only refines span start times; a corrupt file is
+		// dropped rather than failing every hook from now on.
+		return traceStateFile{}, nil
+	}
+	return st, nil
+}
+
+func (s *TraceStateStore) save(st traceStateFile) error {
+	sort.Slice(st.Sessions, func(i, j int) bool { return st.Sessions[i].StartedAt.Before(st.Sessions[j].StartedAt) })
+	data, err := yaml.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("marshal trace state: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write trace state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("commit trace state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Agent sessions and hook invocations as OpenTelemetry spans. adb has no
+// long-lived process to hold a tracer: every `adb hook …` call is its own
+// process. Spans are therefore complete when created (start and end known)
+// and exported immediately, and the causal links are rebuilt from stable
+// keys — the trace ID derives from the agent's session id (or arrives in a
+// W3C TRACEPARENT from `adb task run-with-ruflo`), a tool-call span ID from
+// its tool_use_id — so separate processes agree on parents without sharing
+// memory. The only persisted state is when each session and tool call began
+// (tracestate.go).
+
+// Span status codes (OTLP Status.code).
+const (
+	SpanStatusUnset = 0
+	SpanStatusOK    = 1
+	SpanStatusError = 2
+)
+
+// spanKindInternal is OTLP's SPAN_KIND_INTERNAL; every adb span is one.
+const spanKindInternal = 1
+
+// Span is one finished span. IDs are lowercase hex: 32 characters for the
+// trace, 16 for spans. ParentSpanID is empty for a root span.
+type Span struct {
+	TraceID       string
+	SpanID        string
+	ParentSpanID  string
+	Name          string
+	Start         time.Time
+	End           time.Time
+	Attributes    map[string]interface{}
+	StatusCode    int
+	StatusMessage string
+}
+
+// SetError marks the span failed with err's message. A nil err marks it OK.
+func (s *Span) SetError(err error) {
+	if err == nil {
+		s.StatusCode = SpanStatusOK
+		return
+	}
+	s.StatusCode = SpanStatusError
+	s.StatusMessage = err.Error()
+}
+
+// SpanExporter ships finished spans somewhere.
+type SpanExporter interface {
+	ExportSpans(ctx context.Context, spans []Span) error
+}
+
+// NewTraceID returns a random 16-byte trace ID.
+func NewTraceID() string { return randomHex(16) }
+
+// NewSpanID returns a random 8-byte span ID.
+func NewSpanID() string { return randomHex(8) }
+
+// TraceIDFromKey derives a trace ID from a stable key (an agent session id),
+// so every process that sees the key lands in the same trace.
+func TraceIDFromKey(key string) string {
+	sum := sha256.Sum256([]byte("trace\x00" + key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// SpanIDFromKey derives a span ID from a stable key, e.g. trace ID + tool_use_id.
+func SpanIDFromKey(key string) string {
+	sum := sha256.Sum256([]byte("span\x00" + key))
+	return hex.EncodeToString(sum[:8])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand never fails on supported platforms; fall back to the
+		// clock rather than returning an invalid (all-zero) ID.
+		sum := sha256.Sum256([]byte(time.Now().String()))
+		return hex.EncodeToString(sum[:n])
+	}
+	return hex.EncodeToString(b)
+}
+
+// FormatTraceparent renders a W3C traceparent header/env value.
+func FormatTraceparent(traceID, spanID string) string {
+	return "00-" + traceID + "-" + spanID + "-01"
+}
+
+// ParseTraceparent extracts the trace and parent span IDs from a W3C
+// traceparent value. ok is false for anything malformed or all-zero.
+func ParseTraceparent(s string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", false
+	}
+	if !isHex(parts[1]) || !isHex(parts[2]) ||
+		parts[1] == strings.Repeat("0", 32) || parts[2] == strings.Repeat("0", 16) {
+		return "", "", false
+	}
+	return strings.ToLower(parts[1]), strings.ToLower(parts[2]), true
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// MarshalOTLPTraces encodes spans as an OTLP/JSON ExportTraceServiceRequest
+// with service.name set to service.
+func MarshalOTLPTraces(service string, spans []Span) ([]byte, error) {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		o := otlpSpan{
+			TraceID:           s.TraceID,
+			SpanID:            s.SpanID,
+			ParentSpanID:      s.ParentSpanID,
+			Name:              s.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.Attributes),
+		}
+		if s.StatusCode != SpanStatusUnset {
+			o.Status = &otlpStatus{Code: s.StatusCode, Message: s.StatusMessage}
+		}
+		out = append(out, o)
+	}
+	req := otlpTraceRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes(map[string]interface{}{"service.name": service})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "github.com/valter-silva-au/ai-dev-brain"}, Spans: out}},
+	}}}
+	return json.Marshal(req)
+}
+
+// OTLP/JSON wire types — just the ExportTraceServiceRequest subset adb emits.
+type otlpTraceRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            *otlpStatus    `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"` // int64 travels as a JSON string
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// otlpAttributes converts attrs to sorted key/values. Empty strings are
+// dropped so optional attributes (task_id outside a task) need no guard at
+// the call site.
+func otlpAttributes(attrs map[string]interface{}) []otlpKeyValue {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		var v otlpValue
+		switch x := attrs[k].(type) {
+		case string:
+			if x == "" {
+				continue
+			}
+			v.StringValue = &x
+		case bool:
+			v.BoolValue = &x
+		case int:
+			s := strconv.Itoa(x)
+			v.IntValue = &s
+		case int64:
+			s := strconv.FormatInt(x, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &x
+		default:
+			s := fmt.Sprint(x)
+			v.StringValue = &s
+		}
+		out = append(out, otlpKeyValue{Key: k, Value: v})
+	}
+	return out
+}
+
+// FileSpanExporter appends one OTLP/JSON ExportTraceServiceRequest per line,
+// the layout the OpenTelemetry Collector's file exporter writes and its
+// otlpjsonfile receiver reads back.
+type FileSpanExporter struct {
+	Path    string
+	Service string
+	mu      sync.Mutex
+}
+
+// NewFileSpanExporter returns an exporter appending to path.
+func NewFileSpanExporter(path, service string) *FileSpanExporter {
+	return &FileSpanExporter{Path: path, Service: service}
+}
+
+func (e *FileSpanExporter) ExportSpans(_ context.Context, spans []Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	data, err := MarshalOTLPTraces(e.Service, spans)
+	if err != nil {
+		return fmt.Errorf("marshal spans: %w", err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(e.Path), 0o755); err != nil {
+		return fmt.Errorf("create trace directory: %w", err)
+	}
+	f, err := os.OpenFile(e.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open trace file: %w", err)
+	}
+	defer f.Close()
+	// One write per request keeps concurrent hook processes' lines whole.
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write trace file: %w", err)
+	}
+	return nil
+}
+
+// DefaultOTLPEndpoint is the OTLP/HTTP traces endpoint of a local collector
+// or Jaeger all-in-one.
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+
+// otlpHTTPTimeout bounds one export. Hooks never wait on it: they spool
+// their spans (SpanSpool) and a detached process ships them.
+const otlpHTTPTimeout = 5 * time.Second
+
+// OTLPHTTPSpanExporter POSTs OTLP/JSON to an OTLP/HTTP traces endpoint.
+type OTLPHTTPSpanExporter struct {
+	Endpoint string
+	Headers  map[string]string
+	Service  string
+	Client   *http.Client
+}
+
+// NewOTLPHTTPSpanExporter returns an exporter for endpoint (DefaultOTLPEndpoint
+// when empty) with a bounded HTTP client.
+func NewOTLPHTTPSpanExporter(endpoint string, headers map[string]string, service string) *OTLPHTTPSpanExporter {
+	if endpoint == "" {
+		endpoint = DefaultOTLPEndpoint
+	}
+	return &OTLPHTTPSpanExporter{
+		Endpoint: endpoint,
+		Headers:  headers,
+		Service:  service,
+		Client:   &http.Client{Timeout: otlpHTTPTimeout},
+	}
+}
+
+func (e *OTLPHTTPSpanExporter) ExportSpans(ctx context.Context, spans []Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	data, err := MarshalOTLPTraces(e.Service, spans)
+	if err != nil {
+		return fmt.Errorf("marshal spans: %w", err)
+	}
+	return e.Post(ctx, data)
+}
+
+// Post sends one OTLP/JSON ExportTraceServiceRequest as is (a SpanSpool line).
+func (e *OTLPHTTPSpanExporter) Post(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("build OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+	client := e.Client
+	if client == nil {
+		client = &http.Client{Timeout: otlpHTTPTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("export spans: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("export spans: POST %s returned %s", e.Endpoint, resp.Status)
+	}
+	return nil
+}
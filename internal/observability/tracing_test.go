@@ -0,0 +1,214 @@
+package observability
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var traceT0 = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func testSpans() []Span {
+	root := Span{TraceID: TraceIDFromKey("sess-1"), SpanID: SpanIDFromKey("root"), Name: "agent session",
+		Start: traceT0, End: traceT0.Add(time.Minute), Attributes: map[string]interface{}{"adb.task_id": "TASK-00001"}}
+	root.SetError(nil)
+	gate := Span{TraceID: root.TraceID, SpanID: NewSpanID(), ParentSpanID: root.SpanID, Name: "quality gate go vet",
+		Start: traceT0.Add(time.Second), End: traceT0.Add(2 * time.Second),
+		Attributes: map[string]interface{}{"adb.gate.outcome": "fail", "attempt": 2, "passed": false, "empty": ""}}
+	gate.SetError(errors.New("vet: unreachable code"))
+	return []Span{root, gate}
+}
+
+func TestMarshalOTLPTraces(t *testing.T) {
+	data, err := MarshalOTLPTraces("adb", testSpans())
+	if err != nil {
+		t.Fatalf("MarshalOTLPTraces: %v", err)
+	}
+	var req otlpTraceRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, data)
+	}
+	rs := req.ResourceSpans[0]
+	if a := rs.Resource.Attributes[0]; a.Key != "service.name" || *a.Value.StringValue != "adb" {
+		t.Errorf("resource attribute = %+v", a)
+	}
+	spans := rs.ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans", len(spans))
+	}
+	root, gate := spans[0], spans[1]
+	if len(root.TraceID) != 32 || len(root.SpanID) != 16 || root.ParentSpanID != "" {
+		t.Errorf("root IDs = %q/%q/%q", root.TraceID, root.SpanID, root.ParentSpanID)
+	}
+	if root.StartTimeUnixNano != "1772442000000000000" || root.Status.Code != SpanStatusOK {
+		t.Errorf("root = %+v", root)
+	}
+	if gate.ParentSpanID != root.SpanID || gate.Status.Code != SpanStatusError || gate.Status.Message != "vet: unreachable code" {
+		t.Errorf("gate = %+v", gate)
+	}
+	attrs := map[string]otlpValue{}
+	for _, kv := range gate.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if _, ok := attrs["empty"]; ok {
+		t.Error("empty string attribute should be dropped")
+	}
+	if v := attrs["attempt"]; v.IntValue == nil || *v.IntValue != "2" {
+		t.Errorf("int attribute = %+v", v)
+	}
+	if v := attrs["passed"]; v.BoolValue == nil || *v.BoolValue {
+		t.Errorf("bool attribute = %+v", v)
+	}
+}
+
+func TestTraceparent(t *testing.T) {
+	traceID, spanID := NewTraceID(), NewSpanID()
+	gotT, gotS, ok := ParseTraceparent(FormatTraceparent(traceID, spanID))
+	if !ok || gotT != traceID || gotS != spanID {
+		t.Fatalf("round trip = %q %q %v", gotT, gotS, ok)
+	}
+	for _, bad := range []string{
+		"",
+		"00-" + traceID + "-" + spanID,
+		"00-" + traceID[:30] + "-" + spanID + "-01",
+		"00-0000000000000000000000000000000000-" + spanID + "-01",
+		"00-00000000000000000000000000000000-" + spanID + "-01",
+		"00-" + traceID + "-zzzzzzzzzzzzzzzz-01",
+	} {
+		if _, _, ok := ParseTraceparent(bad); ok {
+			t.Errorf("ParseTraceparent(%q) accepted", bad)
+		}
+	}
+	if TraceIDFromKey("a") != TraceIDFromKey("a") || TraceIDFromKey("a") == TraceIDFromKey("b") {
+		t.Error("TraceIDFromKey should be stable per key")
+	}
+}
+
+func TestFileSpanExporter_AppendsOneRequestPerLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "traces.jsonl")
+	exp := NewFileSpanExporter(path, "adb")
+	for i := 0; i < 2; i++ {
+		if err := exp.ExportSpans(context.Background(), testSpans()); err != nil {
+			t.Fatalf("ExportSpans: %v", err)
+		}
+	}
+	if err := exp.ExportSpans(context.Background(), nil); err != nil {
+		t.Fatalf("ExportSpans(nil): %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := 0
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var req otlpTraceRequest
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			t.Fatalf("line %d: %v", lines+1, err)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("got %d lines, want 2", lines)
+	}
+}
+
+func TestOTLPHTTPSpanExporter(t *testing.T) {
+	var (
+		gotPath, gotType, gotAuth string
+		gotReq                    otlpTraceRequest
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotType, gotAuth = r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &gotReq)
+	}))
+	defer srv.Close()
+
+	exp := NewOTLPHTTPSpanExporter(srv.URL+"/v1/traces", map[string]string{"Authorization": "Bearer t"}, "adb")
+	if err := exp.ExportSpans(context.Background(), testSpans()); err != nil {
+		t.Fatalf("ExportSpans: %v", err)
+	}
+	if gotPath != "/v1/traces" || gotType != "application/json" || gotAuth != "Bearer t" {
+		t.Errorf("request = %s %s %s", gotPath, gotType, gotAuth)
+	}
+	if n := len(gotReq.ResourceSpans[0].ScopeSpans[0].Spans); n != 2 {
+		t.Errorf("collector received %d spans", n)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	if err := NewOTLPHTTPSpanExporter(failing.URL, nil, "adb").ExportSpans(context.Background(), testSpans()); err == nil {
+		t.Error("non-2xx response should be an error")
+	}
+	if NewOTLPHTTPSpanExporter("", nil, "adb").Endpoint != DefaultOTLPEndpoint {
+		t.Error("empty endpoint should default to the local collector")
+	}
+}
+
+func TestTraceStateStore_SessionAndToolLifecycle(t *testing.T) {
+	store := NewTraceStateStore(filepath.Join(t.TempDir(), "trace_state.yaml"))
+
+	first, err := store.Session("sess-1", "TASK-00001", traceT0)
+	if err != nil {
+		t.Fatalf("Session: %v", err)
+	}
+	again, _ := store.Session("sess-1", "", traceT0.Add(time.Minute))
+	if again.TraceID != first.TraceID || again.RootSpanID != first.RootSpanID || !again.StartedAt.Equal(traceT0) || again.TaskID != "TASK-00001" {
+		t.Errorf("second Session = %+v, want the first one back", again)
+	}
+	if first.TraceID != TraceIDFromKey("sess-1") {
+		t.Error("trace ID should derive from the session key")
+	}
+
+	if err := store.StartTool("t/tool-1", "Edit", traceT0.Add(2*time.Minute)); err != nil {
+		t.Fatalf("StartTool: %v", err)
+	}
+	pending, ok, err := store.FinishTool("t/tool-1", traceT0.Add(3*time.Minute))
+	if err != nil || !ok || pending.Tool != "Edit" || !pending.StartedAt.Equal(traceT0.Add(2*time.Minute)) {
+		t.Errorf("FinishTool = %+v, %v, %v", pending, ok, err)
+	}
+	if _, ok, _ := store.FinishTool("t/tool-1", traceT0.Add(3*time.Minute)); ok {
+		t.Error("a finished tool call should be gone")
+	}
+
+	ended, ok, err := store.EndSession("sess-1", traceT0.Add(4*time.Minute))
+	if err != nil || !ok || !ended.StartedAt.Equal(traceT0) {
+		t.Errorf("EndSession = %+v, %v, %v", ended, ok, err)
+	}
+	if _, ok, _ := store.EndSession("sess-1", traceT0.Add(4*time.Minute)); ok {
+		t.Error("an ended session should be gone")
+	}
+}
+
+func TestTraceStateStore_ForgetsStaleEntries(t *testing.T) {
+	store := NewTraceStateStore(filepath.Join(t.TempDir(), "trace_state.yaml"))
+	if _, err := store.Session("crashed", "", traceT0); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.StartTool("t/orphan", "Bash", traceT0); err != nil {
+		t.Fatal(err)
+	}
+	later := traceT0.Add(TraceStateTTL + time.Hour)
+	sess, err := store.Session("crashed", "", later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sess.StartedAt.Equal(later) {
+		t.Errorf("stale session kept its start %v", sess.StartedAt)
+	}
+	if _, ok, _ := store.FinishTool("t/orphan", later); ok {
+		t.Error("stale tool call should have been dropped")
+	}
+}
@@ -24,8 +24,13 @@ type Deps struct {
 	EvaluateAlerts func(ctx context.Context) (int, string, error)
 
 	// LogFiles is the set of files the events-rotate job should size-check.
-	// Usually the adb events log and the scheduler's own log file.
+	// Usually the scheduler's own log file; the event log seals its own
+	// segments and is handled by CompactEvents instead.
 	LogFiles []string
+
+	// CompactEvents merges the event log's sealed segments. Returns a short
+	// one-line summary suitable for logging. Optional.
+	CompactEvents func(ctx context.Context) (string, error)
 }
 
 // DefaultJobs constructs the three v1 jobs from the supplied Deps.
@@ -78,6 +83,13 @@ func DefaultJobs(deps Deps) []Job {
 						fmt.Fprintf(deps.Logger, "    rotated %s\n", path)
 					}
 				}
+				if deps.CompactEvents != nil {
+					summary, err := deps.CompactEvents(ctx)
+					if err != nil {
+						return fmt.Errorf("compact events: %w", err)
+					}
+					fmt.Fprintf(deps.Logger, "    %s\n", summary)
+				}
 				return nil
 			},
 		},
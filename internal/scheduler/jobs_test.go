@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -76,3 +77,26 @@ func TestRotateIfLarge_Missing(t *testing.T) {
 		t.Fatal("expected no rotation for missing file")
 	}
 }
+
+func TestEventsRotateJob_RunsCompaction(t *testing.T) {
+	var logBuf strings.Builder
+	called := false
+	jobs := DefaultJobs(Deps{
+		Logger: &logBuf,
+		CompactEvents: func(ctx context.Context) (string, error) {
+			called = true
+			return "events: 4 sealed segments -> 1", nil
+		},
+	})
+	for _, j := range jobs {
+		if j.Name != "events-rotate" {
+			continue
+		}
+		if err := j.Run(context.Background()); err != nil {
+			t.Fatalf("events-rotate: %v", err)
+		}
+	}
+	if !called || !strings.Contains(logBuf.String(), "4 sealed segments -> 1") {
+		t.Fatalf("compaction not run/logged: called=%v log=%q", called, logBuf.String())
+	}
+}
@@ -17,12 +17,52 @@ import (
 )
 
 // Job is a scheduled unit of work.
+//
+// A job runs every DefaultInterval from daemon start unless it sets Schedule,
+// in which case it runs at the schedule's due times instead. A scheduled job
+// persists its next due time in State.NextRun, so a restarted daemon neither
+// drifts nor forgets: due times that passed while it was down are handled by
+// the job's Misfire policy. Suppress, when set, is asked about the due time of
+// every scheduled run (a caught-up run included) and vetoes that run (counted
+// in State.Suppressed) when it reports true — the hook for workspace quiet
+// hours and holidays.
 type Job struct {
 	Name            string
 	DefaultInterval time.Duration
 	Run             func(ctx context.Context) error
+
+	Schedule Schedule
+	Misfire  MisfirePolicy
+	Suppress func(t time.Time) (reason string, suppressed bool)
+}
+
+// Schedule yields a job's due times: Next returns the first due time strictly
+// after the given instant, or the zero time when there is none.
+type Schedule interface {
+	Next(after time.Time) time.Time
 }
 
+// MisfirePolicy is what a scheduled job does about due times it missed.
+type MisfirePolicy string
+
+const (
+	// MisfireRunOnce runs once for any number of missed due times (default).
+	MisfireRunOnce MisfirePolicy = "run_once"
+	// MisfireRunAll runs once per missed due time, up to catchUpLimit.
+	MisfireRunAll MisfirePolicy = "run_all"
+	// MisfireSkip drops missed due times.
+	MisfireSkip MisfirePolicy = "skip"
+)
+
+const (
+	// misfireGrace is how late a due time may be handled and still count as
+	// on time rather than missed.
+	misfireGrace = time.Minute
+	// catchUpLimit bounds how many missed due times are counted (and, under
+	// run_all, replayed) in one go.
+	catchUpLimit = 50
+)
+
 // JobConfig overrides a job's default interval or disables it entirely.
 type JobConfig struct {
 	Enabled  bool
@@ -42,8 +82,9 @@ type RunOptions struct {
 	Logger io.Writer
 	// Now returns the current time. Defaults to time.Now. Injected for tests.
 	Now func() time.Time
-	// RunOnStart invokes each enabled job once at startup before its
-	// first tick. Defaults true for the daemon; tests override.
+	// RunOnStart invokes each enabled interval job once at startup before
+	// its first tick. Scheduled jobs ignore it: their persisted next due time
+	// and misfire policy decide. Defaults true for the daemon; tests override.
 	RunOnStart bool
 }
 
@@ -54,9 +95,16 @@ type State struct {
 	LastEnd      time.Time     `yaml:"last_end,omitempty"`
 	LastDuration time.Duration `yaml:"last_duration,omitempty"`
 	LastError    string        `yaml:"last_error,omitempty"`
+	LastSuccess  time.Time     `yaml:"last_success,omitempty"`
 	Runs         int           `yaml:"runs"`
 	Failures     int           `yaml:"failures"`
 	Skipped      int           `yaml:"skipped"`
+	// NextRun, Missed and Suppressed are only kept for scheduled jobs: the
+	// persisted next due time, due times dropped by the misfire policy, and
+	// runs vetoed by Suppress.
+	NextRun    time.Time `yaml:"next_run,omitempty"`
+	Missed     int       `yaml:"missed,omitempty"`
+	Suppressed int       `yaml:"suppressed,omitempty"`
 }
 
 // Run starts the scheduler and blocks until ctx is cancelled. Returns
@@ -84,6 +132,14 @@ func Run(ctx context.Context, opts RunOptions) error {
 			logf("job %s disabled by config, skipping", job.Name)
 			continue
 		}
+		if job.Schedule != nil {
+			wg.Add(1)
+			go func(j Job) {
+				defer wg.Done()
+				runScheduleLoop(ctx, j, states, opts.Now, logf)
+			}(job)
+			continue
+		}
 		interval := job.DefaultInterval
 		if ok && cfg.Interval > 0 {
 			interval = cfg.Interval
@@ -127,42 +183,7 @@ func runJobLoop(
 			return
 		}
 		defer mu.Unlock()
-
-		start := now()
-		states.update(job.Name, func(s *State) {
-			s.Name = job.Name
-			s.LastStart = start
-		})
-		logf("job %s started", job.Name)
-
-		var runErr error
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					runErr = fmt.Errorf("panic: %v", r)
-				}
-			}()
-			runErr = job.Run(ctx)
-		}()
-
-		end := now()
-		duration := end.Sub(start)
-		states.update(job.Name, func(s *State) {
-			s.LastEnd = end
-			s.LastDuration = duration
-			s.Runs++
-			if runErr != nil {
-				s.Failures++
-				s.LastError = runErr.Error()
-			} else {
-				s.LastError = ""
-			}
-		})
-		if runErr != nil {
-			logf("job %s failed after %s: %v", job.Name, duration, runErr)
-		} else {
-			logf("job %s finished in %s", job.Name, duration)
-		}
+		runJob(ctx, job, states, now, logf)
 	}
 
 	if runOnStart {
@@ -189,3 +210,171 @@ func runJobLoop(
 		}
 	}
 }
+
+// runJob invokes job once, recording its start, end, duration and outcome.
+func runJob(
+	ctx context.Context,
+	job Job,
+	states *stateStore,
+	now func() time.Time,
+	logf func(format string, args ...interface{}),
+) {
+	start := now()
+	states.update(job.Name, func(s *State) {
+		s.Name = job.Name
+		s.LastStart = start
+	})
+	logf("job %s started", job.Name)
+
+	var runErr error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				runErr = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		runErr = job.Run(ctx)
+	}()
+
+	end := now()
+	duration := end.Sub(start)
+	states.update(job.Name, func(s *State) {
+		s.LastEnd = end
+		s.LastDuration = duration
+		s.Runs++
+		if runErr != nil {
+			s.Failures++
+			s.LastError = runErr.Error()
+		} else {
+			s.LastError = ""
+			s.LastSuccess = end
+		}
+	})
+	if runErr != nil {
+		logf("job %s failed after %s: %v", job.Name, duration, runErr)
+	} else {
+		logf("job %s finished in %s", job.Name, duration)
+	}
+}
+
+// runScheduleLoop runs a job at its Schedule's due times. The next due time
+// is persisted before each run, so a restart resumes from it: due times more
+// than misfireGrace in the past were missed and are dropped, run once, or
+// replayed one by one according to job.Misfire. Runs are sequential — a run
+// that overruns its next due time turns it into a misfire rather than an
+// overlap.
+func runScheduleLoop(
+	ctx context.Context,
+	job Job,
+	states *stateStore,
+	now func() time.Time,
+	logf func(format string, args ...interface{}),
+) {
+	next := states.get(job.Name).NextRun
+	// A missing next due time is a first start; one later than the schedule's
+	// next due time means the schedule changed since it was persisted.
+	if due := job.Schedule.Next(now()); next.IsZero() || next.After(due) {
+		next = due
+	}
+	if next.IsZero() {
+		logf("job %s has no upcoming due time, skipping", job.Name)
+		return
+	}
+	setNext := func(t time.Time) {
+		states.update(job.Name, func(s *State) {
+			s.Name = job.Name
+			s.NextRun = t
+		})
+	}
+	setNext(next)
+	logf("job %s next due %s", job.Name, next.UTC().Format(time.RFC3339))
+
+	for {
+		if wait := next.Sub(now()); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		current := now()
+		var (
+			missedDues []time.Time
+			onTimeDue  time.Time
+		)
+		for due := next; !due.IsZero() && !due.After(current) && len(missedDues) < catchUpLimit; due = job.Schedule.Next(due) {
+			if current.Sub(due) <= misfireGrace {
+				onTimeDue = due
+				break
+			}
+			missedDues = append(missedDues, due)
+		}
+		missed, onTime := len(missedDues), !onTimeDue.IsZero()
+		// dues are the due times that run, each standing for the schedule
+		// slot it was due in; run_once collapses a backlog into its latest.
+		var dues []time.Time
+		switch job.Misfire {
+		case MisfireSkip:
+			if onTime {
+				dues = []time.Time{onTimeDue}
+			}
+		case MisfireRunAll:
+			dues = missedDues
+			if onTime {
+				dues = append(dues, onTimeDue)
+			}
+		default:
+			if onTime {
+				dues = []time.Time{onTimeDue}
+			} else if missed > 0 {
+				dues = missedDues[missed-1:]
+			}
+		}
+		runs := len(dues)
+		if missed > 0 {
+			dropped := missed
+			if job.Misfire == MisfireRunAll {
+				dropped = 0
+			}
+			states.update(job.Name, func(s *State) { s.Missed += dropped })
+			logf("job %s missed %d due time(s); misfire policy %s runs it %d time(s)", job.Name, missed, misfireLabel(job.Misfire), runs)
+		}
+
+		next = job.Schedule.Next(current)
+		setNext(next)
+
+		// The calendar is read at each due time, not at catch-up time: a missed
+		// run due inside quiet hours stays suppressed after a restart, and one
+		// suppressed run does not drop the rest of a run_all backlog.
+		for _, due := range dues {
+			if ctx.Err() != nil {
+				break
+			}
+			if job.Suppress != nil {
+				if reason, ok := job.Suppress(due); ok {
+					states.update(job.Name, func(s *State) { s.Suppressed++ })
+					logf("job %s due %s suppressed (%s)", job.Name, due.UTC().Format(time.RFC3339), reason)
+					continue
+				}
+			}
+			runJob(ctx, job, states, now, logf)
+		}
+		if next.IsZero() {
+			logf("job %s has no further due time, stopping", job.Name)
+			return
+		}
+	}
+}
+
+func misfireLabel(p MisfirePolicy) MisfirePolicy {
+	if p == "" {
+		return MisfireRunOnce
+	}
+	return p
+}
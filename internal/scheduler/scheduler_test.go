@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"path/filepath"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -157,3 +158,210 @@ func TestStateStore_PersistAndReload(t *testing.T) {
 		t.Fatalf("unexpected reloaded state: %+v", got)
 	}
 }
+
+func TestRun_RecordsLastSuccess(t *testing.T) {
+	var calls int32
+	job := Job{
+		Name:            "flaky",
+		DefaultInterval: 20 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return nil
+			}
+			return errors.New("down")
+		},
+	}
+	stateFile := filepath.Join(t.TempDir(), "state.yaml")
+	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Millisecond)
+	defer cancel()
+	_ = Run(ctx, RunOptions{Jobs: []Job{job}, StateFile: stateFile, RunOnStart: true})
+
+	states, err := LoadStates(stateFile)
+	if err != nil || len(states) != 1 {
+		t.Fatalf("LoadStates = %+v, %v", states, err)
+	}
+	s := states[0]
+	if s.Failures == 0 || s.LastError == "" {
+		t.Fatalf("expected later runs to fail: %+v", s)
+	}
+	if s.LastSuccess.IsZero() || !s.LastSuccess.Before(s.LastEnd) {
+		t.Errorf("LastSuccess = %v, want the first (successful) run before LastEnd %v", s.LastSuccess, s.LastEnd)
+	}
+}
+
+// everySchedule is a fixed-interval Schedule for tests.
+type everySchedule time.Duration
+
+func (e everySchedule) Next(after time.Time) time.Time { return after.Add(time.Duration(e)) }
+
+func loadState(t *testing.T, path, name string) State {
+	t.Helper()
+	states, err := LoadStates(path)
+	if err != nil {
+		t.Fatalf("LoadStates: %v", err)
+	}
+	for _, s := range states {
+		if s.Name == name {
+			return s
+		}
+	}
+	return State{}
+}
+
+// TestRun_ScheduleMisfirePolicies seeds a persisted next due time 30 minutes
+// in the past on a 7-minute schedule — five missed due times — and checks
+// what each policy does with them on restart.
+func TestRun_ScheduleMisfirePolicies(t *testing.T) {
+	cases := []struct {
+		policy     MisfirePolicy
+		wantRuns   int32
+		wantMissed int
+	}{
+		{"", 1, 5},
+		{MisfireRunOnce, 1, 5},
+		{MisfireRunAll, 5, 0},
+		{MisfireSkip, 0, 5},
+	}
+	for _, tc := range cases {
+		t.Run(string(misfireLabel(tc.policy)), func(t *testing.T) {
+			stateFile := filepath.Join(t.TempDir(), "state.yaml")
+			seeded := time.Now().Add(-30 * time.Minute)
+			newStateStore(stateFile).update("cron", func(s *State) { s.NextRun = seeded })
+
+			var calls int32
+			job := Job{
+				Name:     "cron",
+				Schedule: everySchedule(7 * time.Minute),
+				Misfire:  tc.policy,
+				Run: func(ctx context.Context) error {
+					atomic.AddInt32(&calls, 1)
+					return nil
+				},
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 80*time.Millisecond)
+			defer cancel()
+			_ = Run(ctx, RunOptions{Jobs: []Job{job}, StateFile: stateFile})
+
+			if got := atomic.LoadInt32(&calls); got != tc.wantRuns {
+				t.Errorf("runs = %d, want %d", got, tc.wantRuns)
+			}
+			s := loadState(t, stateFile, "cron")
+			if s.Missed != tc.wantMissed {
+				t.Errorf("missed = %d, want %d", s.Missed, tc.wantMissed)
+			}
+			if until := time.Until(s.NextRun); until < 6*time.Minute || until > 7*time.Minute {
+				t.Errorf("next run = %v, want ~7m from now", s.NextRun)
+			}
+		})
+	}
+}
+
+// TestRun_ScheduleSuppressedPerDueTime: catching up under run_all asks the
+// calendar about each missed due time, not the restart time, and a suppressed
+// due time skips only its own run.
+func TestRun_ScheduleSuppressedPerDueTime(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.yaml")
+	seeded := time.Now().Add(-30 * time.Minute)
+	newStateStore(stateFile).update("cron", func(s *State) { s.NextRun = seeded })
+
+	var (
+		calls int32
+		mu    sync.Mutex
+		asked []time.Time
+	)
+	job := Job{
+		Name:     "cron",
+		Schedule: everySchedule(7 * time.Minute),
+		Misfire:  MisfireRunAll,
+		// Suppress the 2nd and 4th of the five missed due times.
+		Suppress: func(due time.Time) (string, bool) {
+			mu.Lock()
+			asked = append(asked, due)
+			mu.Unlock()
+			slot := int(due.Sub(seeded) / (7 * time.Minute))
+			return "quiet hours", slot%2 == 1
+		},
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 80*time.Millisecond)
+	defer cancel()
+	_ = Run(ctx, RunOptions{Jobs: []Job{job}, StateFile: stateFile})
+
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("runs = %d, want 3 (5 missed, 2 suppressed)", got)
+	}
+	if s := loadState(t, stateFile, "cron"); s.Suppressed != 2 {
+		t.Errorf("suppressed = %d, want 2", s.Suppressed)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for i, due := range asked {
+		if want := seeded.Add(time.Duration(i) * 7 * time.Minute); !due.Equal(want) {
+			t.Errorf("Suppress asked about %v, want due time %v", due, want)
+		}
+	}
+}
+
+// TestRun_SchedulePersistsNextRun: a first start persists the schedule's next
+// due time instead of running, and on-time due times run.
+func TestRun_SchedulePersistsNextRun(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.yaml")
+	var calls int32
+	job := Job{
+		Name:     "tick",
+		Schedule: everySchedule(30 * time.Millisecond),
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_ = Run(ctx, RunOptions{Jobs: []Job{job}, StateFile: stateFile, RunOnStart: true})
+
+	if got := atomic.LoadInt32(&calls); got < 2 {
+		t.Errorf("runs = %d, want the on-time due times to run", got)
+	}
+	s := loadState(t, stateFile, "tick")
+	if s.NextRun.IsZero() || s.Missed != 0 {
+		t.Errorf("state = %+v, want a persisted next run and no misfires", s)
+	}
+
+	// A far-future persisted time from an older, slower schedule is reset.
+	reseed := newStateStore(stateFile)
+	_ = reseed.load()
+	reseed.update("tick", func(s *State) { s.NextRun = time.Now().Add(24 * time.Hour) })
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel2()
+	_ = Run(ctx2, RunOptions{Jobs: []Job{job}, StateFile: stateFile})
+	if s := loadState(t, stateFile, "tick"); time.Until(s.NextRun) > time.Minute {
+		t.Errorf("stale next run kept: %v", s.NextRun)
+	}
+}
+
+func TestRun_ScheduleSuppressed(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.yaml")
+	var calls int32
+	job := Job{
+		Name:     "quiet",
+		Schedule: everySchedule(20 * time.Millisecond),
+		Suppress: func(time.Time) (string, bool) { return "quiet hours 22:00-07:00", true },
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Millisecond)
+	defer cancel()
+	_ = Run(ctx, RunOptions{Jobs: []Job{job}, StateFile: stateFile})
+
+	if got := atomic.LoadInt32(&calls); got != 0 {
+		t.Errorf("suppressed job ran %d times", got)
+	}
+	if s := loadState(t, stateFile, "quiet"); s.Suppressed == 0 || s.Runs != 0 {
+		t.Errorf("state = %+v, want suppressions and no runs", s)
+	}
+}
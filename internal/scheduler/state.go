@@ -59,6 +59,16 @@ func (s *stateStore) update(name string, mutator func(*State)) {
 	_ = s.writeLocked()
 }
 
+// get returns a copy of the named job's state (zero when it has none).
+func (s *stateStore) get(name string) State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.states[name]; ok {
+		return *st
+	}
+	return State{Name: name}
+}
+
 // Snapshot returns a deep-ish copy of all current states. Useful for
 // `adb scheduler list`.
 func (s *stateStore) Snapshot() []State {
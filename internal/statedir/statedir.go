@@ -24,19 +24,29 @@ const Name = ".adb"
 // const on both sides makes the "target basenames must not drift" contract a
 // compile-time fact rather than a comment.
 const (
-	FileTaskCounter      = "task_counter"         // sequential task-ID counter
-	FileSessionCounter   = "session_counter"      // sequential session counter
-	FileContextState     = "context_state.yaml"   // AIContextGenerator section hashes
-	FileEventsLog        = "events.jsonl"         // append-only dev event log
-	FileGovernanceLog    = "governance.jsonl"     // append-only governance stream (#137)
-	FileSchedulerLog     = "scheduler.log"        // scheduler daemon log
-	FileSchedulerPID     = "scheduler.pid"        // scheduler daemon PID file
-	FileSchedulerState   = "scheduler_state.yaml" // scheduler persisted state
-	FileAutomationCursor = "automation_cursor"    // event-log cursor for event rules
-	FileSessionChanges   = "session_changes"      // hook change tracker
-	FileEvidenceReads    = "evidence_reads"       // hook evidence tracker
-	FileMCPCache         = "mcp_cache.json"       // MCP health-check TTL cache
-	FileMemoryDB         = "memory.sqlite"        // vector-memory SQLite store
+	FileTaskCounter      = "task_counter"          // sequential task-ID counter
+	FileSessionCounter   = "session_counter"       // sequential session counter
+	FileContextState     = "context_state.yaml"    // AIContextGenerator section hashes
+	FileEventsLog        = "events.jsonl"          // append-only dev event log
+	FileGovernanceLog    = "governance.jsonl"      // append-only governance stream (#137)
+	FileSchedulerLog     = "scheduler.log"         // scheduler daemon log
+	FileSchedulerPID     = "scheduler.pid"         // scheduler daemon PID file
+	FileSchedulerState   = "scheduler_state.yaml"  // scheduler persisted state
+	FileAutomationCursor = "automation_cursor"     // event-log cursor for event rules
+	FileRuleFirings      = "rule_firings.jsonl"    // automation rule firing ledger
+	FileAutomationState  = "automation_state.yaml" // rule debounce/throttle state
+	FileRuleOutbox       = "rule_outbox"           // event-rule dispatch claims dir (inline vs drain)
+	FileSessionChanges   = "session_changes"       // hook change tracker
+	FileEvidenceReads    = "evidence_reads"        // hook evidence tracker
+	FileMCPCache         = "mcp_cache.json"        // MCP health-check TTL cache
+	FileMCPTokens        = "mcp_tokens.yaml"       // MCP HTTP bearer tokens (hashed) + scopes
+	FileMemoryDB         = "memory.sqlite"         // vector-memory SQLite store
+	FileNotifyLedger     = "notify_ledger.yaml"    // alert-notification delivery ledger
+	FileAlertState       = "alert_state.yaml"      // alert lifecycle (ack/snooze/resolve) state
+	FileTraces           = "traces.jsonl"          // OTLP/JSON span export (tracing.exporter: file)
+	FileTraceState       = "trace_state.yaml"      // open trace sessions / tool calls
+	FileTraceSpool       = "trace_spool.jsonl"     // spans queued for the otlphttp exporter
+	FileIssueSyncState   = "issue_sync_state.yaml" // issue-sync per-field baselines + conflicts
 )
 
 // Dir returns the absolute path of the .adb/ state directory under basePath:
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WorkCalendar is the parsed workspace calendar for time-triggered rules:
+// quiet-hours windows and holidays, read in one time zone. Build it with
+// AutomationConfig.Calendar.
+type WorkCalendar struct {
+	loc      *time.Location
+	windows  []quietWindow
+	holidays map[string]bool // "2006-01-02" dates and "01-02" every-year dates
+}
+
+type quietWindow struct {
+	label      string
+	start, end int // minutes since midnight; end < start wraps midnight
+	days       [7]bool
+}
+
+// Location returns the workspace automation time zone: automation.timezone,
+// else the host zone.
+func (c AutomationConfig) Location() (*time.Location, error) {
+	tz := strings.TrimSpace(c.Timezone)
+	if tz == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid automation.timezone %q: %w", tz, err)
+	}
+	return loc, nil
+}
+
+// Calendar parses the quiet hours and holidays. Holidays are "YYYY-MM-DD" for
+// one date or "MM-DD" for the same date every year.
+func (c AutomationConfig) Calendar() (*WorkCalendar, error) {
+	loc, err := c.Location()
+	if err != nil {
+		return nil, err
+	}
+	cal := &WorkCalendar{loc: loc, holidays: make(map[string]bool)}
+	for i, q := range c.QuietHours {
+		w, err := parseQuietWindow(q)
+		if err != nil {
+			return nil, fmt.Errorf("automation.quiet_hours[%d]: %w", i, err)
+		}
+		cal.windows = append(cal.windows, w)
+	}
+	for _, h := range c.Holidays {
+		h = strings.TrimSpace(h)
+		if _, err := time.Parse("2006-01-02", h); err == nil {
+			cal.holidays[h] = true
+			continue
+		}
+		if _, err := time.Parse("01-02", h); err == nil {
+			cal.holidays[h] = true
+			continue
+		}
+		return nil, fmt.Errorf("automation.holidays: %q is not YYYY-MM-DD or MM-DD", h)
+	}
+	return cal, nil
+}
+
+// Suppressed reports whether a firing at t falls on a holiday or inside a
+// quiet-hours window, with a short reason naming which. A nil calendar
+// suppresses nothing.
+func (w *WorkCalendar) Suppressed(t time.Time) (string, bool) {
+	if w == nil {
+		return "", false
+	}
+	lt := t.In(w.loc)
+	if w.holidays[lt.Format("2006-01-02")] || w.holidays[lt.Format("01-02")] {
+		return "holiday " + lt.Format("2006-01-02"), true
+	}
+	m := lt.Hour()*60 + lt.Minute()
+	wd := lt.Weekday()
+	prev := (wd + 6) % 7
+	for _, q := range w.windows {
+		var in bool
+		if q.start < q.end {
+			in = q.days[wd] && m >= q.start && m < q.end
+		} else {
+			in = (q.days[wd] && m >= q.start) || (q.days[prev] && m < q.end)
+		}
+		if in {
+			return "quiet hours " + q.label, true
+		}
+	}
+	return "", false
+}
+
+func parseQuietWindow(q QuietHoursConfig) (quietWindow, error) {
+	start, err := parseClock(q.Start, false)
+	if err != nil {
+		return quietWindow{}, fmt.Errorf("start: %w", err)
+	}
+	end, err := parseClock(q.End, true)
+	if err != nil {
+		return quietWindow{}, fmt.Errorf("end: %w", err)
+	}
+	if start == end {
+		return quietWindow{}, fmt.Errorf("start and end are both %s", q.Start)
+	}
+	w := quietWindow{label: strings.TrimSpace(q.Start) + "-" + strings.TrimSpace(q.End), start: start, end: end}
+	if len(q.Days) == 0 {
+		for i := range w.days {
+			w.days[i] = true
+		}
+		return w, nil
+	}
+	for _, d := range q.Days {
+		name := strings.ToUpper(strings.TrimSpace(d))
+		if len(name) > 3 {
+			name = name[:3] // "monday" → MON
+		}
+		n, ok := cronDayNames[name]
+		if !ok {
+			return quietWindow{}, fmt.Errorf("unknown day %q", d)
+		}
+		w.days[n] = true
+	}
+	return w, nil
+}
+
+// parseClock parses "HH:MM" into minutes since midnight; allow24 admits
+// "24:00" as the end of the day.
+func parseClock(s string, allow24 bool) (int, error) {
+	s = strings.TrimSpace(s)
+	hh, mm, ok := strings.Cut(s, ":")
+	h, herr := strconv.Atoi(hh)
+	m, merr := strconv.Atoi(mm)
+	if !ok || herr != nil || merr != nil || m < 0 || m > 59 || h < 0 {
+		return 0, fmt.Errorf("%q is not HH:MM", s)
+	}
+	if h > 23 && !(allow24 && h == 24 && m == 0) {
+		return 0, fmt.Errorf("%q is not a time of day", s)
+	}
+	return h*60 + m, nil
+}
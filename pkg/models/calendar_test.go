@@ -0,0 +1,64 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkCalendar_Suppressed(t *testing.T) {
+	cal, err := AutomationConfig{
+		Timezone: "UTC",
+		QuietHours: []QuietHoursConfig{
+			{Start: "22:00", End: "07:00"},
+			{Start: "00:00", End: "24:00", Days: []string{"sat", "Sunday"}},
+		},
+		Holidays: []string{"2026-07-03", "12-25"},
+	}.Calendar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	cases := []struct {
+		t      time.Time
+		reason string // empty = not suppressed
+	}{
+		{at(7, 1, 12, 0), ""},                                                 // Wednesday midday
+		{at(7, 1, 23, 0), "quiet hours 22:00-07:00"},                          // late evening
+		{at(7, 2, 6, 59), "quiet hours 22:00-07:00"},                          // early morning wraps from the day before
+		{at(7, 2, 7, 0), ""},                                                  // window end is exclusive
+		{at(7, 4, 12, 0), "quiet hours 00:00-24:00"},                          // Saturday
+		{at(7, 3, 12, 0), "holiday 2026-07-03"},                               // dated holiday
+		{time.Date(2031, 12, 25, 9, 0, 0, 0, time.UTC), "holiday 2031-12-25"}, // every-year holiday
+	}
+	for _, tc := range cases {
+		reason, ok := cal.Suppressed(tc.t)
+		if ok != (tc.reason != "") || reason != tc.reason {
+			t.Errorf("Suppressed(%v) = %q, %v; want %q", tc.t, reason, ok, tc.reason)
+		}
+	}
+
+	var none *WorkCalendar
+	if _, ok := none.Suppressed(at(7, 1, 23, 0)); ok {
+		t.Error("a nil calendar suppresses nothing")
+	}
+}
+
+func TestAutomationConfig_CalendarErrors(t *testing.T) {
+	for _, tc := range []struct {
+		cfg  AutomationConfig
+		want string
+	}{
+		{AutomationConfig{Timezone: "Mars/Olympus"}, "automation.timezone"},
+		{AutomationConfig{QuietHours: []QuietHoursConfig{{Start: "25:00", End: "07:00"}}}, "quiet_hours[0]"},
+		{AutomationConfig{QuietHours: []QuietHoursConfig{{Start: "09:00", End: "09:00"}}}, "both"},
+		{AutomationConfig{QuietHours: []QuietHoursConfig{{Start: "09:00", End: "10:00", Days: []string{"funday"}}}}, "unknown day"},
+		{AutomationConfig{Holidays: []string{"Christmas"}}, "automation.holidays"},
+	} {
+		if _, err := tc.cfg.Calendar(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Calendar(%+v) error = %v, want it to mention %q", tc.cfg, err, tc.want)
+		}
+	}
+}
@@ -66,6 +66,12 @@ type ActionItem struct {
 	CreatedAt   time.Time `yaml:"created_at"`
 }
 
+// NewCommunicationID mints a timestamp-based communication id. The stored
+// filename is primarily date+subject; the id is a stable fallback identifier.
+func NewCommunicationID() string {
+	return "comm-" + time.Now().UTC().Format("20060102T150405.000000000")
+}
+
 // NewCommunication creates a new Communication with default values
 func NewCommunication(id, taskID, content string) *Communication {
 	return &Communication{
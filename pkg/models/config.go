@@ -1,10 +1,95 @@
 package models
 
-// NotificationConfig holds notification settings
+import (
+	"fmt"
+	"strings"
+)
+
+// NotificationConfig holds notification settings. Channels names the sinks
+// alert notifications are delivered to (webhook, slack, email, desktop, file);
+// each sink reads its own sub-block below. OnEvents optionally restricts
+// delivery to the listed alert types (empty means every alert). Enabled gates
+// the scheduler's alerts-tick delivery; `adb alerts --notify` delivers on
+// demand regardless. Renotify is a Go duration: an alert that is still firing
+// is not re-sent to a channel within it (default 24h).
 type NotificationConfig struct {
-	Enabled  bool     `mapstructure:"enabled" yaml:"enabled"`
-	Channels []string `mapstructure:"channels" yaml:"channels,omitempty"`
-	OnEvents []string `mapstructure:"on_events" yaml:"on_events,omitempty"`
+	Enabled  bool              `mapstructure:"enabled" yaml:"enabled"`
+	Channels []string          `mapstructure:"channels" yaml:"channels,omitempty"`
+	OnEvents []string          `mapstructure:"on_events" yaml:"on_events,omitempty"`
+	Renotify string            `mapstructure:"renotify" yaml:"renotify,omitempty"`
+	Webhook  WebhookNotifyConf `mapstructure:"webhook" yaml:"webhook,omitempty"`
+	Slack    SlackNotifyConf   `mapstructure:"slack" yaml:"slack,omitempty"`
+	Email    EmailNotifyConf   `mapstructure:"email" yaml:"email,omitempty"`
+	Desktop  DesktopNotifyConf `mapstructure:"desktop" yaml:"desktop,omitempty"`
+	File     FileNotifyConf    `mapstructure:"file" yaml:"file,omitempty"`
+	Alerts   AlertsConfig      `mapstructure:"alerts" yaml:"alerts,omitempty"`
+}
+
+// AlertsConfig declares workspace alert rules. Rules are matched in order
+// ahead of the four built-in defaults (blocked 24h, stale 3d, review 5d,
+// backlog > 10), so a scoped rule overrides a default for the tasks it covers;
+// DisableDefaults drops the built-ins entirely.
+type AlertsConfig struct {
+	DisableDefaults bool              `mapstructure:"disable_defaults" yaml:"disable_defaults,omitempty"`
+	Rules           []AlertRuleConfig `mapstructure:"rules" yaml:"rules,omitempty"`
+}
+
+// AlertRuleConfig is one alert rule. Condition is task_blocked_too_long,
+// task_stale, review_too_long, backlog_too_large, worktree_dirty_untouched or
+// issue_sync_conflict_unresolved. Threshold is a duration ("4h", "5d", "2w");
+// backlog_too_large takes Count instead. Severity is high, medium (default)
+// or low. Priorities, Tags and Repos scope the rule — every non-empty list
+// must match, any entry within it; Repos accepts globs ("github.com/acme/*").
+type AlertRuleConfig struct {
+	Name       string   `mapstructure:"name" yaml:"name"`
+	Condition  string   `mapstructure:"condition" yaml:"condition"`
+	Severity   string   `mapstructure:"severity" yaml:"severity,omitempty"`
+	Threshold  string   `mapstructure:"threshold" yaml:"threshold,omitempty"`
+	Count      int      `mapstructure:"count" yaml:"count,omitempty"`
+	Priorities []string `mapstructure:"priorities" yaml:"priorities,omitempty"`
+	Tags       []string `mapstructure:"tags" yaml:"tags,omitempty"`
+	Repos      []string `mapstructure:"repos" yaml:"repos,omitempty"`
+	Disabled   bool     `mapstructure:"disabled" yaml:"disabled,omitempty"`
+}
+
+// WebhookNotifyConf configures the generic webhook sink: each alert is POSTed
+// to URL as a JSON document. Headers are added verbatim to every request and
+// support `$ENV_VAR` interpolation so a bearer token never lives in the file.
+type WebhookNotifyConf struct {
+	URL     string            `mapstructure:"url" yaml:"url,omitempty"`
+	Headers map[string]string `mapstructure:"headers" yaml:"headers,omitempty"`
+}
+
+// SlackNotifyConf configures a Slack-compatible incoming webhook (Slack,
+// Mattermost, Rocket.Chat all accept the same {"text": …} payload). URL
+// supports `$ENV_VAR` interpolation.
+type SlackNotifyConf struct {
+	URL      string `mapstructure:"url" yaml:"url,omitempty"`
+	Channel  string `mapstructure:"channel" yaml:"channel,omitempty"`
+	Username string `mapstructure:"username" yaml:"username,omitempty"`
+}
+
+// EmailNotifyConf configures SMTP delivery. Host is "smtp.example.com:587";
+// Password supports `$ENV_VAR` interpolation. Auth is PLAIN when Username is
+// set, otherwise the message is sent unauthenticated (a local relay).
+type EmailNotifyConf struct {
+	Host     string   `mapstructure:"host" yaml:"host,omitempty"`
+	From     string   `mapstructure:"from" yaml:"from,omitempty"`
+	To       []string `mapstructure:"to" yaml:"to,omitempty"`
+	Username string   `mapstructure:"username" yaml:"username,omitempty"`
+	Password string   `mapstructure:"password" yaml:"password,omitempty"`
+}
+
+// DesktopNotifyConf configures the local desktop sink. Command defaults to
+// notify-send; any binary taking `<title> <body>` works.
+type DesktopNotifyConf struct {
+	Command string `mapstructure:"command" yaml:"command,omitempty"`
+}
+
+// FileNotifyConf configures the file sink: each delivered alert is appended as
+// one JSON line to Path (relative paths resolve under the workspace root).
+type FileNotifyConf struct {
+	Path string `mapstructure:"path" yaml:"path,omitempty"`
 }
 
 // TeamRoutingConfig holds team routing settings
@@ -80,11 +165,25 @@ type MemoryHookConfig struct {
 	Enabled  bool               `mapstructure:"enabled" yaml:"enabled"`
 	DBPath   string             `mapstructure:"db_path" yaml:"db_path,omitempty"`
 	Embedder MemoryEmbedderConf `mapstructure:"embedder" yaml:"embedder"`
+	// Retention expires records by namespace prefix during `adb memory gc`.
+	// Unlike the rest of the block it applies whether or not Enabled is set.
+	Retention []MemoryRetentionConfig `mapstructure:"retention" yaml:"retention,omitempty"`
+}
+
+// MemoryRetentionConfig keeps records under Prefix (e.g. "sessions/",
+// "archive/") for MaxAge after their last update ("30d", "720h"); the
+// longest matching prefix wins.
+type MemoryRetentionConfig struct {
+	Prefix string `mapstructure:"prefix" yaml:"prefix"`
+	MaxAge string `mapstructure:"max_age" yaml:"max_age"`
 }
 
 // MemoryEmbedderConf describes which embedding provider the memory
 // subsystem should use when opened via config. Provider ∈ {fake, openai,
-// ollama}. APIKey supports `$ENV_VAR` interpolation.
+// ollama, local}; for local, Model is the path to a static token-embedding
+// table (.vec, optionally .gz; ONNX models are not supported), or empty for
+// the model-free lexical embedder. APIKey supports `$ENV_VAR`
+// interpolation.
 type MemoryEmbedderConf struct {
 	Provider string `mapstructure:"provider" yaml:"provider"`
 	Model    string `mapstructure:"model" yaml:"model,omitempty"`
@@ -105,24 +204,121 @@ type CLIAliasConfig struct {
 // matching rules. Defaults to disabled — event rules are inert until a workspace
 // opts in, mirroring the hooks.memory opt-in. DispatchInterval is a Go duration
 // (default 30s when empty).
+//
+// Timezone, QuietHours and Holidays form the workspace calendar for
+// time-triggered rules: Timezone (an IANA name, default the host zone) is the
+// default zone for cron schedules and the zone the calendar is read in, and a
+// due firing that lands in a quiet-hours window or on a holiday is suppressed
+// unless the rule sets ignore_calendar. See WorkCalendar.
+//
+// InlineDispatch ∈ {off, sync, async} (default off) additionally fires event
+// rules in the process that logs the event, as it is logged: sync before the
+// logging call returns, async on a background goroutine the process waits for
+// on exit. A dispatch outbox under .adb/ records which events were fired, so
+// the scheduler's drain skips them. Inline dispatch also needs Enabled. With
+// async, event rules fire without the daemon; sync leaves rules with an exec
+// step to the drain, so those still need the daemon running.
 type AutomationConfig struct {
-	Enabled          bool   `mapstructure:"enabled" yaml:"enabled"`
-	DispatchInterval string `mapstructure:"dispatch_interval" yaml:"dispatch_interval,omitempty"`
+	Enabled          bool               `mapstructure:"enabled" yaml:"enabled"`
+	DispatchInterval string             `mapstructure:"dispatch_interval" yaml:"dispatch_interval,omitempty"`
+	InlineDispatch   string             `mapstructure:"inline_dispatch" yaml:"inline_dispatch,omitempty"`
+	Timezone         string             `mapstructure:"timezone" yaml:"timezone,omitempty"`
+	QuietHours       []QuietHoursConfig `mapstructure:"quiet_hours" yaml:"quiet_hours,omitempty"`
+	Holidays         []string           `mapstructure:"holidays" yaml:"holidays,omitempty"`
+}
+
+// Inline dispatch modes (automation.inline_dispatch).
+const (
+	InlineDispatchOff   = "off"
+	InlineDispatchSync  = "sync"
+	InlineDispatchAsync = "async"
+)
+
+// InlineDispatchMode returns automation.inline_dispatch normalised to one of
+// the InlineDispatch* modes; empty is off.
+func (c AutomationConfig) InlineDispatchMode() (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(c.InlineDispatch)); mode {
+	case "", InlineDispatchOff:
+		return InlineDispatchOff, nil
+	case InlineDispatchSync, InlineDispatchAsync:
+		return mode, nil
+	default:
+		return InlineDispatchOff, fmt.Errorf("invalid automation.inline_dispatch %q (want off, sync or async)", c.InlineDispatch)
+	}
+}
+
+// QuietHoursConfig is one quiet-hours window: Start and End are "HH:MM" (End
+// may be "24:00"; an End before Start wraps past midnight) and Days limits the
+// window to the weekdays it starts on (mon..sun; empty means every day).
+type QuietHoursConfig struct {
+	Start string   `mapstructure:"start" yaml:"start"`
+	End   string   `mapstructure:"end" yaml:"end"`
+	Days  []string `mapstructure:"days" yaml:"days,omitempty"`
+}
+
+// TracingConfig opts into exporting agent sessions, hook invocations, tool
+// calls and task-completed quality gates as OpenTelemetry spans. Exporter ∈
+// {file, otlphttp}: file appends OTLP/JSON to Path (default
+// .adb/traces.jsonl); otlphttp POSTs to Endpoint (default
+// $OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, then http://localhost:4318/v1/traces)
+// from a background flush, hooks only spooling their spans under .adb/.
+// Header values support `$ENV_VAR` interpolation. Defaults to disabled.
+type TracingConfig struct {
+	Enabled     bool              `mapstructure:"enabled" yaml:"enabled"`
+	Exporter    string            `mapstructure:"exporter" yaml:"exporter,omitempty"`
+	Path        string            `mapstructure:"path" yaml:"path,omitempty"`
+	Endpoint    string            `mapstructure:"endpoint" yaml:"endpoint,omitempty"`
+	Headers     map[string]string `mapstructure:"headers" yaml:"headers,omitempty"`
+	ServiceName string            `mapstructure:"service_name" yaml:"service_name,omitempty"`
+}
+
+// IssueTrackerConfig routes the tickets of matching repos to a Jira or Linear
+// project for `adb sync issues`, instead of the repo's own GitHub/GitLab
+// issues. Provider ∈ {jira, linear}. Repos holds platform-qualified repo
+// globs (e.g. github.com/acme/*); empty matches every ticket, repo-less ones
+// included. Project is the Jira project key or Linear team key; URL is the
+// Jira site (https://acme.atlassian.net) or the Linear GraphQL endpoint
+// (default https://api.linear.app/graphql). Token supports `$ENV_VAR`
+// interpolation; with Email set Jira uses basic auth, otherwise a bearer PAT.
+// StatusMap (adb status -> workflow state name) and PriorityMap (P0..P3 ->
+// priority name) override the provider defaults.
+type IssueTrackerConfig struct {
+	Provider    string            `mapstructure:"provider" yaml:"provider"`
+	Repos       []string          `mapstructure:"repos" yaml:"repos,omitempty"`
+	URL         string            `mapstructure:"url" yaml:"url,omitempty"`
+	Project     string            `mapstructure:"project" yaml:"project"`
+	Email       string            `mapstructure:"email" yaml:"email,omitempty"`
+	Token       string            `mapstructure:"token" yaml:"token,omitempty"`
+	IssueType   string            `mapstructure:"issue_type" yaml:"issue_type,omitempty"`
+	StatusMap   map[string]string `mapstructure:"status_map" yaml:"status_map,omitempty"`
+	PriorityMap map[string]string `mapstructure:"priority_map" yaml:"priority_map,omitempty"`
+}
+
+// MCPServerConfig configures what the workspace's MCP server lets an agent
+// persist. WriteTools allowlists the knowledge-capture write tools by name
+// (adb_record_decision, adb_record_learning, adb_record_gotcha, adb_adr_new,
+// adb_debt_add, adb_notes_append, adb_comm_log); "*" allows all of them.
+// Until a tool is listed the server refuses it and leaves it out of
+// tools/list. The task-lifecycle tools and the read-only tools are not gated.
+type MCPServerConfig struct {
+	WriteTools []string `mapstructure:"write_tools" yaml:"write_tools,omitempty"`
 }
 
 // GlobalConfig represents the global .taskconfig configuration
 type GlobalConfig struct {
-	TaskIDPrefix   string             `mapstructure:"task_id_prefix" yaml:"task_id_prefix"`
-	BasePath       string             `mapstructure:"base_path" yaml:"base_path,omitempty"`
-	Defaults       map[string]string  `mapstructure:"defaults" yaml:"defaults,omitempty"`
-	Notifications  NotificationConfig `mapstructure:"notifications" yaml:"notifications"`
-	TeamRouting    TeamRoutingConfig  `mapstructure:"team_routing" yaml:"team_routing"`
-	Hooks          HookConfig         `mapstructure:"hooks" yaml:"hooks"`
-	Aliases        CLIAliasConfig     `mapstructure:"aliases" yaml:"aliases"`
-	Automation     AutomationConfig   `mapstructure:"automation" yaml:"automation"`
-	MCPServers     map[string]string  `mapstructure:"mcp_servers" yaml:"mcp_servers,omitempty"` // name -> URL mapping
-	FeatureFlags   map[string]bool    `mapstructure:"feature_flags" yaml:"feature_flags,omitempty"`
-	CustomSettings map[string]string  `mapstructure:"custom_settings" yaml:"custom_settings,omitempty"`
+	TaskIDPrefix   string               `mapstructure:"task_id_prefix" yaml:"task_id_prefix"`
+	BasePath       string               `mapstructure:"base_path" yaml:"base_path,omitempty"`
+	Defaults       map[string]string    `mapstructure:"defaults" yaml:"defaults,omitempty"`
+	Notifications  NotificationConfig   `mapstructure:"notifications" yaml:"notifications"`
+	TeamRouting    TeamRoutingConfig    `mapstructure:"team_routing" yaml:"team_routing"`
+	Hooks          HookConfig           `mapstructure:"hooks" yaml:"hooks"`
+	Aliases        CLIAliasConfig       `mapstructure:"aliases" yaml:"aliases"`
+	Automation     AutomationConfig     `mapstructure:"automation" yaml:"automation"`
+	Tracing        TracingConfig        `mapstructure:"tracing" yaml:"tracing,omitempty"`
+	IssueTrackers  []IssueTrackerConfig `mapstructure:"issue_trackers" yaml:"issue_trackers,omitempty"`
+	MCPServers     map[string]string    `mapstructure:"mcp_servers" yaml:"mcp_servers,omitempty"` // name -> URL mapping
+	FeatureFlags   map[string]bool      `mapstructure:"feature_flags" yaml:"feature_flags,omitempty"`
+	CustomSettings map[string]string    `mapstructure:"custom_settings" yaml:"custom_settings,omitempty"`
 }
 
 // OrgConfig is the per-organization configuration tier, stored at
@@ -169,6 +365,12 @@ type RepoConfig struct {
 	// workspace enable evidence-gate / operator-controls / memory
 	// without editing ~/.taskconfig.
 	Hooks HookConfig `mapstructure:"hooks" yaml:"hooks,omitempty"`
+	// IssueTrackers are consulted before Global.IssueTrackers, so a
+	// workspace can point its tickets at its own Jira/Linear project.
+	IssueTrackers []IssueTrackerConfig `mapstructure:"issue_trackers" yaml:"issue_trackers,omitempty"`
+	// MCP is per-workspace only: which write tools the MCP server exposes
+	// is a property of the workspace, not of the user.
+	MCP MCPServerConfig `mapstructure:"mcp" yaml:"mcp,omitempty"`
 }
 
 // MergedConfig represents the combined configuration from the global, org, and
@@ -317,7 +519,8 @@ func (mc *MergedConfig) SettingSource(key string) (value, tier string, ok bool)
 // per-sub-struct merge historically done in cli.resolvedHookConfig, generalized
 // to include the org tier: a tier that enables the whole block replaces the
 // base; a tier that enables a sub-feature (evidence gate / operator controls /
-// memory) contributes just that sub-block. The most specific tier wins.
+// memory) contributes just that sub-block, and memory retention rules are
+// taken from the most specific tier that sets any. The most specific tier wins.
 func (mc *MergedConfig) ResolvedHooks() HookConfig {
 	var result HookConfig
 	if mc == nil {
@@ -342,6 +545,9 @@ func (mc *MergedConfig) ResolvedHooks() HookConfig {
 		if h.Memory.Enabled {
 			result.Memory = h.Memory
 		}
+		if len(h.Memory.Retention) > 0 {
+			result.Memory.Retention = h.Memory.Retention
+		}
 	}
 	if mc.Org != nil {
 		apply(mc.Org.Hooks)
@@ -316,6 +316,13 @@ func TestMergedConfig_ResolvedHooks_Precedence(t *testing.T) {
 	if mc.ResolvedHooks().Memory.DBPath != "/repo/mem.sqlite" {
 		t.Errorf("expected repo memory to win over org, got %q", mc.ResolvedHooks().Memory.DBPath)
 	}
+
+	// Retention rules apply even from a tier that leaves memory disabled.
+	repo.Hooks.Memory = MemoryHookConfig{Retention: []MemoryRetentionConfig{{Prefix: "sessions/", MaxAge: "30d"}}}
+	mc = NewMergedConfigWithOrg(global, org, repo)
+	if got := mc.ResolvedHooks().Memory; got.DBPath != "/org/mem.sqlite" || len(got.Retention) != 1 {
+		t.Errorf("expected org memory with repo retention, got %+v", got)
+	}
 }
 
 func TestGlobalConfig_YAMLSerialization(t *testing.T) {
@@ -479,3 +486,16 @@ func TestNotificationConfig_YAMLSerialization(t *testing.T) {
 		t.Errorf("Channels length = %v, want %v", len(decoded.Channels), len(config.Channels))
 	}
 }
+
+func TestAutomationConfig_InlineDispatchMode(t *testing.T) {
+	cases := map[string]string{"": InlineDispatchOff, "off": InlineDispatchOff, "Sync": InlineDispatchSync, " async ": InlineDispatchAsync}
+	for in, want := range cases {
+		got, err := AutomationConfig{InlineDispatch: in}.InlineDispatchMode()
+		if err != nil || got != want {
+			t.Errorf("InlineDispatchMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if got, err := (AutomationConfig{InlineDispatch: "eager"}).InlineDispatchMode(); err == nil || got != InlineDispatchOff {
+		t.Errorf("InlineDispatchMode(eager) = %q, %v; want off with an error", got, err)
+	}
+}
@@ -0,0 +1,299 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed cron expression evaluated in a fixed time zone. It
+// accepts the classic 5-field form (minute hour day-of-month month
+// day-of-week), a 6-field form with a leading seconds field, and the
+// @yearly/@annually, @monthly, @weekly, @daily/@midnight and @hourly macros.
+//
+// Fields take `*`, lists (`1,15`), ranges (`1-5`), steps (`*/15`, `10-40/10`)
+// and, for month and day-of-week, three-letter names (JAN, MON). Day-of-week
+// runs 0-6 from Sunday with 7 as a Sunday alias; `MON#1` is the first Monday
+// of the month and `L` in day-of-month is its last day. `?` is accepted as `*`
+// in either day field. When BOTH day fields are restricted a day matches if
+// either does — the historical cron rule — so "first Monday of the month" is
+// written `0 9 * * MON#1`, not `0 9 1-7 * MON`.
+type CronSchedule struct {
+	expr    string
+	loc     *time.Location
+	second  uint64
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	nth     [7]uint8 // per weekday, bit n set = the nth occurrence (1-5) matches
+	lastDom bool
+	domAny  bool
+	dowAny  bool
+}
+
+// cronSearchYears bounds Next so an impossible expression (Feb 30) ends
+// instead of spinning.
+const cronSearchYears = 5
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronMonthNames = map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}
+	cronDayNames = map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}
+)
+
+// IsCronExpression reports whether s looks like a cron expression rather than
+// a Go duration: a macro, or several whitespace-separated fields.
+func IsCronExpression(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "@") || len(strings.Fields(s)) > 1
+}
+
+// ParseCron parses expr into a schedule evaluated in loc (UTC when nil).
+func ParseCron(expr string, loc *time.Location) (*CronSchedule, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	spec := strings.TrimSpace(expr)
+	if strings.HasPrefix(spec, "@") {
+		m, ok := cronMacros[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("invalid cron expression %q: unknown macro", expr)
+		}
+		spec = m
+	}
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("invalid cron expression %q: want 5 or 6 fields, got %d", expr, len(fields))
+	}
+
+	c := &CronSchedule{expr: strings.TrimSpace(expr), loc: loc}
+	var err error
+	fail := func(name string, err error) (*CronSchedule, error) {
+		return nil, fmt.Errorf("invalid cron expression %q: %s: %w", expr, name, err)
+	}
+	if c.second, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return fail("second", err)
+	}
+	if c.minute, err = parseCronField(fields[1], 0, 59, nil); err != nil {
+		return fail("minute", err)
+	}
+	if c.hour, err = parseCronField(fields[2], 0, 23, nil); err != nil {
+		return fail("hour", err)
+	}
+	if err = c.parseDom(fields[3]); err != nil {
+		return fail("day-of-month", err)
+	}
+	if c.month, err = parseCronField(fields[4], 1, 12, cronMonthNames); err != nil {
+		return fail("month", err)
+	}
+	if err = c.parseDow(fields[5]); err != nil {
+		return fail("day-of-week", err)
+	}
+	return c, nil
+}
+
+// String returns the expression as written.
+func (c *CronSchedule) String() string { return c.expr }
+
+// Location returns the time zone the schedule is evaluated in.
+func (c *CronSchedule) Location() *time.Location { return c.loc }
+
+// Next returns the first matching instant strictly after t, or the zero time
+// when none exists within cronSearchYears. Matching is on wall-clock time, so
+// a time skipped by a DST jump never matches.
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	t := after.In(c.loc).Truncate(time.Second).Add(time.Second)
+	limit := t.Year() + cronSearchYears
+
+	for t.Year() <= limit {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.loc)
+			if !next.After(t) { // DST fall-back repeats the hour; step past it
+				next = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second)
+			}
+			t = next
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute - time.Duration(t.Second())*time.Second)
+			continue
+		}
+		if c.second&(1<<uint(t.Second())) == 0 {
+			t = t.Add(time.Second)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	domOK := c.dom&(1<<uint(t.Day())) != 0 ||
+		(c.lastDom && t.AddDate(0, 0, 1).Month() != t.Month())
+	wd := t.Weekday()
+	dowOK := c.dow&(1<<uint(wd)) != 0 ||
+		c.nth[wd]&(1<<uint((t.Day()-1)/7+1)) != 0
+	if c.domAny || c.dowAny {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+func (c *CronSchedule) parseDom(field string) error {
+	if field == "*" || field == "?" {
+		c.domAny = true
+		c.dom = cronAll(1, 31)
+		return nil
+	}
+	var rest []string
+	for _, part := range strings.Split(field, ",") {
+		if strings.EqualFold(part, "L") {
+			c.lastDom = true
+			continue
+		}
+		rest = append(rest, part)
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	bits, err := parseCronField(strings.Join(rest, ","), 1, 31, nil)
+	c.dom = bits
+	return err
+}
+
+func (c *CronSchedule) parseDow(field string) error {
+	if field == "*" || field == "?" {
+		c.dowAny = true
+		c.dow = cronAll(0, 6)
+		return nil
+	}
+	var rest []string
+	for _, part := range strings.Split(field, ",") {
+		day, nth, ok := strings.Cut(part, "#")
+		if !ok {
+			rest = append(rest, part)
+			continue
+		}
+		d, err := cronValue(day, 0, 7, cronDayNames)
+		if err != nil {
+			return err
+		}
+		n, err := strconv.Atoi(nth)
+		if err != nil || n < 1 || n > 5 {
+			return fmt.Errorf("%q: occurrence must be 1-5", part)
+		}
+		c.nth[d%7] |= 1 << uint(n)
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	bits, err := parseCronField(strings.Join(rest, ","), 0, 7, cronDayNames)
+	if err != nil {
+		return err
+	}
+	if bits&(1<<7) != 0 { // 7 is Sunday
+		bits = bits&^(1<<7) | 1
+	}
+	c.dow = bits
+	return nil
+}
+
+// parseCronField parses a comma list of `*`, values, ranges and steps into a
+// bit set over [lo, hi].
+func parseCronField(field string, lo, hi int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		if part == "" {
+			return 0, fmt.Errorf("empty list entry in %q", field)
+		}
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%q: step must be a positive number", part)
+			}
+			step = n
+		}
+		start, end := lo, hi
+		switch {
+		case rng == "*" || rng == "?":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if start, err = cronValue(a, lo, hi, names); err != nil {
+				return 0, err
+			}
+			if end, err = cronValue(b, lo, hi, names); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("%q: range start is after its end", part)
+			}
+		default:
+			v, err := cronValue(rng, lo, hi, names)
+			if err != nil {
+				return 0, err
+			}
+			start = v
+			if !hasStep {
+				end = v
+			}
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, lo, hi int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if v < lo || v > hi {
+		return 0, fmt.Errorf("%d is out of range %d-%d", v, lo, hi)
+	}
+	return v, nil
+}
+
+func cronAll(lo, hi int) uint64 {
+	var bits uint64
+	for v := lo; v <= hi; v++ {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	// Wednesday 2026-07-01 10:30:00 UTC.
+	from := time.Date(2026, 7, 1, 10, 30, 0, 0, time.UTC)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 7, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * MON-FRI", time.Date(2026, 7, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 7, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * MON#1", time.Date(2026, 7, 6, 9, 0, 0, 0, time.UTC)},
+		{"0 18 L * *", time.Date(2026, 7, 31, 18, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 7, 2, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 7, 1, 11, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 7, 5, 0, 0, 0, 0, time.UTC)},
+		{"30 */10 * * * *", time.Date(2026, 7, 1, 10, 30, 30, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, 7, 5, 12, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 15th OR any Monday.
+		{"0 0 15 * MON", time.Date(2026, 7, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 FEB *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		c, err := ParseCron(tc.expr, time.UTC)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) error = %v", tc.expr, err)
+		}
+		if got := c.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q.Next = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestParseCron_TimeZone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	c, err := ParseCron("0 9 * * *", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 09:00 EDT is 13:00 UTC.
+	got := c.Next(time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 7, 1, 13, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got.UTC(), want)
+	}
+	// 02:30 does not exist on the spring-forward day, so it is skipped.
+	c, _ = ParseCron("30 2 * * *", loc)
+	got = c.Next(time.Date(2026, 3, 8, 0, 0, 0, 0, loc))
+	if got.Day() != 9 || got.Hour() != 2 || got.Minute() != 30 {
+		t.Errorf("DST gap Next = %v, want 2026-03-09 02:30", got)
+	}
+}
+
+func TestParseCron_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"", "* * * *", "* * * * * * *", "@fortnightly",
+		"60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"5-1 * * * *", "*/0 * * * *", "* * * * MON#6", "1,,2 * * * *", "x * * * *",
+	} {
+		if _, err := ParseCron(expr, nil); err == nil {
+			t.Errorf("ParseCron(%q) accepted an invalid expression", expr)
+		}
+	}
+	if c, _ := ParseCron("0 0 30 FEB *", nil); !c.Next(time.Now()).IsZero() {
+		t.Error("an impossible date should have no next time")
+	}
+}
+
+func TestIsCronExpression(t *testing.T) {
+	for s, want := range map[string]bool{"15m": false, "6h": false, "@daily": true, "0 9 * * 1-5": true} {
+		if got := IsCronExpression(s); got != want {
+			t.Errorf("IsCronExpression(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
@@ -4,18 +4,30 @@ import "time"
 
 // Decision represents a decision made during a task
 type Decision struct {
-	ID           string    `yaml:"id"`
-	Title        string    `yaml:"title"`
-	Description  string    `yaml:"description"`
-	Context      string    `yaml:"context,omitempty"`
-	Rationale    string    `yaml:"rationale,omitempty"`
-	Alternatives []string  `yaml:"alternatives,omitempty"`
-	Consequences []string  `yaml:"consequences,omitempty"`
-	Status       string    `yaml:"status"` // proposed, accepted, rejected, deprecated
-	DecidedBy    string    `yaml:"decided_by,omitempty"`
-	DecidedAt    time.Time `yaml:"decided_at"`
-	Tags         []string  `yaml:"tags,omitempty"`
-	RelatedTo    []string  `yaml:"related_to,omitempty"` // related task IDs or decision IDs
+	ID           string      `yaml:"id"`
+	Title        string      `yaml:"title"`
+	Description  string      `yaml:"description"`
+	Context      string      `yaml:"context,omitempty"`
+	Rationale    string      `yaml:"rationale,omitempty"`
+	Alternatives []string    `yaml:"alternatives,omitempty"`
+	Consequences []string    `yaml:"consequences,omitempty"`
+	Status       string      `yaml:"status"` // proposed, accepted, rejected, deprecated
+	DecidedBy    string      `yaml:"decided_by,omitempty"`
+	DecidedAt    time.Time   `yaml:"decided_at"`
+	Tags         []string    `yaml:"tags,omitempty"`
+	RelatedTo    []string    `yaml:"related_to,omitempty"` // related task IDs or decision IDs
+	Source       *Provenance `yaml:"source,omitempty"`
+}
+
+// Provenance records where an extracted entry came from: a file relative to
+// the workspace root and the 1-based line the entry starts on. For a
+// captured session Turn is the turn index and Line counts within that turn's
+// content. Entries recorded by hand (CLI, MCP) carry no provenance.
+type Provenance struct {
+	File      string `yaml:"file"`
+	Line      int    `yaml:"line,omitempty"`
+	Turn      int    `yaml:"turn,omitempty"`
+	Extractor string `yaml:"extractor,omitempty"` // parser or summarizer
 }
 
 // ExtractedKnowledge represents knowledge extracted from a completed task
@@ -31,22 +43,24 @@ type ExtractedKnowledge struct {
 
 // Learning represents a learning or insight from a task
 type Learning struct {
-	Title       string    `yaml:"title"`
-	Description string    `yaml:"description"`
-	Category    string    `yaml:"category,omitempty"` // technical, process, domain, etc.
-	Tags        []string  `yaml:"tags,omitempty"`
-	Timestamp   time.Time `yaml:"timestamp"`
+	Title       string      `yaml:"title"`
+	Description string      `yaml:"description"`
+	Category    string      `yaml:"category,omitempty"` // technical, process, domain, etc.
+	Tags        []string    `yaml:"tags,omitempty"`
+	Timestamp   time.Time   `yaml:"timestamp"`
+	Source      *Provenance `yaml:"source,omitempty"`
 }
 
 // Gotcha represents a gotcha or pitfall encountered during a task
 type Gotcha struct {
-	Title       string    `yaml:"title"`
-	Description string    `yaml:"description"`
-	Solution    string    `yaml:"solution,omitempty"`
-	Prevention  string    `yaml:"prevention,omitempty"`
-	Severity    string    `yaml:"severity,omitempty"` // low, medium, high, critical
-	Tags        []string  `yaml:"tags,omitempty"`
-	Timestamp   time.Time `yaml:"timestamp"`
+	Title       string      `yaml:"title"`
+	Description string      `yaml:"description"`
+	Solution    string      `yaml:"solution,omitempty"`
+	Prevention  string      `yaml:"prevention,omitempty"`
+	Severity    string      `yaml:"severity,omitempty"` // low, medium, high, critical
+	Tags        []string    `yaml:"tags,omitempty"`
+	Timestamp   time.Time   `yaml:"timestamp"`
+	Source      *Provenance `yaml:"source,omitempty"`
 }
 
 // HandoffDocument represents a handoff document for an archived task
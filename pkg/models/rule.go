@@ -4,12 +4,18 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Rule is one declarative automation rule (decision D7). It reads:
 //
 //	on <trigger> [if <graph condition>] run <action> → write <outputs>
 //
+// or, for workflow-shaped automation, `steps` in place of `run`: ordered
+// actions, each with its own timeout and retries, whose outputs template into
+// the steps after them, plus `on_failure` steps run when one fails.
+//
 // A rule is authored into automation/rules.yaml (the source of truth) and
 // surfaced by `adb schedule`. The logic a rule invokes lives in a skill or an
 // external command — never in Go — so the engine only decides WHEN a rule fires
@@ -26,29 +32,118 @@ type Rule struct {
 	// If is an optional graph condition that must hold for the rule to fire.
 	If *RuleCondition `yaml:"if,omitempty" json:"if,omitempty"`
 	// Run is the action the rule invokes — exactly one of a skill or a command.
-	Run RuleAction `yaml:"run" json:"run"`
+	// A rule sets Run or Steps, not both.
+	Run RuleAction `yaml:"run,omitempty" json:"run"`
+	// Steps is a workflow run in order in place of Run; the first step that
+	// still fails after its retries stops it.
+	Steps []RuleStep `yaml:"steps,omitempty" json:"steps,omitempty"`
+	// OnFailure steps run, in order, when the action or a step fails. They can
+	// read {{.error}} and {{.failed_step}}.
+	OnFailure []RuleStep `yaml:"on_failure,omitempty" json:"on_failure,omitempty"`
+	// Concurrency optionally keys firings and debounces or throttles them per
+	// key.
+	Concurrency *RuleConcurrency `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
 	// Write is the set of outputs applied after the action succeeds — artifacts
 	// and/or typed graph edges (the #109 edge model).
 	Write []RuleOutput `yaml:"write,omitempty" json:"write,omitempty"`
 }
 
 // RuleTrigger is a rule's trigger. Exactly one of Schedule (time-triggered) or
-// Event (event-triggered) is set. Schedule is a Go duration string (e.g. "15m",
-// "6h"); Event is one of the observability KnownEventTypes (e.g.
+// Event (event-triggered) is set. Schedule is either a Go duration (e.g. "15m",
+// "6h") or a cron expression ("0 9 * * MON-FRI", "@daily"; see CronSchedule);
+// Event is one of the observability KnownEventTypes (e.g.
 // "task.status_changed"). The two-mode split mirrors decision D7: time-triggers
 // first, event-triggers next.
+//
+// Timezone, Misfire and IgnoreCalendar only apply to a schedule. Timezone is
+// an IANA zone name the cron expression is evaluated in (default: the
+// workspace automation.timezone, else the host zone). Misfire says what a
+// restarted scheduler does about firings it missed while down. IgnoreCalendar
+// lets the rule fire through the workspace quiet hours and holidays.
 type RuleTrigger struct {
-	Schedule string `yaml:"schedule,omitempty" json:"schedule,omitempty"`
-	Event    string `yaml:"event,omitempty" json:"event,omitempty"`
+	Schedule       string        `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+	Event          string        `yaml:"event,omitempty" json:"event,omitempty"`
+	Timezone       string        `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	Misfire        MisfirePolicy `yaml:"misfire,omitempty" json:"misfire,omitempty"`
+	IgnoreCalendar bool          `yaml:"ignore_calendar,omitempty" json:"ignore_calendar,omitempty"`
 }
 
-// RuleCondition is an optional graph guard: the resolved Entity must have an
-// incident edge of type HasEdge for the rule to fire. Entity may be a literal
-// entity id (a task id, initiative id) or a "{{.field}}" template resolved
-// against the triggering event's payload (e.g. "{{.task_id}}").
+// MisfirePolicy is what the scheduler does with the firings of a time rule
+// that fell due while it was not running.
+type MisfirePolicy string
+
+const (
+	// MisfireRunOnce fires once to catch up, however many runs were missed.
+	// It is the default (an empty policy).
+	MisfireRunOnce MisfirePolicy = "run_once"
+	// MisfireRunAll fires once per missed run, up to a catch-up cap.
+	MisfireRunAll MisfirePolicy = "run_all"
+	// MisfireSkip drops missed runs and waits for the next due time.
+	MisfireSkip MisfirePolicy = "skip"
+)
+
+// Valid reports whether p is a known policy. Empty is valid (run_once).
+func (p MisfirePolicy) Valid() bool {
+	switch p {
+	case "", MisfireRunOnce, MisfireRunAll, MisfireSkip:
+		return true
+	}
+	return false
+}
+
+// Cadence is when a time-triggered rule falls due: Next returns the first due
+// instant strictly after the given time (zero when there is none).
+type Cadence interface {
+	Next(after time.Time) time.Time
+}
+
+// IntervalCadence is the cadence of a duration schedule: due every D after
+// the previous due time.
+type IntervalCadence time.Duration
+
+// Next returns after plus the interval.
+func (c IntervalCadence) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(c))
+}
+
+// RuleCondition is an optional guard on a rule, in one of two forms.
+//
+// The graph form: the resolved Entity must have an incident edge of type
+// HasEdge for the rule to fire. Entity may be a literal entity id (a task id,
+// initiative id) or a "{{.field}}" template resolved against the triggering
+// event's payload (e.g. "{{.task_id}}").
+//
+// The expression form: Expr is a RuleExpr evaluated against the event payload,
+// the task the firing is about and the graph. Entity, when set, names that task
+// (it may template); otherwise it is the payload's task_id. In YAML a bare
+// string is shorthand for an expression-only condition:
+//
+//	if: 'task.priority == "P0" && !has_edge(task, "depends_on")'
+//
+// When both HasEdge and Expr are set, both must hold.
 type RuleCondition struct {
-	Entity  string   `yaml:"entity" json:"entity"`
-	HasEdge EdgeType `yaml:"has_edge" json:"has_edge"`
+	Entity  string   `yaml:"entity,omitempty" json:"entity,omitempty"`
+	HasEdge EdgeType `yaml:"has_edge,omitempty" json:"has_edge,omitempty"`
+	Expr    string   `yaml:"expr,omitempty" json:"expr,omitempty"`
+}
+
+// UnmarshalYAML accepts the mapping form or a bare expression string.
+func (c *RuleCondition) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*c = RuleCondition{Expr: node.Value}
+		return nil
+	}
+	type plain RuleCondition
+	return node.Decode((*plain)(c))
+}
+
+// MarshalYAML writes an expression-only condition back as a bare string.
+func (c RuleCondition) MarshalYAML() (interface{}, error) {
+	if c.Expr != "" && c.Entity == "" && c.HasEdge == "" {
+		return c.Expr, nil
+	}
+	type plain RuleCondition
+	return plain(c), nil
 }
 
 // RuleAction is what a rule invokes when it fires. Exactly one of Skill or Exec
@@ -61,6 +156,79 @@ type RuleAction struct {
 	Exec  []string `yaml:"exec,omitempty" json:"exec,omitempty"`
 }
 
+// RuleStep is one workflow step: an action (a skill or an exec, as in
+// RuleAction) plus how to run it. Name identifies the step to later templates
+// — {{.steps.<name>.output}} is its trimmed output — and defaults to step<N>
+// (1-based). Timeout bounds each attempt. Retries re-runs a failed attempt,
+// waiting Backoff (default 1s) before the first retry and doubling it after
+// each. With adds templated key/values to a skill step's request payload.
+type RuleStep struct {
+	Name       string `yaml:"name,omitempty" json:"name,omitempty"`
+	RuleAction `yaml:",inline"`
+	With       map[string]string `yaml:"with,omitempty" json:"with,omitempty"`
+	Timeout    string            `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Retries    int               `yaml:"retries,omitempty" json:"retries,omitempty"`
+	Backoff    string            `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+}
+
+// MaxStepRetries bounds RuleStep.Retries.
+const MaxStepRetries = 10
+
+// DefaultStepBackoff is the wait before a step's first retry.
+const DefaultStepBackoff = time.Second
+
+// TimeoutDuration parses Timeout; zero means no timeout.
+func (s RuleStep) TimeoutDuration() (time.Duration, error) {
+	return optionalDuration("timeout", s.Timeout)
+}
+
+// BackoffDuration parses Backoff, defaulting to DefaultStepBackoff.
+func (s RuleStep) BackoffDuration() (time.Duration, error) {
+	d, err := optionalDuration("backoff", s.Backoff)
+	if err == nil && d == 0 {
+		d = DefaultStepBackoff
+	}
+	return d, err
+}
+
+// RuleConcurrency groups a rule's firings under Key (a template over the
+// event payload, e.g. "{{.task_id}}"; default: one group for the whole rule).
+// Two firings with the same key never run at once. Debounce (event rules
+// only) holds a firing until no further event for its key has arrived for
+// that long, then runs once with the latest event — ten status changes in a
+// minute become one run. Throttle runs the first firing for a key and skips
+// the rest until that long has passed. Set at most one of the two.
+type RuleConcurrency struct {
+	Key      string `yaml:"key,omitempty" json:"key,omitempty"`
+	Debounce string `yaml:"debounce,omitempty" json:"debounce,omitempty"`
+	Throttle string `yaml:"throttle,omitempty" json:"throttle,omitempty"`
+}
+
+// DebounceDuration parses Debounce; zero means no debounce.
+func (c RuleConcurrency) DebounceDuration() (time.Duration, error) {
+	return optionalDuration("debounce", c.Debounce)
+}
+
+// ThrottleDuration parses Throttle; zero means no throttle.
+func (c RuleConcurrency) ThrottleDuration() (time.Duration, error) {
+	return optionalDuration("throttle", c.Throttle)
+}
+
+// optionalDuration parses a positive Go duration, or "" as zero.
+func optionalDuration(field, s string) (time.Duration, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", field, s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s %q must be a positive duration", field, s)
+	}
+	return d, nil
+}
+
 // RuleOutput is one output applied after a rule's action succeeds. Each output
 // writes an Artifact (a file under the workspace, path may template) and/or a
 // typed graph Edge onto a source entity's frontmatter. At least one of the two
@@ -82,6 +250,50 @@ type RuleSet struct {
 	Rules []Rule `yaml:"rules" json:"rules"`
 }
 
+// Workflow returns the steps the rule runs: Steps, or Run as a single step
+// named "run". Unnamed steps get their step<N> default.
+func (r Rule) Workflow() []RuleStep {
+	if len(r.Steps) == 0 {
+		return []RuleStep{{Name: "run", RuleAction: r.Run}}
+	}
+	return namedSteps(r.Steps)
+}
+
+// FailureSteps returns OnFailure with default names (on_failure<N>).
+func (r Rule) FailureSteps() []RuleStep {
+	out := make([]RuleStep, len(r.OnFailure))
+	for i, s := range r.OnFailure {
+		if strings.TrimSpace(s.Name) == "" {
+			s.Name = fmt.Sprintf("on_failure%d", i+1)
+		}
+		out[i] = s
+	}
+	return out
+}
+
+// RunsExec reports whether any of the rule's steps, on_failure included, runs
+// an exec command: the rules sync inline dispatch leaves to the
+// automation-dispatch drain, since a command can take as long as its timeout.
+func (r Rule) RunsExec() bool {
+	for _, st := range append(r.Workflow(), r.OnFailure...) {
+		if len(st.Exec) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func namedSteps(steps []RuleStep) []RuleStep {
+	out := make([]RuleStep, len(steps))
+	for i, s := range steps {
+		if strings.TrimSpace(s.Name) == "" {
+			s.Name = fmt.Sprintf("step%d", i+1)
+		}
+		out[i] = s
+	}
+	return out
+}
+
 // IsEnabled reports whether the rule is active. A nil Enabled pointer means
 // enabled (the default), so an unset field keeps the rule live.
 func (r Rule) IsEnabled() bool {
@@ -94,8 +306,12 @@ func (t RuleTrigger) IsSchedule() bool { return strings.TrimSpace(t.Schedule) !=
 // IsEvent reports whether the trigger is an event type.
 func (t RuleTrigger) IsEvent() bool { return strings.TrimSpace(t.Event) != "" }
 
+// IsCron reports whether the schedule is a cron expression rather than a
+// duration.
+func (t RuleTrigger) IsCron() bool { return t.IsSchedule() && IsCronExpression(t.Schedule) }
+
 // Interval parses the schedule as a Go duration. It errors for an event
-// trigger or an unparseable / non-positive duration.
+// trigger, a cron schedule, or an unparseable / non-positive duration.
 func (t RuleTrigger) Interval() (time.Duration, error) {
 	if !t.IsSchedule() {
 		return 0, fmt.Errorf("trigger is not a schedule")
@@ -110,6 +326,27 @@ func (t RuleTrigger) Interval() (time.Duration, error) {
 	return d, nil
 }
 
+// Cadence parses the schedule into when the rule falls due. A cron schedule is
+// evaluated in the trigger's Timezone, else in def (UTC when def is nil).
+func (t RuleTrigger) Cadence(def *time.Location) (Cadence, error) {
+	if !t.IsCron() {
+		d, err := t.Interval()
+		if err != nil {
+			return nil, err
+		}
+		return IntervalCadence(d), nil
+	}
+	loc := def
+	if tz := strings.TrimSpace(t.Timezone); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+		loc = l
+	}
+	return ParseCron(t.Schedule, loc)
+}
+
 // Validate checks a rule is structurally well-formed: a name, exactly one
 // trigger, exactly one action, a complete condition when present, and complete
 // outputs. It is deliberately structural — it does NOT check that an event type
@@ -124,30 +361,75 @@ func (r Rule) Validate() error {
 	case r.On.IsSchedule() && r.On.IsEvent():
 		return fmt.Errorf("rule %q: trigger has both a schedule and an event; set exactly one", r.Name)
 	case r.On.IsSchedule():
-		if _, err := r.On.Interval(); err != nil {
+		if _, err := r.On.Cadence(nil); err != nil {
 			return fmt.Errorf("rule %q: %w", r.Name, err)
 		}
+		if !r.On.Misfire.Valid() {
+			return fmt.Errorf("rule %q: invalid misfire policy %q (want run_once, run_all or skip)", r.Name, r.On.Misfire)
+		}
 	case r.On.IsEvent():
 		// structural OK; known-type validation is the write surface's job
+		if r.On.Timezone != "" || r.On.Misfire != "" || r.On.IgnoreCalendar {
+			return fmt.Errorf("rule %q: timezone, misfire and ignore_calendar only apply to a schedule", r.Name)
+		}
 	default:
 		return fmt.Errorf("rule %q: trigger must set a schedule or an event", r.Name)
 	}
-	// Action: exactly one of skill / exec.
+	// Action: exactly one of skill / exec, or a workflow of steps.
 	hasSkill := strings.TrimSpace(r.Run.Skill) != ""
 	hasExec := len(r.Run.Exec) > 0
 	switch {
+	case len(r.Steps) > 0:
+		if hasSkill || hasExec {
+			return fmt.Errorf("rule %q: set run or steps, not both", r.Name)
+		}
 	case hasSkill && hasExec:
 		return fmt.Errorf("rule %q: action has both a skill and an exec; set exactly one", r.Name)
 	case !hasSkill && !hasExec:
 		return fmt.Errorf("rule %q: action must set a skill or an exec", r.Name)
 	}
-	// Condition (optional): both fields required when present.
+	names := map[string]bool{"run": len(r.Steps) == 0}
+	for _, group := range []struct {
+		field string
+		steps []RuleStep
+	}{{"steps", namedSteps(r.Steps)}, {"on_failure", r.FailureSteps()}} {
+		for i, st := range group.steps {
+			if err := st.validate(names); err != nil {
+				return fmt.Errorf("rule %q: %s[%d]: %w", r.Name, group.field, i, err)
+			}
+		}
+	}
+	if c := r.Concurrency; c != nil {
+		debounce, err := c.DebounceDuration()
+		if err != nil {
+			return fmt.Errorf("rule %q: concurrency: %w", r.Name, err)
+		}
+		throttle, err := c.ThrottleDuration()
+		if err != nil {
+			return fmt.Errorf("rule %q: concurrency: %w", r.Name, err)
+		}
+		if debounce > 0 && throttle > 0 {
+			return fmt.Errorf("rule %q: concurrency: set debounce or throttle, not both", r.Name)
+		}
+		if debounce > 0 && !r.On.IsEvent() {
+			return fmt.Errorf("rule %q: concurrency: debounce only applies to an event trigger", r.Name)
+		}
+	}
+	// Condition (optional): an expression that compiles, and/or a graph guard
+	// with both its fields.
 	if r.If != nil {
-		if strings.TrimSpace(r.If.Entity) == "" {
+		hasExpr := strings.TrimSpace(r.If.Expr) != ""
+		hasEdge := strings.TrimSpace(string(r.If.HasEdge)) != ""
+		if hasExpr {
+			if _, err := CompileRuleExpr(r.If.Expr); err != nil {
+				return fmt.Errorf("rule %q: if: %w", r.Name, err)
+			}
+		}
+		if hasEdge && strings.TrimSpace(r.If.Entity) == "" {
 			return fmt.Errorf("rule %q: condition needs an entity", r.Name)
 		}
-		if strings.TrimSpace(string(r.If.HasEdge)) == "" {
-			return fmt.Errorf("rule %q: condition needs a has_edge type", r.Name)
+		if !hasEdge && !hasExpr {
+			return fmt.Errorf("rule %q: condition needs a has_edge type or an expr", r.Name)
 		}
 	}
 	// Outputs (optional): each must write an artifact and/or a complete edge.
@@ -165,6 +447,52 @@ func (r Rule) Validate() error {
 	return nil
 }
 
+// validate checks one step and claims its name in names.
+func (s RuleStep) validate(names map[string]bool) error {
+	if !isTemplateIdent(s.Name) {
+		return fmt.Errorf("step name %q must be letters, digits and underscores (it is read as {{.steps.%s.output}})", s.Name, s.Name)
+	}
+	if names[s.Name] {
+		return fmt.Errorf("duplicate step name %q", s.Name)
+	}
+	names[s.Name] = true
+	hasSkill := strings.TrimSpace(s.Skill) != ""
+	hasExec := len(s.Exec) > 0
+	switch {
+	case hasSkill && hasExec:
+		return fmt.Errorf("step %q has both a skill and an exec; set exactly one", s.Name)
+	case !hasSkill && !hasExec:
+		return fmt.Errorf("step %q must set a skill or an exec", s.Name)
+	case len(s.With) > 0 && !hasSkill:
+		return fmt.Errorf("step %q: with only applies to a skill step", s.Name)
+	}
+	if _, err := s.TimeoutDuration(); err != nil {
+		return fmt.Errorf("step %q: %w", s.Name, err)
+	}
+	if _, err := s.BackoffDuration(); err != nil {
+		return fmt.Errorf("step %q: %w", s.Name, err)
+	}
+	if s.Retries < 0 || s.Retries > MaxStepRetries {
+		return fmt.Errorf("step %q: retries must be 0..%d", s.Name, MaxStepRetries)
+	}
+	return nil
+}
+
+func isTemplateIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // Validate checks every rule and rejects duplicate names.
 func (rs RuleSet) Validate() error {
 	seen := make(map[string]struct{}, len(rs.Rules))
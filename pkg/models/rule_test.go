@@ -1,8 +1,11 @@
 package models
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestRule_IsEnabled(t *testing.T) {
@@ -105,3 +108,169 @@ func TestRuleSet_Validate_DuplicateNames(t *testing.T) {
 		t.Fatal("expected duplicate-name error, got nil")
 	}
 }
+
+func TestRuleTrigger_Cadence(t *testing.T) {
+	from := time.Date(2026, 7, 1, 10, 30, 0, 0, time.UTC)
+	c, err := (RuleTrigger{Schedule: "15m"}).Cadence(nil)
+	if err != nil || !c.Next(from).Equal(from.Add(15*time.Minute)) {
+		t.Fatalf("duration cadence = %v, %v", c, err)
+	}
+	c, err = (RuleTrigger{Schedule: "0 9 * * MON-FRI", Timezone: "UTC"}).Cadence(time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Next(from), time.Date(2026, 7, 2, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("cron cadence Next = %v, want %v", got, want)
+	}
+	if _, err := (RuleTrigger{Schedule: "@daily", Timezone: "Mars/Olympus"}).Cadence(nil); err == nil {
+		t.Error("expected an error for an unknown timezone")
+	}
+	if _, err := (RuleTrigger{Schedule: "0 9 * *"}).Interval(); err == nil {
+		t.Error("Interval should refuse a cron schedule")
+	}
+}
+
+func TestRule_Validate_ScheduleOptions(t *testing.T) {
+	ok := Rule{Name: "standup", On: RuleTrigger{Schedule: "0 9 * * MON-FRI", Timezone: "UTC", Misfire: MisfireSkip}, Run: RuleAction{Skill: "s"}}
+	if err := ok.Validate(); err != nil {
+		t.Fatalf("valid cron rule rejected: %v", err)
+	}
+	for name, trig := range map[string]RuleTrigger{
+		"bad cron":          {Schedule: "0 25 * * *"},
+		"bad misfire":       {Schedule: "@daily", Misfire: "later"},
+		"bad timezone":      {Schedule: "@daily", Timezone: "Nowhere/Special"},
+		"event with tz":     {Event: "task.created", Timezone: "UTC"},
+		"event with policy": {Event: "task.created", Misfire: MisfireSkip},
+	} {
+		r := Rule{Name: "r", On: trig, Run: RuleAction{Skill: "s"}}
+		if err := r.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestRuleCondition_ExprYAML(t *testing.T) {
+	src := `rules:
+  - name: p0-blocked
+    on: {event: task.status_changed}
+    if: 'task.priority == "P0" && payload.new_status == "blocked"'
+    run: {skill: escalate}
+  - name: both
+    on: {event: task.status_changed}
+    if: {entity: "{{.task_id}}", has_edge: depends_on, expr: 'task.status == "blocked"'}
+    run: {skill: triage}
+`
+	var rs RuleSet
+	if err := yaml.Unmarshal([]byte(src), &rs); err != nil {
+		t.Fatal(err)
+	}
+	if err := rs.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if c := rs.Rules[0].If; c == nil || c.Expr != `task.priority == "P0" && payload.new_status == "blocked"` || c.HasEdge != "" {
+		t.Errorf("bare-string condition = %+v", c)
+	}
+	if c := rs.Rules[1].If; c.HasEdge != EdgeDependsOn || c.Expr == "" {
+		t.Errorf("mapping condition = %+v", c)
+	}
+
+	out, err := yaml.Marshal(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), `if: task.priority == "P0" && payload.new_status == "blocked"`) {
+		t.Errorf("an expression-only condition should marshal back to a bare string:\n%s", out)
+	}
+}
+
+func TestRule_Validate_ExprNamesRuleAndColumn(t *testing.T) {
+	r := Rule{Name: "p0", On: RuleTrigger{Event: "task.created"}, Run: RuleAction{Skill: "s"},
+		If: &RuleCondition{Expr: `task.prio == "P0"`}}
+	err := r.Validate()
+	if err == nil || !strings.Contains(err.Error(), `rule "p0": if: column 6: unknown task field "prio"`) {
+		t.Fatalf("Validate error = %v", err)
+	}
+}
+
+func TestRule_WorkflowYAML(t *testing.T) {
+	src := `rules:
+  - name: release-notes
+    on: {event: task.status_changed}
+    steps:
+      - name: collect
+        exec: [git, log, --oneline, -20]
+        timeout: 30s
+        retries: 2
+        backoff: 5s
+      - skill: release-notes
+        with: {log: "{{.steps.collect.output}}"}
+    on_failure:
+      - exec: [notify, "{{.failed_step}}: {{.error}}"]
+    concurrency: {key: "{{.task_id}}", debounce: 1m}
+`
+	var rs RuleSet
+	if err := yaml.Unmarshal([]byte(src), &rs); err != nil {
+		t.Fatal(err)
+	}
+	if err := rs.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	r := rs.Rules[0]
+	steps := r.Workflow()
+	if len(steps) != 2 || steps[0].Name != "collect" || steps[0].Retries != 2 || steps[1].Name != "step2" || steps[1].Skill != "release-notes" {
+		t.Fatalf("Workflow() = %+v", steps)
+	}
+	if d, _ := steps[0].TimeoutDuration(); d != 30*time.Second {
+		t.Errorf("timeout = %v", d)
+	}
+	if d, _ := steps[1].BackoffDuration(); d != DefaultStepBackoff {
+		t.Errorf("default backoff = %v", d)
+	}
+	if f := r.FailureSteps(); len(f) != 1 || f[0].Name != "on_failure1" {
+		t.Errorf("FailureSteps() = %+v", f)
+	}
+	if d, _ := r.Concurrency.DebounceDuration(); d != time.Minute {
+		t.Errorf("debounce = %v", d)
+	}
+
+	// A single-action rule is a one-step workflow named "run", and marshals
+	// without a steps key.
+	single := Rule{Name: "s", On: RuleTrigger{Schedule: "1h"}, Run: RuleAction{Skill: "x"}}
+	if w := single.Workflow(); len(w) != 1 || w[0].Name != "run" || w[0].Skill != "x" {
+		t.Errorf("single Workflow() = %+v", w)
+	}
+	out, _ := yaml.Marshal(RuleSet{Rules: []Rule{{Name: "w", On: RuleTrigger{Event: "task.created"}, Steps: []RuleStep{{RuleAction: RuleAction{Skill: "x"}}}}}})
+	if strings.Contains(string(out), "run:") || !strings.Contains(string(out), "- skill: x") {
+		t.Errorf("a steps rule should marshal without run and with inline step actions:\n%s", out)
+	}
+}
+
+func TestRule_Validate_WorkflowRejections(t *testing.T) {
+	step := func(name string) RuleStep { return RuleStep{Name: name, RuleAction: RuleAction{Skill: "s"}} }
+	cases := map[string]struct {
+		rule Rule
+		want string
+	}{
+		"run and steps":      {Rule{Run: RuleAction{Skill: "s"}, Steps: []RuleStep{step("a")}}, "set run or steps, not both"},
+		"empty step":         {Rule{Steps: []RuleStep{{Name: "a"}}}, `steps[0]: step "a" must set a skill or an exec`},
+		"hyphenated name":    {Rule{Steps: []RuleStep{step("send-mail")}}, "letters, digits and underscores"},
+		"duplicate name":     {Rule{Steps: []RuleStep{step("a"), step("a")}}, `duplicate step name "a"`},
+		"clashing failure":   {Rule{Steps: []RuleStep{step("a")}, OnFailure: []RuleStep{step("a")}}, `on_failure[0]: duplicate step name "a"`},
+		"bad timeout":        {Rule{Steps: []RuleStep{{Name: "a", RuleAction: RuleAction{Skill: "s"}, Timeout: "soon"}}}, "invalid timeout"},
+		"too many retries":   {Rule{Steps: []RuleStep{{Name: "a", RuleAction: RuleAction{Skill: "s"}, Retries: 11}}}, "retries must be 0..10"},
+		"with on exec":       {Rule{Steps: []RuleStep{{Name: "a", RuleAction: RuleAction{Exec: []string{"x"}}, With: map[string]string{"k": "v"}}}}, "with only applies to a skill step"},
+		"debounce+throttle":  {Rule{Run: RuleAction{Skill: "s"}, Concurrency: &RuleConcurrency{Debounce: "1m", Throttle: "1m"}}, "not both"},
+		"bad debounce":       {Rule{Run: RuleAction{Skill: "s"}, Concurrency: &RuleConcurrency{Debounce: "-1m"}}, "must be a positive duration"},
+		"scheduled debounce": {Rule{On: RuleTrigger{Schedule: "1h"}, Run: RuleAction{Skill: "s"}, Concurrency: &RuleConcurrency{Debounce: "1m"}}, "debounce only applies to an event trigger"},
+	}
+	for name, tc := range cases {
+		r := tc.rule
+		r.Name = "wf"
+		if r.On == (RuleTrigger{}) {
+			r.On = RuleTrigger{Event: "task.created"}
+		}
+		if err := r.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: Validate error = %v, want %q", name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,1021 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RuleExpr is a compiled rule condition expression: a small, sandboxed,
+// side-effect-free boolean language for a rule's `if:` clause. It reads the
+// triggering event's payload, the task the firing is about, and the graph:
+//
+//	task.priority == "P0" && payload.new_status == "blocked" && !has_edge(task, "depends_on")
+//
+// Names: `payload.<key>` (or `payload["key"]`) is an event payload value,
+// missing keys are null; `task` is the resolved task (null when there is
+// none) with the fields listed in ruleExprTaskFields; `event` is the
+// triggering event type. Literals are strings ("…" or '…'), numbers, true,
+// false, null and lists ([…]). Operators, loosest first: ||, &&, the
+// comparisons == != < <= > >= and `in` (list membership or substring), then
+// unary !. Functions are listed in ruleExprFuncs.
+//
+// Payload values are strings; a comparison against a number compares
+// numerically when the string parses as one. A comparison or a boolean
+// operator that meets null is false rather than an error, so a condition
+// over a missing field simply does not hold. There are no loops, assignments
+// or host calls beyond the graph lookups an Env provides, and evaluation is
+// bounded by the expression's size.
+type RuleExpr struct {
+	src  string
+	root exprNode
+}
+
+// RuleExprEnv is what a RuleExpr evaluates against. Task may be nil.
+// Neighbors returns the entities incident to entity through an edge of the
+// given type; nil makes every graph function see no edges.
+type RuleExprEnv struct {
+	Event     string
+	Payload   map[string]string
+	Task      *Task
+	Now       time.Time
+	Neighbors func(entity string, edge EdgeType) ([]string, error)
+}
+
+// ruleExprTaskFields is every field `task.<name>` may read, with its type.
+var ruleExprTaskFields = map[string]exprType{
+	"id": typeString, "title": typeString, "type": typeString, "status": typeString,
+	"priority": typeString, "owner": typeString, "repo": typeString, "branch": typeString,
+	"initiative": typeString, "source": typeString,
+	"tags": typeList, "teams": typeList, "blocked_by": typeList,
+	"age_days": typeNumber, "idle_days": typeNumber,
+}
+
+// ruleExprFuncs is every callable function: its parameter types and result.
+var ruleExprFuncs = map[string]struct {
+	params []exprType
+	result exprType
+	doc    string
+}{
+	"has_edge":    {[]exprType{typeEntity, typeString}, typeBool, "has_edge(entity, type): entity has an incident edge of that type"},
+	"neighbors":   {[]exprType{typeEntity, typeString}, typeList, "neighbors(entity, type): the entities linked to entity by that edge type"},
+	"contains":    {[]exprType{typeAny, typeAny}, typeBool, "contains(list or string, value)"},
+	"starts_with": {[]exprType{typeString, typeString}, typeBool, "starts_with(s, prefix)"},
+	"ends_with":   {[]exprType{typeString, typeString}, typeBool, "ends_with(s, suffix)"},
+	"lower":       {[]exprType{typeString}, typeString, "lower(s)"},
+	"len":         {[]exprType{typeAny}, typeNumber, "len(list or string)"},
+}
+
+// CompileRuleExpr parses and type-checks src. Errors name the column of the
+// offending token.
+func CompileRuleExpr(src string) (*RuleExpr, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	toks, err := lexRuleExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, exprErrorf(t.pos, "unexpected %s", t)
+	}
+	typ, err := checkExpr(root)
+	if err != nil {
+		return nil, err
+	}
+	if typ != typeBool && typ != typeAny {
+		return nil, exprErrorf(root.position(), "expression is a %s, want a boolean", typ)
+	}
+	return &RuleExpr{src: src, root: root}, nil
+}
+
+// String returns the source expression.
+func (x *RuleExpr) String() string { return x.src }
+
+// Eval evaluates the expression. A null result is false.
+func (x *RuleExpr) Eval(env RuleExprEnv) (bool, error) {
+	v, err := x.root.eval(&env)
+	if err != nil {
+		return false, err
+	}
+	b, _ := v.(bool)
+	return b, nil
+}
+
+// ---- lexer ----
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+type exprToken struct {
+	kind tokKind
+	text string
+	pos  int // 0-based byte offset
+}
+
+func (t exprToken) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+var exprCompareOps = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+var exprOps = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ",", "."}
+
+func lexRuleExpr(src string) ([]exprToken, error) {
+	var toks []exprToken
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			start := i
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(src) {
+					return nil, exprErrorf(start, "unterminated string")
+				}
+				if rune(src[i]) == c {
+					i++
+					break
+				}
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+				}
+				b.WriteByte(src[i])
+				i++
+			}
+			toks = append(toks, exprToken{tokString, b.String(), start})
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			toks = append(toks, exprToken{tokNumber, src[start:i], start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			toks = append(toks, exprToken{tokIdent, src[start:i], start})
+		default:
+			matched := false
+			for _, op := range exprOps {
+				if strings.HasPrefix(src[i:], op) {
+					toks = append(toks, exprToken{tokOp, op, i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				if c == '=' || c == '&' || c == '|' {
+					return nil, exprErrorf(i, "unexpected %q (did you mean %q?)", string(c), strings.Repeat(string(c), 2))
+				}
+				return nil, exprErrorf(i, "unexpected character %q", string(c))
+			}
+		}
+	}
+	return append(toks, exprToken{kind: tokEOF, pos: len(src)}), nil
+}
+
+func exprErrorf(pos int, format string, args ...interface{}) error {
+	return fmt.Errorf("column %d: %s", pos+1, fmt.Sprintf(format, args...))
+}
+
+// ---- parser ----
+
+type exprParser struct {
+	toks []exprToken
+	i    int
+}
+
+func (p *exprParser) peek() exprToken { return p.toks[p.i] }
+
+func (p *exprParser) next() exprToken {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *exprParser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.text == op
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.isOp(op) {
+		t := p.peek()
+		return exprErrorf(t.pos, "expected %q, got %s", op, t)
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		op := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op.text, pos: op.pos, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		op := p.next()
+		right, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op.text, pos: op.pos, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseCompare() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if (t.kind == tokOp && exprCompareOps[t.text]) || (t.kind == tokIdent && t.text == "in") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: t.text, pos: t.pos, left: left, right: right}
+		if n := p.peek(); (n.kind == tokOp && exprCompareOps[n.text]) || (n.kind == tokIdent && n.text == "in") {
+			return nil, exprErrorf(n.pos, "comparisons do not chain; combine them with && or ||")
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.isOp("!") {
+		op := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{pos: op.pos, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *exprParser) parsePostfix() (exprNode, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isOp("."):
+			p.next()
+			t := p.next()
+			if t.kind != tokIdent {
+				return nil, exprErrorf(t.pos, "expected a field name after \".\", got %s", t)
+			}
+			n = &fieldNode{pos: t.pos, target: n, name: t.text}
+		case p.isOp("["):
+			open := p.next()
+			idx, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &indexNode{pos: open.pos, target: n, index: idx}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return &literalNode{pos: t.pos, value: t.text}, nil
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, exprErrorf(t.pos, "invalid number %q", t.text)
+		}
+		return &literalNode{pos: t.pos, value: f}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{pos: t.pos, value: true}, nil
+		case "false":
+			return &literalNode{pos: t.pos, value: false}, nil
+		case "null":
+			return &literalNode{pos: t.pos, value: nil}, nil
+		}
+		if p.isOp("(") {
+			p.next()
+			call := &callNode{pos: t.pos, name: t.text}
+			for !p.isOp(")") {
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				call.args = append(call.args, arg)
+				if !p.isOp(")") {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+			}
+			p.next()
+			return call, nil
+		}
+		return &nameNode{pos: t.pos, name: t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			list := &listNode{pos: t.pos}
+			for !p.isOp("]") {
+				item, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, item)
+				if !p.isOp("]") {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+			}
+			p.next()
+			return list, nil
+		}
+	}
+	return nil, exprErrorf(t.pos, "unexpected %s", t)
+}
+
+// ---- static types ----
+
+type exprType int
+
+const (
+	typeAny exprType = iota
+	typeNull
+	typeBool
+	typeString
+	typeNumber
+	typeList
+	typeTask
+	typePayload
+	typeEntity // parameter type: a task or an entity id string
+)
+
+func (t exprType) String() string {
+	return [...]string{"value", "null", "boolean", "string", "number", "list", "task", "payload", "entity"}[t]
+}
+
+func checkExpr(n exprNode) (exprType, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		switch n.value.(type) {
+		case nil:
+			return typeNull, nil
+		case bool:
+			return typeBool, nil
+		case string:
+			return typeString, nil
+		default:
+			return typeNumber, nil
+		}
+	case *listNode:
+		for _, item := range n.items {
+			if _, err := checkExpr(item); err != nil {
+				return 0, err
+			}
+		}
+		return typeList, nil
+	case *nameNode:
+		switch n.name {
+		case "task":
+			return typeTask, nil
+		case "payload":
+			return typePayload, nil
+		case "event":
+			return typeString, nil
+		}
+		return 0, exprErrorf(n.pos, "unknown name %q (want task, payload or event)", n.name)
+	case *fieldNode:
+		target, err := checkExpr(n.target)
+		if err != nil {
+			return 0, err
+		}
+		switch target {
+		case typePayload:
+			return typeAny, nil
+		case typeTask:
+			t, ok := ruleExprTaskFields[n.name]
+			if !ok {
+				return 0, exprErrorf(n.pos, "unknown task field %q (want one of %s)", n.name, strings.Join(sortedTaskFields(), ", "))
+			}
+			return t, nil
+		}
+		return 0, exprErrorf(n.pos, "a %s has no field %q", target, n.name)
+	case *indexNode:
+		target, err := checkExpr(n.target)
+		if err != nil {
+			return 0, err
+		}
+		idx, err := checkExpr(n.index)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case target == typePayload && (idx == typeString || idx == typeAny):
+			return typeAny, nil
+		case target == typeList && (idx == typeNumber || idx == typeAny):
+			return typeAny, nil
+		case target == typeAny:
+			return typeAny, nil
+		}
+		return 0, exprErrorf(n.pos, "cannot index a %s with a %s", target, idx)
+	case *notNode:
+		t, err := checkExpr(n.operand)
+		if err != nil {
+			return 0, err
+		}
+		if !boolish(t) {
+			return 0, exprErrorf(n.pos, "! needs a boolean, got a %s", t)
+		}
+		return typeBool, nil
+	case *callNode:
+		fn, ok := ruleExprFuncs[n.name]
+		if !ok {
+			return 0, exprErrorf(n.pos, "unknown function %q (want one of %s)", n.name, strings.Join(sortedFuncs(), ", "))
+		}
+		if len(n.args) != len(fn.params) {
+			return 0, exprErrorf(n.pos, "%s takes %d argument(s), got %d — %s", n.name, len(fn.params), len(n.args), fn.doc)
+		}
+		for i, arg := range n.args {
+			t, err := checkExpr(arg)
+			if err != nil {
+				return 0, err
+			}
+			if !assignable(t, fn.params[i]) {
+				return 0, exprErrorf(arg.position(), "argument %d of %s is a %s, want a %s — %s", i+1, n.name, t, fn.params[i], fn.doc)
+			}
+		}
+		if n.name == "has_edge" || n.name == "neighbors" {
+			if lit, ok := n.args[1].(*literalNode); ok {
+				if et, isStr := lit.value.(string); isStr && !EdgeType(et).IsCanonical() {
+					return 0, exprErrorf(lit.pos, "unknown edge type %q (want one of %s)", et, edgeTypeList())
+				}
+			}
+		}
+		return fn.result, nil
+	case *binaryNode:
+		left, err := checkExpr(n.left)
+		if err != nil {
+			return 0, err
+		}
+		right, err := checkExpr(n.right)
+		if err != nil {
+			return 0, err
+		}
+		switch n.op {
+		case "&&", "||":
+			if !boolish(left) {
+				return 0, exprErrorf(n.left.position(), "left side of %s is a %s, want a boolean", n.op, left)
+			}
+			if !boolish(right) {
+				return 0, exprErrorf(n.right.position(), "right side of %s is a %s, want a boolean", n.op, right)
+			}
+		case "in":
+			if right != typeList && right != typeString && right != typeAny && right != typeNull {
+				return 0, exprErrorf(n.right.position(), "right side of in is a %s, want a list or a string", right)
+			}
+		case "==", "!=":
+			if !comparable(left, right, false) {
+				return 0, exprErrorf(n.pos, "cannot compare a %s with a %s", left, right)
+			}
+		default:
+			if !comparable(left, right, true) {
+				return 0, exprErrorf(n.pos, "%s needs two numbers or two strings, got a %s and a %s", n.op, left, right)
+			}
+		}
+		return typeBool, nil
+	}
+	return 0, fmt.Errorf("unknown expression node %T", n)
+}
+
+func boolish(t exprType) bool { return t == typeBool || t == typeAny || t == typeNull }
+
+func assignable(t, param exprType) bool {
+	switch {
+	case t == typeAny || t == typeNull || param == typeAny || t == param:
+		return true
+	case param == typeEntity:
+		return t == typeTask || t == typeString
+	}
+	return false
+}
+
+func comparable(a, b exprType, ordered bool) bool {
+	if a == typeAny || b == typeAny || a == typeNull || b == typeNull {
+		return true
+	}
+	if a == typeTask || b == typeTask || a == typePayload || b == typePayload {
+		return false
+	}
+	if ordered {
+		return a == b && (a == typeNumber || a == typeString)
+	}
+	return a == b
+}
+
+func sortedTaskFields() []string {
+	out := make([]string, 0, len(ruleExprTaskFields))
+	for k := range ruleExprTaskFields {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortedFuncs() []string {
+	out := make([]string, 0, len(ruleExprFuncs))
+	for k := range ruleExprFuncs {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func edgeTypeList() string {
+	names := make([]string, len(CanonicalEdgeTypes))
+	for i, t := range CanonicalEdgeTypes {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ---- AST + evaluation ----
+
+// exprNode is one node of a compiled expression. Values are nil, bool,
+// string, float64, []interface{}, *Task or map[string]string.
+type exprNode interface {
+	position() int
+	eval(env *RuleExprEnv) (interface{}, error)
+}
+
+type literalNode struct {
+	pos   int
+	value interface{}
+}
+
+type listNode struct {
+	pos   int
+	items []exprNode
+}
+
+type nameNode struct {
+	pos  int
+	name string
+}
+
+type fieldNode struct {
+	pos    int
+	target exprNode
+	name   string
+}
+
+type indexNode struct {
+	pos           int
+	target, index exprNode
+}
+
+type notNode struct {
+	pos     int
+	operand exprNode
+}
+
+type callNode struct {
+	pos  int
+	name string
+	args []exprNode
+}
+
+type binaryNode struct {
+	op          string
+	pos         int
+	left, right exprNode
+}
+
+func (n *literalNode) position() int { return n.pos }
+func (n *listNode) position() int    { return n.pos }
+func (n *nameNode) position() int    { return n.pos }
+func (n *fieldNode) position() int   { return n.pos }
+func (n *indexNode) position() int   { return n.pos }
+func (n *notNode) position() int     { return n.pos }
+func (n *callNode) position() int    { return n.pos }
+func (n *binaryNode) position() int  { return n.pos }
+
+func (n *literalNode) eval(*RuleExprEnv) (interface{}, error) { return n.value, nil }
+
+func (n *listNode) eval(env *RuleExprEnv) (interface{}, error) {
+	out := make([]interface{}, len(n.items))
+	for i, item := range n.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func (n *nameNode) eval(env *RuleExprEnv) (interface{}, error) {
+	switch n.name {
+	case "task":
+		if env.Task == nil {
+			return nil, nil
+		}
+		return env.Task, nil
+	case "payload":
+		return env.Payload, nil
+	case "event":
+		return env.Event, nil
+	}
+	return nil, exprErrorf(n.pos, "unknown name %q", n.name)
+}
+
+func (n *fieldNode) eval(env *RuleExprEnv) (interface{}, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case map[string]string:
+		if v, ok := t[n.name]; ok {
+			return v, nil
+		}
+		return nil, nil
+	case *Task:
+		return taskField(t, n.name, env.Now), nil
+	}
+	return nil, nil
+}
+
+func (n *indexNode) eval(env *RuleExprEnv) (interface{}, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := n.index.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case map[string]string:
+		if key, ok := idx.(string); ok {
+			if v, ok := t[key]; ok {
+				return v, nil
+			}
+		}
+	case []interface{}:
+		if f, ok := toNumber(idx); ok && f >= 0 && int(f) < len(t) && f == math.Trunc(f) {
+			return t[int(f)], nil
+		}
+	}
+	return nil, nil
+}
+
+func (n *notNode) eval(env *RuleExprEnv) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch b := v.(type) {
+	case nil:
+		return true, nil
+	case bool:
+		return !b, nil
+	}
+	return nil, exprErrorf(n.pos, "! needs a boolean, got %s", describeValue(v))
+}
+
+func (n *callNode) eval(env *RuleExprEnv) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	switch n.name {
+	case "has_edge", "neighbors":
+		ids, err := neighborIDs(env, args[0], args[1])
+		if err != nil {
+			return nil, exprErrorf(n.pos, "%s: %v", n.name, err)
+		}
+		if n.name == "has_edge" {
+			return len(ids) > 0, nil
+		}
+		out := make([]interface{}, len(ids))
+		for i, id := range ids {
+			out[i] = id
+		}
+		return out, nil
+	case "contains":
+		return containsValue(args[0], args[1]), nil
+	case "starts_with", "ends_with":
+		s, ok1 := args[0].(string)
+		affix, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			return false, nil
+		}
+		if n.name == "starts_with" {
+			return strings.HasPrefix(s, affix), nil
+		}
+		return strings.HasSuffix(s, affix), nil
+	case "lower":
+		if s, ok := args[0].(string); ok {
+			return strings.ToLower(s), nil
+		}
+		return nil, nil
+	case "len":
+		switch t := args[0].(type) {
+		case string:
+			return float64(len([]rune(t))), nil
+		case []interface{}:
+			return float64(len(t)), nil
+		}
+		return float64(0), nil
+	}
+	return nil, exprErrorf(n.pos, "unknown function %q", n.name)
+}
+
+func (n *binaryNode) eval(env *RuleExprEnv) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "&&", "||":
+		lb, err := asBool(left, n.left.position())
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "&&" && !lb {
+			return false, nil
+		}
+		if n.op == "||" && lb {
+			return true, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		return asBool(right, n.right.position())
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return valuesEqual(left, right), nil
+	case "!=":
+		return !valuesEqual(left, right), nil
+	case "in":
+		return containsValue(right, left), nil
+	}
+	if left == nil || right == nil {
+		return false, nil
+	}
+	if lf, ok := toNumber(left); ok {
+		if rf, ok := toNumber(right); ok {
+			return compareOrdered(n.op, lf < rf, lf == rf), nil
+		}
+	}
+	ls, lok := left.(string)
+	rs, rok := right.(string)
+	if !lok || !rok {
+		return nil, exprErrorf(n.pos, "%s needs two numbers or two strings, got %s and %s", n.op, describeValue(left), describeValue(right))
+	}
+	return compareOrdered(n.op, ls < rs, ls == rs), nil
+}
+
+func compareOrdered(op string, less, equal bool) bool {
+	switch op {
+	case "<":
+		return less
+	case "<=":
+		return less || equal
+	case ">":
+		return !less && !equal
+	default: // ">="
+		return !less
+	}
+}
+
+func asBool(v interface{}, pos int) (bool, error) {
+	switch b := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return b, nil
+	}
+	return false, exprErrorf(pos, "want a boolean, got %s", describeValue(v))
+}
+
+// toNumber reads a number, or a string that parses as one (payload values
+// are strings).
+func toNumber(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func valuesEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	_, an := a.(float64)
+	_, bn := b.(float64)
+	if an || bn {
+		af, aok := toNumber(a)
+		bf, bok := toNumber(b)
+		return aok && bok && af == bf
+	}
+	switch at := a.(type) {
+	case string:
+		bs, ok := b.(string)
+		return ok && at == bs
+	case bool:
+		bb, ok := b.(bool)
+		return ok && at == bb
+	case *Task:
+		bt, ok := b.(*Task)
+		return ok && at.ID == bt.ID
+	}
+	return false
+}
+
+func containsValue(haystack, needle interface{}) bool {
+	switch h := haystack.(type) {
+	case []interface{}:
+		for _, v := range h {
+			if valuesEqual(v, needle) {
+				return true
+			}
+		}
+	case string:
+		s, ok := needle.(string)
+		return ok && strings.Contains(h, s)
+	}
+	return false
+}
+
+func neighborIDs(env *RuleExprEnv, entity, edge interface{}) ([]string, error) {
+	var id string
+	switch e := entity.(type) {
+	case *Task:
+		id = e.ID
+	case string:
+		id = e
+	}
+	typ, _ := edge.(string)
+	if strings.TrimSpace(id) == "" || typ == "" || env.Neighbors == nil {
+		return nil, nil
+	}
+	return env.Neighbors(id, EdgeType(typ))
+}
+
+func taskField(t *Task, name string, now time.Time) interface{} {
+	switch name {
+	case "id":
+		return t.ID
+	case "title":
+		return t.Title
+	case "type":
+		return string(t.Type)
+	case "status":
+		return string(t.Status)
+	case "priority":
+		return string(t.Priority)
+	case "owner":
+		return t.Owner
+	case "repo":
+		return t.Repo
+	case "branch":
+		return t.Branch
+	case "initiative":
+		return t.Initiative
+	case "source":
+		return t.Source
+	case "tags":
+		return stringList(t.Tags)
+	case "teams":
+		return stringList(t.Teams)
+	case "blocked_by":
+		return stringList(t.BlockedBy)
+	case "age_days":
+		return daysSince(t.Created, now)
+	case "idle_days":
+		return daysSince(t.Updated, now)
+	}
+	return nil
+}
+
+func stringList(ss []string) []interface{} {
+	out := make([]interface{}, len(ss))
+	for i, s := range ss {
+		out[i] = s
+	}
+	return out
+}
+
+func daysSince(t, now time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return now.Sub(t).Hours() / 24
+}
+
+func describeValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case string:
+		return fmt.Sprintf("the string %q", t)
+	case float64:
+		return "a number"
+	case []interface{}:
+		return "a list"
+	case *Task:
+		return "a task"
+	case map[string]string:
+		return "the payload"
+	}
+	return fmt.Sprintf("%T", v)
+}
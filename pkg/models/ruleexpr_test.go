@@ -0,0 +1,105 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func exprEnv() RuleExprEnv {
+	now := time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC)
+	return RuleExprEnv{
+		Event:   "task.status_changed",
+		Payload: map[string]string{"task_id": "TASK-1", "new_status": "blocked", "count": "12"},
+		Task: &Task{
+			ID: "TASK-1", Status: TaskStatusBlocked, Priority: PriorityP0, Type: TaskTypeFeat,
+			Tags: []string{"billing", "urgent"}, Initiative: "INIT-1",
+			Created: now.Add(-10 * 24 * time.Hour), Updated: now.Add(-36 * time.Hour),
+		},
+		Now: now,
+		Neighbors: func(entity string, edge EdgeType) ([]string, error) {
+			if entity == "TASK-1" && edge == EdgePartOf {
+				return []string{"INIT-1"}, nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestRuleExpr_Eval(t *testing.T) {
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{`task.priority == "P0" && payload.new_status == "blocked" && !has_edge(task, "depends_on")`, true},
+		{`task.priority == "P1" || payload.new_status != "blocked"`, false},
+		{`"urgent" in task.tags && !("wontfix" in task.tags)`, true},
+		{`task.age_days > 7 && task.idle_days < 2`, true},
+		{`payload.count > 9`, true}, // numeric, not lexical, comparison
+		{`payload.count == 12`, true},
+		{`payload.missing == null && !(payload.missing == "x")`, true},
+		{`payload.missing > 3`, false},
+		{`payload["new_status"] == "blocked"`, true},
+		{`"INIT-1" in neighbors(task, "part_of") && has_edge("TASK-1", "part_of")`, true},
+		{`event == "task.status_changed" && starts_with(task.id, "TASK-")`, true},
+		{`len(task.tags) == 2 && contains(lower("Billing Team"), "billing")`, true},
+		{`task.type in ["feat", "fix"]`, true},
+		{`"bill" in task.initiative`, false},
+	}
+	env := exprEnv()
+	for _, tc := range cases {
+		x, err := CompileRuleExpr(tc.expr)
+		if err != nil {
+			t.Fatalf("CompileRuleExpr(%q) error = %v", tc.expr, err)
+		}
+		got, err := x.Eval(env)
+		if err != nil {
+			t.Fatalf("Eval(%q) error = %v", tc.expr, err)
+		}
+		if got != tc.want {
+			t.Errorf("Eval(%q) = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestRuleExpr_NullTask(t *testing.T) {
+	x, err := CompileRuleExpr(`task == null || task.priority == "P0"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := x.Eval(RuleExprEnv{}); err != nil || !got {
+		t.Errorf("Eval with no task = %v, %v; want true", got, err)
+	}
+	x, _ = CompileRuleExpr(`task.priority == "P0" && has_edge(task, "blocks")`)
+	if got, err := x.Eval(RuleExprEnv{}); err != nil || got {
+		t.Errorf("Eval with no task or graph = %v, %v; want false", got, err)
+	}
+}
+
+func TestCompileRuleExpr_Errors(t *testing.T) {
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{``, "empty"},
+		{`task.prio == "P0"`, `column 6: unknown task field "prio"`},
+		{`ticket.priority == "P0"`, `column 1: unknown name "ticket"`},
+		{`task.priority = "P0"`, `column 15: unexpected "=" (did you mean "=="?)`},
+		{`task.priority == "P0" &&`, "column 25: unexpected end of expression"},
+		{`has_edge(task)`, "has_edge takes 2 argument(s), got 1"},
+		{`has_edge(task, "blocked_by")`, `column 16: unknown edge type "blocked_by"`},
+		{`shell("rm -rf /")`, `unknown function "shell"`},
+		{`task.priority`, "expression is a string, want a boolean"},
+		{`task.age_days > "old"`, "> needs two numbers or two strings"},
+		{`task.tags && true`, "left side of && is a list"},
+		{`1 < 2 < 3`, "comparisons do not chain"},
+		{`(task.priority == "P0"`, `expected ")"`},
+		{`task.title == 'unterminated`, "column 15: unterminated string"},
+	}
+	for _, tc := range cases {
+		_, err := CompileRuleExpr(tc.expr)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("CompileRuleExpr(%q) error = %v, want %q", tc.expr, err, tc.want)
+		}
+	}
+}